@@ -18,6 +18,20 @@ func (e *Error) Error() string {
 	return e.msg
 }
 
+// ExitError exits abctl with its Code, instead of the exit code 1 of every other error.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
 var (
 	// ErrAirbyteDir is returned anytime an there is an issue in accessing the paths.Airbyte directory.
 	ErrAirbyteDir = &Error{
@@ -33,6 +47,35 @@ You may need to remove this directory before trying your command again.`,
 you may need to run the "local install" command again.`,
 	}
 
+	// ErrComponentNotFound is returned in the event that no pod of a requested component was located.
+	ErrComponentNotFound = &Error{
+		msg: "component not found",
+		help: `No pod of the component was found in the airbyte-abctl namespace.
+Run "abctl local status" to list the components of the installation.`,
+	}
+
+	// ErrClusterNotOwned is returned in the event that an existing cluster was not created by abctl.
+	ErrClusterNotOwned = &Error{
+		msg: "existing cluster is not managed by abctl",
+		help: `A cluster with the same name already exists but was not created by abctl.
+To avoid interfering with a cluster you manage yourself, abctl will not install into it.
+If this cluster should be managed by abctl, pass the flag --adopt to take ownership of it.`,
+	}
+
+	// ErrClusterCreateTimeout is returned in the event that the cluster was not created in time.
+	ErrClusterCreateTimeout = &Error{
+		msg: "timed out creating the cluster",
+		help: `The cluster was not created in time, and the partially created cluster was removed.
+Ensure that Docker is running and has sufficient resources available, then try your command again.`,
+	}
+
+	// ErrClusterDeleteTimeout is returned in the event that the cluster was not deleted in time.
+	ErrClusterDeleteTimeout = &Error{
+		msg: "timed out deleting the cluster",
+		help: `The cluster was not deleted in time, Docker appears to be unresponsive.
+Try restarting Docker, then run "abctl local uninstall" again, which deletes whatever remains of the cluster.`,
+	}
+
 	// ErrDocker is returned anytime an error occurs when attempting to communicate with docker.
 	ErrDocker = &Error{
 		msg: "error communicating with docker",
@@ -52,6 +95,14 @@ Your data will persist between the uninstall and install commands.
 `,
 	}
 
+	// ErrHelmTimeout is returned if helm did not finish waiting on the resources of a chart within its timeout.
+	ErrHelmTimeout = &Error{
+		msg: "timed out waiting for the helm chart resources",
+		help: `Helm did not finish waiting for the resources of the chart to be ready within the --helm-timeout.
+The resource helm was still waiting on is reported above, "abctl local status" reports the state of every component.
+If the components are only slow to start (e.g. due to slow image pulls), pass a longer --helm-timeout.`,
+	}
+
 	// ErrKubernetes is returned anytime an error occurs when attempting to communicate with the kubernetes cluster.
 	ErrKubernetes = &Error{
 		msg: "error communicating with kubernetes",
@@ -69,6 +120,45 @@ This could be in indication that the ingress port is already in use by a differe
 The ingress port can be changed by passing the flag --port.`,
 	}
 
+	// ErrNodeOOMKilled is returned in the event that the docker engine OOM-killed the node container of the cluster.
+	ErrNodeOOMKilled = &Error{
+		msg: "the cluster node was OOM-killed",
+		help: `The Docker engine stopped the cluster node, as it ran out of memory.
+Increase the memory available to Docker (e.g. in the Resources settings of Docker Desktop),
+or pass the flag --low-resource-mode to reduce the memory used by Airbyte, then try your command again.`,
+	}
+
+	// ErrPortAbctlCluster is returned in the event that the requested port is in use by the ingress of another abctl cluster.
+	ErrPortAbctlCluster = &Error{
+		msg: "port in use by an existing abctl cluster",
+		help: `The requested port is already serving the ingress of an existing abctl cluster.
+Airbyte may already be installed, check the address above in your browser.
+Otherwise run "abctl local uninstall" to remove the existing cluster, or pass the flag --port to use a different port.`,
+	}
+
+	// ErrPreflightStrict is returned in the event that a pre-flight check warned while in strict mode.
+	ErrPreflightStrict = &Error{
+		msg: "pre-flight check failed in strict mode",
+		help: `A pre-flight check which only warns by default failed, as the flag --strict was passed.
+Address the warnings above before trying your command again, or run it without --strict to continue despite them.`,
+	}
+
+	// ErrPreflightFailed is returned in the event that a check of install --preflight-only failed.
+	ErrPreflightFailed = &Error{
+		msg: "pre-flight checks failed",
+		help: `At least one pre-flight check failed, see the table above for the measured values and their requirements.
+The exit code is that of the first failing check: 10 docker, 11 docker version, 12 resources, 13 disk, 14 connectivity, 15 port.
+Checks which only warn fail too with --strict.`,
+	}
+
+	// ErrLicenseKeyRequired is returned in the event that a chart flavor requiring a license key is selected without one.
+	ErrLicenseKeyRequired = &Error{
+		msg: "license key required",
+		help: `The enterprise chart flavor cannot be installed without an Airbyte Enterprise license key.
+Provide the license key with the flag --license-key, or the ABCTL_LOCAL_INSTALL_LICENSE_KEY environment variable.
+To install the community edition instead, pass the flag --chart-flavor community.`,
+	}
+
 	// ErrPort is returned in the event that the requested port is unavailable.
 	ErrPort = &Error{
 		msg: "error verifying port availability",
@@ -93,6 +183,135 @@ IP addresses won't work. Ports won't work (e.g. example:8000). URLs won't work (
 By default, abctl will allow access from any hostname or IP, so you might not need the --host flag.`,
 	}
 
+	// ErrSnapshotIncompatible is returned in the event that a snapshot cannot be restored with the requested chart version.
+	ErrSnapshotIncompatible = &Error{
+		msg: "snapshot incompatible with the requested chart version",
+		help: `The data in the snapshot passed with --from-snapshot was written by a different major, or a newer, chart version.
+A database cannot be downgraded, nor migrated across major chart versions by a restore.
+Omit --chart-version to install the chart version recorded in the snapshot.`,
+	}
+
+	// ErrSnapshotTarget is returned in the event that a snapshot would be restored over an existing installation.
+	ErrSnapshotTarget = &Error{
+		msg: "existing installation found",
+		help: `A snapshot can only seed a fresh installation, but an existing cluster or data directory was found.
+Run "abctl local uninstall --persisted" to remove the existing installation and its data, then try your command again.`,
+	}
+
+	// ErrEnvFromSource is returned in the event that a secret or config map passed to the components does not exist.
+	ErrEnvFromSource = &Error{
+		msg: "env source not found",
+		help: `A secret passed with --env-from-secret, or a config map passed with --env-from-configmap, does not exist in the
+airbyte-abctl namespace. Create it before installing, e.g. "kubectl create secret generic <NAME> --from-env-file=<FILE> -n airbyte-abctl".`,
+	}
+
+	// ErrStorageClass is returned in the event that the storage class of the persistent volumes does not exist.
+	ErrStorageClass = &Error{
+		msg: "storage class not found",
+		help: `The storage class passed with --storage-class does not exist in the cluster.
+List the storage classes of the cluster with "kubectl get storageclass", or omit --storage-class to use the default.`,
+	}
+
+	// ErrTLSSecret is returned in the event that the TLS secret of the ingress does not exist.
+	ErrTLSSecret = &Error{
+		msg: "tls secret not found",
+		help: `The secret passed with --tls-secret does not exist in the airbyte-abctl namespace.
+Create it before installing, e.g. "kubectl create secret tls <NAME> --cert=<CERT_FILE> --key=<KEY_FILE> -n airbyte-abctl",
+or provide it as a secret file with the --secret flag.`,
+	}
+
+	// ErrUpgradeBlocked is returned in the event that the target chart version cannot be upgraded to.
+	ErrUpgradeBlocked = &Error{
+		msg: "upgrade blocked",
+		help: `The requested chart version cannot be upgraded to from the currently installed version.
+Downgrading an existing installation is not supported, as the database may already have been migrated.
+To install an older version, run "abctl local uninstall" before running "abctl local install" again.`,
+	}
+
+	// ErrVolumeShrink is returned in the event that a smaller size is requested for an existing persistent volume claim.
+	ErrVolumeShrink = &Error{
+		msg: "persistent volume cannot be shrunk",
+		help: `The requested --data-volume-size is smaller than the size of the existing database volume.
+Kubernetes does not support shrinking a persistent volume claim, so the existing size will be kept.
+To continue with the existing size, pass the flag --force.`,
+	}
+
+	// ErrValuesSchema is returned in the event that the helm values do not match the chart's values schema.
+	ErrValuesSchema = &Error{
+		msg: "helm values failed schema validation",
+		help: `The provided helm values do not match the values schema shipped with the Airbyte chart.
+Check the values file passed with the --values flag for misspelled keys or values of the wrong type.
+Validation can be skipped by passing the flag --no-schema-validate.`,
+	}
+
+	// ErrValuesEnvUndefined is returned in the event that a values file references an undefined environment variable.
+	ErrValuesEnvUndefined = &Error{
+		msg: "undefined environment variable in values file",
+		help: `The values file passed with the --values flag references an environment variable which is not set.
+Set the environment variable, or provide a default with the ${VAR:-default} syntax.
+A literal $ can be written as $$.`,
+	}
+
+	// ErrSyncVerification is returned in the event that a component a sync flows through does not accept work.
+	ErrSyncVerification = &Error{
+		msg: "sync verification failed",
+		help: `Airbyte was installed and its components are healthy, but one of them is not accepting work, so syncs cannot run.
+Check the logs of the named component with 'abctl local logs <COMPONENT>'.`,
+	}
+
+	// ErrImagesIncomplete is returned in the event that required images are missing, or not at their required digest.
+	ErrImagesIncomplete = &Error{
+		msg: "required images are missing",
+		help: `Not every image Airbyte requires is present in Docker at the required digest, so an installation would need to pull them.
+Pull or load (docker load) the images listed above, e.g. from the registry or archive the installation bundle was made from.`,
+	}
+
+	// ErrNodeNotReady is returned in the event that the node of a created cluster does not become Ready in time.
+	ErrNodeNotReady = &Error{
+		msg: "kind node did not become Ready",
+		help: `The cluster was created, but its node did not register as Ready with Kubernetes in time.
+On a slow or heavily loaded machine this can take a while, a longer --node-ready-timeout (e.g. 10m) allows for it.
+The last conditions of the node are listed above, a condition such as MemoryPressure or DiskPressure means Docker needs more resources.`,
+	}
+
+	// ErrRepoIndexDNS is returned in the event that the host of the chart repository cannot be resolved.
+	ErrRepoIndexDNS = &Error{
+		msg: "unable to resolve the host of the chart repository",
+		help: `The host of the Airbyte helm chart repository could not be resolved, after retrying.
+Check the network connection and DNS settings of this machine, and any proxy configured by the HTTPS_PROXY environment variable.
+The repository index is only fetched to find the latest chart version, which --chart-version, or --chart with a local chart, avoids.`,
+	}
+
+	// ErrRepoIndexTLS is returned in the event that the certificate of the chart repository cannot be verified.
+	ErrRepoIndexTLS = &Error{
+		msg: "unable to verify the certificate of the chart repository",
+		help: `The TLS connection to the Airbyte helm chart repository failed, as its certificate could not be verified.
+A proxy or firewall which intercepts TLS traffic is a common cause, in which case its CA certificate must be trusted by this machine.
+A clock which is far off can cause this as well.`,
+	}
+
+	// ErrRepoIndexTimeout is returned in the event that the chart repository does not respond in time.
+	ErrRepoIndexTimeout = &Error{
+		msg: "timed out fetching the index of the chart repository",
+		help: `The Airbyte helm chart repository did not respond in time, after retrying. This is usually transient, try again.
+If it persists, check the network connection of this machine and any proxy configured by the HTTPS_PROXY environment variable.`,
+	}
+
+	// ErrRepoIndexStatus is returned in the event that the chart repository responds to the index request with an error.
+	ErrRepoIndexStatus = &Error{
+		msg: "the chart repository responded with an error",
+		help: `The Airbyte helm chart repository responded to the request for its index with an HTTP error.
+A 5xx status is usually transient, try again later. A 403 or 407 status may mean a proxy is blocking the request.
+The repository index is only fetched to find the latest chart version, which --chart-version, or --chart with a local chart, avoids.`,
+	}
+
+	// ErrOfflineChart is returned in the event that the latest chart version must be resolved with --offline.
+	ErrOfflineChart = &Error{
+		msg: "the latest chart version cannot be resolved offline",
+		help: `With --offline, the chart repository index is never fetched, so the latest chart version cannot be found.
+Specify the chart version to install with --chart-version, or a local chart with --chart.`,
+	}
+
 	ErrBootloaderFailed = &Error{
 		msg:  "bootloader failed",
 		help: "The bootloader failed to its initialization checks or migrations. Try running again with --verbose to see the full bootloader logs.",
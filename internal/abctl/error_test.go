@@ -2,6 +2,7 @@ package abctl
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -28,3 +29,21 @@ func TestLocalError(t *testing.T) {
 		t.Errorf("error message diff:\n%s", d)
 	}
 }
+
+func TestExitError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &ExitError{Code: 12, Err: fmt.Errorf("%w: resources", ErrPreflightFailed)})
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatal("error should be of type ExitError")
+	}
+	if d := cmp.Diff(12, exitErr.Code); d != "" {
+		t.Errorf("exit code diff:\n%s", d)
+	}
+	if !errors.Is(err, ErrPreflightFailed) {
+		t.Errorf("expected the error to wrap ErrPreflightFailed, got %v", err)
+	}
+	if d := cmp.Diff("wrapped: pre-flight checks failed: resources", err.Error()); d != "" {
+		t.Errorf("error message diff:\n%s", d)
+	}
+}
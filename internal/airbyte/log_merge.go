@@ -0,0 +1,113 @@
+package airbyte
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// TaggedLine is a log line tagged with the component which produced it.
+type TaggedLine struct {
+	Component string
+	Line      logLine
+}
+
+type mergeEntry struct {
+	TaggedLine
+	// received is when the line was added to the merger
+	received time.Time
+	// seq preserves the order lines were added in, for lines with identical times
+	seq int
+}
+
+// LogMerger merges the log lines of multiple components into timestamp order.
+//
+// As lines from different components arrive independently, each line is buffered for a short window
+// allowing any near-simultaneous lines from other components to be ordered before it.
+// Lines without a timestamp (e.g. non-JSON lines) are attached to the time of the previous line of the same component,
+// keeping them next to the line they belong to.
+type LogMerger struct {
+	mu     sync.Mutex
+	window time.Duration
+	buf    []mergeEntry
+	seq    int
+	// last is the time of the most recent line of each component
+	last map[string]time.Time
+}
+
+// NewLogMerger returns a LogMerger which buffers lines for the window before they are eligible to be flushed.
+func NewLogMerger(window time.Duration) *LogMerger {
+	return &LogMerger{
+		window: window,
+		last:   map[string]time.Time{},
+	}
+}
+
+// Add adds the line of the component to the merger, received is when the line was read.
+func (m *LogMerger) Add(component string, line logLine, received time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if line.Time.IsZero() {
+		line.Time = m.last[component]
+	} else {
+		m.last[component] = line.Time
+	}
+
+	m.buf = append(m.buf, mergeEntry{
+		TaggedLine: TaggedLine{Component: component, Line: line},
+		received:   received,
+		seq:        m.seq,
+	})
+	m.seq++
+}
+
+// Flush returns, in timestamp order, the buffered lines which were received at least one window before now.
+// A line which has not yet aged past the window holds back any lines ordered after it.
+func (m *LogMerger) Flush(now time.Time) []TaggedLine {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sort()
+
+	var out []TaggedLine
+	i := 0
+	for ; i < len(m.buf); i++ {
+		if now.Sub(m.buf[i].received) < m.window {
+			break
+		}
+		out = append(out, m.buf[i].TaggedLine)
+	}
+	m.buf = m.buf[i:]
+
+	return out
+}
+
+// FlushAll returns all the buffered lines in timestamp order.
+func (m *LogMerger) FlushAll() []TaggedLine {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sort()
+
+	out := make([]TaggedLine, len(m.buf))
+	for i, e := range m.buf {
+		out[i] = e.TaggedLine
+	}
+	m.buf = nil
+
+	return out
+}
+
+func (m *LogMerger) sort() {
+	sort.SliceStable(m.buf, func(i, j int) bool {
+		if !m.buf[i].Line.Time.Equal(m.buf[j].Line.Time) {
+			return m.buf[i].Line.Time.Before(m.buf[j].Line.Time)
+		}
+		// keep lines with the same time grouped by component, so untimed lines stay with the line they follow
+		if m.buf[i].Component != m.buf[j].Component {
+			return m.buf[i].Component < m.buf[j].Component
+		}
+		return m.buf[i].seq < m.buf[j].seq
+	})
+}
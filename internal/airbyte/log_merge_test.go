@@ -0,0 +1,105 @@
+package airbyte
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var (
+	testServerLogs = strings.TrimSpace(`
+{"timestamp":1734723317000,"message":"server starting","level":"INFO"}
+{"timestamp":1734723317020,"message":"server failed","level":"ERROR"}
+	at io.airbyte.server.Application.main
+{"timestamp":1734723317040,"message":"server stopped","level":"INFO"}
+`)
+	testWorkerLogs = strings.TrimSpace(`
+{"timestamp":1734723317010,"message":"worker starting","level":"INFO"}
+{"timestamp":1734723317020,"message":"worker waiting","level":"WARN"}
+{"timestamp":1734723317030,"message":"worker ready","level":"INFO"}
+`)
+	// expected merge of the server and worker logs
+	testMergedLogs = []string{
+		"server: server starting",
+		"worker: worker starting",
+		"server: server failed",
+		"server: \tat io.airbyte.server.Application.main",
+		"worker: worker waiting",
+		"worker: worker ready",
+		"server: server stopped",
+	}
+)
+
+func mergedMessages(lines []TaggedLine) []string {
+	var out []string
+	for _, l := range lines {
+		out = append(out, l.Component+": "+l.Line.Message)
+	}
+	return out
+}
+
+func addAll(t *testing.T, m *LogMerger, component, logs string, received time.Time) {
+	s := NewLogScanner(strings.NewReader(logs))
+	for s.Scan() {
+		m.Add(component, s.Line, received)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestLogScanner_Time(t *testing.T) {
+	s := NewLogScanner(strings.NewReader(testLogs))
+
+	s.Scan()
+	if !s.Line.Time.IsZero() {
+		t.Errorf("expected zero time for non-json line but got %s", s.Line.Time)
+	}
+
+	s.Scan()
+	if d := cmp.Diff(time.UnixMilli(1734723317023), s.Line.Time); d != "" {
+		t.Errorf("time mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestLogMerger_FlushAll(t *testing.T) {
+	m := NewLogMerger(time.Second)
+	now := time.Now()
+
+	// add the streams one after the other, the merger must interleave them
+	addAll(t, m, "worker", testWorkerLogs, now)
+	addAll(t, m, "server", testServerLogs, now)
+
+	if d := cmp.Diff(testMergedLogs, mergedMessages(m.FlushAll())); d != "" {
+		t.Errorf("merged logs mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(0, len(m.FlushAll())); d != "" {
+		t.Errorf("expected an empty merger after flushing (-want +got):\n%s", d)
+	}
+}
+
+func TestLogMerger_Flush(t *testing.T) {
+	m := NewLogMerger(100 * time.Millisecond)
+	start := time.Now()
+
+	// the server lines arrive first, the worker lines arrive shortly after within the window
+	addAll(t, m, "server", testServerLogs, start)
+	addAll(t, m, "worker", testWorkerLogs, start.Add(50*time.Millisecond))
+
+	// nothing has aged past the window yet
+	if d := cmp.Diff(0, len(m.Flush(start.Add(50*time.Millisecond)))); d != "" {
+		t.Errorf("expected no lines to be flushed (-want +got):\n%s", d)
+	}
+
+	// only the server lines have aged, but the first worker line is ordered before the second server line
+	if d := cmp.Diff([]string{"server: server starting"}, mergedMessages(m.Flush(start.Add(120*time.Millisecond)))); d != "" {
+		t.Errorf("flushed lines mismatch (-want +got):\n%s", d)
+	}
+
+	// everything has aged
+	if d := cmp.Diff(testMergedLogs[1:], mergedMessages(m.Flush(start.Add(200*time.Millisecond)))); d != "" {
+		t.Errorf("flushed lines mismatch (-want +got):\n%s", d)
+	}
+}
@@ -2,44 +2,177 @@ package airbyte
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"time"
 )
 
+// gzipMagic are the leading bytes of every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
 // LogScanner
 type LogScanner struct {
 	scanner *bufio.Scanner
 	Line    logLine
+
+	// closer is the file being scanned, if created by NewLogScannerFromFile
+	closer  io.Closer
+	gzipped bool
+
+	// src is the reader being scanned, which is closed to unblock a pending read once ScanContext is canceled
+	src io.Reader
+	// pending receives the result of the read started by ScanContext, nil if no read is in progress
+	pending chan bool
+	// ctxErr is the error of the context which stopped ScanContext
+	ctxErr error
 }
 
 // NewLogScanner returns an initialized Airbyte log scanner.
 func NewLogScanner(r io.Reader) *LogScanner {
 	return &LogScanner{
 		scanner: bufio.NewScanner(r),
+		src:     r,
+	}
+}
+
+// NewLogScannerFromFile returns an initialized Airbyte log scanner of the file at path.
+// Gzipped files are detected by their content, not their extension, and are transparently decompressed.
+// The returned scanner must be closed.
+func NewLogScannerFromFile(path string) (*LogScanner, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open log file: %w", err)
+	}
+
+	r, gzipped, err := decompress(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("unable to read log file '%s': %w", path, err)
 	}
+
+	s := NewLogScanner(r)
+	s.closer = f
+	s.gzipped = gzipped
+	return s, nil
 }
 
+// decompress returns a reader of the decompressed stream if r is gzipped, otherwise a reader of r as is.
+func decompress(r io.Reader) (io.Reader, bool, error) {
+	br := bufio.NewReader(r)
+	// streams shorter than the magic bytes can't be gzipped
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, false, err
+	}
+	if !bytes.Equal(magic, gzipMagic) {
+		return br, false, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid gzip header: %w", err)
+	}
+	return gz, true, nil
+}
+
+// Scan advances the scanner to the next line, which is then available as Line.
+// It returns false once the end of the logs is reached, or on an error, which Err returns.
 func (j *LogScanner) Scan() bool {
-	for {
-		if ok := j.scanner.Scan(); !ok {
+	if j.ctxErr != nil {
+		return false
+	}
+	if !j.scanner.Scan() {
+		return false
+	}
+	j.parse()
+	return true
+}
+
+// ScanContext is Scan, which is unblocked once the ctx is done, e.g. while waiting on a stream of logs from a pod.
+// Once the ctx is done, it returns false, as do any further scans, and Err returns the error of the ctx.
+// If the reader being scanned is an io.Closer, it's closed to unblock the pending read, otherwise the pending
+// read is left to return on its own.
+func (j *LogScanner) ScanContext(ctx context.Context) bool {
+	if j.ctxErr != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		j.stop(err)
+		return false
+	}
+
+	if j.pending == nil {
+		j.pending = make(chan bool, 1)
+		go func(pending chan<- bool) {
+			pending <- j.scanner.Scan()
+		}(j.pending)
+	}
+
+	select {
+	case ok := <-j.pending:
+		j.pending = nil
+		if !ok {
 			return false
 		}
+		j.parse()
+		return true
+	case <-ctx.Done():
+		j.stop(ctx.Err())
+		return false
+	}
+}
 
-		var data logLine
-		err := json.Unmarshal(j.scanner.Bytes(), &data)
-		// not all lines are JSON. don't propogate errors, just include the full line.
-		if err != nil {
-			j.Line = logLine{Message: j.scanner.Text()}
-		} else {
-			j.Line = data
-		}
+// stop stops the scanner with the err of the ctx, closing the reader being scanned to unblock any pending read.
+func (j *LogScanner) stop(err error) {
+	j.ctxErr = err
+	c, ok := j.src.(io.Closer)
+	if !ok || j.pending == nil {
+		return
+	}
+	_ = c.Close()
+	// wait for the pending read to return, so it's not left reading from the closed reader
+	<-j.pending
+	j.pending = nil
+}
 
-		return true
+// parse sets Line to the line the scanner advanced to.
+func (j *LogScanner) parse() {
+	var data logLine
+	err := json.Unmarshal(j.scanner.Bytes(), &data)
+	// not all lines are JSON. don't propogate errors, just include the full line.
+	if err != nil {
+		j.Line = logLine{Message: j.scanner.Text()}
+		return
+	}
+	if data.Timestamp != 0 {
+		data.Time = time.UnixMilli(data.Timestamp)
 	}
+	j.Line = data
 }
 
 func (j *LogScanner) Err() error {
-	return j.scanner.Err()
+	if j.ctxErr != nil {
+		return j.ctxErr
+	}
+	err := j.scanner.Err()
+	if j.gzipped && errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("gzip stream is truncated: %w", err)
+	}
+	return err
+}
+
+// Close closes the file being scanned, if the scanner was created by NewLogScannerFromFile.
+func (j *LogScanner) Close() error {
+	if j.closer == nil {
+		return nil
+	}
+	return j.closer.Close()
 }
 
 /*
@@ -88,6 +221,9 @@ type logLine struct {
 	LogSource string        `json:"logSource"`
 	Caller    *logCaller    `json:"caller"`
 	Throwable *logThrowable `json:throwable`
+
+	// Time is the parsed Timestamp, it is the zero time for lines without a timestamp
+	Time time.Time `json:"-"`
 }
 
 type logCaller struct {
@@ -1,8 +1,19 @@
 package airbyte
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 var testLogs = strings.TrimSpace(`
@@ -30,3 +41,234 @@ func TestJavaLogScanner(t *testing.T) {
 	expectLogLine("", "nonjsonline")
 	expectLogLine("WARN", "Waiting for database to become available...")
 }
+
+// writeLogFile writes the data to a file in a temp directory, gzipped if requested, returning its path.
+func writeLogFile(t *testing.T, name string, data []byte, gzipped bool) string {
+	t.Helper()
+
+	if gzipped {
+		var b bytes.Buffer
+		gz := gzip.NewWriter(&b)
+		if _, err := gz.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+		data = b.Bytes()
+	}
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewLogScannerFromFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		gzipped bool
+	}{
+		{name: "plain", file: "server.log"},
+		{name: "gzipped", file: "server.log.gz", gzipped: true},
+		// gzip is detected by content, not by extension
+		{name: "gzipped without extension", file: "server.log", gzipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewLogScannerFromFile(writeLogFile(t, tt.file, []byte(testLogs), tt.gzipped))
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			defer s.Close()
+
+			var got []string
+			for s.Scan() {
+				got = append(got, s.Line.Level+":"+s.Line.Message)
+			}
+			if s.Err() != nil {
+				t.Fatal("unexpected error", s.Err())
+			}
+
+			want := []string{":nonjsonline", "WARN:Waiting for database to become available..."}
+			if d := cmp.Diff(want, got); d != "" {
+				t.Errorf("lines mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestNewLogScannerFromFile_Truncated(t *testing.T) {
+	path := writeLogFile(t, "server.log.gz", []byte(testLogs), true)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// drop the trailing checksum and part of the compressed data
+	if err := os.WriteFile(path, data[:len(data)-20], 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewLogScannerFromFile(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer s.Close()
+
+	for s.Scan() {
+	}
+	if err := s.Err(); err == nil || !strings.Contains(err.Error(), "gzip stream is truncated") {
+		t.Errorf("expected truncated error but got %v", err)
+	}
+}
+
+func TestNewLogScannerFromFile_Missing(t *testing.T) {
+	if _, err := NewLogScannerFromFile(filepath.Join(t.TempDir(), "missing.log")); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestNewLogScannerFromFile_Short(t *testing.T) {
+	for _, data := range []string{"", "x"} {
+		s, err := NewLogScannerFromFile(writeLogFile(t, "short.log", []byte(data), false))
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		var got []string
+		for s.Scan() {
+			got = append(got, s.Line.Message)
+		}
+		_ = s.Close()
+		if s.Err() != nil {
+			t.Error("unexpected error", s.Err())
+		}
+		if len(data) > 0 && cmp.Diff([]string{data}, got) != "" {
+			t.Errorf("unexpected lines %v", got)
+		}
+	}
+}
+
+// blockingReader is a reader of a stream which has no more data yet, its reads block until it's closed.
+type blockingReader struct {
+	closed chan struct{}
+	// reading is closed once a read is blocked
+	reading chan struct{}
+	once    sync.Once
+}
+
+func newBlockingReader() *blockingReader {
+	return &blockingReader{closed: make(chan struct{}), reading: make(chan struct{})}
+}
+
+func (r *blockingReader) Read([]byte) (int, error) {
+	r.once.Do(func() { close(r.reading) })
+	<-r.closed
+	return 0, io.ErrClosedPipe
+}
+
+func (r *blockingReader) Close() error {
+	close(r.closed)
+	return nil
+}
+
+func TestLogScanner_ScanContext(t *testing.T) {
+	pr, pw := io.Pipe()
+	s := NewLogScanner(pr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		_, _ = pw.Write([]byte(testLogs + "\n"))
+	}()
+
+	var msgs []string
+	for i := 0; i < 2 && s.ScanContext(ctx); i++ {
+		msgs = append(msgs, s.Line.Message)
+	}
+	if d := cmp.Diff([]string{"nonjsonline", "Waiting for database to become available..."}, msgs); d != "" {
+		t.Errorf("messages mismatch (-want +got):\n%s", d)
+	}
+
+	// the next scan blocks on the pipe, until the ctx is canceled
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	if s.ScanContext(ctx) {
+		t.Error("expected the scan to stop")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the scan to stop promptly, took %s", elapsed)
+	}
+	if !errors.Is(s.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled but got %v", s.Err())
+	}
+
+	// the pipe was closed to unblock the pending read
+	if _, err := pw.Write([]byte("after\n")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected the pipe to be closed, got %v", err)
+	}
+
+	// every further scan is stopped
+	if s.ScanContext(context.Background()) || s.Scan() {
+		t.Error("expected further scans to stop")
+	}
+}
+
+func TestLogScanner_ScanContext_NoLeak(t *testing.T) {
+	r := newBlockingReader()
+	s := NewLogScanner(r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-r.reading
+		cancel()
+	}()
+
+	if s.ScanContext(ctx) {
+		t.Error("expected the scan to stop")
+	}
+	if !errors.Is(s.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled but got %v", s.Err())
+	}
+
+	// the reader was closed, and the pending read waited for, before the scan returned
+	select {
+	case <-r.closed:
+	default:
+		t.Error("expected the reader to be closed")
+	}
+	if s.pending != nil {
+		t.Error("expected no read to be pending")
+	}
+}
+
+func TestLogScanner_ScanContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := NewLogScanner(strings.NewReader(testLogs))
+	if s.ScanContext(ctx) {
+		t.Error("expected the scan to stop")
+	}
+	if !errors.Is(s.Err(), context.Canceled) {
+		t.Errorf("expected context.Canceled but got %v", s.Err())
+	}
+}
+
+func TestLogScanner_ScanContext_EOF(t *testing.T) {
+	s := NewLogScanner(strings.NewReader(testLogs))
+
+	var n int
+	for s.ScanContext(context.Background()) {
+		n++
+	}
+	if d := cmp.Diff(2, n); d != "" {
+		t.Errorf("lines mismatch (-want +got):\n%s", d)
+	}
+	if s.Err() != nil {
+		t.Errorf("unexpected error %v", s.Err())
+	}
+}
@@ -2,6 +2,7 @@ package build
 
 import (
 	"fmt"
+	"runtime"
 	"runtime/debug"
 	"strings"
 
@@ -69,3 +70,9 @@ func setVersion() {
 func init() {
 	setVersion()
 }
+
+// UserAgent returns the User-Agent abctl identifies itself with, e.g. abctl/v0.30.0 (darwin/arm64).
+// It only includes the version and platform of abctl, never anything identifying the user or their machine.
+func UserAgent() string {
+	return fmt.Sprintf("abctl/%s (%s/%s)", Version, runtime.GOOS, runtime.GOARCH)
+}
@@ -1,7 +1,10 @@
 package build
 
 import (
+	"os"
+	"runtime"
 	"runtime/debug"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -116,3 +119,25 @@ func TestVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestUserAgent(t *testing.T) {
+	origVersion := Version
+	t.Cleanup(func() { Version = origVersion })
+	Version = "v0.30.0"
+
+	want := "abctl/v0.30.0 (" + runtime.GOOS + "/" + runtime.GOARCH + ")"
+	if d := cmp.Diff(want, UserAgent()); d != "" {
+		t.Error("UserAgent differed (-want, +got):", d)
+	}
+
+	// the user agent must not identify the user or their machine
+	leaks := []string{os.Getenv("USER"), os.Getenv("USERNAME")}
+	if hostname, err := os.Hostname(); err == nil {
+		leaks = append(leaks, hostname)
+	}
+	for _, leak := range leaks {
+		if leak != "" && strings.Contains(UserAgent(), leak) {
+			t.Errorf("UserAgent %q contains %q", UserAgent(), leak)
+		}
+	}
+}
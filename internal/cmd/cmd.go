@@ -3,9 +3,12 @@ package cmd
 import (
 	"context"
 
+	"github.com/airbytehq/abctl/internal/cmd/completion"
 	"github.com/airbytehq/abctl/internal/cmd/images"
 	"github.com/airbytehq/abctl/internal/cmd/local"
 	"github.com/airbytehq/abctl/internal/cmd/version"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/http"
 	"github.com/airbytehq/abctl/internal/k8s"
 	"github.com/airbytehq/abctl/internal/service"
 	"github.com/alecthomas/kong"
@@ -19,14 +22,64 @@ func (v verbose) BeforeApply() error {
 	return nil
 }
 
+type dockerAPIVersion string
+
+func (d dockerAPIVersion) AfterApply() error {
+	return docker.SetAPIVersion(string(d))
+}
+
+type dockerContext string
+
+func (d dockerContext) AfterApply() error {
+	docker.SetContext(string(d))
+	return nil
+}
+
+type preferDockerHostEnv bool
+
+func (p preferDockerHostEnv) AfterApply() error {
+	docker.SetPreferHostEnv(bool(p))
+	return nil
+}
+
+type userAgent string
+
+func (u userAgent) AfterApply() error {
+	http.SetUserAgent(string(u))
+	return nil
+}
+
 type Cmd struct {
-	Local   local.Cmd   `cmd:"" help:"Manage the local Airbyte installation."`
-	Images  images.Cmd  `cmd:"" help:"Manage images used by Airbyte and abctl."`
-	Version version.Cmd `cmd:"" help:"Display version information."`
-	Verbose verbose     `short:"v" help:"Enable verbose output."`
+	Local               local.Cmd              `cmd:"" help:"Manage the local Airbyte installation."`
+	Images              images.Cmd             `cmd:"" help:"Manage images used by Airbyte and abctl."`
+	Version             version.Cmd            `cmd:"" help:"Display version information."`
+	Completion          completion.Cmd         `cmd:"" help:"Output the shell completion script of abctl (bash, zsh, fish or powershell)."`
+	Complete            completion.CompleteCmd `cmd:"" name:"__complete" hidden:"" help:"Complete an abctl command line."`
+	Verbose             verbose                `short:"v" help:"Enable verbose output."`
+	Color               colorMode              `default:"auto" enum:"auto,never,always" help:"When to color the output (auto, never or always). With auto, the output is only colored for a terminal and if NO_COLOR isn't set."`
+	DockerAPIVersion    dockerAPIVersion       `help:"Use a fixed Docker API version (e.g. 1.45) instead of negotiating it." env:"ABCTL_DOCKER_API_VERSION"`
+	DockerContext       dockerContext          `help:"Use the host of this Docker context instead of discovering the Docker host." env:"ABCTL_DOCKER_CONTEXT"`
+	DockerTLSCA         string                 `type:"path" name:"docker-tls-ca" help:"Verify a TLS secured tcp Docker host against this CA certificate. Defaults to the ca.pem of DOCKER_CERT_PATH."`
+	DockerTLSCert       string                 `type:"path" name:"docker-tls-cert" help:"Authenticate to a TLS secured tcp Docker host with this client certificate. Defaults to the cert.pem of DOCKER_CERT_PATH."`
+	DockerTLSKey        string                 `type:"path" name:"docker-tls-key" help:"The key of the --docker-tls-cert client certificate. Defaults to the key.pem of DOCKER_CERT_PATH."`
+	DockerTLSVerify     bool                   `name:"docker-tls-verify" help:"Verify the certificate of a TLS secured tcp Docker host, otherwise the connection is only encrypted. Also enabled by DOCKER_TLS_VERIFY."`
+	PreferDockerHostEnv preferDockerHostEnv    `help:"Use the DOCKER_HOST environment variable, if set, even if a --docker-context is selected." env:"ABCTL_PREFER_DOCKER_HOST_ENV"`
+	UserAgent           userAgent              `help:"Identify the outbound HTTP and Docker requests of abctl with this User-Agent, instead of abctl/<version> (<os>/<arch>)." env:"ABCTL_USER_AGENT"`
+}
+
+// AfterApply configures the TLS of tcp Docker hosts, once every flag is known.
+func (c *Cmd) AfterApply() error {
+	return docker.SetTLS(docker.TLSOptions{
+		CACert: c.DockerTLSCA,
+		Cert:   c.DockerTLSCert,
+		Key:    c.DockerTLSKey,
+		Verify: c.DockerTLSVerify,
+	})
 }
 
 func (c *Cmd) BeforeApply(_ context.Context, kCtx *kong.Context) error {
+	// the --color flag is only applied after every BeforeApply hook, which may already print output
+	applyColor(colorAuto)
 	kCtx.BindTo(k8s.DefaultProvider, (*k8s.Provider)(nil))
 	kCtx.BindTo(service.DefaultManagerClientFactory, (*service.ManagerClientFactory)(nil))
 	return nil
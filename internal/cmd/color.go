@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/pterm/pterm"
+)
+
+// colorMode controls whether the output of every command is colored.
+type colorMode string
+
+const (
+	// colorAuto colors the output only if stdout is a terminal and NO_COLOR isn't set.
+	colorAuto colorMode = "auto"
+	// colorNever never colors the output, stripping every ANSI color code.
+	colorNever colorMode = "never"
+	// colorAlways always colors the output, even if NO_COLOR is set.
+	colorAlways colorMode = "always"
+)
+
+func (c colorMode) AfterApply() error {
+	applyColor(c)
+	return nil
+}
+
+// applyColor enables or disables the color output for the mode, the NO_COLOR environment variable and stdout.
+func applyColor(mode colorMode) {
+	setColor(colorEnabled(mode, os.Getenv("NO_COLOR"), isTerminal(os.Stdout)))
+}
+
+// colorEnabled returns true if the output should be colored for the mode. Following the NO_COLOR convention
+// (https://no-color.org), a non-empty noColor disables color unless the mode is colorAlways.
+func colorEnabled(mode colorMode, noColor string, terminal bool) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	default:
+		return noColor == "" && terminal
+	}
+}
+
+// setColor enables or disables the color output of every pterm printer, including the spinners.
+func setColor(enabled bool) {
+	if enabled {
+		pterm.EnableColor()
+	} else {
+		pterm.DisableColor()
+	}
+}
+
+// isTerminal returns true if f is a terminal, rather than e.g. a pipe or a file.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
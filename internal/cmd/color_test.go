@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+)
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     colorMode
+		noColor  string
+		terminal bool
+		want     bool
+	}{
+		{name: "auto terminal", mode: colorAuto, terminal: true, want: true},
+		{name: "auto not a terminal", mode: colorAuto},
+		{name: "auto NO_COLOR", mode: colorAuto, noColor: "1", terminal: true},
+		{name: "never", mode: colorNever, terminal: true},
+		{name: "always", mode: colorAlways, want: true},
+		{name: "always overrides NO_COLOR", mode: colorAlways, noColor: "1", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, colorEnabled(tt.mode, tt.noColor, tt.terminal)); d != "" {
+				t.Errorf("color enabled mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+// printInfo returns the output of the pterm info printer for msg.
+func printInfo(t *testing.T, msg string) string {
+	t.Helper()
+	var b bytes.Buffer
+	pterm.Info.WithWriter(&b).Println(msg)
+	return b.String()
+}
+
+func TestApplyColor(t *testing.T) {
+	t.Cleanup(pterm.EnableColor)
+
+	t.Run("NO_COLOR", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		pterm.EnableColor()
+
+		applyColor(colorAuto)
+		if out := printInfo(t, "installing"); strings.Contains(out, "\x1b[") {
+			t.Errorf("expected no ANSI codes, got %q", out)
+		}
+	})
+
+	t.Run("never", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		pterm.EnableColor()
+
+		if err := colorNever.AfterApply(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if out := printInfo(t, "installing"); strings.Contains(out, "\x1b[") {
+			t.Errorf("expected no ANSI codes, got %q", out)
+		}
+	})
+
+	t.Run("always", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		pterm.DisableColor()
+
+		if err := colorAlways.AfterApply(); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if out := printInfo(t, "installing"); !strings.Contains(out, "\x1b[") {
+			t.Errorf("expected ANSI codes, got %q", out)
+		}
+	})
+}
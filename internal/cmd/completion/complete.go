@@ -0,0 +1,183 @@
+package completion
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/alecthomas/kong"
+)
+
+// predictorTag is the struct tag naming the predictor which completes the values of a flag, e.g. predictor:"cluster".
+const predictorTag = "predictor"
+
+// predictor returns the possible values of a flag. It must be fast, and return nothing rather than fail.
+type predictor func(ctx context.Context) []string
+
+// predictors are the available predictors, keyed by the name used in the predictorTag.
+var predictors = map[string]predictor{
+	"cluster": predictClusters,
+}
+
+// clusterListTimeout caps how long listing the clusters may delay a completion, e.g. when docker isn't running.
+const clusterListTimeout = time.Second
+
+// clusterLister lists the kind clusters, primarily exists for testing.
+type clusterLister interface {
+	KindClusters(ctx context.Context) ([]string, error)
+}
+
+var _ clusterLister = (*docker.Docker)(nil)
+
+// predictClusters returns the names of the existing abctl clusters, nothing if docker is unavailable.
+func predictClusters(ctx context.Context) []string {
+	ctx, cancel := context.WithTimeout(ctx, clusterListTimeout)
+	defer cancel()
+
+	d, err := docker.New(ctx)
+	if err != nil {
+		return nil
+	}
+	return abctlClusters(ctx, d)
+}
+
+// abctlClusters returns the names of the kind clusters of the lister which were created by abctl.
+func abctlClusters(ctx context.Context, lister clusterLister) []string {
+	clusters, err := lister.KindClusters(ctx)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, cluster := range clusters {
+		if cluster == k8s.DefaultProvider.ClusterName || cluster == k8s.TestProvider.ClusterName {
+			names = append(names, cluster)
+		}
+	}
+	return names
+}
+
+// complete returns the sorted completions of the last of the args, which are the words of the command line
+// following the program name. The last word is the one being completed, and may be empty.
+func complete(ctx context.Context, root *kong.Node, preds map[string]predictor, args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, cur := args[:len(args)-1], args[len(args)-1]
+
+	node := root
+	// the flag whose value is the next word, if any
+	var pending *kong.Flag
+	for _, word := range words {
+		switch {
+		case pending != nil:
+			pending = nil
+		case word == "--":
+			// only positional arguments follow, which are never completed
+			return nil
+		case strings.HasPrefix(word, "-"):
+			if f := findFlag(node, word); f != nil && !f.IsBool() && !f.IsCounter() && !strings.Contains(word, "=") {
+				pending = f
+			}
+		default:
+			if child := findChild(node, word); child != nil {
+				node = child
+			}
+		}
+	}
+
+	var candidates []string
+	switch {
+	case pending != nil:
+		candidates = flagValues(ctx, pending, preds, "")
+	case strings.HasPrefix(cur, "--") && strings.Contains(cur, "="):
+		name, _, _ := strings.Cut(cur, "=")
+		if f := findFlag(node, name); f != nil {
+			candidates = flagValues(ctx, f, preds, name+"=")
+		}
+	case strings.HasPrefix(cur, "-"):
+		for _, f := range visibleFlags(node) {
+			candidates = append(candidates, "--"+f.Name)
+		}
+	default:
+		for _, child := range node.Children {
+			if !child.Hidden {
+				candidates = append(candidates, child.Name)
+			}
+		}
+		for _, p := range node.Positional {
+			if p.Enum != "" {
+				candidates = append(candidates, p.EnumSlice()...)
+			}
+		}
+	}
+
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, cur) && !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// flagValues returns the possible values of the flag, each with the prefix, from its enum or its predictor.
+func flagValues(ctx context.Context, f *kong.Flag, preds map[string]predictor, prefix string) []string {
+	var values []string
+	if f.Enum != "" {
+		values = f.EnumSlice()
+	} else if p, ok := preds[f.Tag.Get(predictorTag)]; ok {
+		values = p(ctx)
+	}
+
+	for i, v := range values {
+		values[i] = prefix + v
+	}
+	return values
+}
+
+// visibleFlags returns the flags which are not hidden of the node and its parents.
+func visibleFlags(node *kong.Node) []*kong.Flag {
+	var flags []*kong.Flag
+	for n := node; n != nil; n = n.Parent {
+		for _, f := range n.Flags {
+			if !f.Hidden {
+				flags = append(flags, f)
+			}
+		}
+	}
+	return flags
+}
+
+// findFlag returns the flag of the node or its parents matching the word (e.g. --port, --port=8000 or -v),
+// nil if none match.
+func findFlag(node *kong.Node, word string) *kong.Flag {
+	name, _, _ := strings.Cut(word, "=")
+	for n := node; n != nil; n = n.Parent {
+		for _, f := range n.Flags {
+			if strings.HasPrefix(name, "--") {
+				if name[2:] == f.Name || slices.Contains(f.Aliases, name[2:]) {
+					return f
+				}
+			} else if f.Short != 0 && name == "-"+string(f.Short) {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// findChild returns the command of the node named, or aliased, word, nil if there is none.
+func findChild(node *kong.Node, word string) *kong.Node {
+	for _, child := range node.Children {
+		if child.Type == kong.CommandNode && (child.Name == word || slices.Contains(child.Aliases, word)) {
+			return child
+		}
+	}
+	return nil
+}
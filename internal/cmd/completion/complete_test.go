@@ -0,0 +1,137 @@
+package completion
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alecthomas/kong"
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeClusterLister struct {
+	clusters []string
+	err      error
+}
+
+func (f fakeClusterLister) KindClusters(context.Context) ([]string, error) {
+	return f.clusters, f.err
+}
+
+type testInstallCmd struct {
+	ClusterName string `predictor:"cluster"`
+	Flavor      string `enum:"community,enterprise" default:"community"`
+	Force       bool
+	Port        int
+}
+
+type testLocalCmd struct {
+	Install testInstallCmd `cmd:""`
+	Status  struct{}       `cmd:""`
+}
+
+type testCmd struct {
+	Local      testLocalCmd `cmd:""`
+	Completion Cmd          `cmd:""`
+	Complete   CompleteCmd  `cmd:"" name:"__complete" hidden:""`
+	Verbose    bool         `short:"v"`
+}
+
+func testRoot(t *testing.T) *kong.Node {
+	t.Helper()
+	parser, err := kong.New(&testCmd{}, kong.Name("abctl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parser.Model.Node
+}
+
+func TestComplete(t *testing.T) {
+	preds := map[string]predictor{
+		"cluster": func(ctx context.Context) []string {
+			return abctlClusters(ctx, fakeClusterLister{clusters: []string{"airbyte-abctl", "kind", "test-airbyte-abctl"}})
+		},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "commands", args: []string{""}, want: []string{"completion", "local"}},
+		{name: "no args", want: []string{"completion", "local"}},
+		{name: "subcommands", args: []string{"local", ""}, want: []string{"install", "status"}},
+		{name: "subcommand prefix", args: []string{"local", "in"}, want: []string{"install"}},
+		{name: "flags", args: []string{"local", "install", "--"}, want: []string{"--cluster-name", "--flavor", "--force", "--help", "--port", "--verbose"}},
+		{name: "flag prefix", args: []string{"local", "install", "--f"}, want: []string{"--flavor", "--force"}},
+		{name: "enum values", args: []string{"local", "install", "--flavor", ""}, want: []string{"community", "enterprise"}},
+		{name: "enum values with equals", args: []string{"local", "install", "--flavor=e"}, want: []string{"--flavor=enterprise"}},
+		{name: "cluster names", args: []string{"local", "install", "--cluster-name", ""}, want: []string{"airbyte-abctl", "test-airbyte-abctl"}},
+		{name: "cluster name prefix", args: []string{"local", "install", "--cluster-name", "test"}, want: []string{"test-airbyte-abctl"}},
+		{name: "cluster names with equals", args: []string{"local", "install", "--cluster-name="}, want: []string{"--cluster-name=airbyte-abctl", "--cluster-name=test-airbyte-abctl"}},
+		{name: "after a bool flag", args: []string{"local", "install", "--force", "--fl"}, want: []string{"--flavor"}},
+		{name: "after a flag value", args: []string{"local", "install", "--port", "8000", "--po"}, want: []string{"--port"}},
+		{name: "after a global flag", args: []string{"-v", "local", ""}, want: []string{"install", "status"}},
+		{name: "flag without values", args: []string{"local", "install", "--port", ""}},
+		{name: "positional enum", args: []string{"completion", "z"}, want: []string{"zsh"}},
+		{name: "after double dash", args: []string{"local", "install", "--", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := complete(context.Background(), testRoot(t), preds, tt.args)
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("completions mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestAbctlClusters(t *testing.T) {
+	tests := []struct {
+		name   string
+		lister fakeClusterLister
+		want   []string
+	}{
+		{
+			name:   "abctl clusters",
+			lister: fakeClusterLister{clusters: []string{"airbyte-abctl", "dev", "test-airbyte-abctl"}},
+			want:   []string{"airbyte-abctl", "test-airbyte-abctl"},
+		},
+		{
+			name:   "no abctl clusters",
+			lister: fakeClusterLister{clusters: []string{"dev"}},
+		},
+		{
+			name:   "docker unavailable",
+			lister: fakeClusterLister{err: errors.New("test error")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, abctlClusters(context.Background(), tt.lister)); d != "" {
+				t.Errorf("clusters mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestRequested(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{"completion", "bash"}, want: true},
+		{args: []string{"-v", "__complete", "--", "local"}, want: true},
+		{args: []string{"local", "install"}},
+		{args: []string{"local", "completion"}},
+		{},
+	}
+
+	for _, tt := range tests {
+		if got := Requested(tt.args); got != tt.want {
+			t.Errorf("Requested(%v) = %t, want %t", tt.args, got, tt.want)
+		}
+	}
+}
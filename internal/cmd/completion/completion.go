@@ -0,0 +1,126 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/pterm/pterm"
+)
+
+// CompleteCmdName is the name of the hidden command the completion scripts call to complete a command line,
+// it must match the name the CompleteCmd is registered with.
+const CompleteCmdName = "__complete"
+
+// Cmd outputs the completion script of a shell. The script completes the commands and flags of abctl, the values
+// of enum flags, and the values of any flag tagged with a predictor (e.g. predictor:"cluster" for the names of the
+// existing abctl clusters).
+type Cmd struct {
+	Shell string `arg:"" enum:"bash,zsh,fish,powershell" help:"The shell to output the completion script of (bash, zsh, fish or powershell)."`
+}
+
+func (c *Cmd) Run() error {
+	return writeScript(os.Stdout, c.Shell)
+}
+
+// writeScript writes the completion script of the shell to w.
+func writeScript(w io.Writer, shell string) error {
+	script, ok := scripts[shell]
+	if !ok {
+		return fmt.Errorf("unsupported shell '%s'", shell)
+	}
+	_, err := io.WriteString(w, script)
+	return err
+}
+
+// CompleteCmd writes the completions of the last of its arguments, one per line. It is called by the completion scripts.
+type CompleteCmd struct {
+	NewWord bool     `help:"Complete a new, empty, word following the arguments, for shells which drop empty arguments."`
+	Args    []string `arg:"" optional:"" help:"The words of the command line following abctl, the last being the one to complete."`
+}
+
+func (c *CompleteCmd) Run(ctx context.Context, kCtx *kong.Context) error {
+	// anything else written to the output would be taken as a completion
+	pterm.DisableOutput()
+
+	args := c.Args
+	if c.NewWord {
+		args = append(args, "")
+	}
+	for _, completion := range complete(ctx, kCtx.Model.Node, predictors, args) {
+		fmt.Fprintln(os.Stdout, completion)
+	}
+	return nil
+}
+
+// Requested returns true if the args (following the program name) run one of the completion commands,
+// whose output is consumed by the shell.
+func Requested(args []string) bool {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		return arg == "completion" || arg == CompleteCmdName
+	}
+	return false
+}
+
+// scripts are the completion scripts, keyed by shell. Each passes the words of the command line, up to the cursor,
+// to the CompleteCmd following a "--", so that words which look like flags are not parsed as its own flags.
+var scripts = map[string]string{
+	"bash": `# abctl bash completion, load with: source <(abctl completion bash)
+_abctl() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local -a words
+    read -r -a words <<< "$line"
+    if [[ "$line" =~ [[:space:]]$ ]]; then
+        words+=("")
+    fi
+    local cur="${words[${#words[@]}-1]}"
+    local IFS=$'\n'
+    COMPREPLY=($(abctl __complete -- "${words[@]:1}" 2>/dev/null))
+    # bash completes the value of a --flag=value word separately from the flag
+    if [[ "$cur" == --*=* ]]; then
+        COMPREPLY=("${COMPREPLY[@]#*=}")
+    fi
+}
+complete -o default -F _abctl abctl
+`,
+	"zsh": `#compdef abctl
+# abctl zsh completion, load with: source <(abctl completion zsh)
+_abctl() {
+    local -a completions
+    completions=("${(@f)$(abctl __complete -- "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ -z "${completions[1]}" ]]; then
+        _files
+    else
+        compadd -a completions
+    fi
+}
+compdef _abctl abctl
+`,
+	"fish": `# abctl fish completion, load with: abctl completion fish | source
+function __abctl_complete
+    set -l words (commandline -opc)[2..-1] (commandline -ct)
+    abctl __complete -- $words 2>/dev/null
+end
+complete -c abctl -a '(__abctl_complete)'
+`,
+	"powershell": `# abctl powershell completion, load with: abctl completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName abctl -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    $newWord = @()
+    if ($wordToComplete -eq '') {
+        # powershell drops empty arguments to native commands
+        $newWord = @('--new-word')
+    }
+    abctl __complete @newWord -- @words 2>$null | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`,
+}
@@ -14,9 +14,10 @@ import (
 )
 
 type ManifestCmd struct {
-	Chart        string `help:"Path to chart." xor:"chartver"`
-	ChartVersion string `help:"Version of the chart." xor:"chartver"`
-	Values       string `type:"existingfile" help:"An Airbyte helm chart values file to configure helm."`
+	Chart             string `help:"Path to chart." xor:"chartver"`
+	ChartVersion      string `help:"Version of the chart." xor:"chartver"`
+	RefreshImageCache bool   `help:"Resolve the images of the chart again, instead of using the cached ones."`
+	Values            string `type:"existingfile" help:"An Airbyte helm chart values file to configure helm."`
 }
 
 func (c *ManifestCmd) Run(ctx context.Context, newSvcMgrClients service.ManagerClientFactory) error {
@@ -56,7 +57,8 @@ func (c *ManifestCmd) findAirbyteImages(ctx context.Context, helmClient goHelm.C
 		return nil, fmt.Errorf("failed to set chart flag defaults: %w", err)
 	}
 
-	return helm.FindImagesFromChart(helmClient, valuesYaml, c.Chart, c.ChartVersion)
+	cache := &helm.ImageCache{Path: paths.ImageCache, Refresh: c.RefreshImageCache}
+	return cache.FindImages(helmClient, valuesYaml, c.Chart, c.ChartVersion)
 }
 
 func (c *ManifestCmd) setDefaultChartFlags(helmClient goHelm.Client) error {
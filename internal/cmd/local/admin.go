@@ -0,0 +1,96 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/airbytehq/abctl/internal/airbyte"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+)
+
+// minAdminPasswordLen is the length below which an admin password is considered weak.
+const minAdminPasswordLen = 12
+
+// adminPassword returns the admin password from either the password or the contents of the file.
+// Surrounding whitespace is trimmed from the file contents, as files commonly end with a newline.
+func adminPassword(password, file string) (string, error) {
+	if file == "" {
+		return password, nil
+	}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("unable to read admin password file: %w", err)
+	}
+	password = strings.TrimSpace(string(raw))
+	if password == "" {
+		return "", fmt.Errorf("admin password file '%s' is empty", file)
+	}
+
+	return password, nil
+}
+
+// passwordWeaknesses returns the reasons the password is considered weak, if any.
+// The reasons never include the password itself.
+func passwordWeaknesses(password string) []string {
+	var weaknesses []string
+	if len(password) < minAdminPasswordLen {
+		weaknesses = append(weaknesses, fmt.Sprintf("shorter than %d characters", minAdminPasswordLen))
+	}
+
+	var lower, upper, digit, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			classes++
+		}
+	}
+	if classes < 3 {
+		weaknesses = append(weaknesses, "uses fewer than 3 of lowercase, uppercase, digits and symbols")
+	}
+
+	return weaknesses
+}
+
+// validateAdminEmail ensures the email is a bare email address, e.g. user@example.com.
+func validateAdminEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return fmt.Errorf("invalid admin email '%s': must be an email address", email)
+	}
+	return nil
+}
+
+// setAdminEmail sets the email of the admin login of the airbyte installation at url.
+func setAdminEmail(ctx context.Context, k8sClient k8s.Client, url, email string) error {
+	secret, err := k8sClient.SecretGet(ctx, airbyteNamespace, airbyteAuthSecretName)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the airbyte credentials: %w", err)
+	}
+
+	abAPI := airbyte.New(url, string(secret.Data[secretClientID]), string(secret.Data[secretClientSecret]))
+	if err := abAPI.SetOrgEmail(ctx, email); err != nil {
+		pterm.Error.Println("Unable to set the admin email")
+		return fmt.Errorf("unable to set the admin email: %w", err)
+	}
+	pterm.Success.Printfln("Admin email set to %s", email)
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAdminPassword(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "password")
+	if err := os.WriteFile(file, []byte("Sup3r-Secret-Pass\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty")
+	if err := os.WriteFile(empty, []byte("\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		file     string
+		want     string
+		wantErr  bool
+	}{
+		{name: "none"},
+		{name: "flag", password: "Sup3r-Secret-Pass", want: "Sup3r-Secret-Pass"},
+		{name: "file", file: file, want: "Sup3r-Secret-Pass"},
+		{name: "empty file", file: empty, wantErr: true},
+		{name: "missing file", file: filepath.Join(dir, "missing"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := adminPassword(tt.password, tt.file)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("password mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestPasswordWeaknesses(t *testing.T) {
+	tests := []struct {
+		password string
+		want     []string
+	}{
+		{password: "Sup3r-Secret-Pass"},
+		{password: "correct horse battery staple", want: []string{"uses fewer than 3 of lowercase, uppercase, digits and symbols"}},
+		{password: "Sh0rt!", want: []string{"shorter than 12 characters"}},
+		{password: "password", want: []string{
+			"shorter than 12 characters",
+			"uses fewer than 3 of lowercase, uppercase, digits and symbols",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, passwordWeaknesses(tt.password)); d != "" {
+				t.Errorf("weaknesses mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestValidateAdminEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		wantErr bool
+	}{
+		{email: "admin@example.com"},
+		{email: "admin", wantErr: true},
+		{email: "Admin <admin@example.com>", wantErr: true},
+		{email: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			if err := validateAdminEmail(tt.email); tt.wantErr != (err != nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package local
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const secretJWTSignature = "jwt-signature-secret"
+
+// apiKey is a credential of the auth secret which --rotate-api-keys regenerates.
+type apiKey struct {
+	// key of the credential within the auth secret
+	key string
+	// deployments read the credential when they start, so are restarted once it is rotated
+	deployments []string
+}
+
+// apiKeys are the credentials which can be rotated, keyed by the name given to --api-key.
+var apiKeys = map[string]apiKey{
+	"client-secret": {
+		key:         secretClientSecret,
+		deployments: []string{"airbyte-abctl-server"},
+	},
+	"jwt-signature-secret": {
+		key: secretJWTSignature,
+		deployments: []string{
+			"airbyte-abctl-server",
+			"airbyte-abctl-worker",
+			"airbyte-abctl-workload-api-server",
+			"airbyte-abctl-workload-launcher",
+		},
+	},
+}
+
+// apiKeyNames returns the sorted names of the credentials which can be rotated.
+func apiKeyNames() []string {
+	names := make([]string, 0, len(apiKeys))
+	for name := range apiKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectAPIKeys returns the sorted names of the credentials to rotate, the selected ones or, if none are selected,
+// every one the secret holds. A selected credential must be known and held by the secret.
+func selectAPIKeys(secret *corev1.Secret, selected []string) ([]string, error) {
+	if len(selected) == 0 {
+		var names []string
+		for _, name := range apiKeyNames() {
+			if _, ok := secret.Data[apiKeys[name].key]; ok {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("the secret '%s' holds none of the api keys %s", secret.Name, strings.Join(apiKeyNames(), ", "))
+		}
+		return names, nil
+	}
+
+	seen := map[string]bool{}
+	for _, name := range selected {
+		k, ok := apiKeys[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid api key '%s': must be one of %s", name, strings.Join(apiKeyNames(), ", "))
+		}
+		if _, ok := secret.Data[k.key]; !ok {
+			return nil, fmt.Errorf("the secret '%s' has no api key '%s'", secret.Name, name)
+		}
+		seen[name] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// runningSyncs returns the names of the running pods of syncs. Unlike the pods of the airbyte components, they are
+// launched directly by the workload launcher, without a controller owning them.
+func runningSyncs(pods []corev1.Pod) []string {
+	var names []string
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodRunning && len(pod.OwnerReferences) == 0 {
+			names = append(names, pod.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// generateAPIKey returns a new random value for an api key.
+// It is exposed here primarily for testing purposes.
+var generateAPIKey = func() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate an api key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// rotateAPIKeys regenerates the selected api keys of the auth secret, then restarts each deployment reading any of
+// them, once. Returns the new value of each of the rotated keys, which are never logged, also if a restart fails, as
+// the keys were already rotated.
+// As a running sync may fail once the components it talks to restart, nothing is rotated while syncs are running,
+// unless forced.
+func rotateAPIKeys(ctx context.Context, client k8s.Client, selected []string, force bool) (map[string]string, error) {
+	secret, err := client.SecretGet(ctx, airbyteNamespace, airbyteAuthSecretName)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := selectAPIKeys(secret, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := client.PodList(ctx, airbyteNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pods in namespace '%s': %w", airbyteNamespace, err)
+	}
+	if syncs := runningSyncs(pods.Items); len(syncs) > 0 {
+		pterm.Warning.Printfln("%d syncs are running, which may fail once the components reading the api keys restart:\n  %s",
+			len(syncs), strings.Join(syncs, "\n  "))
+		if !force {
+			return nil, fmt.Errorf("unable to rotate the api keys while %d syncs are running, wait for them to complete or pass --force", len(syncs))
+		}
+	}
+
+	rotated := map[string]string{}
+	var deployments []string
+	for _, name := range names {
+		value, err := generateAPIKey()
+		if err != nil {
+			return nil, err
+		}
+		secret.Data[apiKeys[name].key] = []byte(value)
+		rotated[name] = value
+
+		for _, deployment := range apiKeys[name].deployments {
+			if !slices.Contains(deployments, deployment) {
+				deployments = append(deployments, deployment)
+			}
+		}
+	}
+
+	if err := client.SecretCreateOrUpdate(ctx, *secret); err != nil {
+		pterm.Error.Println("Unable to rotate the api keys")
+		return nil, fmt.Errorf("unable to update the secret '%s': %w", secret.Name, err)
+	}
+	pterm.Success.Printfln("Rotated %s", strings.Join(names, ", "))
+
+	for _, deployment := range deployments {
+		pterm.Info.Printfln("Restarting %s", deployment)
+		if err := client.DeploymentRestart(ctx, airbyteNamespace, deployment); err != nil {
+			pterm.Error.Printfln("Unable to restart %s", deployment)
+			return rotated, fmt.Errorf("unable to restart %s: %w", deployment, err)
+		}
+		pterm.Success.Printfln("Restarted %s", deployment)
+	}
+
+	return rotated, nil
+}
+
+// writeAPIKeys writes the rotated api keys to w, as json if requested.
+func writeAPIKeys(w io.Writer, rotated map[string]string, output string) error {
+	if output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rotated)
+	}
+
+	names := make([]string, 0, len(rotated))
+	for name := range rotated {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("Rotated API keys, clients of the Airbyte API must use the new values:")
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n  %s: %s", name, rotated[name])
+	}
+	pterm.Info.WithWriter(w).Println(b.String())
+	return nil
+}
@@ -0,0 +1,257 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// restartRecorder is a k8s client which records the deployments restarted, instead of waiting for their pods to
+// restart, which the fake clientset never does.
+type restartRecorder struct {
+	k8s.Client
+	restarted []string
+	err       error
+}
+
+func (r *restartRecorder) DeploymentRestart(_ context.Context, _, name string) error {
+	r.restarted = append(r.restarted, name)
+	return r.err
+}
+
+func testAuthSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: airbyteAuthSecretName, Namespace: airbyteNamespace},
+		Data: map[string][]byte{
+			secretPassword:     []byte("hunter2"),
+			secretClientID:     []byte("client-id"),
+			secretClientSecret: []byte("client-secret"),
+			secretJWTSignature: []byte("jwt-signature"),
+		},
+	}
+}
+
+func testSyncPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: airbyteNamespace},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+// setGenerateAPIKey makes the generated api keys predictable for the duration of the test.
+func setGenerateAPIKey(t *testing.T) {
+	orig := generateAPIKey
+	n := 0
+	generateAPIKey = func() (string, error) {
+		n++
+		return fmt.Sprintf("new-key-%d", n), nil
+	}
+	t.Cleanup(func() { generateAPIKey = orig })
+}
+
+func TestRotateAPIKeys(t *testing.T) {
+	allDeployments := []string{
+		"airbyte-abctl-server",
+		"airbyte-abctl-worker",
+		"airbyte-abctl-workload-api-server",
+		"airbyte-abctl-workload-launcher",
+	}
+
+	tests := []struct {
+		name           string
+		selected       []string
+		want           map[string]string
+		wantRestarted  []string
+		wantSecretData map[string]string
+	}{
+		{
+			name:          "all",
+			want:          map[string]string{"client-secret": "new-key-1", "jwt-signature-secret": "new-key-2"},
+			wantRestarted: allDeployments,
+			wantSecretData: map[string]string{
+				secretPassword:     "hunter2",
+				secretClientID:     "client-id",
+				secretClientSecret: "new-key-1",
+				secretJWTSignature: "new-key-2",
+			},
+		},
+		{
+			name:          "selected",
+			selected:      []string{"client-secret", "client-secret"},
+			want:          map[string]string{"client-secret": "new-key-1"},
+			wantRestarted: []string{"airbyte-abctl-server"},
+			wantSecretData: map[string]string{
+				secretPassword:     "hunter2",
+				secretClientID:     "client-id",
+				secretClientSecret: "new-key-1",
+				secretJWTSignature: "jwt-signature",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGenerateAPIKey(t)
+			cs := fake.NewSimpleClientset(testAuthSecret())
+			client := &restartRecorder{Client: &k8s.DefaultK8sClient{ClientSet: cs}}
+
+			got, err := rotateAPIKeys(context.Background(), client, tt.selected, false)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("rotated mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantRestarted, client.restarted); d != "" {
+				t.Errorf("restarted mismatch (-want +got):\n%s", d)
+			}
+
+			secret, err := cs.CoreV1().Secrets(airbyteNamespace).Get(context.Background(), airbyteAuthSecretName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			data := map[string]string{}
+			for k, v := range secret.Data {
+				data[k] = string(v)
+			}
+			if d := cmp.Diff(tt.wantSecretData, data); d != "" {
+				t.Errorf("secret mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestRotateAPIKeys_RunningSyncs(t *testing.T) {
+	setGenerateAPIKey(t)
+
+	// the pods of the components are owned by their controllers, only the bare pods of the syncs are running syncs
+	component := testSyncPod("airbyte-abctl-server-123-abc")
+	component.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "airbyte-abctl-server-123"}}
+	completed := testSyncPod("replication-job-1-attempt-0")
+	completed.Status.Phase = corev1.PodSucceeded
+
+	cs := fake.NewSimpleClientset(testAuthSecret(), component, completed, testSyncPod("replication-job-2-attempt-0"))
+	client := &restartRecorder{Client: &k8s.DefaultK8sClient{ClientSet: cs}}
+
+	_, err := rotateAPIKeys(context.Background(), client, nil, false)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if d := cmp.Diff("unable to rotate the api keys while 1 syncs are running, wait for them to complete or pass --force", err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+	if len(client.restarted) > 0 {
+		t.Errorf("expected no restarts, got %v", client.restarted)
+	}
+	secret, err := cs.CoreV1().Secrets(airbyteNamespace).Get(context.Background(), airbyteAuthSecretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("client-secret", string(secret.Data[secretClientSecret])); d != "" {
+		t.Errorf("expected the secret to be unchanged (-want +got):\n%s", d)
+	}
+
+	// forced
+	got, err := rotateAPIKeys(context.Background(), client, []string{"client-secret"}, true)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(map[string]string{"client-secret": "new-key-1"}, got); d != "" {
+		t.Errorf("rotated mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestRotateAPIKeys_RestartErr(t *testing.T) {
+	setGenerateAPIKey(t)
+	errRestart := errors.New("restart failed")
+	cs := fake.NewSimpleClientset(testAuthSecret())
+	client := &restartRecorder{Client: &k8s.DefaultK8sClient{ClientSet: cs}, err: errRestart}
+
+	// the keys were rotated, so are returned along with the error
+	got, err := rotateAPIKeys(context.Background(), client, []string{"client-secret"}, false)
+	if !errors.Is(err, errRestart) {
+		t.Errorf("expected the restart error but got %v", err)
+	}
+	if d := cmp.Diff(map[string]string{"client-secret": "new-key-1"}, got); d != "" {
+		t.Errorf("rotated mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestSelectAPIKeys_Errors(t *testing.T) {
+	secret := testAuthSecret()
+	delete(secret.Data, secretJWTSignature)
+
+	tests := []struct {
+		name     string
+		secret   *corev1.Secret
+		selected []string
+		wantErr  string
+	}{
+		{
+			name:     "unknown",
+			secret:   secret,
+			selected: []string{"password"},
+			wantErr:  "invalid api key 'password': must be one of client-secret, jwt-signature-secret",
+		},
+		{
+			name:     "missing",
+			secret:   secret,
+			selected: []string{"jwt-signature-secret"},
+			wantErr:  "the secret 'airbyte-auth-secrets' has no api key 'jwt-signature-secret'",
+		},
+		{
+			name:    "none",
+			secret:  &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: airbyteAuthSecretName}},
+			wantErr: "the secret 'airbyte-auth-secrets' holds none of the api keys client-secret, jwt-signature-secret",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := selectAPIKeys(tt.secret, tt.selected)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if d := cmp.Diff(tt.wantErr, err.Error()); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestWriteAPIKeys(t *testing.T) {
+	rotated := map[string]string{"jwt-signature-secret": "new-key-2", "client-secret": "new-key-1"}
+
+	b := &bytes.Buffer{}
+	if err := writeAPIKeys(b, rotated, "json"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal("output is not valid json", err)
+	}
+	if d := cmp.Diff(rotated, got); d != "" {
+		t.Errorf("json mismatch (-want +got):\n%s", d)
+	}
+
+	b.Reset()
+	if err := writeAPIKeys(b, rotated, "text"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	// the keys are sorted by name
+	out := b.String()
+	client, jwt := strings.Index(out, "client-secret: new-key-1"), strings.Index(out, "jwt-signature-secret: new-key-2")
+	if client < 0 || jwt < client {
+		t.Errorf("unexpected text output:\n%s", out)
+	}
+}
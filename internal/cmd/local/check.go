@@ -9,10 +9,15 @@ import (
 	"regexp"
 	"runtime"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/service"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/airbytehq/abctl/internal/trace"
 	"github.com/pterm/pterm"
@@ -24,16 +29,57 @@ import (
 // If this value is nil, the default docker-client (as returned from defaultDocker) will be utilized.
 var dockerClient *docker.Docker
 
+// newDockerClient creates the docker-client if dockerClient is nil, exposed for testing purposes.
+var newDockerClient = docker.New
+
+// preflightWarnings records the warnings of the soft pre-flight checks, those which don't necessarily prevent
+// a successful installation. By default they only warn, in strict mode every warning fails the pre-flight check.
+// Every soft check reports through warn, so that strict mode covers all of them.
+type preflightWarnings struct {
+	strict   bool
+	warnings []string
+	// skip are the names of the checks skipped by --skip-preflight, see preflightChecks.
+	skip map[string]bool
+}
+
+// skips returns true if the named check is skipped.
+func (p *preflightWarnings) skips(name string) bool {
+	if p.skip[name] {
+		pterm.Debug.Printfln("Skipping the %s pre-flight check", name)
+		return true
+	}
+	return false
+}
+
+// warn prints the msg of a soft pre-flight check, as an error in strict mode, and records its summary.
+func (p *preflightWarnings) warn(summary, msg string) {
+	if p.strict {
+		pterm.Error.Println(msg)
+	} else {
+		pterm.Warning.Println(msg)
+	}
+	p.warnings = append(p.warnings, summary)
+}
+
+// err returns an ErrPreflightStrict error listing every recorded warning if in strict mode, otherwise nil.
+func (p *preflightWarnings) err() error {
+	if !p.strict || len(p.warnings) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", abctl.ErrPreflightStrict, strings.Join(p.warnings, "; "))
+}
+
 // dockerInstalled checks if docker is installed on the host machine.
 // Returns a nil error if docker was successfully detected, otherwise an error will be returned.  Any error returned
 // is guaranteed to include the ErrDocker error in the error chain.
-func dockerInstalled(ctx context.Context, telClient telemetry.Client) (docker.Version, error) {
+// The soft checks of the docker installation, such as its version, only record their warnings to checks.
+func dockerInstalled(ctx context.Context, telClient telemetry.Client, checks *preflightWarnings) (docker.Version, error) {
 	ctx, span := trace.NewSpan(ctx, "check.dockerInstalled")
 	defer span.End()
 
 	var err error
 	if dockerClient == nil {
-		if dockerClient, err = docker.New(ctx); err != nil {
+		if dockerClient, err = newDockerClient(ctx); err != nil {
 			pterm.Error.Println("Unable to create Docker client")
 			return docker.Version{}, fmt.Errorf("%w: unable to create client: %w", abctl.ErrDocker, err)
 		}
@@ -54,6 +100,11 @@ func dockerInstalled(ctx context.Context, telClient telemetry.Client) (docker.Ve
 	telClient.Attr("docker_arch", version.Arch)
 	telClient.Attr("docker_platform", version.Platform)
 
+	if !checks.skips(preflightDockerVersion) {
+		checkDockerVersion(checks, version.Version)
+	}
+	checkArchitecture(checks, runtime.GOARCH, version.Arch)
+
 	if info, err := dockerClient.Client.Info(ctx); err == nil {
 		telClient.Attr("docker_ncpu", fmt.Sprintf("%d", info.NCPU))
 		telClient.Attr("docker_memtotal", fmt.Sprintf("%d", info.MemTotal))
@@ -66,6 +117,13 @@ func dockerInstalled(ctx context.Context, telClient telemetry.Client) (docker.Ve
 			attribute.String("docker_cgroup_driver", info.CgroupDriver),
 			attribute.String("docker_cgroup_version", info.CgroupVersion),
 		)
+
+		if skew, err := docker.ClockSkew(info, time.Now()); err != nil {
+			pterm.Debug.Printfln("Unable to determine the Docker clock skew: %s", err)
+		} else {
+			span.SetAttributes(attribute.Int64("docker_clock_skew_seconds", int64(skew.Seconds())))
+			checkClockSkew(checks, skew)
+		}
 	}
 
 	pterm.Success.Println(fmt.Sprintf("Found Docker installation: version %s", version.Version))
@@ -73,27 +131,178 @@ func dockerInstalled(ctx context.Context, telClient telemetry.Client) (docker.Ve
 
 }
 
+// maxClockSkew is the largest difference between the host and docker clocks which doesn't trigger a warning.
+const maxClockSkew = time.Minute
+
+// checkClockSkew warns if the skew between the host and docker clocks exceeds maxClockSkew, returning true if it does.
+// A skewed clock can cause certificates and tokens inside the cluster to be treated as expired or not yet valid.
+func checkClockSkew(checks *preflightWarnings, skew time.Duration) bool {
+	if skew <= maxClockSkew {
+		return false
+	}
+
+	checks.warn(fmt.Sprintf("docker clock is skewed by %s", skew.Round(time.Second)),
+		fmt.Sprintf("The Docker clock differs from the host clock by %s.\n"+
+			"This can cause TLS and authentication failures inside the cluster. "+
+			"If Docker is running in a VM (e.g. Docker Desktop), restarting Docker should resync its clock.", skew.Round(time.Second)))
+	return true
+}
+
+// minDockerVersion is the oldest major and minor docker version which doesn't trigger a warning.
+// Older versions lack the cgroup v2 support the cluster node relies on.
+var minDockerVersion = [2]int{20, 10}
+
+// checkDockerVersion warns if the docker version is older than minDockerVersion, returning true if it is.
+// Versions which can't be parsed, e.g. those of a custom build, are assumed to be supported.
+func checkDockerVersion(checks *preflightWarnings, version string) bool {
+	m := regexp.MustCompile(`^(\d+)\.(\d+)`).FindStringSubmatch(version)
+	if m == nil {
+		pterm.Debug.Printfln("Unable to parse the Docker version '%s'", version)
+		return false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	if major > minDockerVersion[0] || (major == minDockerVersion[0] && minor >= minDockerVersion[1]) {
+		return false
+	}
+
+	checks.warn(fmt.Sprintf("docker version %s is unsupported", version),
+		fmt.Sprintf("Docker version %s is older than the oldest supported version %d.%d.\n"+
+			"The cluster may fail to start, upgrading Docker is recommended.", version, minDockerVersion[0], minDockerVersion[1]))
+	return true
+}
+
+// checkArchitecture warns if the host and docker architectures differ, returning true if they do.
+// Differing architectures mean either abctl or docker is running under emulation (e.g. Rosetta or QEMU),
+// which is considerably slower, and some images may not be available for the emulated architecture.
+func checkArchitecture(checks *preflightWarnings, hostArch, dockerArch string) bool {
+	if dockerArch == "" || hostArch == dockerArch {
+		return false
+	}
+
+	checks.warn(fmt.Sprintf("host architecture %s differs from docker architecture %s", hostArch, dockerArch),
+		fmt.Sprintf("abctl is running on %s, but Docker is running on %s.\n"+
+			"One of them is likely running under emulation, which is considerably slower. "+
+			"Installing the build of abctl, or Docker, for the native architecture is recommended.", hostArch, dockerArch))
+	return true
+}
+
+// checkResourcesPreset warns if the docker cpus or memory are below those the preset is intended for,
+// returning true if they are.
+func checkResourcesPreset(checks *preflightWarnings, preset helm.ResourcesPreset, ncpu int, memTotal int64) bool {
+	if ncpu >= preset.MinCPUs && memTotal >= preset.MinMemory {
+		return false
+	}
+
+	checks.warn(fmt.Sprintf("docker resources are below those of the '%s' resources preset", preset.Name),
+		fmt.Sprintf("The '%s' resources preset is intended for at least %d CPUs and %s of memory, "+
+			"but Docker has %d CPUs and %s available.\n"+
+			"Consider a smaller preset, or increasing the resources available to Docker.",
+			preset.Name, preset.MinCPUs, formatGiB(preset.MinMemory), ncpu, formatGiB(memTotal)))
+	return true
+}
+
+// checkHelmTimeout warns if the helm timeout is shorter than the longest readiness timeout of the components,
+// as helm would then time out first, without naming the component which didn't become ready. Returns true if it is.
+func checkHelmTimeout(checks *preflightWarnings, helmTimeout time.Duration, timeouts service.ComponentTimeouts) bool {
+	longest := timeouts.Longest()
+	if helmTimeout <= 0 || helmTimeout >= longest {
+		return false
+	}
+
+	checks.warn(fmt.Sprintf("the helm timeout is shorter than the %s readiness timeout of a component", longest),
+		fmt.Sprintf("The --helm-timeout of %s is shorter than the %s readiness timeout of a component.\n"+
+			"Helm may time out before abctl reports which component didn't become ready, "+
+			"consider a --helm-timeout of at least %s.", helmTimeout, longest, longest))
+	return true
+}
+
+// formatGiB formats the bytes in GiB, e.g. 4.0GiB.
+func formatGiB(bytes int64) string {
+	return fmt.Sprintf("%.1fGiB", float64(bytes)/(1024*1024*1024))
+}
+
+// checkDockerRequired runs the docker pre-flight check if the provider requires docker.
+// The check can be skipped, in which case the docker-client is still created, as the provider needs it to load the
+// images, but its version isn't validated and any other docker related failures will only surface later on.
+func checkDockerRequired(ctx context.Context, telClient telemetry.Client, provider k8s.Provider, skip bool, checks *preflightWarnings) error {
+	if !provider.RequiresDocker() {
+		pterm.Debug.Printfln("Provider '%s' does not require Docker, skipping Docker installation check", provider.Name)
+		return nil
+	}
+	if skip || checks.skips(preflightDocker) {
+		pterm.Debug.Println("Skipping Docker installation check")
+		if dockerClient == nil {
+			var err error
+			if dockerClient, err = newDockerClient(ctx); err != nil {
+				pterm.Error.Println("Unable to create Docker client")
+				return fmt.Errorf("%w: unable to create client: %w", abctl.ErrDocker, err)
+			}
+		}
+		return nil
+	}
+
+	if _, err := dockerInstalled(ctx, telClient, checks); err != nil {
+		pterm.Error.Println("Unable to determine if Docker is installed")
+		return fmt.Errorf("unable to determine docker installation status: %w", err)
+	}
+
+	return nil
+}
+
+// preflightReason returns the anonymized telemetry reason for the error of a failed pre-flight check.
+// Returns false if the err wasn't caused by a pre-flight check.
+func preflightReason(err error) (telemetry.PreflightReason, bool) {
+	switch {
+	case errors.Is(err, abctl.ErrDocker):
+		return telemetry.ReasonDockerDaemonDown, true
+	case errors.Is(err, abctl.ErrPort), errors.Is(err, abctl.ErrPortAbctlCluster):
+		return telemetry.ReasonPortUnavailable, true
+	case errors.Is(err, abctl.ErrClusterNotOwned):
+		return telemetry.ReasonClusterNotOwned, true
+	case errors.Is(err, abctl.ErrPreflightStrict):
+		return telemetry.ReasonStrictWarning, true
+	default:
+		return "", false
+	}
+}
+
+// reportPreflight sends the reason the pre-flight check failed with to telemetry.
+// Only the anonymized reason is sent, never the err itself.
+func reportPreflight(ctx context.Context, telClient telemetry.Client, et telemetry.EventType, err error) {
+	reason, ok := preflightReason(err)
+	if !ok {
+		return
+	}
+	if errTel := telClient.Preflight(ctx, et, reason); errTel != nil {
+		pterm.Debug.Printfln("Unable to send telemetry pre-flight data: %s", errTel)
+	}
+}
+
 // portAvailable returns a nil error if the port is available, or already is use by Airbyte, otherwise returns an error.
 //
 // This function works by attempting to establish a tcp listener on a port.
 // If we can establish a tcp listener on the port, an additional check is made to see if Airbyte may already be
 // bound to that port. If something besides Airbyte is using it, treat this as an inaccessible port.
-func portAvailable(ctx context.Context, port int) error {
+// The availability of a privileged port can't be determined, which only warns unless checks is in strict mode.
+func portAvailable(ctx context.Context, port int, checks *preflightWarnings) error {
 	ctx, span := trace.NewSpan(ctx, "check.portAvailable")
 	defer span.End()
 
 	if port < 1024 {
-		pterm.Warning.Printfln(
-			"Availability of port %d cannot be determined, as this is a privileged port (less than 1024).\n"+
-				"Installation may not complete successfully",
-			port)
-		return nil
+		checks.warn(fmt.Sprintf("availability of privileged port %d cannot be determined", port),
+			fmt.Sprintf("Availability of port %d cannot be determined, as this is a privileged port (less than 1024).\n"+
+				"Installation may not complete successfully", port))
+		return checks.err()
 	}
 
 	// net.Listen doesn't support providing a context
 	lc := &net.ListenConfig{}
 	listener, err := lc.Listen(ctx, "tcp", fmt.Sprintf("localhost:%d", port))
 	if isErrorAddressAlreadyInUse(err) {
+		if cluster, ok := abctlClusterOnPort(ctx, port); ok {
+			return fmt.Errorf("%w: port %d is in use by existing abctl cluster '%s', available at http://localhost:%d", abctl.ErrPortAbctlCluster, port, cluster, port)
+		}
 		return fmt.Errorf("%w: port %d is already in use", abctl.ErrPort, port)
 	}
 	if err != nil {
@@ -107,6 +316,24 @@ func portAvailable(ctx context.Context, port int) error {
 	return nil
 }
 
+// abctlClusterOnPort returns the name of the abctl cluster whose ingress is published on the port, false if the
+// port isn't published by an abctl cluster, or if docker is unavailable.
+func abctlClusterOnPort(ctx context.Context, port int) (string, bool) {
+	if dockerClient == nil {
+		return "", false
+	}
+
+	cluster, ok, err := dockerClient.KindClusterOnPort(ctx, port)
+	if err != nil {
+		pterm.Debug.Printfln("Unable to determine which container is using port %d: %s", port, err)
+		return "", false
+	}
+	if !ok || (cluster != k8s.DefaultProvider.ClusterName && cluster != k8s.TestProvider.ClusterName) {
+		return "", false
+	}
+	return cluster, true
+}
+
 func isErrorAddressAlreadyInUse(err error) bool {
 	var eOsSyscall *os.SyscallError
 	if !errors.As(err, &eOsSyscall) {
@@ -200,3 +427,28 @@ func validateHostFlag(host string) error {
 	}
 	return nil
 }
+
+// checkClusterOwnership verifies the existing cluster is managed by abctl, returning an ErrClusterNotOwned error if not.
+// Clusters created by prior abctl versions predate the cluster marker, any cluster with an existing airbyte namespace
+// is treated as one of those and marked. If adopt is true, an unmarked cluster is marked instead of returning an error.
+func checkClusterOwnership(ctx context.Context, k8sClient k8s.Client, clusterName string, adopt bool) error {
+	marked, err := k8s.ClusterMarked(ctx, k8sClient)
+	if err != nil {
+		return err
+	}
+	if marked {
+		return nil
+	}
+
+	switch {
+	case k8sClient.NamespaceExists(ctx, airbyteNamespace):
+		pterm.Debug.Printfln("Cluster '%s' was created by a prior version of abctl", clusterName)
+	case adopt:
+		pterm.Info.Printfln("Adopting cluster '%s'", clusterName)
+	default:
+		pterm.Error.Printfln("Cluster '%s' was not created by abctl", clusterName)
+		return fmt.Errorf("%w: cluster '%s'", abctl.ErrClusterNotOwned, clusterName)
+	}
+
+	return k8s.MarkCluster(ctx, k8sClient)
+}
@@ -0,0 +1,67 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pterm/pterm"
+
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/trace"
+)
+
+// CheckCmd runs the diagnostics of the dependencies of abctl, without creating anything.
+type CheckCmd struct {
+	Docker CheckDockerCmd `cmd:"" help:"Verify abctl can find and communicate with Docker, describing the Docker engine found."`
+}
+
+// CheckDockerCmd verifies abctl can communicate with docker, the smallest reproduction of a docker which isn't found.
+type CheckDockerCmd struct{}
+
+// diagnoseDocker exists for testing purposes.
+var diagnoseDocker = docker.Diagnose
+
+// Run executes the check docker command, returning an error if docker can't be communicated with.
+func (c *CheckDockerCmd) Run(ctx context.Context) error {
+	ctx, span := trace.NewSpan(ctx, "local check docker")
+	defer span.End()
+
+	diag, err := diagnoseDocker(ctx)
+	if err != nil {
+		if diag.Host != "" {
+			pterm.Error.Printfln("Connected to the Docker host %s, but unable to communicate with the Docker engine", diag.Host)
+		} else {
+			pterm.Error.Println("Unable to find a Docker host which responds")
+		}
+		if diag.Context != "" {
+			pterm.Info.Printfln("The Docker context '%s' was selected with --docker-context", diag.Context)
+		}
+		return err
+	}
+
+	pterm.Success.Printfln("Found Docker at %s", diag.Host)
+	pterm.Info.Println(describeDocker(diag))
+	return nil
+}
+
+// describeDocker returns the description of the docker engine of the diagnosis.
+func describeDocker(diag docker.Diagnosis) string {
+	dockerContext := diag.Context
+	if dockerContext == "" {
+		dockerContext = "(discovered)"
+	}
+
+	lines := []string{
+		"Docker:",
+		"  Context: " + dockerContext,
+		"  Version: " + diag.Version.Version,
+		"  Arch: " + diag.Version.Arch,
+		"  OS: " + diag.OS,
+		"  Platform: " + diag.Version.Platform,
+		fmt.Sprintf("  Rootless: %t", diag.Rootless),
+		fmt.Sprintf("  CPUs: %d", diag.NCPU),
+		"  Memory: " + formatGiB(diag.MemTotal),
+	}
+	return strings.Join(lines, "\n")
+}
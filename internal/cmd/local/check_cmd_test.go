@@ -0,0 +1,61 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker"
+)
+
+func TestCheckDockerCmd(t *testing.T) {
+	orig := diagnoseDocker
+	t.Cleanup(func() { diagnoseDocker = orig })
+
+	t.Run("success", func(t *testing.T) {
+		diagnoseDocker = func(context.Context) (docker.Diagnosis, error) {
+			return docker.Diagnosis{Host: "unix:///var/run/docker.sock"}, nil
+		}
+		if err := (&CheckDockerCmd{}).Run(context.Background()); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		diagnoseDocker = func(context.Context) (docker.Diagnosis, error) {
+			return docker.Diagnosis{}, fmt.Errorf("%w: unable to create docker client", abctl.ErrDocker)
+		}
+		if err := (&CheckDockerCmd{}).Run(context.Background()); !errors.Is(err, abctl.ErrDocker) {
+			t.Errorf("expected ErrDocker but got %v", err)
+		}
+	})
+}
+
+func TestDescribeDocker(t *testing.T) {
+	diag := docker.Diagnosis{
+		Host:     "unix:///var/run/docker.sock",
+		Context:  "colima",
+		Version:  docker.Version{Version: "27.1.1", Arch: "arm64", Platform: "Docker Engine - Community"},
+		OS:       "Ubuntu 24.04 LTS",
+		Rootless: true,
+		NCPU:     4,
+		MemTotal: 8 << 30,
+	}
+
+	want := `Docker:
+  Context: colima
+  Version: 27.1.1
+  Arch: arm64
+  OS: Ubuntu 24.04 LTS
+  Platform: Docker Engine - Community
+  Rootless: true
+  CPUs: 4
+  Memory: 8.0GiB`
+	if d := cmp.Diff(want, describeDocker(diag)); d != "" {
+		t.Errorf("description mismatch (-want +got):\n%s", d)
+	}
+}
@@ -4,19 +4,28 @@ import (
 	"context"
 	"errors"
 	"net"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/docker"
 	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/airbytehq/abctl/internal/service"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/system"
 	"github.com/docker/go-connections/nat"
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestDockerInstalled(t *testing.T) {
@@ -41,7 +50,7 @@ func TestDockerInstalled(t *testing.T) {
 
 	tel := telemetry.MockClient{}
 
-	version, err := dockerInstalled(context.Background(), &tel)
+	version, err := dockerInstalled(context.Background(), &tel, &preflightWarnings{})
 	if err != nil {
 		t.Error("unexpected error:", err)
 	}
@@ -70,12 +79,445 @@ func TestDockerInstalled_Error(t *testing.T) {
 		},
 	}
 
-	_, err := dockerInstalled(context.Background(), &telemetry.MockClient{})
+	_, err := dockerInstalled(context.Background(), &telemetry.MockClient{}, &preflightWarnings{})
 	if err == nil {
 		t.Error("unexpected error:", err)
 	}
 }
 
+func TestCheckClockSkew(t *testing.T) {
+	tests := []struct {
+		skew time.Duration
+		want bool
+	}{
+		{skew: 0, want: false},
+		{skew: 30 * time.Second, want: false},
+		{skew: maxClockSkew, want: false},
+		{skew: maxClockSkew + time.Second, want: true},
+		{skew: time.Hour, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.skew.String(), func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				checks := &preflightWarnings{strict: strict}
+				if d := cmp.Diff(tt.want, checkClockSkew(checks, tt.skew)); d != "" {
+					t.Errorf("warning mismatch (-want +got):\n%s", d)
+				}
+				assertPreflight(t, checks, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDockerVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{version: "27.4.0"},
+		{version: "20.10.0"},
+		{version: "20.10.24+dfsg1"},
+		{version: "26.1.1-desktop"},
+		{version: "20.9.1", want: true},
+		{version: "19.03.15", want: true},
+		// unparseable versions are assumed to be supported
+		{version: "dev"},
+		{version: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				checks := &preflightWarnings{strict: strict}
+				if d := cmp.Diff(tt.want, checkDockerVersion(checks, tt.version)); d != "" {
+					t.Errorf("warning mismatch (-want +got):\n%s", d)
+				}
+				assertPreflight(t, checks, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckArchitecture(t *testing.T) {
+	tests := []struct {
+		name       string
+		hostArch   string
+		dockerArch string
+		want       bool
+	}{
+		{name: "native", hostArch: "arm64", dockerArch: "arm64"},
+		{name: "unknown docker arch", hostArch: "arm64"},
+		{name: "emulated docker", hostArch: "arm64", dockerArch: "amd64", want: true},
+		{name: "emulated abctl", hostArch: "amd64", dockerArch: "arm64", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				checks := &preflightWarnings{strict: strict}
+				if d := cmp.Diff(tt.want, checkArchitecture(checks, tt.hostArch, tt.dockerArch)); d != "" {
+					t.Errorf("warning mismatch (-want +got):\n%s", d)
+				}
+				assertPreflight(t, checks, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckResourcesPreset(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	large := helm.ResourcesPresets["large"]
+
+	tests := []struct {
+		name     string
+		ncpu     int
+		memTotal int64
+		want     bool
+	}{
+		{name: "sufficient", ncpu: 8, memTotal: 16 * gib},
+		{name: "more than sufficient", ncpu: 16, memTotal: 64 * gib},
+		{name: "insufficient memory", ncpu: 8, memTotal: 4 * gib, want: true},
+		{name: "insufficient cpus", ncpu: 4, memTotal: 16 * gib, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				checks := &preflightWarnings{strict: strict}
+				if d := cmp.Diff(tt.want, checkResourcesPreset(checks, large, tt.ncpu, tt.memTotal)); d != "" {
+					t.Errorf("warning mismatch (-want +got):\n%s", d)
+				}
+				assertPreflight(t, checks, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckHelmTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		helmTimeout time.Duration
+		timeouts    service.ComponentTimeouts
+		want        bool
+	}{
+		{name: "default", timeouts: service.ComponentTimeouts{}},
+		{name: "longer than the readiness timeouts", helmTimeout: 30 * time.Minute},
+		{name: "shorter than the database timeout", helmTimeout: 15 * time.Minute, want: true},
+		{
+			name:        "shorter than a component override",
+			helmTimeout: 30 * time.Minute,
+			timeouts:    service.ComponentTimeouts{Overrides: map[string]time.Duration{"server": 45 * time.Minute}},
+			want:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				checks := &preflightWarnings{strict: strict}
+				if d := cmp.Diff(tt.want, checkHelmTimeout(checks, tt.helmTimeout, tt.timeouts)); d != "" {
+					t.Errorf("warning mismatch (-want +got):\n%s", d)
+				}
+				assertPreflight(t, checks, tt.want)
+			}
+		})
+	}
+}
+
+// assertPreflight verifies that a check which warned fails the pre-flight check in strict mode, and only then.
+func assertPreflight(t *testing.T, checks *preflightWarnings, warned bool) {
+	t.Helper()
+	err := checks.err()
+	if checks.strict && warned {
+		if !errors.Is(err, abctl.ErrPreflightStrict) {
+			t.Errorf("strict: expected ErrPreflightStrict but got %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf("strict %t: unexpected error: %v", checks.strict, err)
+	}
+}
+
+func TestPreflightWarnings_Err(t *testing.T) {
+	checks := &preflightWarnings{strict: true}
+	checkClockSkew(checks, time.Hour)
+	checkDockerVersion(checks, "19.03.15")
+
+	err := checks.err()
+	if !errors.Is(err, abctl.ErrPreflightStrict) {
+		t.Fatalf("expected ErrPreflightStrict but got %v", err)
+	}
+	// every warning is listed, not only the first
+	want := "pre-flight check failed in strict mode: docker clock is skewed by 1h0m0s; docker version 19.03.15 is unsupported"
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestDockerInstalled_Strict(t *testing.T) {
+	t.Cleanup(func() {
+		dockerClient = nil
+	})
+
+	dockerClient = &docker.Docker{
+		Client: dockertest.MockClient{
+			FnServerVersion: func(ctx context.Context) (types.Version, error) {
+				return types.Version{Version: "19.03.15", Arch: runtime.GOARCH}, nil
+			},
+			FnInfo: func(ctx context.Context) (system.Info, error) {
+				return system.Info{SystemTime: time.Now().Add(time.Hour).Format(time.RFC3339Nano)}, nil
+			},
+		},
+	}
+
+	for _, strict := range []bool{false, true} {
+		checks := &preflightWarnings{strict: strict}
+		// the soft checks never fail the docker check itself
+		if _, err := dockerInstalled(context.Background(), &telemetry.MockClient{}, checks); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if d := cmp.Diff([]string{"docker version 19.03.15 is unsupported", "docker clock is skewed by 1h0m0s"}, checks.warnings); d != "" {
+			t.Errorf("warnings mismatch (-want +got):\n%s", d)
+		}
+		assertPreflight(t, checks, true)
+	}
+}
+
+func TestDockerInstalled_SkipPreflight(t *testing.T) {
+	t.Cleanup(func() {
+		dockerClient = nil
+	})
+
+	dockerClient = &docker.Docker{
+		Client: dockertest.MockClient{
+			FnServerVersion: func(ctx context.Context) (types.Version, error) {
+				return types.Version{Version: "19.03.15", Arch: runtime.GOARCH}, nil
+			},
+			FnInfo: func(ctx context.Context) (system.Info, error) {
+				return system.Info{SystemTime: time.Now().Add(time.Hour).Format(time.RFC3339Nano)}, nil
+			},
+		},
+	}
+
+	// only the docker version check is skipped, the clock skew is still enforced
+	checks := &preflightWarnings{strict: true, skip: map[string]bool{preflightDockerVersion: true}}
+	if _, err := dockerInstalled(context.Background(), &telemetry.MockClient{}, checks); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if d := cmp.Diff([]string{"docker clock is skewed by 1h0m0s"}, checks.warnings); d != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", d)
+	}
+	assertPreflight(t, checks, true)
+}
+
+func TestPortAvailable_Privileged(t *testing.T) {
+	if err := portAvailable(context.Background(), 80, &preflightWarnings{}); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	err := portAvailable(context.Background(), 80, &preflightWarnings{strict: true})
+	if !errors.Is(err, abctl.ErrPreflightStrict) {
+		t.Errorf("expected ErrPreflightStrict but got %v", err)
+	}
+}
+
+func TestCheckDockerRequired(t *testing.T) {
+	t.Cleanup(func() {
+		dockerClient = nil
+	})
+
+	// docker is unavailable, the check fails whenever it is run
+	dockerClient = &docker.Docker{
+		Client: dockertest.MockClient{
+			FnServerVersion: func(ctx context.Context) (types.Version, error) {
+				return types.Version{}, errors.New("test")
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		provider k8s.Provider
+		skip     bool
+		// skipPreflight are the names of the checks skipped by --skip-preflight
+		skipPreflight []string
+		wantErr       bool
+	}{
+		{
+			name:     "kind",
+			provider: k8s.DefaultProvider,
+			wantErr:  true,
+		},
+		{
+			name:     "kind skipped",
+			provider: k8s.DefaultProvider,
+			skip:     true,
+		},
+		{
+			name:          "kind skipped by name",
+			provider:      k8s.DefaultProvider,
+			skipPreflight: []string{preflightDocker},
+		},
+		{
+			name:          "kind other check skipped",
+			provider:      k8s.DefaultProvider,
+			skipPreflight: []string{preflightDisk},
+			wantErr:       true,
+		},
+		{
+			name:     "external",
+			provider: k8s.Provider{Name: k8s.External},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skips, err := parsePreflightSkips(tt.skipPreflight)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = checkDockerRequired(context.Background(), &telemetry.MockClient{}, tt.provider, tt.skip, &preflightWarnings{skip: skips})
+			if tt.wantErr {
+				if !errors.Is(err, abctl.ErrDocker) {
+					t.Errorf("expected ErrDocker but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Error("unexpected error:", err)
+			}
+		})
+	}
+}
+
+func TestCheckDockerRequired_SkipCreatesClient(t *testing.T) {
+	origNew := newDockerClient
+	t.Cleanup(func() {
+		dockerClient = nil
+		newDockerClient = origNew
+	})
+
+	// the version of docker is never validated when the check is skipped
+	mock := &docker.Docker{
+		Client: dockertest.MockClient{
+			FnServerVersion: func(ctx context.Context) (types.Version, error) {
+				t.Error("unexpected docker version check")
+				return types.Version{}, errors.New("test")
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		provider   k8s.Provider
+		newErr     error
+		wantClient bool
+		wantErr    error
+	}{
+		{
+			// kind loads the images with the docker-client, so it must exist
+			name:       "kind",
+			provider:   k8s.DefaultProvider,
+			wantClient: true,
+		},
+		{
+			name:     "kind without docker",
+			provider: k8s.DefaultProvider,
+			newErr:   errors.New("test"),
+			wantErr:  abctl.ErrDocker,
+		},
+		{
+			name:     "external",
+			provider: k8s.Provider{Name: k8s.External},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerClient = nil
+			newDockerClient = func(ctx context.Context) (*docker.Docker, error) {
+				if tt.newErr != nil {
+					return nil, tt.newErr
+				}
+				return mock, nil
+			}
+
+			err := checkDockerRequired(context.Background(), &telemetry.MockClient{}, tt.provider, true, &preflightWarnings{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if d := cmp.Diff(tt.wantClient, dockerClient != nil); d != "" {
+				t.Errorf("docker-client mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestReportPreflight(t *testing.T) {
+	t.Cleanup(func() {
+		dockerClient = nil
+	})
+
+	// docker is unavailable, the docker check always fails
+	dockerClient = &docker.Docker{
+		Client: dockertest.MockClient{
+			FnServerVersion: func(ctx context.Context) (types.Version, error) {
+				return types.Version{}, errors.New("test")
+			},
+			FnContainerList: func(ctx context.Context, options container.ListOptions) ([]types.Container, error) {
+				return nil, errors.New("test")
+			},
+		},
+	}
+	dockerErr := checkDockerRequired(context.Background(), &telemetry.MockClient{}, k8s.DefaultProvider, false, &preflightWarnings{})
+
+	// occupy a port, the port check fails for it
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal("unable to create listener", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	portErr := portAvailable(context.Background(), port(listener.Addr().String()), &preflightWarnings{})
+
+	ownershipErr := checkClusterOwnership(context.Background(), &k8stest.MockClient{
+		FnConfigMapGet: func(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+			return nil, k8serrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, name)
+		},
+		FnNamespaceExists: func(ctx context.Context, namespace string) bool {
+			return false
+		},
+	}, "test", false)
+
+	strictErr := portAvailable(context.Background(), 80, &preflightWarnings{strict: true})
+
+	tests := []struct {
+		name string
+		err  error
+		want []telemetry.PreflightReason
+	}{
+		{name: "docker", err: dockerErr, want: []telemetry.PreflightReason{telemetry.ReasonDockerDaemonDown}},
+		{name: "port", err: portErr, want: []telemetry.PreflightReason{telemetry.ReasonPortUnavailable}},
+		{name: "cluster ownership", err: ownershipErr, want: []telemetry.PreflightReason{telemetry.ReasonClusterNotOwned}},
+		{name: "strict", err: strictErr, want: []telemetry.PreflightReason{telemetry.ReasonStrictWarning}},
+		{name: "not a pre-flight error", err: errors.New("test")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil {
+				t.Fatal("expected the pre-flight check to fail")
+			}
+			tel := &telemetry.MockClient{}
+			reportPreflight(context.Background(), tel, telemetry.Install, tt.err)
+			if d := cmp.Diff(tt.want, tel.Preflights()); d != "" {
+				t.Errorf("reasons mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
 func TestPortAvailable_Available(t *testing.T) {
 	// spin up a listener to find a port and then shut it down to ensure that port is available
 	listener, err := net.Listen("tcp", ":0")
@@ -87,7 +529,7 @@ func TestPortAvailable_Available(t *testing.T) {
 		t.Fatal("unable to close listener", err)
 	}
 
-	err = portAvailable(context.Background(), p)
+	err = portAvailable(context.Background(), p, &preflightWarnings{})
 	if err != nil {
 		t.Error("portAvailable returned unexpected error", err)
 	}
@@ -102,7 +544,7 @@ func TestPortAvailable_Unavailable(t *testing.T) {
 	defer listener.Close()
 	p := port(listener.Addr().String())
 
-	err = portAvailable(context.Background(), p)
+	err = portAvailable(context.Background(), p, &preflightWarnings{})
 	// expecting an error
 	if err == nil {
 		t.Error("portAvailable should have returned an error")
@@ -112,6 +554,62 @@ func TestPortAvailable_Unavailable(t *testing.T) {
 	}
 }
 
+func TestPortAvailable_AbctlCluster(t *testing.T) {
+	t.Cleanup(func() {
+		dockerClient = nil
+	})
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal("unable to create listener", err)
+	}
+	defer listener.Close()
+	p := port(listener.Addr().String())
+
+	// the node container of a kind cluster, publishing the port
+	nodeOf := func(cluster string) types.ContainerJSON {
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				State: &types.ContainerState{Running: true},
+				HostConfig: &container.HostConfig{PortBindings: nat.PortMap{
+					"80/tcp": {{HostIP: "0.0.0.0", HostPort: strconv.Itoa(p)}},
+				}},
+			},
+			Config: &container.Config{Labels: map[string]string{docker.KindClusterLabel: cluster}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		cluster string
+		want    error
+	}{
+		{name: "abctl cluster", cluster: k8s.DefaultProvider.ClusterName, want: abctl.ErrPortAbctlCluster},
+		{name: "other kind cluster", cluster: "kind", want: abctl.ErrPort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerClient = &docker.Docker{Client: dockertest.MockClient{
+				FnContainerList: func(ctx context.Context, options container.ListOptions) ([]types.Container, error) {
+					return []types.Container{{ID: "node"}}, nil
+				},
+				FnContainerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+					return nodeOf(tt.cluster), nil
+				},
+			}}
+
+			err := portAvailable(context.Background(), p, &preflightWarnings{})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v but got %v", tt.want, err)
+			}
+			if tt.want == abctl.ErrPortAbctlCluster && !strings.Contains(err.Error(), "'"+tt.cluster+"'") {
+				t.Errorf("error should name the cluster: %s", err)
+			}
+		})
+	}
+}
+
 func TestGetPort_Found(t *testing.T) {
 	t.Cleanup(func() {
 		dockerClient = nil
@@ -300,3 +798,75 @@ func port(s string) int {
 	p, _ := strconv.Atoi(vals[len(vals)-1])
 	return p
 }
+
+func TestCheckClusterOwnership(t *testing.T) {
+	notFound := k8serrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, k8s.ClusterMarkerName)
+
+	tests := []struct {
+		name            string
+		marked          bool
+		airbyteNSExists bool
+		adopt           bool
+		expErr          error
+		expMarked       bool
+	}{
+		{
+			name:   "marked",
+			marked: true,
+		},
+		{
+			name:   "unmarked",
+			expErr: abctl.ErrClusterNotOwned,
+		},
+		{
+			name:      "unmarked adopt",
+			adopt:     true,
+			expMarked: true,
+		},
+		{
+			name:            "unmarked prior abctl version",
+			airbyteNSExists: true,
+			expMarked:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markerCreated := false
+			k8sClient := &k8stest.MockClient{
+				FnConfigMapGet: func(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+					if d := cmp.Diff(k8s.ClusterMarkerNamespace, namespace); d != "" {
+						t.Error("unexpected namespace", d)
+					}
+					if tt.marked {
+						return &corev1.ConfigMap{}, nil
+					}
+					return nil, notFound
+				},
+				FnConfigMapCreate: func(ctx context.Context, configMap *corev1.ConfigMap) error {
+					if d := cmp.Diff(k8s.ClusterMarkerName, configMap.Name); d != "" {
+						t.Error("unexpected marker name", d)
+					}
+					markerCreated = true
+					return nil
+				},
+				FnNamespaceExists: func(ctx context.Context, namespace string) bool {
+					return tt.airbyteNSExists && namespace == airbyteNamespace
+				},
+			}
+
+			err := checkClusterOwnership(context.Background(), k8sClient, "airbyte-abctl", tt.adopt)
+			if tt.expErr != nil {
+				if !errors.Is(err, tt.expErr) {
+					t.Errorf("expected %v but got %v", tt.expErr, err)
+				}
+			} else if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if d := cmp.Diff(tt.expMarked, markerCreated); d != "" {
+				t.Error("unexpected marker creation", d)
+			}
+		})
+	}
+}
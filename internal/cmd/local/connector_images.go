@@ -0,0 +1,64 @@
+package local
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/docker"
+)
+
+// connectorImages returns the validated connector images from the refs and the optional file, with duplicates removed.
+// The file contains one image per line, blank lines and lines starting with # are ignored.
+func connectorImages(refs []string, file string) ([]string, error) {
+	fileRefs, err := readImagesFile(file, "connector images")
+	if err != nil {
+		return nil, err
+	}
+	all := append(append([]string{}, refs...), fileRefs...)
+
+	seen := map[string]struct{}{}
+	var images []string
+	for _, ref := range all {
+		if err := docker.ValidateImageRef(ref); err != nil {
+			return nil, err
+		}
+		if _, ok := seen[ref]; ok {
+			continue
+		}
+		seen[ref] = struct{}{}
+		images = append(images, ref)
+	}
+
+	return images, nil
+}
+
+// readImagesFile returns the images of the file, one per line, ignoring blank lines and lines starting with #.
+// No images are returned if the file is empty. The what names the file in errors.
+func readImagesFile(file, what string) ([]string, error) {
+	if file == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s file: %w", what, err)
+	}
+	defer f.Close()
+
+	var images []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		images = append(images, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %s file: %w", what, err)
+	}
+
+	return images, nil
+}
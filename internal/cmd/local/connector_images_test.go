@@ -0,0 +1,74 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConnectorImages(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "connectors.txt")
+	content := `# sources
+airbyte/source-postgres:3.6.0
+
+  airbyte/source-faker:6.2.0
+# destinations
+airbyte/destination-postgres:2.0.0
+airbyte/source-postgres:3.6.0
+`
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		refs    []string
+		file    string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "flags",
+			refs: []string{"airbyte/source-postgres:3.6.0", "ghcr.io/airbytehq/source-faker:6.2.0"},
+			want: []string{"airbyte/source-postgres:3.6.0", "ghcr.io/airbytehq/source-faker:6.2.0"},
+		},
+		{
+			name: "file",
+			file: file,
+			want: []string{"airbyte/source-postgres:3.6.0", "airbyte/source-faker:6.2.0", "airbyte/destination-postgres:2.0.0"},
+		},
+		{
+			name: "flags and file",
+			refs: []string{"airbyte/source-s3:4.0.0", "airbyte/source-faker:6.2.0"},
+			file: file,
+			want: []string{"airbyte/source-s3:4.0.0", "airbyte/source-faker:6.2.0", "airbyte/source-postgres:3.6.0", "airbyte/destination-postgres:2.0.0"},
+		},
+		{
+			name:    "invalid ref",
+			refs:    []string{"airbyte/source postgres"},
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			file:    filepath.Join(t.TempDir(), "missing.txt"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := connectorImages(tt.refs, tt.file)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("images mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -2,34 +2,117 @@ package local
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
 
 	"github.com/airbytehq/abctl/internal/airbyte"
+	"github.com/airbytehq/abctl/internal/common"
 	"github.com/airbytehq/abctl/internal/k8s"
 	"github.com/airbytehq/abctl/internal/service"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/pterm/pterm"
+	"github.com/skip2/go-qrcode"
 	"go.opencensus.io/trace"
 )
 
 const (
-	airbyteAuthSecretName = "airbyte-auth-secrets"
+	airbyteAuthSecretName = common.AirbyteAuthSecretName
 	airbyteNamespace      = "airbyte-abctl"
 
-	secretPassword     = "instance-admin-password"
+	secretPassword     = common.AirbyteAuthSecretPassword
 	secretClientID     = "instance-admin-client-id"
 	secretClientSecret = "instance-admin-client-secret"
 )
 
 type CredentialsCmd struct {
-	Email    string `help:"Specify a new email address to use for authentication."`
-	Password string `help:"Specify a new password to use for authentication."`
+	APIKey        []string `help:"With --rotate-api-keys, only rotate this api key (client-secret or jwt-signature-secret). May be specified multiple times."`
+	Email         string   `help:"Specify a new email address to use for authentication."`
+	Field         string   `help:"Print only the value of a single credential field (email, password or url)."`
+	Force         bool     `help:"With --rotate-api-keys, rotate the api keys even while syncs are running, which may fail them."`
+	Host          string   `help:"Host other devices reach this machine at, used by --qr and --show-url (e.g. the host given to 'local install --host'). Defaults to the address of this machine on the local network."`
+	Output        string   `default:"text" help:"Output format of the credentials (text or json)."`
+	Password      string   `help:"Specify a new password to use for authentication."`
+	QR            bool     `help:"Display a QR code of the URL other devices on the network can open Airbyte at, instead of the credentials. Only displayed in a terminal."`
+	RotateAPIKeys bool     `help:"Regenerate the api keys of the Airbyte API, restart the components reading them and print the new values, instead of the credentials. Refuses to while syncs are running, unless --force."`
+	ShowURL       bool     `help:"Print only the URL other devices on the network can open Airbyte at, instead of the credentials."`
+}
+
+// credentials are the login credentials printed by the credentials command.
+type credentials struct {
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	URL          string `json:"url"`
+	ClientID     string `json:"-"`
+	ClientSecret string `json:"-"`
+}
+
+// credentialFields are the fields which can be selected with the --field flag.
+var credentialFields = map[string]func(credentials) string{
+	"email":    func(c credentials) string { return c.Email },
+	"password": func(c credentials) string { return c.Password },
+	"url":      func(c credentials) string { return c.URL },
+}
+
+// validate ensures the output and field flags contain supported values.
+func (cc *CredentialsCmd) validate() error {
+	if cc.Output != "text" && cc.Output != "json" {
+		return fmt.Errorf("invalid output format '%s': must be one of text or json", cc.Output)
+	}
+	if cc.Field != "" {
+		if _, ok := credentialFields[cc.Field]; !ok {
+			return fmt.Errorf("invalid field '%s': must be one of email, password or url", cc.Field)
+		}
+	}
+	if cc.QR && cc.ShowURL {
+		return fmt.Errorf("--qr and --show-url can't be used together")
+	}
+	if (cc.QR || cc.ShowURL) && (cc.Field != "" || cc.Output == "json") {
+		return fmt.Errorf("--qr and --show-url can't be used with --field or --output json")
+	}
+	if cc.RotateAPIKeys && (cc.Field != "" || cc.QR || cc.ShowURL || cc.Email != "" || cc.Password != "") {
+		return fmt.Errorf("--rotate-api-keys can't be used with --field, --qr, --show-url, --email or --password")
+	}
+	if !cc.RotateAPIKeys && (len(cc.APIKey) > 0 || cc.Force) {
+		return fmt.Errorf("--api-key and --force require --rotate-api-keys")
+	}
+	return nil
+}
+
+// machineReadable returns true if the credentials are being written for consumption by another program.
+func (cc *CredentialsCmd) machineReadable() bool {
+	return cc.Field != "" || cc.Output == "json" || cc.ShowURL
+}
+
+// rotate rotates the api keys, then writes their new values to w.
+func (cc *CredentialsCmd) rotate(ctx context.Context, k8sClient k8s.Client, w io.Writer) error {
+	rotated, err := rotateAPIKeys(ctx, k8sClient, cc.APIKey, cc.Force)
+	// the keys are rotated even if a component couldn't be restarted, so their new values must still be written
+	if len(rotated) > 0 {
+		if err := writeAPIKeys(w, rotated, cc.Output); err != nil {
+			return err
+		}
+	}
+	return err
 }
 
 func (cc *CredentialsCmd) Run(ctx context.Context, provider k8s.Provider, telClient telemetry.Client) error {
 	ctx, span := trace.StartSpan(ctx, "local credentials")
 	defer span.End()
 
+	if err := cc.validate(); err != nil {
+		return err
+	}
+
+	// only the credentials themselves may be written to stdout, send everything else to stderr
+	if cc.machineReadable() {
+		pterm.SetDefaultOutput(os.Stderr)
+		defer pterm.SetDefaultOutput(os.Stdout)
+	}
+
 	spinner := &pterm.DefaultSpinner
 
 	return telClient.Wrap(ctx, telemetry.Credentials, func() error {
@@ -39,6 +122,10 @@ func (cc *CredentialsCmd) Run(ctx context.Context, provider k8s.Provider, telCli
 			return nil
 		}
 
+		if cc.RotateAPIKeys {
+			return cc.rotate(ctx, k8sClient, os.Stdout)
+		}
+
 		secret, err := k8sClient.SecretGet(ctx, airbyteNamespace, airbyteAuthSecretName)
 		if err != nil {
 			return err
@@ -52,11 +139,15 @@ func (cc *CredentialsCmd) Run(ctx context.Context, provider k8s.Provider, telCli
 			return err
 		}
 
-		abAPI := airbyte.New(fmt.Sprintf("http://localhost:%d", port), clientId, clientSecret)
-
 		if cc.Email != "" {
 			pterm.Info.Println("Updating email for authentication")
-			if err := abAPI.SetOrgEmail(ctx, cc.Email); err != nil {
+			abAPI, closeAPI, err := newServerAPI(ctx, k8sClient, clientId, clientSecret)
+			if err != nil {
+				return err
+			}
+			err = abAPI.SetOrgEmail(ctx, cc.Email)
+			closeAPI()
+			if err != nil {
 				pterm.Error.Println("Unable to update the email address")
 				return fmt.Errorf("unable to udpate the email address: %w", err)
 			}
@@ -86,21 +177,142 @@ func (cc *CredentialsCmd) Run(ctx context.Context, provider k8s.Provider, telCli
 			spinner.Success("Restarted airbyte-abctl-server")
 		}
 
+		if cc.QR || cc.ShowURL {
+			return cc.writeURL(os.Stdout, shareURL(cc.Host, port), isTerminal(os.Stdout))
+		}
+
+		// connected to only now, as a password update restarts the server
+		abAPI, closeAPI, err := newServerAPI(ctx, k8sClient, clientId, clientSecret)
+		if err != nil {
+			return err
+		}
 		orgEmail, err := abAPI.GetOrgEmail(ctx)
+		closeAPI()
 		if err != nil {
 			pterm.Error.Println("Unable to determine organization email")
 			return fmt.Errorf("unable to determine organization email: %w", err)
 		}
-		if orgEmail == "" {
-			orgEmail = "[not set]"
+		creds := credentials{
+			Email:        orgEmail,
+			Password:     string(secret.Data[secretPassword]),
+			URL:          fmt.Sprintf("http://localhost:%d", port),
+			ClientID:     clientId,
+			ClientSecret: clientSecret,
+		}
+
+		if !cc.machineReadable() {
+			pterm.Success.Println(fmt.Sprintf("Retrieving your credentials from '%s'", secret.Name))
 		}
+		return cc.writeCredentials(os.Stdout, creds)
+	})
+}
+
+// airbyteServerPort is the port of the Airbyte server pod serving the Airbyte API.
+const airbyteServerPort = "8001"
+
+// newServerAPI returns an Airbyte API client connected to a pod of the Airbyte server through a port-forward, and
+// the func closing the port-forward. The API is reached directly, rather than through the ingress, so it doesn't
+// depend on how the ingress is served (e.g. its host or ingress class).
+func newServerAPI(ctx context.Context, k8sClient k8s.Client, clientID, clientSecret string) (*airbyte.Airbyte, func(), error) {
+	pod, err := k8s.ServicePod(ctx, k8sClient, airbyteNamespace, fmt.Sprintf("%s-airbyte-server-svc", common.AirbyteChartRelease))
+	if err != nil {
+		pterm.Error.Println("Unable to find the Airbyte server")
+		return nil, nil, fmt.Errorf("unable to find the airbyte server: %w", err)
+	}
 
-		pterm.Success.Println(fmt.Sprintf("Retrieving your credentials from '%s'", secret.Name))
-		pterm.Info.Println(fmt.Sprintf(`Credentials:
+	fw, err := k8s.PortForward(ctx, k8sClient, airbyteNamespace, pod, []string{airbyteServerPort})
+	if err != nil {
+		pterm.Error.Println("Unable to connect to the Airbyte server")
+		return nil, nil, err
+	}
+
+	abAPI := airbyte.New("http://"+fw.Addr(airbyteServerPort), clientID, clientSecret)
+	return abAPI, func() { _ = fw.Close() }, nil
+}
+
+// writeCredentials writes the creds to w in the format requested by the output and field flags.
+func (cc *CredentialsCmd) writeCredentials(w io.Writer, creds credentials) error {
+	if cc.Field != "" {
+		_, err := fmt.Fprintln(w, credentialFields[cc.Field](creds))
+		return err
+	}
+
+	if cc.Output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(creds)
+	}
+
+	email := creds.Email
+	if email == "" {
+		email = "[not set]"
+	}
+	pterm.Info.WithWriter(w).Println(fmt.Sprintf(`Credentials:
   Email: %s
   Password: %s
   Client-Id: %s
-  Client-Secret: %s`, orgEmail, secret.Data[secretPassword], clientId, clientSecret))
-		return nil
-	})
+  Client-Secret: %s`, email, creds.Password, creds.ClientID, creds.ClientSecret))
+	return nil
+}
+
+// writeURL writes the url to w, as a QR code if requested and w is a terminal. A QR code written anywhere else,
+// e.g. to a file or a pipe, couldn't be scanned, so only the url is written instead.
+func (cc *CredentialsCmd) writeURL(w io.Writer, url string, terminal bool) error {
+	if cc.ShowURL {
+		_, err := fmt.Fprintln(w, url)
+		return err
+	}
+
+	if !terminal {
+		pterm.Warning.Println("Not writing to a terminal, skipping the QR code")
+		_, err := fmt.Fprintln(w, url)
+		return err
+	}
+
+	code, err := qrcode.New(url, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("unable to encode '%s' as a QR code: %w", url, err)
+	}
+	// light modules are drawn and dark modules left blank, which scans correctly on a terminal with a dark background
+	if _, err := fmt.Fprint(w, code.ToSmallString(false)); err != nil {
+		return err
+	}
+	pterm.Info.WithWriter(w).Println(fmt.Sprintf("Scan the QR code to open %s", url))
+	return nil
+}
+
+// lanAddr returns the address of this machine on the local network.
+// It is exposed here primarily for testing purposes.
+var lanAddr = defaultLANAddr
+
+// defaultLANAddr returns the address of the network interface used to reach other hosts.
+// No packets are sent, connecting a UDP socket only selects the route.
+func defaultLANAddr() (string, error) {
+	conn, err := net.Dial("udp", "192.0.2.1:80")
+	if err != nil {
+		return "", fmt.Errorf("unable to determine the local network address: %w", err)
+	}
+	defer conn.Close()
+
+	addr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok || addr.IP.IsLoopback() {
+		return "", fmt.Errorf("unable to determine the local network address: no network interface found")
+	}
+	return addr.IP.String(), nil
+}
+
+// shareURL returns the URL other devices on the network can open Airbyte at: the host if specified, otherwise
+// the address of this machine on the local network. If neither is available, the URL only works on this machine.
+func shareURL(host string, port int) string {
+	if host == "" {
+		addr, err := lanAddr()
+		if err != nil {
+			pterm.Debug.Println(err)
+			pterm.Warning.Println("Unable to determine the address of this machine on the local network, " +
+				"the URL is only reachable from this machine. Specify the address with --host")
+			addr = "localhost"
+		}
+		host = addr
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
 }
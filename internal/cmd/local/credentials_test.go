@@ -0,0 +1,233 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testCreds = credentials{
+	Email:        "user@example.com",
+	Password:     "hunter2",
+	URL:          "http://localhost:8000",
+	ClientID:     "client-id",
+	ClientSecret: "client-secret",
+}
+
+func TestCredentialsCmd_JSON(t *testing.T) {
+	cmd := &CredentialsCmd{Output: "json"}
+	if err := cmd.validate(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	b := &bytes.Buffer{}
+	if err := cmd.writeCredentials(b, testCreds); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal("output is not valid json", err)
+	}
+
+	exp := map[string]any{
+		"email":    "user@example.com",
+		"password": "hunter2",
+		"url":      "http://localhost:8000",
+	}
+	if d := cmp.Diff(exp, got); d != "" {
+		t.Errorf("json mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestCredentialsCmd_Field(t *testing.T) {
+	tests := []struct {
+		field string
+		exp   string
+	}{
+		{field: "email", exp: "user@example.com\n"},
+		{field: "password", exp: "hunter2\n"},
+		{field: "url", exp: "http://localhost:8000\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			// the field takes precedence over the output format
+			for _, output := range []string{"text", "json"} {
+				cmd := &CredentialsCmd{Field: tt.field, Output: output}
+				if err := cmd.validate(); err != nil {
+					t.Fatal("unexpected error", err)
+				}
+
+				b := &bytes.Buffer{}
+				if err := cmd.writeCredentials(b, testCreds); err != nil {
+					t.Fatal("unexpected error", err)
+				}
+				if d := cmp.Diff(tt.exp, b.String()); d != "" {
+					t.Errorf("output mismatch (-want +got):\n%s", d)
+				}
+			}
+		})
+	}
+}
+
+func TestCredentialsCmd_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  CredentialsCmd
+	}{
+		{name: "output", cmd: CredentialsCmd{Output: "yaml"}},
+		{name: "field", cmd: CredentialsCmd{Output: "text", Field: "client-secret"}},
+		{name: "qr and show-url", cmd: CredentialsCmd{Output: "text", QR: true, ShowURL: true}},
+		{name: "qr and field", cmd: CredentialsCmd{Output: "text", QR: true, Field: "url"}},
+		{name: "show-url and json", cmd: CredentialsCmd{Output: "json", ShowURL: true}},
+		{name: "rotate and password", cmd: CredentialsCmd{Output: "text", RotateAPIKeys: true, Password: "hunter2"}},
+		{name: "rotate and field", cmd: CredentialsCmd{Output: "text", RotateAPIKeys: true, Field: "url"}},
+		{name: "api-key without rotate", cmd: CredentialsCmd{Output: "text", APIKey: []string{"client-secret"}}},
+		{name: "force without rotate", cmd: CredentialsCmd{Output: "text", Force: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cmd.validate(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestShareURL(t *testing.T) {
+	origLANAddr := lanAddr
+	t.Cleanup(func() { lanAddr = origLANAddr })
+
+	tests := []struct {
+		name    string
+		host    string
+		lanAddr string
+		lanErr  error
+		want    string
+	}{
+		{name: "host", host: "airbyte.example.com", lanAddr: "192.168.1.20", want: "http://airbyte.example.com:8000"},
+		{name: "lan address", lanAddr: "192.168.1.20", want: "http://192.168.1.20:8000"},
+		{name: "ipv6 lan address", lanAddr: "fd00::20", want: "http://[fd00::20]:8000"},
+		{name: "no lan address", lanErr: errors.New("test"), want: "http://localhost:8000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lanAddr = func() (string, error) { return tt.lanAddr, tt.lanErr }
+			if d := cmp.Diff(tt.want, shareURL(tt.host, 8000)); d != "" {
+				t.Errorf("url mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestCredentialsCmd_WriteURL(t *testing.T) {
+	const url = "http://192.168.1.20:8000"
+
+	tests := []struct {
+		name     string
+		cmd      CredentialsCmd
+		terminal bool
+		wantQR   bool
+	}{
+		{name: "show-url", cmd: CredentialsCmd{ShowURL: true}, terminal: true},
+		{name: "qr", cmd: CredentialsCmd{QR: true}, terminal: true, wantQR: true},
+		// a QR code can't be scanned from a file or pipe
+		{name: "qr not a terminal", cmd: CredentialsCmd{QR: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if err := tt.cmd.writeURL(b, url, tt.terminal); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if !strings.Contains(b.String(), url) {
+				t.Errorf("expected the url in the output, got %q", b.String())
+			}
+			if d := cmp.Diff(tt.wantQR, strings.Contains(b.String(), "█")); d != "" {
+				t.Errorf("qr code mismatch (-want +got):\n%s", d)
+			}
+			if !tt.wantQR {
+				if d := cmp.Diff(url+"\n", b.String()); d != "" {
+					t.Errorf("output mismatch (-want +got):\n%s", d)
+				}
+			}
+		})
+	}
+}
+
+func TestNewServerAPI(t *testing.T) {
+	serverPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-server-1", Labels: map[string]string{"app.kubernetes.io/name": "server"}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		pods    []corev1.Pod
+		wantPod string
+		wantErr error
+	}{
+		{name: "server", pods: []corev1.Pod{serverPod}, wantPod: serverPod.Name},
+		{name: "no server", wantErr: k8s.ErrNoPod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var forwarded string
+			var ports []string
+			client := &k8stest.MockClient{
+				FnServiceGet: func(ctx context.Context, namespace, name string) (*corev1.Service, error) {
+					if d := cmp.Diff("airbyte-abctl-airbyte-server-svc", name); d != "" {
+						t.Errorf("service mismatch (-want +got):\n%s", d)
+					}
+					return &corev1.Service{Spec: corev1.ServiceSpec{Selector: map[string]string{"app.kubernetes.io/name": "server"}}}, nil
+				},
+				FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+					return &corev1.PodList{Items: tt.pods}, nil
+				},
+				FnPodPortForward: func(ctx context.Context, namespace, name string, p []string, ready chan struct{}) error {
+					forwarded, ports = name, p
+					close(ready)
+					<-ctx.Done()
+					return nil
+				},
+			}
+
+			abAPI, closeAPI, err := newServerAPI(context.Background(), client, "client-id", "client-secret")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil {
+				return
+			}
+			defer closeAPI()
+
+			if abAPI == nil {
+				t.Fatal("expected an api client")
+			}
+			if d := cmp.Diff(tt.wantPod, forwarded); d != "" {
+				t.Errorf("pod mismatch (-want +got):\n%s", d)
+			}
+			if len(ports) != 1 || !strings.HasSuffix(ports[0], ":"+airbyteServerPort) {
+				t.Errorf("expected the server port to be forwarded, got %v", ports)
+			}
+		})
+	}
+}
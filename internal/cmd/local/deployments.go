@@ -3,8 +3,12 @@ package local
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/output"
 	"github.com/airbytehq/abctl/internal/service"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/pterm/pterm"
@@ -12,7 +16,24 @@ import (
 )
 
 type DeploymentsCmd struct {
-	Restart string `help:"Deployment to restart."`
+	OutputFormat output.Format `default:"table" enum:"table,yaml,json" help:"Output format of the deployments (table, yaml or json). Ignored with --restart."`
+	Restart      string        `help:"Deployment to restart."`
+}
+
+// deploymentsReport is the yaml and json output of the deployments command.
+type deploymentsReport struct {
+	// Deployments are the names of the deployments in the Airbyte namespace.
+	Deployments []string `json:"deployments"`
+}
+
+// WriteTable writes the report to w in the human readable table format.
+func (r deploymentsReport) WriteTable(w io.Writer) error {
+	if len(r.Deployments) == 0 {
+		_, err := io.WriteString(w, "No deployments found\n")
+		return err
+	}
+	_, err := io.WriteString(w, "Found the following deployments:\n  "+strings.Join(r.Deployments, "\n  ")+"\n")
+	return err
 }
 
 func (d *DeploymentsCmd) Run(ctx context.Context, telClient telemetry.Client, provider k8s.Provider) error {
@@ -24,17 +45,24 @@ func (d *DeploymentsCmd) Run(ctx context.Context, telClient telemetry.Client, pr
 		return err
 	}
 
+	// only the deployments may be written to stdout, send everything else to stderr
+	if d.OutputFormat.MachineReadable() && d.Restart == "" {
+		pterm.SetDefaultOutput(os.Stderr)
+		defer pterm.SetDefaultOutput(os.Stdout)
+	}
+
 	spinner := &pterm.DefaultSpinner
 	if err := checkDocker(ctx, telClient, spinner); err != nil {
 		return err
 	}
 
 	return telClient.Wrap(ctx, telemetry.Deployments, func() error {
-		return d.deployments(ctx, k8sClient, spinner)
+		return d.deployments(ctx, k8sClient, spinner, os.Stdout)
 	})
 }
 
-func (d *DeploymentsCmd) deployments(ctx context.Context, k8sClient k8s.Client, spinner *pterm.SpinnerPrinter) error {
+// deployments lists the deployments, writing them to w in the yaml or json output format, or restarts one of them.
+func (d *DeploymentsCmd) deployments(ctx context.Context, k8sClient k8s.Client, spinner *pterm.SpinnerPrinter, w io.Writer) error {
 	if d.Restart == "" {
 		spinner.UpdateText("Fetching deployments")
 		deployments, err := k8sClient.DeploymentList(ctx, airbyteNamespace)
@@ -43,15 +71,19 @@ func (d *DeploymentsCmd) deployments(ctx context.Context, k8sClient k8s.Client,
 			return fmt.Errorf("unable to list deployments: %w", err)
 		}
 
-		if len(deployments.Items) == 0 {
-			pterm.Info.Println("No deployments found")
-			return nil
-		}
-		output := "Found the following deployments:"
+		report := deploymentsReport{Deployments: make([]string, 0, len(deployments.Items))}
 		for _, deployment := range deployments.Items {
-			output += "\n  " + deployment.Name
+			report.Deployments = append(report.Deployments, deployment.Name)
+		}
+		if d.OutputFormat.MachineReadable() {
+			return output.Render(w, d.OutputFormat, report)
+		}
+
+		var b strings.Builder
+		if err := report.WriteTable(&b); err != nil {
+			return err
 		}
-		pterm.Info.Println(output)
+		pterm.Info.Println(strings.TrimSuffix(b.String(), "\n"))
 
 		return nil
 	}
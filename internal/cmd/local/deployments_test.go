@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/airbytehq/abctl/internal/output"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pterm/pterm"
@@ -50,7 +51,7 @@ func TestDeploymentsCmd(t *testing.T) {
 		}
 
 		cmd := &DeploymentsCmd{}
-		err := cmd.deployments(ctx, mockK8s, &pterm.DefaultSpinner)
+		err := cmd.deployments(ctx, mockK8s, &pterm.DefaultSpinner, b)
 		if err != nil {
 			t.Fatal("unexpected error", err)
 		}
@@ -77,7 +78,7 @@ func TestDeploymentsCmd(t *testing.T) {
 		}
 
 		cmd := &DeploymentsCmd{}
-		err := cmd.deployments(ctx, mockK8s, &pterm.DefaultSpinner)
+		err := cmd.deployments(ctx, mockK8s, &pterm.DefaultSpinner, b)
 		if err != nil {
 			t.Fatal("unexpected error", err)
 		}
@@ -98,9 +99,58 @@ func TestDeploymentsCmd(t *testing.T) {
 		}
 
 		cmd := &DeploymentsCmd{}
-		err := cmd.deployments(ctx, mockK8s, &pterm.DefaultSpinner)
+		err := cmd.deployments(ctx, mockK8s, &pterm.DefaultSpinner, b)
 		if d := cmp.Diff(errTest, err, cmpopts.EquateErrors()); d != "" {
 			t.Errorf("error mismatch (-want +got):\n%s", d)
 		}
 	})
 }
+
+func TestDeploymentsCmd_OutputFormat(t *testing.T) {
+	mockK8s := &k8stest.MockClient{
+		FnDeploymentList: func(ctx context.Context, namespace string) (*v1.DeploymentList, error) {
+			return &v1.DeploymentList{
+				Items: []v1.Deployment{
+					{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-server"}},
+					{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-worker"}},
+				},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		format output.Format
+		want   string
+	}{
+		{
+			format: output.YAML,
+			want: `deployments:
+  - airbyte-abctl-server
+  - airbyte-abctl-worker
+`,
+		},
+		{
+			format: output.JSON,
+			want: `{
+  "deployments": [
+    "airbyte-abctl-server",
+    "airbyte-abctl-worker"
+  ]
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var b bytes.Buffer
+			cmd := &DeploymentsCmd{OutputFormat: tt.format}
+			if err := cmd.deployments(context.Background(), mockK8s, &pterm.DefaultSpinner, &b); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, b.String()); d != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package local
+
+import "syscall"
+
+// freeDiskSpace returns the disk space, in bytes, available to abctl on the filesystem of the path.
+func freeDiskSpace(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
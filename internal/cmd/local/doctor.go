@@ -0,0 +1,455 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/pterm/pterm"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type DoctorCmd struct {
+	Fix bool `help:"Remediate the detected problems. Every remediation is listed before it runs."`
+	Yes bool `help:"With --fix, also run the destructive remediations, such as restarting Docker Desktop, deleting a broken cluster or removing the unused Docker images pulled by abctl."`
+}
+
+// remediation is an action doctor can take to fix a detected problem.
+type remediation string
+
+const (
+	// remediationNone is the remediation of a problem doctor can't fix, only describe how to fix manually.
+	remediationNone          remediation = ""
+	remediationRestartDocker remediation = "restart-docker"
+	remediationUnpauseNode   remediation = "unpause-node"
+	remediationStartNode     remediation = "start-node"
+	remediationDeleteCluster remediation = "delete-cluster"
+	remediationLoadImages    remediation = "load-images"
+	remediationPruneImages   remediation = "prune-images"
+)
+
+// destructive returns true if the remediation removes anything, or stops the containers running on the docker engine,
+// which --fix only does with --yes.
+func (r remediation) destructive() bool {
+	return r == remediationRestartDocker || r == remediationDeleteCluster || r == remediationPruneImages
+}
+
+// doctorProblem is a problem detected by doctor, along with the remediation which fixes it.
+type doctorProblem struct {
+	summary     string
+	remediation remediation
+	// action describes what the remediation changes, or how to fix the problem manually if it has no remediation.
+	action string
+
+	// stuckPulls are the images the cluster is unable to pull, along with the pods waiting on them.
+	stuckPulls map[string][]string
+	// unusedImages are the unused images pulled by abctl.
+	unusedImages []docker.UnusedImage
+}
+
+// doctorState is what doctor observed of the docker engine and the cluster.
+type doctorState struct {
+	// dockerErr is the error communicating with the docker engine, nil if it's reachable.
+	dockerErr error
+	// node is the state of the node container of the cluster, nil if the cluster doesn't exist.
+	node *types.ContainerState
+	// apiErr is the error communicating with the kubernetes api of the running cluster, nil if it's reachable.
+	apiErr error
+	// stuckPulls are the images the pods of airbyte are in ImagePullBackOff for, along with those pods.
+	stuckPulls map[string][]string
+	// unusedImages are the images pulled by abctl which no container uses.
+	unusedImages []docker.UnusedImage
+}
+
+func (d *DoctorCmd) Run(ctx context.Context, provider k8s.Provider) error {
+	ctx, span := trace.NewSpan(ctx, "local doctor")
+	defer span.End()
+
+	span.SetAttributes(attribute.Bool("fix", d.Fix), attribute.Bool("yes", d.Yes))
+
+	if d.Yes && !d.Fix {
+		return errors.New("the --yes flag requires the --fix flag")
+	}
+
+	state := observe(ctx, provider)
+	problems := diagnose(provider.ClusterName, state)
+	printProblems(os.Stdout, problems)
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if !d.Fix {
+		pterm.Info.Println("Run with --fix to remediate these problems")
+		return nil
+	}
+
+	return fix(ctx, os.Stdout, &hostRemediator{provider: provider, docker: dockerClient}, problems, d.Yes)
+}
+
+// observe returns the state of the docker engine and the cluster. Failures to observe anything beyond the docker
+// engine being reachable are only logged at debug, the corresponding checks are skipped.
+func observe(ctx context.Context, provider k8s.Provider) doctorState {
+	var state doctorState
+
+	if dockerClient == nil {
+		var err error
+		if dockerClient, err = docker.New(ctx); err != nil {
+			state.dockerErr = err
+			return state
+		}
+	}
+	if _, err := dockerClient.Client.ServerVersion(ctx); err != nil {
+		state.dockerErr = err
+		return state
+	}
+
+	if provider.RequiresDocker() {
+		ci, err := dockerClient.Client.ContainerInspect(ctx, fmt.Sprintf("%s-control-plane", provider.ClusterName))
+		if err != nil {
+			pterm.Debug.Printfln("Unable to inspect the node container of cluster '%s': %s", provider.ClusterName, err)
+		} else if ci.ContainerJSONBase != nil {
+			state.node = ci.State
+		}
+	}
+
+	if state.node != nil && state.node.Running && !state.node.Paused {
+		k8sClient, err := service.DefaultK8s(provider.Kubeconfig, provider.Context)
+		if err == nil {
+			_, err = k8sClient.ServerVersionGet()
+		}
+		state.apiErr = err
+
+		if err == nil {
+			pods, err := k8sClient.PodList(ctx, common.AirbyteNamespace)
+			if err != nil {
+				pterm.Debug.Printfln("Unable to list the pods in namespace '%s': %s", common.AirbyteNamespace, err)
+			} else {
+				state.stuckPulls = service.StuckImagePulls(pods.Items)
+			}
+		}
+	}
+
+	_, unused, err := service.UnusedImages(ctx, dockerClient, paths.ImageManifest)
+	if err != nil {
+		pterm.Debug.Printfln("Unable to determine the unused Docker images pulled by abctl: %s", err)
+	}
+	state.unusedImages = unused
+
+	return state
+}
+
+// diagnose returns the problems of the observed state, each with the remediation which fixes it.
+func diagnose(clusterName string, state doctorState) []doctorProblem {
+	if state.dockerErr != nil {
+		if docker.DesktopPaused(state.dockerErr) {
+			return []doctorProblem{{
+				summary:     "Docker Desktop is paused",
+				remediation: remediationRestartDocker,
+				action:      "Restart Docker Desktop, resuming the Docker engine and restarting every running container",
+			}}
+		}
+		// nothing else can be checked without the docker engine
+		return []doctorProblem{{
+			summary: fmt.Sprintf("Unable to communicate with the Docker engine: %s", state.dockerErr),
+			action:  "Start Docker, or select the Docker engine to use with --docker-context, and try again",
+		}}
+	}
+
+	var problems []doctorProblem
+	node := fmt.Sprintf("%s-control-plane", clusterName)
+	if state.node != nil {
+		switch {
+		case state.node.Paused:
+			problems = append(problems, doctorProblem{
+				summary:     fmt.Sprintf("The node container '%s' of cluster '%s' is paused", node, clusterName),
+				remediation: remediationUnpauseNode,
+				action:      fmt.Sprintf("Unpause the node container '%s'", node),
+			})
+		case state.node.Status == "exited" || state.node.Status == "created":
+			summary := fmt.Sprintf("The node container '%s' of cluster '%s' is stopped", node, clusterName)
+			if state.node.OOMKilled {
+				summary += ", after running out of memory"
+			}
+			problems = append(problems, doctorProblem{
+				summary:     summary,
+				remediation: remediationStartNode,
+				action:      fmt.Sprintf("Start the node container '%s'", node),
+			})
+		case state.node.Status == "dead" || state.node.Status == "restarting":
+			problems = append(problems, doctorProblem{
+				summary:     fmt.Sprintf("The node container '%s' of cluster '%s' is stuck %s", node, clusterName, state.node.Status),
+				remediation: remediationDeleteCluster,
+				action:      fmt.Sprintf("Delete cluster '%s', keeping the persisted data, to be recreated by 'abctl local install'", clusterName),
+			})
+		case state.apiErr != nil:
+			problems = append(problems, doctorProblem{
+				summary:     fmt.Sprintf("The Kubernetes API of cluster '%s' is unreachable: %s", clusterName, state.apiErr),
+				remediation: remediationDeleteCluster,
+				action:      fmt.Sprintf("Delete cluster '%s', keeping the persisted data, to be recreated by 'abctl local install'", clusterName),
+			})
+		}
+	}
+
+	if len(state.stuckPulls) > 0 {
+		problems = append(problems, doctorProblem{
+			summary:     fmt.Sprintf("The cluster is unable to pull images %s", strings.Join(sortedKeys(state.stuckPulls), ", ")),
+			remediation: remediationLoadImages,
+			action:      "Load the images into the cluster from the host, and recreate the pods waiting on them",
+			stuckPulls:  state.stuckPulls,
+		})
+	}
+
+	if len(state.unusedImages) > 0 {
+		var size int64
+		for _, img := range state.unusedImages {
+			size += img.Size
+		}
+		problems = append(problems, doctorProblem{
+			summary:      fmt.Sprintf("%d unused Docker images pulled by abctl use %s of disk space", len(state.unusedImages), formatGiB(size)),
+			remediation:  remediationPruneImages,
+			action:       "Remove the unused Docker images pulled by abctl, as 'abctl local prune --confirm' does",
+			unusedImages: state.unusedImages,
+		})
+	}
+
+	return problems
+}
+
+// sortedKeys returns the sorted keys of m.
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printProblems writes the detected problems, and how each one is fixed.
+func printProblems(w io.Writer, problems []doctorProblem) {
+	if len(problems) == 0 {
+		fmt.Fprintln(w, "No problems were found")
+		return
+	}
+
+	fmt.Fprintf(w, "%d problems were found:\n", len(problems))
+	for i, p := range problems {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, p.summary)
+		switch {
+		case p.remediation == remediationNone:
+			fmt.Fprintf(w, "     To fix manually: %s\n", p.action)
+		case p.remediation.destructive():
+			fmt.Fprintf(w, "     Fix (requires --yes): %s\n", p.action)
+		default:
+			fmt.Fprintf(w, "     Fix: %s\n", p.action)
+		}
+	}
+}
+
+// remediator runs the remediations of doctor. Each method returns a description of what it changed.
+type remediator interface {
+	restartDocker(ctx context.Context) (string, error)
+	unpauseNode(ctx context.Context) (string, error)
+	startNode(ctx context.Context) (string, error)
+	deleteCluster(ctx context.Context) (string, error)
+	loadImages(ctx context.Context, stuck map[string][]string) (string, error)
+	pruneImages(ctx context.Context, images []docker.UnusedImage) (string, error)
+}
+
+// remediate runs the remediation of the problem with r, returning what it changed.
+func remediate(ctx context.Context, r remediator, p doctorProblem) (string, error) {
+	switch p.remediation {
+	case remediationRestartDocker:
+		return r.restartDocker(ctx)
+	case remediationUnpauseNode:
+		return r.unpauseNode(ctx)
+	case remediationStartNode:
+		return r.startNode(ctx)
+	case remediationDeleteCluster:
+		return r.deleteCluster(ctx)
+	case remediationLoadImages:
+		return r.loadImages(ctx, p.stuckPulls)
+	case remediationPruneImages:
+		return r.pruneImages(ctx, p.unusedImages)
+	default:
+		return "", fmt.Errorf("unsupported remediation '%s'", p.remediation)
+	}
+}
+
+// fix lists the remediations of the problems, then runs them in order, reporting what each one changed.
+// The destructive remediations are only run if yes is true, the problems without a remediation are left as is.
+// A failed remediation stops the remaining ones, as they may depend on it.
+func fix(ctx context.Context, w io.Writer, r remediator, problems []doctorProblem, yes bool) error {
+	var run []doctorProblem
+	for _, p := range problems {
+		if p.remediation == remediationNone {
+			continue
+		}
+		if p.remediation.destructive() && !yes {
+			pterm.Warning.Printfln("Skipping '%s', as it requires --yes", p.action)
+			continue
+		}
+		run = append(run, p)
+	}
+	if len(run) == 0 {
+		fmt.Fprintln(w, "No remediations to run")
+		return nil
+	}
+
+	fmt.Fprintln(w, "Running the remediations:")
+	for _, p := range run {
+		fmt.Fprintf(w, "  - %s\n", p.action)
+	}
+
+	var changes []string
+	for _, p := range run {
+		change, err := remediate(ctx, r, p)
+		if err != nil {
+			pterm.Error.Printfln("Remediation failed: %s", p.action)
+			printChanges(w, changes)
+			return fmt.Errorf("unable to remediate '%s': %w", p.summary, err)
+		}
+		pterm.Success.Println(change)
+		changes = append(changes, change)
+	}
+
+	printChanges(w, changes)
+	return nil
+}
+
+// printChanges writes what the remediations changed.
+func printChanges(w io.Writer, changes []string) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "Nothing was changed")
+		return
+	}
+	fmt.Fprintln(w, "Changed:")
+	for _, c := range changes {
+		fmt.Fprintf(w, "  - %s\n", c)
+	}
+}
+
+// hostRemediator is the remediator which changes the docker engine, and the cluster, of the host.
+type hostRemediator struct {
+	provider k8s.Provider
+	docker   *docker.Docker
+}
+
+func (h *hostRemediator) node() string {
+	return fmt.Sprintf("%s-control-plane", h.provider.ClusterName)
+}
+
+func (h *hostRemediator) restartDocker(_ context.Context) (string, error) {
+	if err := docker.RestartDesktop(); err != nil {
+		return "", err
+	}
+	return "Restarted Docker Desktop", nil
+}
+
+func (h *hostRemediator) unpauseNode(ctx context.Context) (string, error) {
+	if err := h.docker.Client.ContainerUnpause(ctx, h.node()); err != nil {
+		return "", fmt.Errorf("unable to unpause container '%s': %w", h.node(), err)
+	}
+	return fmt.Sprintf("Unpaused the node container '%s'", h.node()), nil
+}
+
+func (h *hostRemediator) startNode(ctx context.Context) (string, error) {
+	if err := h.docker.Client.ContainerStart(ctx, h.node(), container.StartOptions{}); err != nil {
+		return "", fmt.Errorf("unable to start container '%s': %w", h.node(), err)
+	}
+	return fmt.Sprintf("Started the node container '%s'", h.node()), nil
+}
+
+// clusterOwnershipTimeout is how long deleteCluster waits on the kubernetes api to confirm the cluster is abctl's.
+var clusterOwnershipTimeout = 10 * time.Second
+
+func (h *hostRemediator) deleteCluster(ctx context.Context) (string, error) {
+	owned, err := h.clusterOwned(ctx)
+	switch {
+	case err != nil:
+		// the api of a broken cluster is usually unreachable, deleting it anyway is what --yes confirmed
+		pterm.Warning.Printfln("Unable to confirm cluster '%s' was created by abctl, deleting it anyway: %s", h.provider.ClusterName, err)
+	case !owned:
+		pterm.Error.Printfln("Cluster '%s' was not created by abctl, it must be deleted manually", h.provider.ClusterName)
+		return "", fmt.Errorf("%w: cluster '%s'", abctl.ErrClusterNotOwned, h.provider.ClusterName)
+	}
+
+	cluster, err := h.provider.Cluster(ctx)
+	if err != nil {
+		return "", err
+	}
+	if err := cluster.Delete(ctx); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Deleted cluster '%s', run 'abctl local install' to recreate it", h.provider.ClusterName), nil
+}
+
+func (h *hostRemediator) clusterOwned(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, clusterOwnershipTimeout)
+	defer cancel()
+
+	k8sClient, err := service.DefaultK8s(h.provider.Kubeconfig, h.provider.Context)
+	if err != nil {
+		return false, err
+	}
+	return clusterOwned(ctx, k8sClient)
+}
+
+// clusterOwned returns true if the cluster was created by abctl, either marked by it or by a prior version of it
+// which installed airbyte.
+func clusterOwned(ctx context.Context, k8sClient k8s.Client) (bool, error) {
+	marked, err := k8s.ClusterMarked(ctx, k8sClient)
+	if err != nil {
+		return false, err
+	}
+	return marked || k8sClient.NamespaceExists(ctx, airbyteNamespace), nil
+}
+
+func (h *hostRemediator) loadImages(ctx context.Context, stuck map[string][]string) (string, error) {
+	cluster, err := h.provider.Cluster(ctx)
+	if err != nil {
+		return "", err
+	}
+	k8sClient, err := service.DefaultK8s(h.provider.Kubeconfig, h.provider.Context)
+	if err != nil {
+		return "", err
+	}
+
+	images := sortedKeys(stuck)
+	cluster.LoadImages(ctx, h.docker.Client, images)
+
+	// the pods are recreated with the images present
+	var deleted int
+	for _, img := range images {
+		for _, pod := range stuck[img] {
+			if err := k8sClient.PodDelete(ctx, common.AirbyteNamespace, pod); err != nil {
+				pterm.Debug.Printfln("Unable to delete pod '%s': %s", pod, err)
+				continue
+			}
+			deleted++
+		}
+	}
+	return fmt.Sprintf("Loaded %d images into the cluster, recreating %d pods", len(images), deleted), nil
+}
+
+func (h *hostRemediator) pruneImages(ctx context.Context, images []docker.UnusedImage) (string, error) {
+	res, err := service.RemoveUnusedImages(ctx, h.docker, paths.ImageManifest, images)
+	if err != nil {
+		return "", fmt.Errorf("unable to prune images: %w", err)
+	}
+	return fmt.Sprintf("Removed %d unused Docker images pulled by abctl", len(res.Removed)), nil
+}
@@ -0,0 +1,309 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/docker/docker/api/types"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestDiagnose(t *testing.T) {
+	unused := []docker.UnusedImage{{ID: "sha256:1", Refs: []string{"airbyte/server:1.0.0"}, Size: 1024 * 1024 * 1024}}
+
+	tests := []struct {
+		name  string
+		state doctorState
+		want  []remediation
+	}{
+		{
+			name: "healthy",
+			state: doctorState{
+				node: &types.ContainerState{Status: "running", Running: true},
+			},
+		},
+		{
+			name: "no cluster",
+		},
+		{
+			name:  "docker desktop paused",
+			state: doctorState{dockerErr: errors.New("Docker Desktop is manually paused. Unpause it through the Whale menu or the Dashboard.")},
+			want:  []remediation{remediationRestartDocker},
+		},
+		{
+			name:  "docker unreachable",
+			state: doctorState{dockerErr: errors.New("Cannot connect to the Docker daemon"), unusedImages: unused},
+			want:  []remediation{remediationNone},
+		},
+		{
+			name:  "node paused",
+			state: doctorState{node: &types.ContainerState{Status: "paused", Running: true, Paused: true}},
+			want:  []remediation{remediationUnpauseNode},
+		},
+		{
+			name:  "node stopped",
+			state: doctorState{node: &types.ContainerState{Status: "exited", OOMKilled: true}},
+			want:  []remediation{remediationStartNode},
+		},
+		{
+			name:  "node dead",
+			state: doctorState{node: &types.ContainerState{Status: "dead"}},
+			want:  []remediation{remediationDeleteCluster},
+		},
+		{
+			name:  "node restarting",
+			state: doctorState{node: &types.ContainerState{Status: "restarting", Running: true, Restarting: true}},
+			want:  []remediation{remediationDeleteCluster},
+		},
+		{
+			name: "api unreachable",
+			state: doctorState{
+				node:   &types.ContainerState{Status: "running", Running: true},
+				apiErr: errors.New("connection refused"),
+			},
+			want: []remediation{remediationDeleteCluster},
+		},
+		{
+			name: "stuck image pulls",
+			state: doctorState{
+				node:       &types.ContainerState{Status: "running", Running: true},
+				stuckPulls: map[string][]string{"airbyte/server:1.0.0": {"airbyte-abctl-server-123-abc"}},
+			},
+			want: []remediation{remediationLoadImages},
+		},
+		{
+			name:  "unused images",
+			state: doctorState{unusedImages: unused},
+			want:  []remediation{remediationPruneImages},
+		},
+		{
+			name: "several problems",
+			state: doctorState{
+				node:         &types.ContainerState{Status: "running", Running: true},
+				stuckPulls:   map[string][]string{"airbyte/server:1.0.0": {"airbyte-abctl-server-123-abc"}},
+				unusedImages: unused,
+			},
+			want: []remediation{remediationLoadImages, remediationPruneImages},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []remediation
+			for _, p := range diagnose("airbyte-abctl", tt.state) {
+				got = append(got, p.remediation)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("remediations mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+// fakeRemediator is a remediator which records the remediations it runs, failing the one in fail.
+type fakeRemediator struct {
+	fail remediation
+	ran  []remediation
+}
+
+func (f *fakeRemediator) run(r remediation) (string, error) {
+	f.ran = append(f.ran, r)
+	if r == f.fail {
+		return "", errors.New("remediation failed")
+	}
+	return "ran " + string(r), nil
+}
+
+func (f *fakeRemediator) restartDocker(context.Context) (string, error) {
+	return f.run(remediationRestartDocker)
+}
+
+func (f *fakeRemediator) unpauseNode(context.Context) (string, error) {
+	return f.run(remediationUnpauseNode)
+}
+
+func (f *fakeRemediator) startNode(context.Context) (string, error) {
+	return f.run(remediationStartNode)
+}
+
+func (f *fakeRemediator) deleteCluster(context.Context) (string, error) {
+	return f.run(remediationDeleteCluster)
+}
+
+func (f *fakeRemediator) loadImages(context.Context, map[string][]string) (string, error) {
+	return f.run(remediationLoadImages)
+}
+
+func (f *fakeRemediator) pruneImages(context.Context, []docker.UnusedImage) (string, error) {
+	return f.run(remediationPruneImages)
+}
+
+func TestFix(t *testing.T) {
+	problems := []doctorProblem{
+		{summary: "unreachable", action: "Start Docker"},
+		{summary: "stopped", remediation: remediationStartNode, action: "Start the node"},
+		{summary: "stuck", remediation: remediationLoadImages, action: "Load the images"},
+		{summary: "unused", remediation: remediationPruneImages, action: "Remove the images"},
+	}
+
+	tests := []struct {
+		name        string
+		yes         bool
+		fail        remediation
+		want        []remediation
+		wantErr     bool
+		wantChanges string
+	}{
+		{
+			name:        "without yes",
+			want:        []remediation{remediationStartNode, remediationLoadImages},
+			wantChanges: "Changed:\n  - ran start-node\n  - ran load-images\n",
+		},
+		{
+			name:        "with yes",
+			yes:         true,
+			want:        []remediation{remediationStartNode, remediationLoadImages, remediationPruneImages},
+			wantChanges: "Changed:\n  - ran start-node\n  - ran load-images\n  - ran prune-images\n",
+		},
+		{
+			name:        "failed remediation",
+			yes:         true,
+			fail:        remediationLoadImages,
+			want:        []remediation{remediationStartNode, remediationLoadImages},
+			wantErr:     true,
+			wantChanges: "Changed:\n  - ran start-node\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeRemediator{fail: tt.fail}
+			var out bytes.Buffer
+
+			err := fix(context.Background(), &out, r, problems, tt.yes)
+			if d := cmp.Diff(tt.wantErr, err != nil); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s\nerror: %v", d, err)
+			}
+			if d := cmp.Diff(tt.want, r.ran); d != "" {
+				t.Errorf("remediations mismatch (-want +got):\n%s", d)
+			}
+
+			// every remediation is listed before any of them runs
+			if !strings.HasPrefix(out.String(), "Running the remediations:\n") {
+				t.Errorf("expected the remediations to be listed first, got:\n%s", out.String())
+			}
+			if !strings.HasSuffix(out.String(), tt.wantChanges) {
+				t.Errorf("expected the changes to be reported, got:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestFix_NothingToRun(t *testing.T) {
+	r := &fakeRemediator{}
+	var out bytes.Buffer
+
+	problems := []doctorProblem{{summary: "unused", remediation: remediationPruneImages, action: "Remove the images"}}
+	if err := fix(context.Background(), &out, r, problems, false); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]remediation(nil), r.ran); d != "" {
+		t.Errorf("remediations mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff("No remediations to run\n", out.String()); d != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestPrintProblems(t *testing.T) {
+	var out bytes.Buffer
+	printProblems(&out, []doctorProblem{
+		{summary: "Docker is unreachable", action: "Start Docker"},
+		{summary: "The node is stopped", remediation: remediationStartNode, action: "Start the node"},
+		{summary: "The node is dead", remediation: remediationDeleteCluster, action: "Delete the cluster"},
+	})
+
+	want := `3 problems were found:
+  1. Docker is unreachable
+     To fix manually: Start Docker
+  2. The node is stopped
+     Fix: Start the node
+  3. The node is dead
+     Fix (requires --yes): Delete the cluster
+`
+	if d := cmp.Diff(want, out.String()); d != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", d)
+	}
+
+	out.Reset()
+	printProblems(&out, nil)
+	if d := cmp.Diff("No problems were found\n", out.String()); d != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestRemediation_Destructive(t *testing.T) {
+	destructive := map[remediation]bool{
+		remediationRestartDocker: true,
+		remediationUnpauseNode:   false,
+		remediationStartNode:     false,
+		remediationDeleteCluster: true,
+		remediationLoadImages:    false,
+		remediationPruneImages:   true,
+	}
+	for r, want := range destructive {
+		if d := cmp.Diff(want, r.destructive()); d != "" {
+			t.Errorf("%s destructive mismatch (-want +got):\n%s", r, d)
+		}
+	}
+}
+
+func TestClusterOwned(t *testing.T) {
+	notFound := k8serrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, k8s.ClusterMarkerName)
+
+	tests := []struct {
+		name      string
+		markerErr error
+		airbyteNS bool
+		want      bool
+		wantErr   bool
+	}{
+		{name: "marked", want: true},
+		{name: "prior version", markerErr: notFound, airbyteNS: true, want: true},
+		{name: "not owned", markerErr: notFound},
+		{name: "unreachable", markerErr: errors.New("connection refused"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := &k8stest.MockClient{
+				FnConfigMapGet: func(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
+					if tt.markerErr != nil {
+						return nil, tt.markerErr
+					}
+					return &corev1.ConfigMap{}, nil
+				},
+				FnNamespaceExists: func(ctx context.Context, namespace string) bool {
+					return tt.airbyteNS && namespace == airbyteNamespace
+				},
+			}
+
+			got, err := clusterOwned(context.Background(), k8sClient)
+			if d := cmp.Diff(tt.wantErr, err != nil); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s\nerror: %v", d, err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("owned mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
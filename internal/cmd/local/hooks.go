@@ -0,0 +1,133 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+)
+
+// Environment variables provided to every post-install hook.
+const (
+	envHookURL             = "ABCTL_URL"
+	envHookCredentialsFile = "ABCTL_CREDENTIALS_FILE"
+	envHookKubeconfig      = "ABCTL_KUBECONFIG"
+	envHookKubeContext     = "ABCTL_KUBE_CONTEXT"
+)
+
+// hookEnv contains the values exposed to the post-install hooks.
+type hookEnv struct {
+	// URL of the airbyte installation
+	URL string
+	// CredentialsFile is the path to a file containing the airbyte credentials,
+	// the credentials themselves are never passed through the environment
+	CredentialsFile string
+	// Kubeconfig is the path to the kubeconfig of the cluster
+	Kubeconfig string
+	// Context is the kubeconfig context of the cluster
+	Context string
+}
+
+// environ returns the hook environment appended to the environment of this process.
+func (h hookEnv) environ() []string {
+	return append(os.Environ(),
+		envHookURL+"="+h.URL,
+		envHookCredentialsFile+"="+h.CredentialsFile,
+		envHookKubeconfig+"="+h.Kubeconfig,
+		envHookKubeContext+"="+h.Context,
+	)
+}
+
+// hookCredentials is the content of the credentials file provided to the post-install hooks.
+type hookCredentials struct {
+	Password     string `json:"password"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	URL          string `json:"url"`
+}
+
+// writeHookCredentials writes the airbyte credentials to a temporary file, readable only by the current user.
+// The returned function removes the file.
+func writeHookCredentials(ctx context.Context, k8sClient k8s.Client, url string) (string, func(), error) {
+	secret, err := k8sClient.SecretGet(ctx, airbyteNamespace, airbyteAuthSecretName)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to fetch the airbyte credentials: %w", err)
+	}
+
+	raw, err := json.Marshal(hookCredentials{
+		Password:     string(secret.Data[secretPassword]),
+		ClientID:     string(secret.Data[secretClientID]),
+		ClientSecret: string(secret.Data[secretClientSecret]),
+		URL:          url,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to marshal the airbyte credentials: %w", err)
+	}
+
+	// os.CreateTemp creates the file with 0600 permissions
+	f, err := os.CreateTemp("", "abctl-credentials-*.json")
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create the credentials file: %w", err)
+	}
+	cleanup := func() { _ = os.Remove(f.Name()) }
+
+	if _, err := f.Write(raw); err != nil {
+		_ = f.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("unable to write the credentials file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("unable to write the credentials file: %w", err)
+	}
+
+	return f.Name(), cleanup, nil
+}
+
+// hookCommand returns the command which runs the hook through the platform's shell.
+func hookCommand(ctx context.Context, hook string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", hook)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", hook)
+}
+
+// runPostInstallHooks runs each of the hooks, in order, with the env provided, writing their stdout to stdout.
+// If a hook fails, the remaining hooks are not run and an error is returned, unless ignoreErrors is true.
+func runPostInstallHooks(ctx context.Context, stdout io.Writer, hooks []string, env hookEnv, ignoreErrors bool) error {
+	for _, hook := range hooks {
+		pterm.Info.Printfln("Running post-install hook '%s'", hook)
+
+		cmd := hookCommand(ctx, hook)
+		cmd.Env = env.environ()
+		cmd.Stdout = stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				err = fmt.Errorf("post-install hook '%s' exited with code %d: %w", hook, exitErr.ExitCode(), err)
+			} else {
+				err = fmt.Errorf("unable to run post-install hook '%s': %w", hook, err)
+			}
+
+			if ignoreErrors {
+				pterm.Warning.Println(err.Error())
+				continue
+			}
+			pterm.Error.Printfln("Post-install hook '%s' failed", hook)
+			return err
+		}
+
+		pterm.Success.Printfln("Post-install hook '%s' completed", hook)
+	}
+
+	return nil
+}
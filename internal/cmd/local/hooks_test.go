@@ -0,0 +1,150 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRunPostInstallHooks_Env(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are run through sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "hook.out")
+	t.Setenv("HOOK_OUT", out)
+
+	env := hookEnv{
+		URL:             "http://localhost:8000",
+		CredentialsFile: "/tmp/creds.json",
+		Kubeconfig:      "/home/user/.airbyte/abctl/abctl.kubeconfig",
+		Context:         "kind-airbyte-abctl",
+	}
+	hook := `echo "$ABCTL_URL|$ABCTL_CREDENTIALS_FILE|$ABCTL_KUBECONFIG|$ABCTL_KUBE_CONTEXT" > "$HOOK_OUT"`
+
+	if err := runPostInstallHooks(context.Background(), io.Discard, []string{hook}, env, false); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal("unable to read hook output", err)
+	}
+	exp := "http://localhost:8000|/tmp/creds.json|/home/user/.airbyte/abctl/abctl.kubeconfig|kind-airbyte-abctl"
+	if d := cmp.Diff(exp, strings.TrimSpace(string(raw))); d != "" {
+		t.Errorf("hook env mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestRunPostInstallHooks_Stdout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are run through sh")
+	}
+
+	var stdout bytes.Buffer
+	if err := runPostInstallHooks(context.Background(), &stdout, []string{"echo hello"}, hookEnv{}, false); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("hello\n", stdout.String()); d != "" {
+		t.Errorf("hook stdout mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestRunPostInstallHooks_ExitCode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are run through sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "hook.out")
+	t.Setenv("HOOK_OUT", out)
+	hooks := []string{"exit 3", `touch "$HOOK_OUT"`}
+
+	t.Run("fails", func(t *testing.T) {
+		err := runPostInstallHooks(context.Background(), io.Discard, hooks, hookEnv{}, false)
+
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Fatalf("expected exit error but got %v", err)
+		}
+		if d := cmp.Diff(3, exitErr.ExitCode()); d != "" {
+			t.Errorf("exit code mismatch (-want +got):\n%s", d)
+		}
+		if _, err := os.Stat(out); !errors.Is(err, os.ErrNotExist) {
+			t.Error("hooks after the failed hook should not run")
+		}
+	})
+
+	t.Run("ignore errors", func(t *testing.T) {
+		if err := runPostInstallHooks(context.Background(), io.Discard, hooks, hookEnv{}, true); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if _, err := os.Stat(out); err != nil {
+			t.Error("hooks after the failed hook should run", err)
+		}
+	})
+}
+
+func TestWriteHookCredentials(t *testing.T) {
+	k8sClient := &k8stest.MockClient{
+		FnSecretGet: func(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+			if d := cmp.Diff(airbyteAuthSecretName, name); d != "" {
+				t.Error("unexpected secret name", d)
+			}
+			return &corev1.Secret{Data: map[string][]byte{
+				secretPassword:     []byte("hunter2"),
+				secretClientID:     []byte("client-id"),
+				secretClientSecret: []byte("client-secret"),
+			}}, nil
+		},
+	}
+
+	path, cleanup, err := writeHookCredentials(context.Background(), k8sClient, "http://localhost:8000")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal("unable to stat credentials file", err)
+		}
+		if d := cmp.Diff(os.FileMode(0o600), info.Mode().Perm()); d != "" {
+			t.Errorf("credentials file permissions mismatch (-want +got):\n%s", d)
+		}
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unable to read credentials file", err)
+	}
+	var got hookCredentials
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatal("unable to unmarshal credentials file", err)
+	}
+	exp := hookCredentials{
+		Password:     "hunter2",
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		URL:          "http://localhost:8000",
+	}
+	if d := cmp.Diff(exp, got); d != "" {
+		t.Errorf("credentials mismatch (-want +got):\n%s", d)
+	}
+
+	cleanup()
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Error("credentials file should have been removed")
+	}
+}
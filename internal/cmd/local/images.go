@@ -0,0 +1,152 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ImagesCmd contains the image commands.
+type ImagesCmd struct {
+	Verify ImagesVerifyCmd `cmd:"" help:"Verify that Docker has every image Airbyte requires, at the required digest."`
+}
+
+// ImagesVerifyCmd contains the arguments used when executing the images verify command.
+type ImagesVerifyCmd struct {
+	Chart             string `help:"Path to chart." xor:"chartver"`
+	ChartVersion      string `help:"Version of the chart to verify the images of." xor:"chartver"`
+	FromBundle        string `type:"existingfile" help:"A file of the required images, one per line (e.g. the output of 'abctl images manifest'), instead of the images of the chart. An image pinned to a digest, in the format <IMAGE>:<TAG>@sha256:<DIGEST>, must be present at that digest." xor:"chartver"`
+	RefreshImageCache bool   `help:"Resolve the images of the chart again, instead of using the ones cached for its version."`
+	Values            string `type:"existingfile" help:"An Airbyte helm chart values file, which may change the images of the chart."`
+}
+
+// Run executes the images verify command, which checks that every required image is present in Docker, from which
+// abctl loads the images into the cluster, e.g. before or after an air-gapped installation.
+func (c *ImagesVerifyCmd) Run(ctx context.Context, provider k8s.Provider, newSvcMgrClients service.ManagerClientFactory) error {
+	ctx, span := trace.NewSpan(ctx, "local images verify")
+	defer span.End()
+
+	span.SetAttributes(attribute.Bool("from_bundle", c.FromBundle != ""))
+
+	images, err := c.requiredImages(ctx, provider, newSvcMgrClients)
+	if err != nil {
+		return err
+	}
+
+	if dockerClient == nil {
+		if dockerClient, err = docker.New(ctx); err != nil {
+			pterm.Error.Println("Unable to create Docker client")
+			return fmt.Errorf("%w: unable to create client: %w", abctl.ErrDocker, err)
+		}
+	}
+
+	return verifyImages(ctx, os.Stdout, dockerClient, images)
+}
+
+// requiredImages returns the images of the bundle, or else those of the chart.
+func (c *ImagesVerifyCmd) requiredImages(ctx context.Context, provider k8s.Provider, newSvcMgrClients service.ManagerClientFactory) ([]string, error) {
+	if c.FromBundle != "" {
+		return bundleImages(c.FromBundle)
+	}
+
+	_, helmClient, err := newSvcMgrClients(provider.Kubeconfig, provider.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	chart, version, err := helm.NewChartResolver(helmClient).ResolveChartReference(c.Chart, c.ChartVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve chart flags: %w", err)
+	}
+
+	valuesYaml, err := helm.BuildAirbyteValues(ctx, helm.ValuesOpts{ValuesFile: c.Values}, version)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := &helm.ImageCache{Path: paths.ImageCache, Refresh: c.RefreshImageCache}
+	images, err := cache.FindImages(helmClient, valuesYaml, chart, version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine the images of chart version %s: %w", version, err)
+	}
+	return images, nil
+}
+
+// bundleImages returns the validated images of the bundle file, with duplicates removed.
+func bundleImages(file string) ([]string, error) {
+	refs, err := readImagesFile(file, "bundle")
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("the bundle file %s lists no images", file)
+	}
+
+	seen := map[string]struct{}{}
+	var images []string
+	for _, ref := range refs {
+		if err := docker.ValidateImageRef(ref); err != nil {
+			return nil, fmt.Errorf("invalid bundle file %s: %w", file, err)
+		}
+		if _, ok := seen[ref]; ok {
+			continue
+		}
+		seen[ref] = struct{}{}
+		images = append(images, ref)
+	}
+	return images, nil
+}
+
+// verifyImages checks that Docker has every one of the images, writing a table of those which are missing or
+// not at their required digest to w. An ErrImagesIncomplete error is returned if any is.
+func verifyImages(ctx context.Context, w io.Writer, d *docker.Docker, images []string) error {
+	verifications, err := d.VerifyImages(ctx, images)
+	if err != nil {
+		pterm.Error.Println("Unable to list the Docker images")
+		return fmt.Errorf("%w: %w", abctl.ErrDocker, err)
+	}
+
+	var incomplete []docker.ImageVerification
+	for _, v := range verifications {
+		if v.Status != docker.ImagePresent {
+			incomplete = append(incomplete, v)
+		}
+	}
+
+	if len(incomplete) == 0 {
+		pterm.Success.Printfln("All %d required images are present", len(verifications))
+		return nil
+	}
+
+	if err := writeImageVerifications(w, incomplete); err != nil {
+		return fmt.Errorf("unable to write the images: %w", err)
+	}
+	return fmt.Errorf("%w: %d of %d images are missing or not at the required digest", abctl.ErrImagesIncomplete, len(incomplete), len(verifications))
+}
+
+// writeImageVerifications writes the verifications to w as a table.
+func writeImageVerifications(w io.Writer, verifications []docker.ImageVerification) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "IMAGE\tSTATUS\tPRESENT DIGEST")
+	for _, v := range verifications {
+		present := "-"
+		if len(v.Digests) > 0 {
+			present = strings.Join(v.Digests, ",")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.Ref, v.Status, present)
+	}
+	return tw.Flush()
+}
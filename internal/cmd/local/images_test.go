@@ -0,0 +1,118 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types/image"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBundleImages(t *testing.T) {
+	bundle := filepath.Join(t.TempDir(), "images.txt")
+	content := `# airbyte 1.6.0
+airbyte/server:1.6.0@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa
+
+airbyte/worker:1.6.0
+airbyte/worker:1.6.0
+`
+	if err := os.WriteFile(bundle, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := bundleImages(bundle)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := []string{
+		"airbyte/server:1.6.0@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+		"airbyte/worker:1.6.0",
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("images mismatch (-want +got):\n%s", d)
+	}
+
+	if err := os.WriteFile(bundle, []byte("# no images\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundleImages(bundle); err == nil {
+		t.Error("expected an error for a bundle without images")
+	}
+
+	if err := os.WriteFile(bundle, []byte("Airbyte/Server\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bundleImages(bundle); err == nil {
+		t.Error("expected an error for an invalid image")
+	}
+}
+
+func TestVerifyImages(t *testing.T) {
+	const (
+		digest      = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		otherDigest = "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	)
+
+	d := &docker.Docker{Client: dockertest.MockClient{
+		FnImageList: func(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+			return []image.Summary{
+				{ID: "sha256:1", RepoTags: []string{"airbyte/server:1.6.0"}, RepoDigests: []string{"airbyte/server@" + digest}},
+				{ID: "sha256:2", RepoTags: []string{"airbyte/worker:1.6.0"}, RepoDigests: []string{"airbyte/worker@" + otherDigest}},
+			}, nil
+		},
+	}}
+
+	tests := []struct {
+		name    string
+		images  []string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "complete",
+			images: []string{"airbyte/server:1.6.0@" + digest, "airbyte/worker:1.6.0"},
+		},
+		{
+			name:   "incomplete",
+			images: []string{"airbyte/server:1.6.0@" + digest, "airbyte/worker:1.6.0@" + digest, "airbyte/webapp:1.6.0"},
+			want: `IMAGE                                                                                         STATUS           PRESENT DIGEST
+airbyte/worker:1.6.0@sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa  digest mismatch  sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb
+airbyte/webapp:1.6.0                                                                          missing          -
+`,
+			wantErr: abctl.ErrImagesIncomplete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := verifyImages(context.Background(), &out, d, tt.images)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if d := cmp.Diff(tt.want, out.String()); d != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestVerifyImages_ListError(t *testing.T) {
+	d := &docker.Docker{Client: dockertest.MockClient{
+		FnImageList: func(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+			return nil, errors.New("test error")
+		},
+	}}
+
+	err := verifyImages(context.Background(), &bytes.Buffer{}, d, []string{"airbyte/server:1.6.0"})
+	if !errors.Is(err, abctl.ErrDocker) {
+		t.Errorf("expected error %v, got %v", abctl.ErrDocker, err)
+	}
+}
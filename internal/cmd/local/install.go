@@ -2,36 +2,128 @@ package local
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/docker"
 	"github.com/airbytehq/abctl/internal/helm"
+	abctlhttp "github.com/airbytehq/abctl/internal/http"
 	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/maps"
+	"github.com/airbytehq/abctl/internal/output"
+	"github.com/airbytehq/abctl/internal/paths"
 	"github.com/airbytehq/abctl/internal/service"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/airbytehq/abctl/internal/trace"
 	goHelm "github.com/mittwald/go-helm-client"
 	"github.com/pterm/pterm"
 	"go.opentelemetry.io/otel/attribute"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // InstallCmd contains the arguments used when executing the install command.
 type InstallCmd struct {
-	Chart           string   `help:"Path to chart." xor:"chartver"`
-	ChartVersion    string   `help:"Version to install." xor:"chartver"`
-	DisableAuth     bool     `help:"Disable auth."`
-	DockerEmail     string   `group:"docker" help:"Docker email." env:"ABCTL_LOCAL_INSTALL_DOCKER_EMAIL"`
-	DockerPassword  string   `group:"docker" help:"Docker password." env:"ABCTL_LOCAL_INSTALL_DOCKER_PASSWORD"`
-	DockerServer    string   `group:"docker" default:"https://index.docker.io/v1/" help:"Docker server." env:"ABCTL_LOCAL_INSTALL_DOCKER_SERVER"`
-	DockerUsername  string   `group:"docker" help:"Docker username." env:"ABCTL_LOCAL_INSTALL_DOCKER_USERNAME"`
-	Host            []string `help:"HTTP ingress host."`
-	InsecureCookies bool     `help:"Allow cookies to be served over HTTP."`
-	LowResourceMode bool     `help:"Run Airbyte in low resource mode."`
-	NoBrowser       bool     `help:"Disable launching a browser post install."`
-	Port            int      `default:"8000" help:"HTTP ingress port."`
-	Secret          []string `type:"existingfile" help:"An Airbyte helm chart secret file."`
-	Values          string   `type:"existingfile" help:"An Airbyte helm chart values file to configure helm."`
-	Volume          []string `help:"Additional volume mounts. Must be in the format <HOST_PATH>:<GUEST_PATH>."`
+	AdminEmail             string                   `help:"Email address of the admin login."`
+	AdminPassword          string                   `help:"Password of the admin login, instead of a generated one." env:"ABCTL_LOCAL_INSTALL_ADMIN_PASSWORD" xor:"adminpw"`
+	AdminPasswordFile      string                   `type:"existingfile" help:"A file containing the password of the admin login, instead of a generated one." xor:"adminpw"`
+	Adopt                  bool                     `help:"Take ownership of an existing cluster which was not created by abctl."`
+	AffinityPreset         string                   `default:"none" enum:"none,spread,pack" help:"Spread the platform components across the nodes, or pack them onto as few nodes as possible (spread, pack or none). Only preferred, so a single node cluster is unaffected."`
+	Annotation             []string                 `help:"An annotation to add to every Airbyte object, in the format <KEY>=<VALUE>. May be specified multiple times."`
+	Chart                  string                   `help:"Path to chart." xor:"chartver"`
+	ChartFlavor            string                   `default:"community" enum:"community,enterprise" help:"Flavor of the Airbyte chart to install (community or enterprise). The enterprise flavor requires --license-key."`
+	ChartValuesFromRelease bool                     `help:"Use the helm chart values of an existing Airbyte installation as the base values of this one, e.g. to reproduce its configuration on a new machine. Read from the abctl cluster, or from the cluster of --kubeconfig. The values abctl provides, the values layers, --values and --set-file are merged over them. Values which only apply to the cluster of the existing installation, such as its URL, image pull secrets, storage class, node selectors and hostPath volumes, are omitted."`
+	ChartVersion           string                   `help:"Version to install." xor:"chartver"`
+	Components             []string                 `help:"Enable (+<NAME>) or disable (-<NAME>) an optional Airbyte component (connector-builder, cron or metrics), e.g. --components=-connector-builder,-cron. May be specified multiple times."`
+	ConnectorImages        []string                 `help:"A connector image to load into the cluster after installation (e.g. airbyte/source-postgres:3.6.0). May be specified multiple times."`
+	ConnectorImagesFrom    string                   `type:"existingfile" help:"A file of connector images to load into the cluster after installation, one per line."`
+	ContextSwitchBack      bool                     `default:"true" help:"With --merge-kubeconfig, switch the current kubectl context back to the previous one once the installation ends."`
+	DataVolumeSize         string                   `help:"Size of the database volume (e.g. 10Gi). Defaults to 500Mi."`
+	DisableAuth            bool                     `help:"Disable auth."`
+	DockerEmail            string                   `group:"docker" help:"Docker email." env:"ABCTL_LOCAL_INSTALL_DOCKER_EMAIL"`
+	DockerPassword         string                   `group:"docker" help:"Docker password." env:"ABCTL_LOCAL_INSTALL_DOCKER_PASSWORD"`
+	DockerServer           string                   `group:"docker" default:"https://index.docker.io/v1/" help:"Docker server." env:"ABCTL_LOCAL_INSTALL_DOCKER_SERVER"`
+	DockerUsername         string                   `group:"docker" help:"Docker username." env:"ABCTL_LOCAL_INSTALL_DOCKER_USERNAME"`
+	EmitEvents             bool                     `help:"Display the Kubernetes events of the Airbyte components as they occur during installation."`
+	EnvFromConfigmap       []string                 `help:"An existing config map in the airbyte-abctl namespace whose keys are given to the platform components as environment variables. On an external cluster, it must exist. May be specified multiple times."`
+	EnvFromSecret          []string                 `help:"An existing secret in the airbyte-abctl namespace whose keys are given to the platform components as environment variables. On an external cluster, it must exist. May be specified multiple times."`
+	Force                  bool                     `help:"Continue even if the requested --data-volume-size is smaller than the existing database volume."`
+	FromSnapshot           string                   `type:"existingfile" help:"Seed a fresh installation from a snapshot (e.g. airbyte-backup.tar.gz), installing the chart version recorded in the snapshot and restoring its data."`
+	HelmHistoryMax         int                      `default:"10" help:"How many revisions helm retains of each release, removing older ones as Airbyte is upgraded, so they don't accumulate in the cluster. Only the retained revisions can be rolled back to. 0 retains every revision."`
+	HelmTimeout            time.Duration            `help:"How long helm waits for the resources of a chart to be ready before failing the installation (e.g. 30m). Defaults to 60m, longer than the readiness timeouts of the components, so a component which doesn't become ready is reported first."`
+	HookIgnoreErrors       bool                     `help:"Do not fail the installation if a post-install hook fails."`
+	Host                   []string                 `help:"HTTP ingress host."`
+	Ignore                 []string                 `help:"Never wait on the pods with this label, in the format <KEY>=<VALUE> (e.g. app=metrics), such as optional components known to be slow. May be specified multiple times."`
+	ImageLoadParallelism   int                      `default:"4" help:"How many images are loaded into the cluster node concurrently."`
+	ImagePrefixMap         []string                 `help:"Remap the repository of every image pulled by abctl, in the format <OLD>=<NEW> (e.g. airbyte/=myorg/airbyte-mirror/). The longest matching prefix wins, tags and digests are kept. May be specified multiple times."`
+	ImagePrefixMapFile     string                   `type:"existingfile" help:"A file of image repository prefixes to remap, one <OLD>=<NEW> per line. Combined with any --image-prefix-map."`
+	IngressClass           string                   `help:"Serve Airbyte through the existing ingress controller of this ingress class (e.g. traefik), instead of installing the nginx ingress controller."`
+	InsecureCookies        bool                     `help:"Allow cookies to be served over HTTP."`
+	KubeContext            string                   `help:"With --chart-values-from-release, the context of the --kubeconfig to read the release from. Defaults to its current context."`
+	Kubeconfig             string                   `type:"existingfile" help:"With --chart-values-from-release, a kubeconfig of the cluster to read the release from, instead of the abctl cluster."`
+	Label                  []string                 `help:"A label to add to every Airbyte object, in the format <KEY>=<VALUE>. May be specified multiple times."`
+	LicenseKey             string                   `help:"Airbyte Enterprise license key, required by --chart-flavor enterprise." env:"ABCTL_LOCAL_INSTALL_LICENSE_KEY"`
+	Layer                  []string                 `aliases:"values-layer" help:"A named values layer to apply (see 'local layers list'). May be specified multiple times, later layers override earlier ones and --values overrides every layer."`
+	ListPreflights         bool                     `help:"List the names of the pre-flight checks, which --skip-preflight accepts, and exit."`
+	LowResourceMode        bool                     `help:"Run Airbyte in low resource mode." xor:"resources"`
+	MaxDownloadRate        string                   `help:"Limit the aggregate bandwidth of the image pulls and of the HTTP downloads made by abctl itself (e.g. 5MB/s)."`
+	MaxRetries             int                      `help:"Retry the installation up to this many times if it fails with a retriable error (e.g. a network, transient Docker or image pull failure), rolling back the failed attempt before each retry."`
+	MergeKubeconfig        bool                     `help:"Merge the context of the cluster into your kubeconfig (honoring KUBECONFIG), so kubectl can access the cluster."`
+	NoBrowser              bool                     `help:"Disable launching a browser post install."`
+	NodeExtraMount         []string                 `help:"Bind mount a host path into the cluster node when it is created, in the format <HOST_PATH>=<NODE_PATH>[:ro] (e.g. ./connectors=/connectors:ro). Pods can then mount the node path as a hostPath volume. May be specified multiple times."`
+	NodeLabel              []string                 `help:"A label to add to the cluster node when it is created. Must be in the format <KEY>=<VALUE>. May be specified multiple times."`
+	NodeReadyTimeout       time.Duration            `help:"How long to wait for the node of a created cluster to register as Ready with Kubernetes, before any helm chart is installed (e.g. 10m). Defaults to 5m. Separate from the readiness timeouts of the Airbyte components."`
+	NodeTaint              []string                 `help:"A taint to add to the cluster node when it is created, which the Airbyte pods will tolerate. Must be in the format <KEY>[=<VALUE>]:<EFFECT>. May be specified multiple times."`
+	NoDefaultValues        bool                     `help:"Do not apply the helm chart values provided by abctl, only the chart defaults and the user provided values. Unsupported."`
+	NoSchemaValidate       bool                     `help:"Skip validating the helm chart values against the chart's values schema."`
+	Offline                bool                     `help:"Never fetch the index of the helm chart repository, which is only needed to find the latest chart version, e.g. on a machine without internet access. Requires --chart-version, or --chart with a local chart."`
+	OutputFormat           output.Format            `default:"table" enum:"table,yaml,json" help:"Format of the summary printed once the installation completes (table, yaml or json). With yaml or json, the summary is always printed, to stdout, and every other output is sent to stderr."`
+	Port                   portFlag                 `default:"8000" help:"HTTP ingress port, or 'auto' to select an available port."`
+	PostInstallHook        []string                 `sep:"none" help:"A command to run after a successful installation. May be specified multiple times."`
+	PreflightOnly          bool                     `help:"Only run the pre-flight checks (Docker, its version, resources, disk space, connectivity and the port), print their results and exit, without creating anything. Exits with a non-zero code by the category of the first failing check."`
+	ProbeDefaults          string                   `help:"Apply a preset of liveness and readiness probe timings to the platform components (slow or very-slow), for machines on which they start slowly, e.g. together with --low-resource-mode."`
+	ProbeFailureThreshold  int                      `help:"How many consecutive liveness or readiness probes of a platform component must fail before it is restarted or marked unready. Overrides --probe-defaults."`
+	ProbeInitialDelay      time.Duration            `help:"How long after a platform component starts before it is first probed (e.g. 2m). Overrides --probe-defaults."`
+	ProbeTimeout           time.Duration            `help:"How long a liveness or readiness probe of a platform component may take before it fails (e.g. 10s). Overrides --probe-defaults."`
+	Progress               progressMode             `default:"auto" enum:"auto,plain,tty" help:"How to display the progress of the installation (auto, plain or tty). With tty, a spinner is redrawn in place. With plain, a line is printed for every step, repeated with its percentage while it runs, for logs and CI. With auto, tty is used for a terminal and plain otherwise."`
+	PullSecret             []string                 `sep:"none" help:"An image pull secret to create in the Airbyte namespace and give to every Airbyte pod, in the format name=<NAME>,registry=<SERVER>,user=<USER>,password=<PASSWORD>[,email=<EMAIL>] or name=<NAME>,config=<DOCKER_CONFIG_PATH>. May be specified multiple times."`
+	RefreshImageCache      bool                     `help:"Resolve the images of the chart again, instead of using the ones cached for its version and flavor."`
+	RegistryMirror         string                   `help:"Pull all images through this registry mirror host (e.g. mirror.example.com:5000)."`
+	ReportFile             string                   `help:"Write a report of the installation to this path once it ends, whether it succeeded or failed, for audits and support tickets: the environment, chart version, image digests, helm values keys, phase timings and warnings. Written as json if the path ends in .json, otherwise as markdown. The helm values themselves are never included."`
+	ResourcesPreset        string                   `help:"Apply curated resource requests and limits to the Airbyte components (small, medium or large)." xor:"resources"`
+	RollbackOnFailure      bool                     `help:"Roll back the resources created by a failed or interrupted installation, such as a newly created cluster, instead of keeping them. An existing cluster and the persisted data are always kept."`
+	Secret                 []string                 `type:"existingfile" help:"An Airbyte helm chart secret file."`
+	SetFile                []string                 `sep:"none" help:"Set a helm chart value to the contents of a file, in the format <KEY>=<PATH> (e.g. tls.crt=./cert.pem), overriding --values. May be specified multiple times."`
+	SkipDockerCheck        bool                     `help:"Skip checking for a Docker installation."`
+	SkipPreflight          []string                 `help:"Skip the named pre-flight checks (docker, docker-version, resources, disk, connectivity or port), keeping every other check enforced, e.g. for a false positive. See --list-preflights."`
+	StallTimeout           time.Duration            `help:"Only fail a component once it has made no progress towards ready for this long (e.g. 5m), instead of after a fixed timeout. Components given a --timeout-per-component keep their fixed timeout."`
+	StorageClass           string                   `help:"The storage class of the Airbyte persistent volume claims (e.g. gp3). Defaults to the built-in 'standard' class of the kind cluster. On an external cluster, the class must exist."`
+	Strict                 bool                     `help:"Fail the installation if any pre-flight check warns (e.g. low resources, an emulated architecture, a skewed clock or an unsupported Docker version), instead of continuing with a warning."`
+	SummaryOnly            bool                     `help:"Suppress the intermediate progress output, printing only a concise summary, including any warnings, once the installation completes."`
+	TLSSecret              string                   `help:"The name of an existing TLS secret in the airbyte-abctl namespace, holding the certificate of the --host. Requires --ingress-class."`
+	TimeoutPerComponent    map[string]time.Duration `help:"Override the readiness timeout of a specific component (e.g. db=30m). May be specified multiple times."`
+	UseContext             bool                     `help:"With --merge-kubeconfig, keep the context of the cluster as the current kubectl context, instead of switching back to the previous one."`
+	Values                 string                   `type:"existingfile" help:"An Airbyte helm chart values file to configure helm."`
+	ValuesDump             string                   `help:"Write the fully-merged helm chart values, with secrets redacted, to this path before the cluster is created, then continue the installation. Use '-' for stdout."`
+	ValuesEnvExpand        bool                     `help:"Expand the environment variable references ($${VAR}, $${VAR:-default} or $VAR) in the --values file. An undefined variable without a default is an error, and $$$$ is a literal $."`
+	ValuesMergeStrategy    string                   `default:"replace" enum:"deep,replace" help:"How the lists of the --values file are merged with those of the values layers and of abctl (deep or replace). With replace, as with helm, a list replaces the list it overrides. With deep, entries identified by the same name, or key, are merged and any other entries are appended."`
+	VerifySync             bool                     `aliases:"wait-for-sync-ability" help:"Once installed, verify that the components a sync flows through (server, workload api, temporal, worker and workload launcher) accept work, failing with the first which doesn't. Slower, so off by default."`
+	Volume                 []string                 `help:"Additional volume mounts. Must be in the format <HOST_PATH>:<GUEST_PATH>."`
+	WaitForSelector        []string                 `help:"Also wait for the pods with this label to be ready, in the format <KEY>=<VALUE> (e.g. app=metrics), such as those added through --values. Without it, only the default Airbyte components are waited on. May be specified multiple times."`
+	WaitForURL             string                   `help:"Declare Airbyte reachable once this path returns one of the given status codes, in the format <PATH>[:<STATUS>[,<STATUS>...]] (e.g. /api/v1/health:200,204), for an Airbyte fronted by auth or redirects which make its root unreliable. Defaults to the root path, accepting a 200 or the 401 of the abctl basic auth."`
+	WaitJobs               bool                     `default:"true" help:"Wait for the jobs created by the Airbyte chart to complete successfully, printing the logs of any which fail. Pass --wait-jobs=false to only wait for the components to be ready."`
+
+	// baseValues are the values read from the existing release with --chart-values-from-release.
+	baseValues map[string]any
 }
 
 // Run executes the install command which creates the Kind cluster and installs the Airbyte service.
@@ -39,6 +131,28 @@ func (i *InstallCmd) Run(ctx context.Context, provider k8s.Provider, newSvcMgrCl
 	ctx, span := trace.NewSpan(ctx, "local install")
 	defer span.End()
 
+	if i.ListPreflights {
+		printPreflightChecks(os.Stdout)
+		return nil
+	}
+
+	// only the summary may be written to stdout, send everything else to stderr
+	if i.OutputFormat.MachineReadable() {
+		pterm.SetDefaultOutput(os.Stderr)
+		defer pterm.SetDefaultOutput(os.Stdout)
+	}
+
+	// record the warnings from the start, so the summary and report include every warning of the run
+	var warnings *warningRecorder
+	switch {
+	case i.SummaryOnly:
+		warnings = newWarningRecorder(io.Discard)
+		defer summaryOutput(warnings)()
+	case i.ReportFile != "" || i.OutputFormat.MachineReadable():
+		warnings = newWarningRecorder(warningWriter())
+		defer recordWarnings(warnings)()
+	}
+
 	// Parse and validate extra volume mounts early to catch user input errors
 	// before proceeding with the installation process.
 	extraVolumeMounts, err := k8s.ParseVolumeMounts(i.Volume)
@@ -46,18 +160,225 @@ func (i *InstallCmd) Run(ctx context.Context, provider k8s.Provider, newSvcMgrCl
 		return fmt.Errorf("failed to parse the extra volume mounts: %w", err)
 	}
 
-	spinner := &pterm.DefaultSpinner
-	spinner, _ = spinner.Start("Starting installation")
-	spinner.UpdateText("Checking for Docker installation")
+	nodeMounts, err := k8s.ParseNodeExtraMounts(i.NodeExtraMount)
+	if err != nil {
+		return fmt.Errorf("failed to parse the node extra mounts: %w", err)
+	}
+	extraVolumeMounts = append(extraVolumeMounts, nodeMounts...)
+
+	nodeLabels, err := k8s.ParseNodeLabels(i.NodeLabel)
+	if err != nil {
+		return fmt.Errorf("failed to parse the node labels: %w", err)
+	}
+
+	nodeTaints, err := k8s.ParseNodeTaints(i.NodeTaint)
+	if err != nil {
+		return fmt.Errorf("failed to parse the node taints: %w", err)
+	}
+
+	if _, err := k8s.ParseLabels(i.Label); err != nil {
+		return fmt.Errorf("failed to parse the labels: %w", err)
+	}
+
+	if _, err := k8s.ParseAnnotations(i.Annotation); err != nil {
+		return fmt.Errorf("failed to parse the annotations: %w", err)
+	}
+
+	if _, err := service.ParsePullSecrets(i.PullSecret); err != nil {
+		return fmt.Errorf("failed to parse the pull secrets: %w", err)
+	}
+
+	if _, err := k8s.ParseLabelSelectors(i.WaitForSelector); err != nil {
+		return fmt.Errorf("failed to parse the wait for selectors: %w", err)
+	}
+
+	if _, err := k8s.ParseLabelSelectors(i.Ignore); err != nil {
+		return fmt.Errorf("failed to parse the ignore selectors: %w", err)
+	}
+
+	if _, err := service.ParseReadinessEndpoint(i.WaitForURL); err != nil {
+		return fmt.Errorf("failed to parse the wait for url: %w", err)
+	}
+
+	for component, timeout := range i.TimeoutPerComponent {
+		if timeout <= 0 {
+			return fmt.Errorf("invalid timeout for component '%s': must be greater than zero", component)
+		}
+	}
+
+	if i.StallTimeout < 0 {
+		return fmt.Errorf("invalid stall timeout %s: must not be negative", i.StallTimeout)
+	}
+
+	if i.HelmTimeout < 0 {
+		return fmt.Errorf("invalid helm timeout %s: must not be negative", i.HelmTimeout)
+	}
+
+	if i.NodeReadyTimeout < 0 {
+		return fmt.Errorf("invalid node ready timeout %s: must not be negative", i.NodeReadyTimeout)
+	}
+
+	if i.HelmHistoryMax < 0 {
+		return fmt.Errorf("invalid helm history max %d: must not be negative", i.HelmHistoryMax)
+	}
+
+	if i.MaxRetries < 0 {
+		return fmt.Errorf("invalid max retries %d: must not be negative", i.MaxRetries)
+	}
+
+	if _, err := parseDataVolumeSize(i.DataVolumeSize); err != nil {
+		return fmt.Errorf("failed to parse the data volume size: %w", err)
+	}
+
+	if _, err := helm.ResolveLayers(paths.Layers, i.Layer); err != nil {
+		return fmt.Errorf("failed to resolve the values layers: %w", err)
+	}
+
+	if _, err := helm.ParseSetFiles(i.SetFile); err != nil {
+		return fmt.Errorf("failed to parse the set files: %w", err)
+	}
+
+	if _, err := i.probeSettings(); err != nil {
+		return err
+	}
+
+	if err := i.checkNoDefaultValues(); err != nil {
+		return err
+	}
+
+	if err := checkChartFlavor(i.ChartFlavor, i.LicenseKey); err != nil {
+		return err
+	}
+
+	if err := k8s.SetImageLoadParallelism(i.ImageLoadParallelism); err != nil {
+		return err
+	}
+
+	if i.TLSSecret != "" && i.IngressClass == "" {
+		return errors.New("the --tls-secret flag requires the --ingress-class flag, the nginx ingress controller installed by abctl only serves HTTP")
+	}
+
+	if (i.Kubeconfig != "" || i.KubeContext != "") && !i.ChartValuesFromRelease {
+		return errors.New("the --kubeconfig and --kube-context flags require the --chart-values-from-release flag")
+	}
+
+	var snapshot snapshotManifest
+	if i.FromSnapshot != "" {
+		if snapshot, err = readSnapshotManifest(i.FromSnapshot); err != nil {
+			return fmt.Errorf("failed to read the snapshot: %w", err)
+		}
+		if err := checkSnapshotVersion(snapshot.ChartVersion, i.ChartVersion); err != nil {
+			return err
+		}
+		if err := checkSnapshotTarget(paths.Data); err != nil {
+			return err
+		}
+		// install the chart version which wrote the data, unless another (compatible) one was requested
+		if i.Chart == "" && i.ChartVersion == "" {
+			i.ChartVersion = snapshot.ChartVersion
+		}
+	}
+
+	var preset helm.ResourcesPreset
+	if i.ResourcesPreset != "" {
+		if preset, err = helm.ResourcesPresetFor(i.ResourcesPreset); err != nil {
+			return err
+		}
+	}
+
+	connectorImgs, err := connectorImages(i.ConnectorImages, i.ConnectorImagesFrom)
+	if err != nil {
+		return fmt.Errorf("failed to parse the connector images: %w", err)
+	}
+
+	if i.AdminEmail != "" {
+		if err := validateAdminEmail(i.AdminEmail); err != nil {
+			return err
+		}
+	}
+
+	if i.RegistryMirror != "" {
+		if err := docker.ValidateRegistryMirror(i.RegistryMirror); err != nil {
+			return err
+		}
+	}
+
+	imagePrefixes, err := imagePrefixMap(i.ImagePrefixMap, i.ImagePrefixMapFile)
+	if err != nil {
+		return fmt.Errorf("failed to parse the image prefix map: %w", err)
+	}
 
-	_, err = dockerInstalled(ctx, telClient)
+	var downloadLimiter *abctlhttp.RateLimiter
+	if i.MaxDownloadRate != "" {
+		rate, err := abctlhttp.ParseRate(i.MaxDownloadRate)
+		if err != nil {
+			return fmt.Errorf("failed to parse the max download rate: %w", err)
+		}
+		downloadLimiter = abctlhttp.NewRateLimiter(rate)
+		// the image pulls share the limit with the downloads of abctl's http client
+		abctlhttp.SetDownloadLimiter(downloadLimiter)
+	}
+
+	preflightSkips, err := parsePreflightSkips(i.SkipPreflight)
 	if err != nil {
-		pterm.Error.Println("Unable to determine if Docker is installed")
-		return fmt.Errorf("unable to determine docker installation status: %w", err)
+		return err
+	}
+
+	if i.PreflightOnly {
+		return i.preflightOnly(ctx, provider.RequiresDocker(), preset, preflightSkips)
+	}
+
+	// read the release before creating anything, so a release which can't be read fails the installation early
+	if i.ChartValuesFromRelease {
+		if err := i.readReleaseValues(provider, newSvcMgrClients); err != nil {
+			return err
+		}
+	}
+
+	// with a machine readable output format, the progress is written to stderr
+	progressOut := os.Stdout
+	if i.OutputFormat.MachineReadable() {
+		progressOut = os.Stderr
+	}
+	progress := newProgress(i.Progress.resolve(isTerminal(progressOut)))
+	progress.Start("Starting installation")
+	progress.UpdateText("Checking for Docker installation")
+
+	span.SetAttributes(attribute.Bool("strict", i.Strict))
+	checks := &preflightWarnings{strict: i.Strict, skip: preflightSkips}
+
+	if err := checkDockerRequired(ctx, telClient, provider, i.SkipDockerCheck, checks); err != nil {
+		reportPreflight(ctx, telClient, telemetry.Install, err)
+		return err
+	}
+
+	if i.ResourcesPreset != "" && dockerClient != nil && !checks.skips(preflightResources) {
+		if info, err := dockerClient.Client.Info(ctx); err == nil {
+			checkResourcesPreset(checks, preset, info.NCPU, info.MemTotal)
+		}
 	}
 
-	return telClient.Wrap(ctx, telemetry.Install, func() error {
-		spinner.UpdateText(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
+	checkHelmTimeout(checks, i.HelmTimeout, i.componentTimeouts())
+
+	if err := checks.err(); err != nil {
+		reportPreflight(ctx, telClient, telemetry.Install, err)
+		return err
+	}
+
+	// dumped before the cluster is created, so the values can be inspected even if the installation fails
+	if i.ValuesDump != "" {
+		if err := i.dumpValues(ctx, telClient.User()); err != nil {
+			return err
+		}
+	}
+
+	// resources created by this install, which are rolled back if the install fails or is interrupted
+	rb := &rollback{}
+	// what the install did, for the report
+	run := installRun{chart: i.Chart}
+
+	install := func() error {
+		progress.UpdateText(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
 
 		cluster, err := provider.Cluster(ctx)
 		if err != nil {
@@ -65,43 +386,90 @@ func (i *InstallCmd) Run(ctx context.Context, provider k8s.Provider, newSvcMgrCl
 			return err
 		}
 
-		if cluster.Exists(ctx) {
+		clusterExists := cluster.Exists(ctx)
+		if clusterExists && i.FromSnapshot != "" {
+			pterm.Error.Printfln("A snapshot can only seed a fresh installation, but cluster '%s' already exists", provider.ClusterName)
+			return fmt.Errorf("%w: cluster '%s' already exists", abctl.ErrSnapshotTarget, provider.ClusterName)
+		}
+		if clusterExists {
 			// existing cluster, validate it
 			pterm.Success.Printfln("Existing cluster '%s' found", provider.ClusterName)
-			spinner.UpdateText(fmt.Sprintf("Validating existing cluster '%s'", provider.ClusterName))
+			progress.UpdateText(fmt.Sprintf("Validating existing cluster '%s'", provider.ClusterName))
 			span.SetAttributes(attribute.Bool("cluster_exists", true))
 
 			// only for kind do we need to check the existing port
 			if provider.Name == k8s.Kind {
 				providedPort := i.Port
-				i.Port, err = getPort(ctx, provider.ClusterName)
+				port, err := getPort(ctx, provider.ClusterName)
 				if err != nil {
 					return err
 				}
-				if providedPort != i.Port {
+				i.Port = portFlag(port)
+				if providedPort != autoPort && providedPort != i.Port {
 					pterm.Warning.Printfln("The existing cluster was found to be using port %d, which differs from the provided port %d.\n"+
 						"The existing port will be used, as changing ports currently requires the existing installation to be uninstalled first.", i.Port, providedPort)
 				}
 			}
 
+			if i.Port == autoPort {
+				i.Port = portFlag(autoPortMin)
+				pterm.Warning.Printfln("The port of the existing cluster can only be determined for a kind cluster, port %d will be used.\n"+
+					"Pass --port with the port the existing cluster is available on, if it differs.", i.Port)
+			}
+
+			if len(nodeLabels) > 0 || len(nodeTaints) > 0 || len(nodeMounts) > 0 {
+				pterm.Warning.Println("The --node-label, --node-taint and --node-extra-mount flags are only applied when the cluster is created, the existing cluster node is unchanged")
+			}
+
 			pterm.Success.Printfln("Cluster '%s' validation complete", provider.ClusterName)
 		} else {
 			// no existing cluster, need to create one
 			pterm.Info.Println(fmt.Sprintf("No existing cluster found, cluster '%s' will be created", provider.ClusterName))
 			span.SetAttributes(attribute.Bool("cluster_exists", false))
 
-			spinner.UpdateText(fmt.Sprintf("Checking if port %d is available", i.Port))
-			if err := portAvailable(ctx, i.Port); err != nil {
-				return err
+			var reservation *portReservation
+			if i.Port == autoPort {
+				progress.UpdateText(fmt.Sprintf("Selecting an available port between %d and %d", autoPortMin, autoPortMax))
+				if reservation, err = reservePort(ctx, autoPortMin, autoPortMax); err != nil {
+					reportPreflight(ctx, telClient, telemetry.Install, err)
+					return err
+				}
+				i.Port = portFlag(reservation.Port)
+				pterm.Success.Printfln("Selected available port %d", i.Port)
+			} else if !checks.skips(preflightPort) {
+				progress.UpdateText(fmt.Sprintf("Checking if port %d is available", i.Port))
+				if err := portAvailable(ctx, int(i.Port), checks); err != nil {
+					reportPreflight(ctx, telClient, telemetry.Install, err)
+					return err
+				}
+				pterm.Success.Printfln("Port %d appears to be available", i.Port)
 			}
-			pterm.Success.Printfln("Port %d appears to be available", i.Port)
-			spinner.UpdateText(fmt.Sprintf("Creating cluster '%s'", provider.ClusterName))
+			progress.UpdateText(fmt.Sprintf("Creating cluster '%s'", provider.ClusterName))
 
-			if err := cluster.Create(ctx, i.Port, extraVolumeMounts); err != nil {
+			// hold the selected port until the cluster is about to bind it
+			if reservation != nil {
+				if err := reservation.Release(); err != nil {
+					return fmt.Errorf("unable to release port %d: %w", reservation.Port, err)
+				}
+			}
+			if err := cluster.Create(ctx, int(i.Port), extraVolumeMounts, k8s.NodeOpts{Labels: nodeLabels, Taints: nodeTaints}); err != nil {
 				pterm.Error.Printfln("Cluster '%s' could not be created", provider.ClusterName)
 				return err
 			}
 			pterm.Success.Printfln("Cluster '%s' created", provider.ClusterName)
+			rb.add(fmt.Sprintf("cluster '%s'", provider.ClusterName), cluster.Delete)
+		}
+
+		// abort as soon as the engine stops the node, e.g. when it runs out of memory, rather than on a timeout
+		ctx, stopWatch := watchNode(ctx, provider)
+		defer stopWatch()
+
+		if i.MergeKubeconfig {
+			switchBack, err := mergeKubeconfig(provider, k8s.KubeconfigPaths(), i.ContextSwitchBack && !i.UseContext)
+			if err != nil {
+				return err
+			}
+			defer switchBack()
 		}
 
 		// Load the required service manager clients.
@@ -110,11 +478,28 @@ func (i *InstallCmd) Run(ctx context.Context, provider k8s.Provider, newSvcMgrCl
 			return err
 		}
 
+		// a node which isn't Ready yet would only surface as opaque failures of the helm charts
+		if !clusterExists {
+			progress.UpdateText(fmt.Sprintf("Waiting for the node of cluster '%s' to be ready", provider.ClusterName))
+			if err := k8s.WaitForNodesReady(ctx, k8sClient, i.NodeReadyTimeout); err != nil {
+				pterm.Error.Printfln("The node of cluster '%s' did not become ready", provider.ClusterName)
+				return err
+			}
+			pterm.Success.Printfln("Cluster '%s' node is ready", provider.ClusterName)
+		}
+
 		// Determine and set defaults for chart flags.
 		err = i.setDefaultChartFlags(helmClient)
 		if err != nil {
 			return fmt.Errorf("failed to set chart defaults: %w", err)
 		}
+		run.chart, run.chartVersion = i.Chart, i.ChartVersion
+		// a --chart is only resolved to its version now
+		if i.FromSnapshot != "" {
+			if err := checkSnapshotVersion(snapshot.ChartVersion, i.ChartVersion); err != nil {
+				return err
+			}
+		}
 
 		// Overrides Helm chart images.
 		overrideImages := []string{}
@@ -123,39 +508,169 @@ func (i *InstallCmd) Run(ctx context.Context, provider k8s.Provider, newSvcMgrCl
 		if err != nil {
 			return err
 		}
+		run.valuesYAML = opts.HelmValuesYaml
+		if i.FromSnapshot != "" {
+			opts.RestoreVolumes = func() error {
+				return restoreSnapshot(i.FromSnapshot, paths.Data)
+			}
+		}
+
+		if !i.NoSchemaValidate {
+			progress.UpdateText("Validating helm chart values")
+			if err := validateValuesSchema(helmClient, opts); err != nil {
+				return err
+			}
+		}
+
+		// Refuse to install into an existing cluster which isn't managed by abctl, and mark any newly created ones.
+		if clusterExists {
+			if err := checkClusterOwnership(ctx, k8sClient, provider.ClusterName, i.Adopt); err != nil {
+				reportPreflight(ctx, telClient, telemetry.Install, err)
+				return err
+			}
+		} else {
+			if err := k8s.MarkCluster(ctx, k8sClient); err != nil {
+				return err
+			}
+			if err := k8s.TolerateSystemPods(ctx, k8sClient, nodeTaints); err != nil {
+				return err
+			}
+		}
 
 		if opts.EnablePsql17 {
 			overrideImages = append(overrideImages, "airbyte/db:"+helm.Psql17AirbyteTag)
 		}
 
+		// without a docker-client, e.g. a provider which doesn't require docker, the cluster pulls the images itself
+		pullClient := dockerClient
+		if pullClient != nil && downloadLimiter != nil {
+			pterm.Info.Printfln("Limiting image downloads to %s", i.MaxDownloadRate)
+			pullClient = &docker.Docker{Client: docker.NewRateLimitedClient(pullClient.Client, downloadLimiter)}
+		}
+		if pullClient != nil && i.RegistryMirror != "" {
+			pterm.Info.Printfln("Pulling images through the registry mirror %s", i.RegistryMirror)
+			pullClient = &docker.Docker{Client: docker.NewMirrorClient(pullClient.Client, i.RegistryMirror)}
+		}
+		if pullClient != nil && len(imagePrefixes) > 0 {
+			pterm.Info.Printfln("Remapping the repositories of %d image prefixes", len(imagePrefixes))
+			pullClient = &docker.Docker{Client: docker.NewPrefixMapClient(pullClient.Client, imagePrefixes)}
+		}
+
 		svcMgr, err := service.NewManager(provider,
 			service.WithK8sClient(k8sClient),
 			service.WithHelmClient(helmClient),
-			service.WithPortHTTP(i.Port),
+			service.WithPortHTTP(int(i.Port)),
 			service.WithTelemetryClient(telClient),
-			service.WithSpinner(spinner),
-			service.WithDockerClient(dockerClient),
+			service.WithProgress(progress.Event),
+			service.WithDockerClient(pullClient),
 		)
 		if err != nil {
 			pterm.Error.Printfln("Failed to initialize 'local' command")
 			return fmt.Errorf("unable to initialize local command: %w", err)
 		}
 
-		spinner.UpdateText("Pulling images")
-		svcMgr.PrepImages(ctx, cluster, opts, overrideImages...)
+		progress.UpdateText("Pulling images")
+		run.images = svcMgr.PrepImages(ctx, cluster, opts, overrideImages...)
 
 		if err := svcMgr.Install(ctx, opts); err != nil {
-			spinner.Fail("Unable to install Airbyte locally")
-			return err
+			progress.Fail("Unable to install Airbyte locally")
+			return nodeFailure(ctx, err)
 		}
 
-		spinner.Success(
-			"Airbyte installation complete.\n" +
+		url := i.airbyteURL()
+		progress.Success(
+			fmt.Sprintf("Airbyte installation complete, available at %s\n", url) +
 				"  A password may be required to login. The password can by found by running\n" +
 				"  the command " + pterm.LightBlue("abctl local credentials"),
 		)
+		rb.clear()
+
+		if i.AdminEmail != "" {
+			if err := setAdminEmail(ctx, k8sClient, url, i.AdminEmail); err != nil {
+				return err
+			}
+		}
+
+		if len(connectorImgs) > 0 {
+			progress.Start("Loading connector images")
+			loaded := svcMgr.LoadConnectorImages(ctx, cluster, connectorImgs)
+			progress.Success(fmt.Sprintf("Loaded %d of %d connector images", len(loaded), len(connectorImgs)))
+		}
+
+		if len(i.PostInstallHook) > 0 {
+			credsFile, cleanup, err := writeHookCredentials(ctx, k8sClient, url)
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+
+			env := hookEnv{
+				URL:             url,
+				CredentialsFile: credsFile,
+				Kubeconfig:      provider.Kubeconfig,
+				Context:         provider.Context,
+			}
+			// with a machine readable output format only the summary may be written to stdout, as with the progress
+			if err := runPostInstallHooks(ctx, progressOut, i.PostInstallHook, env, i.HookIgnoreErrors); err != nil {
+				return err
+			}
+		}
+
 		return nil
+	}
+
+	span.SetAttributes(attribute.Int("max_retries", i.MaxRetries))
+	err = telClient.Wrap(ctx, telemetry.Install, func() error {
+		return retryInstall(ctx, i.MaxRetries, install, func() {
+			// every attempt starts afresh, the next one could not reuse a cluster which abctl hasn't marked yet
+			handleInstallFailure(ctx, rb, true)
+			progress.Start("Retrying installation")
+		})
 	})
+	if i.ReportFile != "" {
+		journal, jErr := service.ReadJournal(paths.Journal)
+		if jErr != nil {
+			pterm.Debug.Printfln("Unable to read the journal for the report: %s", jErr)
+		}
+		report := buildInstallReport(ctx, dockerClient, run, journal, warnings.warnings(), err)
+		if rErr := writeInstallReport(i.ReportFile, report); rErr != nil && err == nil {
+			err = rErr
+		}
+	}
+	if err != nil {
+		handleInstallFailure(ctx, rb, i.RollbackOnFailure)
+		return err
+	}
+
+	if i.SummaryOnly || i.OutputFormat.MachineReadable() {
+		summary := installSummary{
+			URL:          i.airbyteURL(),
+			Cluster:      provider.ClusterName,
+			Context:      provider.Context,
+			Kubeconfig:   provider.Kubeconfig,
+			Chart:        i.Chart,
+			ChartVersion: i.ChartVersion,
+			StorageClass: summaryStorageClass(provider, i.StorageClass),
+			LicenseKey:   i.LicenseKey,
+			Warnings:     warnings.warnings(),
+		}
+		if summary.Warnings == nil {
+			summary.Warnings = []string{}
+		}
+		return output.Render(os.Stdout, i.OutputFormat, summary)
+	}
+
+	return nil
+}
+
+// ingressOpts returns the ingress of airbyte, served by the nginx ingress controller unless an ingress class is set.
+func (i *InstallCmd) ingressOpts() k8s.IngressOpts {
+	return k8s.IngressOpts{Class: i.IngressClass, TLSSecret: i.TLSSecret}
+}
+
+// airbyteURL returns the url airbyte is available at once installed, https if it is served with a TLS secret.
+func (i *InstallCmd) airbyteURL() string {
+	return service.AirbyteURL(i.Host, int(i.Port), i.ingressOpts())
 }
 
 func (i *InstallCmd) installOpts(ctx context.Context, user string) (*service.InstallOpts, error) {
@@ -188,33 +703,164 @@ func (i *InstallCmd) installOpts(ctx context.Context, user string) (*service.Ins
 		pterm.Warning.Println("PostgreSQL 13 detected. Consider upgrading to PostgreSQL 17")
 	}
 
+	dataVolumeSize, err := parseDataVolumeSize(i.DataVolumeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the data volume size: %w", err)
+	}
+
+	if !dataVolumeSize.IsZero() && dataVolumeSize.Cmp(k8s.DefaultPersistentVolumeSize) < 0 {
+		pterm.Warning.Printfln("A data volume size of %s is below the recommended minimum of %s", dataVolumeSize.String(), k8s.DefaultPersistentVolumeSize.String())
+	}
+
+	adminPass, err := adminPassword(i.AdminPassword, i.AdminPasswordFile)
+	if err != nil {
+		return nil, err
+	}
+	if adminPass != "" {
+		if weaknesses := passwordWeaknesses(adminPass); len(weaknesses) > 0 {
+			pterm.Warning.Printfln("The provided admin password is weak: %s", strings.Join(weaknesses, ", "))
+		}
+	}
+
+	tolerations, err := nodeTolerations(i.NodeTaint)
+	if err != nil {
+		return nil, err
+	}
+
+	components, err := helm.ParseComponentToggles(i.Components)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the components: %w", err)
+	}
+	for _, warning := range helm.ComponentWarnings(components) {
+		pterm.Warning.Println(warning)
+	}
+
+	labels, err := k8s.ParseLabels(i.Label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the labels: %w", err)
+	}
+
+	annotations, err := k8s.ParseAnnotations(i.Annotation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the annotations: %w", err)
+	}
+
+	waitFor, err := k8s.ParseLabelSelectors(i.WaitForSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the wait for selectors: %w", err)
+	}
+
+	ignore, err := k8s.ParseLabelSelectors(i.Ignore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the ignore selectors: %w", err)
+	}
+
+	pullSecrets, err := service.ParsePullSecrets(i.PullSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the pull secrets: %w", err)
+	}
+
+	readinessEndpoint, err := service.ParseReadinessEndpoint(i.WaitForURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the wait for url: %w", err)
+	}
+
+	setFiles, err := helm.ParseSetFiles(i.SetFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the set files: %w", err)
+	}
+	for _, f := range setFiles {
+		pterm.Debug.Printfln("Setting the helm value '%s' to the contents of '%s'", f.Key, f.Path)
+	}
+
+	probes, err := i.probeSettings()
+	if err != nil {
+		return nil, err
+	}
+
 	opts := &service.InstallOpts{
-		HelmChartVersion: i.ChartVersion,
-		AirbyteChartLoc:  i.Chart,
-		Secrets:          i.Secret,
-		Hosts:            i.Host,
-		LocalStorage:     !supportMinio,
-		EnablePsql17:     enablePsql17,
-		DockerServer:     i.DockerServer,
-		DockerUser:       i.DockerUsername,
-		DockerPass:       i.DockerPassword,
-		DockerEmail:      i.DockerEmail,
-		NoBrowser:        i.NoBrowser,
+		HelmChartVersion:  i.ChartVersion,
+		AirbyteChartLoc:   i.Chart,
+		Secrets:           i.Secret,
+		Hosts:             i.Host,
+		LocalStorage:      !supportMinio,
+		EnablePsql17:      enablePsql17,
+		DockerServer:      i.DockerServer,
+		DockerUser:        i.DockerUsername,
+		DockerPass:        i.DockerPassword,
+		DockerEmail:       i.DockerEmail,
+		NoBrowser:         i.NoBrowser,
+		ReadinessEndpoint: readinessEndpoint,
+		AdminPassword:     adminPass,
+		LicenseKey:        i.LicenseKey,
+		EmitEvents:        i.EmitEvents,
+		DataVolumeSize:    dataVolumeSize,
+		AllowVolumeShrink: i.Force,
+		StorageClass:      i.StorageClass,
+		HelmTimeout:       i.HelmTimeout,
+		HelmHistoryMax:    i.HelmHistoryMax,
+		ComponentTimeouts: i.componentTimeouts(),
+		ReadinessSelectors: service.ReadinessSelectors{
+			WaitFor: waitFor,
+			Ignore:  ignore,
+		},
+		WaitJobs:          i.WaitJobs,
+		VerifySync:        i.VerifySync,
+		PullSecrets:       pullSecrets,
+		Tolerations:       tolerations,
+		Ingress:           i.ingressOpts(),
+		EnvFromSecrets:    i.EnvFromSecret,
+		EnvFromConfigMaps: i.EnvFromConfigmap,
+		ImageCache: &helm.ImageCache{
+			Path:    paths.ImageCache,
+			Refresh: i.RefreshImageCache,
+		},
 	}
 
 	valuesOpts := helm.ValuesOpts{
-		ValuesFile:      i.Values,
-		InsecureCookies: i.InsecureCookies,
-		LowResourceMode: i.LowResourceMode,
-		DisableAuth:     i.DisableAuth,
-		LocalStorage:    !supportMinio,
-		EnablePsql17:    enablePsql17,
-		Port:            i.Port,
+		ValuesFile:        i.Values,
+		Layers:            i.Layer,
+		LayersDir:         paths.Layers,
+		InsecureCookies:   i.InsecureCookies,
+		LowResourceMode:   i.LowResourceMode,
+		ResourcesPreset:   i.ResourcesPreset,
+		ChartFlavor:       i.ChartFlavor,
+		StorageClass:      i.StorageClass,
+		DisableAuth:       i.DisableAuth,
+		LocalStorage:      !supportMinio,
+		EnablePsql17:      enablePsql17,
+		Port:              int(i.Port),
+		Tolerations:       tolerations,
+		EnvFromSecrets:    i.EnvFromSecret,
+		EnvFromConfigMaps: i.EnvFromConfigmap,
+		Labels:            labels,
+		Annotations:       annotations,
+		ExpandEnv:         i.ValuesEnvExpand,
+		SetFiles:          setFiles,
+		Probes:            probes,
+		AffinityPreset:    i.AffinityPreset,
+		Components:        components,
+		MergeStrategy:     i.ValuesMergeStrategy,
+		BaseValues:        i.baseValues,
+	}
+
+	if i.IngressClass != "" {
+		valuesOpts.AirbyteURL = service.AirbyteURL(i.Host, int(i.Port), opts.Ingress)
 	}
 
 	if opts.DockerAuth() {
 		valuesOpts.ImagePullSecret = common.DockerAuthSecretName
 	}
+	for _, p := range pullSecrets {
+		valuesOpts.ImagePullSecrets = append(valuesOpts.ImagePullSecrets, p.Name)
+	}
+
+	if i.NoDefaultValues {
+		valuesOpts.NoDefaultValues = true
+		pterm.Warning.Println("Installing without the helm chart values provided by abctl, this is unsupported.\n" +
+			"Any values abctl requires, such as the auth, storage, ingress, image pull secret or node taint toleration configuration,\n" +
+			"must be provided with --values or --layer.")
+	}
 
 	// only override the empty telUser if the tel.User returns a non-nil (uuid.Nil) value.
 	if user != "" {
@@ -229,11 +875,161 @@ func (i *InstallCmd) installOpts(ctx context.Context, user string) (*service.Ins
 
 	opts.HelmValuesYaml = valuesYAML
 
+	if len(labels) > 0 {
+		pterm.Info.Printfln("Applying the labels %s to the Airbyte objects", joinKeyValues(labels))
+	}
+	if len(annotations) > 0 {
+		pterm.Info.Printfln("Applying the annotations %s to the Airbyte objects", joinKeyValues(annotations))
+	}
+
 	return opts, nil
 }
 
+// imagePrefixMap returns the image prefix map of the specs, followed by those of the file if one is provided.
+func imagePrefixMap(specs []string, path string) (docker.ImagePrefixMap, error) {
+	if path != "" {
+		fileSpecs, err := docker.ReadImagePrefixMapFile(path)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(append([]string{}, specs...), fileSpecs...)
+	}
+	return docker.ParseImagePrefixMap(specs)
+}
+
+// joinKeyValues returns the map as a comma separated list of <KEY>=<VALUE>, sorted by key.
+func joinKeyValues(m map[string]string) string {
+	kvs := make([]string, 0, len(m))
+	for k, v := range m {
+		kvs = append(kvs, k+"="+v)
+	}
+	sort.Strings(kvs)
+	return strings.Join(kvs, ", ")
+}
+
+// nodeTolerations returns a toleration for each of the node taint specs.
+func nodeTolerations(specs []string) ([]corev1.Toleration, error) {
+	taints, err := k8s.ParseNodeTaints(specs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the node taints: %w", err)
+	}
+
+	var tolerations []corev1.Toleration
+	for _, t := range taints {
+		tolerations = append(tolerations, t.Toleration())
+	}
+	return tolerations, nil
+}
+
+// checkNoDefaultValues returns an error if --no-default-values is combined with a flag which only configures
+// the values provided by abctl, as that flag would be silently ignored.
+func (i *InstallCmd) checkNoDefaultValues() error {
+	if !i.NoDefaultValues {
+		return nil
+	}
+
+	conflicts := map[string]bool{
+		"--disable-auth":            i.DisableAuth,
+		"--insecure-cookies":        i.InsecureCookies,
+		"--low-resource-mode":       i.LowResourceMode,
+		"--resources-preset":        i.ResourcesPreset != "",
+		"--chart-flavor":            i.ChartFlavor != "" && i.ChartFlavor != helm.FlavorCommunity,
+		"--label":                   len(i.Label) > 0,
+		"--annotation":              len(i.Annotation) > 0,
+		"--pull-secret":             len(i.PullSecret) > 0,
+		"--probe-defaults":          i.ProbeDefaults != "",
+		"--probe-failure-threshold": i.ProbeFailureThreshold != 0,
+		"--probe-initial-delay":     i.ProbeInitialDelay != 0,
+		"--probe-timeout":           i.ProbeTimeout != 0,
+		"--env-from-secret":         len(i.EnvFromSecret) > 0,
+		"--env-from-configmap":      len(i.EnvFromConfigmap) > 0,
+		"--affinity-preset":         i.AffinityPreset != "" && i.AffinityPreset != helm.AffinityNone,
+		"--components":              len(i.Components) > 0,
+	}
+	var flags []string
+	for flag, set := range conflicts {
+		if set {
+			flags = append(flags, flag)
+		}
+	}
+	if len(flags) > 0 {
+		sort.Strings(flags)
+		return fmt.Errorf("--no-default-values cannot be combined with %s", strings.Join(flags, ", "))
+	}
+
+	return nil
+}
+
+// componentTimeouts returns the readiness timeouts of the airbyte components.
+func (i *InstallCmd) componentTimeouts() service.ComponentTimeouts {
+	return service.ComponentTimeouts{
+		Overrides: i.TimeoutPerComponent,
+		Stall:     i.StallTimeout,
+	}
+}
+
+// probeSettings returns the probe settings of the --probe-defaults preset, overridden by any of the individual
+// probe flags, or an error if they are invalid.
+func (i *InstallCmd) probeSettings() (helm.ProbeSettings, error) {
+	var probes helm.ProbeSettings
+	if i.ProbeDefaults != "" {
+		preset, err := helm.ProbePresetFor(i.ProbeDefaults)
+		if err != nil {
+			return helm.ProbeSettings{}, err
+		}
+		probes = preset
+	}
+
+	probes = probes.Override(helm.ProbeSettings{
+		InitialDelay:     i.ProbeInitialDelay,
+		Timeout:          i.ProbeTimeout,
+		FailureThreshold: i.ProbeFailureThreshold,
+	})
+	if err := probes.Validate(); err != nil {
+		return helm.ProbeSettings{}, err
+	}
+	return probes, nil
+}
+
+// checkChartFlavor returns an error if the chart flavor is unknown, or if its license requirement isn't met.
+// A license key is only accepted by a flavor which requires one.
+func checkChartFlavor(name, licenseKey string) error {
+	flavor, err := helm.ChartFlavorFor(name)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case flavor.RequiresLicense && licenseKey == "":
+		return fmt.Errorf("%w: the '%s' chart flavor requires --license-key", abctl.ErrLicenseKeyRequired, flavor.Name)
+	case !flavor.RequiresLicense && licenseKey != "":
+		return fmt.Errorf("--license-key cannot be used with the '%s' chart flavor", flavor.Name)
+	}
+
+	return nil
+}
+
+// parseDataVolumeSize parses the --data-volume-size flag. An empty size returns a zero quantity,
+// in which case the default volume size is used.
+func parseDataVolumeSize(s string) (resource.Quantity, error) {
+	if s == "" {
+		return resource.Quantity{}, nil
+	}
+
+	size, err := resource.ParseQuantity(s)
+	if err != nil {
+		return resource.Quantity{}, fmt.Errorf("invalid size '%s': %w", s, err)
+	}
+	if size.Sign() <= 0 {
+		return resource.Quantity{}, fmt.Errorf("invalid size '%s': must be greater than zero", s)
+	}
+
+	return size, nil
+}
+
 func (i *InstallCmd) setDefaultChartFlags(helmClient goHelm.Client) error {
 	resolver := helm.NewChartResolver(helmClient)
+	resolver.Offline = i.Offline
 	resolvedChart, resolvedVersion, err := resolver.ResolveChartReference(i.Chart, i.ChartVersion)
 	if err != nil {
 		return fmt.Errorf("failed to resolve chart flags: %w", err)
@@ -244,3 +1040,78 @@ func (i *InstallCmd) setDefaultChartFlags(helmClient goHelm.Client) error {
 
 	return nil
 }
+
+// validateValuesSchema validates the merged helm values against the values schema shipped with the chart.
+// Charts which do not ship a schema are not validated.
+func validateValuesSchema(helmClient goHelm.Client, opts *service.InstallOpts) error {
+	chrt, _, err := helmClient.GetChart(opts.AirbyteChartLoc, &action.ChartPathOptions{Version: opts.HelmChartVersion})
+	if err != nil {
+		return fmt.Errorf("unable to fetch airbyte chart: %w", err)
+	}
+
+	if err := helm.ValidateValuesSchema(chrt, opts.HelmValuesYaml); err != nil {
+		pterm.Error.Println("The helm chart values failed validation against the chart's values schema")
+		return fmt.Errorf("%w: %w", abctl.ErrValuesSchema, err)
+	}
+
+	return nil
+}
+
+// newLocalHelm creates the helm client used before the cluster exists, exposed for testing purposes.
+var newLocalHelm = helm.NewLocal
+
+// dumpValues writes the merged helm values to the ValuesDump path. It doesn't require the cluster, so the values are
+// written before the cluster is created or the values are validated. A resolved chart version is kept, so the
+// installation installs the version whose values were dumped.
+func (i *InstallCmd) dumpValues(ctx context.Context, user string) error {
+	helmClient, err := newLocalHelm(airbyteNamespace)
+	if err != nil {
+		return err
+	}
+
+	resolved := *i
+	if err := resolved.setDefaultChartFlags(helmClient); err != nil {
+		return fmt.Errorf("failed to set chart defaults: %w", err)
+	}
+
+	opts, err := resolved.installOpts(ctx, user)
+	if err != nil {
+		return err
+	}
+	if err := writeValuesDump(i.ValuesDump, opts.HelmValuesYaml); err != nil {
+		return err
+	}
+
+	if i.Chart == "" {
+		i.ChartVersion = resolved.ChartVersion
+	}
+	return nil
+}
+
+// writeValuesDump writes the merged helm values, with any sensitive values redacted, to the path.
+// The valuesYAML must be the same values that are provided to helm, to ensure the dump reflects
+// the actual merge order. A path of "-" writes the values to stdout.
+func writeValuesDump(path, valuesYAML string) error {
+	var values map[string]any
+	if err := yaml.Unmarshal([]byte(valuesYAML), &values); err != nil {
+		return fmt.Errorf("unable to unmarshal merged values: %w", err)
+	}
+
+	dump, err := maps.ToYAML(helm.RedactValues(values))
+	if err != nil {
+		return fmt.Errorf("unable to marshal merged values: %w", err)
+	}
+
+	if path == "-" {
+		fmt.Print(dump)
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(dump), 0o600); err != nil {
+		pterm.Error.Printfln("Unable to write merged values to '%s'", path)
+		return fmt.Errorf("unable to write merged values to '%s': %w", path, err)
+	}
+	pterm.Info.Printfln("Merged helm values written to '%s'", path)
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package local
+
+import (
+	"fmt"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+)
+
+// mergeKubeconfig merges the context of the cluster into the user's kubeconfig, making it the current context.
+// If switchBack is true, the returned func restores the context which was current before, so that the context of
+// the cluster exists in the kubeconfig without being left active. Otherwise the returned func does nothing.
+func mergeKubeconfig(provider k8s.Provider, kubeconfigs []string, switchBack bool) (func(), error) {
+	current, err := k8s.LoadMergedKubeconfig(kubeconfigs)
+	if err != nil {
+		return nil, err
+	}
+	previous := current.CurrentContext
+
+	target, err := k8s.MergeKubeconfig(provider.Kubeconfig, kubeconfigs, true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to merge the kubeconfig: %w", err)
+	}
+	pterm.Success.Printfln("Merged context '%s' into kubeconfig %s", provider.Context, target)
+
+	// without a previous context there is nothing to switch back to
+	if !switchBack || previous == "" || previous == provider.Context {
+		return func() {}, nil
+	}
+
+	return func() {
+		if _, err := k8s.UseContext(kubeconfigs, previous); err != nil {
+			pterm.Warning.Printfln("Unable to switch the current context back to '%s': %s", previous, err)
+			return
+		}
+		pterm.Info.Printfln("Switched the current context back to '%s', use 'kubectl config use-context %s' to access the cluster",
+			previous, provider.Context)
+	}, nil
+}
@@ -0,0 +1,93 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: NAME
+clusters:
+- name: NAME
+  cluster:
+    server: https://NAME.example.com
+contexts:
+- name: NAME
+  context:
+    cluster: NAME
+    user: NAME
+users:
+- name: NAME
+  user:
+    token: NAME-token
+`
+
+func writeTestKubeconfig(t *testing.T, path, name string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(testKubeconfig, "NAME", name)), 0o600); err != nil {
+		t.Fatal("unable to write kubeconfig", err)
+	}
+}
+
+func TestMergeKubeconfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		previous   string
+		switchBack bool
+		// want is the current context after the install ended
+		want string
+	}{
+		{name: "switch back", previous: "mine", switchBack: true, want: "mine"},
+		{name: "use context", previous: "mine", want: "kind-airbyte-abctl"},
+		{name: "no previous context", switchBack: true, want: "kind-airbyte-abctl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			provider := k8s.DefaultProvider
+			provider.Kubeconfig = filepath.Join(dir, "abctl.kubeconfig")
+			writeTestKubeconfig(t, provider.Kubeconfig, provider.Context)
+
+			kubeconfig := filepath.Join(dir, "config")
+			if tt.previous != "" {
+				writeTestKubeconfig(t, kubeconfig, tt.previous)
+			}
+			kubeconfigs := []string{kubeconfig}
+
+			switchBack, err := mergeKubeconfig(provider, kubeconfigs, tt.switchBack)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			// the context of the cluster is current for the duration of the install
+			cfg, err := k8s.LoadMergedKubeconfig(kubeconfigs)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(provider.Context, cfg.CurrentContext); d != "" {
+				t.Errorf("current context mismatch during install (-want +got):\n%s", d)
+			}
+
+			switchBack()
+
+			cfg, err = k8s.LoadMergedKubeconfig(kubeconfigs)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, cfg.CurrentContext); d != "" {
+				t.Errorf("current context mismatch after install (-want +got):\n%s", d)
+			}
+			// the context of the cluster is kept, whether or not it is current
+			if _, ok := cfg.Contexts[provider.Context]; !ok {
+				t.Errorf("expected context %q in the kubeconfig", provider.Context)
+			}
+		})
+	}
+}
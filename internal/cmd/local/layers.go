@@ -0,0 +1,44 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/airbytehq/abctl/internal/trace"
+)
+
+// LayersCmd contains the values layer commands.
+type LayersCmd struct {
+	List LayersListCmd `cmd:"" help:"List the available values layers."`
+}
+
+// LayersListCmd contains the arguments used when executing the layers list command.
+type LayersListCmd struct{}
+
+// Run executes the layers list command, which lists the builtin layers and those in the layers directory.
+func (l *LayersListCmd) Run(ctx context.Context) error {
+	_, span := trace.NewSpan(ctx, "local layers list")
+	defer span.End()
+
+	layers, err := helm.Layers(paths.Layers)
+	if err != nil {
+		return err
+	}
+
+	return writeLayers(os.Stdout, layers)
+}
+
+// writeLayers writes the layers to w as a table.
+func writeLayers(w io.Writer, layers []helm.Layer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSOURCE\tVERSION\tDESCRIPTION")
+	for _, layer := range layers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", layer.Name, layer.Source, layer.Version, layer.Description)
+	}
+	return tw.Flush()
+}
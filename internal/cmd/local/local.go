@@ -14,11 +14,20 @@ import (
 )
 
 type Cmd struct {
+	Check       CheckCmd       `cmd:"" help:"Diagnose the dependencies of abctl, such as Docker."`
 	Credentials CredentialsCmd `cmd:"" help:"Get local Airbyte user credentials."`
+	Images      ImagesCmd      `cmd:"" help:"Manage the images of local Airbyte."`
 	Install     InstallCmd     `cmd:"" help:"Install local Airbyte."`
+	Layers      LayersCmd      `cmd:"" help:"Manage the helm chart values layers."`
+	Logs        LogsCmd        `cmd:"" help:"View local Airbyte logs."`
 	Deployments DeploymentsCmd `cmd:"" help:"View local Airbyte deployments."`
+	Doctor      DoctorCmd      `cmd:"" help:"Diagnose common problems of local Airbyte, and remediate them with --fix."`
+	Prune       PruneCmd       `cmd:"" help:"Report the Docker disk usage and remove the unused Docker images pulled by abctl."`
+	Reinstall   ReinstallCmd   `cmd:"" help:"Reinstall local Airbyte, keeping its data."`
 	Status      StatusCmd      `cmd:"" help:"Get local Airbyte status."`
 	Uninstall   UninstallCmd   `cmd:"" help:"Uninstall local Airbyte."`
+	Upgrade     UpgradeCmd     `cmd:"" help:"Upgrade local Airbyte."`
+	Validate    ValidateCmd    `cmd:"" help:"Validate an Airbyte helm chart values file without installing."`
 }
 
 func (c *Cmd) BeforeApply() error {
@@ -7,15 +7,19 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker"
 	"github.com/airbytehq/abctl/internal/k8s"
 	"github.com/airbytehq/abctl/internal/paths"
 	"github.com/airbytehq/abctl/internal/service"
+	"github.com/airbytehq/abctl/internal/service/servicetest"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/alecthomas/kong"
 	"github.com/google/go-cmp/cmp"
 	goHelm "github.com/mittwald/go-helm-client"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func TestCheckAirbyteDir(t *testing.T) {
@@ -178,3 +182,268 @@ func TestInstallOpts(t *testing.T) {
 		t.Errorf("unexpected error diff (-want +got):\n%s", d)
 	}
 }
+
+func TestValuesDump(t *testing.T) {
+	cmd := InstallCmd{
+		// Don't let the code dynamically resolve the latest chart version.
+		Chart:  "/test/path/to/chart",
+		Port:   8000,
+		Values: "./testdata/dump.values.yaml",
+	}
+	opts, err := cmd.installOpts(context.Background(), "test-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dumpPath := filepath.Join(t.TempDir(), "dump.values.yaml")
+	if err := writeValuesDump(dumpPath, opts.HelmValuesYaml); err != nil {
+		t.Fatal(err)
+	}
+
+	expect, err := os.ReadFile("./testdata/expected-dump.values.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(string(expect), string(got)); d != "" {
+		t.Errorf("values dump mismatch (-want +got):\n%s", d)
+	}
+
+	// the values provided to helm must not be redacted
+	if !strings.Contains(opts.HelmValuesYaml, "hunter2") {
+		t.Error("helm values should not be redacted")
+	}
+}
+
+func TestInstallCmd_DumpValues(t *testing.T) {
+	orig := newLocalHelm
+	newLocalHelm = func(string) (goHelm.Client, error) {
+		return servicetest.NewFakeHelm(), nil
+	}
+	t.Cleanup(func() { newLocalHelm = orig })
+
+	dumpPath := filepath.Join(t.TempDir(), "dump.values.yaml")
+	cmd := InstallCmd{
+		ChartVersion: "2.0.6",
+		Port:         8000,
+		Values:       "./testdata/dump.values.yaml",
+		ValuesDump:   dumpPath,
+	}
+	if err := cmd.dumpValues(context.Background(), "test-user"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatal("expected the values to be dumped", err)
+	}
+	if strings.Contains(string(got), "hunter2") {
+		t.Error("dumped values should be redacted")
+	}
+	if d := cmp.Diff("", cmd.Chart); d != "" {
+		t.Errorf("chart mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff("2.0.6", cmd.ChartVersion); d != "" {
+		t.Errorf("chart version mismatch (-want +got):\n%s", d)
+	}
+
+	// nothing is dumped if the chart can't be resolved
+	offline := InstallCmd{Offline: true, Port: 8000, ValuesDump: filepath.Join(t.TempDir(), "offline.values.yaml")}
+	if err := offline.dumpValues(context.Background(), "test-user"); !errors.Is(err, abctl.ErrOfflineChart) {
+		t.Errorf("expected error %v, got %v", abctl.ErrOfflineChart, err)
+	}
+	if _, err := os.Stat(offline.ValuesDump); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no values dump, got %v", err)
+	}
+}
+
+func TestParseDataVolumeSize(t *testing.T) {
+	tests := []struct {
+		size    string
+		want    resource.Quantity
+		wantErr bool
+	}{
+		{size: "", want: resource.Quantity{}},
+		{size: "10Gi", want: resource.MustParse("10Gi")},
+		{size: "1500M", want: resource.MustParse("1500M")},
+		{size: "0", wantErr: true},
+		{size: "-1Gi", wantErr: true},
+		{size: "10GB", wantErr: true},
+		{size: "ten", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.size, func(t *testing.T) {
+			got, err := parseDataVolumeSize(tt.size)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("size mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestInstallOpts_DataVolumeSize(t *testing.T) {
+	cmd := InstallCmd{
+		Chart:          "/test/path/to/chart",
+		Port:           8000,
+		DataVolumeSize: "20Gi",
+		Force:          true,
+	}
+	opts, err := cmd.installOpts(context.Background(), "test-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(resource.MustParse("20Gi"), opts.DataVolumeSize); d != "" {
+		t.Errorf("size mismatch (-want +got):\n%s", d)
+	}
+	if !opts.AllowVolumeShrink {
+		t.Error("expected volume shrink to be allowed")
+	}
+}
+
+func TestInstallOpts_NoDefaultValues(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("global:\n  edition: community\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := InstallCmd{
+		Chart:           "/test/path/to/chart",
+		Port:            8000,
+		NoDefaultValues: true,
+		Values:          valuesFile,
+	}
+	opts, err := cmd.installOpts(context.Background(), "test-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff("global:\n    edition: community\n", opts.HelmValuesYaml); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestCheckNoDefaultValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     InstallCmd
+		wantErr string
+	}{
+		{
+			name: "flag not set",
+			cmd:  InstallCmd{DisableAuth: true, LowResourceMode: true},
+		},
+		{
+			name: "no conflicts",
+			cmd:  InstallCmd{NoDefaultValues: true, Values: "values.yaml"},
+		},
+		{
+			name:    "conflicting flags",
+			cmd:     InstallCmd{NoDefaultValues: true, LowResourceMode: true, DisableAuth: true},
+			wantErr: "--no-default-values cannot be combined with --disable-auth, --low-resource-mode",
+		},
+		{
+			name:    "resources preset",
+			cmd:     InstallCmd{NoDefaultValues: true, ResourcesPreset: "small"},
+			wantErr: "--no-default-values cannot be combined with --resources-preset",
+		},
+		{
+			name:    "labels and annotations",
+			cmd:     InstallCmd{NoDefaultValues: true, Label: []string{"team=data"}, Annotation: []string{"owner=data"}},
+			wantErr: "--no-default-values cannot be combined with --annotation, --label",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if err := tt.cmd.checkNoDefaultValues(); err != nil {
+				got = err.Error()
+			}
+			if d := cmp.Diff(tt.wantErr, got); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestCheckChartFlavor(t *testing.T) {
+	tests := []struct {
+		name       string
+		flavor     string
+		licenseKey string
+		wantErr    error
+		wantAnyErr bool
+	}{
+		{name: "community", flavor: "community"},
+		{name: "enterprise with license", flavor: "enterprise", licenseKey: "key"},
+		{name: "enterprise without license", flavor: "enterprise", wantErr: abctl.ErrLicenseKeyRequired},
+		{name: "community with license", flavor: "community", licenseKey: "key", wantAnyErr: true},
+		{name: "unknown flavor", flavor: "premium", wantAnyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkChartFlavor(tt.flavor, tt.licenseKey)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v but got %v", tt.wantErr, err)
+				}
+			case tt.wantAnyErr:
+				if err == nil {
+					t.Error("expected an error")
+				}
+			case err != nil:
+				t.Error("unexpected error:", err)
+			}
+		})
+	}
+}
+
+func TestInstallOpts_StallTimeout(t *testing.T) {
+	cmd := InstallCmd{
+		Chart:               "/test/path/to/chart",
+		Port:                8000,
+		StallTimeout:        5 * time.Minute,
+		TimeoutPerComponent: map[string]time.Duration{"db": 30 * time.Minute},
+	}
+	opts, err := cmd.installOpts(context.Background(), "test-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := service.ComponentTimeouts{
+		Overrides: map[string]time.Duration{"db": 30 * time.Minute},
+		Stall:     5 * time.Minute,
+	}
+	if d := cmp.Diff(want, opts.ComponentTimeouts); d != "" {
+		t.Errorf("timeouts mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestImagePrefixMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefixes")
+	if err := os.WriteFile(path, []byte("# forks\ntemporalio=myorg/temporalio\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := imagePrefixMap([]string{"airbyte/=myorg/airbyte-mirror/"}, path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := docker.ImagePrefixMap{
+		{Old: "airbyte/", New: "myorg/airbyte-mirror/"},
+		{Old: "temporalio", New: "myorg/temporalio"},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("prefix map mismatch (-want +got):\n%s", d)
+	}
+
+	if _, err := imagePrefixMap([]string{"airbyte/"}, ""); err == nil {
+		t.Error("expected an error for an invalid prefix spec")
+	}
+}
@@ -0,0 +1,182 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/airbyte"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/pterm/pterm"
+	"go.opencensus.io/trace"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// logMergeWindow is how long lines are buffered, allowing near-simultaneous lines of other components to be ordered before them.
+var logMergeWindow = 500 * time.Millisecond
+
+const logTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+type LogsCmd struct {
+	Component string `arg:"" optional:"" help:"Component (e.g. server, worker) or pod to show the logs of."`
+	All       bool   `help:"Show the logs of every Airbyte component, merged in timestamp order."`
+	Follow    bool   `short:"f" help:"Continue streaming the logs as they are written."`
+}
+
+func (l *LogsCmd) Run(ctx context.Context, telClient telemetry.Client, provider k8s.Provider) error {
+	ctx, span := trace.StartSpan(ctx, "local logs")
+	defer span.End()
+
+	if l.All == (l.Component != "") {
+		return errors.New("either a component or --all must be specified")
+	}
+
+	k8sClient, err := service.DefaultK8s(provider.Kubeconfig, provider.Context)
+	if err != nil {
+		return err
+	}
+
+	spinner := &pterm.DefaultSpinner
+	if err := checkDocker(ctx, telClient, spinner); err != nil {
+		return err
+	}
+	_ = spinner.Stop()
+
+	return telClient.Wrap(ctx, telemetry.Logs, func() error {
+		return l.logs(ctx, k8sClient, os.Stdout)
+	})
+}
+
+// logPod is a pod whose logs are being shown, tagged with its component.
+type logPod struct {
+	component string
+	name      string
+}
+
+// pods returns the pods whose logs should be shown.
+// Pods not belonging to a component (e.g. the bootloader) are tagged with their pod name.
+func (l *LogsCmd) pods(ctx context.Context, k8sClient k8s.Client) ([]logPod, error) {
+	pods, err := k8sClient.PodList(ctx, airbyteNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	var out []logPod
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodPending {
+			continue
+		}
+		component := service.PodComponent(pod)
+		if component == "" {
+			component = pod.Name
+		}
+		if l.All || l.Component == component || l.Component == pod.Name {
+			out = append(out, logPod{component: component, name: pod.Name})
+		}
+	}
+
+	if len(out) == 0 {
+		if l.All {
+			return nil, fmt.Errorf("no pods found in namespace '%s'", airbyteNamespace)
+		}
+		return nil, fmt.Errorf("no pods found for component '%s'", l.Component)
+	}
+
+	return out, nil
+}
+
+func (l *LogsCmd) logs(ctx context.Context, k8sClient k8s.Client, w io.Writer) error {
+	pods, err := l.pods(ctx, k8sClient)
+	if err != nil {
+		return err
+	}
+
+	merger := airbyte.NewLogMerger(logMergeWindow)
+
+	if !l.Follow {
+		for _, pod := range pods {
+			logs, err := k8sClient.LogsGet(ctx, airbyteNamespace, pod.name)
+			if err != nil {
+				return fmt.Errorf("unable to get logs of pod '%s': %w", pod.name, err)
+			}
+			if err := scanLogs(ctx, merger, pod.component, strings.NewReader(logs)); err != nil {
+				return fmt.Errorf("unable to read logs of pod '%s': %w", pod.name, err)
+			}
+		}
+		return writeLogLines(w, merger.FlushAll())
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(pods))
+	for i, pod := range pods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := k8sClient.StreamPodLogs(ctx, airbyteNamespace, pod.name, time.Time{})
+			if err != nil {
+				errs[i] = fmt.Errorf("unable to stream logs of pod '%s': %w", pod.name, err)
+				return
+			}
+			defer r.Close()
+			if err := scanLogs(ctx, merger, pod.component, r); err != nil && ctx.Err() == nil {
+				errs[i] = fmt.Errorf("unable to read logs of pod '%s': %w", pod.name, err)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(logMergeWindow / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			if err := writeLogLines(w, merger.FlushAll()); err != nil {
+				return err
+			}
+			return errors.Join(errs...)
+		case now := <-ticker.C:
+			if err := writeLogLines(w, merger.Flush(now)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// scanLogs adds every line read from r to the merger, tagged with the component, until the ctx is done.
+func scanLogs(ctx context.Context, merger *airbyte.LogMerger, component string, r io.Reader) error {
+	s := airbyte.NewLogScanner(r)
+	for s.ScanContext(ctx) {
+		merger.Add(component, s.Line, time.Now())
+	}
+	return s.Err()
+}
+
+// writeLogLines writes the lines prefixed with their component.
+// Lines without a level, such as stack traces, are written as is.
+func writeLogLines(w io.Writer, lines []airbyte.TaggedLine) error {
+	for _, l := range lines {
+		var err error
+		if l.Line.Level == "" {
+			_, err = fmt.Fprintf(w, "[%s] %s\n", l.Component, l.Line.Message)
+		} else {
+			_, err = fmt.Fprintf(w, "[%s] %s %s %s\n", l.Component, l.Line.Time.UTC().Format(logTimeFormat), l.Line.Level, l.Line.Message)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to write logs: %w", err)
+		}
+	}
+	return nil
+}
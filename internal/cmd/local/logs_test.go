@@ -0,0 +1,110 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testPodLogs = map[string]string{
+	"airbyte-abctl-server-123-abc": strings.TrimSpace(`
+{"timestamp":1734723317000,"message":"server starting","level":"INFO"}
+{"timestamp":1734723317020,"message":"server failed","level":"ERROR"}
+	at io.airbyte.server.Application.main
+`),
+	"airbyte-abctl-worker-456-def": strings.TrimSpace(`
+{"timestamp":1734723317010,"message":"worker starting","level":"INFO"}
+{"timestamp":1734723317030,"message":"worker ready","level":"INFO"}
+`),
+}
+
+func testLogsClient(t *testing.T) *k8stest.MockClient {
+	pod := func(name, owner string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: owner}},
+		}}
+	}
+
+	return &k8stest.MockClient{
+		FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+			if d := cmp.Diff(airbyteNamespace, namespace); d != "" {
+				t.Errorf("unexpected namespace:\n%s", d)
+			}
+			return &corev1.PodList{Items: []corev1.Pod{
+				pod("airbyte-abctl-server-123-abc", "airbyte-abctl-server-123"),
+				pod("airbyte-abctl-worker-456-def", "airbyte-abctl-worker-456"),
+			}}, nil
+		},
+		FnLogsGet: func(ctx context.Context, namespace string, name string) (string, error) {
+			return testPodLogs[name], nil
+		},
+		FnStreamPodLogs: func(ctx context.Context, namespace, podName string, since time.Time) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(testPodLogs[podName])), nil
+		},
+	}
+}
+
+func TestLogsCmd(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  LogsCmd
+		want string
+	}{
+		{
+			name: "all",
+			cmd:  LogsCmd{All: true},
+			want: `[server] 2024-12-20T19:35:17.000Z INFO server starting
+[worker] 2024-12-20T19:35:17.010Z INFO worker starting
+[server] 2024-12-20T19:35:17.020Z ERROR server failed
+[server] 	at io.airbyte.server.Application.main
+[worker] 2024-12-20T19:35:17.030Z INFO worker ready
+`,
+		},
+		{
+			name: "all follow",
+			cmd:  LogsCmd{All: true, Follow: true},
+			want: `[server] 2024-12-20T19:35:17.000Z INFO server starting
+[worker] 2024-12-20T19:35:17.010Z INFO worker starting
+[server] 2024-12-20T19:35:17.020Z ERROR server failed
+[server] 	at io.airbyte.server.Application.main
+[worker] 2024-12-20T19:35:17.030Z INFO worker ready
+`,
+		},
+		{
+			name: "component",
+			cmd:  LogsCmd{Component: "worker"},
+			want: `[worker] 2024-12-20T19:35:17.010Z INFO worker starting
+[worker] 2024-12-20T19:35:17.030Z INFO worker ready
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tt.cmd.logs(context.Background(), testLogsClient(t), &b); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, b.String()); d != "" {
+				t.Errorf("logs mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestLogsCmd_NoPods(t *testing.T) {
+	cmd := LogsCmd{Component: "missing"}
+	err := cmd.logs(context.Background(), testLogsClient(t), io.Discard)
+	if d := cmp.Diff("no pods found for component 'missing'", err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+}
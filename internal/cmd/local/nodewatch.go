@@ -0,0 +1,45 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+)
+
+// watchNode returns a ctx which is canceled, with the failure as its cause, if the docker engine stops the node
+// container of the cluster, e.g. by OOM-killing it. Without it, such a failure would only surface as a timeout.
+// The returned function unsubscribes from the engine events, and must be called before the node is expected to stop.
+func watchNode(ctx context.Context, provider k8s.Provider) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if dockerClient == nil || !provider.RequiresDocker() {
+		return ctx, func() { cancel(nil) }
+	}
+
+	stop := dockerClient.WatchContainer(ctx, fmt.Sprintf("%s-control-plane", provider.ClusterName), func(err error) {
+		cancel(err)
+	})
+	return ctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+// nodeFailure returns the failure of the node container which canceled the ctx returned by watchNode instead of err,
+// which is then only a consequence of it, or err if the node container did not fail.
+func nodeFailure(ctx context.Context, err error) error {
+	cause := context.Cause(ctx)
+	if err == nil || cause == nil || errors.Is(cause, context.Canceled) {
+		return err
+	}
+	if !errors.Is(cause, abctl.ErrNodeOOMKilled) && !errors.Is(cause, abctl.ErrDocker) {
+		return err
+	}
+
+	pterm.Error.Println("The cluster node stopped during the installation")
+	pterm.Debug.Printfln("Installation failed after the cluster node stopped: %s", err)
+	return cause
+}
@@ -0,0 +1,77 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+)
+
+func TestWatchNode_OOMKilled(t *testing.T) {
+	t.Cleanup(func() {
+		dockerClient = nil
+	})
+
+	dockerClient = &docker.Docker{Client: dockertest.MockClient{
+		FnEvents: func(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+			if !options.Filters.ExactMatch("container", k8s.TestProvider.ClusterName+"-control-plane") {
+				t.Errorf("events should be filtered by the node container: %v", options.Filters)
+			}
+			msgs := make(chan events.Message, 2)
+			msgs <- events.Message{Action: events.ActionOOM}
+			msgs <- events.Message{Action: events.ActionDie, Actor: events.Actor{Attributes: map[string]string{"exitCode": "137"}}}
+			return msgs, make(chan error)
+		},
+		FnContainerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{
+				State: &types.ContainerState{OOMKilled: true, ExitCode: 137},
+			}}, nil
+		},
+	}}
+
+	ctx, stop := watchNode(context.Background(), k8s.TestProvider)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the node failure to cancel the context")
+	}
+
+	// the install only fails on the canceled context, the node failure is reported instead
+	err := nodeFailure(ctx, errors.New("unable to install airbyte chart: context canceled"))
+	if !errors.Is(err, abctl.ErrNodeOOMKilled) {
+		t.Errorf("expected ErrNodeOOMKilled but got %v", err)
+	}
+}
+
+func TestNodeFailure_NoFailure(t *testing.T) {
+	t.Cleanup(func() {
+		dockerClient = nil
+	})
+
+	dockerClient = &docker.Docker{Client: dockertest.MockClient{
+		FnEvents: func(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+			return make(chan events.Message), make(chan error)
+		},
+	}}
+
+	ctx, stop := watchNode(context.Background(), k8s.TestProvider)
+	testErr := errors.New("test error")
+	if err := nodeFailure(ctx, testErr); !errors.Is(err, testErr) {
+		t.Errorf("expected the install error but got %v", err)
+	}
+
+	// unsubscribing doesn't turn the install error into a node failure
+	stop()
+	if err := nodeFailure(ctx, testErr); !errors.Is(err, testErr) {
+		t.Errorf("expected the install error but got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+)
+
+// autoPort is the portFlag value requesting that an available port be selected automatically.
+const autoPort portFlag = -1
+
+// autoPortMin and autoPortMax are the range of ports which are scanned when the port is "auto".
+var (
+	autoPortMin = 8000
+	autoPortMax = 8100
+)
+
+// portFlag is a port number, or "auto" to select an available port.
+type portFlag int
+
+// UnmarshalText implements encoding.TextUnmarshaler, allowing kong to decode the flag.
+func (p *portFlag) UnmarshalText(text []byte) error {
+	if string(text) == "auto" {
+		*p = autoPort
+		return nil
+	}
+
+	port, err := strconv.Atoi(string(text))
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port '%s': must be a port number or auto", text)
+	}
+	*p = portFlag(port)
+	return nil
+}
+
+// listenPort binds the port on every interface, as kind publishes the port of the cluster on every interface,
+// so a port only bound on another interface is never selected. It is exposed here primarily for testing purposes.
+var listenPort = func(ctx context.Context, port int) (net.Listener, error) {
+	lc := &net.ListenConfig{}
+	return lc.Listen(ctx, "tcp", fmt.Sprintf(":%d", port))
+}
+
+// portReservation holds the port bound, preventing other processes from binding it until it is released.
+type portReservation struct {
+	Port     int
+	listener net.Listener
+}
+
+// Release unbinds the port, allowing it to be bound by the cluster.
+// Release should be called as late as possible, immediately before the port is needed.
+func (r *portReservation) Release() error {
+	return r.listener.Close()
+}
+
+// reservePort returns a reservation of the first port within [min, max] which could be bound.
+// Holding the port bound, rather than only checking if it is available, ensures no other process
+// can claim the port between it being selected and the cluster being created.
+func reservePort(ctx context.Context, min, max int) (*portReservation, error) {
+	for port := min; port <= max; port++ {
+		listener, err := listenPort(ctx, port)
+		if err != nil {
+			continue
+		}
+		return &portReservation{Port: port, listener: listener}, nil
+	}
+
+	return nil, fmt.Errorf("%w: no available port found between %d and %d", abctl.ErrPort, min, max)
+}
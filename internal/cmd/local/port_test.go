@@ -0,0 +1,143 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeListener is a net.Listener which only tracks if it was closed.
+type fakeListener struct {
+	net.Listener
+	closed bool
+}
+
+func (f *fakeListener) Close() error {
+	f.closed = true
+	return nil
+}
+
+// setOccupiedPorts replaces listenPort, failing to bind any of the occupied ports.
+func setOccupiedPorts(t *testing.T, occupied ...int) {
+	orig := listenPort
+	listenPort = func(ctx context.Context, port int) (net.Listener, error) {
+		for _, o := range occupied {
+			if port == o {
+				return nil, &net.OpError{Op: "listen", Err: syscall.EADDRINUSE}
+			}
+		}
+		return &fakeListener{}, nil
+	}
+	t.Cleanup(func() { listenPort = orig })
+}
+
+func TestReservePort(t *testing.T) {
+	tests := []struct {
+		name     string
+		occupied []int
+		want     int
+	}{
+		{
+			name: "none occupied",
+			want: 8000,
+		},
+		{
+			name:     "first occupied",
+			occupied: []int{8000},
+			want:     8001,
+		},
+		{
+			name:     "several occupied",
+			occupied: []int{8000, 8001, 8003},
+			want:     8002,
+		},
+		{
+			name:     "last available",
+			occupied: []int{8000, 8001, 8002, 8003},
+			want:     8004,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOccupiedPorts(t, tt.occupied...)
+
+			reservation, err := reservePort(context.Background(), 8000, 8004)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, reservation.Port); d != "" {
+				t.Errorf("port mismatch (-want +got):\n%s", d)
+			}
+
+			if err := reservation.Release(); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if !reservation.listener.(*fakeListener).closed {
+				t.Error("expected the port to be released")
+			}
+		})
+	}
+}
+
+func TestReservePort_AllOccupied(t *testing.T) {
+	setOccupiedPorts(t, 8000, 8001, 8002)
+
+	_, err := reservePort(context.Background(), 8000, 8002)
+	if !errors.Is(err, abctl.ErrPort) {
+		t.Errorf("expected ErrPort but got %v", err)
+	}
+}
+
+func TestReservePort_Held(t *testing.T) {
+	// bind a real port, a second reservation must not be able to claim it while it is held
+	reservation, err := reservePort(context.Background(), autoPortMin, autoPortMax)
+	if err != nil {
+		t.Skip("no port available for testing", err)
+	}
+	t.Cleanup(func() { _ = reservation.Release() })
+
+	second, err := reservePort(context.Background(), reservation.Port, reservation.Port)
+	if err == nil {
+		_ = second.Release()
+		t.Fatalf("expected port %d to be held", reservation.Port)
+	}
+}
+
+func TestPortFlag_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    portFlag
+		wantErr bool
+	}{
+		{input: "8000", want: 8000},
+		{input: "auto", want: autoPort},
+		{input: "0", wantErr: true},
+		{input: "65536", wantErr: true},
+		{input: "eight", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var p portFlag
+			err := p.UnmarshalText([]byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, p); d != "" {
+				t.Errorf("port mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
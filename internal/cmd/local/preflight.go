@@ -0,0 +1,415 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/helm"
+	abctlhttp "github.com/airbytehq/abctl/internal/http"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+)
+
+// The exit codes of install --preflight-only, by the category of the first failing check.
+const (
+	exitPreflightDocker        = 10
+	exitPreflightDockerVersion = 11
+	exitPreflightResources     = 12
+	exitPreflightDisk          = 13
+	exitPreflightConnectivity  = 14
+	exitPreflightPort          = 15
+)
+
+// The names of the pre-flight checks, which --skip-preflight skips.
+const (
+	preflightDocker        = "docker"
+	preflightDockerVersion = "docker-version"
+	preflightResources     = "resources"
+	preflightDisk          = "disk"
+	preflightConnectivity  = "connectivity"
+	preflightPort          = "port"
+)
+
+// preflightChecks describes every pre-flight check, by name, in the order they run.
+var preflightChecks = []struct{ name, description string }{
+	{preflightDocker, "Docker is reachable, skipping it also skips the checks which depend on Docker"},
+	{preflightDockerVersion, "the Docker version is supported"},
+	{preflightResources, "Docker has the CPUs and memory of the resources preset"},
+	{preflightDisk, "the abctl data directory has enough free disk space"},
+	{preflightConnectivity, "the chart repositories and image registry are reachable"},
+	{preflightPort, "the ingress port is available"},
+}
+
+// parsePreflightSkips returns the set of the named pre-flight checks, or an error if any of them doesn't exist.
+func parsePreflightSkips(names []string) (map[string]bool, error) {
+	known := preflightNames()
+	skips := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(known, name) {
+			return nil, fmt.Errorf("unknown pre-flight check '%s' in --skip-preflight, must be one of %s (see --list-preflights)", name, strings.Join(known, ", "))
+		}
+		skips[name] = true
+	}
+	return skips, nil
+}
+
+func preflightNames() []string {
+	names := make([]string, len(preflightChecks))
+	for idx, c := range preflightChecks {
+		names[idx] = c.name
+	}
+	return names
+}
+
+// printPreflightChecks writes the name and description of every pre-flight check to w.
+func printPreflightChecks(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tCHECKS")
+	for _, c := range preflightChecks {
+		fmt.Fprintf(tw, "%s\t%s\n", c.name, c.description)
+	}
+	_ = tw.Flush()
+}
+
+// minFreeDisk is the free disk space, in bytes, of the abctl data directory which doesn't trigger a warning.
+const minFreeDisk = 10 * 1024 * 1024 * 1024
+
+// defaultRegistryURL is the registry the images are pulled from, without a registry mirror.
+const defaultRegistryURL = "https://registry-1.docker.io/v2/"
+
+type preflightStatus string
+
+const (
+	preflightPass preflightStatus = "pass"
+	preflightWarn preflightStatus = "warn"
+	preflightFail preflightStatus = "fail"
+	preflightSkip preflightStatus = "skip"
+)
+
+// preflightCheck is the result of a single check of install --preflight-only.
+type preflightCheck struct {
+	// id is the name of the check in --skip-preflight.
+	id          string
+	name        string
+	status      preflightStatus
+	measured    string
+	requirement string
+	// code is the exit code if the check fails.
+	code int
+}
+
+// preflightConfig is what the checks of install --preflight-only are run against.
+type preflightConfig struct {
+	port   int
+	preset helm.ResourcesPreset
+	// strict fails the checks which would otherwise only warn.
+	strict bool
+	// requiresDocker is false if the provider doesn't run its cluster within docker, which skips the docker checks.
+	requiresDocker bool
+	// dataDir is the directory whose free disk space is checked, it may not exist yet.
+	dataDir string
+	// urls must be reachable for the installation to succeed, e.g. the chart repositories.
+	urls []string
+	// skip are the names of the checks which are skipped rather than run.
+	skip map[string]bool
+}
+
+// preflightEnv is how the checks of install --preflight-only measure the host.
+type preflightEnv struct {
+	http *http.Client
+	// freeDisk returns the free disk space, in bytes, of the filesystem of the path.
+	freeDisk func(path string) (uint64, error)
+}
+
+// preflightURLs returns the urls an installation with the flags needs to reach.
+func (i *InstallCmd) preflightURLs() []string {
+	var urls []string
+	if i.Chart == "" {
+		urls = append(urls, common.AirbyteRepoURLv2+"/index.yaml")
+	}
+	if i.IngressClass == "" {
+		urls = append(urls, common.NginxRepoURL+"/index.yaml")
+	}
+	if i.RegistryMirror != "" {
+		urls = append(urls, fmt.Sprintf("https://%s/v2/", i.RegistryMirror))
+	} else {
+		urls = append(urls, defaultRegistryURL)
+	}
+	return urls
+}
+
+// preflightOnly runs every pre-flight check of the installation, prints their results and returns an
+// abctl.ExitError if any failed. Nothing is created, pulled or modified.
+func (i *InstallCmd) preflightOnly(ctx context.Context, requiresDocker bool, preset helm.ResourcesPreset, skip map[string]bool) error {
+	ctx, span := trace.NewSpan(ctx, "local install preflight")
+	defer span.End()
+
+	if preset.Name == "" {
+		// without a preset, the resources are checked against those of the smallest one
+		preset, _ = helm.ResourcesPresetFor("small")
+	}
+
+	port := int(i.Port)
+	if i.Port == autoPort {
+		port = autoPortMin
+	}
+
+	checks := runPreflight(ctx, preflightEnv{http: &http.Client{Timeout: 10 * time.Second, Transport: &abctlhttp.UserAgentTransport{}}, freeDisk: freeDiskSpace}, preflightConfig{
+		port:           port,
+		preset:         preset,
+		strict:         i.Strict,
+		requiresDocker: requiresDocker && !i.SkipDockerCheck,
+		dataDir:        paths.Data,
+		urls:           i.preflightURLs(),
+		skip:           skip,
+	})
+
+	printPreflight(os.Stdout, checks)
+	if err := preflightErr(checks); err != nil {
+		return err
+	}
+	pterm.Success.Println("All pre-flight checks passed")
+	return nil
+}
+
+// runPreflight runs every pre-flight check, in order, returning their results. The checks which only warn
+// during an installation, such as the resources, fail in strict mode. If docker is unreachable, the checks
+// which depend on it are skipped, as are the checks named by cfg.skip.
+func runPreflight(ctx context.Context, env preflightEnv, cfg preflightConfig) []preflightCheck {
+	warnings := &preflightWarnings{strict: cfg.strict}
+	var checks []preflightCheck
+
+	// skipped records the check as skipped if it's named by cfg.skip, returning true if it is
+	skipped := func(c preflightCheck) bool {
+		if !cfg.skip[c.id] {
+			return false
+		}
+		c.status, c.measured = preflightSkip, "skipped by --skip-preflight"
+		checks = append(checks, c)
+		return true
+	}
+
+	// soft records the result of a check which only warns outside of strict mode
+	soft := func(c preflightCheck, warned bool) {
+		switch {
+		case !warned:
+			c.status = preflightPass
+		case cfg.strict:
+			c.status = preflightFail
+		default:
+			c.status = preflightWarn
+		}
+		checks = append(checks, c)
+	}
+
+	dockerCheck := preflightCheck{id: preflightDocker, name: "Docker", requirement: "reachable", code: exitPreflightDocker}
+	versionCheck := preflightCheck{
+		id:          preflightDockerVersion,
+		name:        "Docker version",
+		requirement: fmt.Sprintf(">= %d.%d", minDockerVersion[0], minDockerVersion[1]),
+		code:        exitPreflightDockerVersion,
+	}
+	resourcesCheck := preflightCheck{
+		id:          preflightResources,
+		name:        "Resources",
+		requirement: fmt.Sprintf(">= %d CPUs, %s memory (%s preset)", cfg.preset.MinCPUs, formatGiB(cfg.preset.MinMemory), cfg.preset.Name),
+		code:        exitPreflightResources,
+	}
+
+	requiresDocker := cfg.requiresDocker && !cfg.skip[preflightDocker]
+	version, err := preflightDockerVersion(ctx, requiresDocker)
+	switch {
+	case cfg.requiresDocker && !requiresDocker:
+		for _, c := range []preflightCheck{dockerCheck, versionCheck, resourcesCheck} {
+			c.status, c.measured = preflightSkip, "skipped by --skip-preflight"
+			checks = append(checks, c)
+		}
+	case !requiresDocker:
+		for _, c := range []preflightCheck{dockerCheck, versionCheck, resourcesCheck} {
+			c.status, c.measured = preflightSkip, "docker not required"
+			checks = append(checks, c)
+		}
+	case err != nil:
+		dockerCheck.status, dockerCheck.measured = preflightFail, err.Error()
+		checks = append(checks, dockerCheck)
+		for _, c := range []preflightCheck{versionCheck, resourcesCheck} {
+			c.status, c.measured = preflightSkip, "docker unreachable"
+			checks = append(checks, c)
+		}
+	default:
+		dockerCheck.status, dockerCheck.measured = preflightPass, "reachable"
+		checks = append(checks, dockerCheck)
+
+		if !skipped(versionCheck) {
+			versionCheck.measured = version.Version
+			soft(versionCheck, checkDockerVersion(warnings, version.Version))
+		}
+
+		if skipped(resourcesCheck) {
+			break
+		}
+		if info, err := dockerClient.Client.Info(ctx); err != nil {
+			resourcesCheck.status, resourcesCheck.measured = preflightSkip, fmt.Sprintf("unknown: %s", err)
+			checks = append(checks, resourcesCheck)
+		} else {
+			resourcesCheck.measured = fmt.Sprintf("%d CPUs, %s memory", info.NCPU, formatGiB(info.MemTotal))
+			soft(resourcesCheck, checkResourcesPreset(warnings, cfg.preset, info.NCPU, info.MemTotal))
+		}
+	}
+
+	diskCheck := preflightCheck{id: preflightDisk, name: "Disk", requirement: fmt.Sprintf(">= %s free", formatGiB(minFreeDisk)), code: exitPreflightDisk}
+	dir := existingDir(cfg.dataDir)
+	if !skipped(diskCheck) {
+		if free, err := env.freeDisk(dir); err != nil {
+			diskCheck.status, diskCheck.measured = preflightSkip, fmt.Sprintf("unknown: %s", err)
+			checks = append(checks, diskCheck)
+		} else {
+			diskCheck.measured = fmt.Sprintf("%s free at %s", formatGiB(int64(free)), dir)
+			soft(diskCheck, checkFreeDisk(warnings, dir, free))
+		}
+	}
+
+	for _, url := range cfg.urls {
+		if !skipped(newPreflightURLCheck(url)) {
+			checks = append(checks, preflightURL(ctx, env.http, url))
+		}
+	}
+
+	portCheck := preflightCheck{id: preflightPort, name: "Port", requirement: fmt.Sprintf("%d available", cfg.port), code: exitPreflightPort}
+	if skipped(portCheck) {
+		return checks
+	}
+	// the port has its own warnings, as it's the only check which reports a warning through its error
+	portWarnings := &preflightWarnings{strict: cfg.strict}
+	err = portAvailable(ctx, cfg.port, portWarnings)
+	switch {
+	case errors.Is(err, abctl.ErrPortAbctlCluster):
+		// the existing cluster keeps its port when reinstalled
+		portCheck.status, portCheck.measured = preflightPass, "in use by the existing abctl cluster"
+		checks = append(checks, portCheck)
+	case len(portWarnings.warnings) > 0:
+		portCheck.measured = "privileged, availability unknown"
+		soft(portCheck, true)
+	case err != nil:
+		portCheck.status, portCheck.measured = preflightFail, err.Error()
+		checks = append(checks, portCheck)
+	default:
+		portCheck.status, portCheck.measured = preflightPass, "available"
+		checks = append(checks, portCheck)
+	}
+
+	return checks
+}
+
+// preflightDockerVersion returns the version of the docker engine, creating the client if necessary.
+// Returns an error if docker is unreachable.
+func preflightDockerVersion(ctx context.Context, requiresDocker bool) (docker.Version, error) {
+	if !requiresDocker {
+		return docker.Version{}, nil
+	}
+
+	var err error
+	if dockerClient == nil {
+		if dockerClient, err = docker.New(ctx); err != nil {
+			return docker.Version{}, fmt.Errorf("unable to create client: %w", err)
+		}
+	}
+	return dockerClient.Version(ctx)
+}
+
+// checkFreeDisk warns if the free disk space at the path is below minFreeDisk, returning true if it is.
+func checkFreeDisk(checks *preflightWarnings, path string, free uint64) bool {
+	if free >= minFreeDisk {
+		return false
+	}
+
+	checks.warn(fmt.Sprintf("only %s of disk space is free", formatGiB(int64(free))),
+		fmt.Sprintf("Only %s of disk space is free at %s, at least %s is recommended.\n"+
+			"The Airbyte images and data may not fit, consider freeing disk space (e.g. with 'abctl local prune').",
+			formatGiB(int64(free)), path, formatGiB(minFreeDisk)))
+	return true
+}
+
+// preflightURL checks that the url is reachable. Any response, even an error status such as the 401 of a
+// registry without credentials, shows that it is.
+func preflightURL(ctx context.Context, client *http.Client, url string) preflightCheck {
+	c := newPreflightURLCheck(url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		c.status, c.measured = preflightFail, err.Error()
+		return c
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		c.status, c.measured = preflightFail, fmt.Sprintf("unreachable: %s", err)
+		return c
+	}
+	_ = res.Body.Close()
+
+	c.status, c.measured = preflightPass, fmt.Sprintf("HTTP %d", res.StatusCode)
+	return c
+}
+
+// newPreflightURLCheck returns the connectivity check of the url, before it's run.
+func newPreflightURLCheck(url string) preflightCheck {
+	return preflightCheck{id: preflightConnectivity, name: "Connectivity", requirement: url + " reachable", code: exitPreflightConnectivity}
+}
+
+// existingDir returns the path, or its closest parent which exists, e.g. before the data directory is created.
+func existingDir(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// printPreflight writes the table of the checks to w.
+func printPreflight(w io.Writer, checks []preflightCheck) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tMEASURED\tREQUIRED")
+	for _, c := range checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.name, strings.ToUpper(string(c.status)), c.measured, c.requirement)
+	}
+	_ = tw.Flush()
+}
+
+// preflightErr returns an ErrPreflightFailed error, exiting with the code of the first failing check,
+// if any of the checks failed. Otherwise returns nil.
+func preflightErr(checks []preflightCheck) error {
+	var failed []string
+	code := 0
+	for _, c := range checks {
+		if c.status != preflightFail {
+			continue
+		}
+		if code == 0 {
+			code = c.code
+		}
+		failed = append(failed, strings.ToLower(c.name))
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &abctl.ExitError{Code: code, Err: fmt.Errorf("%w: %s", abctl.ErrPreflightFailed, strings.Join(failed, ", "))}
+}
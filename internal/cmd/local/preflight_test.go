@@ -0,0 +1,440 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/system"
+	"github.com/google/go-cmp/cmp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// preflightDockerClient returns a docker client which reports the version and resources, and fails the test
+// if anything is created, pulled, started or removed.
+func preflightDockerClient(t *testing.T, version string, ncpu int, memTotal int64) *docker.Docker {
+	t.Helper()
+
+	mutated := func(call string) {
+		t.Errorf("unexpected mutating docker call %s", call)
+	}
+
+	return &docker.Docker{Client: dockertest.MockClient{
+		FnServerVersion: func(ctx context.Context) (types.Version, error) {
+			return types.Version{Version: version, Arch: "arch"}, nil
+		},
+		FnInfo: func(ctx context.Context) (system.Info, error) {
+			return system.Info{NCPU: ncpu, MemTotal: memTotal}, nil
+		},
+		FnContainerList: func(ctx context.Context, options container.ListOptions) ([]types.Container, error) {
+			return nil, nil
+		},
+		FnContainerCreate: func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error) {
+			mutated("ContainerCreate")
+			return container.CreateResponse{}, nil
+		},
+		FnContainerStart: func(ctx context.Context, container string, options container.StartOptions) error {
+			mutated("ContainerStart")
+			return nil
+		},
+		FnContainerRemove: func(ctx context.Context, container string, options container.RemoveOptions) error {
+			mutated("ContainerRemove")
+			return nil
+		},
+		FnImagePull: func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+			mutated("ImagePull")
+			return io.NopCloser(strings.NewReader("")), nil
+		},
+		FnImageRemove: func(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error) {
+			mutated("ImageRemove")
+			return nil, nil
+		},
+		FnImageTag: func(ctx context.Context, source, target string) error {
+			mutated("ImageTag")
+			return nil
+		},
+	}}
+}
+
+// freePort returns a port which was available when called.
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal("unable to listen", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	if err := l.Close(); err != nil {
+		t.Fatal("unable to close the listener", err)
+	}
+	return port
+}
+
+func TestRunPreflight(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	t.Cleanup(func() {
+		dockerClient = nil
+	})
+
+	reachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s request to %s", r.Method, r.URL)
+		}
+		// a registry without credentials is still reachable
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(reachable.Close)
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachableURL := unreachable.URL
+	unreachable.Close()
+
+	held, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal("unable to listen", err)
+	}
+	t.Cleanup(func() { _ = held.Close() })
+	heldPort := held.Addr().(*net.TCPAddr).Port
+
+	small, err := helm.ResourcesPresetFor("small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plentyDisk := func(string) (uint64, error) { return 100 * minFreeDisk, nil }
+
+	tests := []struct {
+		name     string
+		docker   *docker.Docker
+		cfg      func(cfg *preflightConfig)
+		freeDisk func(string) (uint64, error)
+		// wantStatus are the statuses of the checks, by name
+		wantStatus map[string]preflightStatus
+		wantCode   int
+	}{
+		{
+			name:   "pass",
+			docker: preflightDockerClient(t, "27.1.1", 4, 8*gib),
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightPass, "Resources": preflightPass,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightPass,
+			},
+		},
+		{
+			name: "docker unreachable",
+			docker: &docker.Docker{Client: dockertest.MockClient{
+				FnServerVersion: func(ctx context.Context) (types.Version, error) {
+					return types.Version{}, errors.New("cannot connect to the docker daemon")
+				},
+				FnContainerList: func(ctx context.Context, options container.ListOptions) ([]types.Container, error) {
+					return nil, errors.New("cannot connect to the docker daemon")
+				},
+			}},
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightFail, "Docker version": preflightSkip, "Resources": preflightSkip,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightPass,
+			},
+			wantCode: exitPreflightDocker,
+		},
+		{
+			name:   "docker not required",
+			docker: nil,
+			cfg:    func(cfg *preflightConfig) { cfg.requiresDocker = false },
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightSkip, "Docker version": preflightSkip, "Resources": preflightSkip,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightPass,
+			},
+		},
+		{
+			name:   "docker version warns",
+			docker: preflightDockerClient(t, "19.03.5", 4, 8*gib),
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightWarn, "Resources": preflightPass,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightPass,
+			},
+		},
+		{
+			name:   "docker version strict",
+			docker: preflightDockerClient(t, "19.03.5", 4, 8*gib),
+			cfg:    func(cfg *preflightConfig) { cfg.strict = true },
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightFail, "Resources": preflightPass,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightPass,
+			},
+			wantCode: exitPreflightDockerVersion,
+		},
+		{
+			name:   "resources strict",
+			docker: preflightDockerClient(t, "27.1.1", 1, 2*gib),
+			cfg:    func(cfg *preflightConfig) { cfg.strict = true },
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightPass, "Resources": preflightFail,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightPass,
+			},
+			wantCode: exitPreflightResources,
+		},
+		{
+			name:     "disk strict",
+			docker:   preflightDockerClient(t, "27.1.1", 4, 8*gib),
+			cfg:      func(cfg *preflightConfig) { cfg.strict = true },
+			freeDisk: func(string) (uint64, error) { return gib, nil },
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightPass, "Resources": preflightPass,
+				"Disk": preflightFail, "Connectivity": preflightPass, "Port": preflightPass,
+			},
+			wantCode: exitPreflightDisk,
+		},
+		{
+			name:   "connectivity",
+			docker: preflightDockerClient(t, "27.1.1", 4, 8*gib),
+			cfg:    func(cfg *preflightConfig) { cfg.urls = []string{unreachableURL} },
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightPass, "Resources": preflightPass,
+				"Disk": preflightPass, "Connectivity": preflightFail, "Port": preflightPass,
+			},
+			wantCode: exitPreflightConnectivity,
+		},
+		{
+			name:   "port",
+			docker: preflightDockerClient(t, "27.1.1", 4, 8*gib),
+			cfg:    func(cfg *preflightConfig) { cfg.port = heldPort },
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightPass, "Resources": preflightPass,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightFail,
+			},
+			wantCode: exitPreflightPort,
+		},
+		{
+			name:   "first failing check",
+			docker: preflightDockerClient(t, "27.1.1", 4, 8*gib),
+			cfg: func(cfg *preflightConfig) {
+				cfg.urls = []string{unreachableURL}
+				cfg.port = heldPort
+			},
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightPass, "Resources": preflightPass,
+				"Disk": preflightPass, "Connectivity": preflightFail, "Port": preflightFail,
+			},
+			wantCode: exitPreflightConnectivity,
+		},
+		{
+			name:     "skipped checks keep the rest enforced",
+			docker:   preflightDockerClient(t, "27.1.1", 4, 8*gib),
+			freeDisk: func(string) (uint64, error) { return gib, nil },
+			cfg: func(cfg *preflightConfig) {
+				cfg.strict = true
+				cfg.urls = []string{unreachableURL}
+				cfg.port = heldPort
+				cfg.skip = map[string]bool{preflightDisk: true, preflightConnectivity: true}
+			},
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightPass, "Resources": preflightPass,
+				"Disk": preflightSkip, "Connectivity": preflightSkip, "Port": preflightFail,
+			},
+			wantCode: exitPreflightPort,
+		},
+		{
+			name:   "skipped port",
+			docker: preflightDockerClient(t, "27.1.1", 4, 8*gib),
+			cfg: func(cfg *preflightConfig) {
+				cfg.port = heldPort
+				cfg.skip = map[string]bool{preflightPort: true}
+			},
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightPass, "Resources": preflightPass,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightSkip,
+			},
+		},
+		{
+			name:   "skipped docker version",
+			docker: preflightDockerClient(t, "19.03.5", 1, 2*gib),
+			cfg: func(cfg *preflightConfig) {
+				cfg.strict = true
+				cfg.skip = map[string]bool{preflightDockerVersion: true}
+			},
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightPass, "Docker version": preflightSkip, "Resources": preflightFail,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightPass,
+			},
+			wantCode: exitPreflightResources,
+		},
+		{
+			name: "skipped docker skips its dependents",
+			docker: &docker.Docker{Client: dockertest.MockClient{
+				FnServerVersion: func(ctx context.Context) (types.Version, error) {
+					t.Error("unexpected docker version call")
+					return types.Version{}, errors.New("cannot connect to the docker daemon")
+				},
+			}},
+			cfg: func(cfg *preflightConfig) { cfg.skip = map[string]bool{preflightDocker: true} },
+			wantStatus: map[string]preflightStatus{
+				"Docker": preflightSkip, "Docker version": preflightSkip, "Resources": preflightSkip,
+				"Disk": preflightPass, "Connectivity": preflightPass, "Port": preflightPass,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dockerClient = tt.docker
+
+			cfg := preflightConfig{
+				port:           freePort(t),
+				preset:         small,
+				requiresDocker: true,
+				dataDir:        t.TempDir(),
+				urls:           []string{reachable.URL},
+			}
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			freeDisk := plentyDisk
+			if tt.freeDisk != nil {
+				freeDisk = tt.freeDisk
+			}
+
+			checks := runPreflight(context.Background(), preflightEnv{http: reachable.Client(), freeDisk: freeDisk}, cfg)
+
+			gotStatus := map[string]preflightStatus{}
+			for _, c := range checks {
+				gotStatus[c.name] = c.status
+			}
+			if d := cmp.Diff(tt.wantStatus, gotStatus); d != "" {
+				t.Errorf("status mismatch (-want +got):\n%s", d)
+			}
+
+			err := preflightErr(checks)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatal("unexpected error", err)
+				}
+				return
+			}
+
+			var exitErr *abctl.ExitError
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("expected an ExitError but got %v", err)
+			}
+			if d := cmp.Diff(tt.wantCode, exitErr.Code); d != "" {
+				t.Errorf("exit code mismatch (-want +got):\n%s", d)
+			}
+			if !errors.Is(err, abctl.ErrPreflightFailed) {
+				t.Errorf("expected ErrPreflightFailed but got %v", err)
+			}
+		})
+	}
+}
+
+func TestRunPreflight_Measured(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	t.Cleanup(func() {
+		dockerClient = nil
+	})
+	dockerClient = preflightDockerClient(t, "27.1.1", 1, 2*gib)
+
+	small, err := helm.ResourcesPresetFor("small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	port := freePort(t)
+
+	checks := runPreflight(context.Background(), preflightEnv{
+		http:     http.DefaultClient,
+		freeDisk: func(string) (uint64, error) { return 5 * gib, nil },
+	}, preflightConfig{port: port, preset: small, requiresDocker: true, dataDir: dir + "/airbyte/data"})
+
+	want := []preflightCheck{
+		{id: preflightDocker, name: "Docker", status: preflightPass, measured: "reachable", requirement: "reachable", code: exitPreflightDocker},
+		{id: preflightDockerVersion, name: "Docker version", status: preflightPass, measured: "27.1.1", requirement: ">= 20.10", code: exitPreflightDockerVersion},
+		{id: preflightResources, name: "Resources", status: preflightWarn, measured: "1 CPUs, 2.0GiB memory", requirement: ">= 2 CPUs, 4.0GiB memory (small preset)", code: exitPreflightResources},
+		{id: preflightDisk, name: "Disk", status: preflightWarn, measured: fmt.Sprintf("5.0GiB free at %s", dir), requirement: ">= 10.0GiB free", code: exitPreflightDisk},
+		{id: preflightPort, name: "Port", status: preflightPass, measured: "available", requirement: fmt.Sprintf("%d available", port), code: exitPreflightPort},
+	}
+	if d := cmp.Diff(want, checks, cmp.AllowUnexported(preflightCheck{})); d != "" {
+		t.Errorf("checks mismatch (-want +got):\n%s", d)
+	}
+	if err := preflightErr(checks); err != nil {
+		t.Error("expected warnings not to fail outside of strict mode, got", err)
+	}
+}
+
+func TestParsePreflightSkips(t *testing.T) {
+	got, err := parsePreflightSkips([]string{"disk", " connectivity"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if d := cmp.Diff(map[string]bool{preflightDisk: true, preflightConnectivity: true}, got); d != "" {
+		t.Errorf("skips mismatch (-want +got):\n%s", d)
+	}
+
+	// a typo must not silently leave the intended check enforced
+	_, err = parsePreflightSkips([]string{"disk", "conectivity"})
+	want := "unknown pre-flight check 'conectivity' in --skip-preflight, must be one of docker, docker-version, resources, disk, connectivity, port (see --list-preflights)"
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestPrintPreflight(t *testing.T) {
+	var b strings.Builder
+	printPreflight(&b, []preflightCheck{
+		{name: "Docker", status: preflightPass, measured: "reachable", requirement: "reachable"},
+		{name: "Disk", status: preflightFail, measured: "1.0GiB free at /tmp", requirement: ">= 10.0GiB free"},
+	})
+
+	want := `CHECK   STATUS  MEASURED             REQUIRED
+Docker  PASS    reachable            reachable
+Disk    FAIL    1.0GiB free at /tmp  >= 10.0GiB free
+`
+	if d := cmp.Diff(want, b.String()); d != "" {
+		t.Errorf("table mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestInstallCmd_PreflightURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  InstallCmd
+		want []string
+	}{
+		{
+			name: "defaults",
+			want: []string{
+				"https://airbytehq.github.io/charts/index.yaml",
+				"https://kubernetes.github.io/ingress-nginx/index.yaml",
+				"https://registry-1.docker.io/v2/",
+			},
+		},
+		{
+			name: "local chart, ingress class and registry mirror",
+			cmd:  InstallCmd{Chart: "./airbyte", IngressClass: "traefik", RegistryMirror: "mirror.example.com:5000"},
+			want: []string{"https://mirror.example.com:5000/v2/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, tt.cmd.preflightURLs()); d != "" {
+				t.Errorf("urls mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package local
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/pterm/pterm"
+)
+
+// progressMode controls how the progress of a command is displayed, mirroring the progress modes of docker buildx.
+type progressMode string
+
+const (
+	// progressAuto displays the tty progress if stdout is a terminal, otherwise the plain progress.
+	progressAuto progressMode = "auto"
+	// progressPlain prints a line for every step, never redrawing, so the output is readable in logs and CI.
+	progressPlain progressMode = "plain"
+	// progressTTY redraws a spinner in place.
+	progressTTY progressMode = "tty"
+)
+
+// resolve returns the mode, with auto resolved to tty or plain by whether the output is a terminal.
+func (m progressMode) resolve(terminal bool) progressMode {
+	if m == progressAuto || m == "" {
+		if terminal {
+			return progressTTY
+		}
+		return progressPlain
+	}
+	return m
+}
+
+// plainProgressInterval is how often the plain progress repeats the current step, with its percentage and how long
+// it has been running, so a long step, such as pulling the images or waiting for the components to be ready, is
+// seen to progress.
+var plainProgressInterval = 15 * time.Second
+
+// progressPrinter displays the progress of the steps of a command.
+type progressPrinter interface {
+	// Start begins a step, displaying its text.
+	Start(text string)
+	// UpdateText displays the text of the current step.
+	UpdateText(text string)
+	// Event displays a progress event of the service manager, see service.WithProgress.
+	Event(e service.Event)
+	// Success ends the current step, displaying the message as a success.
+	Success(msg string)
+	// Fail ends the current step, displaying the message as a failure.
+	Fail(msg string)
+	// Stop ends the current step without displaying anything.
+	Stop()
+}
+
+// newProgress returns the progress printer of the mode, which must be resolved, writing to the writer of the
+// pterm.DefaultSpinner, so its output follows that of the spinners, e.g. when silenced by --summary-only.
+func newProgress(mode progressMode) progressPrinter {
+	if mode == progressPlain {
+		return newPlainProgress(pterm.DefaultSpinner.Writer)
+	}
+	return &spinnerProgress{spinner: &pterm.DefaultSpinner}
+}
+
+// spinnerProgress displays the progress on a spinner, redrawn in place.
+type spinnerProgress struct {
+	spinner *pterm.SpinnerPrinter
+}
+
+func (p *spinnerProgress) Start(text string) {
+	p.spinner, _ = p.spinner.Start(text)
+}
+
+func (p *spinnerProgress) UpdateText(text string) {
+	p.spinner.UpdateText(text)
+}
+
+func (p *spinnerProgress) Event(e service.Event) {
+	p.spinner.UpdateText(e.Message)
+}
+
+func (p *spinnerProgress) Success(msg string) {
+	p.spinner.Success(msg)
+}
+
+func (p *spinnerProgress) Fail(msg string) {
+	p.spinner.Fail(msg)
+}
+
+func (p *spinnerProgress) Stop() {
+	_ = p.spinner.Stop()
+}
+
+// plainProgress prints a line for every update of the progress, prefixed by the percentage of the operation
+// completed once the service manager has reported one. While a step runs, it is repeated every
+// plainProgressInterval.
+type plainProgress struct {
+	w io.Writer
+
+	mu      sync.Mutex
+	text    string
+	percent int
+	// since is when the current step began
+	since time.Time
+	// stop ends the repetition of the current step, nil if no step is running.
+	stop chan struct{}
+}
+
+// newPlainProgress returns a plain progress printing to w, the default output of pterm if nil.
+func newPlainProgress(w io.Writer) *plainProgress {
+	return &plainProgress{w: w, percent: -1}
+}
+
+func (p *plainProgress) Start(text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.end()
+	p.percent = -1
+	p.stop = make(chan struct{})
+	go p.repeat(p.stop)
+	p.update(text)
+}
+
+func (p *plainProgress) UpdateText(text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.update(text)
+}
+
+func (p *plainProgress) Event(e service.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.percent = e.Percent
+	p.update(e.Message)
+}
+
+func (p *plainProgress) Success(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.end()
+	pterm.Success.WithWriter(p.w).Println(msg)
+}
+
+func (p *plainProgress) Fail(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.end()
+	pterm.Error.WithWriter(p.w).Println(msg)
+}
+
+func (p *plainProgress) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.end()
+}
+
+// update prints the text as the current step, unless it already is.
+func (p *plainProgress) update(text string) {
+	if text == p.text {
+		return
+	}
+	p.text = text
+	p.since = time.Now()
+	pterm.Fprintln(p.w, p.line(""))
+}
+
+// repeat prints the current step every plainProgressInterval, until stopped.
+func (p *plainProgress) repeat(stop chan struct{}) {
+	ticker := time.NewTicker(plainProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			// the step may have ended while waiting for the lock
+			if p.stop == stop {
+				pterm.Fprintln(p.w, p.line(fmt.Sprintf(" (%s)", time.Since(p.since).Round(time.Second))))
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+// line returns the current step, prefixed by its percentage if known, followed by the suffix.
+func (p *plainProgress) line(suffix string) string {
+	if p.percent < 0 {
+		return p.text + suffix
+	}
+	return fmt.Sprintf("[%3d%%] %s%s", p.percent, p.text, suffix)
+}
+
+// end stops repeating the current step, which is forgotten.
+func (p *plainProgress) end() {
+	if p.stop != nil {
+		close(p.stop)
+		p.stop = nil
+	}
+	p.text = ""
+}
@@ -0,0 +1,144 @@
+package local
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+)
+
+func TestProgressMode_Resolve(t *testing.T) {
+	tests := []struct {
+		mode     progressMode
+		terminal bool
+		want     progressMode
+	}{
+		{mode: progressAuto, terminal: true, want: progressTTY},
+		{mode: progressAuto, terminal: false, want: progressPlain},
+		{mode: "", terminal: false, want: progressPlain},
+		{mode: progressPlain, terminal: true, want: progressPlain},
+		{mode: progressTTY, terminal: false, want: progressTTY},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			if d := cmp.Diff(tt.want, tt.mode.resolve(tt.terminal)); d != "" {
+				t.Errorf("mode mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestNewProgress(t *testing.T) {
+	if _, ok := newProgress(progressPlain).(*plainProgress); !ok {
+		t.Error("expected the plain progress")
+	}
+	if _, ok := newProgress(progressTTY).(*spinnerProgress); !ok {
+		t.Error("expected the spinner progress")
+	}
+}
+
+// syncBuffer is a bytes.Buffer which may be written to while it is read.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) lines() []string {
+	return strings.Split(strings.TrimSpace(pterm.RemoveColorFromString(b.String())), "\n")
+}
+
+func setPlainProgressInterval(t *testing.T, d time.Duration) {
+	orig := plainProgressInterval
+	plainProgressInterval = d
+	t.Cleanup(func() { plainProgressInterval = orig })
+}
+
+func TestPlainProgress(t *testing.T) {
+	// no step is repeated during the test
+	setPlainProgressInterval(t, time.Hour)
+
+	b := &syncBuffer{}
+	p := newPlainProgress(b)
+	p.Start("Starting installation")
+	p.UpdateText("Checking for Docker installation")
+	p.UpdateText("Checking for Docker installation")
+	p.Event(service.Event{Phase: service.PhaseAirbyte, Message: "Installing the airbyte chart", Percent: 15})
+	p.Event(service.Event{Phase: service.PhaseHealth, Message: "Verifying the health of the airbyte components", Percent: 60})
+	p.Success("Airbyte installation complete")
+
+	// a line for every update, the same text only once, never redrawing a line in place
+	if out := b.String(); strings.Contains(out, "\r") {
+		t.Errorf("expected no redraws:\n%q", out)
+	}
+	lines := b.lines()
+	want := []string{
+		"Starting installation",
+		"Checking for Docker installation",
+		"[ 15%] Installing the airbyte chart",
+		"[ 60%] Verifying the health of the airbyte components",
+	}
+	if len(lines) != len(want)+1 {
+		t.Fatalf("unexpected lines:\n%s", strings.Join(lines, "\n"))
+	}
+	if d := cmp.Diff(want, lines[:len(want)]); d != "" {
+		t.Errorf("lines mismatch (-want +got):\n%s", d)
+	}
+	if !strings.Contains(lines[len(want)], "Airbyte installation complete") {
+		t.Errorf("expected the success message, got %q", lines[len(want)])
+	}
+}
+
+func TestPlainProgress_Repeats(t *testing.T) {
+	setPlainProgressInterval(t, time.Millisecond)
+
+	b := &syncBuffer{}
+	p := newPlainProgress(b)
+	p.Start("Starting installation")
+	p.Event(service.Event{Phase: service.PhaseHealth, Message: "Verifying the health of the airbyte components", Percent: 60})
+
+	// the running step is repeated with its percentage and how long it has been running
+	repeated := func() bool {
+		for _, line := range b.lines() {
+			if strings.HasPrefix(line, "[ 60%] Verifying the health of the airbyte components (") {
+				return true
+			}
+		}
+		return false
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !repeated() {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the step to be repeated:\n%s", strings.Join(b.lines(), "\n"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// once stopped, nothing is printed
+	p.Stop()
+	stopped := len(b.lines())
+	time.Sleep(20 * time.Millisecond)
+	if d := cmp.Diff(stopped, len(b.lines())); d != "" {
+		t.Errorf("expected no lines once stopped (-want +got):\n%s", d)
+	}
+	if out := b.String(); strings.Contains(out, "\r") {
+		t.Errorf("expected no redraws:\n%q", out)
+	}
+}
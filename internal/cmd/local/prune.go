@@ -0,0 +1,106 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type PruneCmd struct {
+	Confirm bool `help:"Remove the unused Docker images pulled by abctl. Without it, only the reclaimable disk space is reported."`
+}
+
+func (p *PruneCmd) Run(ctx context.Context) error {
+	ctx, span := trace.NewSpan(ctx, "local prune")
+	defer span.End()
+
+	span.SetAttributes(attribute.Bool("confirm", p.Confirm))
+
+	if dockerClient == nil {
+		var err error
+		if dockerClient, err = docker.New(ctx); err != nil {
+			pterm.Error.Println("Unable to create Docker client")
+			return fmt.Errorf("%w: unable to create client: %w", abctl.ErrDocker, err)
+		}
+	}
+
+	before, images, err := service.UnusedImages(ctx, dockerClient, paths.ImageManifest)
+	if err != nil {
+		pterm.Error.Println("Unable to determine the Docker disk usage")
+		return err
+	}
+	printDiskUsage(os.Stdout, before)
+	printUnusedImages(os.Stdout, images)
+
+	if len(images) == 0 {
+		return nil
+	}
+	if !p.Confirm {
+		pterm.Info.Println("Run with --confirm to remove these images")
+		return nil
+	}
+
+	res, err := service.RemoveUnusedImages(ctx, dockerClient, paths.ImageManifest, images)
+	if err != nil {
+		pterm.Error.Println("Unable to remove the unused Docker images pulled by abctl")
+		return fmt.Errorf("unable to prune images: %w", err)
+	}
+
+	after, err := dockerClient.DiskUsage(ctx)
+	if err != nil {
+		pterm.Warning.Printfln("Removed %d Docker images, unable to determine the reclaimed disk space: %s", len(res.Removed), err)
+		return nil
+	}
+	pterm.Success.Printfln("Removed %d Docker images, reclaiming %s (Docker disk usage %s before, %s after)",
+		len(res.Removed), formatGiB(max(before.Size()-after.Size(), 0)), formatGiB(before.Size()), formatGiB(after.Size()))
+	return nil
+}
+
+// printDiskUsage writes the disk space used, and reclaimable, per type of Docker resource.
+func printDiskUsage(w io.Writer, u docker.DiskUsage) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tTOTAL\tACTIVE\tSIZE\tRECLAIMABLE")
+	rows := []struct {
+		name string
+		c    docker.DiskUsageCategory
+	}{
+		{"Images", u.Images},
+		{"Containers", u.Containers},
+		{"Volumes", u.Volumes},
+		{"Build Cache", u.BuildCache},
+	}
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n", r.name, r.c.Count, r.c.Active, formatGiB(r.c.Size), formatGiB(r.c.Reclaimable))
+	}
+	_ = tw.Flush()
+}
+
+// printUnusedImages writes the unused images abctl would remove, and the disk space removing them reclaims.
+// Only these images are ever removed, any other reclaimable space belongs to resources unrelated to abctl.
+func printUnusedImages(w io.Writer, images []docker.UnusedImage) {
+	if len(images) == 0 {
+		fmt.Fprintln(w, "No unused Docker images pulled by abctl were found")
+		return
+	}
+
+	var total int64
+	for _, img := range images {
+		total += img.Size
+	}
+	fmt.Fprintf(w, "%d unused Docker images pulled by abctl, reclaiming up to %s:\n", len(images), formatGiB(total))
+	for _, img := range images {
+		for _, ref := range img.Refs {
+			fmt.Fprintf(w, "  - %s\n", ref)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package local
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPrintDiskUsage(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+
+	var out bytes.Buffer
+	printDiskUsage(&out, docker.DiskUsage{
+		Images:     docker.DiskUsageCategory{Count: 3, Active: 1, Size: 2 * gib, Reclaimable: 3 * gib / 2},
+		Containers: docker.DiskUsageCategory{Count: 2, Active: 1, Size: gib / 2},
+		Volumes:    docker.DiskUsageCategory{Count: 1, Size: gib, Reclaimable: gib},
+	})
+
+	want := `TYPE         TOTAL  ACTIVE  SIZE    RECLAIMABLE
+Images       3      1       2.0GiB  1.5GiB
+Containers   2      1       0.5GiB  0.0GiB
+Volumes      1      0       1.0GiB  1.0GiB
+Build Cache  0      0       0.0GiB  0.0GiB
+`
+	if d := cmp.Diff(want, out.String()); d != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestPrintUnusedImages(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+
+	var out bytes.Buffer
+	printUnusedImages(&out, []docker.UnusedImage{
+		{ID: "sha256:1", Refs: []string{"airbyte/server:1.0.0", "airbyte/server:latest"}, Size: gib},
+		{ID: "sha256:2", Refs: []string{"sha256:2"}, Size: gib / 2},
+	})
+
+	want := `2 unused Docker images pulled by abctl, reclaiming up to 1.5GiB:
+  - airbyte/server:1.0.0
+  - airbyte/server:latest
+  - sha256:2
+`
+	if d := cmp.Diff(want, out.String()); d != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", d)
+	}
+
+	out.Reset()
+	printUnusedImages(&out, nil)
+	if d := cmp.Diff("No unused Docker images pulled by abctl were found\n", out.String()); d != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,148 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pterm/pterm"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/airbytehq/abctl/internal/trace"
+)
+
+// ReinstallCmd uninstalls the existing installation, keeping its persisted data, and installs it again on that data.
+// A reinstall accepts the same flags as an install, by default reinstalling the installed chart version.
+type ReinstallCmd struct {
+	InstallCmd `embed:""`
+}
+
+// reinstallSteps are the steps of a reinstall, see reinstall.
+type reinstallSteps struct {
+	// installedVersion returns the chart version of the existing installation.
+	installedVersion func(ctx context.Context) (string, error)
+	// targetVersion returns the chart version to reinstall, given the installed chart version.
+	targetVersion func(installed string) (string, error)
+	uninstall     func(ctx context.Context, opts service.UninstallOpts) error
+	install       func(ctx context.Context) error
+}
+
+// Run executes the reinstall command, which requires an existing installation.
+func (r *ReinstallCmd) Run(ctx context.Context, provider k8s.Provider, newSvcMgrClients service.ManagerClientFactory, telClient telemetry.Client) error {
+	ctx, span := trace.NewSpan(ctx, "local reinstall")
+	defer span.End()
+
+	if r.FromSnapshot != "" {
+		return errors.New("--from-snapshot cannot be combined with reinstall, which keeps the existing data")
+	}
+
+	cluster, err := provider.GetCluster(ctx)
+	if errors.Is(err, abctl.ErrClusterNotFound) {
+		pterm.Error.Printfln("No existing cluster '%s' found", provider.ClusterName)
+		return errors.New("no existing installation to reinstall, run 'abctl local install' instead")
+	}
+	if err != nil {
+		pterm.Error.Printfln("Unable to determine status of any existing '%s' cluster", provider.ClusterName)
+		return err
+	}
+
+	_, helmClient, err := newSvcMgrClients(provider.Kubeconfig, provider.Context)
+	if err != nil {
+		return err
+	}
+
+	steps := reinstallSteps{
+		installedVersion: func(ctx context.Context) (string, error) {
+			rel, err := helmClient.GetRelease(common.AirbyteChartRelease)
+			if err != nil {
+				return "", fmt.Errorf("unable to fetch the installed airbyte release: %w", err)
+			}
+			return rel.Chart.Metadata.Version, nil
+		},
+		targetVersion: func(installed string) (string, error) {
+			if r.Chart == "" && r.ChartVersion == "" {
+				r.ChartVersion = installed
+			}
+			if err := r.setDefaultChartFlags(helmClient); err != nil {
+				return "", fmt.Errorf("failed to set chart defaults: %w", err)
+			}
+			return r.ChartVersion, nil
+		},
+		uninstall: func(ctx context.Context, opts service.UninstallOpts) error {
+			progress := newProgress(r.Progress.resolve(isTerminal(os.Stdout)))
+			progress.Start("Uninstalling the existing installation")
+			defer progress.Stop()
+
+			svcMgr, err := service.NewManager(provider, service.WithTelemetryClient(telClient), service.WithProgress(progress.Event))
+			if err != nil {
+				return fmt.Errorf("unable to initialize local command: %w", err)
+			}
+			if err := svcMgr.Uninstall(ctx, opts); err != nil {
+				pterm.Warning.Printfln("Unable to complete uninstall: %s\nWill still attempt to uninstall the cluster", err)
+			}
+			return uninstallCluster(ctx, cluster, provider.ClusterName)
+		},
+		install: func(ctx context.Context) error {
+			return r.InstallCmd.Run(ctx, provider, newSvcMgrClients, telClient)
+		},
+	}
+
+	return reinstall(ctx, steps, paths.Data)
+}
+
+// reinstall uninstalls the existing installation, keeping the persisted data in the dataDir, and installs the target
+// chart version on it. Nothing is uninstalled unless the target chart version is compatible with the data of the
+// installed one. Should the install fail, the data is still kept, so the install can be retried.
+func reinstall(ctx context.Context, steps reinstallSteps, dataDir string) error {
+	span := oteltrace.SpanFromContext(ctx)
+
+	installed, err := steps.installedVersion(ctx)
+	if err != nil {
+		return err
+	}
+	target, err := steps.targetVersion(installed)
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(
+		attribute.String("installed_version", installed),
+		attribute.String("target_version", target),
+	)
+
+	// the database of the installed version may already be migrated, and cannot be downgraded
+	if err := helm.CheckUpgrade(installed, target); err != nil {
+		pterm.Error.Printfln("Chart version %s cannot be reinstalled on the data of chart version %s", target, installed)
+		return err
+	}
+
+	volumes, err := persistedVolumes(dataDir)
+	if err != nil {
+		return err
+	}
+	if len(volumes) == 0 {
+		pterm.Warning.Printfln("No persisted data found in '%s', Airbyte will be reinstalled without any existing data", dataDir)
+	}
+
+	pterm.Info.Printfln("Uninstalling chart version %s, keeping the persisted data in '%s'", installed, dataDir)
+	if err := steps.uninstall(ctx, service.UninstallOpts{Persisted: false}); err != nil {
+		return err
+	}
+
+	pterm.Info.Printfln("Reinstalling chart version %s", target)
+	if err := steps.install(ctx); err != nil {
+		pterm.Error.Printfln("Reinstallation failed, the persisted data in '%s' was kept.\n"+
+			"Once the failure is resolved, run 'abctl local install --chart-version %s' to install Airbyte on it again.", dataDir, target)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/google/go-cmp/cmp"
+)
+
+// reinstallRecorder records the steps of a reinstall, failing the install with installErr.
+type reinstallRecorder struct {
+	installed  string
+	target     string
+	installErr error
+
+	calls     []string
+	uninstall []service.UninstallOpts
+}
+
+func (r *reinstallRecorder) steps() reinstallSteps {
+	return reinstallSteps{
+		installedVersion: func(context.Context) (string, error) {
+			r.calls = append(r.calls, "installedVersion")
+			return r.installed, nil
+		},
+		targetVersion: func(installed string) (string, error) {
+			r.calls = append(r.calls, "targetVersion")
+			if r.target == "" {
+				return installed, nil
+			}
+			return r.target, nil
+		},
+		uninstall: func(_ context.Context, opts service.UninstallOpts) error {
+			r.calls = append(r.calls, "uninstall")
+			r.uninstall = append(r.uninstall, opts)
+			return nil
+		},
+		install: func(context.Context) error {
+			r.calls = append(r.calls, "install")
+			return r.installErr
+		},
+	}
+}
+
+// dataDirWithVolumes returns a data dir containing persisted volumes.
+func dataDirWithVolumes(t *testing.T) string {
+	dataDir := t.TempDir()
+	for _, dir := range []string{"airbyte-volume-db", "airbyte-local-pv"} {
+		if err := os.MkdirAll(filepath.Join(dataDir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dataDir, dir, "data"), []byte(dir), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dataDir
+}
+
+func TestReinstall(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed string
+		target    string
+		wantCalls []string
+		wantErr   error
+	}{
+		{
+			name:      "same version",
+			installed: "1.5.0",
+			wantCalls: []string{"installedVersion", "targetVersion", "uninstall", "install"},
+		},
+		{
+			name:      "newer version",
+			installed: "1.5.0",
+			target:    "1.6.0",
+			wantCalls: []string{"installedVersion", "targetVersion", "uninstall", "install"},
+		},
+		{
+			name:      "older version is blocked before uninstalling",
+			installed: "1.6.0",
+			target:    "1.5.0",
+			wantCalls: []string{"installedVersion", "targetVersion"},
+			wantErr:   abctl.ErrUpgradeBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &reinstallRecorder{installed: tt.installed, target: tt.target}
+
+			err := reinstall(context.Background(), rec.steps(), dataDirWithVolumes(t))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v but got %v", tt.wantErr, err)
+			}
+			if d := cmp.Diff(tt.wantCalls, rec.calls); d != "" {
+				t.Errorf("steps mismatch (-want +got):\n%s", d)
+			}
+			for _, opts := range rec.uninstall {
+				if opts.Persisted {
+					t.Error("reinstall must not remove the persisted data")
+				}
+			}
+		})
+	}
+}
+
+func TestReinstall_InstallFailureKeepsData(t *testing.T) {
+	dataDir := dataDirWithVolumes(t)
+	want, err := persistedVolumes(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	installErr := errors.New("test error")
+	rec := &reinstallRecorder{installed: "1.5.0", installErr: installErr}
+
+	if err := reinstall(context.Background(), rec.steps(), dataDir); !errors.Is(err, installErr) {
+		t.Errorf("expected error %v but got %v", installErr, err)
+	}
+	if d := cmp.Diff([]service.UninstallOpts{{Persisted: false}}, rec.uninstall); d != "" {
+		t.Errorf("uninstall opts mismatch (-want +got):\n%s", d)
+	}
+
+	got, err := persistedVolumes(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("persisted volumes mismatch (-want +got):\n%s", d)
+	}
+	for _, volume := range got {
+		raw, err := os.ReadFile(filepath.Join(volume, "data"))
+		if err != nil {
+			t.Fatalf("persisted data was removed: %s", err)
+		}
+		if d := cmp.Diff(filepath.Base(volume), string(raw)); d != "" {
+			t.Errorf("persisted data mismatch (-want +got):\n%s", d)
+		}
+	}
+}
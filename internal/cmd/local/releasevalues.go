@@ -0,0 +1,50 @@
+package local
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/service"
+	goHelm "github.com/mittwald/go-helm-client"
+	"github.com/pterm/pterm"
+)
+
+// readReleaseValues reads the values of the existing Airbyte release into the baseValues, from the cluster of the
+// --kubeconfig if one is given, otherwise from the abctl cluster.
+func (i *InstallCmd) readReleaseValues(provider k8s.Provider, newSvcMgrClients service.ManagerClientFactory) error {
+	kubeconfig, kubeContext := provider.Kubeconfig, provider.Context
+	if i.Kubeconfig != "" {
+		// the current context of the kubeconfig, unless another is given
+		kubeconfig, kubeContext = i.Kubeconfig, ""
+	}
+	if i.KubeContext != "" {
+		kubeContext = i.KubeContext
+	}
+
+	_, helmClient, err := newSvcMgrClients(kubeconfig, kubeContext)
+	if err != nil {
+		return fmt.Errorf("unable to connect to the cluster of the release: %w", err)
+	}
+
+	i.baseValues, err = releaseValues(helmClient)
+	return err
+}
+
+// releaseValues returns the values of the existing Airbyte release, without those which don't transfer to another
+// cluster, see helm.TransferableValues.
+func releaseValues(helmClient goHelm.Client) (map[string]any, error) {
+	values, err := helmClient.GetReleaseValues(common.AirbyteChartRelease, false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the values of the release '%s': %w", common.AirbyteChartRelease, err)
+	}
+
+	values, omitted := helm.TransferableValues(values)
+	pterm.Success.Printfln("Using the values of the release '%s' as the base values", common.AirbyteChartRelease)
+	if len(omitted) > 0 {
+		pterm.Info.Printfln("Omitted the values which only apply to the cluster of the release:\n  %s", strings.Join(omitted, "\n  "))
+	}
+	return values, nil
+}
@@ -0,0 +1,169 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/airbytehq/abctl/internal/service/servicetest"
+	"github.com/google/go-cmp/cmp"
+	goHelm "github.com/mittwald/go-helm-client"
+	"gopkg.in/yaml.v3"
+)
+
+const testReleaseValues = `
+global:
+  airbyteUrl: http://airbyte.old-host:8000
+  auth:
+    enabled: true
+  env_vars:
+    AIRBYTE_INSTALLATION_ID: old-user
+  storageClass: gp3
+server:
+  nodeSelector:
+    kubernetes.io/hostname: old-node
+worker:
+  replicaCount: 2
+  debug: true
+`
+
+// fakeRelease returns a fake helm client holding an airbyte release of the values.
+func fakeRelease(t *testing.T, values string) *servicetest.FakeHelm {
+	helmClient := servicetest.NewFakeHelm()
+	if _, err := helmClient.InstallOrUpgradeChart(context.Background(), &goHelm.ChartSpec{
+		ReleaseName: common.AirbyteChartRelease,
+		ValuesYaml:  values,
+	}, nil); err != nil {
+		t.Fatal(err)
+	}
+	return helmClient
+}
+
+func TestReadReleaseValues(t *testing.T) {
+	provider := k8s.FakeProvider(k8s.NewFakeCluster(true), "/abctl/abctl.kubeconfig")
+
+	tests := []struct {
+		name            string
+		cmd             InstallCmd
+		wantKubeconfig  string
+		wantKubeContext string
+	}{
+		{
+			name:            "abctl cluster",
+			wantKubeconfig:  "/abctl/abctl.kubeconfig",
+			wantKubeContext: "fake-airbyte-abctl",
+		},
+		{
+			name:           "kubeconfig",
+			cmd:            InstallCmd{Kubeconfig: "/old/kubeconfig"},
+			wantKubeconfig: "/old/kubeconfig",
+		},
+		{
+			name:            "kubeconfig and context",
+			cmd:             InstallCmd{Kubeconfig: "/old/kubeconfig", KubeContext: "old"},
+			wantKubeconfig:  "/old/kubeconfig",
+			wantKubeContext: "old",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			helmClient := fakeRelease(t, testReleaseValues)
+			var gotKubeconfig, gotKubeContext string
+			factory := func(kubeconfig, kubeContext string) (k8s.Client, goHelm.Client, error) {
+				gotKubeconfig, gotKubeContext = kubeconfig, kubeContext
+				return &k8stest.MockClient{}, helmClient, nil
+			}
+
+			if err := tt.cmd.readReleaseValues(provider, factory); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.wantKubeconfig, gotKubeconfig); d != "" {
+				t.Errorf("kubeconfig mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantKubeContext, gotKubeContext); d != "" {
+				t.Errorf("kube context mismatch (-want +got):\n%s", d)
+			}
+
+			// the values only applying to the cluster of the release are omitted
+			want := map[string]any{
+				"global": map[string]any{
+					"auth":     map[string]any{"enabled": true},
+					"env_vars": map[string]any{},
+				},
+				"server": map[string]any{},
+				"worker": map[string]any{"replicaCount": 2, "debug": true},
+			}
+			if d := cmp.Diff(want, tt.cmd.baseValues); d != "" {
+				t.Errorf("base values mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestReadReleaseValues_NotFound(t *testing.T) {
+	provider := k8s.FakeProvider(k8s.NewFakeCluster(true), "/abctl/abctl.kubeconfig")
+	cmd := InstallCmd{}
+
+	err := cmd.readReleaseValues(provider, servicetest.ClientFactory(&k8stest.MockClient{}, servicetest.NewFakeHelm()))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if d := cmp.Diff("unable to read the values of the release 'airbyte-abctl': release: not found: airbyte-abctl", err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+
+	errConnect := errors.New("connection refused")
+	err = cmd.readReleaseValues(provider, func(_, _ string) (k8s.Client, goHelm.Client, error) {
+		return nil, nil, errConnect
+	})
+	if !errors.Is(err, errConnect) {
+		t.Errorf("expected the connection error but got %v", err)
+	}
+}
+
+func TestInstallOpts_ChartValuesFromRelease(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("worker:\n  replicaCount: 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := k8s.FakeProvider(k8s.NewFakeCluster(true), "/abctl/abctl.kubeconfig")
+	cmd := InstallCmd{
+		Chart:                  "/test/path/to/chart",
+		Port:                   9000,
+		Values:                 valuesFile,
+		ChartValuesFromRelease: true,
+	}
+	factory := servicetest.ClientFactory(&k8stest.MockClient{}, fakeRelease(t, testReleaseValues))
+	if err := cmd.readReleaseValues(provider, factory); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	opts, err := cmd.installOpts(context.Background(), "test-user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vals map[string]any
+	if err := yaml.Unmarshal([]byte(opts.HelmValuesYaml), &vals); err != nil {
+		t.Fatal(err)
+	}
+
+	// the values of the release are the base of those of abctl, which the values file overrides
+	global := vals["global"].(map[string]any)
+	if d := cmp.Diff(map[string]any{"AIRBYTE_INSTALLATION_ID": "test-user"}, global["env_vars"]); d != "" {
+		t.Errorf("env vars mismatch (-want +got):\n%s", d)
+	}
+	if _, ok := global["storageClass"]; ok {
+		t.Error("expected the storage class of the release to be omitted")
+	}
+	if d := cmp.Diff(map[string]any{"replicaCount": 3, "debug": true}, vals["worker"]); d != "" {
+		t.Errorf("worker mismatch (-want +got):\n%s", d)
+	}
+}
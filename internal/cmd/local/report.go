@@ -0,0 +1,293 @@
+package local
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/build"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/docker/docker/api/types/image"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+)
+
+// installRun is what an install run did, as recorded for its report.
+type installRun struct {
+	chart        string
+	chartVersion string
+	// images are the images of the chart which were pulled and loaded into the cluster
+	images []string
+	// valuesYAML are the merged helm values provided to the chart
+	valuesYAML string
+}
+
+// installReport is the report written by install --report-file, to attach to audits and support tickets.
+// It never contains the helm values themselves, only their keys.
+type installReport struct {
+	Generated    time.Time         `json:"generated"`
+	Outcome      string            `json:"outcome"`
+	Error        string            `json:"error,omitempty"`
+	Environment  reportEnvironment `json:"environment"`
+	Chart        string            `json:"chart"`
+	ChartVersion string            `json:"chartVersion"`
+	Images       []reportImage     `json:"images"`
+	Phases       []reportPhase     `json:"phases"`
+	ValuesKeys   []string          `json:"valuesKeys"`
+	Warnings     []string          `json:"warnings"`
+}
+
+type reportEnvironment struct {
+	OS             string `json:"os"`
+	Arch           string `json:"arch"`
+	Abctl          string `json:"abctl"`
+	DockerVersion  string `json:"dockerVersion"`
+	DockerArch     string `json:"dockerArch"`
+	DockerPlatform string `json:"dockerPlatform"`
+}
+
+type reportImage struct {
+	Name string `json:"name"`
+	// Digest is the repository digest of the image, or its id if it has none, empty if the image isn't present.
+	Digest string `json:"digest"`
+}
+
+type reportPhase struct {
+	Phase    string `json:"phase"`
+	Duration string `json:"duration"`
+	// Outcome is empty if the phase never ended, e.g. the install was interrupted.
+	Outcome string `json:"outcome"`
+}
+
+// buildInstallReport returns the report of the install run, which failed if err isn't nil.
+// The phases are those of the most recent install in the journal entries.
+func buildInstallReport(ctx context.Context, dockerClient *docker.Docker, run installRun, journal []service.JournalEntry, warnings []string, err error) installReport {
+	report := installReport{
+		Generated:    time.Now().UTC(),
+		Outcome:      service.JournalSucceeded,
+		Environment:  reportEnv(ctx, dockerClient),
+		Chart:        run.chart,
+		ChartVersion: run.chartVersion,
+		Phases:       reportPhases(journal),
+	}
+	if err != nil {
+		report.Outcome, report.Error = service.JournalFailed, redactMessage(err.Error())
+	}
+	for _, w := range warnings {
+		report.Warnings = append(report.Warnings, redactMessage(w))
+	}
+
+	if dockerClient != nil {
+		report.Images = reportImages(ctx, dockerClient.Client, run.images)
+	}
+
+	if run.valuesYAML != "" {
+		var values map[string]any
+		if err := yaml.Unmarshal([]byte(run.valuesYAML), &values); err != nil {
+			pterm.Debug.Printfln("Unable to unmarshal the merged values for the report: %s", err)
+		} else {
+			report.ValuesKeys = helm.ValuesKeys(values)
+		}
+	}
+
+	return report
+}
+
+// reportEnv returns the environment abctl ran in, the docker fields are empty if docker is unavailable.
+func reportEnv(ctx context.Context, dockerClient *docker.Docker) reportEnvironment {
+	env := reportEnvironment{OS: runtime.GOOS, Arch: runtime.GOARCH, Abctl: build.Version}
+	if dockerClient == nil {
+		return env
+	}
+
+	ver, err := dockerClient.Version(ctx)
+	if err != nil {
+		pterm.Debug.Printfln("Unable to determine the docker version for the report: %s", err)
+		return env
+	}
+	env.DockerVersion, env.DockerArch, env.DockerPlatform = ver.Version, ver.Arch, ver.Platform
+	return env
+}
+
+// reportImages returns the digest of each of the images, as present in docker.
+func reportImages(ctx context.Context, client docker.Client, images []string) []reportImage {
+	summaries, err := client.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		pterm.Debug.Printfln("Unable to list the images for the report: %s", err)
+	}
+
+	byRef := map[string]image.Summary{}
+	for _, s := range summaries {
+		for _, tag := range s.RepoTags {
+			byRef[tag] = s
+		}
+	}
+
+	report := make([]reportImage, 0, len(images))
+	for _, img := range images {
+		ri := reportImage{Name: img}
+		if s, ok := byRef[img]; ok {
+			ri.Digest = s.ID
+			if len(s.RepoDigests) > 0 {
+				if _, digest, ok := strings.Cut(s.RepoDigests[0], "@"); ok {
+					ri.Digest = digest
+				}
+			}
+		}
+		report = append(report, ri)
+	}
+	return report
+}
+
+// reportPhases returns how long each phase of the most recent install in the journal entries took.
+func reportPhases(entries []service.JournalEntry) []reportPhase {
+	spans := service.Timeline(entries)
+
+	start := -1
+	for idx, span := range spans {
+		if span.Operation == service.OperationInstall && span.Phase == "" {
+			start = idx
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	var phases []reportPhase
+	for _, span := range spans[start:] {
+		if span.Operation != service.OperationInstall {
+			continue
+		}
+		phase := string(span.Phase)
+		if phase == "" {
+			phase = "total"
+		}
+		phases = append(phases, reportPhase{
+			Phase:    phase,
+			Duration: span.Duration.Round(time.Millisecond).String(),
+			Outcome:  span.Outcome,
+		})
+	}
+	return phases
+}
+
+// redactMessage returns the message, unless it contains secret material (e.g. a private key), see helm.IsSensitiveValue.
+func redactMessage(msg string) string {
+	if helm.IsSensitiveValue(msg) {
+		return helm.RedactedValue
+	}
+	return msg
+}
+
+// writeInstallReport writes the report to the path, as json if the path has a .json extension, otherwise as markdown.
+func writeInstallReport(path string, report installReport) error {
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var err error
+		if data, err = json.MarshalIndent(report, "", "  "); err != nil {
+			return fmt.Errorf("unable to marshal the install report: %w", err)
+		}
+		data = append(data, '\n')
+	} else {
+		data = []byte(report.markdown())
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		pterm.Error.Printfln("Unable to write the install report to '%s'", path)
+		return fmt.Errorf("unable to write the install report to '%s': %w", path, err)
+	}
+	pterm.Info.Printfln("Install report written to '%s'", path)
+	return nil
+}
+
+// markdown returns the report as a markdown document.
+func (r installReport) markdown() string {
+	var b strings.Builder
+	b.WriteString("# abctl install report\n\n")
+	fmt.Fprintf(&b, "- Generated: %s\n", r.Generated.Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Outcome: %s\n", r.Outcome)
+	if r.Error != "" {
+		fmt.Fprintf(&b, "- Error: %s\n", r.Error)
+	}
+
+	b.WriteString("\n## Environment\n\n")
+	fmt.Fprintf(&b, "- OS: %s/%s\n", r.Environment.OS, r.Environment.Arch)
+	fmt.Fprintf(&b, "- abctl: %s\n", r.Environment.Abctl)
+	if r.Environment.DockerVersion == "" {
+		b.WriteString("- Docker: unknown\n")
+	} else {
+		fmt.Fprintf(&b, "- Docker: %s (%s, %s)\n", r.Environment.DockerVersion, r.Environment.DockerArch, r.Environment.DockerPlatform)
+	}
+
+	b.WriteString("\n## Chart\n\n")
+	fmt.Fprintf(&b, "- Chart: %s\n", orUnknown(r.Chart))
+	fmt.Fprintf(&b, "- Version: %s\n", orUnknown(r.ChartVersion))
+
+	b.WriteString("\n## Images\n\n")
+	if len(r.Images) == 0 {
+		b.WriteString("None\n")
+	} else {
+		tw := tabwriter.NewWriter(&b, 0, 0, 1, ' ', tabwriter.Debug)
+		fmt.Fprintln(tw, "| Image\t Digest\t")
+		fmt.Fprintln(tw, "|---\t---\t")
+		for _, img := range r.Images {
+			fmt.Fprintf(tw, "| %s\t %s\t\n", img.Name, orUnknown(img.Digest))
+		}
+		_ = tw.Flush()
+	}
+
+	b.WriteString("\n## Phases\n\n")
+	if len(r.Phases) == 0 {
+		b.WriteString("None\n")
+	} else {
+		tw := tabwriter.NewWriter(&b, 0, 0, 1, ' ', tabwriter.Debug)
+		fmt.Fprintln(tw, "| Phase\t Duration\t Outcome\t")
+		fmt.Fprintln(tw, "|---\t---\t---\t")
+		for _, p := range r.Phases {
+			outcome := p.Outcome
+			if outcome == "" {
+				outcome = "interrupted"
+			}
+			fmt.Fprintf(tw, "| %s\t %s\t %s\t\n", p.Phase, p.Duration, outcome)
+		}
+		_ = tw.Flush()
+	}
+
+	b.WriteString("\n## Values\n\n")
+	if len(r.ValuesKeys) == 0 {
+		b.WriteString("None\n")
+	} else {
+		b.WriteString("The keys of the merged helm values, the values themselves are omitted.\n\n```\n")
+		for _, k := range r.ValuesKeys {
+			b.WriteString(k + "\n")
+		}
+		b.WriteString("```\n")
+	}
+
+	b.WriteString("\n## Warnings\n\n")
+	if len(r.Warnings) == 0 {
+		b.WriteString("None\n")
+	} else {
+		for _, w := range r.Warnings {
+			fmt.Fprintf(&b, "- %s\n", w)
+		}
+	}
+
+	return b.String()
+}
+
+// orUnknown returns s, or "unknown" if it is empty.
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,173 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/pterm/pterm"
+)
+
+// installRetryBackoff is how long to wait before the first retry of a failed install, doubling with every retry.
+var installRetryBackoff = 10 * time.Second
+
+// maxInstallRetryBackoff caps how long to wait between the retries of a failed install.
+const maxInstallRetryBackoff = 2 * time.Minute
+
+// nonRetriableInstallErrors are caused by the provided configuration or the state of the environment,
+// retrying an install which failed with one of them would only fail again.
+var nonRetriableInstallErrors = []error{
+	abctl.ErrAirbyteDir,
+	abctl.ErrBootloaderFailed,
+	abctl.ErrClusterNotOwned,
+	abctl.ErrIngress,
+	abctl.ErrInvalidHostFlag,
+	abctl.ErrIpAddressForHostFlag,
+	abctl.ErrLicenseKeyRequired,
+	abctl.ErrNodeOOMKilled,
+	abctl.ErrOfflineChart,
+	abctl.ErrPort,
+	abctl.ErrPortAbctlCluster,
+	abctl.ErrPreflightStrict,
+	abctl.ErrRepoIndexTLS,
+	abctl.ErrSnapshotIncompatible,
+	abctl.ErrSnapshotTarget,
+	abctl.ErrStorageClass,
+	abctl.ErrTLSSecret,
+	abctl.ErrUpgradeBlocked,
+	abctl.ErrValuesEnvUndefined,
+	abctl.ErrValuesSchema,
+	abctl.ErrVolumeShrink,
+}
+
+// retriableInstallErrors are transient failures communicating with docker or the cluster.
+var retriableInstallErrors = []error{
+	abctl.ErrClusterCreateTimeout,
+	abctl.ErrDocker,
+	abctl.ErrHelmTimeout,
+	abctl.ErrKubernetes,
+	abctl.ErrNodeNotReady,
+}
+
+// nonRetriableMessages identify resource exhaustion, which a retry can't fix. As the kubernetes and docker clients
+// don't return usable error types for these, the error message has to be checked.
+var nonRetriableMessages = []string{
+	"insufficient cpu",
+	"insufficient memory",
+	"no space left on device",
+	"oomkilled",
+}
+
+// retriableMessages identify transient network and image pull failures.
+var retriableMessages = []string{
+	"connection refused",
+	"connection reset",
+	"errimagepull",
+	"i/o timeout",
+	"imagepullbackoff",
+	"no such host",
+	"tls handshake timeout",
+	"toomanyrequests",
+	"unexpected eof",
+}
+
+// retriableInstallError returns true if the install failed with an error which a retry may not encounter,
+// such as a network, transient docker or image pull failure. Unrecognized errors are not retriable, as
+// rolling back and repeating an install which will fail again only delays reporting the failure.
+func retriableInstallError(ctx context.Context, err error) bool {
+	// an interrupted install must not be retried
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	for _, e := range nonRetriableInstallErrors {
+		if errors.Is(err, e) {
+			return false
+		}
+	}
+	var portErr InvalidPortError
+	if errors.As(err, &portErr) {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, m := range nonRetriableMessages {
+		if strings.Contains(msg, m) {
+			return false
+		}
+	}
+
+	for _, e := range retriableInstallErrors {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	// a component which didn't become ready in time is often waiting on a slow image pull
+	var timeoutErr *service.ComponentTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return true
+	}
+
+	for _, m := range retriableMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// installRetryWait returns how long to wait before the retry following the failed attempt (starting at 1).
+func installRetryWait(attempt int) time.Duration {
+	wait := installRetryBackoff
+	for i := 1; i < attempt && wait < maxInstallRetryBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxInstallRetryBackoff)
+}
+
+// retryInstall calls install, retrying it up to maxRetries times for as long as it fails with a retriable error.
+// Before every retry, rollback is called to remove the partial state of the failed attempt.
+func retryInstall(ctx context.Context, maxRetries int, install func() error, rollback func()) error {
+	attempts := maxRetries + 1
+	for attempt := 1; ; attempt++ {
+		if attempts > 1 {
+			pterm.Info.Printfln("Installation attempt %d of %d", attempt, attempts)
+		}
+
+		err := install()
+		if err == nil {
+			return nil
+		}
+		if attempts == 1 {
+			return err
+		}
+		if attempt == attempts {
+			return fmt.Errorf("installation failed after %d attempts: %w", attempts, err)
+		}
+		if !retriableInstallError(ctx, err) {
+			pterm.Warning.Printfln("Installation attempt %d of %d failed with an error which is not retriable", attempt, attempts)
+			return err
+		}
+
+		pterm.Warning.Printfln("Installation attempt %d of %d failed: %s", attempt, attempts, err)
+		rollback()
+
+		wait := installRetryWait(attempt)
+		pterm.Info.Printfln("Retrying the installation in %s", wait)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetriableInstallError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil},
+		{name: "unrecognized", err: errors.New("something unexpected")},
+		{name: "docker", err: fmt.Errorf("%w: unable to list containers", abctl.ErrDocker), want: true},
+		{name: "kubernetes", err: fmt.Errorf("%w: unable to get pod", abctl.ErrKubernetes), want: true},
+		{name: "helm timeout", err: fmt.Errorf("%w: airbyte chart after 1h0m0s: context deadline exceeded", abctl.ErrHelmTimeout), want: true},
+		{name: "cluster create timeout", err: abctl.ErrClusterCreateTimeout, want: true},
+		{name: "network", err: fmt.Errorf("unable to fetch chart: %w", &net.OpError{Op: "dial", Err: errors.New("refused")}), want: true},
+		{name: "connection refused", err: errors.New("dial tcp 127.0.0.1:6443: connect: connection refused"), want: true},
+		{name: "image pull", err: errors.New("pod airbyte-server is waiting: ErrImagePull"), want: true},
+		{name: "registry rate limit", err: errors.New("toomanyrequests: You have reached your pull rate limit"), want: true},
+		{name: "component timeout", err: fmt.Errorf("unable to install airbyte chart: %w", &service.ComponentTimeoutError{Component: "server", Timeout: time.Minute}), want: true},
+		{name: "insufficient memory", err: errors.New("0/1 nodes are available: 1 Insufficient memory"), want: false},
+		{name: "insufficient memory over the network", err: fmt.Errorf("%w: 0/1 nodes are available: 1 Insufficient memory", abctl.ErrKubernetes), want: false},
+		{name: "oom killed", err: errors.New("container airbyte-db-0 terminated: OOMKilled"), want: false},
+		{name: "invalid values", err: fmt.Errorf("%w: additional property foo is not allowed", abctl.ErrValuesSchema), want: false},
+		{name: "undefined values env", err: abctl.ErrValuesEnvUndefined, want: false},
+		{name: "volume shrink", err: abctl.ErrVolumeShrink, want: false},
+		{name: "cluster not owned", err: abctl.ErrClusterNotOwned, want: false},
+		{name: "offline chart", err: fmt.Errorf("failed to resolve chart flags: %w", abctl.ErrOfflineChart), want: false},
+		{name: "invalid port", err: InvalidPortError{Port: "abc", Inner: errors.New("invalid syntax")}, want: false},
+		{name: "canceled", err: fmt.Errorf("unable to pull image: %w", context.Canceled), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, retriableInstallError(context.Background(), tt.err)); d != "" {
+				t.Errorf("retriable mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+
+	t.Run("interrupted", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if retriableInstallError(ctx, abctl.ErrDocker) {
+			t.Error("an interrupted install must not be retriable")
+		}
+	})
+}
+
+func TestInstallRetryWait(t *testing.T) {
+	var got []time.Duration
+	for attempt := 1; attempt <= 6; attempt++ {
+		got = append(got, installRetryWait(attempt))
+	}
+	want := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, 2 * time.Minute, 2 * time.Minute}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("wait mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestRetryInstall(t *testing.T) {
+	origBackoff := installRetryBackoff
+	installRetryBackoff = 0
+	t.Cleanup(func() { installRetryBackoff = origBackoff })
+
+	errTransient := fmt.Errorf("%w: connection reset", abctl.ErrDocker)
+	errPermanent := fmt.Errorf("%w: invalid values", abctl.ErrValuesSchema)
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		// errs are returned by the install attempts in order, any further attempts succeed
+		errs         []error
+		wantErr      error
+		wantAttempts int
+		wantRollback int
+	}{
+		{
+			name:         "success",
+			maxRetries:   3,
+			wantAttempts: 1,
+		},
+		{
+			name:         "failing then succeeding",
+			maxRetries:   3,
+			errs:         []error{errTransient, errTransient},
+			wantAttempts: 3,
+			wantRollback: 2,
+		},
+		{
+			name:         "retries exhausted",
+			maxRetries:   2,
+			errs:         []error{errTransient, errTransient, errTransient, errTransient},
+			wantErr:      errTransient,
+			wantAttempts: 3,
+			wantRollback: 2,
+		},
+		{
+			name:         "non-retriable",
+			maxRetries:   3,
+			errs:         []error{errTransient, errPermanent},
+			wantErr:      errPermanent,
+			wantAttempts: 2,
+			wantRollback: 1,
+		},
+		{
+			name:         "no retries",
+			errs:         []error{errTransient},
+			wantErr:      errTransient,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts, rollbacks int
+			install := func() error {
+				attempts++
+				if attempts <= len(tt.errs) {
+					return tt.errs[attempts-1]
+				}
+				return nil
+			}
+
+			err := retryInstall(context.Background(), tt.maxRetries, install, func() { rollbacks++ })
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("expected error %v but got %v", tt.wantErr, err)
+			}
+			if d := cmp.Diff(tt.wantAttempts, attempts); d != "" {
+				t.Errorf("attempts mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantRollback, rollbacks); d != "" {
+				t.Errorf("rollbacks mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestRetryInstall_Interrupted(t *testing.T) {
+	origBackoff := installRetryBackoff
+	installRetryBackoff = time.Hour
+	t.Cleanup(func() { installRetryBackoff = origBackoff })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	install := func() error {
+		attempts++
+		return errors.New("attempt " + strconv.Itoa(attempts) + ": connection refused")
+	}
+
+	// interrupt the install while it waits to retry
+	err := retryInstall(ctx, 3, install, cancel)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if d := cmp.Diff(1, attempts); d != "" {
+		t.Errorf("attempts mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,103 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/pterm/pterm"
+)
+
+// rollback records the resources created by an install, allowing them to be removed if the install
+// fails or is interrupted.
+type rollback struct {
+	mu    sync.Mutex
+	steps []rollbackStep
+}
+
+type rollbackStep struct {
+	// resource describes what is removed by this step, e.g. "cluster 'airbyte-abctl'"
+	resource string
+	fn       func(ctx context.Context) error
+}
+
+// add records a created resource and the func which removes it.
+func (r *rollback) add(resource string, fn func(ctx context.Context) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.steps = append(r.steps, rollbackStep{resource: resource, fn: fn})
+}
+
+// clear forgets the recorded resources, once an install has succeeded they must never be rolled back.
+func (r *rollback) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.steps = nil
+}
+
+// run removes the recorded resources, in the reverse order of their creation, returning what was removed.
+// Each resource is only ever removed once, subsequent calls will not repeat any previous steps.
+func (r *rollback) run(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	steps := r.steps
+	r.steps = nil
+	r.mu.Unlock()
+
+	var removed []string
+	var errs []error
+	for _, step := range slices.Backward(steps) {
+		pterm.Debug.Printfln("Rolling back %s", step.resource)
+		if err := step.fn(ctx); err != nil {
+			pterm.Warning.Printfln("Unable to remove %s: %s", step.resource, err)
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, step.resource)
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// resources returns the recorded resources which have not been rolled back.
+func (r *rollback) resources() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []string
+	for _, step := range r.steps {
+		out = append(out, step.resource)
+	}
+	return out
+}
+
+// handleInstallFailure is called when an install fails, or is interrupted by its context being cancelled.
+// The resources created by the install are only rolled back if rollBack is true, otherwise they are kept, e.g. so the
+// install can be troubleshot. As the context of an interrupted install is already cancelled, the rollback is run with
+// a context which is not.
+func handleInstallFailure(ctx context.Context, rb *rollback, rollBack bool) {
+	if ctx.Err() != nil {
+		pterm.Warning.Println("Installation was interrupted")
+	}
+
+	if !rollBack {
+		resources := rb.resources()
+		for _, resource := range resources {
+			pterm.Info.Printfln("Keeping %s", resource)
+		}
+		if len(resources) > 0 {
+			pterm.Info.Println("Pass --rollback-on-failure to remove the resources created by a failed installation")
+		}
+		return
+	}
+
+	removed, err := rb.run(context.WithoutCancel(ctx))
+	for _, resource := range removed {
+		pterm.Info.Printfln("Rolled back %s", resource)
+	}
+	if err != nil {
+		pterm.Warning.Println("Some resources could not be rolled back and may need to be removed manually")
+	}
+}
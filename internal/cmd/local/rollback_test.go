@@ -0,0 +1,88 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// recordingRollback returns a rollback with steps for the resources, which record the order they're invoked in.
+func recordingRollback(t *testing.T, invoked *[]string, resources ...string) *rollback {
+	rb := &rollback{}
+	for _, resource := range resources {
+		rb.add(resource, func(ctx context.Context) error {
+			if err := ctx.Err(); err != nil {
+				t.Errorf("rollback of %s invoked with a cancelled context: %s", resource, err)
+			}
+			*invoked = append(*invoked, resource)
+			return nil
+		})
+	}
+	return rb
+}
+
+func TestHandleInstallFailure_Interrupted(t *testing.T) {
+	var invoked []string
+	rb := recordingRollback(t, &invoked, "cluster 'test'", "namespace 'test'")
+
+	// simulate an interrupt mid-install
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	handleInstallFailure(ctx, rb, true)
+	if d := cmp.Diff([]string{"namespace 'test'", "cluster 'test'"}, invoked); d != "" {
+		t.Errorf("rollback mismatch (-want +got):\n%s", d)
+	}
+
+	// a second failure must not roll anything back again
+	handleInstallFailure(ctx, rb, true)
+	if d := cmp.Diff(2, len(invoked)); d != "" {
+		t.Errorf("expected the rollback to only run once (-want +got):\n%s", d)
+	}
+}
+
+func TestHandleInstallFailure_KeepByDefault(t *testing.T) {
+	var invoked []string
+	rb := recordingRollback(t, &invoked, "cluster 'test'")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	handleInstallFailure(ctx, rb, false)
+	if len(invoked) != 0 {
+		t.Errorf("expected no rollback by default but got %v", invoked)
+	}
+	if d := cmp.Diff([]string{"cluster 'test'"}, rb.resources()); d != "" {
+		t.Errorf("resources mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestRollback_Cleared(t *testing.T) {
+	var invoked []string
+	rb := recordingRollback(t, &invoked, "cluster 'test'")
+	rb.clear()
+
+	handleInstallFailure(context.Background(), rb, true)
+	if len(invoked) != 0 {
+		t.Errorf("expected no rollback after clear but got %v", invoked)
+	}
+}
+
+func TestRollback_Error(t *testing.T) {
+	var invoked []string
+	rb := recordingRollback(t, &invoked, "cluster 'test'")
+	rb.add("namespace 'test'", func(ctx context.Context) error {
+		return errors.New("test")
+	})
+
+	removed, err := rb.run(context.Background())
+	if err == nil {
+		t.Error("expected error")
+	}
+	// a failing step must not prevent the remaining steps from running
+	if d := cmp.Diff([]string{"cluster 'test'"}, removed); d != "" {
+		t.Errorf("removed mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,196 @@
+package local
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/pterm/pterm"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// snapshotManifestFile is the file within a snapshot which records the installation the snapshot was taken of.
+	snapshotManifestFile = "snapshot.json"
+	// snapshotDataDir is the directory within a snapshot which holds the contents of the paths.Data directory.
+	snapshotDataDir = "data"
+)
+
+// snapshotManifest records the installation a snapshot was taken of.
+type snapshotManifest struct {
+	// ChartVersion is the version of the airbyte chart which wrote the data of the snapshot.
+	ChartVersion string `json:"chartVersion"`
+}
+
+// errStopWalk stops walkSnapshot early, without returning an error.
+var errStopWalk = errors.New("stop walking the snapshot")
+
+// walkSnapshot calls fn with every entry of the gzipped tar snapshot at path, until fn returns errStopWalk.
+func walkSnapshot(snapshot string, fn func(hdr *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(snapshot)
+	if err != nil {
+		return fmt.Errorf("unable to open snapshot: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("unable to read snapshot '%s': %w", snapshot, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read snapshot '%s': %w", snapshot, err)
+		}
+		if err := fn(hdr, tr); err != nil {
+			if errors.Is(err, errStopWalk) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// readSnapshotManifest returns the manifest of the snapshot at path.
+func readSnapshotManifest(snapshot string) (snapshotManifest, error) {
+	var manifest snapshotManifest
+	found := false
+	err := walkSnapshot(snapshot, func(hdr *tar.Header, r io.Reader) error {
+		if path.Clean(hdr.Name) != snapshotManifestFile {
+			return nil
+		}
+		if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+			return fmt.Errorf("unable to decode the snapshot manifest: %w", err)
+		}
+		found = true
+		return errStopWalk
+	})
+	if err != nil {
+		return snapshotManifest{}, err
+	}
+	if !found {
+		return snapshotManifest{}, fmt.Errorf("snapshot '%s' has no %s manifest", snapshot, snapshotManifestFile)
+	}
+	if manifest.ChartVersion == "" {
+		return snapshotManifest{}, fmt.Errorf("snapshot '%s' does not record its chart version", snapshot)
+	}
+	return manifest, nil
+}
+
+// checkSnapshotVersion returns an ErrSnapshotIncompatible error if the data of a snapshot written by the snapshot
+// chart version can't be restored with the requested chart version. The database can't be downgraded, nor migrated
+// across major chart versions. An empty requested version installs the snapshot chart version, so is compatible.
+func checkSnapshotVersion(snapshot, requested string) error {
+	if requested == "" {
+		return nil
+	}
+
+	s, r := semverOf(snapshot), semverOf(requested)
+	if !semver.IsValid(s) || !semver.IsValid(r) {
+		return fmt.Errorf("%w: unable to compare the snapshot chart version %s with the chart version %s",
+			abctl.ErrSnapshotIncompatible, snapshot, requested)
+	}
+	if semver.Major(s) != semver.Major(r) {
+		return fmt.Errorf("%w: the snapshot chart version %s is a different major version than the chart version %s",
+			abctl.ErrSnapshotIncompatible, snapshot, requested)
+	}
+	if semver.Compare(r, s) < 0 {
+		return fmt.Errorf("%w: the chart version %s is older than the snapshot chart version %s",
+			abctl.ErrSnapshotIncompatible, requested, snapshot)
+	}
+	return nil
+}
+
+// semverOf returns the version with the "v" prefix the semver package requires.
+func semverOf(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// checkSnapshotTarget returns an ErrSnapshotTarget error if the dataDir already holds the data of the database,
+// which restoring a snapshot would overwrite.
+func checkSnapshotTarget(dataDir string) error {
+	entries, err := os.ReadDir(filepath.Join(dataDir, paths.PvPsql))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read the data directory '%s': %w", dataDir, err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%w: the data directory '%s' already holds a database", abctl.ErrSnapshotTarget, dataDir)
+	}
+	return nil
+}
+
+// restoreSnapshot extracts the data directory of the snapshot into the dataDir. Directories which already exist,
+// such as those of the persistent volumes, keep their permissions.
+func restoreSnapshot(snapshot, dataDir string) error {
+	var files int
+	err := walkSnapshot(snapshot, func(hdr *tar.Header, r io.Reader) error {
+		name := path.Clean(hdr.Name)
+		if !filepath.IsLocal(filepath.FromSlash(name)) {
+			return fmt.Errorf("snapshot entry '%s' is outside of the snapshot", hdr.Name)
+		}
+		rel, ok := strings.CutPrefix(name, snapshotDataDir+"/")
+		if !ok {
+			return nil
+		}
+		target := filepath.Join(dataDir, filepath.FromSlash(rel))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, hdr.FileInfo().Mode().Perm()); err != nil {
+				return fmt.Errorf("unable to restore directory '%s': %w", target, err)
+			}
+		case tar.TypeReg:
+			if err := restoreFile(target, hdr.FileInfo().Mode().Perm(), r); err != nil {
+				return err
+			}
+			files++
+		default:
+			pterm.Debug.Printfln("Skipping snapshot entry '%s' of unsupported type %c", hdr.Name, hdr.Typeflag)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	pterm.Debug.Printfln("Restored %d files from snapshot '%s'", files, snapshot)
+	return nil
+}
+
+// restoreFile writes the contents of r to the file at target, creating its parent directories.
+func restoreFile(target string, perm os.FileMode, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("unable to restore directory '%s': %w", filepath.Dir(target), err)
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("unable to restore file '%s': %w", target, err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to restore file '%s': %w", target, err)
+	}
+	return f.Close()
+}
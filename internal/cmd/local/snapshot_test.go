@@ -0,0 +1,213 @@
+package local
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/google/go-cmp/cmp"
+)
+
+// snapshotEntry is a file, or a directory if its body is nil, of a test snapshot.
+type snapshotEntry struct {
+	name string
+	body []byte
+}
+
+// writeSnapshot writes a gzipped tar snapshot of the entries, returning its path.
+func writeSnapshot(t *testing.T, entries ...snapshotEntry) string {
+	t.Helper()
+
+	snapshot := filepath.Join(t.TempDir(), "airbyte-backup.tar.gz")
+	f, err := os.Create(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o600, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.body == nil {
+			hdr.Mode, hdr.Typeflag = 0o700, tar.TypeDir
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(e.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return snapshot
+}
+
+func TestReadSnapshotManifest(t *testing.T) {
+	t.Run("manifest", func(t *testing.T) {
+		snapshot := writeSnapshot(t,
+			snapshotEntry{name: "data/"},
+			snapshotEntry{name: "./snapshot.json", body: []byte(`{"chartVersion":"1.5.1"}`)},
+		)
+
+		manifest, err := readSnapshotManifest(snapshot)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(snapshotManifest{ChartVersion: "1.5.1"}, manifest); d != "" {
+			t.Errorf("manifest mismatch (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("no manifest", func(t *testing.T) {
+		snapshot := writeSnapshot(t, snapshotEntry{name: "data/"})
+		if _, err := readSnapshotManifest(snapshot); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("no chart version", func(t *testing.T) {
+		snapshot := writeSnapshot(t, snapshotEntry{name: "snapshot.json", body: []byte(`{}`)})
+		if _, err := readSnapshotManifest(snapshot); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("not a snapshot", func(t *testing.T) {
+		snapshot := filepath.Join(t.TempDir(), "values.yaml")
+		if err := os.WriteFile(snapshot, []byte("global: {}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readSnapshotManifest(snapshot); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestCheckSnapshotVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshot  string
+		requested string
+		wantErr   bool
+	}{
+		{name: "snapshot version", snapshot: "1.5.1"},
+		{name: "same version", snapshot: "1.5.1", requested: "1.5.1"},
+		{name: "newer version", snapshot: "1.5.1", requested: "1.6.0"},
+		{name: "prefixed version", snapshot: "v1.5.1", requested: "1.5.2"},
+		{name: "older version", snapshot: "1.5.1", requested: "1.5.0", wantErr: true},
+		{name: "different major version", snapshot: "1.5.1", requested: "2.0.0", wantErr: true},
+		{name: "invalid version", snapshot: "1.5.1", requested: "latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSnapshotVersion(tt.snapshot, tt.requested)
+			if d := cmp.Diff(tt.wantErr, errors.Is(err, abctl.ErrSnapshotIncompatible)); d != "" {
+				t.Errorf("incompatible mismatch (-want +got):\n%s\nerror: %v", d, err)
+			}
+		})
+	}
+}
+
+func TestCheckSnapshotTarget(t *testing.T) {
+	t.Run("no data", func(t *testing.T) {
+		if err := checkSnapshotTarget(t.TempDir()); err != nil {
+			t.Error("unexpected error", err)
+		}
+	})
+
+	t.Run("empty volume", func(t *testing.T) {
+		dataDir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dataDir, paths.PvPsql), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := checkSnapshotTarget(dataDir); err != nil {
+			t.Error("unexpected error", err)
+		}
+	})
+
+	t.Run("existing database", func(t *testing.T) {
+		dataDir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dataDir, paths.PvPsql, "pgdata"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := checkSnapshotTarget(dataDir); !errors.Is(err, abctl.ErrSnapshotTarget) {
+			t.Errorf("expected ErrSnapshotTarget but got %v", err)
+		}
+	})
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	t.Run("restores the data", func(t *testing.T) {
+		snapshot := writeSnapshot(t,
+			snapshotEntry{name: "snapshot.json", body: []byte(`{"chartVersion":"1.5.1"}`)},
+			snapshotEntry{name: "data/"},
+			snapshotEntry{name: "data/airbyte-volume-db/"},
+			snapshotEntry{name: "data/airbyte-volume-db/pgdata/PG_VERSION", body: []byte("13\n")},
+			snapshotEntry{name: "data/airbyte-minio-pv/bucket/object", body: []byte("minio")},
+		)
+
+		// the persistent volume directories are created before the snapshot is restored
+		dataDir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dataDir, paths.PvPsql), 0o777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(filepath.Join(dataDir, paths.PvPsql), 0o777); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := restoreSnapshot(snapshot, dataDir); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		for file, want := range map[string]string{
+			filepath.Join(paths.PvPsql, "pgdata", "PG_VERSION"): "13\n",
+			filepath.Join(paths.PvMinio, "bucket", "object"):    "minio",
+		} {
+			got, err := os.ReadFile(filepath.Join(dataDir, file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := cmp.Diff(want, string(got)); d != "" {
+				t.Errorf("%s mismatch (-want +got):\n%s", file, d)
+			}
+		}
+
+		// the manifest is not part of the data
+		if _, err := os.Stat(filepath.Join(dataDir, "snapshot.json")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected the manifest to not be restored, got %v", err)
+		}
+
+		// the existing volume directory keeps its permissions
+		info, err := os.Stat(filepath.Join(dataDir, paths.PvPsql))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := cmp.Diff(os.FileMode(0o777), info.Mode().Perm()); d != "" {
+			t.Errorf("permissions mismatch (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("entry outside of the data directory", func(t *testing.T) {
+		snapshot := writeSnapshot(t, snapshotEntry{name: "data/../../escaped", body: []byte("oops")})
+
+		dataDir := filepath.Join(t.TempDir(), "data")
+		if err := restoreSnapshot(snapshot, dataDir); err == nil {
+			t.Error("expected an error")
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dataDir), "escaped")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("expected the entry to not be restored, got %v", err)
+		}
+	})
+}
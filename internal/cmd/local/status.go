@@ -2,36 +2,104 @@ package local
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/output"
 	"github.com/airbytehq/abctl/internal/service"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/airbytehq/abctl/internal/trace"
 	"github.com/pterm/pterm"
 )
 
-type StatusCmd struct{}
+type StatusCmd struct {
+	Component    string        `help:"Show the full detail of a single component (e.g. worker): its pods, container states, recent events and logs. Exits non-zero unless the component is ready."`
+	Interval     time.Duration `default:"5s" help:"How often the component status is refreshed when watching."`
+	LogLines     int           `default:"20" help:"With --component, how many of the most recent log lines of each pod are shown."`
+	OutputFormat output.Format `default:"table" enum:"table,yaml,json" help:"Output format of the status (table, yaml or json). The yaml and json output details every component, exiting non-zero unless all of them are ready."`
+	UntilReady   bool          `help:"Stop watching, and exit successfully, once every component is ready. Implies --watch."`
+	Watch        bool          `help:"Continuously show the status of the Airbyte components."`
+}
 
 func (s *StatusCmd) Run(ctx context.Context, provider k8s.Provider, telClient telemetry.Client) error {
 	ctx, span := trace.NewSpan(ctx, "local status")
 	defer span.End()
 
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	// only the status report may be written to stdout, send everything else to stderr
+	if s.OutputFormat.MachineReadable() {
+		pterm.SetDefaultOutput(os.Stderr)
+		defer pterm.SetDefaultOutput(os.Stdout)
+	}
+
 	spinner := &pterm.DefaultSpinner
 	if err := checkDocker(ctx, telClient, spinner); err != nil {
 		return err
 	}
 
 	return telClient.Wrap(ctx, telemetry.Status, func() error {
-		return status(ctx, provider, telClient, spinner)
+		if err := status(ctx, provider, telClient, spinner); err != nil {
+			return err
+		}
+		if s.Component != "" {
+			k8sClient, err := service.DefaultK8s(provider.Kubeconfig, provider.Context)
+			if err != nil {
+				return err
+			}
+			return writeComponentDetail(ctx, k8sClient, os.Stdout, s.Component, s.LogLines)
+		}
+		if s.OutputFormat.MachineReadable() {
+			k8sClient, err := service.DefaultK8s(provider.Kubeconfig, provider.Context)
+			if err != nil {
+				return err
+			}
+			return writeStatusReport(ctx, k8sClient, os.Stdout, s.OutputFormat)
+		}
+		if !s.Watch && !s.UntilReady {
+			return nil
+		}
+
+		k8sClient, err := service.DefaultK8s(provider.Kubeconfig, provider.Context)
+		if err != nil {
+			return err
+		}
+		return watchStatus(ctx, k8sClient, os.Stdout, s.Interval, s.UntilReady, isTerminal(os.Stdout))
 	})
 }
 
+// validate returns an error if the flags are invalid, or are an unsupported combination.
+func (s *StatusCmd) validate() error {
+	if s.Interval <= 0 {
+		return fmt.Errorf("invalid interval %s: must be greater than zero", s.Interval)
+	}
+	if s.OutputFormat.MachineReadable() && (s.Watch || s.UntilReady) {
+		return fmt.Errorf("--output-format %s cannot be combined with --watch or --until-ready", s.OutputFormat)
+	}
+	if s.Component != "" && (s.OutputFormat.MachineReadable() || s.Watch || s.UntilReady) {
+		return errors.New("--component cannot be combined with an --output-format of yaml or json, --watch or --until-ready")
+	}
+	if s.LogLines < 0 {
+		return fmt.Errorf("invalid log lines %d: must not be negative", s.LogLines)
+	}
+	return nil
+}
+
 func checkDocker(ctx context.Context, telClient telemetry.Client, spinner *pterm.SpinnerPrinter) error {
 	spinner, _ = spinner.Start("Starting status check")
 	spinner.UpdateText("Checking for Docker installation")
 
-	_, err := dockerInstalled(ctx, telClient)
+	_, err := dockerInstalled(ctx, telClient, &preflightWarnings{})
 	if err != nil {
 		pterm.Error.Println("Unable to determine if Docker is installed")
 		return fmt.Errorf("unable to determine docker installation status: %w", err)
@@ -43,15 +111,12 @@ func checkDocker(ctx context.Context, telClient telemetry.Client, spinner *pterm
 func status(ctx context.Context, provider k8s.Provider, telClient telemetry.Client, spinner *pterm.SpinnerPrinter) error {
 	spinner.UpdateText(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
 
-	cluster, err := provider.Cluster(ctx)
-	if err != nil {
-		pterm.Error.Printfln("Unable to determine status of any existing '%s' cluster", provider.ClusterName)
-		return err
-	}
-
-	if !cluster.Exists(ctx) {
+	if _, err := provider.GetCluster(ctx); errors.Is(err, abctl.ErrClusterNotFound) {
 		pterm.Warning.Println("Airbyte does not appear to be installed locally")
 		return nil
+	} else if err != nil {
+		pterm.Error.Printfln("Unable to determine status of any existing '%s' cluster", provider.ClusterName)
+		return err
 	}
 
 	pterm.Success.Printfln("Existing cluster '%s' found", provider.ClusterName)
@@ -62,6 +127,8 @@ func status(ctx context.Context, provider k8s.Provider, telClient telemetry.Clie
 		return err
 	}
 
+	reportNetwork(ctx, fmt.Sprintf("%s-control-plane", provider.ClusterName))
+
 	svcMgr, err := service.NewManager(provider,
 		service.WithPortHTTP(port),
 		service.WithTelemetryClient(telClient),
@@ -72,7 +139,7 @@ func status(ctx context.Context, provider k8s.Provider, telClient telemetry.Clie
 		return fmt.Errorf("unable to initialize local command: %w", err)
 	}
 
-	if err := svcMgr.Status(ctx); err != nil {
+	if err := svcMgr.PrintStatus(ctx); err != nil {
 		spinner.Fail("Unable to install Airbyte locally")
 		return err
 	}
@@ -81,3 +148,310 @@ func status(ctx context.Context, provider k8s.Provider, telClient telemetry.Clie
 
 	return nil
 }
+
+// reportNetwork prints the subnets and gateways of the docker networks the container is attached to, warning if any
+// overlap a range commonly routed by VPNs. The diagnosis is informational, so failures are only logged at debug.
+func reportNetwork(ctx context.Context, container string) {
+	if dockerClient == nil {
+		return
+	}
+
+	diagnoses, err := dockerClient.DiagnoseNetwork(ctx, container)
+	if err != nil {
+		pterm.Debug.Printfln("Unable to diagnose the docker network: %s", err)
+		return
+	}
+
+	for _, diag := range diagnoses {
+		subnets := make([]string, len(diag.Subnets))
+		for i, subnet := range diag.Subnets {
+			subnets[i] = subnet.String()
+		}
+		gateways := make([]string, len(diag.Gateways))
+		for i, gateway := range diag.Gateways {
+			gateways[i] = gateway.String()
+		}
+		pterm.Info.Printfln("Docker network '%s' subnet %s, gateway %s", diag.Name, strings.Join(subnets, ", "), strings.Join(gateways, ", "))
+
+		for _, r := range diag.Overlaps {
+			pterm.Warning.Printfln("Docker network '%s' overlaps %s, commonly routed by %s.\n"+
+				"While such a VPN is connected Airbyte may be unreachable. To use a different subnet, uninstall Airbyte,\n"+
+				"recreate the network, e.g. 'docker network rm %s && docker network create %s --subnet 192.168.240.0/24',\n"+
+				"and install Airbyte again.",
+				diag.Name, r.Prefix, r.Name, diag.Name, diag.Name)
+		}
+	}
+}
+
+// isTerminal returns true if the file is a terminal.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// watchStatus polls the status of the components every interval until the ctx is done.
+// If redraw is true the component table is redrawn in place on stdout, otherwise a line is written to w for every poll.
+// If untilReady is true, watching stops once every component is ready.
+func watchStatus(ctx context.Context, k8sClient k8s.Client, w io.Writer, interval time.Duration, untilReady, redraw bool) error {
+	var area *pterm.AreaPrinter
+	if redraw {
+		area, _ = pterm.DefaultArea.Start()
+		defer func() {
+			_ = area.Stop()
+		}()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev []service.ComponentStatus
+	for {
+		cur, err := service.Statuses(ctx, k8sClient, airbyteNamespace)
+		if err != nil {
+			pterm.Debug.Println(err)
+		} else {
+			now := time.Now()
+
+			// the first poll has nothing to compare against
+			var changes []service.ComponentChange
+			if prev != nil {
+				changes = service.DiffComponentStatuses(prev, cur)
+			}
+			prev = cur
+
+			if redraw {
+				area.Update(renderStatusTable(now, interval, cur, changes))
+			} else if _, err := fmt.Fprintln(w, renderStatusLine(now, cur, changes)); err != nil {
+				return fmt.Errorf("unable to write status: %w", err)
+			}
+
+			if untilReady && allComponentsReady(cur) {
+				pterm.Success.Println("All components are ready")
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// errComponentsNotReady is returned by the yaml and json status when not every component is ready.
+var errComponentsNotReady = errors.New("not every component is ready")
+
+// statusReport is the yaml and json output of the status command.
+type statusReport struct {
+	// AllReady is true if there is at least one component and all of them are ready.
+	AllReady bool `json:"allReady"`
+	// Components are sorted by their name.
+	Components []componentReport `json:"components"`
+}
+
+// componentReport is the yaml and json status of a single component.
+type componentReport struct {
+	Name string `json:"name"`
+	// Kind is one of database, storage or service.
+	Kind string `json:"kind"`
+	// Desired is the number of pods of the component.
+	Desired   int    `json:"desired"`
+	Ready     int    `json:"ready"`
+	Restarts  int32  `json:"restarts"`
+	LastState string `json:"lastState"`
+	Healthy   bool   `json:"healthy"`
+}
+
+// newStatusReport returns the report of the statuses, sorted by component name.
+func newStatusReport(statuses []service.ComponentStatus) statusReport {
+	report := statusReport{
+		AllReady:   allComponentsReady(statuses),
+		Components: make([]componentReport, 0, len(statuses)),
+	}
+	for _, status := range statuses {
+		report.Components = append(report.Components, componentReport{
+			Name:      status.Component,
+			Kind:      string(status.Kind()),
+			Desired:   status.Pods,
+			Ready:     status.Ready,
+			Restarts:  status.Restarts,
+			LastState: status.LastState,
+			Healthy:   status.IsReady(),
+		})
+	}
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Name < report.Components[j].Name
+	})
+	return report
+}
+
+// writeStatusReport writes the status of every component to w in the format, returning an errComponentsNotReady
+// error if not every component is ready.
+func writeStatusReport(ctx context.Context, k8sClient k8s.Client, w io.Writer, format output.Format) error {
+	statuses, err := service.Statuses(ctx, k8sClient, airbyteNamespace)
+	if err != nil {
+		return err
+	}
+
+	report := newStatusReport(statuses)
+	if err := output.Render(w, format, report); err != nil {
+		return fmt.Errorf("unable to write status: %w", err)
+	}
+
+	if !report.AllReady {
+		ready := 0
+		for _, c := range report.Components {
+			if c.Healthy {
+				ready++
+			}
+		}
+		return fmt.Errorf("%w: %d of %d components ready", errComponentsNotReady, ready, len(report.Components))
+	}
+	return nil
+}
+
+// writeComponentDetail writes the detail of the component to w, returning an error if the component isn't ready.
+func writeComponentDetail(ctx context.Context, k8sClient k8s.Client, w io.Writer, component string, logLines int) error {
+	detail, err := service.ComponentDetails(ctx, k8sClient, airbyteNamespace, component, logLines)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, renderComponentDetail(detail)); err != nil {
+		return fmt.Errorf("unable to write status: %w", err)
+	}
+
+	if !detail.Status.IsReady() {
+		return fmt.Errorf("component '%s' is not ready: %d of %d pods ready", component, detail.Status.Ready, detail.Status.Pods)
+	}
+	return nil
+}
+
+// renderComponentDetail returns the detail of the component, with each pod's containers, events and logs.
+// Log lines are highlighted by their level.
+func renderComponentDetail(detail service.ComponentDetail) string {
+	var b strings.Builder
+	status := detail.Status
+	fmt.Fprintf(&b, "Component: %s\nReady: %d/%d\nRestarts: %d\n", status.Component, status.Ready, status.Pods, status.Restarts)
+
+	for _, pod := range detail.Pods {
+		fmt.Fprintf(&b, "\nPod: %s (%s, ready: %t)\n", pod.Name, pod.Phase, pod.Ready)
+
+		tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  CONTAINER\tREADY\tRESTARTS\tSTATE\tLAST TERMINATION")
+		for _, c := range pod.Containers {
+			name := c.Name
+			if c.Init {
+				name += " (init)"
+			}
+			lastTermination := c.LastTermination
+			if lastTermination == "" {
+				lastTermination = "-"
+			}
+			fmt.Fprintf(tw, "  %s\t%t\t%d\t%s\t%s\n", name, c.Ready, c.Restarts, c.State, lastTermination)
+		}
+		_ = tw.Flush()
+
+		if len(pod.Events) > 0 {
+			b.WriteString("  Events:\n")
+			for _, e := range pod.Events {
+				fmt.Fprintf(&b, "    %s\n", e)
+			}
+		}
+
+		if len(pod.Logs) > 0 {
+			fmt.Fprintf(&b, "  Last %d log lines:\n", len(pod.Logs))
+			for _, l := range pod.Logs {
+				fmt.Fprintf(&b, "    %s\n", renderLogLine(l))
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// renderLogLine returns the log line, highlighted by its level.
+// Lines without a level, such as stack traces, are returned as is.
+func renderLogLine(l service.LogLine) string {
+	if l.Level == "" {
+		return l.Message
+	}
+
+	line := fmt.Sprintf("%s %s %s", l.Time.UTC().Format(logTimeFormat), l.Level, l.Message)
+	switch l.Level {
+	case "ERROR":
+		return pterm.LightRed(line)
+	case "WARN":
+		return pterm.LightYellow(line)
+	default:
+		return line
+	}
+}
+
+// allComponentsReady returns true if there is at least one component and all of them are ready.
+func allComponentsReady(statuses []service.ComponentStatus) bool {
+	if len(statuses) == 0 {
+		return false
+	}
+	for _, status := range statuses {
+		if !status.IsReady() {
+			return false
+		}
+	}
+	return true
+}
+
+// renderStatusTable returns the component table, with the changes since the previous poll highlighted.
+func renderStatusTable(now time.Time, interval time.Duration, statuses []service.ComponentStatus, changes []service.ComponentChange) string {
+	changed := map[string][]string{}
+	for _, c := range changes {
+		if c.Kind == service.ComponentRestarted {
+			changed[c.Component] = append(changed[c.Component], pterm.LightRed(fmt.Sprintf("restarted (+%d)", c.Restarts)))
+			continue
+		}
+		changed[c.Component] = append(changed[c.Component], pterm.LightYellow(string(c.Kind)))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Updated %s, refreshing every %s (ctrl+c to exit)\n\n", now.Format(time.TimeOnly), interval)
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMPONENT\tREADY\tRESTARTS\t")
+	for _, status := range statuses {
+		// the changes are the last column, as their colors would otherwise break the alignment
+		fmt.Fprintf(tw, "%s\t%d/%d\t%d\t%s\n", status.Component, status.Ready, status.Pods, status.Restarts, strings.Join(changed[status.Component], ", "))
+	}
+	_ = tw.Flush()
+
+	for _, c := range changes {
+		if c.Kind == service.ComponentRemoved {
+			fmt.Fprintf(&b, "%s\n", pterm.LightYellow(c.String()))
+		}
+	}
+
+	return b.String()
+}
+
+// renderStatusLine returns a single line summary of the component statuses and the changes since the previous poll.
+func renderStatusLine(now time.Time, statuses []service.ComponentStatus, changes []service.ComponentChange) string {
+	ready := 0
+	for _, status := range statuses {
+		if status.IsReady() {
+			ready++
+		}
+	}
+
+	line := fmt.Sprintf("%s %d/%d components ready", now.Format(time.RFC3339), ready, len(statuses))
+	if len(changes) > 0 {
+		s := make([]string, len(changes))
+		for i, c := range changes {
+			s[i] = c.String()
+		}
+		line += ": " + strings.Join(s, ", ")
+	}
+	return line
+}
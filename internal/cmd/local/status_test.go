@@ -0,0 +1,360 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/airbytehq/abctl/internal/output"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRenderStatusLine(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	statuses := []service.ComponentStatus{
+		{Component: "server", Ready: 1, Pods: 1},
+		{Component: "worker", Pods: 1, Restarts: 2},
+	}
+
+	tests := []struct {
+		name    string
+		changes []service.ComponentChange
+		want    string
+	}{
+		{
+			name: "no changes",
+			want: "2024-06-01T12:00:00Z 1/2 components ready",
+		},
+		{
+			name: "changes",
+			changes: []service.ComponentChange{
+				{Component: "server", Kind: service.ComponentReady},
+				{Component: "worker", Kind: service.ComponentRestarted, Restarts: 1},
+			},
+			want: "2024-06-01T12:00:00Z 1/2 components ready: server ready, worker restarted (+1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, renderStatusLine(now, statuses, tt.changes)); d != "" {
+				t.Errorf("line mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestWatchStatus_UntilReady(t *testing.T) {
+	pod := func(ready corev1.ConditionStatus) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "airbyte-abctl-server-5d8f7b9c4-x2x7z",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "airbyte-abctl-server-5d8f7b9c4"}},
+			},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}},
+		}
+	}
+
+	polls := 0
+	k8sClient := &k8stest.MockClient{
+		FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+			polls++
+			if polls < 3 {
+				return &corev1.PodList{Items: []corev1.Pod{pod(corev1.ConditionFalse)}}, nil
+			}
+			return &corev1.PodList{Items: []corev1.Pod{pod(corev1.ConditionTrue)}}, nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var b bytes.Buffer
+	if err := watchStatus(ctx, k8sClient, &b, time.Millisecond, true, false); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("watch did not stop once ready")
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if d := cmp.Diff(3, len(lines)); d != "" {
+		t.Fatalf("lines mismatch (-want +got):\n%s", d)
+	}
+	if !strings.HasSuffix(lines[2], "1/1 components ready: server ready") {
+		t.Errorf("unexpected last line: %s", lines[2])
+	}
+}
+
+func TestWriteStatusJSON(t *testing.T) {
+	pod := func(name, ownerKind, owner string, ready bool, containers ...corev1.ContainerStatus) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       airbyteNamespace,
+				OwnerReferences: []metav1.OwnerReference{{Kind: ownerKind, Name: owner}},
+			},
+			Status: corev1.PodStatus{
+				Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+				ContainerStatuses: containers,
+			},
+		}
+	}
+	crashing := corev1.ContainerStatus{
+		State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		RestartCount: 3,
+	}
+
+	tests := []struct {
+		name    string
+		pods    []*corev1.Pod
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "all ready",
+			// created out of order, the components are sorted by name
+			pods: []*corev1.Pod{
+				pod("airbyte-abctl-worker-7c9f8d6b5-a1b2c", "ReplicaSet", "airbyte-abctl-worker-7c9f8d6b5", true),
+				pod("airbyte-db-0", "StatefulSet", "airbyte-db", true),
+				pod("airbyte-abctl-server-5d8f7b9c4-x2x7z", "ReplicaSet", "airbyte-abctl-server-5d8f7b9c4", true),
+			},
+			want: `{
+  "allReady": true,
+  "components": [
+    {
+      "name": "db",
+      "kind": "database",
+      "desired": 1,
+      "ready": 1,
+      "restarts": 0,
+      "lastState": "",
+      "healthy": true
+    },
+    {
+      "name": "server",
+      "kind": "service",
+      "desired": 1,
+      "ready": 1,
+      "restarts": 0,
+      "lastState": "",
+      "healthy": true
+    },
+    {
+      "name": "worker",
+      "kind": "service",
+      "desired": 1,
+      "ready": 1,
+      "restarts": 0,
+      "lastState": "",
+      "healthy": true
+    }
+  ]
+}
+`,
+		},
+		{
+			name: "not ready",
+			pods: []*corev1.Pod{
+				pod("airbyte-abctl-server-5d8f7b9c4-x2x7z", "ReplicaSet", "airbyte-abctl-server-5d8f7b9c4", false, crashing),
+				pod("airbyte-db-0", "StatefulSet", "airbyte-db", true),
+				// job pods aren't components
+				pod("airbyte-abctl-airbyte-bootloader", "Job", "airbyte-abctl-airbyte-bootloader", false),
+			},
+			want: `{
+  "allReady": false,
+  "components": [
+    {
+      "name": "db",
+      "kind": "database",
+      "desired": 1,
+      "ready": 1,
+      "restarts": 0,
+      "lastState": "",
+      "healthy": true
+    },
+    {
+      "name": "server",
+      "kind": "service",
+      "desired": 1,
+      "ready": 0,
+      "restarts": 3,
+      "lastState": "CrashLoopBackOff",
+      "healthy": false
+    }
+  ]
+}
+`,
+			wantErr: true,
+		},
+		{
+			name: "no components",
+			want: `{
+  "allReady": false,
+  "components": []
+}
+`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset()
+			for _, p := range tt.pods {
+				if err := cs.Tracker().Add(p); err != nil {
+					t.Fatal("unable to add pod", err)
+				}
+			}
+
+			var b bytes.Buffer
+			err := writeStatusReport(context.Background(), &k8s.DefaultK8sClient{ClientSet: cs}, &b, output.JSON)
+			if tt.wantErr != errors.Is(err, errComponentsNotReady) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, b.String()); d != "" {
+				t.Errorf("json mismatch (-want +got):\n%s", d)
+			}
+
+			// the output must remain valid json for the scripts consuming it
+			var report statusReport
+			if err := json.Unmarshal(b.Bytes(), &report); err != nil {
+				t.Error("invalid json", err)
+			}
+		})
+	}
+}
+
+func TestStatusCmd_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     StatusCmd
+		wantErr bool
+	}{
+		{name: "table", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.Table, Watch: true}},
+		{name: "json", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.JSON}},
+		{name: "json watch", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.JSON, Watch: true}, wantErr: true},
+		{name: "json until ready", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.JSON, UntilReady: true}, wantErr: true},
+		{name: "invalid interval", cmd: StatusCmd{OutputFormat: output.Table}, wantErr: true},
+		{name: "component", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.Table, Component: "worker", LogLines: 20}},
+		{name: "component json", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.JSON, Component: "worker"}, wantErr: true},
+		{name: "component watch", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.Table, Component: "worker", Watch: true}, wantErr: true},
+		{name: "negative log lines", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.Table, Component: "worker", LogLines: -1}, wantErr: true},
+		{name: "yaml", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.YAML}},
+		{name: "yaml watch", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.YAML, Watch: true}, wantErr: true},
+		{name: "component yaml", cmd: StatusCmd{Interval: time.Second, OutputFormat: output.YAML, Component: "worker"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cmd.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRenderComponentDetail(t *testing.T) {
+	pterm.DisableColor()
+	t.Cleanup(pterm.EnableColor)
+
+	detail := service.ComponentDetail{
+		Status: service.ComponentStatus{Component: "worker", Ready: 0, Pods: 1, Restarts: 3},
+		Pods: []service.PodDetail{{
+			Name:  "airbyte-abctl-worker-123-abc",
+			Phase: corev1.PodRunning,
+			Containers: []service.ContainerDetail{
+				{Name: "wait-for-db", Init: true, Ready: true, State: "terminated: Completed (exit code 0)"},
+				{Name: "worker", Restarts: 3, State: "waiting: CrashLoopBackOff", LastTermination: "OOMKilled (exit code 137)"},
+			},
+			Events: []string{"Warning BackOff: Back-off restarting failed container"},
+			Logs: []service.LogLine{
+				{Time: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), Level: "ERROR", Message: "Unable to connect to the database."},
+				{Message: "at io.airbyte.workers.Application.main"},
+			},
+		}},
+	}
+
+	want := `Component: worker
+Ready: 0/1
+Restarts: 3
+
+Pod: airbyte-abctl-worker-123-abc (Running, ready: false)
+  CONTAINER           READY  RESTARTS  STATE                                LAST TERMINATION
+  wait-for-db (init)  true   0         terminated: Completed (exit code 0)  -
+  worker              false  3         waiting: CrashLoopBackOff            OOMKilled (exit code 137)
+  Events:
+    Warning BackOff: Back-off restarting failed container
+  Last 2 log lines:
+    2024-06-01T12:00:00.000Z ERROR Unable to connect to the database.
+    at io.airbyte.workers.Application.main
+`
+	if d := cmp.Diff(want, renderComponentDetail(detail)); d != "" {
+		t.Errorf("detail mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestWriteComponentDetail(t *testing.T) {
+	pod := func(name string, ready corev1.ConditionStatus) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       airbyteNamespace,
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "airbyte-abctl-worker-5d8f7b9c4"}},
+			},
+			Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		pods    []*corev1.Pod
+		wantErr string
+	}{
+		{name: "ready", pods: []*corev1.Pod{pod("airbyte-abctl-worker-5d8f7b9c4-a", corev1.ConditionTrue)}},
+		{
+			name:    "not ready",
+			pods:    []*corev1.Pod{pod("airbyte-abctl-worker-5d8f7b9c4-a", corev1.ConditionTrue), pod("airbyte-abctl-worker-5d8f7b9c4-b", corev1.ConditionFalse)},
+			wantErr: "component 'worker' is not ready: 1 of 2 pods ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset()
+			for _, p := range tt.pods {
+				if _, err := cs.CoreV1().Pods(airbyteNamespace).Create(context.Background(), p, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var out bytes.Buffer
+			err := writeComponentDetail(context.Background(), &k8s.DefaultK8sClient{ClientSet: cs}, &out, "worker", 5)
+			if d := cmp.Diff(tt.wantErr, fmt.Sprint(err)); tt.wantErr != "" && d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !strings.HasPrefix(out.String(), "Component: worker\n") {
+				t.Errorf("expected the detail to be written, got:\n%s", out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package local
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+)
+
+// warningRecorder is an io.Writer for the pterm.Warning printer, which records every warning printed through it
+// before passing it on to the next writer.
+type warningRecorder struct {
+	next io.Writer
+
+	mu       sync.Mutex
+	recorded []string
+}
+
+func newWarningRecorder(next io.Writer) *warningRecorder {
+	return &warningRecorder{next: next}
+}
+
+func (w *warningRecorder) Write(p []byte) (int, error) {
+	if warning := plainWarning(string(p)); warning != "" {
+		w.mu.Lock()
+		w.recorded = append(w.recorded, warning)
+		w.mu.Unlock()
+	}
+	return w.next.Write(p)
+}
+
+// warnings returns the recorded warnings, in the order they were printed.
+func (w *warningRecorder) warnings() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.recorded...)
+}
+
+// plainWarning returns the warning printed by the pterm.Warning printer without its colors or prefix,
+// and with any continuation lines joined onto a single line.
+func plainWarning(s string) string {
+	s = strings.TrimSpace(pterm.RemoveColorFromString(s))
+	s = strings.TrimPrefix(s, strings.TrimSpace(pterm.Warning.Prefix.Text))
+	// raw output separates the prefix with a colon
+	s = strings.TrimSpace(strings.TrimPrefix(s, ":"))
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// warningWriter returns the writer the pterm.Warning printer currently writes to.
+func warningWriter() io.Writer {
+	if pterm.Warning.Writer != nil {
+		return pterm.Warning.Writer
+	}
+	return os.Stdout
+}
+
+// recordWarnings records any warnings to the recorder, which is expected to pass them on to the warningWriter.
+// The returned func restores the original output.
+func recordWarnings(rec *warningRecorder) (restore func()) {
+	warning := pterm.Warning
+	pterm.Warning = *pterm.Warning.WithWriter(rec)
+	return func() {
+		pterm.Warning = warning
+	}
+}
+
+// summaryOutput silences the intermediate progress output of the install, recording any warnings to the recorder.
+// Errors are still printed. The returned func restores the original output.
+func summaryOutput(rec *warningRecorder) (restore func()) {
+	info, success, warning, spinner := pterm.Info, pterm.Success, pterm.Warning, pterm.DefaultSpinner
+
+	pterm.Info = *pterm.Info.WithWriter(io.Discard)
+	pterm.Success = *pterm.Success.WithWriter(io.Discard)
+	pterm.Warning = *pterm.Warning.WithWriter(rec)
+	pterm.DefaultSpinner = *pterm.DefaultSpinner.WithWriter(io.Discard)
+
+	return func() {
+		pterm.Info, pterm.Success, pterm.Warning, pterm.DefaultSpinner = info, success, warning, spinner
+	}
+}
+
+// installSummary is the concise report printed at the end of an install run with --summary-only, or with an
+// --output-format other than table.
+type installSummary struct {
+	URL          string `json:"url"`
+	Cluster      string `json:"cluster,omitempty"`
+	Context      string `json:"context,omitempty"`
+	Kubeconfig   string `json:"kubeconfig,omitempty"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chartVersion,omitempty"`
+	StorageClass string `json:"storageClass,omitempty"`
+	// LicenseKey is the Airbyte Enterprise license key, only ever shown redacted.
+	LicenseKey string   `json:"licenseKey,omitempty" output:"secret"`
+	Warnings   []string `json:"warnings"`
+}
+
+// summaryStorageClass returns the storage class of the persistent volume claims, as shown by the summary.
+func summaryStorageClass(provider k8s.Provider, storageClass string) string {
+	switch {
+	case storageClass != "":
+		return storageClass
+	case provider.RequiresDocker():
+		return k8s.DefaultStorageClass
+	default:
+		return "cluster default"
+	}
+}
+
+// WriteTable writes the summary to w in the human readable table format.
+func (s installSummary) WriteTable(w io.Writer) error {
+	chart := s.Chart
+	if s.ChartVersion != "" {
+		chart = fmt.Sprintf("%s (version %s)", s.Chart, s.ChartVersion)
+	}
+
+	rows := []struct{ name, value string }{
+		{"URL", s.URL},
+		{"Credentials", "run 'abctl local credentials'"},
+		{"Cluster", s.Cluster},
+		{"Context", s.Context},
+		{"Kubeconfig", s.Kubeconfig},
+		{"Chart", chart},
+		{"Storage", s.StorageClass},
+		{"License key", s.LicenseKey},
+	}
+
+	var b strings.Builder
+	b.WriteString("Airbyte installation complete\n")
+	for _, r := range rows {
+		if r.value != "" {
+			fmt.Fprintf(&b, "  %-12s %s\n", r.name+":", r.value)
+		}
+	}
+	if len(s.Warnings) == 0 {
+		b.WriteString("  Warnings:    none\n")
+	} else {
+		fmt.Fprintf(&b, "  Warnings:    %d\n", len(s.Warnings))
+		for _, warning := range s.Warnings {
+			fmt.Fprintf(&b, "    - %s\n", warning)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
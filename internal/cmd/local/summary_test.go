@@ -0,0 +1,214 @@
+package local
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/output"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+)
+
+func TestPlainWarning(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "plain",
+			input: "disk space is low\n",
+			want:  "disk space is low",
+		},
+		{
+			name:  "prefixed",
+			input: pterm.Warning.Sprintln("disk space is low"),
+			want:  "disk space is low",
+		},
+		{
+			name:  "multiline",
+			input: pterm.Warning.Sprintln("disk space is low\nconsider freeing some"),
+			want:  "disk space is low consider freeing some",
+		},
+		{
+			name:  "empty",
+			input: "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, plainWarning(tt.input)); d != "" {
+				t.Errorf("warning mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestSummaryOutput(t *testing.T) {
+	var out bytes.Buffer
+	rec := newWarningRecorder(&out)
+
+	restore := summaryOutput(rec)
+	pterm.Info.Println("Pulling images")
+	pterm.Warning.Println("Only 2GB of memory is available")
+	pterm.Success.Println("Images pulled")
+	pterm.Warning.Printfln("The %s platform is emulated", "linux/amd64")
+	restore()
+
+	want := []string{"Only 2GB of memory is available", "The linux/amd64 platform is emulated"}
+	if d := cmp.Diff(want, rec.warnings()); d != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", d)
+	}
+
+	// only the warnings are passed on to the recorder's writer
+	if got := pterm.RemoveColorFromString(out.String()); bytes.Contains([]byte(got), []byte("Pulling images")) {
+		t.Errorf("unexpected info output: %s", got)
+	}
+
+	// the output is restored
+	if pterm.Warning.Writer == rec {
+		t.Error("expected the warning printer to be restored")
+	}
+}
+
+func TestInstallSummary_Print(t *testing.T) {
+	rec := newWarningRecorder(io.Discard)
+	restore := summaryOutput(rec)
+	// a warning emitted mid-run, long before the summary is printed
+	pterm.Warning.Println("Only 2GB of memory is available")
+	pterm.Info.Println("Installing airbyte")
+	restore()
+
+	var out bytes.Buffer
+	installSummary{
+		URL:          "http://localhost:8000",
+		Cluster:      "airbyte-abctl",
+		Context:      "kind-airbyte-abctl",
+		Kubeconfig:   "/home/test/.airbyte/abctl/abctl.kubeconfig",
+		Chart:        "airbyte/airbyte",
+		ChartVersion: "1.7.0",
+		StorageClass: "standard",
+		Warnings:     rec.warnings(),
+	}.WriteTable(&out)
+
+	want := `Airbyte installation complete
+  URL:         http://localhost:8000
+  Credentials: run 'abctl local credentials'
+  Cluster:     airbyte-abctl
+  Context:     kind-airbyte-abctl
+  Kubeconfig:  /home/test/.airbyte/abctl/abctl.kubeconfig
+  Chart:       airbyte/airbyte (version 1.7.0)
+  Storage:     standard
+  Warnings:    1
+    - Only 2GB of memory is available
+`
+	if d := cmp.Diff(want, out.String()); d != "" {
+		t.Errorf("summary mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestInstallSummary_PrintNoWarnings(t *testing.T) {
+	var out bytes.Buffer
+	installSummary{URL: "http://localhost:8000", Chart: "/tmp/airbyte.tgz"}.WriteTable(&out)
+
+	want := `Airbyte installation complete
+  URL:         http://localhost:8000
+  Credentials: run 'abctl local credentials'
+  Chart:       /tmp/airbyte.tgz
+  Warnings:    none
+`
+	if d := cmp.Diff(want, out.String()); d != "" {
+		t.Errorf("summary mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestInstallSummary_Render(t *testing.T) {
+	summary := installSummary{
+		URL:          "http://localhost:8000",
+		Cluster:      "airbyte-abctl",
+		Chart:        "airbyte/airbyte",
+		ChartVersion: "1.7.0",
+		LicenseKey:   "ab-license-123",
+		Warnings:     []string{"Only 2GB of memory is available"},
+	}
+
+	tests := []struct {
+		format output.Format
+		want   string
+	}{
+		{
+			format: output.Table,
+			want: `Airbyte installation complete
+  URL:         http://localhost:8000
+  Credentials: run 'abctl local credentials'
+  Cluster:     airbyte-abctl
+  Chart:       airbyte/airbyte (version 1.7.0)
+  License key: [REDACTED]
+  Warnings:    1
+    - Only 2GB of memory is available
+`,
+		},
+		{
+			format: output.YAML,
+			want: `url: http://localhost:8000
+cluster: airbyte-abctl
+chart: airbyte/airbyte
+chartVersion: 1.7.0
+licenseKey: '[REDACTED]'
+warnings:
+  - Only 2GB of memory is available
+`,
+		},
+		{
+			format: output.JSON,
+			want: `{
+  "url": "http://localhost:8000",
+  "cluster": "airbyte-abctl",
+  "chart": "airbyte/airbyte",
+  "chartVersion": "1.7.0",
+  "licenseKey": "[REDACTED]",
+  "warnings": [
+    "Only 2GB of memory is available"
+  ]
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var out bytes.Buffer
+			if err := output.Render(&out, tt.format, summary); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if d := cmp.Diff(tt.want, out.String()); d != "" {
+				t.Errorf("summary mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestSummaryStorageClass(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     k8s.Provider
+		storageClass string
+		want         string
+	}{
+		{name: "kind", provider: k8s.DefaultProvider, want: "standard"},
+		{name: "kind override", provider: k8s.DefaultProvider, storageClass: "fast", want: "fast"},
+		{name: "external", provider: k8s.Provider{Name: k8s.External}, want: "cluster default"},
+		{name: "external override", provider: k8s.Provider{Name: k8s.External}, storageClass: "gp3", want: "gp3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, summaryStorageClass(tt.provider, tt.storageClass)); d != "" {
+				t.Errorf("storage class mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
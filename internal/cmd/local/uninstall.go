@@ -2,9 +2,19 @@ package local
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
 
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/docker"
 	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/paths"
 	"github.com/airbytehq/abctl/internal/service"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/airbytehq/abctl/internal/trace"
@@ -13,27 +23,50 @@ import (
 )
 
 type UninstallCmd struct {
-	Persisted bool `help:"Remove persisted data."`
+	DryRun          bool         `help:"List the resources which would be removed, and the persisted data which would be kept, without removing anything."`
+	Persisted       bool         `help:"Remove persisted data."`
+	Progress        progressMode `default:"auto" enum:"auto,plain,tty" help:"How to display the progress of the uninstallation (auto, plain or tty). With tty, a spinner is redrawn in place. With plain, a line is printed for every step, for logs and CI. With auto, tty is used for a terminal and plain otherwise."`
+	PruneImages     bool         `help:"Remove the Docker images pulled by abctl for the cluster. Images pulled by anything else are kept."`
+	SkipDockerCheck bool         `help:"Skip checking for a Docker installation."`
 }
 
 func (u *UninstallCmd) Run(ctx context.Context, provider k8s.Provider, telClient telemetry.Client) error {
 	ctx, span := trace.NewSpan(ctx, "local uninstall")
 	defer span.End()
 
-	span.SetAttributes(attribute.Bool("persisted", u.Persisted))
+	span.SetAttributes(
+		attribute.Bool("persisted", u.Persisted),
+		attribute.Bool("prune-images", u.PruneImages),
+		attribute.Bool("dry-run", u.DryRun),
+	)
 
-	spinner := &pterm.DefaultSpinner
-	spinner, _ = spinner.Start("Starting uninstallation")
-	spinner.UpdateText("Checking for Docker installation")
+	progress := newProgress(u.Progress.resolve(isTerminal(os.Stdout)))
+	progress.Start("Starting uninstallation")
+	progress.UpdateText("Checking for Docker installation")
 
-	_, err := dockerInstalled(ctx, telClient)
-	if err != nil {
-		pterm.Error.Println("Unable to determine if Docker is installed")
-		return fmt.Errorf("unable to determine docker installation status: %w", err)
+	if err := checkDockerRequired(ctx, telClient, provider, u.SkipDockerCheck, &preflightWarnings{}); err != nil {
+		reportPreflight(ctx, telClient, telemetry.Uninstall, err)
+		return err
+	}
+
+	// a dry run removes nothing, so isn't reported as an uninstall
+	if u.DryRun {
+		cluster, err := provider.Cluster(ctx)
+		if err != nil {
+			pterm.Error.Printfln("Unable to determine if the cluster '%s' exists", provider.ClusterName)
+			return err
+		}
+		plan, err := u.uninstallPlan(ctx, cluster, provider.ClusterName, paths.Data, paths.ImageManifest)
+		if err != nil {
+			return err
+		}
+		progress.Stop()
+		printUninstallPlan(os.Stdout, plan)
+		return nil
 	}
 
 	return telClient.Wrap(ctx, telemetry.Uninstall, func() error {
-		spinner.UpdateText(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
+		progress.UpdateText(fmt.Sprintf("Checking for existing Kubernetes cluster '%s'", provider.ClusterName))
 
 		cluster, err := provider.Cluster(ctx)
 		if err != nil {
@@ -44,12 +77,12 @@ func (u *UninstallCmd) Run(ctx context.Context, provider k8s.Provider, telClient
 		// if no cluster exists, there is nothing to do
 		if !cluster.Exists(ctx) {
 			pterm.Success.Printfln("Cluster '%s' does not exist\nNo additional action required", provider.ClusterName)
-			return nil
+			return u.pruneImages(ctx, progress)
 		}
 
 		pterm.Success.Printfln("Existing cluster '%s' found", provider.ClusterName)
 
-		svcMgr, err := service.NewManager(provider, service.WithTelemetryClient(telClient), service.WithSpinner(spinner))
+		svcMgr, err := service.NewManager(provider, service.WithTelemetryClient(telClient), service.WithProgress(progress.Event))
 		if err != nil {
 			pterm.Warning.Printfln("Failed to initialize 'local' command\nUninstallation attempt will continue")
 			pterm.Debug.Printfln("Initialization of 'local' failed with %s", err.Error())
@@ -60,15 +93,199 @@ func (u *UninstallCmd) Run(ctx context.Context, provider k8s.Provider, telClient
 			}
 		}
 
-		spinner.UpdateText(fmt.Sprintf("Verifying uninstallation status of cluster '%s'", provider.ClusterName))
-		if err := cluster.Delete(ctx); err != nil {
-			pterm.Error.Printfln("Uninstallation of cluster '%s' failed", provider.ClusterName)
-			return fmt.Errorf("unable to uninstall cluster %s", provider.ClusterName)
+		progress.UpdateText(fmt.Sprintf("Verifying uninstallation status of cluster '%s'", provider.ClusterName))
+		if err := uninstallCluster(ctx, cluster, provider.ClusterName); err != nil {
+			return err
 		}
 		pterm.Success.Printfln("Uninstallation of cluster '%s' completed successfully", provider.ClusterName)
 
-		spinner.Success("Airbyte uninstallation complete")
+		if err := u.pruneImages(ctx, progress); err != nil {
+			return err
+		}
+
+		progress.Success("Airbyte uninstallation complete")
 
 		return nil
 	})
 }
+
+// exitClusterDeleteTimeout is the exit code of an uninstall whose cluster wasn't deleted within the clusterDeleteTimeout.
+const exitClusterDeleteTimeout = 20
+
+// clusterDeleteTimeout limits how long the cluster delete is waited on, as it hangs indefinitely on a wedged docker.
+var clusterDeleteTimeout = 5 * time.Minute
+
+// uninstallCluster deletes the cluster, returning an ErrClusterDeleteTimeout error, which exits with the
+// exitClusterDeleteTimeout code, if it isn't deleted within the clusterDeleteTimeout.
+// A partially deleted cluster still exists, so running uninstall again resumes deleting it.
+func uninstallCluster(ctx context.Context, cluster k8s.Cluster, clusterName string) error {
+	err := deleteClusterWithin(ctx, cluster, clusterDeleteTimeout)
+	if errors.Is(err, context.DeadlineExceeded) {
+		pterm.Error.Printfln("Uninstallation of cluster '%s' did not complete within %s", clusterName, clusterDeleteTimeout)
+		pterm.Warning.Println("Docker appears unresponsive, try restarting Docker then run 'abctl local uninstall' again")
+		return &abctl.ExitError{
+			Code: exitClusterDeleteTimeout,
+			Err:  fmt.Errorf("%w: cluster '%s' was not deleted within %s", abctl.ErrClusterDeleteTimeout, clusterName, clusterDeleteTimeout),
+		}
+	}
+	if err != nil {
+		pterm.Error.Printfln("Uninstallation of cluster '%s' failed", clusterName)
+		return fmt.Errorf("unable to uninstall cluster %s: %w", clusterName, err)
+	}
+	return nil
+}
+
+// deleteClusterWithin deletes the cluster, giving up once the timeout elapses. The delete of a kind cluster ignores
+// its context, so a hung delete is abandoned rather than waited on.
+func deleteClusterWithin(ctx context.Context, cluster k8s.Cluster, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cluster.Delete(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// pruneImages removes the unused images pulled by abctl, if requested, reporting the reclaimed space.
+func (u *UninstallCmd) pruneImages(ctx context.Context, progress progressPrinter) error {
+	if !u.PruneImages {
+		return nil
+	}
+
+	progress.UpdateText("Removing the Docker images pulled by abctl")
+	if dockerClient == nil {
+		var err error
+		if dockerClient, err = docker.New(ctx); err != nil {
+			pterm.Error.Println("Unable to create Docker client")
+			return fmt.Errorf("%w: unable to create client: %w", abctl.ErrDocker, err)
+		}
+	}
+
+	// the cluster is gone, so the images it used are unused and selected the same way as by local prune
+	_, images, err := service.UnusedImages(ctx, dockerClient, paths.ImageManifest)
+	if err != nil {
+		pterm.Error.Println("Unable to determine the Docker images pulled by abctl")
+		return err
+	}
+	if len(images) == 0 {
+		pterm.Info.Println("No unused Docker images pulled by abctl were found")
+		return nil
+	}
+
+	res, err := service.RemoveUnusedImages(ctx, dockerClient, paths.ImageManifest, images)
+	if err != nil {
+		pterm.Error.Println("Unable to remove the Docker images pulled by abctl")
+		return fmt.Errorf("unable to prune images: %w", err)
+	}
+	pterm.Success.Printfln("Removed %d Docker images, reclaiming up to %s", len(res.Removed), formatGiB(res.Reclaimed))
+	return nil
+}
+
+// removal is a resource which uninstall removes, or keeps.
+type removal struct {
+	resource string
+	name     string
+	// kept is set to the reason the resource is kept, empty if it is removed.
+	kept string
+}
+
+// uninstallPlan returns the resources an uninstall would remove or keep, without removing anything.
+// The persisted volumes are the directories within the dataDir, the images are those tracked in the image manifest.
+func (u *UninstallCmd) uninstallPlan(ctx context.Context, cluster k8s.Cluster, clusterName, dataDir, manifest string) ([]removal, error) {
+	var plan []removal
+
+	clusterExists := cluster.Exists(ctx)
+	if clusterExists {
+		// the helm releases and their namespaces are removed along with the cluster
+		plan = append(plan,
+			removal{resource: "cluster", name: clusterName},
+			removal{resource: "helm release", name: fmt.Sprintf("%s (namespace %s)", common.AirbyteChartRelease, common.AirbyteNamespace)},
+			removal{resource: "helm release", name: fmt.Sprintf("%s (namespace %s)", common.NginxChartRelease, common.NginxNamespace)},
+			removal{resource: "namespace", name: common.AirbyteNamespace},
+			removal{resource: "namespace", name: common.NginxNamespace},
+		)
+	}
+
+	volumes, err := persistedVolumes(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, volume := range volumes {
+		r := removal{resource: "persisted volume", name: volume}
+		switch {
+		case !clusterExists:
+			r.kept = "the cluster does not exist"
+		case !u.Persisted:
+			r.kept = "pass --persisted to remove"
+		}
+		plan = append(plan, r)
+	}
+
+	if u.PruneImages {
+		images, err := service.PrunableImages(manifest)
+		if err != nil {
+			return nil, err
+		}
+		for _, img := range images {
+			plan = append(plan, removal{resource: "docker image", name: img})
+		}
+	}
+
+	return plan, nil
+}
+
+// persistedVolumes returns the sorted paths of the persisted volume directories within the dataDir.
+func persistedVolumes(dataDir string) ([]string, error) {
+	entries, err := os.ReadDir(dataDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the persisted data directory '%s': %w", dataDir, err)
+	}
+
+	var volumes []string
+	for _, e := range entries {
+		if e.IsDir() {
+			volumes = append(volumes, filepath.Join(dataDir, e.Name()))
+		}
+	}
+	sort.Strings(volumes)
+	return volumes, nil
+}
+
+// printUninstallPlan writes the resources which would be removed, followed by those which would be kept.
+func printUninstallPlan(w io.Writer, plan []removal) {
+	var removed, kept []removal
+	for _, r := range plan {
+		if r.kept == "" {
+			removed = append(removed, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+
+	if len(removed) == 0 {
+		fmt.Fprintln(w, "Nothing would be removed")
+	} else {
+		fmt.Fprintln(w, "Would remove:")
+		for _, r := range removed {
+			fmt.Fprintf(w, "  - %-17s %s\n", r.resource, r.name)
+		}
+	}
+
+	if len(kept) > 0 {
+		fmt.Fprintln(w, "Would keep:")
+		for _, r := range kept {
+			fmt.Fprintf(w, "  - %-17s %s (%s)\n", r.resource, r.name, r.kept)
+		}
+	}
+}
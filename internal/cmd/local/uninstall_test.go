@@ -0,0 +1,280 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/google/go-cmp/cmp"
+)
+
+var _ k8s.Cluster = (*dryRunCluster)(nil)
+
+// dryRunCluster is a k8s.Cluster which fails the test if anything is created or deleted.
+type dryRunCluster struct {
+	t      *testing.T
+	exists bool
+}
+
+func (c *dryRunCluster) Create(context.Context, int, []k8s.ExtraVolumeMount, k8s.NodeOpts) error {
+	c.t.Error("unexpected cluster create")
+	return nil
+}
+
+func (c *dryRunCluster) Delete(context.Context) error {
+	c.t.Error("unexpected cluster delete")
+	return nil
+}
+
+func (c *dryRunCluster) Exists(context.Context) bool {
+	return c.exists
+}
+
+func (c *dryRunCluster) LoadImages(context.Context, docker.Client, []string) {
+	c.t.Error("unexpected image load")
+}
+
+func TestUninstallPlan(t *testing.T) {
+	dataDir := t.TempDir()
+	for _, dir := range []string{"airbyte-volume-db", "airbyte-local-pv"} {
+		if err := os.MkdirAll(filepath.Join(dataDir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// files within the data dir aren't volumes
+	if err := os.WriteFile(filepath.Join(dataDir, "notes.txt"), []byte("notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	manifest := filepath.Join(t.TempDir(), "images.json")
+	if err := os.WriteFile(manifest, []byte(`{"images":["airbyte/server:1.0.0","airbyte/webapp:1.0.0"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dbVolume := filepath.Join(dataDir, "airbyte-volume-db")
+	pvVolume := filepath.Join(dataDir, "airbyte-local-pv")
+	clusterRemovals := []removal{
+		{resource: "cluster", name: "airbyte-abctl"},
+		{resource: "helm release", name: "airbyte-abctl (namespace airbyte-abctl)"},
+		{resource: "helm release", name: "ingress-nginx (namespace ingress-nginx)"},
+		{resource: "namespace", name: "airbyte-abctl"},
+		{resource: "namespace", name: "ingress-nginx"},
+	}
+
+	tests := []struct {
+		name          string
+		cmd           UninstallCmd
+		clusterExists bool
+		want          []removal
+	}{
+		{
+			name:          "volumes kept",
+			cmd:           UninstallCmd{DryRun: true},
+			clusterExists: true,
+			want: append(append([]removal{}, clusterRemovals...),
+				removal{resource: "persisted volume", name: pvVolume, kept: "pass --persisted to remove"},
+				removal{resource: "persisted volume", name: dbVolume, kept: "pass --persisted to remove"},
+			),
+		},
+		{
+			name:          "volumes removed",
+			cmd:           UninstallCmd{DryRun: true, Persisted: true},
+			clusterExists: true,
+			want: append(append([]removal{}, clusterRemovals...),
+				removal{resource: "persisted volume", name: pvVolume},
+				removal{resource: "persisted volume", name: dbVolume},
+			),
+		},
+		{
+			name:          "images pruned",
+			cmd:           UninstallCmd{DryRun: true, Persisted: true, PruneImages: true},
+			clusterExists: true,
+			want: append(append([]removal{}, clusterRemovals...),
+				removal{resource: "persisted volume", name: pvVolume},
+				removal{resource: "persisted volume", name: dbVolume},
+				removal{resource: "docker image", name: "airbyte/server:1.0.0"},
+				removal{resource: "docker image", name: "airbyte/webapp:1.0.0"},
+			),
+		},
+		{
+			name: "no cluster",
+			cmd:  UninstallCmd{DryRun: true, Persisted: true, PruneImages: true},
+			want: []removal{
+				{resource: "persisted volume", name: pvVolume, kept: "the cluster does not exist"},
+				{resource: "persisted volume", name: dbVolume, kept: "the cluster does not exist"},
+				{resource: "docker image", name: "airbyte/server:1.0.0"},
+				{resource: "docker image", name: "airbyte/webapp:1.0.0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &dryRunCluster{t: t, exists: tt.clusterExists}
+			got, err := tt.cmd.uninstallPlan(context.Background(), cluster, "airbyte-abctl", dataDir, manifest)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, got, cmp.AllowUnexported(removal{})); d != "" {
+				t.Errorf("plan mismatch (-want +got):\n%s", d)
+			}
+
+			// nothing was removed
+			for _, path := range []string{dbVolume, pvVolume, manifest} {
+				if _, err := os.Stat(path); err != nil {
+					t.Errorf("expected %s to still exist: %s", path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestUninstallPlan_NoDataDir(t *testing.T) {
+	cluster := &dryRunCluster{t: t}
+	cmd := UninstallCmd{DryRun: true, PruneImages: true}
+	got, err := cmd.uninstallPlan(context.Background(), cluster, "airbyte-abctl", filepath.Join(t.TempDir(), "missing"), filepath.Join(t.TempDir(), "images.json"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("expected an empty plan, got %v", got)
+	}
+}
+
+func TestPrintUninstallPlan(t *testing.T) {
+	var out bytes.Buffer
+	printUninstallPlan(&out, []removal{
+		{resource: "cluster", name: "airbyte-abctl"},
+		{resource: "persisted volume", name: "/data/airbyte-volume-db", kept: "pass --persisted to remove"},
+		{resource: "docker image", name: "airbyte/server:1.0.0"},
+	})
+
+	want := `Would remove:
+  - cluster           airbyte-abctl
+  - docker image      airbyte/server:1.0.0
+Would keep:
+  - persisted volume  /data/airbyte-volume-db (pass --persisted to remove)
+`
+	if d := cmp.Diff(want, out.String()); d != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", d)
+	}
+
+	out.Reset()
+	printUninstallPlan(&out, nil)
+	if d := cmp.Diff("Nothing would be removed\n", out.String()); d != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", d)
+	}
+}
+
+var _ k8s.Cluster = (*deleteCluster)(nil)
+
+// deleteCluster is a k8s.Cluster whose delete returns err, or hangs until the test ends while hang is set.
+type deleteCluster struct {
+	dryRunCluster
+	err     error
+	hang    atomic.Bool
+	release chan struct{}
+	deletes atomic.Int32
+}
+
+func newDeleteCluster(t *testing.T, err error) *deleteCluster {
+	c := &deleteCluster{dryRunCluster: dryRunCluster{t: t, exists: true}, err: err, release: make(chan struct{})}
+	t.Cleanup(func() { close(c.release) })
+	return c
+}
+
+func (c *deleteCluster) Delete(context.Context) error {
+	c.deletes.Add(1)
+	if c.hang.Load() {
+		// ignores its context, as the delete of a kind cluster does
+		<-c.release
+	}
+	return c.err
+}
+
+func TestUninstallCluster(t *testing.T) {
+	orig := clusterDeleteTimeout
+	t.Cleanup(func() { clusterDeleteTimeout = orig })
+	clusterDeleteTimeout = 10 * time.Millisecond
+
+	errDelete := errors.New("test error")
+
+	tests := []struct {
+		name     string
+		hang     bool
+		err      error
+		wantErr  error
+		wantCode int
+	}{
+		{name: "deleted"},
+		{
+			name:     "timeout",
+			hang:     true,
+			wantErr:  abctl.ErrClusterDeleteTimeout,
+			wantCode: exitClusterDeleteTimeout,
+		},
+		{
+			name:    "error",
+			err:     errDelete,
+			wantErr: errDelete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newDeleteCluster(t, tt.err)
+			cluster.hang.Store(tt.hang)
+
+			err := uninstallCluster(context.Background(), cluster, "airbyte-abctl")
+			if d := cmp.Diff(int32(1), cluster.deletes.Load()); d != "" {
+				t.Errorf("deletes mismatch (-want +got):\n%s", d)
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v but got %v", tt.wantErr, err)
+			}
+
+			var exitErr *abctl.ExitError
+			code := 0
+			if errors.As(err, &exitErr) {
+				code = exitErr.Code
+			}
+			if d := cmp.Diff(tt.wantCode, code); d != "" {
+				t.Errorf("exit code mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestUninstallCluster_Reattempt(t *testing.T) {
+	orig := clusterDeleteTimeout
+	t.Cleanup(func() { clusterDeleteTimeout = orig })
+	clusterDeleteTimeout = 10 * time.Millisecond
+
+	// the first delete hangs on a wedged docker, leaving the cluster in place for the next uninstall
+	cluster := newDeleteCluster(t, nil)
+	cluster.hang.Store(true)
+	if err := uninstallCluster(context.Background(), cluster, "airbyte-abctl"); !errors.Is(err, abctl.ErrClusterDeleteTimeout) {
+		t.Fatalf("expected ErrClusterDeleteTimeout but got %v", err)
+	}
+	if !cluster.Exists(context.Background()) {
+		t.Fatal("expected the cluster to still exist")
+	}
+
+	cluster.hang.Store(false)
+	if err := uninstallCluster(context.Background(), cluster, "airbyte-abctl"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
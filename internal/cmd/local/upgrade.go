@@ -0,0 +1,94 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/airbytehq/abctl/internal/trace"
+	goHelm "github.com/mittwald/go-helm-client"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+)
+
+// UpgradeCmd contains the arguments used when executing the upgrade command.
+// An upgrade accepts the same flags as an install, but requires an existing installation.
+type UpgradeCmd struct {
+	InstallCmd `embed:""`
+
+	DryRun bool `help:"Show the chart version and helm values changes of the upgrade, without applying them."`
+}
+
+// Run executes the upgrade command, which upgrades an existing installation.
+func (u *UpgradeCmd) Run(ctx context.Context, provider k8s.Provider, newSvcMgrClients service.ManagerClientFactory, telClient telemetry.Client) error {
+	ctx, span := trace.NewSpan(ctx, "local upgrade")
+	defer span.End()
+
+	if _, err := provider.GetCluster(ctx); errors.Is(err, abctl.ErrClusterNotFound) {
+		pterm.Error.Printfln("No existing cluster '%s' found", provider.ClusterName)
+		return errors.New("no existing installation to upgrade, run 'abctl local install' instead")
+	} else if err != nil {
+		pterm.Error.Printfln("Unable to determine status of any existing '%s' cluster", provider.ClusterName)
+		return err
+	}
+
+	if !u.DryRun {
+		return u.InstallCmd.Run(ctx, provider, newSvcMgrClients, telClient)
+	}
+
+	if u.ChartValuesFromRelease {
+		if err := u.readReleaseValues(provider, newSvcMgrClients); err != nil {
+			return err
+		}
+	}
+
+	_, helmClient, err := newSvcMgrClients(provider.Kubeconfig, provider.Context)
+	if err != nil {
+		return err
+	}
+
+	return u.dryRun(ctx, helmClient, telClient.User(), os.Stdout)
+}
+
+// dryRun writes the differences between the deployed release and the upgrade target.
+// An error is returned if the upgrade would be blocked.
+func (u *UpgradeCmd) dryRun(ctx context.Context, helmClient goHelm.Client, user string, w io.Writer) error {
+	rel, err := helmClient.GetRelease(common.AirbyteChartRelease)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the deployed airbyte release: %w", err)
+	}
+
+	if err := u.setDefaultChartFlags(helmClient); err != nil {
+		return fmt.Errorf("failed to set chart defaults: %w", err)
+	}
+
+	opts, err := u.installOpts(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	var target map[string]any
+	if err := yaml.Unmarshal([]byte(opts.HelmValuesYaml), &target); err != nil {
+		return fmt.Errorf("unable to unmarshal merged values: %w", err)
+	}
+
+	deployedVersion := rel.Chart.Metadata.Version
+	if _, err := fmt.Fprint(w, helm.RenderValuesDiff(deployedVersion, u.ChartVersion, rel.Config, target)); err != nil {
+		return fmt.Errorf("unable to write the upgrade diff: %w", err)
+	}
+
+	if err := helm.CheckUpgrade(deployedVersion, u.ChartVersion); err != nil {
+		pterm.Error.Println("The upgrade would be blocked")
+		return err
+	}
+
+	return nil
+}
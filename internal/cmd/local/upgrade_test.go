@@ -0,0 +1,69 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/helm/mock"
+	"go.uber.org/mock/gomock"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestUpgradeCmd_DryRun(t *testing.T) {
+	tests := []struct {
+		name            string
+		deployedVersion string
+		wantErr         error
+	}{
+		{
+			name:            "upgrade",
+			deployedVersion: "2.0.0",
+		},
+		{
+			name:            "downgrade blocked",
+			deployedVersion: "2.1.0",
+			wantErr:         abctl.ErrUpgradeBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			helmClient := mock.NewMockClient(ctrl)
+			helmClient.EXPECT().
+				GetRelease(common.AirbyteChartRelease).
+				Return(&release.Release{
+					Chart: &chart.Chart{Metadata: &chart.Metadata{Version: tt.deployedVersion}},
+					Config: map[string]any{
+						"global": map[string]any{"airbyteUrl": "http://localhost:8001"},
+					},
+				}, nil)
+
+			cmd := UpgradeCmd{InstallCmd: InstallCmd{ChartVersion: "2.0.1", Port: 8000}, DryRun: true}
+
+			var b bytes.Buffer
+			err := cmd.dryRun(context.Background(), helmClient, "", &b)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v but got %v", tt.wantErr, err)
+			}
+
+			// the diff is written even if the upgrade is blocked
+			for _, want := range []string{
+				"--- deployed (chart " + tt.deployedVersion + ")",
+				"+++ target (chart 2.0.1)",
+				"- global.airbyteUrl: http://localhost:8001",
+				"+ global.airbyteUrl: http://localhost:8000",
+			} {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("expected diff to contain %q but got:\n%s", want, b.String())
+				}
+			}
+		})
+	}
+}
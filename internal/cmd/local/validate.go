@@ -0,0 +1,61 @@
+package local
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/trace"
+	goHelm "github.com/mittwald/go-helm-client"
+	"github.com/pterm/pterm"
+)
+
+// ValidateCmd contains the arguments used when executing the validate command.
+type ValidateCmd struct {
+	Chart        string   `help:"Path to chart." xor:"chartver"`
+	ChartVersion string   `help:"Version to validate against." xor:"chartver"`
+	Layer        []string `aliases:"values-layer" help:"A named values layer to apply before the values file. May be specified multiple times."`
+	Values       string   `short:"f" required:"" type:"existingfile" help:"An Airbyte helm chart values file to validate."`
+}
+
+// Run executes the validate command, which validates the values file against the chart without
+// requiring docker or a cluster.
+func (v *ValidateCmd) Run(ctx context.Context) error {
+	ctx, span := trace.NewSpan(ctx, "local validate")
+	defer span.End()
+
+	helmClient, err := helm.NewLocal(airbyteNamespace)
+	if err != nil {
+		return err
+	}
+
+	return v.validate(ctx, helmClient)
+}
+
+func (v *ValidateCmd) validate(ctx context.Context, helmClient goHelm.Client) error {
+	// merge the values exactly as an install would, the port has no impact on the validation
+	install := InstallCmd{
+		Chart:        v.Chart,
+		ChartVersion: v.ChartVersion,
+		Values:       v.Values,
+		Layer:        v.Layer,
+		Port:         8000,
+	}
+
+	if err := install.setDefaultChartFlags(helmClient); err != nil {
+		return fmt.Errorf("failed to set chart defaults: %w", err)
+	}
+
+	opts, err := install.installOpts(ctx, "")
+	if err != nil {
+		pterm.Error.Printfln("Unable to read the values file '%s'", v.Values)
+		return err
+	}
+
+	if err := validateValuesSchema(helmClient, opts); err != nil {
+		return err
+	}
+
+	pterm.Success.Printfln("Values file '%s' is valid for chart version %s", v.Values, install.ChartVersion)
+	return nil
+}
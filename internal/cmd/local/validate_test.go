@@ -0,0 +1,95 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/helm/mock"
+	"go.uber.org/mock/gomock"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const testValuesSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "server": {
+      "type": "object",
+      "properties": {
+        "replicaCount": {"type": "integer"}
+      }
+    }
+  }
+}`
+
+func TestValidateCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  string
+		file    string
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:   "valid",
+			values: "server:\n  replicaCount: 2\n",
+		},
+		{
+			name:    "malformed yaml",
+			file:    "./testdata/invalid.values.yaml",
+			wantMsg: "failed to unmarshal file",
+		},
+		{
+			name:    "schema violation",
+			values:  "server:\n  replicaCount: two\n",
+			wantErr: abctl.ErrValuesSchema,
+			wantMsg: "replicaCount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			helmClient := mock.NewMockClient(ctrl)
+			helmClient.EXPECT().
+				GetChart(gomock.Any(), gomock.Any()).
+				Return(&chart.Chart{
+					Metadata: &chart.Metadata{Name: "airbyte", Version: "2.0.0"},
+					Schema:   []byte(testValuesSchema),
+				}, "", nil).
+				AnyTimes()
+
+			file := tt.file
+			if file == "" {
+				file = filepath.Join(t.TempDir(), "values.yaml")
+				if err := os.WriteFile(file, []byte(tt.values), 0o600); err != nil {
+					t.Fatal("unable to write values file", err)
+				}
+			}
+
+			cmd := ValidateCmd{ChartVersion: "2.0.0", Values: file}
+			err := cmd.validate(context.Background(), helmClient)
+
+			if tt.wantMsg == "" {
+				if err != nil {
+					t.Fatal("unexpected error", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v but got %v", tt.wantErr, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("expected error to contain %q but got %q", tt.wantMsg, err)
+			}
+		})
+	}
+}
@@ -18,4 +18,14 @@ const (
 
 	// DockerAuthSecretName is the name of the secret which holds the docker authentication information.
 	DockerAuthSecretName = "docker-auth"
+
+	// AirbyteAuthSecretName is the name of the secret which holds the airbyte login credentials.
+	AirbyteAuthSecretName = "airbyte-auth-secrets"
+	// AirbyteAuthSecretPassword is the key of the admin password within the AirbyteAuthSecretName secret.
+	AirbyteAuthSecretPassword = "instance-admin-password"
+
+	// AirbyteLicenseSecretName is the name of the secret which holds the airbyte enterprise license key.
+	AirbyteLicenseSecretName = "airbyte-license"
+	// AirbyteLicenseSecretKey is the key of the license key within the AirbyteLicenseSecretName secret.
+	AirbyteLicenseSecretKey = "license-key"
 )
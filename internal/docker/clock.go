@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/system"
+)
+
+// ClockSkew returns the absolute difference between the system time reported by the docker daemon and now.
+// On Docker Desktop the daemon runs in a VM, whose clock can drift from the host after the host sleeps.
+func ClockSkew(info system.Info, now time.Time) (time.Duration, error) {
+	if info.SystemTime == "" {
+		return 0, errors.New("docker daemon did not report its system time")
+	}
+
+	daemonTime, err := time.Parse(time.RFC3339Nano, info.SystemTime)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse docker daemon system time '%s': %w", info.SystemTime, err)
+	}
+
+	skew := now.Sub(daemonTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	return skew, nil
+}
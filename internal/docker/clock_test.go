@@ -0,0 +1,39 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/system"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClockSkew(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		systemTime string
+		want       time.Duration
+		wantErr    bool
+	}{
+		{name: "in sync", systemTime: "2024-06-01T12:00:00.000000000Z", want: 0},
+		{name: "daemon behind", systemTime: "2024-06-01T11:55:00.5Z", want: 4*time.Minute + 59500*time.Millisecond},
+		{name: "daemon ahead", systemTime: "2024-06-01T12:10:00Z", want: 10 * time.Minute},
+		{name: "different time zone", systemTime: "2024-06-01T14:00:30+02:00", want: 30 * time.Second},
+		{name: "missing", systemTime: "", wantErr: true},
+		{name: "invalid", systemTime: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skew, err := ClockSkew(system.Info{SystemTime: tt.systemTime}, now)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, skew); d != "" {
+				t.Errorf("skew mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Context is a docker context, as listed by the "docker context ls" command.
+type Context struct {
+	Name    string
+	Host    string
+	Current bool
+}
+
+// dockerCLI runs the docker cli with the args, returning its output.
+// Exists as a variable for testing purposes.
+var dockerCLI = func(args ...string) ([]byte, error) {
+	out, err := exec.Command("docker", args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return out, err
+}
+
+// selectedContext, when set, is the docker context whose host is used instead of discovering the docker host.
+var selectedContext string
+
+// SetContext selects the docker context used by all clients created by New, instead of the current docker context.
+// Unlike the current docker context, the host of a selected context is the only host tried.
+// An empty name restores the default discovery.
+func SetContext(name string) {
+	selectedContext = strings.TrimSpace(name)
+}
+
+// contextHost returns the docker host of the named docker context, or of the current docker context if the name is empty.
+func contextHost(name string) (string, error) {
+	args := []string{"context", "inspect"}
+	if name != "" {
+		args = append(args, name)
+	}
+	out, err := dockerCLI(args...)
+	if err != nil {
+		return "", fmt.Errorf("unable to inspect docker context: %w", err)
+	}
+
+	var data []struct {
+		Endpoints struct {
+			Docker struct {
+				Host string
+			} `json:"docker"`
+		}
+	}
+	if err := json.Unmarshal(out, &data); err != nil {
+		return "", fmt.Errorf("unable to parse docker context: %w", err)
+	}
+	if len(data) == 0 || data[0].Endpoints.Docker.Host == "" {
+		return "", errors.New("docker context has no docker endpoint")
+	}
+
+	return data[0].Endpoints.Docker.Host, nil
+}
+
+// listContexts returns the available docker contexts.
+func listContexts() ([]Context, error) {
+	out, err := dockerCLI("context", "ls", "--format", "{{json .}}")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list docker contexts: %w", err)
+	}
+
+	var contexts []Context
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var c struct {
+			Name           string
+			Current        bool
+			DockerEndpoint string
+		}
+		if err := json.Unmarshal(line, &c); err != nil {
+			return nil, fmt.Errorf("unable to parse docker context: %w", err)
+		}
+		contexts = append(contexts, Context{Name: c.Name, Host: c.DockerEndpoint, Current: c.Current})
+	}
+
+	return contexts, nil
+}
+
+// contextGuidance returns the guidance for when the docker host of the current docker context cannot be connected to,
+// suggesting the other available contexts instead.
+// Returns an empty string if there are no other contexts to suggest.
+func contextGuidance(host string, contexts []Context) string {
+	current := "unknown"
+	var others []Context
+	for _, c := range contexts {
+		if c.Current {
+			current = c.Name
+		} else {
+			others = append(others, c)
+		}
+	}
+	if len(others) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Unable to connect to the docker host %s of the current docker context '%s'.\n", host, current)
+	b.WriteString("The current docker context may not be the expected one, the other available docker contexts are:\n")
+	for _, c := range others {
+		fmt.Fprintf(&b, "  - %s (%s)\n", c.Name, c.Host)
+	}
+	fmt.Fprintf(&b, "Try a specific docker context with --docker-context <NAME>, e.g. --docker-context %s", others[0].Name)
+
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package docker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// mockDockerCLI replaces the dockerCLI with one returning the output for the args, recording the args of every call.
+func mockDockerCLI(t *testing.T, outputs map[string]string) *[][]string {
+	orig := dockerCLI
+	t.Cleanup(func() { dockerCLI = orig })
+
+	var calls [][]string
+	dockerCLI = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		out, ok := outputs[strings.Join(args, " ")]
+		if !ok {
+			return nil, errors.New("exit status 1: context does not exist")
+		}
+		return []byte(out), nil
+	}
+	return &calls
+}
+
+const (
+	inspectDefault = `[{"Name":"default","Metadata":{},"Endpoints":{"docker":{"Host":"unix:///var/run/docker.sock","SkipTLSVerify":false}}}]`
+	inspectColima  = `[{"Name":"colima","Metadata":{"Description":"colima"},"Endpoints":{"docker":{"Host":"unix:///Users/test/.colima/default/docker.sock","SkipTLSVerify":false}}}]`
+	contextList    = `{"Current":false,"Description":"colima","DockerEndpoint":"unix:///Users/test/.colima/default/docker.sock","Error":"","Name":"colima"}
+{"Current":true,"Description":"Current DOCKER_HOST based configuration","DockerEndpoint":"unix:///var/run/docker.sock","Error":"","Name":"default"}
+{"Current":false,"Description":"Docker Desktop","DockerEndpoint":"unix:///Users/test/.docker/run/docker.sock","Error":"","Name":"desktop-linux"}
+`
+)
+
+func TestContextHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		context  string
+		outputs  map[string]string
+		wantHost string
+		wantArgs []string
+		wantErr  bool
+	}{
+		{
+			name:     "current context",
+			outputs:  map[string]string{"context inspect": inspectDefault},
+			wantHost: "unix:///var/run/docker.sock",
+			wantArgs: []string{"context", "inspect"},
+		},
+		{
+			name:     "named context",
+			context:  "colima",
+			outputs:  map[string]string{"context inspect colima": inspectColima},
+			wantHost: "unix:///Users/test/.colima/default/docker.sock",
+			wantArgs: []string{"context", "inspect", "colima"},
+		},
+		{
+			name:     "unknown context",
+			context:  "missing",
+			outputs:  map[string]string{},
+			wantArgs: []string{"context", "inspect", "missing"},
+			wantErr:  true,
+		},
+		{
+			name:     "no docker endpoint",
+			context:  "empty",
+			outputs:  map[string]string{"context inspect empty": `[{"Name":"empty","Endpoints":{}}]`},
+			wantArgs: []string{"context", "inspect", "empty"},
+			wantErr:  true,
+		},
+		{
+			name:     "invalid output",
+			context:  "invalid",
+			outputs:  map[string]string{"context inspect invalid": `not json`},
+			wantArgs: []string{"context", "inspect", "invalid"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := mockDockerCLI(t, tt.outputs)
+
+			host, err := contextHost(tt.context)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+			} else if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if d := cmp.Diff(tt.wantHost, host); d != "" {
+				t.Errorf("host mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff([][]string{tt.wantArgs}, *calls); d != "" {
+				t.Errorf("docker cli args mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestListContexts(t *testing.T) {
+	mockDockerCLI(t, map[string]string{"context ls --format {{json .}}": contextList})
+
+	contexts, err := listContexts()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	want := []Context{
+		{Name: "colima", Host: "unix:///Users/test/.colima/default/docker.sock"},
+		{Name: "default", Host: "unix:///var/run/docker.sock", Current: true},
+		{Name: "desktop-linux", Host: "unix:///Users/test/.docker/run/docker.sock"},
+	}
+	if d := cmp.Diff(want, contexts); d != "" {
+		t.Errorf("contexts mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestListContexts_Err(t *testing.T) {
+	t.Run("docker cli error", func(t *testing.T) {
+		mockDockerCLI(t, map[string]string{})
+		if _, err := listContexts(); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("invalid output", func(t *testing.T) {
+		mockDockerCLI(t, map[string]string{"context ls --format {{json .}}": "NAME DESCRIPTION\n"})
+		if _, err := listContexts(); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestContextGuidance(t *testing.T) {
+	contexts := []Context{
+		{Name: "colima", Host: "unix:///Users/test/.colima/default/docker.sock"},
+		{Name: "default", Host: "unix:///var/run/docker.sock", Current: true},
+		{Name: "desktop-linux", Host: "unix:///Users/test/.docker/run/docker.sock"},
+	}
+
+	want := `Unable to connect to the docker host unix:///var/run/docker.sock of the current docker context 'default'.
+The current docker context may not be the expected one, the other available docker contexts are:
+  - colima (unix:///Users/test/.colima/default/docker.sock)
+  - desktop-linux (unix:///Users/test/.docker/run/docker.sock)
+Try a specific docker context with --docker-context <NAME>, e.g. --docker-context colima`
+	if d := cmp.Diff(want, contextGuidance("unix:///var/run/docker.sock", contexts)); d != "" {
+		t.Errorf("guidance mismatch (-want +got):\n%s", d)
+	}
+
+	// nothing to suggest when the current context is the only one
+	if d := cmp.Diff("", contextGuidance("unix:///var/run/docker.sock", contexts[1:2])); d != "" {
+		t.Errorf("guidance mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,22 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// desktopPausedMessage is the start of the error the docker engine of a paused Docker Desktop returns to every request.
+const desktopPausedMessage = "docker desktop is manually paused"
+
+// DesktopPaused returns true if the err, as returned by the docker engine, is caused by Docker Desktop being paused.
+func DesktopPaused(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), desktopPausedMessage)
+}
+
+// RestartDesktop restarts Docker Desktop with the docker cli, which also resumes a paused docker engine.
+func RestartDesktop() error {
+	if _, err := dockerCLI("desktop", "restart"); err != nil {
+		return fmt.Errorf("unable to restart docker desktop: %w", err)
+	}
+	return nil
+}
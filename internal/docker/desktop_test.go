@@ -0,0 +1,43 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDesktopPaused(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "no error"},
+		{name: "unrelated error", err: errors.New("Cannot connect to the Docker daemon at unix:///var/run/docker.sock")},
+		{
+			name: "paused",
+			err:  errors.New("Error response from daemon: Docker Desktop is manually paused. Unpause it through the Whale menu or the Dashboard."),
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, DesktopPaused(tt.err)); d != "" {
+				t.Errorf("paused mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestRestartDesktop(t *testing.T) {
+	calls := mockDockerCLI(t, map[string]string{"desktop restart": ""})
+
+	if err := RestartDesktop(); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([][]string{{"desktop", "restart"}}, *calls); d != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", d)
+	}
+}
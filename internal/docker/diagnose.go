@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+)
+
+// Diagnosis describes the docker engine abctl connects to, see Diagnose.
+type Diagnosis struct {
+	// Host is the docker host abctl connected to.
+	Host string
+	// Context is the docker context selected with SetContext, empty if the host was discovered.
+	Context string
+	Version Version
+	// OS is the operating system of the docker engine, e.g. Docker Desktop.
+	OS string
+	// Rootless is true if the docker engine runs without root privileges.
+	Rootless bool
+	// NCPU and MemTotal are the cpus and bytes of memory available to the docker engine.
+	NCPU     int
+	MemTotal int64
+}
+
+// Diagnose runs the full docker host discovery of New, then describes the docker engine it connected to.
+// Every error returned includes the ErrDocker error in its chain. The diagnosis is filled in as far as it got.
+func Diagnose(ctx context.Context) (Diagnosis, error) {
+	return diagnose(ctx, newClientPing, runtime.GOOS)
+}
+
+func diagnose(ctx context.Context, newPing newPing, goos string) (Diagnosis, error) {
+	diag := Diagnosis{Context: selectedContext}
+
+	d, err := newWithOptions(ctx, newPing, goos)
+	if err != nil {
+		return diag, err
+	}
+	diag.Host = d.DaemonHost()
+
+	if diag.Version, err = d.Version(ctx); err != nil {
+		if DesktopPaused(err) {
+			return diag, fmt.Errorf("%w: docker desktop is paused: %w", abctl.ErrDocker, err)
+		}
+		return diag, fmt.Errorf("%w: %w", abctl.ErrDocker, err)
+	}
+
+	info, err := d.Client.Info(ctx)
+	if err != nil {
+		return diag, fmt.Errorf("%w: unable to determine docker info: %w", abctl.ErrDocker, err)
+	}
+	diag.OS = info.OperatingSystem
+	diag.Rootless = slices.Contains(info.SecurityOptions, "name=rootless")
+	diag.NCPU = info.NCPU
+	diag.MemTotal = info.MemTotal
+
+	return diag, nil
+}
@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+)
+
+func TestDiagnose(t *testing.T) {
+	origContextHost := dockerContextHost
+	t.Cleanup(func() { dockerContextHost = origContextHost })
+	dockerContextHost = func() string { return "unix:///var/run/docker.sock" }
+	mockDockerCLI(t, nil)
+
+	info := system.Info{
+		OperatingSystem: "Docker Desktop",
+		SecurityOptions: []string{"name=seccomp,profile=builtin", "name=rootless"},
+		NCPU:            4,
+		MemTotal:        8 << 30,
+	}
+	version := Version{
+		Version:  dockertest.DefaultServerVersion.Version,
+		Arch:     dockertest.DefaultServerVersion.Arch,
+		Platform: dockertest.DefaultServerVersion.Platform.Name,
+	}
+
+	tests := []struct {
+		name    string
+		ping    func(ctx context.Context) (types.Ping, error)
+		version func(ctx context.Context) (types.Version, error)
+		info    func(ctx context.Context) (system.Info, error)
+		want    Diagnosis
+		wantErr string
+	}{
+		{
+			name: "success",
+			want: Diagnosis{
+				Host:     "unix:///var/run/docker.sock",
+				Version:  version,
+				OS:       "Docker Desktop",
+				Rootless: true,
+				NCPU:     4,
+				MemTotal: 8 << 30,
+			},
+		},
+		{
+			name: "unreachable",
+			ping: func(ctx context.Context) (types.Ping, error) {
+				return types.Ping{}, errors.New("connection refused")
+			},
+			wantErr: "error communicating with docker: unable to create docker client",
+		},
+		{
+			name: "desktop paused",
+			version: func(ctx context.Context) (types.Version, error) {
+				return types.Version{}, errors.New("Docker Desktop is manually paused. Unpause it through the Whale menu.")
+			},
+			want:    Diagnosis{Host: "unix:///var/run/docker.sock"},
+			wantErr: "error communicating with docker: docker desktop is paused: unable to determine server version: Docker Desktop is manually paused. Unpause it through the Whale menu.",
+		},
+		{
+			name: "version error",
+			version: func(ctx context.Context) (types.Version, error) {
+				return types.Version{}, errors.New("test error")
+			},
+			want:    Diagnosis{Host: "unix:///var/run/docker.sock"},
+			wantErr: "error communicating with docker: unable to determine server version: test error",
+		},
+		{
+			name: "info error",
+			info: func(ctx context.Context) (system.Info, error) {
+				return system.Info{}, errors.New("test error")
+			},
+			want:    Diagnosis{Host: "unix:///var/run/docker.sock", Version: version},
+			wantErr: "error communicating with docker: unable to determine docker info: test error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := dockertest.NewMockClient()
+			mockClient.FnInfo = func(ctx context.Context) (system.Info, error) { return info, nil }
+			if tt.version != nil {
+				mockClient.FnServerVersion = tt.version
+			}
+			if tt.info != nil {
+				mockClient.FnInfo = tt.info
+			}
+
+			f := func(opts ...client.Opt) (pinger, error) {
+				return mockPinger{MockClient: mockClient, ping: tt.ping}, nil
+			}
+
+			got, err := diagnose(context.Background(), f, "linux")
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("diagnosis mismatch (-want +got):\n%s", d)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if !errors.Is(err, abctl.ErrDocker) {
+				t.Errorf("expected ErrDocker but got %v", err)
+			}
+			if d := cmp.Diff(tt.wantErr, err.Error()); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+)
+
+// DiskUsageCategory is the disk space used by one type of docker resource.
+type DiskUsageCategory struct {
+	// Count is the number of resources.
+	Count int
+	// Active is the number of resources in use, e.g. images used by a container or running containers.
+	Active int
+	// Size is the disk space, in bytes, used by the resources.
+	Size int64
+	// Reclaimable is the disk space, in bytes, which removing the unused resources would reclaim.
+	Reclaimable int64
+}
+
+// DiskUsage is the disk space used by docker, calculated the same way as `docker system df`.
+type DiskUsage struct {
+	Images     DiskUsageCategory
+	Containers DiskUsageCategory
+	Volumes    DiskUsageCategory
+	BuildCache DiskUsageCategory
+
+	// images are kept to select the unused images, as only the disk usage reports how many containers use an image.
+	images []*image.Summary
+}
+
+// Size returns the total disk space, in bytes, used by docker.
+func (u DiskUsage) Size() int64 {
+	return u.Images.Size + u.Containers.Size + u.Volumes.Size + u.BuildCache.Size
+}
+
+// Reclaimable returns the total disk space, in bytes, which removing every unused resource would reclaim.
+func (u DiskUsage) Reclaimable() int64 {
+	return u.Images.Reclaimable + u.Containers.Reclaimable + u.Volumes.Reclaimable + u.BuildCache.Reclaimable
+}
+
+// DiskUsage returns the disk space used by docker's images, containers, volumes and build cache.
+func (d *Docker) DiskUsage(ctx context.Context) (DiskUsage, error) {
+	du, err := d.Client.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("unable to determine disk usage: %w", err)
+	}
+	return newDiskUsage(du), nil
+}
+
+// newDiskUsage summarizes the raw disk usage. Sizes docker couldn't calculate, reported as -1, are skipped.
+func newDiskUsage(du types.DiskUsage) DiskUsage {
+	u := DiskUsage{images: du.Images}
+
+	// images share layers, so the total is the size of all layers, and only the layers unique to the images
+	// in use are kept when the unused ones are removed
+	u.Images.Count, u.Images.Size = len(du.Images), du.LayersSize
+	var used int64
+	for _, img := range du.Images {
+		if img.Containers == 0 {
+			continue
+		}
+		u.Images.Active++
+		if img.Size != -1 && img.SharedSize != -1 {
+			used += img.Size - img.SharedSize
+		}
+	}
+	u.Images.Reclaimable = max(u.Images.Size-used, 0)
+
+	u.Containers.Count = len(du.Containers)
+	for _, c := range du.Containers {
+		u.Containers.Size += c.SizeRw
+		switch c.State {
+		case "running", "paused", "restarting":
+			u.Containers.Active++
+		default:
+			u.Containers.Reclaimable += c.SizeRw
+		}
+	}
+
+	u.Volumes.Count = len(du.Volumes)
+	for _, v := range du.Volumes {
+		if v.UsageData == nil || v.UsageData.Size == -1 {
+			continue
+		}
+		u.Volumes.Size += v.UsageData.Size
+		if v.UsageData.RefCount > 0 {
+			u.Volumes.Active++
+		} else {
+			u.Volumes.Reclaimable += v.UsageData.Size
+		}
+	}
+
+	u.BuildCache.Count = len(du.BuildCache)
+	for _, bc := range du.BuildCache {
+		if bc.InUse {
+			u.BuildCache.Active++
+		}
+		if bc.Shared {
+			continue
+		}
+		u.BuildCache.Size += bc.Size
+		if !bc.InUse {
+			u.BuildCache.Reclaimable += bc.Size
+		}
+	}
+
+	return u
+}
+
+// UnusedImage is an image which no container uses.
+type UnusedImage struct {
+	ID string
+	// Refs are the references the image is removed by, either the tracked references it matches,
+	// or its ID for a dangling image.
+	Refs []string
+	// Size is the disk space, in bytes, which removing the image reclaims. This is zero if the image
+	// is also tagged with another reference, as removing the references only untags it.
+	Size int64
+}
+
+// UnusedImages returns the images which no container uses and which are either tagged with one of the refs,
+// or are dangling images of the same repository as one of the refs, e.g. left behind when a newer image
+// was pulled with the same tag. No other image is ever returned.
+func (u DiskUsage) UnusedImages(refs []string) []UnusedImage {
+	parsed := make([]imageRef, len(refs))
+	repos := make(map[string]bool, len(refs))
+	for i, ref := range refs {
+		parsed[i] = parseImageRef(ref)
+		repos[parsed[i].name] = true
+	}
+
+	var unused []UnusedImage
+	for _, img := range u.images {
+		// a count of -1 means docker didn't determine whether the image is in use
+		if img.Containers != 0 {
+			continue
+		}
+
+		var matched []string
+		for i, r := range parsed {
+			if r.matches(*img) {
+				matched = append(matched, refs[i])
+			}
+		}
+
+		switch {
+		case len(matched) > 0:
+			size := uniqueSize(*img)
+			if !onlyTaggedWith(*img, matched) {
+				size = 0
+			}
+			unused = append(unused, UnusedImage{ID: img.ID, Refs: matched, Size: size})
+		case dangling(*img) && slices.ContainsFunc(img.RepoDigests, func(d string) bool { return repos[parseImageRef(d).name] }):
+			unused = append(unused, UnusedImage{ID: img.ID, Refs: []string{img.ID}, Size: uniqueSize(*img)})
+		}
+	}
+
+	return unused
+}
+
+// uniqueSize returns the size of the layers which belong only to this image.
+func uniqueSize(img image.Summary) int64 {
+	if img.SharedSize > 0 {
+		return img.Size - img.SharedSize
+	}
+	return img.Size
+}
+
+// onlyTaggedWith returns true if every tag of the image is matched by one of the refs.
+func onlyTaggedWith(img image.Summary, refs []string) bool {
+	for _, tag := range img.RepoTags {
+		t := parseImageRef(tag)
+		if !slices.ContainsFunc(refs, func(ref string) bool {
+			r := parseImageRef(ref)
+			return r.name == t.name && r.tag == t.tag
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// dangling returns true if the image is no longer tagged.
+func dangling(img image.Summary) bool {
+	return len(img.RepoTags) == 0 || slices.Equal(img.RepoTags, []string{"<none>:<none>"})
+}
+
+// RemoveUnusedImage removes the unused image by each of its references. Images are never force removed,
+// so an image which has since started being used by a container is kept and returns an error.
+func (d *Docker) RemoveUnusedImage(ctx context.Context, img UnusedImage) error {
+	for _, ref := range img.Refs {
+		if _, err := d.Client.ImageRemove(ctx, ref, image.RemoveOptions{PruneChildren: true}); err != nil {
+			return fmt.Errorf("unable to remove image %s: %w", ref, err)
+		}
+	}
+	return nil
+}
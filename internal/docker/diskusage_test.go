@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDocker_DiskUsage(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnDiskUsage: func(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+			return types.DiskUsage{
+				LayersSize: 1000,
+				Images: []*image.Summary{
+					{ID: "sha256:1", Size: 400, SharedSize: 100, Containers: 1},
+					{ID: "sha256:2", Size: 300, SharedSize: 100, Containers: 0},
+					// sizes docker couldn't calculate are skipped
+					{ID: "sha256:3", Size: -1, SharedSize: -1, Containers: 2},
+				},
+				Containers: []*types.Container{
+					{ID: "running", State: "running", SizeRw: 10},
+					{ID: "paused", State: "paused", SizeRw: 20},
+					{ID: "exited", State: "exited", SizeRw: 30},
+					{ID: "created", State: "created", SizeRw: 40},
+				},
+				Volumes: []*volume.Volume{
+					{Name: "used", UsageData: &volume.UsageData{RefCount: 1, Size: 50}},
+					{Name: "unused", UsageData: &volume.UsageData{RefCount: 0, Size: 60}},
+					{Name: "unknown", UsageData: &volume.UsageData{RefCount: -1, Size: -1}},
+					{Name: "no-usage"},
+				},
+				BuildCache: []*types.BuildCache{
+					{ID: "in-use", InUse: true, Size: 70},
+					{ID: "unused", Size: 80},
+					{ID: "shared", Shared: true, Size: 90},
+				},
+			}, nil
+		},
+	}}
+
+	u, err := d.DiskUsage(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	want := map[string]DiskUsageCategory{
+		// only the layers unique to the image in use, 400-100, are kept
+		"images":      {Count: 3, Active: 2, Size: 1000, Reclaimable: 700},
+		"containers":  {Count: 4, Active: 2, Size: 100, Reclaimable: 70},
+		"volumes":     {Count: 4, Active: 1, Size: 110, Reclaimable: 60},
+		"build cache": {Count: 3, Active: 1, Size: 150, Reclaimable: 80},
+	}
+	got := map[string]DiskUsageCategory{
+		"images":      u.Images,
+		"containers":  u.Containers,
+		"volumes":     u.Volumes,
+		"build cache": u.BuildCache,
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("disk usage mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(int64(1360), u.Size()); d != "" {
+		t.Errorf("size mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(int64(910), u.Reclaimable()); d != "" {
+		t.Errorf("reclaimable mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestDocker_DiskUsage_Error(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnDiskUsage: func(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+			return types.DiskUsage{}, errors.New("test error")
+		},
+	}}
+
+	if _, err := d.DiskUsage(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestDiskUsage_UnusedImages(t *testing.T) {
+	const digest = "sha256:0123456789012345678901234567890123456789012345678901234567890123"
+
+	u := newDiskUsage(types.DiskUsage{
+		Images: []*image.Summary{
+			// pulled by abctl and unused
+			{ID: "sha256:server", RepoTags: []string{"airbyte/server:1.0.0"}, Size: 300, SharedSize: 100},
+			// pulled by abctl, but still used by a container
+			{ID: "sha256:worker", RepoTags: []string{"airbyte/worker:1.0.0"}, Size: 200, Containers: 1},
+			// pulled by abctl, also tagged by the user, so removing it only untags it
+			{ID: "sha256:bootloader", RepoTags: []string{"airbyte/bootloader:1.0.0", "mine:latest"}, Size: 100},
+			// pulled by abctl, but docker didn't determine whether it is in use
+			{ID: "sha256:cron", RepoTags: []string{"airbyte/cron:1.0.0"}, Size: 100, Containers: -1},
+			// a dangling image of a repository pulled by abctl
+			{ID: "sha256:old-server", RepoTags: []string{"<none>:<none>"}, RepoDigests: []string{"airbyte/server@" + digest}, Size: 250},
+			// a dangling image of a repository the user pulled
+			{ID: "sha256:old-postgres", RepoDigests: []string{"postgres@" + digest}, Size: 500},
+			// pulled by the user
+			{ID: "sha256:postgres", RepoTags: []string{"postgres:13"}, Size: 400},
+		},
+	})
+
+	got := u.UnusedImages([]string{"airbyte/server:1.0.0", "airbyte/worker:1.0.0", "airbyte/bootloader:1.0.0", "airbyte/cron:1.0.0"})
+	want := []UnusedImage{
+		{ID: "sha256:server", Refs: []string{"airbyte/server:1.0.0"}, Size: 200},
+		{ID: "sha256:bootloader", Refs: []string{"airbyte/bootloader:1.0.0"}, Size: 0},
+		{ID: "sha256:old-server", Refs: []string{"sha256:old-server"}, Size: 250},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("unused images mismatch (-want +got):\n%s", d)
+	}
+
+	// nothing tracked, nothing is ever selected
+	if got := u.UnusedImages(nil); len(got) != 0 {
+		t.Errorf("expected no unused images, got %v", got)
+	}
+}
+
+func TestDocker_RemoveUnusedImage(t *testing.T) {
+	var removed []string
+	d := &Docker{Client: dockertest.MockClient{
+		FnImageRemove: func(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error) {
+			if options.Force {
+				t.Error("images must never be force removed")
+			}
+			removed = append(removed, imageID)
+			return nil, nil
+		},
+	}}
+
+	img := UnusedImage{ID: "sha256:1", Refs: []string{"airbyte/server:1.0.0", "airbyte/server:latest"}}
+	if err := d.RemoveUnusedImage(context.Background(), img); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(img.Refs, removed); d != "" {
+		t.Errorf("removed images mismatch (-want +got):\n%s", d)
+	}
+}
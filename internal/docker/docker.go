@@ -2,16 +2,20 @@ package docker
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
-	"os/exec"
+	"os"
+	"regexp"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/http"
 	"github.com/airbytehq/abctl/internal/paths"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/system"
@@ -36,9 +40,12 @@ type Version struct {
 type Client interface {
 	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error)
 	ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	ContainerList(ctx context.Context, options container.ListOptions) ([]types.Container, error)
+	ContainerLogs(ctx context.Context, container string, options container.LogsOptions) (io.ReadCloser, error)
 	ContainerRemove(ctx context.Context, container string, options container.RemoveOptions) error
 	ContainerStart(ctx context.Context, container string, options container.StartOptions) error
 	ContainerStop(ctx context.Context, container string, options container.StopOptions) error
+	ContainerUnpause(ctx context.Context, container string) error
 	CopyFromContainer(ctx context.Context, container, srcPath string) (io.ReadCloser, container.PathStat, error)
 
 	ContainerExecCreate(ctx context.Context, container string, config container.ExecOptions) (types.IDResponse, error)
@@ -47,8 +54,13 @@ type Client interface {
 
 	ImageList(ctx context.Context, options image.ListOptions) ([]image.Summary, error)
 	ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+	ImageRemove(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error)
 	ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error)
+	ImageTag(ctx context.Context, source, target string) error
 
+	DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
+	Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
+	NetworkInspect(ctx context.Context, networkID string, options network.InspectOptions) (network.Inspect, error)
 	ServerVersion(ctx context.Context) (types.Version, error)
 	VolumeInspect(ctx context.Context, volumeID string) (volume.Volume, error)
 	Info(ctx context.Context) (system.Info, error)
@@ -60,22 +72,24 @@ var _ Client = (*client.Client)(nil)
 // Can be created with default settings by calling New or with a custom Client by manually instantiating this type.
 type Docker struct {
 	Client Client
+	// Host is the docker host the Client was connected to by New, empty if the Client was provided manually.
+	Host string
 }
 
 // New returns a new Docker type with a default Client implementation.
 func New(ctx context.Context) (*Docker, error) {
-	// convert the client.NewClientWithOpts to a newPing function
-	f := func(opts ...client.Opt) (pinger, error) {
-		var p pinger
-		var err error
-		p, err = client.NewClientWithOpts(opts...)
-		if err != nil {
-			return nil, err
-		}
-		return p, nil
-	}
+	return newWithOptions(ctx, newClientPing, runtime.GOOS)
+}
 
-	return newWithOptions(ctx, f, runtime.GOOS)
+// newClientPing converts the client.NewClientWithOpts to a newPing function.
+func newClientPing(opts ...client.Opt) (pinger, error) {
+	var p pinger
+	var err error
+	p, err = client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 // newPing exists for testing purposes.
@@ -91,31 +105,86 @@ type pinger interface {
 
 var _ pinger = (*client.Client)(nil)
 
+// apiVersion, when set, pins the docker api version instead of negotiating it with the docker daemon.
+var apiVersion string
+
+// apiVersionRegex matches the supported api version format, e.g. 1.45
+var apiVersionRegex = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// SetAPIVersion pins the docker api version used by all clients created by New, disabling the api version negotiation.
+// An empty version restores the default negotiation.
+func SetAPIVersion(version string) error {
+	if version != "" && !apiVersionRegex.MatchString(version) {
+		return fmt.Errorf("invalid docker api version '%s': must be in the format <MAJOR>.<MINOR> (e.g. 1.45)", version)
+	}
+	apiVersion = version
+	return nil
+}
+
+// withAPIVersionNegotiation exists for testing purposes.
+var withAPIVersionNegotiation = client.WithAPIVersionNegotiation
+
+// windowsProbeTimeout limits how long each windows docker host is probed for.
+// Connecting to a named pipe which isn't being served can hang indefinitely.
+var windowsProbeTimeout = 5 * time.Second
+
+// getenv exists for testing purposes.
+var getenv = os.Getenv
+
+// dockerContextHost returns the host of the current docker context, or an empty string if it cannot be determined.
+// Exists as a variable for testing purposes.
+var dockerContextHost = func() string {
+	// The "docker context inspect" command describes the current context in detail.
+	host, err := contextHost("")
+	if err != nil {
+		pterm.Debug.Printfln("unable to determine the docker host of the current docker context: %s", err)
+		return ""
+	}
+	return host
+}
+
+// preferHostEnv makes a DOCKER_HOST environment variable take precedence over the docker context selected with
+// SetContext.
+var preferHostEnv bool
+
+// SetPreferHostEnv sets whether a DOCKER_HOST environment variable takes precedence over the docker context selected
+// with SetContext, which otherwise ignores it.
+func SetPreferHostEnv(prefer bool) {
+	preferHostEnv = prefer
+}
+
+// runningUnderWSL returns true if this process was started through WSL interop.
+func runningUnderWSL() bool {
+	return getenv("WSL_DISTRO_NAME") != "" || getenv("WSL_INTEROP") != ""
+}
+
 // newWithOptions allows for the docker client to be injected for testing purposes.
+// The docker host is, in order of precedence, the host of the docker context selected with SetContext, the
+// DOCKER_HOST environment variable, or the first of the potential hosts which responds, see SetPreferHostEnv.
 func newWithOptions(ctx context.Context, newPing newPing, goos string) (*Docker, error) {
+	envHost := getenv(client.EnvOverrideHost)
+	if selectedContext != "" && (envHost == "" || !preferHostEnv) {
+		if envHost != "" {
+			pterm.Debug.Printfln("ignoring the DOCKER_HOST %s, as docker context '%s' is selected", envHost, selectedContext)
+		}
+		return newWithContext(ctx, newPing, goos, selectedContext)
+	}
+	if envHost != "" {
+		return newWithEnvHost(ctx, newPing, goos, envHost)
+	}
 
 	var potentialHosts []string
 
-	// The best guess at the docker host comes from the "docker context inspect" command,
-	// which describes the current context in detail.
-	if out, err := exec.Command("docker", "context", "inspect").Output(); err == nil {
-		var data []struct {
-			Endpoints struct {
-				Docker struct {
-					Host string
-				} `json:"docker"`
-			}
-		}
-		if err := json.Unmarshal(out, &data); err == nil {
-			if len(data) > 0 && data[0].Endpoints.Docker.Host != "" {
-				potentialHosts = append(potentialHosts, data[0].Endpoints.Docker.Host)
-			}
-		}
+	// The best guess at the docker host comes from the "docker context inspect" command.
+	ctxHost := dockerContextHost()
+	if ctxHost != "" {
+		potentialHosts = append(potentialHosts, ctxHost)
 	}
 
 	// If the code above fails, then fall back to some educated guesses.
 	// Unfortunately, these can easily be wrong if the user is using a non-standard
 	// docker context, or if we've missed any common installation configs here.
+	var probeTimeout time.Duration
 	switch goos {
 	case "darwin":
 		potentialHosts = append(potentialHosts,
@@ -123,7 +192,17 @@ func newWithOptions(ctx context.Context, newPing newPing, goos string) (*Docker,
 			fmt.Sprintf("unix://%s/.docker/run/docker.sock", paths.UserHome),
 		)
 	case "windows":
-		potentialHosts = append(potentialHosts, "npipe:////./pipe/docker_engine")
+		probeTimeout = windowsProbeTimeout
+		potentialHosts = append(potentialHosts,
+			"npipe:////./pipe/docker_engine",
+			"npipe:////./pipe/dockerDesktopLinuxEngine",
+			// Docker Desktop's "Expose daemon on tcp://localhost:2375 without TLS" setting
+			"tcp://localhost:2375",
+		)
+		// With the WSL2 backend, the docker socket may only be reachable from within WSL.
+		if runningUnderWSL() {
+			potentialHosts = append(potentialHosts, "unix:///var/run/docker.sock")
+		}
 	default:
 		potentialHosts = append(potentialHosts,
 			"unix:///var/run/docker.sock",
@@ -131,42 +210,180 @@ func newWithOptions(ctx context.Context, newPing newPing, goos string) (*Docker,
 		)
 	}
 
-	// Do not sample Docker traces. Dockers Net/HTTP client has Otel instrumentation enabled.
-	// URL's and other fields may contain PII, or sensitive information.
-	noopTraceProvider := trace.NewTracerProvider(
-		trace.WithSampler(trace.NeverSample()),
-	)
-
-	dockerOpts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation(), client.WithTraceProvider(noopTraceProvider)}
-
 	for _, host := range potentialHosts {
-		dockerCli, err := createAndPing(ctx, newPing, host, dockerOpts)
+		dockerCli, err := createAndPing(ctx, newPing, host, probeTimeout)
 		if err != nil {
 			pterm.Debug.Printfln("error connecting to docker host %s: %s", host, err)
 		} else {
-			return &Docker{Client: dockerCli}, nil
+			pterm.Debug.Printfln("connected to docker host %s using the %s transport", host, hostTransport(host))
+			return &Docker{Client: dockerCli, Host: host}, nil
+		}
+	}
+
+	// The current docker context may not be the one the user expects, e.g. when several docker engines are installed.
+	if ctxHost != "" {
+		if contexts, err := listContexts(); err != nil {
+			pterm.Debug.Println(err)
+		} else if guidance := contextGuidance(ctxHost, contexts); guidance != "" {
+			pterm.Warning.Println(guidance)
 		}
 	}
 
 	return nil, fmt.Errorf("%w: unable to create docker client", abctl.ErrDocker)
 }
 
-// createAndPing attempts to create a docker client and ping it to ensure we can communicate
-func createAndPing(ctx context.Context, newPing newPing, host string, opts []client.Opt) (Client, error) {
-	// Pass client.WithHost first to ensure it runs prior to the client.FromEnv call.
-	// We want the DOCKER_HOST to be used if it has been specified, overriding our host.
-	cli, err := newPing(append([]client.Opt{client.WithHost(host)}, opts...)...)
+// newWithContext returns a new Docker type connected to the host of the named docker context.
+// No other hosts are tried, as the docker context was explicitly selected.
+func newWithContext(ctx context.Context, newPing newPing, goos, name string) (*Docker, error) {
+	host, err := contextHost(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to resolve docker context '%s': %w", abctl.ErrDocker, name, err)
+	}
+
+	var probeTimeout time.Duration
+	if goos == "windows" {
+		probeTimeout = windowsProbeTimeout
+	}
+
+	dockerCli, err := createAndPing(ctx, newPing, host, probeTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to the docker host %s of docker context '%s': %w", abctl.ErrDocker, host, name, err)
+	}
+	pterm.Debug.Printfln("connected to docker host %s of docker context '%s' using the %s transport", host, name, hostTransport(host))
+
+	return &Docker{Client: dockerCli, Host: host}, nil
+}
+
+// newWithEnvHost returns a new Docker type connected to the host of the DOCKER_HOST environment variable.
+// No other hosts are tried, as the docker host was explicitly set.
+func newWithEnvHost(ctx context.Context, newPing newPing, goos, host string) (*Docker, error) {
+	var probeTimeout time.Duration
+	if goos == "windows" {
+		probeTimeout = windowsProbeTimeout
+	}
+
+	dockerCli, err := createAndPing(ctx, newPing, host, probeTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to connect to the docker host %s of DOCKER_HOST: %w", abctl.ErrDocker, host, err)
+	}
+	pterm.Debug.Printfln("connected to docker host %s of DOCKER_HOST using the %s transport", host, hostTransport(host))
+
+	return &Docker{Client: dockerCli, Host: host}, nil
+}
+
+// clientOpts returns the options every docker client of the host is created with.
+func clientOpts(host string) []client.Opt {
+	// Do not sample Docker traces. Dockers Net/HTTP client has Otel instrumentation enabled.
+	// URL's and other fields may contain PII, or sensitive information.
+	noopTraceProvider := trace.NewTracerProvider(
+		trace.WithSampler(trace.NeverSample()),
+	)
+
+	// The host was already resolved by newWithOptions, honoring DOCKER_HOST, so it must override the DOCKER_HOST
+	// applied by client.FromEnv. It's applied before the tls options, which only apply to a tcp host.
+	opts := []client.Opt{client.FromEnv, client.WithHost(host)}
+	if apiVersion != "" {
+		pterm.Debug.Printfln("using docker api version %s, api version negotiation is disabled", apiVersion)
+		opts = append(opts, client.WithVersion(apiVersion))
+	} else {
+		opts = append(opts, withAPIVersionNegotiation())
+	}
+	// after client.FromEnv, so the tls options take precedence over DOCKER_CERT_PATH
+	if tlsClientConfig != nil {
+		opts = append(opts, withTLSClientConfig(tlsClientConfig))
+	}
+	return append(opts, client.WithUserAgent(http.UserAgent()), client.WithTraceProvider(noopTraceProvider))
+}
+
+// hostTransport returns the transport (e.g. npipe, tcp, unix) of the docker host.
+func hostTransport(host string) string {
+	if idx := strings.Index(host, "://"); idx > 0 {
+		return host[:idx]
+	}
+	return "unknown"
+}
+
+// createAndPing attempts to create a docker client and ping it to ensure we can communicate.
+// If timeout is non-zero, the ping will fail if it does not complete within the timeout.
+func createAndPing(ctx context.Context, newPing newPing, host string, timeout time.Duration) (Client, error) {
+	cli, err := newPing(clientOpts(host)...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create docker client: %w", err)
 	}
 
-	if _, err := cli.Ping(ctx); err != nil {
+	pingCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		pingCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// A paused engine (e.g. Docker Desktop's Resource Saver) accepts the connection but only responds once woken.
+	start := time.Now()
+	slow := time.AfterFunc(slowPingThreshold, func() {
+		pterm.Info.Println("Waiting for Docker to respond, it may be paused by Docker Desktop's Resource Saver")
+	})
+	ping, err := cli.Ping(pingCtx)
+	slow.Stop()
+	if err != nil {
 		return nil, fmt.Errorf("unable to ping docker client: %w", err)
 	}
+	pterm.Debug.Printfln("docker host %s supports api version %s", host, ping.APIVersion)
+
+	if time.Since(start) >= slowPingThreshold {
+		if err := waitResponsive(ctx, cli); err != nil {
+			return nil, err
+		}
+	}
 
 	return cli, nil
 }
 
+// slowPingThreshold is the ping duration which indicates the docker engine was paused.
+// A responsive engine answers a ping in milliseconds.
+var slowPingThreshold = 2 * time.Second
+
+// wakeTimeout limits how long waitResponsive waits for a waking docker engine.
+var wakeTimeout = time.Minute
+
+// wakeInterval is the delay between the pings of waitResponsive.
+var wakeInterval = 500 * time.Millisecond
+
+// waitResponsive pings the docker engine until it answers within the slowPingThreshold.
+// The first ping to a paused engine only starts waking it, the engine may not be ready for work until later pings are fast.
+func waitResponsive(ctx context.Context, cli pinger) error {
+	pterm.Info.Println("Docker was slow to respond, waiting for it to wake")
+
+	ctx, cancel := context.WithTimeout(ctx, wakeTimeout)
+	defer cancel()
+
+	for {
+		pingCtx, pingCancel := context.WithTimeout(ctx, slowPingThreshold)
+		_, err := cli.Ping(pingCtx)
+		pingCancel()
+		if err == nil {
+			pterm.Debug.Println("docker is responsive")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			pterm.Warning.Printfln("Docker did not become responsive within %s.\n"+
+				"If Docker Desktop's Resource Saver is enabled, open Docker Desktop to wake it and try again.", wakeTimeout)
+			return fmt.Errorf("%w: docker did not become responsive within %s", abctl.ErrDocker, wakeTimeout)
+		case <-time.After(wakeInterval):
+		}
+	}
+}
+
+// DaemonHost returns the docker host the Client is connected to, the Host if the Client doesn't report it.
+func (d *Docker) DaemonHost() string {
+	if c, ok := d.Client.(interface{ DaemonHost() string }); ok {
+		return c.DaemonHost()
+	}
+	return d.Host
+}
+
 // Version returns the version information from the underlying docker process.
 func (d *Docker) Version(ctx context.Context) (Version, error) {
 	ver, err := d.Client.ServerVersion(ctx)
@@ -3,10 +3,15 @@ package docker
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/build"
 	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	abctlhttp "github.com/airbytehq/abctl/internal/http"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	"github.com/google/go-cmp/cmp"
@@ -57,7 +62,7 @@ func TestNewWithOptions(t *testing.T) {
 			f := func(opts ...client.Opt) (pinger, error) {
 				// as go doesn't have a way to compare to functions, count the number of functions we have
 				// and compare those instead
-				if d := cmp.Diff(4, len(opts)); d != "" {
+				if d := cmp.Diff(5, len(opts)); d != "" {
 					t.Error("unexpected client option count options", d)
 				}
 
@@ -98,7 +103,7 @@ func TestNewWithOptions_InitErr(t *testing.T) {
 		{
 			name:        "windows",
 			goos:        "windows",
-			expAttempts: 2,
+			expAttempts: 4,
 		},
 		{
 			name:        "linux",
@@ -209,6 +214,438 @@ func TestNewWithOptions_DarwinPingErrFirstAttemptOnly(t *testing.T) {
 	}
 }
 
+func TestNewWithOptions_Windows(t *testing.T) {
+	origContextHost, origGetenv, origTimeout := dockerContextHost, getenv, windowsProbeTimeout
+	t.Cleanup(func() {
+		dockerContextHost, getenv, windowsProbeTimeout = origContextHost, origGetenv, origTimeout
+	})
+	dockerContextHost = func() string { return "" }
+	windowsProbeTimeout = 50 * time.Millisecond
+
+	tests := []struct {
+		name string
+		// wsl, if true, simulates running under WSL interop
+		wsl bool
+		// succeedOn is the ping attempt (1-indexed) which succeeds, 0 if no attempt succeeds
+		succeedOn   int
+		expAttempts int
+		// expHost, if set, is the host of the client which succeeded
+		expHost string
+		expErr  bool
+	}{
+		{
+			name:        "npipe",
+			succeedOn:   1,
+			expAttempts: 1,
+		},
+		{
+			name:        "tcp",
+			succeedOn:   3,
+			expAttempts: 3,
+			expHost:     "tcp://localhost:2375",
+		},
+		{
+			name:        "wsl socket",
+			wsl:         true,
+			succeedOn:   4,
+			expAttempts: 4,
+			expHost:     "unix:///var/run/docker.sock",
+		},
+		{
+			name:        "no wsl socket outside of wsl",
+			expAttempts: 3,
+			expErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv = func(key string) string {
+				if tt.wsl && key == "WSL_DISTRO_NAME" {
+					return "Ubuntu"
+				}
+				return ""
+			}
+
+			attempts := 0
+			p := mockPinger{
+				ping: func(ctx context.Context) (types.Ping, error) {
+					attempts++
+					if attempts == tt.succeedOn {
+						return types.Ping{}, nil
+					}
+					// simulate a hanging named pipe, only the probe timeout can end this ping
+					<-ctx.Done()
+					return types.Ping{}, ctx.Err()
+				},
+			}
+			// the npipe hosts can't be created outside of windows, only the others are recorded
+			var host string
+			f := func(opts ...client.Opt) (pinger, error) {
+				if c, err := client.NewClientWithOpts(opts...); err == nil {
+					host = c.DaemonHost()
+				}
+				return p, nil
+			}
+
+			cli, err := newWithOptions(context.Background(), f, "windows")
+			if tt.expErr {
+				if !errors.Is(err, abctl.ErrDocker) {
+					t.Errorf("expected ErrDocker but got %v", err)
+				}
+			} else if err != nil || cli == nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if d := cmp.Diff(tt.expAttempts, attempts); d != "" {
+				t.Error("unexpected attempts", d)
+			}
+			if tt.expHost != "" {
+				if d := cmp.Diff(tt.expHost, host); d != "" {
+					t.Errorf("host mismatch (-want +got):\n%s", d)
+				}
+			}
+		})
+	}
+}
+
+func TestNewWithOptions_ResourceSaver(t *testing.T) {
+	origContextHost, origThreshold, origTimeout, origInterval := dockerContextHost, slowPingThreshold, wakeTimeout, wakeInterval
+	t.Cleanup(func() {
+		dockerContextHost, slowPingThreshold, wakeTimeout, wakeInterval = origContextHost, origThreshold, origTimeout, origInterval
+	})
+	dockerContextHost = func() string { return "" }
+	slowPingThreshold = 20 * time.Millisecond
+	wakeTimeout = 500 * time.Millisecond
+	wakeInterval = time.Millisecond
+
+	tests := []struct {
+		name string
+		// slowPings is the number of pings which take longer than the slowPingThreshold, -1 if every ping is slow
+		slowPings   int
+		expAttempts int
+		expErr      bool
+	}{
+		{
+			name:        "responsive",
+			expAttempts: 1,
+		},
+		{
+			name:        "woken by the first ping",
+			slowPings:   1,
+			expAttempts: 2,
+		},
+		{
+			name:        "slow to wake",
+			slowPings:   3,
+			expAttempts: 4,
+		},
+		{
+			name:      "never wakes",
+			slowPings: -1,
+			expErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			p := mockPinger{
+				ping: func(ctx context.Context) (types.Ping, error) {
+					attempts++
+					if tt.slowPings >= 0 && attempts > tt.slowPings {
+						return types.Ping{}, nil
+					}
+					// simulate a waking engine, the first ping has no deadline and eventually completes
+					select {
+					case <-time.After(2 * slowPingThreshold):
+						return types.Ping{}, nil
+					case <-ctx.Done():
+						return types.Ping{}, ctx.Err()
+					}
+				},
+			}
+			f := func(opts ...client.Opt) (pinger, error) { return p, nil }
+
+			// linux has multiple potential hosts, only the first is expected to be used unless it never wakes
+			cli, err := newWithOptions(context.Background(), f, "linux")
+			if tt.expErr {
+				if !errors.Is(err, abctl.ErrDocker) {
+					t.Errorf("expected ErrDocker but got %v", err)
+				}
+				return
+			}
+			if err != nil || cli == nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if d := cmp.Diff(tt.expAttempts, attempts); d != "" {
+				t.Error("unexpected attempts", d)
+			}
+		})
+	}
+}
+
+func TestSetAPIVersion(t *testing.T) {
+	t.Cleanup(func() { apiVersion = "" })
+
+	for _, v := range []string{"1.45", "1.24", ""} {
+		if err := SetAPIVersion(v); err != nil {
+			t.Errorf("unexpected error for %q: %s", v, err)
+		}
+	}
+
+	for _, v := range []string{"1", "v1.45", "1.45.0", "latest"} {
+		if err := SetAPIVersion(v); err == nil {
+			t.Errorf("expected error for %q", v)
+		}
+	}
+}
+
+func TestNewWithOptions_APIVersion(t *testing.T) {
+	origContextHost, origNegotiation := dockerContextHost, withAPIVersionNegotiation
+	t.Cleanup(func() {
+		dockerContextHost, withAPIVersionNegotiation, apiVersion = origContextHost, origNegotiation, ""
+	})
+	dockerContextHost = func() string { return "" }
+
+	tests := []struct {
+		name           string
+		apiVersion     string
+		expNegotiation bool
+	}{
+		{
+			name:           "negotiated",
+			expNegotiation: true,
+		},
+		{
+			name:       "pinned",
+			apiVersion: "1.41",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetAPIVersion(tt.apiVersion); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			negotiation := false
+			withAPIVersionNegotiation = func() client.Opt {
+				negotiation = true
+				return origNegotiation()
+			}
+
+			f := func(opts ...client.Opt) (pinger, error) {
+				// build a real client from the options to determine the version they configure
+				cli, err := client.NewClientWithOpts(opts...)
+				if err != nil {
+					t.Fatal("unable to create client", err)
+				}
+				if tt.apiVersion != "" {
+					if d := cmp.Diff(tt.apiVersion, cli.ClientVersion()); d != "" {
+						t.Error("unexpected client version", d)
+					}
+				}
+				return mockPinger{}, nil
+			}
+
+			if _, err := newWithOptions(context.Background(), f, "linux"); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.expNegotiation, negotiation); d != "" {
+				t.Error("unexpected api version negotiation", d)
+			}
+		})
+	}
+}
+
+func TestNewWithOptions_Context(t *testing.T) {
+	origContextHost := dockerContextHost
+	t.Cleanup(func() {
+		dockerContextHost = origContextHost
+		SetContext("")
+	})
+	// the current docker context must not be used when a context is selected
+	dockerContextHost = func() string {
+		t.Error("unexpected lookup of the current docker context")
+		return ""
+	}
+	calls := mockDockerCLI(t, map[string]string{"context inspect colima": inspectColima})
+
+	t.Run("selected context", func(t *testing.T) {
+		*calls = nil
+		SetContext("colima")
+		attempts := 0
+		f := func(opts ...client.Opt) (pinger, error) {
+			attempts++
+			return mockPinger{MockClient: dockertest.NewMockClient()}, nil
+		}
+
+		if _, err := newWithOptions(context.Background(), f, "darwin"); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		// only the host of the selected context is tried
+		if d := cmp.Diff(1, attempts); d != "" {
+			t.Error("unexpected attempts", d)
+		}
+		if d := cmp.Diff([][]string{{"context", "inspect", "colima"}}, *calls); d != "" {
+			t.Error("unexpected docker cli calls", d)
+		}
+	})
+
+	t.Run("unknown context", func(t *testing.T) {
+		SetContext("missing")
+		attempts := 0
+		f := func(opts ...client.Opt) (pinger, error) {
+			attempts++
+			return mockPinger{MockClient: dockertest.NewMockClient()}, nil
+		}
+
+		_, err := newWithOptions(context.Background(), f, "darwin")
+		if d := cmp.Diff(true, errors.Is(err, abctl.ErrDocker)); d != "" {
+			t.Error("unexpected error, should be ErrDocker", d)
+		}
+		if d := cmp.Diff(0, attempts); d != "" {
+			t.Error("unexpected attempts", d)
+		}
+	})
+
+	t.Run("ping error", func(t *testing.T) {
+		SetContext("colima")
+		attempts := 0
+		f := func(opts ...client.Opt) (pinger, error) {
+			attempts++
+			return mockPinger{
+				MockClient: dockertest.NewMockClient(),
+				ping: func(ctx context.Context) (types.Ping, error) {
+					return types.Ping{}, errors.New("test error")
+				},
+			}, nil
+		}
+
+		_, err := newWithOptions(context.Background(), f, "darwin")
+		if d := cmp.Diff(true, errors.Is(err, abctl.ErrDocker)); d != "" {
+			t.Error("unexpected error, should be ErrDocker", d)
+		}
+		// no fallback hosts are tried
+		if d := cmp.Diff(1, attempts); d != "" {
+			t.Error("unexpected attempts", d)
+		}
+	})
+}
+
+func TestNewWithOptions_DockerHost(t *testing.T) {
+	const envHost = "tcp://10.0.0.5:2375"
+
+	origContextHost := dockerContextHost
+	t.Cleanup(func() {
+		dockerContextHost = origContextHost
+		SetContext("")
+		SetPreferHostEnv(false)
+	})
+	dockerContextHost = func() string { return "unix:///var/run/docker.sock" }
+	mockDockerCLI(t, map[string]string{"context inspect colima": inspectColima})
+	t.Setenv(client.EnvOverrideHost, envHost)
+
+	tests := []struct {
+		name    string
+		context string
+		prefer  bool
+		expHost string
+	}{
+		{
+			name:    "no context",
+			expHost: envHost,
+		},
+		{
+			name:    "no context preferring DOCKER_HOST",
+			prefer:  true,
+			expHost: envHost,
+		},
+		{
+			name:    "selected context",
+			context: "colima",
+			expHost: "unix:///Users/test/.colima/default/docker.sock",
+		},
+		{
+			name:    "selected context preferring DOCKER_HOST",
+			context: "colima",
+			prefer:  true,
+			expHost: envHost,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetContext(tt.context)
+			SetPreferHostEnv(tt.prefer)
+
+			// the hosts the clients were created for, after applying every option
+			var hosts []string
+			f := func(opts ...client.Opt) (pinger, error) {
+				cli, err := client.NewClientWithOpts(opts...)
+				if err != nil {
+					t.Fatal("unable to create client", err)
+				}
+				hosts = append(hosts, cli.DaemonHost())
+				return mockPinger{MockClient: dockertest.NewMockClient()}, nil
+			}
+
+			dc, err := newWithOptions(context.Background(), f, "darwin")
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			// only the winning host is tried, and the client is connected to the host reported
+			if d := cmp.Diff([]string{tt.expHost}, hosts); d != "" {
+				t.Error("unexpected hosts", d)
+			}
+			if d := cmp.Diff(tt.expHost, dc.Host); d != "" {
+				t.Error("unexpected host", d)
+			}
+		})
+	}
+}
+
+func TestNewWithOptions_DockerHostPingErr(t *testing.T) {
+	t.Setenv(client.EnvOverrideHost, "tcp://10.0.0.5:2375")
+
+	attempts := 0
+	f := func(opts ...client.Opt) (pinger, error) {
+		attempts++
+		return mockPinger{
+			MockClient: dockertest.NewMockClient(),
+			ping: func(ctx context.Context) (types.Ping, error) {
+				return types.Ping{}, errors.New("test error")
+			},
+		}, nil
+	}
+
+	_, err := newWithOptions(context.Background(), f, "darwin")
+	if d := cmp.Diff(true, errors.Is(err, abctl.ErrDocker)); d != "" {
+		t.Error("unexpected error, should be ErrDocker", d)
+	}
+	// the potential hosts are not tried, as the DOCKER_HOST was explicitly set
+	if d := cmp.Diff(1, attempts); d != "" {
+		t.Error("unexpected attempts", d)
+	}
+}
+
+func TestHostTransport(t *testing.T) {
+	tests := map[string]string{
+		"npipe:////./pipe/docker_engine": "npipe",
+		"tcp://localhost:2375":           "tcp",
+		"unix:///var/run/docker.sock":    "unix",
+		"docker.sock":                    "unknown",
+	}
+
+	for host, exp := range tests {
+		if d := cmp.Diff(exp, hostTransport(host)); d != "" {
+			t.Errorf("unexpected transport for %s: %s", host, d)
+		}
+	}
+}
+
 func TestVersion_Err(t *testing.T) {
 	ctx := context.Background()
 	p := mockPinger{
@@ -247,3 +684,50 @@ func (m mockPinger) Ping(ctx context.Context) (types.Ping, error) {
 
 	return m.ping(ctx)
 }
+
+func TestClientOpts_UserAgent(t *testing.T) {
+	t.Cleanup(func() { abctlhttp.SetUserAgent("") })
+
+	tests := []struct {
+		name     string
+		override string
+		expUA    string
+	}{
+		{
+			name:  "default",
+			expUA: build.UserAgent(),
+		},
+		{
+			name:     "override",
+			override: "acme-egress/1.0",
+			expUA:    "acme-egress/1.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			abctlhttp.SetUserAgent(tt.override)
+
+			var capturedUA string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedUA = r.Header.Get("User-Agent")
+				w.Header().Set("API-Version", "1.45")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			cli, err := client.NewClientWithOpts(clientOpts("tcp://" + server.Listener.Addr().String())...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cli.Close()
+
+			if _, err := cli.Ping(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if d := cmp.Diff(tt.expUA, capturedUA); d != "" {
+				t.Errorf("user agent mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
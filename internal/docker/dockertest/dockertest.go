@@ -6,6 +6,7 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/system"
@@ -17,16 +18,24 @@ import (
 type MockClient struct {
 	FnContainerCreate      func(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error)
 	FnContainerInspect     func(ctx context.Context, containerID string) (types.ContainerJSON, error)
+	FnContainerList        func(ctx context.Context, options container.ListOptions) ([]types.Container, error)
+	FnContainerLogs        func(ctx context.Context, container string, options container.LogsOptions) (io.ReadCloser, error)
 	FnContainerRemove      func(ctx context.Context, container string, options container.RemoveOptions) error
 	FnContainerStart       func(ctx context.Context, container string, options container.StartOptions) error
 	FnContainerStop        func(ctx context.Context, container string, options container.StopOptions) error
+	FnContainerUnpause     func(ctx context.Context, container string) error
 	FnCopyFromContainer    func(ctx context.Context, container, srcPath string) (io.ReadCloser, container.PathStat, error)
 	FnContainerExecCreate  func(ctx context.Context, container string, config container.ExecOptions) (types.IDResponse, error)
 	FnContainerExecInspect func(ctx context.Context, execID string) (container.ExecInspect, error)
 	FnContainerExecStart   func(ctx context.Context, execID string, config container.ExecStartOptions) error
 	FnImageList            func(ctx context.Context, options image.ListOptions) ([]image.Summary, error)
 	FnImagePull            func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error)
+	FnImageRemove          func(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error)
 	FnImageSave            func(ctx context.Context, imageIDs []string) (io.ReadCloser, error)
+	FnImageTag             func(ctx context.Context, source, target string) error
+	FnDiskUsage            func(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error)
+	FnEvents               func(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error)
+	FnNetworkInspect       func(ctx context.Context, networkID string, options network.InspectOptions) (network.Inspect, error)
 	FnServerVersion        func(ctx context.Context) (types.Version, error)
 	FnVolumeInspect        func(ctx context.Context, volumeID string) (volume.Volume, error)
 	FnInfo                 func(ctx context.Context) (system.Info, error)
@@ -47,6 +56,14 @@ func (m MockClient) ContainerInspect(ctx context.Context, containerID string) (t
 	return m.FnContainerInspect(ctx, containerID)
 }
 
+func (m MockClient) ContainerList(ctx context.Context, options container.ListOptions) ([]types.Container, error) {
+	return m.FnContainerList(ctx, options)
+}
+
+func (m MockClient) ContainerLogs(ctx context.Context, container string, options container.LogsOptions) (io.ReadCloser, error) {
+	return m.FnContainerLogs(ctx, container, options)
+}
+
 func (m MockClient) ContainerRemove(ctx context.Context, container string, options container.RemoveOptions) error {
 	return m.FnContainerRemove(ctx, container, options)
 }
@@ -59,6 +76,10 @@ func (m MockClient) ContainerStop(ctx context.Context, container string, options
 	return m.FnContainerStop(ctx, container, options)
 }
 
+func (m MockClient) ContainerUnpause(ctx context.Context, container string) error {
+	return m.FnContainerUnpause(ctx, container)
+}
+
 func (m MockClient) CopyFromContainer(ctx context.Context, container, srcPath string) (io.ReadCloser, container.PathStat, error) {
 	return m.FnCopyFromContainer(ctx, container, srcPath)
 }
@@ -83,8 +104,28 @@ func (m MockClient) ImagePull(ctx context.Context, refStr string, options image.
 	return m.FnImagePull(ctx, refStr, options)
 }
 
+func (m MockClient) ImageRemove(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error) {
+	return m.FnImageRemove(ctx, imageID, options)
+}
+
 func (m MockClient) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
-	return m.ImageSave(ctx, imageIDs)
+	return m.FnImageSave(ctx, imageIDs)
+}
+
+func (m MockClient) ImageTag(ctx context.Context, source, target string) error {
+	return m.FnImageTag(ctx, source, target)
+}
+
+func (m MockClient) DiskUsage(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+	return m.FnDiskUsage(ctx, options)
+}
+
+func (m MockClient) Events(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+	return m.FnEvents(ctx, options)
+}
+
+func (m MockClient) NetworkInspect(ctx context.Context, networkID string, options network.InspectOptions) (network.Inspect, error) {
+	return m.FnNetworkInspect(ctx, networkID, options)
 }
 
 func (m MockClient) ServerVersion(ctx context.Context) (types.Version, error) {
@@ -0,0 +1,77 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/pterm/pterm"
+)
+
+// WatchContainer subscribes to the engine events of the named container, calling onFailure with the failure once
+// the container stops, e.g. when the engine OOM-kills it. A stopped container which ran out of memory fails with
+// an ErrNodeOOMKilled error, otherwise with an ErrDocker error. The returned function unsubscribes, it must be called
+// once the container is no longer expected to be running.
+func (d *Docker) WatchContainer(ctx context.Context, name string, onFailure func(error)) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	msgs, errs := d.Client.Events(ctx, events.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", string(events.ContainerEventType)),
+			filters.Arg("container", name),
+			filters.Arg("event", string(events.ActionOOM)),
+			filters.Arg("event", string(events.ActionDie)),
+		),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var oom bool
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-errs:
+				if ctx.Err() == nil {
+					pterm.Debug.Printfln("Stopped watching the events of container %s: %s", name, err)
+				}
+				return
+			case msg := <-msgs:
+				switch msg.Action {
+				case events.ActionOOM:
+					// the kernel may only have killed a process within the container, which keeps running
+					oom = true
+					pterm.Warning.Printfln("Container %s ran out of memory, a process within it was killed", name)
+				case events.ActionDie:
+					onFailure(d.containerFailure(ctx, name, msg, oom))
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// containerFailure returns the failure of the named container which stopped with the die event msg.
+// The container ran out of memory if an oom event preceded it, or its state reports it was OOM-killed.
+func (d *Docker) containerFailure(ctx context.Context, name string, msg events.Message, oom bool) error {
+	exitCode := msg.Actor.Attributes["exitCode"]
+	if ci, err := d.Client.ContainerInspect(ctx, name); err != nil {
+		pterm.Debug.Printfln("Unable to inspect the stopped container %s: %s", name, err)
+	} else if ci.ContainerJSONBase != nil && ci.State != nil {
+		oom = oom || ci.State.OOMKilled
+		exitCode = strconv.Itoa(ci.State.ExitCode)
+	}
+
+	if oom {
+		return fmt.Errorf("%w: container %s stopped with exit code %s", abctl.ErrNodeOOMKilled, name, exitCode)
+	}
+	return fmt.Errorf("%w: container %s stopped unexpectedly with exit code %s", abctl.ErrDocker, name, exitCode)
+}
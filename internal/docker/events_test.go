@@ -0,0 +1,90 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+)
+
+// eventsClient returns a mock client streaming the msgs, and reporting the state, of a container.
+func eventsClient(t *testing.T, msgs []events.Message, state types.ContainerState) dockertest.MockClient {
+	return dockertest.MockClient{
+		FnEvents: func(ctx context.Context, options events.ListOptions) (<-chan events.Message, <-chan error) {
+			if !options.Filters.ExactMatch("container", "airbyte-abctl-control-plane") {
+				t.Errorf("events should be filtered by the container: %v", options.Filters)
+			}
+			msgCh := make(chan events.Message)
+			errCh := make(chan error, 1)
+			go func() {
+				for _, msg := range msgs {
+					select {
+					case msgCh <- msg:
+					case <-ctx.Done():
+						errCh <- ctx.Err()
+						return
+					}
+				}
+				<-ctx.Done()
+				errCh <- ctx.Err()
+			}()
+			return msgCh, errCh
+		},
+		FnContainerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{State: &state}}, nil
+		},
+	}
+}
+
+func TestDocker_WatchContainer(t *testing.T) {
+	die := events.Message{Action: events.ActionDie, Actor: events.Actor{Attributes: map[string]string{"exitCode": "137"}}}
+
+	tests := []struct {
+		name    string
+		msgs    []events.Message
+		state   types.ContainerState
+		wantErr error
+	}{
+		{name: "oom event", msgs: []events.Message{{Action: events.ActionOOM}, die}, wantErr: abctl.ErrNodeOOMKilled},
+		{name: "oom killed state", msgs: []events.Message{die}, state: types.ContainerState{OOMKilled: true, ExitCode: 137}, wantErr: abctl.ErrNodeOOMKilled},
+		{name: "stopped", msgs: []events.Message{die}, state: types.ContainerState{ExitCode: 1}, wantErr: abctl.ErrDocker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Docker{Client: eventsClient(t, tt.msgs, tt.state)}
+
+			failures := make(chan error, 1)
+			stop := d.WatchContainer(context.Background(), "airbyte-abctl-control-plane", func(err error) { failures <- err })
+			defer stop()
+
+			select {
+			case err := <-failures:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v but got %v", tt.wantErr, err)
+				}
+				if tt.wantErr == abctl.ErrDocker && errors.Is(err, abctl.ErrNodeOOMKilled) {
+					t.Error("a container which didn't run out of memory should not be reported as OOM-killed")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected a failure")
+			}
+		})
+	}
+}
+
+func TestDocker_WatchContainer_OOMWithoutDie(t *testing.T) {
+	d := &Docker{Client: eventsClient(t, []events.Message{{Action: events.ActionOOM}}, types.ContainerState{Running: true})}
+
+	stop := d.WatchContainer(context.Background(), "airbyte-abctl-control-plane", func(err error) {
+		t.Errorf("a container which keeps running should not fail: %v", err)
+	})
+	time.Sleep(50 * time.Millisecond)
+	// unsubscribing must not block, nor report a failure
+	stop()
+}
@@ -0,0 +1,214 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+)
+
+const (
+	defaultRegistry = "docker.io"
+	defaultTag      = "latest"
+)
+
+// imageRef is a normalized image reference.
+type imageRef struct {
+	// name is the fully qualified name, including the registry, e.g. docker.io/library/postgres
+	name   string
+	tag    string
+	digest string
+}
+
+// parseImageRef normalizes an image reference the same way docker does, qualifying the name with the
+// default registry, adding the library/ prefix to official images, and defaulting the tag to latest
+// when neither a tag nor a digest is provided.
+func parseImageRef(ref string) imageRef {
+	var r imageRef
+
+	name := ref
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, r.digest = name[:i], name[i+1:]
+	}
+	// a colon after the last slash separates the tag, any other colon belongs to a registry port
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, r.tag = name[:i], name[i+1:]
+	}
+	if r.tag == "" && r.digest == "" {
+		r.tag = defaultTag
+	}
+
+	domain, path, found := strings.Cut(name, "/")
+	if !found || (!strings.ContainsAny(domain, ".:") && domain != "localhost") {
+		domain, path = defaultRegistry, name
+	}
+	if domain == "index.docker.io" {
+		domain = defaultRegistry
+	}
+	if domain == defaultRegistry && !strings.Contains(path, "/") {
+		path = "library/" + path
+	}
+	r.name = domain + "/" + path
+
+	return r
+}
+
+// imageRefRegexp matches an image reference of the form [registry[:port]/]name[:tag][@digest].
+var imageRefRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9.-]+(?::[0-9]+)?/)?[a-z0-9]+(?:[._-]+[a-z0-9]+)*(?:/[a-z0-9]+(?:[._-]+[a-z0-9]+)*)*(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`)
+
+// ValidateImageRef returns an error if the image reference is not a valid reference, e.g. airbyte/source-postgres:3.6.0.
+func ValidateImageRef(ref string) error {
+	if !imageRefRegexp.MatchString(ref) {
+		return fmt.Errorf("invalid image reference '%s'", ref)
+	}
+	return nil
+}
+
+func (r imageRef) String() string {
+	s := r.name
+	if r.tag != "" {
+		s += ":" + r.tag
+	}
+	if r.digest != "" {
+		s += "@" + r.digest
+	}
+	return s
+}
+
+// matches returns true if the image summary refers to the same image as the reference.
+// References containing a digest are matched against the RepoDigests, otherwise the RepoTags are matched.
+func (r imageRef) matches(summary image.Summary) bool {
+	if r.digest != "" {
+		for _, d := range summary.RepoDigests {
+			if other := parseImageRef(d); other.name == r.name && other.digest == r.digest {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, t := range summary.RepoTags {
+		if other := parseImageRef(t); other.name == r.name && other.tag == r.tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageExists returns true if the image reference is present locally, either by its tag or by its digest.
+func (d *Docker) ImageExists(ctx context.Context, ref string) (bool, error) {
+	images, err := d.Client.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return false, fmt.Errorf("unable to list images: %w", err)
+	}
+
+	r := parseImageRef(ref)
+	for _, summary := range images {
+		if r.matches(summary) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ImagePullRequired returns true if the image reference should be pulled. Images using the latest tag are
+// always pulled, as the tag may have moved, all other images are only pulled if they are not present locally.
+func (d *Docker) ImagePullRequired(ctx context.Context, ref string) (bool, error) {
+	if r := parseImageRef(ref); r.digest == "" && r.tag == defaultTag {
+		return true, nil
+	}
+
+	exists, err := d.ImageExists(ctx, ref)
+	if err != nil {
+		return true, err
+	}
+	return !exists, nil
+}
+
+// ImageStatus is whether a required image is present locally.
+type ImageStatus string
+
+const (
+	// ImagePresent is an image which is present, at the required digest if one was provided.
+	ImagePresent ImageStatus = "present"
+	// ImageMissing is an image which isn't present.
+	ImageMissing ImageStatus = "missing"
+	// ImageDigestMismatch is an image whose tag is present, but not at the required digest.
+	ImageDigestMismatch ImageStatus = "digest mismatch"
+)
+
+// ImageVerification is the status of a required image reference.
+type ImageVerification struct {
+	Ref    string
+	Status ImageStatus
+	// Digests are the digests of the image present for the tag of a reference whose digest mismatches.
+	Digests []string
+}
+
+// VerifyImages returns the status of each of the required image references, listing the local images only once.
+// A reference with a tag and a digest requires the image of the tag to have the digest. As an image loaded from an
+// archive has no repository digests, its ID is accepted as its digest as well.
+func (d *Docker) VerifyImages(ctx context.Context, refs []string) ([]ImageVerification, error) {
+	images, err := d.Client.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list images: %w", err)
+	}
+
+	verifications := make([]ImageVerification, len(refs))
+	for i, ref := range refs {
+		verifications[i] = verifyImage(parseImageRef(ref), images)
+		verifications[i].Ref = ref
+	}
+	return verifications, nil
+}
+
+// verifyImage returns the status of the image reference among the images.
+func verifyImage(r imageRef, images []image.Summary) ImageVerification {
+	if r.digest == "" {
+		for _, summary := range images {
+			if r.matches(summary) {
+				return ImageVerification{Status: ImagePresent}
+			}
+		}
+		return ImageVerification{Status: ImageMissing}
+	}
+
+	// the image of the tag must be the one of the digest
+	if r.tag != "" {
+		tagged := imageRef{name: r.name, tag: r.tag}
+		for _, summary := range images {
+			if !tagged.matches(summary) {
+				continue
+			}
+			if summary.ID == r.digest || r.matches(summary) {
+				return ImageVerification{Status: ImagePresent}
+			}
+			return ImageVerification{Status: ImageDigestMismatch, Digests: imageDigests(summary)}
+		}
+	}
+
+	for _, summary := range images {
+		if summary.ID == r.digest || r.matches(summary) {
+			return ImageVerification{Status: ImagePresent}
+		}
+	}
+	return ImageVerification{Status: ImageMissing}
+}
+
+// imageDigests returns the repository digests of the image, or its ID if it has none.
+func imageDigests(summary image.Summary) []string {
+	var digests []string
+	for _, d := range summary.RepoDigests {
+		if digest := parseImageRef(d).digest; digest != "" && !slices.Contains(digests, digest) {
+			digests = append(digests, digest)
+		}
+	}
+	if len(digests) == 0 {
+		return []string{summary.ID}
+	}
+	return digests
+}
@@ -0,0 +1,219 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types/image"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{ref: "postgres", want: "docker.io/library/postgres:latest"},
+		{ref: "postgres:13", want: "docker.io/library/postgres:13"},
+		{ref: "library/postgres:13", want: "docker.io/library/postgres:13"},
+		{ref: "docker.io/postgres:13", want: "docker.io/library/postgres:13"},
+		{ref: "index.docker.io/library/postgres:13", want: "docker.io/library/postgres:13"},
+		{ref: "airbyte/server:1.0.0", want: "docker.io/airbyte/server:1.0.0"},
+		{ref: "airbyte/server@sha256:abc", want: "docker.io/airbyte/server@sha256:abc"},
+		{ref: "airbyte/server:1.0.0@sha256:abc", want: "docker.io/airbyte/server:1.0.0@sha256:abc"},
+		{ref: "ghcr.io/airbytehq/server:1.0.0", want: "ghcr.io/airbytehq/server:1.0.0"},
+		{ref: "localhost:5000/server", want: "localhost:5000/server:latest"},
+		{ref: "localhost/server:1.0.0", want: "localhost/server:1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, parseImageRef(tt.ref).String()); d != "" {
+				t.Errorf("ref mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDocker_ImageExists(t *testing.T) {
+	summaries := []image.Summary{
+		{
+			ID:       "sha256:1",
+			RepoTags: []string{"postgres:13", "airbyte/server:1.0.0"},
+		},
+		{
+			// only present by digest, e.g. pulled with a digest reference
+			ID:          "sha256:2",
+			RepoDigests: []string{"airbyte/worker@sha256:def"},
+		},
+	}
+
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{ref: "postgres:13", want: true},
+		{ref: "docker.io/library/postgres:13", want: true},
+		{ref: "postgres", want: false},
+		{ref: "postgres:17", want: false},
+		{ref: "docker.io/airbyte/server:1.0.0", want: true},
+		{ref: "airbyte/server:1.0.1", want: false},
+		{ref: "airbyte/worker@sha256:def", want: true},
+		{ref: "airbyte/worker:1.0.0@sha256:def", want: true},
+		{ref: "airbyte/worker@sha256:000", want: false},
+		{ref: "airbyte/worker:1.0.0", want: false},
+	}
+
+	d := &Docker{Client: dockertest.MockClient{
+		FnImageList: func(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+			return summaries, nil
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			exists, err := d.ImageExists(context.Background(), tt.ref)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, exists); d != "" {
+				t.Errorf("exists mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDocker_ImagePullRequired(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnImageList: func(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+			return []image.Summary{{RepoTags: []string{"postgres:13", "postgres:latest"}}}, nil
+		},
+	}}
+
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{ref: "postgres:13", want: false},
+		{ref: "postgres:17", want: true},
+		// latest is always pulled, even when present
+		{ref: "postgres", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			required, err := d.ImagePullRequired(context.Background(), tt.ref)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, required); d != "" {
+				t.Errorf("required mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDocker_ImageExists_Error(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnImageList: func(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+			return nil, errors.New("test")
+		},
+	}}
+
+	if _, err := d.ImageExists(context.Background(), "postgres:13"); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestDocker_VerifyImages(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnImageList: func(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+			return []image.Summary{
+				{ID: "sha256:1", RepoTags: []string{"airbyte/server:1.0.0"}, RepoDigests: []string{"airbyte/server@sha256:abc"}},
+				{ID: "sha256:2", RepoTags: []string{"airbyte/worker:1.0.0"}, RepoDigests: []string{"airbyte/worker@sha256:def"}},
+				// loaded from an archive, so without repository digests
+				{ID: "sha256:3", RepoTags: []string{"airbyte/bootloader:1.0.0"}},
+				{ID: "sha256:4", RepoDigests: []string{"airbyte/cron@sha256:ghi"}},
+			}, nil
+		},
+	}}
+
+	refs := []string{
+		"airbyte/server:1.0.0",
+		"docker.io/airbyte/server:1.0.0@sha256:abc",
+		"airbyte/worker:1.0.0@sha256:000",
+		"airbyte/bootloader:1.0.0@sha256:3",
+		"airbyte/bootloader:1.0.0@sha256:999",
+		"airbyte/cron@sha256:ghi",
+		"airbyte/cron:1.0.0@sha256:ghi",
+		"airbyte/cron@sha256:000",
+		"airbyte/webapp:1.0.0",
+	}
+
+	got, err := d.VerifyImages(context.Background(), refs)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	want := []ImageVerification{
+		{Ref: "airbyte/server:1.0.0", Status: ImagePresent},
+		{Ref: "docker.io/airbyte/server:1.0.0@sha256:abc", Status: ImagePresent},
+		{Ref: "airbyte/worker:1.0.0@sha256:000", Status: ImageDigestMismatch, Digests: []string{"sha256:def"}},
+		{Ref: "airbyte/bootloader:1.0.0@sha256:3", Status: ImagePresent},
+		{Ref: "airbyte/bootloader:1.0.0@sha256:999", Status: ImageDigestMismatch, Digests: []string{"sha256:3"}},
+		{Ref: "airbyte/cron@sha256:ghi", Status: ImagePresent},
+		// the tag isn't present, but the image of the digest is
+		{Ref: "airbyte/cron:1.0.0@sha256:ghi", Status: ImagePresent},
+		{Ref: "airbyte/cron@sha256:000", Status: ImageMissing},
+		{Ref: "airbyte/webapp:1.0.0", Status: ImageMissing},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("verifications mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestDocker_VerifyImages_Error(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnImageList: func(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+			return nil, errors.New("test error")
+		},
+	}}
+
+	if _, err := d.VerifyImages(context.Background(), []string{"airbyte/server:1.0.0"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestValidateImageRef(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	tests := []struct {
+		ref     string
+		wantErr bool
+	}{
+		{ref: "postgres"},
+		{ref: "airbyte/source-postgres:3.6.0"},
+		{ref: "docker.io/airbyte/source-postgres:3.6.0"},
+		{ref: "ghcr.io/airbytehq/source_faker:latest"},
+		{ref: "localhost:5000/source-faker:6.2.0"},
+		{ref: "airbyte/source-postgres@" + digest},
+		{ref: "airbyte/source-postgres:3.6.0@" + digest},
+		{ref: "", wantErr: true},
+		{ref: "airbyte/Source-Postgres:3.6.0", wantErr: true},
+		{ref: "airbyte/source-postgres:", wantErr: true},
+		{ref: "airbyte/source-postgres@sha256:abc", wantErr: true},
+		{ref: "airbyte/source postgres", wantErr: true},
+		{ref: "airbyte//source-postgres", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if err := ValidateImageRef(tt.ref); tt.wantErr != (err != nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
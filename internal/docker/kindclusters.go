@@ -0,0 +1,36 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// KindClusters returns the sorted names of the kind clusters with a node container, running or not.
+func (d *Docker) KindClusters(ctx context.Context) ([]string, error) {
+	containers, err := d.Client.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", KindClusterLabel)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list containers: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var clusters []string
+	for _, c := range containers {
+		// a cluster with several nodes has a container per node
+		cluster := c.Labels[KindClusterLabel]
+		if cluster == "" || seen[cluster] {
+			continue
+		}
+		seen[cluster] = true
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+
+	return clusters, nil
+}
@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDocker_KindClusters(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnContainerList: func(ctx context.Context, options container.ListOptions) ([]types.Container, error) {
+			if !options.All {
+				t.Error("stopped containers should be listed")
+			}
+			if !options.Filters.ExactMatch("label", KindClusterLabel) {
+				t.Errorf("containers should be filtered by the kind label: %v", options.Filters)
+			}
+			return []types.Container{
+				{ID: "1", Labels: map[string]string{KindClusterLabel: "test-airbyte-abctl"}},
+				{ID: "2", Labels: map[string]string{KindClusterLabel: "airbyte-abctl"}},
+				{ID: "3", Labels: map[string]string{KindClusterLabel: "test-airbyte-abctl"}},
+				{ID: "4", Labels: map[string]string{"app": "web"}},
+			}, nil
+		},
+	}}
+
+	got, err := d.KindClusters(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]string{"airbyte-abctl", "test-airbyte-abctl"}, got); d != "" {
+		t.Errorf("clusters mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestDocker_KindClusters_Error(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnContainerList: func(ctx context.Context, options container.ListOptions) ([]types.Container, error) {
+			return nil, errors.New("test error")
+		},
+	}}
+
+	if _, err := d.KindClusters(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// nodeLogsOptions returns the options for reading the last lines of a container's logs, every line if lines isn't positive.
+func nodeLogsOptions(lines int) container.LogsOptions {
+	tail := "all"
+	if lines > 0 {
+		tail = strconv.Itoa(lines)
+	}
+
+	return container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Tail:       tail,
+	}
+}
+
+// TailNodeLogs returns the last lines of the logs of the node container id, which include the output of the
+// node's own services (e.g. kubelet and containerd). All the logs are returned if lines isn't positive.
+// The stdout and stderr of the container are interleaved in the order they were written.
+func (d *Docker) TailNodeLogs(ctx context.Context, id string, lines int) (string, error) {
+	ci, err := d.Client.ContainerInspect(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("unable to inspect container %s: %w", id, err)
+	}
+
+	rdr, err := d.Client.ContainerLogs(ctx, id, nodeLogsOptions(lines))
+	if err != nil {
+		return "", fmt.Errorf("unable to get logs for container %s: %w", id, err)
+	}
+	defer rdr.Close()
+
+	var buf bytes.Buffer
+	// containers without a tty multiplex stdout and stderr into a single stream, which must be demultiplexed
+	if ci.Config != nil && ci.Config.Tty {
+		_, err = io.Copy(&buf, rdr)
+	} else {
+		_, err = stdcopy.StdCopy(&buf, &buf, rdr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to read logs for container %s: %w", id, err)
+	}
+
+	return buf.String(), nil
+}
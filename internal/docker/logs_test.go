@@ -0,0 +1,139 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNodeLogsOptions(t *testing.T) {
+	tests := []struct {
+		lines int
+		want  container.LogsOptions
+	}{
+		{lines: 100, want: container.LogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true, Tail: "100"}},
+		{lines: 0, want: container.LogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true, Tail: "all"}},
+		{lines: -1, want: container.LogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true, Tail: "all"}},
+	}
+
+	for _, tt := range tests {
+		if d := cmp.Diff(tt.want, nodeLogsOptions(tt.lines)); d != "" {
+			t.Errorf("options mismatch for %d lines (-want +got):\n%s", tt.lines, d)
+		}
+	}
+}
+
+// multiplexed returns the stdout and stderr in the multiplexed stream format of a container without a tty.
+func multiplexed(t *testing.T, stdout, stderr string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte(stdout)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdcopy.NewStdWriter(&buf, stdcopy.Stderr).Write([]byte(stderr)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDocker_TailNodeLogs(t *testing.T) {
+	tests := []struct {
+		name   string
+		tty    bool
+		stream func(t *testing.T) []byte
+		want   string
+	}{
+		{
+			name:   "multiplexed",
+			stream: func(t *testing.T) []byte { return multiplexed(t, "kubelet started\n", "containerd warning\n") },
+			want:   "kubelet started\ncontainerd warning\n",
+		},
+		{
+			name:   "tty",
+			tty:    true,
+			stream: func(*testing.T) []byte { return []byte("kubelet started\n") },
+			want:   "kubelet started\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOpts container.LogsOptions
+			d := Docker{Client: dockertest.MockClient{
+				FnContainerInspect: func(_ context.Context, id string) (types.ContainerJSON, error) {
+					if d := cmp.Diff("airbyte-abctl-control-plane", id); d != "" {
+						t.Errorf("container id mismatch (-want +got):\n%s", d)
+					}
+					return types.ContainerJSON{Config: &container.Config{Tty: tt.tty}}, nil
+				},
+				FnContainerLogs: func(_ context.Context, id string, options container.LogsOptions) (io.ReadCloser, error) {
+					gotOpts = options
+					return io.NopCloser(bytes.NewReader(tt.stream(t))), nil
+				},
+			}}
+
+			got, err := d.TailNodeLogs(context.Background(), "airbyte-abctl-control-plane", 50)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("logs mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff("50", gotOpts.Tail); d != "" {
+				t.Errorf("tail mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDocker_TailNodeLogs_Errors(t *testing.T) {
+	errTest := errors.New("test error")
+
+	t.Run("inspect", func(t *testing.T) {
+		d := Docker{Client: dockertest.MockClient{
+			FnContainerInspect: func(context.Context, string) (types.ContainerJSON, error) {
+				return types.ContainerJSON{}, errTest
+			},
+		}}
+		if _, err := d.TailNodeLogs(context.Background(), "node", 10); !errors.Is(err, errTest) {
+			t.Errorf("expected error %v, got %v", errTest, err)
+		}
+	})
+
+	t.Run("logs", func(t *testing.T) {
+		d := Docker{Client: dockertest.MockClient{
+			FnContainerInspect: func(context.Context, string) (types.ContainerJSON, error) {
+				return types.ContainerJSON{}, nil
+			},
+			FnContainerLogs: func(context.Context, string, container.LogsOptions) (io.ReadCloser, error) {
+				return nil, errTest
+			},
+		}}
+		if _, err := d.TailNodeLogs(context.Background(), "node", 10); !errors.Is(err, errTest) {
+			t.Errorf("expected error %v, got %v", errTest, err)
+		}
+	})
+
+	t.Run("malformed stream", func(t *testing.T) {
+		d := Docker{Client: dockertest.MockClient{
+			FnContainerInspect: func(context.Context, string) (types.ContainerJSON, error) {
+				return types.ContainerJSON{}, nil
+			},
+			FnContainerLogs: func(context.Context, string, container.LogsOptions) (io.ReadCloser, error) {
+				// an unknown stream type in the header
+				return io.NopCloser(bytes.NewReader([]byte{9, 0, 0, 0, 0, 0, 0, 1, 'x'})), nil
+			},
+		}}
+		if _, err := d.TailNodeLogs(context.Background(), "node", 10); err == nil {
+			t.Error("expected an error reading a malformed stream")
+		}
+	})
+}
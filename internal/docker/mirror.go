@@ -0,0 +1,85 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/pterm/pterm"
+)
+
+// ValidateRegistryMirror returns an error if the mirror is not a registry host, e.g. mirror.example.com:5000.
+func ValidateRegistryMirror(mirror string) error {
+	switch {
+	case mirror == "":
+		return errors.New("registry mirror must not be empty")
+	case strings.Contains(mirror, "://"):
+		return fmt.Errorf("invalid registry mirror '%s': must be a host without a scheme", mirror)
+	case strings.ContainsAny(mirror, "/@ "):
+		return fmt.Errorf("invalid registry mirror '%s': must be a host, optionally with a port", mirror)
+	}
+	return nil
+}
+
+// MirrorImageRef returns the image reference with its registry host replaced by the mirror.
+// References without a registry are qualified with the docker.io defaults first, so "postgres:13" becomes
+// "<mirror>/library/postgres:13". Tags and digests are kept as is.
+func MirrorImageRef(ref, mirror string) string {
+	r := parseImageRef(ref)
+	_, path, _ := strings.Cut(r.name, "/")
+	r.name = mirror + "/" + path
+	return r.String()
+}
+
+var _ Client = (*mirrorClient)(nil)
+
+// mirrorClient wraps a Client, pulling every image through a registry mirror.
+type mirrorClient struct {
+	Client
+	mirror string
+}
+
+// NewMirrorClient returns a Client where all image pulls are fetched from the mirror.
+// Once pulled, images are tagged with their original reference, so they can be found and loaded by it.
+func NewMirrorClient(client Client, mirror string) Client {
+	return mirrorClient{Client: client, mirror: mirror}
+}
+
+func (c mirrorClient) ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+	mirrored := MirrorImageRef(refStr, c.mirror)
+	pterm.Debug.Printfln("pulling image %s from mirror as %s", refStr, mirrored)
+
+	r, err := c.Client.ImagePull(ctx, mirrored, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// an image can't be tagged with a digest, so digest only references keep the mirrored name
+	orig := parseImageRef(refStr)
+	if orig.tag == "" {
+		return r, nil
+	}
+
+	return &retagPullReader{ReadCloser: r, tag: func() error {
+		return c.Client.ImageTag(ctx, mirrored, orig.name+":"+orig.tag)
+	}}, nil
+}
+
+// retagPullReader tags a mirrored or remapped image with its original reference once the pull has been read and closed.
+type retagPullReader struct {
+	io.ReadCloser
+	tag func() error
+}
+
+func (r *retagPullReader) Close() error {
+	if err := r.ReadCloser.Close(); err != nil {
+		return err
+	}
+	if err := r.tag(); err != nil {
+		return fmt.Errorf("unable to tag image with its original reference: %w", err)
+	}
+	return nil
+}
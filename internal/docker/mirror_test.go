@@ -0,0 +1,136 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types/image"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMirrorImageRef(t *testing.T) {
+	const mirror = "mirror.example.com:5000"
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		// docker hub
+		{ref: "postgres", want: "mirror.example.com:5000/library/postgres:latest"},
+		{ref: "postgres:13", want: "mirror.example.com:5000/library/postgres:13"},
+		{ref: "airbyte/server:1.0.0", want: "mirror.example.com:5000/airbyte/server:1.0.0"},
+		{ref: "docker.io/airbyte/server:1.0.0", want: "mirror.example.com:5000/airbyte/server:1.0.0"},
+		{ref: "index.docker.io/library/postgres:13", want: "mirror.example.com:5000/library/postgres:13"},
+		// ghcr
+		{ref: "ghcr.io/airbytehq/server:1.0.0", want: "mirror.example.com:5000/airbytehq/server:1.0.0"},
+		// fully qualified
+		{ref: "registry.example.com:443/team/app:2.0", want: "mirror.example.com:5000/team/app:2.0"},
+		{ref: "localhost:5000/server", want: "mirror.example.com:5000/server:latest"},
+		// digests
+		{ref: "airbyte/server@sha256:abc", want: "mirror.example.com:5000/airbyte/server@sha256:abc"},
+		{ref: "ghcr.io/airbytehq/server:1.0.0@sha256:abc", want: "mirror.example.com:5000/airbytehq/server:1.0.0@sha256:abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, MirrorImageRef(tt.ref, mirror)); d != "" {
+				t.Errorf("ref mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestValidateRegistryMirror(t *testing.T) {
+	tests := []struct {
+		mirror  string
+		wantErr bool
+	}{
+		{mirror: "mirror.example.com"},
+		{mirror: "mirror.example.com:5000"},
+		{mirror: "localhost:5000"},
+		{mirror: "", wantErr: true},
+		{mirror: "https://mirror.example.com", wantErr: true},
+		{mirror: "mirror.example.com/path", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mirror, func(t *testing.T) {
+			if err := ValidateRegistryMirror(tt.mirror); tt.wantErr != (err != nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMirrorClient_ImagePull(t *testing.T) {
+	tests := []struct {
+		ref       string
+		wantPull  string
+		wantTag   []string
+		wantNoTag bool
+	}{
+		{
+			ref:      "airbyte/server:1.0.0",
+			wantPull: "mirror:5000/airbyte/server:1.0.0",
+			wantTag:  []string{"mirror:5000/airbyte/server:1.0.0", "docker.io/airbyte/server:1.0.0"},
+		},
+		{
+			ref:      "ghcr.io/airbytehq/server:1.0.0@sha256:abc",
+			wantPull: "mirror:5000/airbytehq/server:1.0.0@sha256:abc",
+			wantTag:  []string{"mirror:5000/airbytehq/server:1.0.0@sha256:abc", "ghcr.io/airbytehq/server:1.0.0"},
+		},
+		{
+			ref:       "airbyte/server@sha256:abc",
+			wantPull:  "mirror:5000/airbyte/server@sha256:abc",
+			wantNoTag: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			var pulled string
+			var tagged []string
+			c := NewMirrorClient(dockertest.MockClient{
+				FnImagePull: func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+					pulled = refStr
+					return io.NopCloser(strings.NewReader("")), nil
+				},
+				FnImageTag: func(ctx context.Context, source, target string) error {
+					tagged = []string{source, target}
+					return nil
+				},
+			}, "mirror:5000")
+
+			r, err := c.ImagePull(context.Background(), tt.ref, image.PullOptions{})
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if _, err := io.Copy(io.Discard, r); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			// the image is only tagged once the pull completes
+			if tagged != nil {
+				t.Error("image tagged before the pull was closed")
+			}
+			if err := r.Close(); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if d := cmp.Diff(tt.wantPull, pulled); d != "" {
+				t.Errorf("pull mismatch (-want +got):\n%s", d)
+			}
+			if tt.wantNoTag {
+				if tagged != nil {
+					t.Errorf("expected no tag but got %v", tagged)
+				}
+				return
+			}
+			if d := cmp.Diff(tt.wantTag, tagged); d != "" {
+				t.Errorf("tag mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
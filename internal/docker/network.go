@@ -0,0 +1,93 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/docker/docker/api/types/network"
+)
+
+// VPNRange is an address range VPN clients commonly route through the VPN. A docker network which overlaps it
+// is unreachable, from the host and from the containers, while the VPN is connected.
+type VPNRange struct {
+	// Name describes which VPNs route the range.
+	Name   string
+	Prefix netip.Prefix
+}
+
+// commonVPNRanges are the ranges most frequently responsible for a broken cluster network.
+var commonVPNRanges = []VPNRange{
+	{Name: "corporate VPNs", Prefix: netip.MustParsePrefix("10.0.0.0/8")},
+	{Name: "Tailscale and carrier-grade NAT", Prefix: netip.MustParsePrefix("100.64.0.0/10")},
+	{Name: "corporate VPNs", Prefix: netip.MustParsePrefix("172.16.0.0/16")},
+}
+
+// NetworkDiagnosis describes a docker network a container is attached to.
+type NetworkDiagnosis struct {
+	Name     string
+	Subnets  []netip.Prefix
+	Gateways []netip.Addr
+	// Overlaps are the common VPN ranges which overlap any of the Subnets.
+	Overlaps []VPNRange
+}
+
+// DiagnoseNetwork inspects every docker network the container is attached to, e.g. the network of a kind node,
+// reporting their subnets and gateways, and whether they overlap a range commonly routed by VPNs.
+func (d *Docker) DiagnoseNetwork(ctx context.Context, container string) ([]NetworkDiagnosis, error) {
+	ci, err := d.Client.ContainerInspect(ctx, container)
+	if err != nil {
+		return nil, fmt.Errorf("unable to inspect container %s: %w", container, err)
+	}
+	if ci.NetworkSettings == nil {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(ci.NetworkSettings.Networks))
+	for name := range ci.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	diagnoses := make([]NetworkDiagnosis, 0, len(names))
+	for _, name := range names {
+		id := name
+		if ep := ci.NetworkSettings.Networks[name]; ep != nil && ep.NetworkID != "" {
+			id = ep.NetworkID
+		}
+		n, err := d.Client.NetworkInspect(ctx, id, network.InspectOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to inspect network %s: %w", name, err)
+		}
+		diagnoses = append(diagnoses, diagnoseNetwork(name, n.IPAM))
+	}
+
+	return diagnoses, nil
+}
+
+// diagnoseNetwork parses the subnets and gateways of the network, skipping any docker reports which aren't valid.
+func diagnoseNetwork(name string, ipam network.IPAM) NetworkDiagnosis {
+	diag := NetworkDiagnosis{Name: name}
+	for _, cfg := range ipam.Config {
+		if subnet, err := netip.ParsePrefix(cfg.Subnet); err == nil {
+			diag.Subnets = append(diag.Subnets, subnet)
+			diag.Overlaps = append(diag.Overlaps, vpnOverlaps(subnet)...)
+		}
+		if gateway, err := netip.ParseAddr(cfg.Gateway); err == nil {
+			diag.Gateways = append(diag.Gateways, gateway)
+		}
+	}
+	return diag
+}
+
+// vpnOverlaps returns the common VPN ranges which overlap the subnet.
+func vpnOverlaps(subnet netip.Prefix) []VPNRange {
+	var overlaps []VPNRange
+	for _, r := range commonVPNRanges {
+		if r.Prefix.Overlaps(subnet) {
+			overlaps = append(overlaps, r)
+		}
+	}
+	return overlaps
+}
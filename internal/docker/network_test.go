@@ -0,0 +1,143 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/google/go-cmp/cmp"
+)
+
+// diagnosisStrings formats the diagnosis for comparison, as the netip types can't be compared by cmp.
+func diagnosisStrings(d NetworkDiagnosis) map[string][]string {
+	s := map[string][]string{"name": {d.Name}}
+	for _, subnet := range d.Subnets {
+		s["subnets"] = append(s["subnets"], subnet.String())
+	}
+	for _, gateway := range d.Gateways {
+		s["gateways"] = append(s["gateways"], gateway.String())
+	}
+	for _, r := range d.Overlaps {
+		s["overlaps"] = append(s["overlaps"], r.Name+" "+r.Prefix.String())
+	}
+	return s
+}
+
+func TestDiagnoseNetwork(t *testing.T) {
+	tests := []struct {
+		name   string
+		config []network.IPAMConfig
+		want   map[string][]string
+	}{
+		{
+			name: "kind default",
+			config: []network.IPAMConfig{
+				{Subnet: "172.18.0.0/16", Gateway: "172.18.0.1"},
+				{Subnet: "fc00:f853:ccd:e793::/64", Gateway: "fc00:f853:ccd:e793::1"},
+			},
+			want: map[string][]string{
+				"name":     {"kind"},
+				"subnets":  {"172.18.0.0/16", "fc00:f853:ccd:e793::/64"},
+				"gateways": {"172.18.0.1", "fc00:f853:ccd:e793::1"},
+			},
+		},
+		{
+			name:   "corporate vpn",
+			config: []network.IPAMConfig{{Subnet: "10.89.0.0/24", Gateway: "10.89.0.1"}},
+			want: map[string][]string{
+				"name":     {"kind"},
+				"subnets":  {"10.89.0.0/24"},
+				"gateways": {"10.89.0.1"},
+				"overlaps": {"corporate VPNs 10.0.0.0/8"},
+			},
+		},
+		{
+			name:   "tailscale",
+			config: []network.IPAMConfig{{Subnet: "100.100.0.0/16"}},
+			want: map[string][]string{
+				"name":     {"kind"},
+				"subnets":  {"100.100.0.0/16"},
+				"overlaps": {"Tailscale and carrier-grade NAT 100.64.0.0/10"},
+			},
+		},
+		{
+			name:   "subnet containing a vpn range",
+			config: []network.IPAMConfig{{Subnet: "172.16.0.0/12", Gateway: "172.16.0.1"}},
+			want: map[string][]string{
+				"name":     {"kind"},
+				"subnets":  {"172.16.0.0/12"},
+				"gateways": {"172.16.0.1"},
+				"overlaps": {"corporate VPNs 172.16.0.0/16"},
+			},
+		},
+		{
+			name:   "invalid",
+			config: []network.IPAMConfig{{Subnet: "not-a-subnet", Gateway: "not-a-gateway"}},
+			want:   map[string][]string{"name": {"kind"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diagnoseNetwork("kind", network.IPAM{Config: tt.config})
+			if d := cmp.Diff(tt.want, diagnosisStrings(got)); d != "" {
+				t.Errorf("diagnosis mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDocker_DiagnoseNetwork(t *testing.T) {
+	var inspected []string
+	d := &Docker{Client: dockertest.MockClient{
+		FnContainerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{NetworkSettings: &types.NetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					"vpn":  {NetworkID: "vpn-id"},
+					"kind": {NetworkID: "kind-id"},
+				},
+			}}, nil
+		},
+		FnNetworkInspect: func(ctx context.Context, networkID string, options network.InspectOptions) (network.Inspect, error) {
+			inspected = append(inspected, networkID)
+			subnet := map[string]string{"kind-id": "172.18.0.0/16", "vpn-id": "10.1.0.0/16"}[networkID]
+			return network.Inspect{IPAM: network.IPAM{Config: []network.IPAMConfig{{Subnet: subnet}}}}, nil
+		},
+	}}
+
+	diagnoses, err := d.DiagnoseNetwork(context.Background(), "airbyte-abctl-control-plane")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// networks are inspected by their id, in the order of their names
+	if d := cmp.Diff([]string{"kind-id", "vpn-id"}, inspected); d != "" {
+		t.Errorf("inspected networks mismatch (-want +got):\n%s", d)
+	}
+	var got []map[string][]string
+	for _, diag := range diagnoses {
+		got = append(got, diagnosisStrings(diag))
+	}
+	want := []map[string][]string{
+		{"name": {"kind"}, "subnets": {"172.18.0.0/16"}},
+		{"name": {"vpn"}, "subnets": {"10.1.0.0/16"}, "overlaps": {"corporate VPNs 10.0.0.0/8"}},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("diagnoses mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestDocker_DiagnoseNetwork_Error(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnContainerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{}, errors.New("test error")
+		},
+	}}
+
+	if _, err := d.DiagnoseNetwork(context.Background(), "airbyte-abctl-control-plane"); err == nil {
+		t.Error("expected error")
+	}
+}
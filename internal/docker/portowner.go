@@ -0,0 +1,51 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// KindClusterLabel is the label kind gives the node containers of a cluster, its value is the name of the cluster.
+const KindClusterLabel = "io.x-k8s.kind.cluster"
+
+// KindClusterOnPort returns the name of the kind cluster whose running node container publishes the host port,
+// false if no kind node publishes it. The ingress of an abctl cluster is published by its node container.
+func (d *Docker) KindClusterOnPort(ctx context.Context, port int) (string, bool, error) {
+	containers, err := d.Client.ContainerList(ctx, container.ListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("label", KindClusterLabel),
+			filters.Arg("publish", strconv.Itoa(port)),
+		),
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("unable to list containers: %w", err)
+	}
+
+	for _, c := range containers {
+		// the listed containers are only a summary, inspect them to verify their labels and port bindings
+		ci, err := d.Client.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return "", false, fmt.Errorf("unable to inspect container %s: %w", c.ID, err)
+		}
+		if ci.ContainerJSONBase == nil || ci.State == nil || !ci.State.Running || ci.HostConfig == nil || ci.Config == nil {
+			continue
+		}
+		cluster, ok := ci.Config.Labels[KindClusterLabel]
+		if !ok {
+			continue
+		}
+		for _, bindings := range ci.HostConfig.PortBindings {
+			for _, binding := range bindings {
+				if binding.HostPort == strconv.Itoa(port) {
+					return cluster, true, nil
+				}
+			}
+		}
+	}
+
+	return "", false, nil
+}
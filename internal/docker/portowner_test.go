@@ -0,0 +1,111 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-connections/nat"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDocker_KindClusterOnPort(t *testing.T) {
+	node := func(labels map[string]string, running bool, hostPort string) types.ContainerJSON {
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				State: &types.ContainerState{Running: running},
+				HostConfig: &container.HostConfig{PortBindings: nat.PortMap{
+					"80/tcp": {{HostIP: "0.0.0.0", HostPort: hostPort}},
+				}},
+			},
+			Config: &container.Config{Labels: labels},
+		}
+	}
+	kindLabels := map[string]string{KindClusterLabel: "airbyte-abctl"}
+
+	tests := []struct {
+		name        string
+		containers  map[string]types.ContainerJSON
+		wantCluster string
+		wantOK      bool
+	}{
+		{
+			name:        "kind node",
+			containers:  map[string]types.ContainerJSON{"node": node(kindLabels, true, "8000")},
+			wantCluster: "airbyte-abctl",
+			wantOK:      true,
+		},
+		{
+			name: "kind node among other containers",
+			containers: map[string]types.ContainerJSON{
+				"other": node(map[string]string{"app": "web"}, true, "8000"),
+				"node":  node(kindLabels, true, "8000"),
+			},
+			wantCluster: "airbyte-abctl",
+			wantOK:      true,
+		},
+		{
+			name:       "stopped kind node",
+			containers: map[string]types.ContainerJSON{"node": node(kindLabels, false, "8000")},
+		},
+		{
+			name:       "kind node on another port",
+			containers: map[string]types.ContainerJSON{"node": node(kindLabels, true, "8001")},
+		},
+		{
+			name:       "not a kind node",
+			containers: map[string]types.ContainerJSON{"other": node(map[string]string{"app": "web"}, true, "8000")},
+		},
+		{
+			name: "no containers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Docker{Client: dockertest.MockClient{
+				FnContainerList: func(ctx context.Context, options container.ListOptions) ([]types.Container, error) {
+					if !options.Filters.ExactMatch("publish", "8000") {
+						t.Errorf("containers should be filtered by the published port: %v", options.Filters)
+					}
+					var containers []types.Container
+					for _, id := range []string{"other", "node"} {
+						if _, ok := tt.containers[id]; ok {
+							containers = append(containers, types.Container{ID: id})
+						}
+					}
+					return containers, nil
+				},
+				FnContainerInspect: func(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+					return tt.containers[containerID], nil
+				},
+			}}
+
+			cluster, ok, err := d.KindClusterOnPort(context.Background(), 8000)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.wantCluster, cluster); d != "" {
+				t.Errorf("cluster mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantOK, ok); d != "" {
+				t.Errorf("ok mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDocker_KindClusterOnPort_Error(t *testing.T) {
+	d := &Docker{Client: dockertest.MockClient{
+		FnContainerList: func(ctx context.Context, options container.ListOptions) ([]types.Container, error) {
+			return nil, errors.New("test error")
+		},
+	}}
+
+	if _, _, err := d.KindClusterOnPort(context.Background(), 8000); err == nil {
+		t.Error("expected error")
+	}
+}
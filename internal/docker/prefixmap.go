@@ -0,0 +1,164 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/pterm/pterm"
+)
+
+// ImagePrefix remaps the image repositories starting with Old to start with New instead.
+type ImagePrefix struct {
+	Old string
+	New string
+}
+
+// ImagePrefixMap remaps the repositories of image references, e.g. airbyte/ to myorg/airbyte-mirror/.
+// Unlike a registry mirror, which only replaces the registry host, this can rename the repositories themselves.
+type ImagePrefixMap []ImagePrefix
+
+// ParseImagePrefixMap parses a slice of image prefix specs in the format <OLD>=<NEW>.
+// Returns an error if any spec is invalid.
+func ParseImagePrefixMap(specs []string) (ImagePrefixMap, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	m := make(ImagePrefixMap, len(specs))
+	for i, spec := range specs {
+		oldPrefix, newPrefix, ok := strings.Cut(spec, "=")
+		oldPrefix, newPrefix = strings.TrimSpace(oldPrefix), strings.TrimSpace(newPrefix)
+		if !ok || oldPrefix == "" || newPrefix == "" {
+			return nil, fmt.Errorf("image prefix %s is not a valid prefix spec, must be <OLD>=<NEW>", spec)
+		}
+		if hasTagOrDigest(oldPrefix) || hasTagOrDigest(newPrefix) {
+			return nil, fmt.Errorf("image prefix %s is not a valid prefix spec, the prefixes must not contain a tag or digest", spec)
+		}
+		m[i] = ImagePrefix{Old: oldPrefix, New: newPrefix}
+	}
+
+	return m, nil
+}
+
+// hasTagOrDigest returns true if the prefix contains a tag or digest, a colon in its last path segment,
+// as any other colon belongs to a registry port.
+func hasTagOrDigest(prefix string) bool {
+	return strings.Contains(prefix, "@") || strings.LastIndex(prefix, ":") > strings.LastIndex(prefix, "/")
+}
+
+// ReadImagePrefixMapFile returns the image prefix specs of the file, one <OLD>=<NEW> per line.
+// Blank lines and lines starting with # are ignored.
+func ReadImagePrefixMapFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open image prefix map file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var specs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		specs = append(specs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read image prefix map file %s: %w", path, err)
+	}
+
+	return specs, nil
+}
+
+// Map returns the image reference with its repository remapped by the longest matching prefix, and true.
+// The prefixes are matched against both the reference as written and its fully qualified docker.io form,
+// so "airbyte/" matches "airbyte/server:1.0.0" as well as "docker.io/airbyte/server:1.0.0".
+// A prefix only matches whole path segments, unless it ends with a "/". Tags and digests are kept as is.
+// The reference is returned unchanged, and false, if no prefix matches.
+func (m ImagePrefixMap) Map(ref string) (string, bool) {
+	r := parseImageRef(ref)
+
+	var (
+		best   ImagePrefix
+		rest   string
+		mapped bool
+	)
+	for _, name := range refNames(r.name) {
+		for _, p := range m {
+			if !prefixMatches(name, p.Old) || (mapped && len(p.Old) <= len(best.Old)) {
+				continue
+			}
+			best, rest, mapped = p, name[len(p.Old):], true
+		}
+	}
+	if !mapped {
+		return ref, false
+	}
+
+	r.name = best.New + rest
+	return r.String(), true
+}
+
+// refNames returns the names an image may be referred to by, from the fully qualified name, e.g.
+// docker.io/library/postgres, library/postgres and postgres.
+func refNames(name string) []string {
+	names := []string{name}
+	if path, ok := strings.CutPrefix(name, defaultRegistry+"/"); ok {
+		names = append(names, path)
+		if short, ok := strings.CutPrefix(path, "library/"); ok {
+			names = append(names, short)
+		}
+	}
+	return names
+}
+
+// prefixMatches returns true if the prefix matches the start of the name on a path segment boundary.
+func prefixMatches(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	return strings.HasSuffix(prefix, "/") || len(name) == len(prefix) || name[len(prefix)] == '/'
+}
+
+var _ Client = (*prefixMapClient)(nil)
+
+// prefixMapClient wraps a Client, remapping the repository of every image pull.
+type prefixMapClient struct {
+	Client
+	prefixes ImagePrefixMap
+}
+
+// NewPrefixMapClient returns a Client where all image pulls are remapped by the prefixes.
+// Once pulled, remapped images are tagged with their original reference, so they can be found and loaded by it.
+func NewPrefixMapClient(client Client, prefixes ImagePrefixMap) Client {
+	return prefixMapClient{Client: client, prefixes: prefixes}
+}
+
+func (c prefixMapClient) ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+	mapped, ok := c.prefixes.Map(refStr)
+	if !ok {
+		return c.Client.ImagePull(ctx, refStr, options)
+	}
+	pterm.Debug.Printfln("pulling image %s as %s", refStr, mapped)
+
+	r, err := c.Client.ImagePull(ctx, mapped, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// an image can't be tagged with a digest, so digest only references keep the mapped name
+	orig := parseImageRef(refStr)
+	if orig.tag == "" {
+		return r, nil
+	}
+
+	return &retagPullReader{ReadCloser: r, tag: func() error {
+		return c.Client.ImageTag(ctx, mapped, orig.name+":"+orig.tag)
+	}}, nil
+}
@@ -0,0 +1,188 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/docker/docker/api/types/image"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseImagePrefixMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    ImagePrefixMap
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:  "valid",
+			specs: []string{"airbyte/=myorg/airbyte-mirror/", " docker.io/library/postgres = registry.example.com:5000/db/postgres "},
+			want: ImagePrefixMap{
+				{Old: "airbyte/", New: "myorg/airbyte-mirror/"},
+				{Old: "docker.io/library/postgres", New: "registry.example.com:5000/db/postgres"},
+			},
+		},
+		{name: "missing separator", specs: []string{"airbyte/"}, wantErr: true},
+		{name: "empty old", specs: []string{"=myorg/"}, wantErr: true},
+		{name: "empty new", specs: []string{"airbyte/="}, wantErr: true},
+		{name: "tag", specs: []string{"airbyte/server:1.0.0=myorg/server"}, wantErr: true},
+		{name: "digest", specs: []string{"airbyte/server=myorg/server@sha256:abc"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImagePrefixMap(tt.specs)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("prefix map mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestImagePrefixMap_Map(t *testing.T) {
+	m := ImagePrefixMap{
+		{Old: "airbyte/", New: "myorg/airbyte-mirror/"},
+		{Old: "airbyte/server", New: "myorg/platform/api-server"},
+		{Old: "docker.io/library/postgres", New: "registry.example.com:5000/db/postgres"},
+		{Old: "ghcr.io/airbytehq", New: "ghcr.io/myorg"},
+		{Old: "temporalio", New: "myorg/temporalio"},
+	}
+
+	tests := []struct {
+		ref        string
+		want       string
+		wantMapped bool
+	}{
+		// the airbyte/ prefix remaps every airbyte repository
+		{ref: "airbyte/webapp:1.0.0", want: "myorg/airbyte-mirror/webapp:1.0.0", wantMapped: true},
+		{ref: "airbyte/worker:1.0.0", want: "myorg/airbyte-mirror/worker:1.0.0", wantMapped: true},
+		{ref: "docker.io/airbyte/worker:1.0.0", want: "myorg/airbyte-mirror/worker:1.0.0", wantMapped: true},
+		// the longest prefix wins
+		{ref: "airbyte/server:1.0.0", want: "myorg/platform/api-server:1.0.0", wantMapped: true},
+		// prefixes only match whole segments
+		{ref: "airbyte/server-extra:1.0.0", want: "myorg/airbyte-mirror/server-extra:1.0.0", wantMapped: true},
+		{ref: "temporalio-fork/auto-setup:1.0", want: "temporalio-fork/auto-setup:1.0"},
+		{ref: "temporalio/auto-setup:1.23.0", want: "myorg/temporalio/auto-setup:1.23.0", wantMapped: true},
+		// docker.io official images are matched in their fully qualified form
+		{ref: "postgres:13", want: "registry.example.com:5000/db/postgres:13", wantMapped: true},
+		{ref: "library/postgres:13", want: "registry.example.com:5000/db/postgres:13", wantMapped: true},
+		// other registries
+		{ref: "ghcr.io/airbytehq/server:1.0.0", want: "ghcr.io/myorg/server:1.0.0", wantMapped: true},
+		// tags and digests are kept
+		{ref: "airbyte/webapp@sha256:abc", want: "myorg/airbyte-mirror/webapp@sha256:abc", wantMapped: true},
+		{ref: "airbyte/webapp:1.0.0@sha256:abc", want: "myorg/airbyte-mirror/webapp:1.0.0@sha256:abc", wantMapped: true},
+		{ref: "airbyte/webapp", want: "myorg/airbyte-mirror/webapp:latest", wantMapped: true},
+		// unmapped references are unchanged
+		{ref: "busybox:1.28", want: "busybox:1.28"},
+		{ref: "quay.io/airbyte/server:1.0.0", want: "quay.io/airbyte/server:1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, mapped := m.Map(tt.ref)
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("ref mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantMapped, mapped); d != "" {
+				t.Errorf("mapped mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestReadImagePrefixMapFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefixes")
+	content := "# forked airbyte images\nairbyte/=myorg/airbyte-mirror/\n\n  temporalio=myorg/temporalio  \n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadImagePrefixMapFile(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]string{"airbyte/=myorg/airbyte-mirror/", "temporalio=myorg/temporalio"}, got); d != "" {
+		t.Errorf("specs mismatch (-want +got):\n%s", d)
+	}
+
+	if _, err := ReadImagePrefixMapFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestPrefixMapClient_ImagePull(t *testing.T) {
+	m := ImagePrefixMap{{Old: "airbyte/", New: "myorg/airbyte-mirror/"}}
+
+	tests := []struct {
+		ref       string
+		wantPull  string
+		wantTag   []string
+		wantNoTag bool
+	}{
+		{
+			ref:      "airbyte/server:1.0.0",
+			wantPull: "myorg/airbyte-mirror/server:1.0.0",
+			wantTag:  []string{"myorg/airbyte-mirror/server:1.0.0", "docker.io/airbyte/server:1.0.0"},
+		},
+		{
+			ref:       "airbyte/server@sha256:abc",
+			wantPull:  "myorg/airbyte-mirror/server@sha256:abc",
+			wantNoTag: true,
+		},
+		{
+			ref:       "postgres:13",
+			wantPull:  "postgres:13",
+			wantNoTag: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			var pulled string
+			var tagged []string
+			c := NewPrefixMapClient(dockertest.MockClient{
+				FnImagePull: func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+					pulled = refStr
+					return io.NopCloser(strings.NewReader("")), nil
+				},
+				FnImageTag: func(ctx context.Context, source, target string) error {
+					tagged = []string{source, target}
+					return nil
+				},
+			}, m)
+
+			r, err := c.ImagePull(context.Background(), tt.ref, image.PullOptions{})
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if err := r.Close(); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if d := cmp.Diff(tt.wantPull, pulled); d != "" {
+				t.Errorf("pull mismatch (-want +got):\n%s", d)
+			}
+			if tt.wantNoTag {
+				if tagged != nil {
+					t.Errorf("expected no tag but got %v", tagged)
+				}
+				return
+			}
+			if d := cmp.Diff(tt.wantTag, tagged); d != "" {
+				t.Errorf("tag mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package docker
+
+import (
+	"context"
+	"io"
+
+	"github.com/airbytehq/abctl/internal/http"
+	"github.com/docker/docker/api/types/image"
+)
+
+var _ Client = (*rateLimitedClient)(nil)
+
+// rateLimitedClient wraps a Client, limiting the throughput of every ImagePull.
+type rateLimitedClient struct {
+	Client
+	limiter *http.RateLimiter
+}
+
+// NewRateLimitedClient returns a Client where all image pulls share the limiter.
+func NewRateLimitedClient(client Client, limiter *http.RateLimiter) Client {
+	return rateLimitedClient{Client: client, limiter: limiter}
+}
+
+func (c rateLimitedClient) ImagePull(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+	r, err := c.Client.ImagePull(ctx, refStr, options)
+	if err != nil {
+		return nil, err
+	}
+	return c.limiter.Reader(ctx, r), nil
+}
@@ -0,0 +1,46 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	abctlhttp "github.com/airbytehq/abctl/internal/http"
+	"github.com/docker/docker/api/types/image"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRateLimitedClient_ImagePull(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 30*1000)
+	var pulled string
+	c := NewRateLimitedClient(dockertest.MockClient{
+		FnImagePull: func(ctx context.Context, refStr string, options image.PullOptions) (io.ReadCloser, error) {
+			pulled = refStr
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	}, abctlhttp.NewRateLimiter(100*1000))
+
+	start := time.Now()
+	r, err := c.ImagePull(context.Background(), "airbyte/server:1.6.0", image.PullOptions{})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff("airbyte/server:1.6.0", pulled); d != "" {
+		t.Errorf("image mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(len(data), len(got)); d != "" {
+		t.Errorf("read size mismatch (-want +got):\n%s", d)
+	}
+	// 30KB at 100KB/s should take ~300ms
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("image pull was not rate limited, took %s", elapsed)
+	}
+}
@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/tlsconfig"
+)
+
+// TLSOptions are the certificates used to connect to a docker daemon secured with TLS, e.g. on tcp://host:2376.
+type TLSOptions struct {
+	// CACert is the path of the certificate authority the daemon certificate is verified against.
+	CACert string
+	// Cert and Key are the paths of the client certificate and its key, which the daemon may require.
+	Cert string
+	Key  string
+	// Verify verifies the daemon certificate, otherwise the connection is only encrypted.
+	Verify bool
+}
+
+// tlsClientConfig, when set, is the tls configuration of every tcp docker host connected to by clients created by New.
+var tlsClientConfig *tls.Config
+
+// SetTLS configures the TLS used to connect to tcp docker hosts by all clients created by New.
+// The paths which aren't set fall back to the ca.pem, cert.pem and key.pem of DOCKER_CERT_PATH, and the daemon
+// certificate is also verified if DOCKER_TLS_VERIFY is set, as with the docker cli.
+// Without any options, the DOCKER_CERT_PATH and DOCKER_TLS_VERIFY environment variables alone configure the TLS.
+func SetTLS(opts TLSOptions) error {
+	if opts == (TLSOptions{}) {
+		tlsClientConfig = nil
+		return nil
+	}
+
+	if certPath := getenv(client.EnvOverrideCertPath); certPath != "" {
+		if opts.CACert == "" {
+			opts.CACert = filepath.Join(certPath, "ca.pem")
+		}
+		if opts.Cert == "" && opts.Key == "" {
+			opts.Cert, opts.Key = filepath.Join(certPath, "cert.pem"), filepath.Join(certPath, "key.pem")
+		}
+	}
+	if getenv(client.EnvTLSVerify) != "" {
+		opts.Verify = true
+	}
+
+	cfg, err := opts.config()
+	if err != nil {
+		return err
+	}
+	tlsClientConfig = cfg
+	return nil
+}
+
+// config returns the tls configuration of the options, or an error if any of their files is missing or invalid.
+func (o TLSOptions) config() (*tls.Config, error) {
+	if (o.Cert == "") != (o.Key == "") {
+		return nil, errors.New("invalid docker tls options: the client certificate and key must be provided together")
+	}
+
+	for _, f := range []struct{ name, path string }{
+		{"ca certificate", o.CACert},
+		{"client certificate", o.Cert},
+		{"client key", o.Key},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			return nil, fmt.Errorf("unable to read the docker tls %s '%s': %w", f.name, f.path, err)
+		}
+	}
+
+	cfg, err := tlsconfig.Client(tlsconfig.Options{
+		CAFile:             o.CACert,
+		CertFile:           o.Cert,
+		KeyFile:            o.Key,
+		InsecureSkipVerify: !o.Verify,
+		ExclusiveRootPools: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the docker tls certificates: %w", err)
+	}
+	return cfg, nil
+}
+
+// withTLSClientConfig configures the client to connect over TLS, if its host is a tcp host.
+// Other hosts, such as unix sockets and named pipes, are unaffected.
+//
+// Like client.WithTLSClientConfig, the transport of the client is configured in place, which keeps the
+// configuration applied to it for the host. Unlike client.WithTLSClientConfig, the daemon certificate
+// verification can be disabled.
+func withTLSClientConfig(cfg *tls.Config) client.Opt {
+	return func(c *client.Client) error {
+		if hostTransport(c.DaemonHost()) != "tcp" {
+			return nil
+		}
+		// the copy returned by HTTPClient shares the transport of the client
+		transport, ok := c.HTTPClient().Transport.(*http.Transport)
+		if !ok {
+			return fmt.Errorf("cannot apply tls config to transport: %T", c.HTTPClient().Transport)
+		}
+		transport.TLSClientConfig = cfg
+		return nil
+	}
+}
@@ -0,0 +1,264 @@
+package docker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeTestCerts writes a self-signed ca.pem, along with a cert.pem and key.pem, to dir.
+func writeTestCerts(t *testing.T, dir string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("unable to generate key", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "abctl test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("unable to create certificate", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal("unable to marshal key", err)
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	for name, data := range map[string][]byte{
+		"ca.pem":   cert,
+		"cert.pem": cert,
+		"key.pem":  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal("unable to write", name, err)
+		}
+	}
+}
+
+func TestTLSOptions_Config(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCerts(t, dir)
+
+	tests := []struct {
+		name         string
+		opts         TLSOptions
+		expRootCAs   bool
+		expCerts     int
+		expSkipCheck bool
+	}{
+		{
+			name:       "verified with client certificate",
+			opts:       TLSOptions{CACert: filepath.Join(dir, "ca.pem"), Cert: filepath.Join(dir, "cert.pem"), Key: filepath.Join(dir, "key.pem"), Verify: true},
+			expRootCAs: true,
+			expCerts:   1,
+		},
+		{
+			name:       "verified without client certificate",
+			opts:       TLSOptions{CACert: filepath.Join(dir, "ca.pem"), Verify: true},
+			expRootCAs: true,
+		},
+		{
+			name:         "unverified",
+			opts:         TLSOptions{Cert: filepath.Join(dir, "cert.pem"), Key: filepath.Join(dir, "key.pem")},
+			expCerts:     1,
+			expSkipCheck: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.opts.config()
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.expRootCAs, cfg.RootCAs != nil); d != "" {
+				t.Error("unexpected root CAs", d)
+			}
+			if d := cmp.Diff(tt.expCerts, len(cfg.Certificates)); d != "" {
+				t.Error("unexpected client certificates", d)
+			}
+			if d := cmp.Diff(tt.expSkipCheck, cfg.InsecureSkipVerify); d != "" {
+				t.Error("unexpected verification", d)
+			}
+		})
+	}
+}
+
+func TestTLSOptions_ConfigErr(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCerts(t, dir)
+	missing := filepath.Join(dir, "missing.pem")
+
+	tests := []struct {
+		name   string
+		opts   TLSOptions
+		expErr string
+	}{
+		{
+			name:   "missing ca certificate",
+			opts:   TLSOptions{CACert: missing, Verify: true},
+			expErr: "unable to read the docker tls ca certificate '" + missing + "'",
+		},
+		{
+			name:   "missing client certificate",
+			opts:   TLSOptions{Cert: missing, Key: filepath.Join(dir, "key.pem")},
+			expErr: "unable to read the docker tls client certificate '" + missing + "'",
+		},
+		{
+			name:   "missing client key",
+			opts:   TLSOptions{Cert: filepath.Join(dir, "cert.pem"), Key: missing},
+			expErr: "unable to read the docker tls client key '" + missing + "'",
+		},
+		{
+			name:   "certificate without key",
+			opts:   TLSOptions{Cert: filepath.Join(dir, "cert.pem")},
+			expErr: "the client certificate and key must be provided together",
+		},
+		{
+			name:   "invalid ca certificate",
+			opts:   TLSOptions{CACert: filepath.Join(dir, "key.pem"), Verify: true},
+			expErr: "unable to load the docker tls certificates",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.opts.config()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.expErr) {
+				t.Errorf("expected error to contain %q, got %q", tt.expErr, err)
+			}
+		})
+	}
+}
+
+func TestSetTLS_Env(t *testing.T) {
+	origGetenv := getenv
+	t.Cleanup(func() {
+		getenv, tlsClientConfig = origGetenv, nil
+	})
+
+	dir := t.TempDir()
+	writeTestCerts(t, dir)
+
+	env := map[string]string{}
+	getenv = func(key string) string { return env[key] }
+
+	t.Run("no options", func(t *testing.T) {
+		env = map[string]string{client.EnvOverrideCertPath: dir}
+		if err := SetTLS(TLSOptions{}); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		// client.FromEnv configures the tls from the environment alone
+		if tlsClientConfig != nil {
+			t.Error("expected no tls config")
+		}
+	})
+
+	t.Run("cert path fallback", func(t *testing.T) {
+		env = map[string]string{client.EnvOverrideCertPath: dir}
+		if err := SetTLS(TLSOptions{Verify: true}); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if tlsClientConfig.RootCAs == nil || len(tlsClientConfig.Certificates) != 1 || tlsClientConfig.InsecureSkipVerify {
+			t.Error("expected the certificates of DOCKER_CERT_PATH to be verified against")
+		}
+	})
+
+	t.Run("verify fallback", func(t *testing.T) {
+		env = map[string]string{client.EnvTLSVerify: "1"}
+		if err := SetTLS(TLSOptions{CACert: filepath.Join(dir, "ca.pem")}); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if tlsClientConfig.InsecureSkipVerify {
+			t.Error("expected DOCKER_TLS_VERIFY to verify the daemon certificate")
+		}
+	})
+
+	t.Run("missing cert path files", func(t *testing.T) {
+		env = map[string]string{client.EnvOverrideCertPath: t.TempDir()}
+		if err := SetTLS(TLSOptions{Verify: true}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestNewWithOptions_TLS(t *testing.T) {
+	origContextHost := dockerContextHost
+	t.Cleanup(func() {
+		dockerContextHost, tlsClientConfig = origContextHost, nil
+	})
+	dockerContextHost = func() string { return "" }
+
+	dir := t.TempDir()
+	writeTestCerts(t, dir)
+	cfg, err := TLSOptions{CACert: filepath.Join(dir, "ca.pem"), Verify: true}.config()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	tlsClientConfig = cfg
+
+	tests := []struct {
+		name   string
+		host   string
+		expTLS bool
+	}{
+		{name: "tcp", host: "tcp://localhost:2376", expTLS: true},
+		{name: "unix", host: "unix:///var/run/docker.sock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(client.EnvOverrideHost, tt.host)
+
+			var got *tls.Config
+			f := func(opts ...client.Opt) (pinger, error) {
+				// capture the tls config before the client wraps its transport
+				capture := func(c *client.Client) error {
+					if transport, ok := c.HTTPClient().Transport.(*http.Transport); ok {
+						got = transport.TLSClientConfig
+					}
+					return nil
+				}
+				if _, err := client.NewClientWithOpts(append(opts, capture)...); err != nil {
+					t.Fatal("unable to create client", err)
+				}
+				return mockPinger{}, nil
+			}
+
+			if _, err := newWithOptions(context.Background(), f, "linux"); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.expTLS, got == cfg); d != "" {
+				t.Error("unexpected tls config", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package helm
+
+import (
+	"fmt"
+
+	"github.com/airbytehq/abctl/internal/common"
+)
+
+const (
+	// AffinityNone keeps the affinity of the chart, the default.
+	AffinityNone = "none"
+	// AffinitySpread prefers to schedule each platform component on a node without any other Airbyte pod.
+	AffinitySpread = "spread"
+	// AffinityPack prefers to schedule each platform component on a node already running an Airbyte pod.
+	AffinityPack = "pack"
+)
+
+// affinityTopologyKey is the node label whose values the pods are spread across, or packed onto, one per node.
+const affinityTopologyKey = "kubernetes.io/hostname"
+
+// affinityWeight is the weight of the preferred scheduling term of the presets.
+const affinityWeight = 100
+
+// CheckAffinityPreset returns an error if the name isn't one of the affinity presets.
+func CheckAffinityPreset(name string) error {
+	switch name {
+	case "", AffinityNone, AffinitySpread, AffinityPack:
+		return nil
+	default:
+		return fmt.Errorf("invalid affinity preset '%s': must be one of %s, %s or %s", name, AffinityNone, AffinitySpread, AffinityPack)
+	}
+}
+
+// affinityValues returns the helm values which give each of the platform components the pod affinity, or
+// anti-affinity, of the preset, nil for AffinityNone.
+// The terms are only preferred, so the pods are still scheduled on a single node, as the kind cluster has.
+// Any affinity of the user values is merged over them, e.g. a nodeAffinity selecting nodes by their labels.
+func affinityValues(components []string, preset string) map[string]any {
+	var key string
+	switch preset {
+	case AffinitySpread:
+		key = "podAntiAffinity"
+	case AffinityPack:
+		key = "podAffinity"
+	default:
+		return nil
+	}
+
+	vals := map[string]any{}
+	for _, component := range components {
+		// each component gets its own list, as the values may be merged deeply
+		valuesAt(vals, component+".affinity")[key] = map[string]any{
+			"preferredDuringSchedulingIgnoredDuringExecution": []any{
+				map[string]any{
+					"weight": affinityWeight,
+					"podAffinityTerm": map[string]any{
+						"topologyKey": affinityTopologyKey,
+						"labelSelector": map[string]any{
+							"matchLabels": map[string]any{
+								"app.kubernetes.io/instance": common.AirbyteChartRelease,
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return vals
+}
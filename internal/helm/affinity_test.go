@@ -0,0 +1,145 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+// testAffinity returns the affinity of the preset key, podAntiAffinity or podAffinity.
+func testAffinity(key string) map[string]any {
+	return map[string]any{key: map[string]any{
+		"preferredDuringSchedulingIgnoredDuringExecution": []any{
+			map[string]any{
+				"weight": 100,
+				"podAffinityTerm": map[string]any{
+					"topologyKey": "kubernetes.io/hostname",
+					"labelSelector": map[string]any{
+						"matchLabels": map[string]any{"app.kubernetes.io/instance": "airbyte-abctl"},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func TestAffinityValues(t *testing.T) {
+	tests := []struct {
+		preset string
+		want   map[string]any
+	}{
+		{preset: ""},
+		{preset: AffinityNone},
+		{
+			preset: AffinitySpread,
+			want: map[string]any{
+				"server": map[string]any{"affinity": testAffinity("podAntiAffinity")},
+				"worker": map[string]any{"affinity": testAffinity("podAntiAffinity")},
+			},
+		},
+		{
+			preset: AffinityPack,
+			want: map[string]any{
+				"server": map[string]any{"affinity": testAffinity("podAffinity")},
+				"worker": map[string]any{"affinity": testAffinity("podAffinity")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, affinityValues([]string{"server", "worker"}, tt.preset)); d != "" {
+				t.Errorf("values mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestCheckAffinityPreset(t *testing.T) {
+	for _, name := range []string{"", AffinityNone, AffinitySpread, AffinityPack} {
+		if err := CheckAffinityPreset(name); err != nil {
+			t.Errorf("unexpected error for '%s': %s", name, err)
+		}
+	}
+
+	err := CheckAffinityPreset("scatter")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if d := cmp.Diff("invalid affinity preset 'scatter': must be one of none, spread or pack", err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestBuildAirbyteValues_AffinityPreset(t *testing.T) {
+	// a node affinity of the values file, e.g. for the node labels, is combined with the preset
+	nodeAffinity := map[string]any{
+		"requiredDuringSchedulingIgnoredDuringExecution": map[string]any{
+			"nodeSelectorTerms": []any{
+				map[string]any{"matchExpressions": []any{
+					map[string]any{"key": "tier", "operator": "In", "values": []any{"airbyte"}},
+				}},
+			},
+		},
+	}
+	raw, err := yaml.Marshal(map[string]any{"worker": map[string]any{"affinity": map[string]any{"nodeAffinity": nodeAffinity}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		chartVersion string
+		components   []string
+	}{
+		{chartVersion: "1.9.9", components: probeComponentsV1},
+		{chartVersion: "2.0.0", components: probeComponentsV2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chartVersion, func(t *testing.T) {
+			got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+				TelemetryUser:  "test-user",
+				Port:           8000,
+				AffinityPreset: AffinitySpread,
+				ValuesFile:     valuesFile,
+			}, tt.chartVersion)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			var vals map[string]any
+			if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, component := range tt.components {
+				want := testAffinity("podAntiAffinity")
+				if component == "worker" {
+					want["nodeAffinity"] = nodeAffinity
+				}
+				if d := cmp.Diff(want, vals[component].(map[string]any)["affinity"]); d != "" {
+					t.Errorf("%s affinity mismatch (-want +got):\n%s", component, d)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildAirbyteValues_InvalidAffinityPreset(t *testing.T) {
+	_, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+		TelemetryUser:  "test-user",
+		Port:           8000,
+		AffinityPreset: "scatter",
+	}, "2.0.0")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -3,10 +3,12 @@ package helm
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/airbytehq/abctl/internal/maps"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // ValuesOpts contains configuration options for building Airbyte Helm values.
@@ -20,6 +22,81 @@ type ValuesOpts struct {
 	LocalStorage    bool
 	EnablePsql17    bool
 	Port            int
+
+	// AirbyteURL is the URL airbyte is accessible at, http://localhost:<Port> if empty.
+	AirbyteURL string
+
+	// ImagePullSecrets are the names of additional image pull secrets, given to every Airbyte pod after the ImagePullSecret.
+	ImagePullSecrets []string
+
+	// ResourcesPreset is the name of the ResourcesPresets entry to apply, none if empty.
+	ResourcesPreset string
+
+	// ChartFlavor is the name of the ChartFlavors entry to apply, the community flavor if empty.
+	ChartFlavor string
+
+	// StorageClass is the storageClassName of the persistent volume claims of the chart, the chart's default if empty.
+	StorageClass string
+
+	// Tolerations are given to every Airbyte component, for the taints applied to the cluster node.
+	Tolerations []corev1.Toleration
+
+	// EnvFromSecrets and EnvFromConfigMaps are the names of existing secrets and config maps, whose keys are given to
+	// the platform components as environment variables, see envFromValues.
+	EnvFromSecrets    []string
+	EnvFromConfigMaps []string
+
+	// Labels and Annotations are applied to every Airbyte object, see metadataValues.
+	Labels      map[string]string
+	Annotations map[string]string
+
+	// ExpandEnv expands the environment variable references in the ValuesFile, see ExpandEnvValues.
+	ExpandEnv bool
+
+	// NoDefaultValues omits every value provided by abctl, only the Layers, ValuesFile and SetFiles are applied.
+	NoDefaultValues bool
+
+	// Layers are the names of the values layers, applied in order before the ValuesFile.
+	Layers []string
+	// LayersDir is the directory of the user provided values layers.
+	LayersDir string
+
+	// SetFiles are read into the values after the ValuesFile, taking precedence over it, see ParseSetFiles.
+	SetFiles []SetFile
+
+	// Probes tune the liveness and readiness probes of the platform components, see probeValues.
+	Probes ProbeSettings
+
+	// AffinityPreset is the affinity of the platform components, one of AffinitySpread, AffinityPack or AffinityNone,
+	// the default if empty, see affinityValues.
+	AffinityPreset string
+
+	// Components enable or disable each of the toggled components, see ParseComponentToggles. They override the
+	// components disabled by the LowResourceMode.
+	Components map[string]bool
+
+	// BaseValues are the values everything else is merged over, with the lowest priority, e.g. the values of an
+	// existing release, see TransferableValues. Unlike the other options they are also applied with NoDefaultValues.
+	BaseValues map[string]any
+
+	// MergeStrategy is how the lists of the ValuesFile are merged with those of the layers and of abctl, either
+	// MergeStrategyReplace, the default if empty, or MergeStrategyDeep.
+	MergeStrategy string
+}
+
+const (
+	// MergeStrategyReplace replaces a list with the list of the values merged over it, as helm does.
+	MergeStrategyReplace = "replace"
+	// MergeStrategyDeep merges the lists, see maps.MergeDeep.
+	MergeStrategyDeep = "deep"
+)
+
+// merge returns the function merging values over each other by the MergeStrategy.
+func (opts ValuesOpts) merge() func(base, override map[string]any) {
+	if opts.MergeStrategy == MergeStrategyDeep {
+		return maps.MergeDeep
+	}
+	return maps.Merge
 }
 
 const (
@@ -28,8 +105,13 @@ const (
 )
 
 // BuildAirbyteValues generates a values yaml string for the Airbyte Helm chart based on the chart version.
-// It delegates to BuildAirbyteValuesV1 for v1 charts and BuildAirbyteValuesV2 for v2+ charts.
+// It delegates to BuildAirbyteValuesV1 for v1 charts and BuildAirbyteValuesV2 for v2+ charts,
+// unless NoDefaultValues is set in which case only the user-provided values are returned.
 func BuildAirbyteValues(ctx context.Context, valuesOpts ValuesOpts, chartVersion string) (string, error) {
+	if valuesOpts.NoDefaultValues {
+		return buildUserValues(ctx, valuesOpts)
+	}
+
 	if ChartIsV2Plus(chartVersion) {
 		valuesYAML, err := buildAirbyteValuesV2(ctx, valuesOpts)
 		if err != nil {
@@ -65,11 +147,44 @@ func buildAirbyteValuesV1(ctx context.Context, opts ValuesOpts) (string, error)
 		vals = append(vals, "postgresql.image.tag="+Psql17AirbyteTag)
 	}
 
+	if opts.StorageClass != "" {
+		vals = append(vals, "global.storageClass="+opts.StorageClass)
+	}
+
+	if opts.ResourcesPreset != "" {
+		preset, err := ResourcesPresetFor(opts.ResourcesPreset)
+		if err != nil {
+			return "", err
+		}
+		vals = append(vals, preset.values...)
+	}
+
+	flavor, err := ChartFlavorFor(opts.ChartFlavor)
+	if err != nil {
+		return "", err
+	}
+	vals = append(vals, flavor.values...)
+
+	if err := CheckAffinityPreset(opts.AffinityPreset); err != nil {
+		return "", err
+	}
+
 	span.SetAttributes(
 		attribute.Bool("low-resource-mode", opts.LowResourceMode),
 		attribute.Bool("insecure-cookies", opts.InsecureCookies),
 		attribute.Bool("image-pull-secret", opts.ImagePullSecret != ""),
+		attribute.Int("image-pull-secrets", len(opts.ImagePullSecrets)),
 		attribute.Bool("local-storage", opts.LocalStorage),
+		attribute.String("resources-preset", opts.ResourcesPreset),
+		attribute.Bool("storage-class", opts.StorageClass != ""),
+		attribute.String("chart-flavor", flavor.Name),
+		attribute.Int("tolerations", len(opts.Tolerations)),
+		attribute.Int("env-from", len(opts.EnvFromSecrets)+len(opts.EnvFromConfigMaps)),
+		attribute.Int("labels", len(opts.Labels)),
+		attribute.Int("annotations", len(opts.Annotations)),
+		attribute.Bool("probes", !opts.Probes.IsZero()),
+		attribute.String("affinity-preset", opts.AffinityPreset),
+		attribute.Int("components", len(opts.Components)),
 	)
 
 	if !opts.DisableAuth {
@@ -95,21 +210,26 @@ func buildAirbyteValuesV1(ctx context.Context, opts ValuesOpts) (string, error)
 		)
 	}
 
-	if opts.ImagePullSecret != "" {
-		vals = append(vals, fmt.Sprintf("global.imagePullSecrets[0].name=%s", opts.ImagePullSecret))
-	}
-
 	if opts.InsecureCookies {
 		// Boolean is a string value in the v1 Helm chart.
 		vals = append(vals, `global.auth.cookieSecureSetting="false"`)
 	}
 
-	fileVals, err := maps.FromYAMLFile(opts.ValuesFile)
+	userVals, err := userValues(opts)
 	if err != nil {
 		return "", err
 	}
 
-	return mergeValuesWithValuesYAML(vals, fileVals)
+	return mergeValuesWithValuesYAML(opts.merge(), opts.BaseValues, vals,
+		imagePullSecretValues(opts),
+		tolerationValues(tolerationComponentsV1, opts.Tolerations),
+		metadataValues(tolerationComponentsV1, opts.Labels, opts.Annotations),
+		probeValues(probeComponentsV1, opts.Probes),
+		affinityValues(probeComponentsV1, opts.AffinityPreset),
+		componentValues(opts.Components, false),
+		envFromValues(probeComponentsV1, opts.EnvFromSecrets, opts.EnvFromConfigMaps),
+		userVals,
+	)
 }
 
 // buildAirbyteValuesV2 generates values string for v2+ Airbyte Helm charts.
@@ -123,6 +243,9 @@ func buildAirbyteValuesV2(ctx context.Context, opts ValuesOpts) (string, error)
 	}
 
 	airbyteURL := fmt.Sprintf("http://localhost:%d", opts.Port)
+	if opts.AirbyteURL != "" {
+		airbyteURL = opts.AirbyteURL
+	}
 
 	vals := []string{
 		// WEBAPP_URL is required for backward compatibility with v2 Helm charts prior to Airbyte 2.0.0.
@@ -146,11 +269,44 @@ func buildAirbyteValuesV2(ctx context.Context, opts ValuesOpts) (string, error)
 		vals = append(vals, "postgresql.image.tag="+Psql17AirbyteTag)
 	}
 
+	if opts.StorageClass != "" {
+		vals = append(vals, "global.storageClass="+opts.StorageClass)
+	}
+
+	if opts.ResourcesPreset != "" {
+		preset, err := ResourcesPresetFor(opts.ResourcesPreset)
+		if err != nil {
+			return "", err
+		}
+		vals = append(vals, preset.values...)
+	}
+
+	flavor, err := ChartFlavorFor(opts.ChartFlavor)
+	if err != nil {
+		return "", err
+	}
+	vals = append(vals, flavor.values...)
+
+	if err := CheckAffinityPreset(opts.AffinityPreset); err != nil {
+		return "", err
+	}
+
 	span.SetAttributes(
 		attribute.Bool("low-resource-mode", opts.LowResourceMode),
 		attribute.Bool("insecure-cookies", opts.InsecureCookies),
 		attribute.Bool("image-pull-secret", opts.ImagePullSecret != ""),
+		attribute.Int("image-pull-secrets", len(opts.ImagePullSecrets)),
 		attribute.Bool("local-storage", opts.LocalStorage),
+		attribute.String("resources-preset", opts.ResourcesPreset),
+		attribute.Bool("storage-class", opts.StorageClass != ""),
+		attribute.String("chart-flavor", flavor.Name),
+		attribute.Int("tolerations", len(opts.Tolerations)),
+		attribute.Int("env-from", len(opts.EnvFromSecrets)+len(opts.EnvFromConfigMaps)),
+		attribute.Int("labels", len(opts.Labels)),
+		attribute.Int("annotations", len(opts.Annotations)),
+		attribute.Bool("probes", !opts.Probes.IsZero()),
+		attribute.String("affinity-preset", opts.AffinityPreset),
+		attribute.Int("components", len(opts.Components)),
 	)
 
 	if !opts.DisableAuth {
@@ -176,21 +332,91 @@ func buildAirbyteValuesV2(ctx context.Context, opts ValuesOpts) (string, error)
 		)
 	}
 
-	if opts.ImagePullSecret != "" {
-		vals = append(vals, fmt.Sprintf("global.imagePullSecrets[0].name=%s", opts.ImagePullSecret))
-	}
-
 	if opts.InsecureCookies {
 		// Boolean is a string value in the v1 Helm chart.
 		vals = append(vals, `global.auth.security.cookieSecureSetting="false"`)
 	}
 
-	fileVals, err := maps.FromYAMLFile(opts.ValuesFile)
+	userVals, err := userValues(opts)
 	if err != nil {
 		return "", err
 	}
 
-	return mergeValuesWithValuesYAML(vals, fileVals)
+	return mergeValuesWithValuesYAML(opts.merge(), opts.BaseValues, vals,
+		imagePullSecretValues(opts),
+		tolerationValues(tolerationComponentsV2, opts.Tolerations),
+		metadataValues(tolerationComponentsV2, opts.Labels, opts.Annotations),
+		probeValues(probeComponentsV2, opts.Probes),
+		affinityValues(probeComponentsV2, opts.AffinityPreset),
+		componentValues(opts.Components, true),
+		envFromValues(probeComponentsV2, opts.EnvFromSecrets, opts.EnvFromConfigMaps),
+		userVals,
+	)
+}
+
+// buildUserValues generates values string from only the user-provided values, omitting all values provided by abctl.
+// Every other option of the opts, except for the BaseValues, is ignored.
+func buildUserValues(ctx context.Context, opts ValuesOpts) (string, error) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("no-default-values", true))
+
+	userVals, err := userValues(opts)
+	if err != nil {
+		return "", err
+	}
+	vals := copyValues(opts.BaseValues)
+	opts.merge()(vals, userVals)
+
+	res, err := maps.ToYAML(vals)
+	if err != nil {
+		return "", fmt.Errorf("unable to merge values: %w", err)
+	}
+
+	return res, nil
+}
+
+// userValues returns the values provided by the user, the values layers in order followed by the values file and
+// the set files. As with helm, the set files have the highest priority, overriding the values file and any of the layers.
+func userValues(opts ValuesOpts) (map[string]any, error) {
+	vals, err := ResolveLayers(opts.LayersDir, opts.Layers)
+	if err != nil {
+		return nil, err
+	}
+
+	fileVals, err := maps.FromYAMLFile(opts.ValuesFile)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ExpandEnv {
+		if fileVals, err = ExpandEnvValues(fileVals, os.LookupEnv); err != nil {
+			return nil, err
+		}
+	}
+	opts.merge()(vals, fileVals)
+
+	setVals, err := setFileValues(opts.SetFiles)
+	if err != nil {
+		return nil, err
+	}
+	maps.Merge(vals, setVals)
+
+	return vals, nil
+}
+
+// imagePullSecretValues returns the helm values which give every Airbyte pod the image pull secrets, nil if there are none.
+// The values are a map as the dot-delimited values don't support lists.
+func imagePullSecretValues(opts ValuesOpts) map[string]any {
+	var secrets []any
+	if opts.ImagePullSecret != "" {
+		secrets = append(secrets, map[string]any{"name": opts.ImagePullSecret})
+	}
+	for _, name := range opts.ImagePullSecrets {
+		secrets = append(secrets, map[string]any{"name": name})
+	}
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	return map[string]any{"global": map[string]any{"imagePullSecrets": secrets}}
 }
 
 // mergeValuesWithValuesYAML ensures that the values defined within this code have a lower
@@ -199,10 +425,15 @@ func buildAirbyteValuesV2(ctx context.Context, opts ValuesOpts) (string, error)
 // defined in this code at a higher priority than the values defined in the values.yaml file.
 // This function returns a string representation of the value.yaml file after all
 // values provided were potentially overridden by the valuesYML file.
-func mergeValuesWithValuesYAML(values []string, userValues map[string]any) (string, error) {
-	a := maps.FromSlice(values)
-
-	maps.Merge(a, userValues)
+// The values are merged over a copy of the base, which may be nil, then the overrides are merged in order with the
+// merge function, the last of which should be the user values.
+func mergeValuesWithValuesYAML(merge func(base, override map[string]any), base map[string]any, values []string, overrides ...map[string]any) (string, error) {
+	a := copyValues(base)
+	merge(a, maps.FromSlice(values))
+
+	for _, o := range overrides {
+		merge(a, o)
+	}
 
 	res, err := maps.ToYAML(a)
 	if err != nil {
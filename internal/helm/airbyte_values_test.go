@@ -78,6 +78,26 @@ global:
 postgresql:
     image:
         tag: 1.7.0-17
+`,
+		},
+		{
+			name:         "v1: storage class",
+			opts:         ValuesOpts{TelemetryUser: "test-user", StorageClass: "gp3"},
+			chartVersion: "1.9.9",
+			want: `airbyte-bootloader:
+    env_vars:
+        PLATFORM_LOG_FORMAT: json
+global:
+    auth:
+        enabled: true
+    env_vars:
+        AIRBYTE_INSTALLATION_ID: test-user
+    jobs:
+        resources:
+            limits:
+                cpu: "3"
+                memory: 4Gi
+    storageClass: gp3
 `,
 		},
 		{
@@ -116,6 +136,41 @@ postgresql:
 			chartVersion: "1.9.9",
 			wantErr:      true,
 		},
+		{
+			name: "no default values only returns the values file",
+			opts: ValuesOpts{
+				TelemetryUser:   "test-user",
+				LowResourceMode: true,
+				NoDefaultValues: true,
+				ValuesFile:      filepath.Join(testdataDir, "expected-default.values.yaml"),
+			},
+			chartVersion: "2.0.0",
+			want: `airbyte-bootloader:
+    env_vars:
+        PLATFORM_LOG_FORMAT: json
+global:
+    auth:
+        enabled: true
+    env_vars:
+        AIRBYTE_INSTALLATION_ID: test-user
+    jobs:
+        resources:
+            limits:
+                cpu: "3"
+                memory: 4Gi
+    storage:
+        type: local
+postgresql:
+    image:
+        tag: 1.7.0-17
+`,
+		},
+		{
+			name:         "no default values without user values",
+			opts:         ValuesOpts{TelemetryUser: "test-user", NoDefaultValues: true},
+			chartVersion: "1.9.9",
+			want:         "{}\n",
+		},
 		{
 			name:         "v1: auth disabled",
 			opts:         ValuesOpts{TelemetryUser: "test-user", DisableAuth: true},
@@ -202,8 +257,8 @@ global:
         enabled: true
     env_vars:
         AIRBYTE_INSTALLATION_ID: test-user
-    imagePullSecrets[0]:
-        name: mysecret
+    imagePullSecrets:
+        - name: mysecret
     jobs:
         resources:
             limits:
@@ -232,6 +287,80 @@ global:
 server:
     env_vars:
         WEBAPP_URL: http://airbyte-abctl-airbyte-server-svc
+`,
+		},
+		{
+			name:         "v2: storage class",
+			opts:         ValuesOpts{TelemetryUser: "test-user", Port: 8000, StorageClass: "gp3"},
+			chartVersion: "2.0.0",
+			want: `airbyte-bootloader:
+    env_vars:
+        PLATFORM_LOG_FORMAT: json
+global:
+    airbyteUrl: http://localhost:8000
+    auth:
+        enabled: true
+    env_vars:
+        AIRBYTE_INSTALLATION_ID: test-user
+    jobs:
+        resources:
+            limits:
+                cpu: "3"
+                memory: 4Gi
+    storageClass: gp3
+server:
+    env_vars:
+        WEBAPP_URL: http://airbyte-abctl-airbyte-server-svc
+`,
+		},
+		{
+			name:         "v2: airbyte url",
+			opts:         ValuesOpts{TelemetryUser: "test-user", Port: 8000, AirbyteURL: "https://airbyte.example.com"},
+			chartVersion: "2.0.0",
+			want: `airbyte-bootloader:
+    env_vars:
+        PLATFORM_LOG_FORMAT: json
+global:
+    airbyteUrl: https://airbyte.example.com
+    auth:
+        enabled: true
+    env_vars:
+        AIRBYTE_INSTALLATION_ID: test-user
+    jobs:
+        resources:
+            limits:
+                cpu: "3"
+                memory: 4Gi
+server:
+    env_vars:
+        WEBAPP_URL: http://airbyte-abctl-airbyte-server-svc
+`,
+		},
+		{
+			name:         "v2: image pull secrets",
+			opts:         ValuesOpts{TelemetryUser: "test-user", Port: 8000, ImagePullSecret: "docker-auth", ImagePullSecrets: []string{"ghcr", "quay"}},
+			chartVersion: "2.0.0",
+			want: `airbyte-bootloader:
+    env_vars:
+        PLATFORM_LOG_FORMAT: json
+global:
+    airbyteUrl: http://localhost:8000
+    auth:
+        enabled: true
+    env_vars:
+        AIRBYTE_INSTALLATION_ID: test-user
+    imagePullSecrets:
+        - name: docker-auth
+        - name: ghcr
+        - name: quay
+    jobs:
+        resources:
+            limits:
+                cpu: "3"
+                memory: 4Gi
+server:
+    env_vars:
+        WEBAPP_URL: http://airbyte-abctl-airbyte-server-svc
 `,
 		},
 		{
@@ -251,8 +380,8 @@ global:
         enabled: true
     env_vars:
         AIRBYTE_INSTALLATION_ID: test-user
-    imagePullSecrets[0]:
-        name: mysecret
+    imagePullSecrets:
+        - name: mysecret
     jobs:
         resources:
             limits:
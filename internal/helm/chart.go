@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/common"
 	"github.com/airbytehq/abctl/internal/validate"
 	goHelm "github.com/mittwald/go-helm-client"
@@ -18,6 +19,8 @@ type ChartResolver struct {
 	v2RepoURL string
 	// client provides Chart.yaml metadata extraction for local paths and URLs
 	client goHelm.Client
+	// Offline prevents fetching the repository index, so the latest chart version can't be resolved
+	Offline bool
 }
 
 // NewChartResolver creates a resolver with default Airbyte v1/v2 repository URLs
@@ -60,12 +63,15 @@ func ChartEqualsOrHigherVersion(v string, threshold string) bool {
 }
 
 // ResolveChartReference resolves an Airbyte chart reference to its full URL/path and version.
-// For empty chart+version, returns latest v2 chart.
+// For empty chart+version, returns latest v2 chart, or abctl.ErrOfflineChart if Offline.
 // For version-only, uses v1/v2 repo based on base version (strips pre-release suffix for repo selection).
 // For URLs and local paths, returns as-is with version from chart metadata.
 func (r *ChartResolver) ResolveChartReference(chart, version string) (string, string, error) {
 	if chart == "" {
 		if version == "" {
+			if r.Offline {
+				return "", "", abctl.ErrOfflineChart
+			}
 			chartURL, chartVersion, err := GetLatestAirbyteChartUrlFromRepoIndex("", r.v2RepoURL)
 			if err != nil {
 				return "", "", err
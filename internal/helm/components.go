@@ -0,0 +1,150 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Component is a component of the airbyte chart, which can be enabled or disabled through its enabled value
+// unless it is Required.
+type Component struct {
+	// Name is the name of the component given to --components, e.g. connector-builder.
+	Name string
+	// KeyV1 and KeyV2 are the values keys of the component in the v1 and v2 charts.
+	KeyV1, KeyV2 string
+	// Required components cannot be disabled, airbyte doesn't work without them.
+	Required bool
+	// Optional components are disabled by default and must be enabled.
+	Optional bool
+	// DisabledWarning describes what no longer works once the component is disabled.
+	DisabledWarning string
+}
+
+// key returns the values key of the component in the chart version.
+func (c Component) key(v2 bool) string {
+	if v2 {
+		return c.KeyV2
+	}
+	return c.KeyV1
+}
+
+// Components are the components of the airbyte chart, keyed by name.
+var Components = map[string]Component{
+	"bootloader":          {Name: "bootloader", KeyV1: "airbyte-bootloader", KeyV2: "airbyte-bootloader", Required: true},
+	"server":              {Name: "server", KeyV1: "server", KeyV2: "server", Required: true},
+	"webapp":              {Name: "webapp", KeyV1: "webapp", KeyV2: "webapp", Required: true},
+	"worker":              {Name: "worker", KeyV1: "worker", KeyV2: "worker", Required: true},
+	"workload-api-server": {Name: "workload-api-server", KeyV1: "workload-api-server", KeyV2: "workloadApiServer", Required: true},
+	"workload-launcher":   {Name: "workload-launcher", KeyV1: "workload-launcher", KeyV2: "workloadLauncher", Required: true},
+	"temporal":            {Name: "temporal", KeyV1: "temporal", KeyV2: "temporal", Required: true},
+	"postgresql":          {Name: "postgresql", KeyV1: "postgresql", KeyV2: "postgresql", Required: true},
+	"connector-builder": {
+		Name:            "connector-builder",
+		KeyV1:           "connector-builder-server",
+		KeyV2:           "connectorBuilderServer",
+		DisabledWarning: "the Connector Builder of the webapp will be unavailable",
+	},
+	"cron": {
+		Name:            "cron",
+		KeyV1:           "cron",
+		KeyV2:           "cron",
+		DisabledWarning: "the connector definitions won't be updated and finished workloads won't be cleaned up",
+	},
+	"metrics": {
+		Name:     "metrics",
+		KeyV1:    "metrics",
+		KeyV2:    "metrics",
+		Optional: true,
+	},
+}
+
+// ComponentNames returns the sorted names of the components which can be toggled.
+func ComponentNames() []string {
+	var names []string
+	for name, c := range Components {
+		if !c.Required {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseComponentToggles returns whether each of the components of the specs is enabled. A spec is the name of a
+// component prefixed by + to enable it, or - to disable it, e.g. -connector-builder. A name without a prefix is
+// enabled. The last spec of a component wins.
+// An error is returned for an unknown component, or a Required one being disabled.
+func ParseComponentToggles(specs []string) (map[string]bool, error) {
+	toggles := map[string]bool{}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		enabled := !strings.HasPrefix(spec, "-")
+		name := strings.TrimLeft(spec, "+-")
+
+		c, ok := Components[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid component '%s': must be one of %s", spec, strings.Join(ComponentNames(), ", "))
+		}
+		if c.Required && !enabled {
+			return nil, fmt.Errorf("invalid component '%s': the %s is required and cannot be disabled", spec, name)
+		}
+		toggles[name] = enabled
+	}
+	return toggles, nil
+}
+
+// ComponentWarnings returns a warning for each of the disabled components which others depend on, sorted by name.
+func ComponentWarnings(toggles map[string]bool) []string {
+	var warnings []string
+	for _, name := range sortedKeys(toggles) {
+		if c := Components[name]; !toggles[name] && c.DisabledWarning != "" {
+			warnings = append(warnings, fmt.Sprintf("With the %s disabled, %s", name, c.DisabledWarning))
+		}
+	}
+	return warnings
+}
+
+// componentValues returns the helm values which set the enabled value of each of the toggled components,
+// nil if there are none.
+func componentValues(toggles map[string]bool, v2 bool) map[string]any {
+	if len(toggles) == 0 {
+		return nil
+	}
+
+	vals := map[string]any{}
+	for name, enabled := range toggles {
+		valuesAt(vals, Components[name].key(v2))["enabled"] = enabled
+	}
+	return vals
+}
+
+// EnabledComponents returns the sorted names of the components which can be toggled and are enabled by the release
+// values, by default or by their enabled value.
+func EnabledComponents(vals map[string]any) []string {
+	var enabled []string
+	for _, name := range ComponentNames() {
+		c := Components[name]
+		on := !c.Optional
+		// the values only hold the key of the chart version they were installed with
+		for _, key := range []string{c.KeyV1, c.KeyV2} {
+			component, _ := vals[key].(map[string]any)
+			if v, ok := component["enabled"].(bool); ok {
+				on = v
+			}
+		}
+		if on {
+			enabled = append(enabled, name)
+		}
+	}
+	return enabled
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,189 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseComponentToggles(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    map[string]bool
+		wantErr string
+	}{
+		{
+			name: "none",
+			want: map[string]bool{},
+		},
+		{
+			name:  "toggles",
+			specs: []string{"-connector-builder", "+metrics", "cron"},
+			want:  map[string]bool{"connector-builder": false, "metrics": true, "cron": true},
+		},
+		{
+			name:  "last wins",
+			specs: []string{"-cron", "+cron"},
+			want:  map[string]bool{"cron": true},
+		},
+		{
+			name:    "unknown",
+			specs:   []string{"-builder"},
+			wantErr: "invalid component '-builder': must be one of connector-builder, cron, metrics",
+		},
+		{
+			name:    "required",
+			specs:   []string{"-server"},
+			wantErr: "invalid component '-server': the server is required and cannot be disabled",
+		},
+		{
+			name:  "required enabled",
+			specs: []string{"+server"},
+			want:  map[string]bool{"server": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseComponentToggles(tt.specs)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if d := cmp.Diff(tt.wantErr, err.Error()); d != "" {
+					t.Errorf("error mismatch (-want +got):\n%s", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("toggles mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestComponentWarnings(t *testing.T) {
+	got := ComponentWarnings(map[string]bool{"cron": false, "connector-builder": false, "metrics": false})
+	want := []string{
+		"With the connector-builder disabled, the Connector Builder of the webapp will be unavailable",
+		"With the cron disabled, the connector definitions won't be updated and finished workloads won't be cleaned up",
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("warnings mismatch (-want +got):\n%s", d)
+	}
+
+	if got := ComponentWarnings(map[string]bool{"cron": true}); got != nil {
+		t.Errorf("expected no warnings but got %v", got)
+	}
+}
+
+func TestComponentValues(t *testing.T) {
+	if vals := componentValues(nil, true); vals != nil {
+		t.Errorf("expected no values, got %v", vals)
+	}
+
+	// every component which can be toggled maps to the enabled value of its chart key
+	tests := []struct {
+		name      string
+		component string
+		v1, v2    string
+	}{
+		{name: "connector builder", component: "connector-builder", v1: "connector-builder-server", v2: "connectorBuilderServer"},
+		{name: "cron", component: "cron", v1: "cron", v2: "cron"},
+		{name: "metrics", component: "metrics", v1: "metrics", v2: "metrics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, enabled := range []bool{true, false} {
+				toggles := map[string]bool{tt.component: enabled}
+				if d := cmp.Diff(map[string]any{tt.v1: map[string]any{"enabled": enabled}}, componentValues(toggles, false)); d != "" {
+					t.Errorf("v1 values mismatch (-want +got):\n%s", d)
+				}
+				if d := cmp.Diff(map[string]any{tt.v2: map[string]any{"enabled": enabled}}, componentValues(toggles, true)); d != "" {
+					t.Errorf("v2 values mismatch (-want +got):\n%s", d)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildAirbyteValues_Components(t *testing.T) {
+	tests := []struct {
+		chartVersion string
+		builder      string
+	}{
+		{chartVersion: "1.9.9", builder: "connector-builder-server"},
+		{chartVersion: "2.0.0", builder: "connectorBuilderServer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chartVersion, func(t *testing.T) {
+			// the components override the low resource mode, which disables the connector builder
+			got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+				TelemetryUser:   "test-user",
+				Port:            8000,
+				LowResourceMode: true,
+				Components:      map[string]bool{"connector-builder": true, "cron": false},
+			}, tt.chartVersion)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			var vals map[string]any
+			if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+				t.Fatal(err)
+			}
+
+			if d := cmp.Diff(true, vals[tt.builder].(map[string]any)["enabled"]); d != "" {
+				t.Errorf("connector builder mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(false, vals["cron"].(map[string]any)["enabled"]); d != "" {
+				t.Errorf("cron mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff([]string{"connector-builder"}, EnabledComponents(vals)); d != "" {
+				t.Errorf("enabled components mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestEnabledComponents(t *testing.T) {
+	tests := []struct {
+		name string
+		vals map[string]any
+		want []string
+	}{
+		{
+			name: "defaults",
+			want: []string{"connector-builder", "cron"},
+		},
+		{
+			name: "toggled",
+			vals: map[string]any{
+				"connector-builder-server": map[string]any{"enabled": false},
+				"metrics":                  map[string]any{"enabled": true},
+			},
+			want: []string{"cron", "metrics"},
+		},
+		{
+			name: "v2",
+			vals: map[string]any{"connectorBuilderServer": map[string]any{"enabled": false}},
+			want: []string{"cron"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, EnabledComponents(tt.vals)); d != "" {
+				t.Errorf("enabled components mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
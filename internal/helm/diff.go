@@ -0,0 +1,120 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+)
+
+// flattenValues returns the leaf values of the values map keyed by their dotted path, e.g. "global.auth.enabled".
+// List items are keyed by their index, e.g. "global.imagePullSecrets[0].name".
+func flattenValues(prefix string, v any, out map[string]string) {
+	switch z := v.(type) {
+	case map[string]any:
+		for k, item := range z {
+			path := k
+			if prefix != "" {
+				path = prefix + "." + k
+			}
+			flattenValues(path, item, out)
+		}
+	case []any:
+		for i, item := range z {
+			flattenValues(fmt.Sprintf("%s[%d]", prefix, i), item, out)
+		}
+	default:
+		out[prefix] = fmt.Sprintf("%v", z)
+	}
+}
+
+// ValuesKeys returns the dotted paths of every leaf value of the values map, sorted, e.g. "global.auth.enabled".
+// Only the paths are returned, never the values, so it is safe to share even if the values contain secrets.
+func ValuesKeys(values map[string]any) []string {
+	flat := map[string]string{}
+	flattenValues("", values, flat)
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sensitivePath returns true if the leaf key of the dotted path is sensitive, see IsSensitiveKey.
+func sensitivePath(path string) bool {
+	parts := strings.Split(path, ".")
+	leaf, _, _ := strings.Cut(parts[len(parts)-1], "[")
+	return IsSensitiveKey(leaf)
+}
+
+// RenderValuesDiff returns a unified style summary of the changes between the old and new chart versions and values.
+// Sensitive values are compared, but are redacted in the output.
+func RenderValuesDiff(oldVersion, newVersion string, oldValues, newValues map[string]any) string {
+	oldFlat := map[string]string{}
+	newFlat := map[string]string{}
+	flattenValues("", oldValues, oldFlat)
+	flattenValues("", newValues, newFlat)
+
+	paths := map[string]struct{}{}
+	for p := range oldFlat {
+		paths[p] = struct{}{}
+	}
+	for p := range newFlat {
+		paths[p] = struct{}{}
+	}
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- deployed (chart %s)\n", oldVersion)
+	fmt.Fprintf(&b, "+++ target (chart %s)\n", newVersion)
+
+	changes := 0
+	for _, p := range sorted {
+		oldVal, inOld := oldFlat[p]
+		newVal, inNew := newFlat[p]
+		if inOld && inNew && oldVal == newVal {
+			continue
+		}
+		changes++
+
+		if sensitivePath(p) || IsSensitiveValue(oldVal) || IsSensitiveValue(newVal) {
+			oldVal, newVal = RedactedValue, RedactedValue
+		}
+		if inOld {
+			fmt.Fprintf(&b, "- %s: %s\n", p, oldVal)
+		}
+		if inNew {
+			fmt.Fprintf(&b, "+ %s: %s\n", p, newVal)
+		}
+	}
+
+	if changes == 0 {
+		b.WriteString("  no changes to the helm values\n")
+	}
+
+	return b.String()
+}
+
+// CheckUpgrade returns an error if the installed chart version cannot be upgraded to the target chart version.
+func CheckUpgrade(installed, target string) error {
+	if installed == "" || target == "" {
+		return nil
+	}
+
+	threshold := installed
+	if threshold[0] != 'v' {
+		threshold = "v" + threshold
+	}
+	if !ChartEqualsOrHigherVersion(target, threshold) {
+		return fmt.Errorf("%w: chart version %s is older than the installed chart version %s", abctl.ErrUpgradeBlocked, target, installed)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package helm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRenderValuesDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldValues map[string]any
+		newValues map[string]any
+		want      string
+	}{
+		{
+			name: "changed, added and removed",
+			oldValues: map[string]any{
+				"global": map[string]any{
+					"airbyteUrl": "http://localhost:8000",
+					"auth":       map[string]any{"enabled": true},
+				},
+				"server": map[string]any{"replicaCount": float64(1)},
+			},
+			newValues: map[string]any{
+				"global": map[string]any{
+					"airbyteUrl": "http://localhost:8001",
+					"auth":       map[string]any{"enabled": true},
+					"imagePullSecrets": []any{
+						map[string]any{"name": "docker-auth"},
+					},
+				},
+				"server": map[string]any{"replicaCount": 1},
+			},
+			want: `--- deployed (chart 1.5.0)
++++ target (chart 1.6.0)
+- global.airbyteUrl: http://localhost:8000
++ global.airbyteUrl: http://localhost:8001
++ global.imagePullSecrets[0].name: docker-auth
+`,
+		},
+		{
+			name: "redacted",
+			oldValues: map[string]any{
+				"auth": map[string]any{"password": "old", "clientSecret": "same"},
+			},
+			newValues: map[string]any{
+				"auth": map[string]any{"password": "new", "clientSecret": "same"},
+			},
+			want: `--- deployed (chart 1.5.0)
++++ target (chart 1.6.0)
+- auth.password: [REDACTED]
++ auth.password: [REDACTED]
+`,
+		},
+		{
+			name:      "no changes",
+			oldValues: map[string]any{"server": map[string]any{"replicaCount": float64(2)}},
+			newValues: map[string]any{"server": map[string]any{"replicaCount": 2}},
+			want: `--- deployed (chart 1.5.0)
++++ target (chart 1.6.0)
+  no changes to the helm values
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderValuesDiff("1.5.0", "1.6.0", tt.oldValues, tt.newValues)
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("diff mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestCheckUpgrade(t *testing.T) {
+	tests := []struct {
+		installed string
+		target    string
+		wantErr   bool
+	}{
+		{installed: "1.5.0", target: "1.6.0"},
+		{installed: "1.5.0", target: "1.5.0"},
+		{installed: "1.8.1", target: "2.0.0"},
+		{installed: "", target: "2.0.0"},
+		{installed: "1.6.0", target: "1.5.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.installed+"->"+tt.target, func(t *testing.T) {
+			err := CheckUpgrade(tt.installed, tt.target)
+			if tt.wantErr != errors.Is(err, abctl.ErrUpgradeBlocked) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValuesKeys(t *testing.T) {
+	values := map[string]any{
+		"global": map[string]any{
+			"auth":             map[string]any{"enabled": true, "instanceAdmin": map[string]any{"password": "hunter2"}},
+			"imagePullSecrets": []any{map[string]any{"name": "regcred"}},
+		},
+		"server": map[string]any{"replicaCount": float64(1)},
+	}
+
+	want := []string{
+		"global.auth.enabled",
+		"global.auth.instanceAdmin.password",
+		"global.imagePullSecrets[0].name",
+		"server.replicaCount",
+	}
+	if d := cmp.Diff(want, ValuesKeys(values)); d != "" {
+		t.Errorf("keys mismatch (-want +got):\n%s", d)
+	}
+}
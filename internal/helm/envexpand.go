@@ -0,0 +1,136 @@
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/pterm/pterm"
+)
+
+// envRefRegex matches the environment variable references expanded in a values file: $$ (an escaped $),
+// ${VAR}, ${VAR:-default} and $VAR.
+var envRefRegex = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// ExpandEnvValues returns a copy of the values where the environment variable references in every string value
+// are expanded with lookup (e.g. os.LookupEnv). A reference to an undefined variable returns ErrValuesEnvUndefined,
+// unless it provides a default with the ${VAR:-default} syntax, which is also used for a variable set to empty.
+// Keys are never expanded, and expanded values are always strings.
+func ExpandEnvValues(values map[string]any, lookup func(string) (string, bool)) (map[string]any, error) {
+	e := envExpander{lookup: lookup, expanded: map[string]string{}}
+	out, err := e.expandMap(values, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// list the expansions for debugging, hiding any value which looks like a secret
+	paths := make([]string, 0, len(e.expanded))
+	for path := range e.expanded {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		pterm.Debug.Printfln("Expanded the environment variables of values key %s: %s", path, e.expanded[path])
+	}
+
+	return out, nil
+}
+
+type envExpander struct {
+	lookup func(string) (string, bool)
+	// expanded is the (possibly redacted) expanded value of each values key containing a reference
+	expanded map[string]string
+}
+
+func (e *envExpander) expandMap(values map[string]any, path string) (map[string]any, error) {
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		expanded, err := e.expandValue(v, valuesPath(path, k), k)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = expanded
+	}
+	return out, nil
+}
+
+// expandValue expands the value v of the key, at the full path.
+func (e *envExpander) expandValue(v any, path, key string) (any, error) {
+	switch z := v.(type) {
+	case map[string]any:
+		return e.expandMap(z, path)
+	case []any:
+		out := make([]any, len(z))
+		for i, item := range z {
+			expanded, err := e.expandValue(item, fmt.Sprintf("%s[%d]", path, i), key)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = expanded
+		}
+		return out, nil
+	case string:
+		return e.expandString(z, path, key)
+	default:
+		return v, nil
+	}
+}
+
+func (e *envExpander) expandString(s, path, key string) (string, error) {
+	var (
+		err       error
+		sensitive = IsSensitiveKey(key)
+		found     bool
+	)
+
+	out := envRefRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$$" {
+			return "$"
+		}
+		found = true
+
+		m := envRefRegex.FindStringSubmatch(ref)
+		name, hasDefault, def := m[1], m[2] != "", m[3]
+		if name == "" {
+			name = m[4]
+		}
+		if IsSensitiveKey(name) {
+			sensitive = true
+		}
+
+		val, ok := e.lookup(name)
+		switch {
+		case ok && (val != "" || !hasDefault):
+			return val
+		case hasDefault:
+			return def
+		default:
+			if err == nil {
+				err = fmt.Errorf("%w: %s referenced by values key %s", abctl.ErrValuesEnvUndefined, name, path)
+			}
+			return ref
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if found {
+		if sensitive {
+			e.expanded[path] = RedactedValue
+		} else {
+			e.expanded[path] = out
+		}
+	}
+
+	return out, nil
+}
+
+// valuesPath returns the dot-delimited values key of the key within the parent path.
+func valuesPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,192 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+// testEnv returns a lookup func of the env.
+func testEnv(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestExpandEnvValues(t *testing.T) {
+	env := testEnv(map[string]string{
+		"AIRBYTE_DB_PASSWORD": "hunter2",
+		"DB_HOST":             "db.example.com",
+		"EMPTY":               "",
+	})
+
+	tests := []struct {
+		name   string
+		values map[string]any
+		want   map[string]any
+	}{
+		{
+			name:   "braces",
+			values: map[string]any{"password": "${AIRBYTE_DB_PASSWORD}"},
+			want:   map[string]any{"password": "hunter2"},
+		},
+		{
+			name:   "no braces",
+			values: map[string]any{"host": "$DB_HOST"},
+			want:   map[string]any{"host": "db.example.com"},
+		},
+		{
+			name:   "embedded",
+			values: map[string]any{"url": "jdbc:postgresql://${DB_HOST}:5432/$DB_HOST-db"},
+			want:   map[string]any{"url": "jdbc:postgresql://db.example.com:5432/db.example.com-db"},
+		},
+		{
+			name:   "default for undefined",
+			values: map[string]any{"port": "${DB_PORT:-5432}"},
+			want:   map[string]any{"port": "5432"},
+		},
+		{
+			name:   "default for empty",
+			values: map[string]any{"user": "${EMPTY:-airbyte}"},
+			want:   map[string]any{"user": "airbyte"},
+		},
+		{
+			name:   "empty default",
+			values: map[string]any{"user": "${DB_USER:-}"},
+			want:   map[string]any{"user": ""},
+		},
+		{
+			name:   "defined empty without default",
+			values: map[string]any{"user": "${EMPTY}"},
+			want:   map[string]any{"user": ""},
+		},
+		{
+			name:   "default unused",
+			values: map[string]any{"host": "${DB_HOST:-localhost}"},
+			want:   map[string]any{"host": "db.example.com"},
+		},
+		{
+			name:   "escaped",
+			values: map[string]any{"password": "pa$$word", "literal": "$${DB_HOST}"},
+			want:   map[string]any{"password": "pa$word", "literal": "${DB_HOST}"},
+		},
+		{
+			name:   "not a reference",
+			values: map[string]any{"price": "$5", "lone": "a $ b"},
+			want:   map[string]any{"price": "$5", "lone": "a $ b"},
+		},
+		{
+			name: "nested",
+			values: map[string]any{
+				"global": map[string]any{
+					"database": map[string]any{"host": "$DB_HOST", "port": 5432},
+					"hosts":    []any{"${DB_HOST}", map[string]any{"name": "$DB_HOST"}, true},
+				},
+			},
+			want: map[string]any{
+				"global": map[string]any{
+					"database": map[string]any{"host": "db.example.com", "port": 5432},
+					"hosts":    []any{"db.example.com", map[string]any{"name": "db.example.com"}, true},
+				},
+			},
+		},
+		{
+			name:   "keys are not expanded",
+			values: map[string]any{"$DB_HOST": "value"},
+			want:   map[string]any{"$DB_HOST": "value"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnvValues(tt.values, env)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("values mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestExpandEnvValues_Undefined(t *testing.T) {
+	values := map[string]any{"global": map[string]any{"database": map[string]any{"password": "${AIRBYTE_DB_PASSWORD}"}}}
+
+	_, err := ExpandEnvValues(values, testEnv(nil))
+	if !errors.Is(err, abctl.ErrValuesEnvUndefined) {
+		t.Fatalf("expected error %v, got %v", abctl.ErrValuesEnvUndefined, err)
+	}
+	if d := cmp.Diff("undefined environment variable in values file: AIRBYTE_DB_PASSWORD referenced by values key global.database.password", err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+
+	// the original values are not modified
+	want := map[string]any{"global": map[string]any{"database": map[string]any{"password": "${AIRBYTE_DB_PASSWORD}"}}}
+	if d := cmp.Diff(want, values); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestExpandEnvValues_Redacted(t *testing.T) {
+	e := envExpander{lookup: testEnv(map[string]string{"DB_PASSWORD": "hunter2", "DB_HOST": "db"}), expanded: map[string]string{}}
+	if _, err := e.expandMap(map[string]any{
+		"host":     "$DB_HOST",
+		"password": "$DB_HOST",
+		"url":      "postgres://airbyte:${DB_PASSWORD}@db",
+		"plain":    "unchanged",
+	}, ""); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// values of sensitive keys, or from sensitive variables, are redacted
+	want := map[string]string{
+		"host":     "db",
+		"password": RedactedValue,
+		"url":      RedactedValue,
+	}
+	if d := cmp.Diff(want, e.expanded); d != "" {
+		t.Errorf("expanded mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestBuildAirbyteValues_ExpandEnv(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("global:\n  database:\n    host: ${ABCTL_TEST_DB_HOST:-localhost}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ABCTL_TEST_DB_HOST", "db.example.com")
+
+	for _, expand := range []bool{true, false} {
+		got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+			TelemetryUser: "test-user",
+			Port:          8000,
+			ValuesFile:    valuesFile,
+			ExpandEnv:     expand,
+		}, "1.9.9")
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		var vals map[string]any
+		if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "${ABCTL_TEST_DB_HOST:-localhost}"
+		if expand {
+			want = "db.example.com"
+		}
+		host := vals["global"].(map[string]any)["database"].(map[string]any)["host"]
+		if d := cmp.Diff(want, host); d != "" {
+			t.Errorf("host mismatch with expand %t (-want +got):\n%s", expand, d)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package helm
+
+// envFromKey is the values key of the envFrom sources added to the containers of a component.
+const envFromKey = "extraEnvFrom"
+
+// envFromValues returns the helm values which give each of the platform components the environment variables of the
+// config maps and secrets, nil if there are none. The config maps come first, so a secret key overrides a config map
+// key of the same name.
+func envFromValues(components []string, secrets, configMaps []string) map[string]any {
+	if len(secrets) == 0 && len(configMaps) == 0 {
+		return nil
+	}
+
+	vals := map[string]any{}
+	for _, component := range components {
+		valuesAt(vals, component)[envFromKey] = envFromList(secrets, configMaps)
+	}
+
+	return vals
+}
+
+// envFromList returns the config maps and secrets as a helm values list of envFrom sources.
+func envFromList(secrets, configMaps []string) []any {
+	list := make([]any, 0, len(secrets)+len(configMaps))
+	for _, name := range configMaps {
+		list = append(list, map[string]any{"configMapRef": map[string]any{"name": name}})
+	}
+	for _, name := range secrets {
+		list = append(list, map[string]any{"secretRef": map[string]any{"name": name}})
+	}
+	return list
+}
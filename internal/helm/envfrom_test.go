@@ -0,0 +1,71 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+var testEnvFromList = []any{
+	map[string]any{"configMapRef": map[string]any{"name": "airbyte-config"}},
+	map[string]any{"secretRef": map[string]any{"name": "airbyte-creds"}},
+	map[string]any{"secretRef": map[string]any{"name": "airbyte-tokens"}},
+}
+
+func TestEnvFromValues(t *testing.T) {
+	if vals := envFromValues(probeComponentsV1, nil, nil); vals != nil {
+		t.Errorf("expected no values, got %v", vals)
+	}
+
+	want := map[string]any{
+		"server": map[string]any{"extraEnvFrom": testEnvFromList},
+		"worker": map[string]any{"extraEnvFrom": testEnvFromList},
+	}
+	got := envFromValues([]string{"server", "worker"}, []string{"airbyte-creds", "airbyte-tokens"}, []string{"airbyte-config"})
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestBuildAirbyteValues_EnvFrom(t *testing.T) {
+	tests := []struct {
+		chartVersion string
+		components   []string
+	}{
+		{chartVersion: "1.9.9", components: probeComponentsV1},
+		{chartVersion: "2.0.0", components: probeComponentsV2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chartVersion, func(t *testing.T) {
+			got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+				TelemetryUser:     "test-user",
+				Port:              8000,
+				EnvFromSecrets:    []string{"airbyte-creds", "airbyte-tokens"},
+				EnvFromConfigMaps: []string{"airbyte-config"},
+			}, tt.chartVersion)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			var vals map[string]any
+			if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, component := range tt.components {
+				if d := cmp.Diff(testEnvFromList, vals[component].(map[string]any)["extraEnvFrom"]); d != "" {
+					t.Errorf("%s envFrom mismatch (-want +got):\n%s", component, d)
+				}
+			}
+
+			// the dependencies are not platform components
+			postgresql, _ := vals["postgresql"].(map[string]any)
+			if _, ok := postgresql["extraEnvFrom"]; ok {
+				t.Error("expected no envFrom for postgresql")
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/common"
+)
+
+const (
+	FlavorCommunity  = "community"
+	FlavorEnterprise = "enterprise"
+)
+
+// ChartFlavor is a flavor of the airbyte helm chart, i.e. the edition of airbyte which is installed.
+// Every flavor is installed from the same chart, the flavor only selects the values overlay applied to it.
+type ChartFlavor struct {
+	Name string
+	// RequiresLicense is true if the flavor cannot be installed without a license key.
+	RequiresLicense bool
+	// values are the dot-delimited helm values of the flavor, e.g. global.edition=enterprise
+	values []string
+}
+
+// ChartFlavors are the available flavors, keyed by name.
+var ChartFlavors = map[string]ChartFlavor{
+	FlavorCommunity: {
+		Name: FlavorCommunity,
+	},
+	FlavorEnterprise: {
+		Name:            FlavorEnterprise,
+		RequiresLicense: true,
+		values: []string{
+			"global.edition=" + FlavorEnterprise,
+			"global.enterprise.secretName=" + common.AirbyteLicenseSecretName,
+			"global.enterprise.licenseKeySecretKey=" + common.AirbyteLicenseSecretKey,
+		},
+	},
+}
+
+// ChartFlavorNames returns the sorted names of the available flavors.
+func ChartFlavorNames() []string {
+	names := make([]string, 0, len(ChartFlavors))
+	for name := range ChartFlavors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ChartFlavorFor returns the flavor with the name, or an error if no such flavor exists.
+// An empty name returns the community flavor.
+func ChartFlavorFor(name string) (ChartFlavor, error) {
+	if name == "" {
+		name = FlavorCommunity
+	}
+	flavor, ok := ChartFlavors[name]
+	if !ok {
+		return ChartFlavor{}, fmt.Errorf("invalid chart flavor '%s': must be one of %s", name, strings.Join(ChartFlavorNames(), ", "))
+	}
+	return flavor, nil
+}
+
+// FlavorFromValues returns the name of the flavor the release values were installed with.
+// Values without an edition, or with an unknown one, are the community flavor.
+func FlavorFromValues(vals map[string]any) string {
+	global, _ := vals["global"].(map[string]any)
+	if edition, _ := global["edition"].(string); edition == FlavorEnterprise {
+		return FlavorEnterprise
+	}
+	return FlavorCommunity
+}
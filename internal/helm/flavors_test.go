@@ -0,0 +1,116 @@
+package helm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func TestChartFlavorFor(t *testing.T) {
+	tests := []struct {
+		name        string
+		wantFlavor  string
+		wantLicense bool
+		wantErr     bool
+	}{
+		{name: "", wantFlavor: FlavorCommunity},
+		{name: "community", wantFlavor: FlavorCommunity},
+		{name: "enterprise", wantFlavor: FlavorEnterprise, wantLicense: true},
+		{name: "premium", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flavor, err := ChartFlavorFor(tt.name)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.wantFlavor, flavor.Name); d != "" {
+				t.Errorf("flavor mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantLicense, flavor.RequiresLicense); d != "" {
+				t.Errorf("license mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestBuildAirbyteValues_ChartFlavor(t *testing.T) {
+	for _, chartVersion := range []string{"1.9.9", "2.0.0"} {
+		t.Run(chartVersion, func(t *testing.T) {
+			tests := []struct {
+				flavor     string
+				wantGlobal map[string]any
+			}{
+				{flavor: FlavorCommunity},
+				{
+					flavor: FlavorEnterprise,
+					wantGlobal: map[string]any{
+						"edition": FlavorEnterprise,
+						"enterprise": map[string]any{
+							"secretName":          common.AirbyteLicenseSecretName,
+							"licenseKeySecretKey": common.AirbyteLicenseSecretKey,
+						},
+					},
+				},
+			}
+
+			for _, tt := range tests {
+				got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+					TelemetryUser: "test-user",
+					Port:          8000,
+					ChartFlavor:   tt.flavor,
+				}, chartVersion)
+				if err != nil {
+					t.Fatal("unexpected error", err)
+				}
+
+				var vals map[string]any
+				if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+					t.Fatal(err)
+				}
+				global := vals["global"].(map[string]any)
+
+				gotGlobal := map[string]any{}
+				for _, key := range []string{"edition", "enterprise"} {
+					if v, ok := global[key]; ok {
+						gotGlobal[key] = v
+					}
+				}
+				if tt.wantGlobal == nil {
+					tt.wantGlobal = map[string]any{}
+				}
+				if d := cmp.Diff(tt.wantGlobal, gotGlobal); d != "" {
+					t.Errorf("%s values mismatch (-want +got):\n%s", tt.flavor, d)
+				}
+				if d := cmp.Diff(tt.flavor, FlavorFromValues(vals)); d != "" {
+					t.Errorf("flavor mismatch (-want +got):\n%s", d)
+				}
+			}
+		})
+	}
+}
+
+func TestFlavorFromValues(t *testing.T) {
+	tests := []struct {
+		name string
+		vals map[string]any
+		want string
+	}{
+		{name: "nil", want: FlavorCommunity},
+		{name: "no edition", vals: map[string]any{"global": map[string]any{"auth": true}}, want: FlavorCommunity},
+		{name: "community", vals: map[string]any{"global": map[string]any{"edition": "community"}}, want: FlavorCommunity},
+		{name: "enterprise", vals: map[string]any{"global": map[string]any{"edition": "enterprise"}}, want: FlavorEnterprise},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, FlavorFromValues(tt.vals)); d != "" {
+				t.Errorf("flavor mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -46,6 +46,17 @@ func New(kubecfg, kubectx, namespace string) (goHelm.Client, error) {
 	return helm, nil
 }
 
+// NewLocal returns a helm client which is not connected to any cluster.
+// It may only be used for operations which don't require a cluster, such as fetching charts.
+func NewLocal(namespace string) (goHelm.Client, error) {
+	helm, err := goHelm.New(ClientOptions(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create helm client: %w", err)
+	}
+
+	return helm, nil
+}
+
 var _ io.Writer = (*helmLogger)(nil)
 
 // helmLogger is used by the Client to convert all helm output into debug logs.
@@ -57,5 +68,7 @@ func (d helmLogger) Write(p []byte) (int, error) {
 }
 
 func (d helmLogger) Debug(format string, v ...interface{}) {
-	pterm.Debug.Println(fmt.Sprintf("helm: "+format, v...))
+	msg := fmt.Sprintf(format, v...)
+	waitLog.record(msg)
+	pterm.Debug.Println("helm: " + msg)
 }
@@ -0,0 +1,149 @@
+package helm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	goHelm "github.com/mittwald/go-helm-client"
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v3"
+
+	"github.com/airbytehq/abctl/internal/common"
+)
+
+// ImageCache caches the images resolved from the airbyte chart, as rendering the chart to find them is expensive.
+// Entries are keyed by chart version and flavor, and are invalidated when the chart source or the values change.
+// The cache is best effort, a cache which can't be read or written only results in the images being resolved again.
+type ImageCache struct {
+	// Path is the file the cache is stored in.
+	Path string
+	// Refresh ignores the cached entries, resolving the images again and replacing them.
+	Refresh bool
+}
+
+// imageCacheEntry are the images resolved for a chart version and flavor.
+type imageCacheEntry struct {
+	// Source identifies where the chart was loaded from, see chartSource.
+	Source string `json:"source"`
+	// Values is the digest of the values the chart was rendered with.
+	Values string   `json:"values"`
+	Images []string `json:"images"`
+}
+
+type imageCacheFile struct {
+	Entries map[string]imageCacheEntry `json:"entries"`
+}
+
+// FindImages returns the images of the chart, resolving them with FindImagesFromChart only if they aren't cached.
+// A chart without a version, i.e. the latest one, is never cached.
+func (c *ImageCache) FindImages(client goHelm.Client, valuesYaml, chartName, chartVersion string) ([]string, error) {
+	if chartVersion == "" {
+		return FindImagesFromChart(client, valuesYaml, chartName, chartVersion)
+	}
+
+	key := imageCacheKey(chartVersion, flavorFromValuesYaml(valuesYaml))
+	entry := imageCacheEntry{
+		Source: chartSource(chartName, chartVersion),
+		Values: digest(valuesYaml),
+	}
+
+	cache, err := c.read()
+	if err != nil {
+		pterm.Debug.Printfln("Ignoring the image cache: %s", err)
+	}
+
+	if cached, ok := cache.Entries[key]; ok && !c.Refresh {
+		if cached.Source == entry.Source && cached.Values == entry.Values {
+			pterm.Debug.Printfln("Using the cached images of chart %s", key)
+			return slices.Clone(cached.Images), nil
+		}
+		pterm.Debug.Printfln("Invalidating the cached images of chart %s, the chart source or values changed", key)
+	}
+
+	images, err := FindImagesFromChart(client, valuesYaml, chartName, chartVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Images = images
+	cache.Entries[key] = entry
+	if err := c.write(cache); err != nil {
+		pterm.Debug.Printfln("Unable to update the image cache: %s", err)
+	}
+	return images, nil
+}
+
+// read returns the cache stored at the path, an empty cache if it doesn't exist or can't be read.
+func (c *ImageCache) read() (imageCacheFile, error) {
+	cache := imageCacheFile{Entries: map[string]imageCacheEntry{}}
+
+	raw, err := os.ReadFile(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, fmt.Errorf("unable to read image cache: %w", err)
+	}
+
+	var stored imageCacheFile
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		return cache, fmt.Errorf("unable to parse image cache '%s': %w", c.Path, err)
+	}
+	if stored.Entries != nil {
+		cache = stored
+	}
+	return cache, nil
+}
+
+func (c *ImageCache) write(cache imageCacheFile) error {
+	raw, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal image cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+		return fmt.Errorf("unable to create image cache directory: %w", err)
+	}
+	if err := os.WriteFile(c.Path, raw, 0o644); err != nil {
+		return fmt.Errorf("unable to write image cache: %w", err)
+	}
+	return nil
+}
+
+func imageCacheKey(chartVersion, flavor string) string {
+	return chartVersion + "/" + flavor
+}
+
+// flavorFromValuesYaml returns the flavor the values install, the community flavor if they can't be parsed.
+func flavorFromValuesYaml(valuesYaml string) string {
+	var vals map[string]any
+	if err := yaml.Unmarshal([]byte(valuesYaml), &vals); err != nil {
+		return FlavorCommunity
+	}
+	return FlavorFromValues(vals)
+}
+
+// chartSource identifies where the chart is loaded from. A chart on disk includes its modification time,
+// so a chart rebuilt in place invalidates the images cached for it. Otherwise, it's the repository of the chart.
+func chartSource(chartName, chartVersion string) string {
+	if info, err := os.Stat(chartName); err == nil {
+		return fmt.Sprintf("file://%s@%d", chartName, info.ModTime().UnixNano())
+	}
+
+	repoURL := common.AirbyteRepoURLv1
+	if ChartIsV2Plus(chartVersion) {
+		repoURL = common.AirbyteRepoURLv2
+	}
+	return repoURL + "#" + chartName
+}
+
+func digest(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,152 @@
+package helm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/airbytehq/abctl/internal/helm/mock"
+)
+
+const cachedRenderedYaml = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: server
+spec:
+  containers:
+    - name: server
+      image: airbyte/server:1.1.0
+`
+
+// expectRender expects the chart to be rendered the given number of times.
+func expectRender(client *mock.MockClient, times int) {
+	client.EXPECT().AddOrUpdateChartRepo(gomock.Any()).Return(nil).Times(times)
+	client.EXPECT().InstallChart(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&release.Release{Manifest: cachedRenderedYaml}, nil).Times(times)
+}
+
+func TestImageCache_FindImages(t *testing.T) {
+	want := []string{"airbyte/server:1.1.0"}
+
+	tests := []struct {
+		name string
+		// first and second are the values, chart and version of the two consecutive lookups
+		first, second [3]string
+		refresh       bool
+		renders       int
+	}{
+		{
+			name:    "hit",
+			first:   [3]string{"", "airbyte/airbyte", "1.1.0"},
+			second:  [3]string{"", "airbyte/airbyte", "1.1.0"},
+			renders: 1,
+		},
+		{
+			name:    "miss on another version",
+			first:   [3]string{"", "airbyte/airbyte", "1.1.0"},
+			second:  [3]string{"", "airbyte/airbyte", "1.2.0"},
+			renders: 2,
+		},
+		{
+			name:    "miss on another flavor",
+			first:   [3]string{"", "airbyte/airbyte", "1.1.0"},
+			second:  [3]string{"global:\n  edition: enterprise\n", "airbyte/airbyte", "1.1.0"},
+			renders: 2,
+		},
+		{
+			name:    "invalidated by another chart source",
+			first:   [3]string{"", "airbyte/airbyte", "1.1.0"},
+			second:  [3]string{"", "mirror/airbyte", "1.1.0"},
+			renders: 2,
+		},
+		{
+			name:    "invalidated by other values",
+			first:   [3]string{"webapp:\n  enabled: true\n", "airbyte/airbyte", "1.1.0"},
+			second:  [3]string{"webapp:\n  enabled: false\n", "airbyte/airbyte", "1.1.0"},
+			renders: 2,
+		},
+		{
+			name:    "refresh",
+			first:   [3]string{"", "airbyte/airbyte", "1.1.0"},
+			second:  [3]string{"", "airbyte/airbyte", "1.1.0"},
+			refresh: true,
+			renders: 2,
+		},
+		{
+			name:    "latest version is never cached",
+			first:   [3]string{"", "airbyte/airbyte", ""},
+			second:  [3]string{"", "airbyte/airbyte", ""},
+			renders: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := mock.NewMockClient(gomock.NewController(t))
+			expectRender(client, tt.renders)
+
+			path := filepath.Join(t.TempDir(), "image-cache.json")
+			for i, lookup := range [][3]string{tt.first, tt.second} {
+				cache := &ImageCache{Path: path, Refresh: tt.refresh && i > 0}
+				got, err := cache.FindImages(client, lookup[0], lookup[1], lookup[2])
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if d := cmp.Diff(want, got); d != "" {
+					t.Errorf("images mismatch (-want +got):\n%s", d)
+				}
+			}
+		})
+	}
+}
+
+func TestImageCache_FindImages_LocalChartChanged(t *testing.T) {
+	client := mock.NewMockClient(gomock.NewController(t))
+	expectRender(client, 2)
+
+	dir := t.TempDir()
+	chart := filepath.Join(dir, "airbyte-1.1.0.tgz")
+	if err := os.WriteFile(chart, []byte("chart"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := &ImageCache{Path: filepath.Join(dir, "image-cache.json")}
+	lookup := func() {
+		if _, err := cache.FindImages(client, "", chart, "1.1.0"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	lookup()
+	lookup()
+
+	// rebuilding the chart in place invalidates the cached images
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(chart, later, later); err != nil {
+		t.Fatal(err)
+	}
+	lookup()
+}
+
+func TestImageCache_FindImages_CorruptCache(t *testing.T) {
+	client := mock.NewMockClient(gomock.NewController(t))
+	expectRender(client, 1)
+
+	path := filepath.Join(t.TempDir(), "image-cache.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := &ImageCache{Path: path}
+	for range 2 {
+		if _, err := cache.FindImages(client, "", "airbyte/airbyte", "1.1.0"); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package helm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/pterm/pterm"
+)
+
+// indexFetchAttempts is how many times the index of a chart repository is fetched before giving up.
+var indexFetchAttempts = 3
+
+// indexFetchBackoff is how long to wait before the first retry of a failed index fetch, doubling with every retry.
+// This variable should only be modified for testing purposes.
+var indexFetchBackoff = time.Second
+
+// IndexFetchError is returned when the index of a chart repository could not be fetched, even after retrying.
+// It wraps the abctl error of the class of the failure (abctl.ErrRepoIndexDNS, abctl.ErrRepoIndexTLS,
+// abctl.ErrRepoIndexTimeout or abctl.ErrRepoIndexStatus), which tells the user how to address it, if the class is known.
+type IndexFetchError struct {
+	// URL of the chart repository.
+	URL string
+	// Attempts is how many times the index was fetched.
+	Attempts int
+	// StatusCode is the HTTP status the repository responded with, 0 if it didn't respond.
+	StatusCode int
+	// Class is the abctl error of the class of the failure, nil if unknown.
+	Class *abctl.Error
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e *IndexFetchError) Error() string {
+	attempts := "1 attempt"
+	if e.Attempts != 1 {
+		attempts = fmt.Sprintf("%d attempts", e.Attempts)
+	}
+	if e.Class == nil {
+		return fmt.Sprintf("unable to download the index of the chart repository %s after %s: %s", e.URL, attempts, e.Err)
+	}
+	return fmt.Sprintf("unable to download the index of the chart repository %s after %s: %s: %s", e.URL, attempts, e.Class, e.Err)
+}
+
+func (e *IndexFetchError) Unwrap() []error {
+	if e.Class == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Class, e.Err}
+}
+
+// retriable returns true if a later attempt may not fail the same way. A certificate which can't be verified,
+// or a request the repository rejects, will fail again.
+func (e *IndexFetchError) retriable() bool {
+	switch e.Class {
+	case abctl.ErrRepoIndexTLS:
+		return false
+	case abctl.ErrRepoIndexStatus:
+		return e.StatusCode >= 500 || e.StatusCode == 408 || e.StatusCode == 429
+	default:
+		return true
+	}
+}
+
+// fetchIndex downloads the index of the chart repository at the url, returning the path it was downloaded to.
+// A failed download is retried, with backoff, up to indexFetchAttempts times, unless it can't succeed.
+func fetchIndex(url string, chartRepository chartRepo) (string, error) {
+	wait := indexFetchBackoff
+	for attempt := 1; ; attempt++ {
+		idxPath, err := chartRepository.DownloadIndexFile()
+		if err == nil {
+			return idxPath, nil
+		}
+
+		fetchErr := classifyIndexFetchError(url, err)
+		fetchErr.Attempts = attempt
+		if attempt >= indexFetchAttempts || !fetchErr.retriable() {
+			return "", fetchErr
+		}
+
+		pterm.Debug.Printfln("Attempt %d of %d to download the index of the chart repository %s failed, retrying in %s: %s",
+			attempt, indexFetchAttempts, url, wait, err)
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// statusPattern matches the status the helm http getter reports when a repository responds with an error,
+// e.g. "failed to fetch https://airbytehq.github.io/charts/index.yaml : 404 Not Found".
+var statusPattern = regexp.MustCompile(`failed to fetch .* : (\d{3})\b`)
+
+// classifyIndexFetchError returns the IndexFetchError of the error of an index fetch.
+// As the helm http getter doesn't return the status of the response, it has to be parsed from the message.
+func classifyIndexFetchError(url string, err error) *IndexFetchError {
+	fetchErr := &IndexFetchError{URL: url, Err: err}
+
+	var (
+		dnsErr       *net.DNSError
+		netErr       net.Error
+		unknownCA    x509.UnknownAuthorityError
+		invalidCert  x509.CertificateInvalidError
+		hostnameErr  x509.HostnameError
+		verification *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+	)
+	switch {
+	// a lookup which timed out is a timeout, not a missing host
+	case errors.As(err, &dnsErr) && !dnsErr.IsTimeout:
+		fetchErr.Class = abctl.ErrRepoIndexDNS
+	case errors.As(err, &unknownCA), errors.As(err, &invalidCert), errors.As(err, &hostnameErr),
+		errors.As(err, &verification), errors.As(err, &recordErr):
+		fetchErr.Class = abctl.ErrRepoIndexTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		fetchErr.Class = abctl.ErrRepoIndexTimeout
+	default:
+		if m := statusPattern.FindStringSubmatch(err.Error()); m != nil {
+			fetchErr.Class = abctl.ErrRepoIndexStatus
+			fetchErr.StatusCode, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	return fetchErr
+}
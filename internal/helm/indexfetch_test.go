@@ -0,0 +1,248 @@
+package helm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/google/go-cmp/cmp"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+const testRepoIndex = `apiVersion: v1
+entries:
+  airbyte:
+    - name: "airbyte"
+      version: "2.0.6"
+      urls: ["airbyte-2.0.6.tgz"]
+`
+
+func setIndexFetchBackoff(t *testing.T, d time.Duration) {
+	orig := indexFetchBackoff
+	indexFetchBackoff = d
+	t.Cleanup(func() { indexFetchBackoff = orig })
+}
+
+// fakeChartRepo fails to download the index with its err.
+type fakeChartRepo struct {
+	err   error
+	calls int
+}
+
+func (f *fakeChartRepo) DownloadIndexFile() (string, error) {
+	f.calls++
+	return "", f.err
+}
+
+func setFakeChartRepo(t *testing.T, f *fakeChartRepo) {
+	orig := defaultNewChartRepo
+	defaultNewChartRepo = func(*repo.Entry, getter.Providers) (chartRepo, error) {
+		return f, nil
+	}
+	t.Cleanup(func() { defaultNewChartRepo = orig })
+}
+
+func TestGetLatestAirbyteChartUrlFromRepoIndex_Retries(t *testing.T) {
+	setIndexFetchBackoff(t, time.Millisecond)
+
+	// the first two requests fail, as a flaky repository would
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, testRepoIndex)
+	}))
+	defer srv.Close()
+
+	chartURL, version, err := GetLatestAirbyteChartUrlFromRepoIndex("", srv.URL)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(srv.URL+"/airbyte-2.0.6.tgz", chartURL); d != "" {
+		t.Errorf("chart url mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff("2.0.6", version); d != "" {
+		t.Errorf("version mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(int32(3), requests.Load()); d != "" {
+		t.Errorf("requests mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestGetLatestAirbyteChartUrlFromRepoIndex_HTTPErrors(t *testing.T) {
+	setIndexFetchBackoff(t, time.Millisecond)
+
+	tests := []struct {
+		name         string
+		newServer    func(http.Handler) *httptest.Server
+		status       int
+		wantClass    *abctl.Error
+		wantStatus   int
+		wantAttempts int
+	}{
+		{
+			name:         "not found",
+			newServer:    httptest.NewServer,
+			status:       http.StatusNotFound,
+			wantClass:    abctl.ErrRepoIndexStatus,
+			wantStatus:   http.StatusNotFound,
+			wantAttempts: 1,
+		},
+		{
+			name:         "server error",
+			newServer:    httptest.NewServer,
+			status:       http.StatusBadGateway,
+			wantClass:    abctl.ErrRepoIndexStatus,
+			wantStatus:   http.StatusBadGateway,
+			wantAttempts: 3,
+		},
+		{
+			// the certificate of the test server isn't trusted
+			name:         "tls",
+			newServer:    httptest.NewTLSServer,
+			status:       http.StatusOK,
+			wantClass:    abctl.ErrRepoIndexTLS,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := tt.newServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, testRepoIndex)
+			}))
+			defer srv.Close()
+
+			_, _, err := GetLatestAirbyteChartUrlFromRepoIndex("", srv.URL)
+			if !errors.Is(err, tt.wantClass) {
+				t.Fatalf("expected error %v, got %v", tt.wantClass, err)
+			}
+			var fetchErr *IndexFetchError
+			if !errors.As(err, &fetchErr) {
+				t.Fatalf("expected an index fetch error, got %v", err)
+			}
+			if d := cmp.Diff(srv.URL, fetchErr.URL); d != "" {
+				t.Errorf("url mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantStatus, fetchErr.StatusCode); d != "" {
+				t.Errorf("status mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantAttempts, fetchErr.Attempts); d != "" {
+				t.Errorf("attempts mismatch (-want +got):\n%s", d)
+			}
+			// a handshake which fails never reaches the handler
+			if tt.wantStatus != 0 {
+				if d := cmp.Diff(int32(tt.wantAttempts), requests.Load()); d != "" {
+					t.Errorf("requests mismatch (-want +got):\n%s", d)
+				}
+			}
+		})
+	}
+}
+
+func TestGetLatestAirbyteChartUrlFromRepoIndex_NetworkErrors(t *testing.T) {
+	setIndexFetchBackoff(t, time.Millisecond)
+
+	const repoURL = "https://charts.example.com"
+
+	tests := []struct {
+		name      string
+		err       error
+		wantClass *abctl.Error
+	}{
+		{
+			name:      "dns",
+			err:       &url.Error{Op: "Get", URL: repoURL, Err: &net.DNSError{Err: "no such host", Name: "charts.example.com", IsNotFound: true}},
+			wantClass: abctl.ErrRepoIndexDNS,
+		},
+		{
+			name:      "dns timeout",
+			err:       &url.Error{Op: "Get", URL: repoURL, Err: &net.DNSError{Err: "i/o timeout", Name: "charts.example.com", IsTimeout: true}},
+			wantClass: abctl.ErrRepoIndexTimeout,
+		},
+		{
+			name:      "timeout",
+			err:       &url.Error{Op: "Get", URL: repoURL, Err: context.DeadlineExceeded},
+			wantClass: abctl.ErrRepoIndexTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeChartRepo{err: tt.err}
+			setFakeChartRepo(t, f)
+
+			_, _, err := GetLatestAirbyteChartUrlFromRepoIndex("", repoURL)
+			if !errors.Is(err, tt.wantClass) {
+				t.Fatalf("expected error %v, got %v", tt.wantClass, err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected the error of the fetch to be wrapped, got %v", err)
+			}
+			// these are transient, so every attempt is made
+			if d := cmp.Diff(indexFetchAttempts, f.calls); d != "" {
+				t.Errorf("attempts mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestGetLatestAirbyteChartUrlFromRepoIndex_UnknownError(t *testing.T) {
+	setIndexFetchBackoff(t, time.Millisecond)
+
+	errRefused := errors.New("dial tcp 127.0.0.1:443: connect: connection refused")
+	f := &fakeChartRepo{err: errRefused}
+	setFakeChartRepo(t, f)
+
+	_, _, err := GetLatestAirbyteChartUrlFromRepoIndex("", "https://charts.example.com")
+	if !errors.Is(err, errRefused) {
+		t.Fatalf("expected the error of the fetch, got %v", err)
+	}
+	var abctlErr *abctl.Error
+	if errors.As(err, &abctlErr) {
+		t.Errorf("expected no class, got %v", abctlErr)
+	}
+	want := "unable to download the index of the chart repository https://charts.example.com after 3 attempts: " + errRefused.Error()
+	if d := cmp.Diff(want, err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestResolveChartReference_Offline(t *testing.T) {
+	f := &fakeChartRepo{err: errors.New("unexpected fetch")}
+	setFakeChartRepo(t, f)
+
+	resolver := NewChartResolverWithURLs(nil, "https://charts.example.com/v1", "https://charts.example.com/v2")
+	resolver.Offline = true
+
+	if _, _, err := resolver.ResolveChartReference("", ""); !errors.Is(err, abctl.ErrOfflineChart) {
+		t.Errorf("expected error %v, got %v", abctl.ErrOfflineChart, err)
+	}
+	if d := cmp.Diff(0, f.calls); d != "" {
+		t.Errorf("expected the index not to be fetched (-want +got):\n%s", d)
+	}
+
+	// a version is resolved without the index
+	chartURL, version, err := resolver.ResolveChartReference("", "2.0.6")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !strings.HasSuffix(chartURL, "/v2/airbyte-2.0.6.tgz") || version != "2.0.6" {
+		t.Errorf("unexpected chart %s of version %s", chartURL, version)
+	}
+}
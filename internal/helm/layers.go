@@ -0,0 +1,175 @@
+package helm
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/maps"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed layers/*.yaml
+var builtinLayers embed.FS
+
+// LayerSource is where a values layer was found.
+type LayerSource string
+
+const (
+	// LayerBuiltin layers are shipped with abctl.
+	LayerBuiltin LayerSource = "builtin"
+	// LayerUser layers are read from the layers directory, shadowing any builtin layer of the same name.
+	LayerUser LayerSource = "user"
+)
+
+// Layer is a named overlay of helm chart values.
+type Layer struct {
+	Name        string
+	Description string
+	Version     string
+	Source      LayerSource
+	// Path of the layer, relative to the embedded layers for builtin layers.
+	Path string
+}
+
+// Layers returns every available layer, sorted by name. Layers in the dir shadow builtin layers of the same name.
+// A dir which does not exist contains no layers.
+func Layers(dir string) ([]Layer, error) {
+	byName := map[string]Layer{}
+
+	builtin, err := fs.Glob(builtinLayers, "layers/*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list builtin layers: %w", err)
+	}
+	for _, path := range builtin {
+		raw, err := builtinLayers.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read builtin layer '%s': %w", path, err)
+		}
+		layer := newLayer(path, LayerBuiltin, raw)
+		byName[layer.Name] = layer
+	}
+
+	user, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list layers in '%s': %w", dir, err)
+	}
+	for _, path := range user {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read layer '%s': %w", path, err)
+		}
+		layer := newLayer(path, LayerUser, raw)
+		byName[layer.Name] = layer
+	}
+
+	layers := make([]Layer, 0, len(byName))
+	for _, layer := range byName {
+		layers = append(layers, layer)
+	}
+	sort.Slice(layers, func(i, j int) bool {
+		return layers[i].Name < layers[j].Name
+	})
+
+	return layers, nil
+}
+
+// newLayer returns the layer of the raw file at path. The description and version are read from the
+// leading "# description: ..." and "# version: ..." comment lines of the file.
+func newLayer(path string, source LayerSource, raw []byte) Layer {
+	layer := Layer{
+		Name:   strings.TrimSuffix(filepath.Base(path), ".yaml"),
+		Source: source,
+		Path:   path,
+	}
+
+	s := bufio.NewScanner(bytes.NewReader(raw))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		key, value, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "description":
+			layer.Description = strings.TrimSpace(value)
+		case "version":
+			layer.Version = strings.TrimSpace(value)
+		}
+	}
+
+	return layer
+}
+
+// ErrUnknownLayer is returned when a requested layer does not exist.
+var ErrUnknownLayer = errors.New("unknown values layer")
+
+// ResolveLayers returns the values of the named layers merged in the order provided, each layer overriding the
+// values of the layers before it. Requesting the same layer more than once is an error, as the resulting order
+// would be ambiguous.
+func ResolveLayers(dir string, names []string) (map[string]any, error) {
+	merged := map[string]any{}
+	if len(names) == 0 {
+		return merged, nil
+	}
+
+	available, err := Layers(dir)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]Layer{}
+	for _, layer := range available {
+		byName[layer.Name] = layer
+	}
+
+	seen := map[string]struct{}{}
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("values layer '%s' was specified more than once", name)
+		}
+		seen[name] = struct{}{}
+
+		layer, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w '%s'", ErrUnknownLayer, name)
+		}
+
+		vals, err := layerValues(layer)
+		if err != nil {
+			return nil, err
+		}
+		maps.Merge(merged, vals)
+	}
+
+	return merged, nil
+}
+
+// layerValues returns the helm chart values of the layer.
+func layerValues(layer Layer) (map[string]any, error) {
+	if layer.Source == LayerUser {
+		return maps.FromYAMLFile(layer.Path)
+	}
+
+	raw, err := builtinLayers.ReadFile(layer.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read builtin layer '%s': %w", layer.Name, err)
+	}
+	var vals map[string]any
+	if err := yaml.Unmarshal(raw, &vals); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal builtin layer '%s': %w", layer.Name, err)
+	}
+	if vals == nil {
+		return map[string]any{}, nil
+	}
+	return vals, nil
+}
@@ -0,0 +1,141 @@
+package helm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeLayer writes a user layer to dir.
+func writeLayer(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name+".yaml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+	writeLayer(t, dir, "team", "# description: Team defaults.\n# version: 3\nglobal:\n  edition: community\n")
+	// shadows the builtin dev layer
+	writeLayer(t, dir, "dev", "global:\n  env_vars:\n    LOG_LEVEL: TRACE\n")
+
+	got, err := Layers(dir)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	want := []Layer{
+		{Name: "ci", Description: "Smaller job resources and no telemetry, for CI pipelines.", Version: "1", Source: LayerBuiltin, Path: "layers/ci.yaml"},
+		{Name: "demo", Description: "Quieter logging, for demos.", Version: "1", Source: LayerBuiltin, Path: "layers/demo.yaml"},
+		{Name: "dev", Source: LayerUser, Path: filepath.Join(dir, "dev.yaml")},
+		{Name: "team", Description: "Team defaults.", Version: "3", Source: LayerUser, Path: filepath.Join(dir, "team.yaml")},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("layers mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestLayers_MissingDir(t *testing.T) {
+	got, err := Layers(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	// only the builtin layers
+	if d := cmp.Diff(3, len(got)); d != "" {
+		t.Errorf("layer count mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestResolveLayers(t *testing.T) {
+	dir := t.TempDir()
+	writeLayer(t, dir, "team", "global:\n  env_vars:\n    LOG_LEVEL: INFO\n    TEAM: data\n")
+
+	tests := []struct {
+		name    string
+		layers  []string
+		want    map[string]any
+		wantErr error
+	}{
+		{
+			name: "none",
+			want: map[string]any{},
+		},
+		{
+			name:   "later layers override earlier ones",
+			layers: []string{"dev", "team"},
+			want: map[string]any{"global": map[string]any{"env_vars": map[string]any{
+				"LOG_LEVEL": "INFO",
+				"TEAM":      "data",
+			}}},
+		},
+		{
+			name:   "reversed order",
+			layers: []string{"team", "dev"},
+			want: map[string]any{"global": map[string]any{"env_vars": map[string]any{
+				"LOG_LEVEL": "DEBUG",
+				"TEAM":      "data",
+			}}},
+		},
+		{
+			name:    "unknown",
+			layers:  []string{"dev", "prod"},
+			wantErr: ErrUnknownLayer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveLayers(dir, tt.layers)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected error %v but got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("values mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestResolveLayers_Duplicate(t *testing.T) {
+	if _, err := ResolveLayers(t.TempDir(), []string{"dev", "ci", "dev"}); err == nil {
+		t.Error("expected error for duplicate layer")
+	}
+}
+
+func TestUserValues_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	valuesFile := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("global:\n  env_vars:\n    LOG_LEVEL: ERROR\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := userValues(ValuesOpts{Layers: []string{"ci", "dev"}, LayersDir: dir, ValuesFile: valuesFile})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the values file overrides every layer
+	want := map[string]any{"global": map[string]any{
+		"env_vars": map[string]any{
+			"LOG_LEVEL":         "ERROR",
+			"TRACKING_STRATEGY": "logging",
+		},
+		"jobs": map[string]any{"resources": map[string]any{"limits": map[string]any{
+			"cpu":    "1",
+			"memory": "2Gi",
+		}}},
+	}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+}
@@ -42,6 +42,7 @@ var defaultLoadIndexFile loadIndexFile = repo.LoadIndexFile
 // GetLatestAirbyteChartUrlFromRepoIndex fetches the latest stable Airbyte Helm chart URL and version
 // from the given Helm repository index. Returns the chart download URL, the chart version, and an error if any.
 // Only stable (non-prerelease) versions are considered.
+// The index is fetched with retries, an *IndexFetchError is returned if it could not be.
 func GetLatestAirbyteChartUrlFromRepoIndex(repoName, repoUrl string) (string, string, error) {
 	chartRepository, err := defaultNewChartRepo(&repo.Entry{
 		Name: repoName,
@@ -51,9 +52,9 @@ func GetLatestAirbyteChartUrlFromRepoIndex(repoName, repoUrl string) (string, st
 		return "", "", fmt.Errorf("unable to access repo index: %w", err)
 	}
 
-	idxPath, err := chartRepository.DownloadIndexFile()
+	idxPath, err := fetchIndex(repoUrl, chartRepository)
 	if err != nil {
-		return "", "", fmt.Errorf("unable to download index file: %w", err)
+		return "", "", err
 	}
 
 	idx, err := defaultLoadIndexFile(idxPath)
@@ -0,0 +1,98 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestBuildAirbyteValues_MergeStrategy(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	values := `
+global:
+  imagePullSecrets:
+    - name: regcred
+server:
+  tolerations:
+    - key: spot
+      operator: Exists
+      effect: NoSchedule
+  extraEnv:
+    - name: LOG_LEVEL
+      value: DEBUG
+`
+	if err := os.WriteFile(valuesFile, []byte(values), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		strategy        string
+		wantPullSecrets []any
+		wantTolerations []any
+	}{
+		{
+			strategy:        "",
+			wantPullSecrets: []any{map[string]any{"name": "regcred"}},
+			wantTolerations: []any{map[string]any{"key": "spot", "operator": "Exists", "effect": "NoSchedule"}},
+		},
+		{
+			strategy:        MergeStrategyReplace,
+			wantPullSecrets: []any{map[string]any{"name": "regcred"}},
+			wantTolerations: []any{map[string]any{"key": "spot", "operator": "Exists", "effect": "NoSchedule"}},
+		},
+		{
+			strategy: MergeStrategyDeep,
+			wantPullSecrets: []any{
+				map[string]any{"name": "airbyte-pull"},
+				map[string]any{"name": "regcred"},
+			},
+			// the toleration of the spot key is merged with that of abctl, the dedicated one is kept
+			wantTolerations: []any{
+				map[string]any{"key": "dedicated", "operator": "Equal", "value": "airbyte", "effect": "NoSchedule"},
+				map[string]any{"key": "spot", "operator": "Exists", "effect": "NoSchedule"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("strategy "+tt.strategy, func(t *testing.T) {
+			got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+				TelemetryUser:    "test-user",
+				Port:             8000,
+				ValuesFile:       valuesFile,
+				ImagePullSecrets: []string{"airbyte-pull"},
+				Tolerations: []corev1.Toleration{
+					{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "airbyte", Effect: corev1.TaintEffectNoSchedule},
+					{Key: "spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+				},
+				MergeStrategy: tt.strategy,
+			}, "2.0.0")
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			var vals map[string]any
+			if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+				t.Fatal(err)
+			}
+
+			global := vals["global"].(map[string]any)
+			if d := cmp.Diff(tt.wantPullSecrets, global["imagePullSecrets"]); d != "" {
+				t.Errorf("image pull secrets mismatch (-want +got):\n%s", d)
+			}
+
+			server := vals["server"].(map[string]any)
+			if d := cmp.Diff(tt.wantTolerations, server["tolerations"]); d != "" {
+				t.Errorf("tolerations mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff([]any{map[string]any{"name": "LOG_LEVEL", "value": "DEBUG"}}, server["extraEnv"]); d != "" {
+				t.Errorf("extra env mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
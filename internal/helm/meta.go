@@ -1,8 +1,7 @@
 package helm
 
 import (
-	"net/http"
-
+	"github.com/airbytehq/abctl/internal/http"
 	goHelm "github.com/mittwald/go-helm-client"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
@@ -21,7 +20,7 @@ func GetMetadataForRef(client goHelm.Client, chartRef string) (*chart.Metadata,
 
 // GetMetadataForURL fetches a remote chart archive (.tgz) from the given URL and returns its chart metadata.
 func GetMetadataForURL(url string) (*chart.Metadata, error) {
-	resp, err := http.Get(url)
+	resp, err := http.DefaultClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,60 @@
+package helm
+
+import "strings"
+
+// jobsComponent is the values key of the connector pods launched by the workload-launcher,
+// which are configured with labels and annotations rather than podLabels and podAnnotations.
+const jobsComponent = "global.jobs.kube"
+
+// metadataValues returns the helm values which apply the common labels and annotations to every Airbyte object,
+// nil if there are none.
+// They are set as the commonLabels and commonAnnotations, as well as the pod labels and annotations of each component,
+// as not every component of the chart applies the common ones to its pods.
+func metadataValues(components []string, labels, annotations map[string]string) map[string]any {
+	if len(labels) == 0 && len(annotations) == 0 {
+		return nil
+	}
+
+	vals := map[string]any{}
+	set := func(p map[string]any, key string, m map[string]string) {
+		if len(m) > 0 {
+			p[key] = stringMap(m)
+		}
+	}
+
+	set(vals, "commonLabels", labels)
+	set(vals, "commonAnnotations", annotations)
+	for _, component := range components {
+		p := valuesAt(vals, component)
+		if component == jobsComponent {
+			set(p, "labels", labels)
+			set(p, "annotations", annotations)
+		} else {
+			set(p, "podLabels", labels)
+			set(p, "podAnnotations", annotations)
+		}
+	}
+
+	return vals
+}
+
+// stringMap returns the map as a helm values map, so it can be merged with the user values.
+func stringMap(m map[string]string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// valuesAt returns the values map at the dot-delimited path, creating any missing maps along the way.
+func valuesAt(vals map[string]any, path string) map[string]any {
+	p := vals
+	for _, k := range strings.Split(path, ".") {
+		if _, ok := p[k]; !ok {
+			p[k] = map[string]any{}
+		}
+		p = p[k].(map[string]any)
+	}
+	return p
+}
@@ -0,0 +1,101 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func TestMetadataValues(t *testing.T) {
+	if vals := metadataValues(tolerationComponentsV1, nil, nil); vals != nil {
+		t.Errorf("expected no values, got %v", vals)
+	}
+
+	want := map[string]any{
+		"commonLabels": map[string]any{"team": "data"},
+		"global":       map[string]any{"jobs": map[string]any{"kube": map[string]any{"labels": map[string]any{"team": "data"}}}},
+		"server":       map[string]any{"podLabels": map[string]any{"team": "data"}},
+	}
+	got := metadataValues([]string{jobsComponent, "server"}, map[string]string{"team": "data"}, nil)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestBuildAirbyteValues_Metadata(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	values := `
+commonLabels:
+  owner: values-file
+server:
+  podAnnotations:
+    example.com/scrape: "false"
+`
+	if err := os.WriteFile(valuesFile, []byte(values), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	labels := map[string]string{"team": "data", "owner": "flag"}
+	annotations := map[string]string{"example.com/cost-center": "1234", "example.com/scrape": "true"}
+
+	tests := []struct {
+		chartVersion string
+		worker       string
+	}{
+		{chartVersion: "1.9.9", worker: "workload-launcher"},
+		{chartVersion: "2.0.0", worker: "workloadLauncher"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chartVersion, func(t *testing.T) {
+			got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+				TelemetryUser: "test-user",
+				Port:          8000,
+				Labels:        labels,
+				Annotations:   annotations,
+				ValuesFile:    valuesFile,
+			}, tt.chartVersion)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			var vals map[string]any
+			if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+				t.Fatal(err)
+			}
+
+			// the values file is merged with, and overrides, the labels and annotations
+			wantLabels := map[string]any{"team": "data", "owner": "values-file"}
+			if d := cmp.Diff(wantLabels, vals["commonLabels"]); d != "" {
+				t.Errorf("common labels mismatch (-want +got):\n%s", d)
+			}
+			wantAnnotations := map[string]any{"example.com/cost-center": "1234", "example.com/scrape": "true"}
+			if d := cmp.Diff(wantAnnotations, vals["commonAnnotations"]); d != "" {
+				t.Errorf("common annotations mismatch (-want +got):\n%s", d)
+			}
+
+			server := vals["server"].(map[string]any)
+			if d := cmp.Diff(map[string]any{"team": "data", "owner": "flag"}, server["podLabels"]); d != "" {
+				t.Errorf("server pod labels mismatch (-want +got):\n%s", d)
+			}
+			wantServerAnnotations := map[string]any{"example.com/cost-center": "1234", "example.com/scrape": "false"}
+			if d := cmp.Diff(wantServerAnnotations, server["podAnnotations"]); d != "" {
+				t.Errorf("server pod annotations mismatch (-want +got):\n%s", d)
+			}
+
+			worker := vals[tt.worker].(map[string]any)
+			if d := cmp.Diff(map[string]any{"team": "data", "owner": "flag"}, worker["podLabels"]); d != "" {
+				t.Errorf("%s pod labels mismatch (-want +got):\n%s", tt.worker, d)
+			}
+
+			kube := vals["global"].(map[string]any)["jobs"].(map[string]any)["kube"].(map[string]any)
+			if d := cmp.Diff(wantAnnotations, kube["annotations"]); d != "" {
+				t.Errorf("jobs annotations mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package helm
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 var nginxValuesTpl = template.Must(template.New("nginx-values").Parse(`
@@ -20,11 +23,29 @@ controller:
     proxy-body-size: 10m
     proxy-read-timeout: "600"
     proxy-send-timeout: "600"
+{{- if .Tolerations }}
+  tolerations: {{ .Tolerations }}
+  admissionWebhooks:
+    patch:
+      tolerations: {{ .Tolerations }}
+{{- end }}
 `))
 
-func BuildNginxValues(port int) (string, error) {
+// BuildNginxValues generates the values yaml for the nginx Helm chart.
+// The controller and its admission webhook jobs are given the tolerations, if any.
+func BuildNginxValues(port int, tolerations []corev1.Toleration) (string, error) {
+	data := map[string]any{"Port": port}
+	if len(tolerations) > 0 {
+		// json is valid yaml, which keeps the template free of any list indentation
+		raw, err := json.Marshal(tolerationList(tolerations))
+		if err != nil {
+			return "", fmt.Errorf("failed to build nginx tolerations: %w", err)
+		}
+		data["Tolerations"] = string(raw)
+	}
+
 	var buf bytes.Buffer
-	err := nginxValuesTpl.Execute(&buf, map[string]any{"Port": port})
+	err := nginxValuesTpl.Execute(&buf, data)
 	if err != nil {
 		return "", fmt.Errorf("failed to build nginx values yaml: %w", err)
 	}
@@ -0,0 +1,109 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResourcesPreset is a curated set of resource requests and limits for the airbyte components.
+type ResourcesPreset struct {
+	Name string
+	// MinCPUs is the number of cpus the preset is intended for.
+	MinCPUs int
+	// MinMemory is the memory, in bytes, the preset is intended for.
+	MinMemory int64
+	// values are the dot-delimited helm values of the preset, e.g. server.resources.requests.cpu=250m
+	values []string
+}
+
+const gib = 1024 * 1024 * 1024
+
+// ResourcesPresets are the available presets, keyed by name.
+// Only components which share the same values key across the v1 and v2 charts are configured.
+var ResourcesPresets = map[string]ResourcesPreset{
+	"small": {
+		Name:      "small",
+		MinCPUs:   2,
+		MinMemory: 4 * gib,
+		values: presetValues(
+			resources{component: "global.jobs", cpuLimit: "1", memLimit: "2Gi"},
+			resources{component: "server", cpu: "250m", mem: "512Mi", memLimit: "1Gi"},
+			resources{component: "worker", cpu: "250m", mem: "512Mi", memLimit: "1Gi"},
+			resources{component: "temporal", cpu: "100m", mem: "256Mi", memLimit: "512Mi"},
+			resources{component: "postgresql", cpu: "100m", mem: "256Mi", memLimit: "1Gi"},
+		),
+	},
+	"medium": {
+		Name:      "medium",
+		MinCPUs:   4,
+		MinMemory: 8 * gib,
+		values: presetValues(
+			resources{component: "global.jobs", cpuLimit: "3", memLimit: "4Gi"},
+			resources{component: "server", cpu: "500m", mem: "1Gi", memLimit: "2Gi"},
+			resources{component: "worker", cpu: "500m", mem: "1Gi", memLimit: "2Gi"},
+			resources{component: "temporal", cpu: "250m", mem: "512Mi", memLimit: "1Gi"},
+			resources{component: "postgresql", cpu: "250m", mem: "512Mi", memLimit: "2Gi"},
+		),
+	},
+	"large": {
+		Name:      "large",
+		MinCPUs:   8,
+		MinMemory: 16 * gib,
+		values: presetValues(
+			resources{component: "global.jobs", cpuLimit: "4", memLimit: "8Gi"},
+			resources{component: "server", cpu: "1", mem: "2Gi", memLimit: "4Gi"},
+			resources{component: "worker", cpu: "1", mem: "2Gi", memLimit: "4Gi"},
+			resources{component: "temporal", cpu: "500m", mem: "1Gi", memLimit: "2Gi"},
+			resources{component: "postgresql", cpu: "500m", mem: "1Gi", memLimit: "4Gi"},
+		),
+	},
+}
+
+// resources are the requests and limits of a single component, empty values are not set.
+type resources struct {
+	component string
+	cpu       string
+	mem       string
+	cpuLimit  string
+	memLimit  string
+}
+
+// presetValues returns the dot-delimited helm values of the resources.
+func presetValues(rs ...resources) []string {
+	var vals []string
+	for _, r := range rs {
+		for _, v := range []struct{ key, value string }{
+			{"requests.cpu", r.cpu},
+			{"requests.memory", r.mem},
+			{"limits.cpu", r.cpuLimit},
+			{"limits.memory", r.memLimit},
+		} {
+			if v.value != "" {
+				vals = append(vals, fmt.Sprintf("%s.resources.%s=%s", r.component, v.key, v.value))
+			}
+		}
+	}
+	return vals
+}
+
+// ResourcesPresetNames returns the names of the available presets, sorted by their intended machine size.
+func ResourcesPresetNames() []string {
+	names := make([]string, 0, len(ResourcesPresets))
+	for name := range ResourcesPresets {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return ResourcesPresets[names[i]].MinMemory < ResourcesPresets[names[j]].MinMemory
+	})
+	return names
+}
+
+// ResourcesPresetFor returns the preset with the name, or an error if no such preset exists.
+func ResourcesPresetFor(name string) (ResourcesPreset, error) {
+	preset, ok := ResourcesPresets[name]
+	if !ok {
+		return ResourcesPreset{}, fmt.Errorf("invalid resources preset '%s': must be one of %s", name, strings.Join(ResourcesPresetNames(), ", "))
+	}
+	return preset, nil
+}
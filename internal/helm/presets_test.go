@@ -0,0 +1,89 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func TestResourcesPresetNames(t *testing.T) {
+	if d := cmp.Diff([]string{"small", "medium", "large"}, ResourcesPresetNames()); d != "" {
+		t.Errorf("names mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestResourcesPresetFor(t *testing.T) {
+	for _, name := range ResourcesPresetNames() {
+		preset, err := ResourcesPresetFor(name)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %s", name, err)
+		}
+		if d := cmp.Diff(name, preset.Name); d != "" {
+			t.Errorf("name mismatch (-want +got):\n%s", d)
+		}
+	}
+
+	if _, err := ResourcesPresetFor("huge"); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+}
+
+func TestBuildAirbyteValues_ResourcesPreset(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("server:\n  resources:\n    limits:\n      memory: 3Gi\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chartVersion := range []string{"1.9.9", "2.0.0"} {
+		t.Run(chartVersion, func(t *testing.T) {
+			got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+				TelemetryUser:   "test-user",
+				Port:            8000,
+				ResourcesPreset: "small",
+				ValuesFile:      valuesFile,
+			}, chartVersion)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			var vals map[string]any
+			if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+				t.Fatal(err)
+			}
+
+			// the preset overrides the default job limits
+			wantJobs := map[string]any{"resources": map[string]any{"limits": map[string]any{"cpu": "1", "memory": "2Gi"}}}
+			if d := cmp.Diff(wantJobs, vals["global"].(map[string]any)["jobs"]); d != "" {
+				t.Errorf("jobs mismatch (-want +got):\n%s", d)
+			}
+
+			// the values file overrides the preset
+			wantServer := map[string]any{
+				"requests": map[string]any{"cpu": "250m", "memory": "512Mi"},
+				"limits":   map[string]any{"memory": "3Gi"},
+			}
+			server := vals["server"].(map[string]any)
+			if d := cmp.Diff(wantServer, server["resources"]); d != "" {
+				t.Errorf("server resources mismatch (-want +got):\n%s", d)
+			}
+
+			wantTemporal := map[string]any{
+				"requests": map[string]any{"cpu": "100m", "memory": "256Mi"},
+				"limits":   map[string]any{"memory": "512Mi"},
+			}
+			if d := cmp.Diff(wantTemporal, vals["temporal"].(map[string]any)["resources"]); d != "" {
+				t.Errorf("temporal resources mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestBuildAirbyteValues_InvalidResourcesPreset(t *testing.T) {
+	if _, err := BuildAirbyteValues(context.Background(), ValuesOpts{Port: 8000, ResourcesPreset: "huge"}, "2.0.0"); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+}
@@ -0,0 +1,141 @@
+package helm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// probeComponentsV1 are the values keys of the v1 chart platform components whose probes are tuned.
+// The bootloader, and the database, storage and temporal dependencies, are not tuned.
+var probeComponentsV1 = []string{
+	"connector-builder-server",
+	"cron",
+	"server",
+	"webapp",
+	"worker",
+	"workload-api-server",
+	"workload-launcher",
+}
+
+// probeComponentsV2 are the values keys of the v2 chart platform components whose probes are tuned.
+var probeComponentsV2 = []string{
+	"connectorBuilderServer",
+	"cron",
+	"server",
+	"webapp",
+	"worker",
+	"workloadApiServer",
+	"workloadLauncher",
+}
+
+// probeKeys are the values keys of the probes of each component.
+var probeKeys = []string{"livenessProbe", "readinessProbe"}
+
+// ProbeSettings tune the liveness and readiness probes of the platform components. Zero settings keep the chart defaults.
+type ProbeSettings struct {
+	// InitialDelay is how long after a container starts before it is first probed.
+	InitialDelay time.Duration
+	// Timeout is how long a single probe may take before it fails.
+	Timeout time.Duration
+	// FailureThreshold is how many consecutive probes must fail before the container is restarted or marked unready.
+	FailureThreshold int
+}
+
+// ProbePresets are the available probe presets, keyed by name, for machines on which the components start slowly.
+var ProbePresets = map[string]ProbeSettings{
+	"slow": {
+		InitialDelay:     2 * time.Minute,
+		Timeout:          10 * time.Second,
+		FailureThreshold: 10,
+	},
+	"very-slow": {
+		InitialDelay:     5 * time.Minute,
+		Timeout:          30 * time.Second,
+		FailureThreshold: 20,
+	},
+}
+
+// ProbePresetFor returns the preset with the name, or an error if no such preset exists.
+func ProbePresetFor(name string) (ProbeSettings, error) {
+	preset, ok := ProbePresets[name]
+	if !ok {
+		names := make([]string, 0, len(ProbePresets))
+		for n := range ProbePresets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return ProbeSettings{}, fmt.Errorf("invalid probe defaults '%s': must be one of %s", name, strings.Join(names, ", "))
+	}
+	return preset, nil
+}
+
+// Override returns the settings with each non-zero setting of o replacing its own.
+func (p ProbeSettings) Override(o ProbeSettings) ProbeSettings {
+	if o.InitialDelay != 0 {
+		p.InitialDelay = o.InitialDelay
+	}
+	if o.Timeout != 0 {
+		p.Timeout = o.Timeout
+	}
+	if o.FailureThreshold != 0 {
+		p.FailureThreshold = o.FailureThreshold
+	}
+	return p
+}
+
+// IsZero returns true if none of the settings are set.
+func (p ProbeSettings) IsZero() bool {
+	return p == ProbeSettings{}
+}
+
+// Validate returns an error if any of the settings are negative, or if a set duration is shorter than a second,
+// as kubernetes configures probes in whole seconds.
+func (p ProbeSettings) Validate() error {
+	for _, d := range []struct {
+		flag  string
+		value time.Duration
+	}{
+		{"--probe-initial-delay", p.InitialDelay},
+		{"--probe-timeout", p.Timeout},
+	} {
+		if d.value < 0 || (d.value > 0 && d.value < time.Second) {
+			return fmt.Errorf("invalid %s %s: must be at least 1s", d.flag, d.value)
+		}
+	}
+	if p.FailureThreshold < 0 {
+		return fmt.Errorf("invalid --probe-failure-threshold %d: must not be negative", p.FailureThreshold)
+	}
+	return nil
+}
+
+// probeValues returns the helm values which apply the settings to the liveness and readiness probes of each
+// of the components, nil if there are none. Durations are rounded up to whole seconds.
+func probeValues(components []string, p ProbeSettings) map[string]any {
+	if p.IsZero() {
+		return nil
+	}
+
+	seconds := func(d time.Duration) int {
+		return int((d + time.Second - 1) / time.Second)
+	}
+
+	vals := map[string]any{}
+	for _, component := range components {
+		for _, probe := range probeKeys {
+			v := valuesAt(vals, component+"."+probe)
+			if p.InitialDelay > 0 {
+				v["initialDelaySeconds"] = seconds(p.InitialDelay)
+			}
+			if p.Timeout > 0 {
+				v["timeoutSeconds"] = seconds(p.Timeout)
+			}
+			if p.FailureThreshold > 0 {
+				v["failureThreshold"] = p.FailureThreshold
+			}
+		}
+	}
+
+	return vals
+}
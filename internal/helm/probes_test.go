@@ -0,0 +1,115 @@
+package helm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProbeValues(t *testing.T) {
+	p := ProbeSettings{InitialDelay: 2 * time.Minute, Timeout: 10 * time.Second, FailureThreshold: 10}
+	probe := map[string]any{"initialDelaySeconds": 120, "timeoutSeconds": 10, "failureThreshold": 10}
+	probes := map[string]any{"livenessProbe": probe, "readinessProbe": probe}
+
+	tests := []struct {
+		name       string
+		components []string
+		want       map[string]any
+	}{
+		{
+			name:       "v1",
+			components: probeComponentsV1,
+			want: map[string]any{
+				"connector-builder-server": probes,
+				"cron":                     probes,
+				"server":                   probes,
+				"webapp":                   probes,
+				"worker":                   probes,
+				"workload-api-server":      probes,
+				"workload-launcher":        probes,
+			},
+		},
+		{
+			name:       "v2",
+			components: probeComponentsV2,
+			want: map[string]any{
+				"connectorBuilderServer": probes,
+				"cron":                   probes,
+				"server":                 probes,
+				"webapp":                 probes,
+				"worker":                 probes,
+				"workloadApiServer":      probes,
+				"workloadLauncher":       probes,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, probeValues(tt.components, p)); d != "" {
+				t.Errorf("values mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestProbeValues_Partial(t *testing.T) {
+	// only the set settings are applied, durations are rounded up to whole seconds
+	got := probeValues([]string{"server"}, ProbeSettings{Timeout: 1500 * time.Millisecond})
+	want := map[string]any{"server": map[string]any{
+		"livenessProbe":  map[string]any{"timeoutSeconds": 2},
+		"readinessProbe": map[string]any{"timeoutSeconds": 2},
+	}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+
+	if got := probeValues(probeComponentsV1, ProbeSettings{}); got != nil {
+		t.Errorf("expected no values but got %v", got)
+	}
+}
+
+func TestProbePresetFor(t *testing.T) {
+	got, err := ProbePresetFor("slow")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(ProbePresets["slow"], got); d != "" {
+		t.Errorf("preset mismatch (-want +got):\n%s", d)
+	}
+
+	if _, err := ProbePresetFor("glacial"); err == nil {
+		t.Error("expected error for an unknown preset")
+	}
+}
+
+func TestProbeSettings_Override(t *testing.T) {
+	got := ProbePresets["slow"].Override(ProbeSettings{Timeout: time.Minute})
+	want := ProbeSettings{InitialDelay: 2 * time.Minute, Timeout: time.Minute, FailureThreshold: 10}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("settings mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestProbeSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       ProbeSettings
+		wantErr bool
+	}{
+		{name: "zero", p: ProbeSettings{}},
+		{name: "preset", p: ProbePresets["very-slow"]},
+		{name: "negative initial delay", p: ProbeSettings{InitialDelay: -time.Second}, wantErr: true},
+		{name: "sub-second timeout", p: ProbeSettings{Timeout: 500 * time.Millisecond}, wantErr: true},
+		{name: "negative failure threshold", p: ProbeSettings{FailureThreshold: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.p.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %t but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
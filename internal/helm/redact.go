@@ -0,0 +1,97 @@
+package helm
+
+import (
+	"strings"
+)
+
+// RedactedValue replaces any value whose key is considered sensitive.
+const RedactedValue = "[REDACTED]"
+
+// redactedKeys contains the (lowercase) key fragments which mark a values entry as sensitive.
+// A key is redacted if it contains any of these fragments, e.g. "DATABASE_PASSWORD" or "clientSecret".
+var redactedKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"apikey",
+	"api_key",
+	"accesskey",
+	"access_key",
+	"privatekey",
+	"private_key",
+	"credentials",
+	"license",
+}
+
+// sensitiveValueMarkers identify values which are secret regardless of their key, e.g. a private key read
+// into "tls.key" with --set-file.
+var sensitiveValueMarkers = []string{
+	"PRIVATE KEY-----",
+	"BEGIN OPENSSH PRIVATE KEY",
+	"BEGIN PGP PRIVATE KEY BLOCK",
+}
+
+// IsSensitiveValue returns true if the value looks like secret material, such as a PEM encoded private key.
+func IsSensitiveValue(value string) bool {
+	for _, m := range sensitiveValueMarkers {
+		if strings.Contains(value, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSensitiveKey returns true if the key name matches any of the known sensitive key fragments.
+// Keys referencing a secret by name (e.g. "secretName") are not considered sensitive.
+func IsSensitiveKey(key string) bool {
+	k := strings.ToLower(key)
+	if strings.HasSuffix(k, "name") {
+		return false
+	}
+	for _, r := range redactedKeys {
+		if strings.Contains(k, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactValues returns a copy of the values map with all sensitive values replaced by RedactedValue.
+// A value is sensitive if its key is, see IsSensitiveKey, or if it looks like secret material, see IsSensitiveValue.
+// Nested maps and lists are walked, the original map is not modified.
+func RedactValues(values map[string]any) map[string]any {
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		if IsSensitiveKey(k) {
+			// keep nested structures (e.g. imagePullSecrets) visible, only the leaf values are hidden
+			if _, ok := v.(map[string]any); !ok {
+				if _, ok := v.([]any); !ok {
+					out[k] = RedactedValue
+					continue
+				}
+			}
+		}
+		out[k] = redactValue(v)
+	}
+	return out
+}
+
+func redactValue(v any) any {
+	switch z := v.(type) {
+	case map[string]any:
+		return RedactValues(z)
+	case []any:
+		out := make([]any, len(z))
+		for i, item := range z {
+			out[i] = redactValue(item)
+		}
+		return out
+	case string:
+		if IsSensitiveValue(z) {
+			return RedactedValue
+		}
+		return z
+	default:
+		return v
+	}
+}
@@ -0,0 +1,30 @@
+package helm
+
+import (
+	"fmt"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// ValidateValuesSchema validates the values yaml against the values.schema.json shipped with the chart,
+// including the schemas of any subcharts. The values are first coalesced with the chart defaults,
+// matching how helm itself validates values during an install.
+// Charts which do not ship a schema are not validated and no error is returned.
+func ValidateValuesSchema(chrt *chart.Chart, valuesYAML string) error {
+	values, err := chartutil.ReadValues([]byte(valuesYAML))
+	if err != nil {
+		return fmt.Errorf("unable to read values: %w", err)
+	}
+
+	coalesced, err := chartutil.CoalesceValues(chrt, values)
+	if err != nil {
+		return fmt.Errorf("unable to coalesce values with chart defaults: %w", err)
+	}
+
+	if err := chartutil.ValidateAgainstSchema(chrt, coalesced); err != nil {
+		return fmt.Errorf("values do not match the chart schema: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package helm
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestValidateValuesSchema(t *testing.T) {
+	schema, err := os.ReadFile("testdata/values.schema.json")
+	if err != nil {
+		t.Fatal("unable to read schema", err)
+	}
+
+	tests := []struct {
+		name    string
+		schema  []byte
+		values  string
+		wantErr []string
+	}{
+		{
+			name:   "valid",
+			schema: schema,
+			values: "global:\n  airbyteUrl: http://localhost:8000\nserver:\n  replicaCount: 2\n",
+		},
+		{
+			name:    "wrong type",
+			schema:  schema,
+			values:  "server:\n  replicaCount: two\n",
+			wantErr: []string{"replicaCount", "integer"},
+		},
+		{
+			name:    "misspelled key",
+			schema:  schema,
+			values:  "server:\n  resource:\n    limits: {}\n",
+			wantErr: []string{"resource"},
+		},
+		{
+			name:   "no schema",
+			values: "server:\n  replicaCount: two\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chrt := &chart.Chart{
+				Metadata: &chart.Metadata{Name: "airbyte", Version: "1.0.0"},
+				Schema:   tt.schema,
+			}
+
+			err := ValidateValuesSchema(chrt, tt.values)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatal("unexpected error", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to contain %q but got %q", want, err.Error())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package helm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/maps"
+)
+
+// MaxSetFileSize limits the size of a file read into a value. Helm stores the values of a release in a secret,
+// which kubernetes limits to 1MiB, so a larger file could never be installed.
+const MaxSetFileSize = 512 * 1024
+
+var (
+	errInvalidSetFile  = errors.New("invalid set file")
+	errSetFileTooLarge = errors.New("set file too large")
+)
+
+// SetFile is a value read from a file, mirroring helm's --set-file.
+type SetFile struct {
+	// Key is the dot-delimited path of the value, e.g. "tls.crt". A literal dot is escaped as `\.`.
+	Key string
+	// Path is the file the value is read from.
+	Path string
+}
+
+// ParseSetFiles parses the specs, in the format <KEY>=<PATH>, verifying every file is readable and no larger
+// than MaxSetFileSize.
+func ParseSetFiles(specs []string) ([]SetFile, error) {
+	files := make([]SetFile, 0, len(specs))
+	for _, spec := range specs {
+		key, path, ok := strings.Cut(spec, "=")
+		if !ok || key == "" || path == "" {
+			return nil, fmt.Errorf("%w '%s': must be in the format <KEY>=<PATH>", errInvalidSetFile, spec)
+		}
+		for _, part := range splitKey(key) {
+			if part == "" {
+				return nil, fmt.Errorf("%w '%s': key '%s' contains an empty segment", errInvalidSetFile, spec, key)
+			}
+		}
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w '%s': %w", errInvalidSetFile, spec, err)
+		}
+		if !fi.Mode().IsRegular() {
+			return nil, fmt.Errorf("%w '%s': '%s' is not a regular file", errInvalidSetFile, spec, path)
+		}
+		if fi.Size() > MaxSetFileSize {
+			return nil, fmt.Errorf("%w: '%s' is %d bytes, the limit is %d bytes", errSetFileTooLarge, path, fi.Size(), MaxSetFileSize)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w '%s': %w", errInvalidSetFile, spec, err)
+		}
+		_ = f.Close()
+
+		files = append(files, SetFile{Key: key, Path: path})
+	}
+	return files, nil
+}
+
+// setFileValues reads every file into the value of its key, a later file overriding any earlier file with the same key.
+// The contents are kept as a string, they are never parsed as yaml.
+func setFileValues(files []SetFile) (map[string]any, error) {
+	vals := map[string]any{}
+	for _, file := range files {
+		contents, err := readSetFile(file.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := splitKey(file.Key)
+		var v any = contents
+		for i := len(keys) - 1; i >= 0; i-- {
+			v = map[string]any{keys[i]: v}
+		}
+		maps.Merge(vals, v.(map[string]any))
+	}
+	return vals, nil
+}
+
+// readSetFile returns the contents of the file, enforcing MaxSetFileSize as the file may have grown since it was parsed.
+func readSetFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read set file '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	contents, err := io.ReadAll(io.LimitReader(f, MaxSetFileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("unable to read set file '%s': %w", path, err)
+	}
+	if len(contents) > MaxSetFileSize {
+		return "", fmt.Errorf("%w: '%s' is larger than the limit of %d bytes", errSetFileTooLarge, path, MaxSetFileSize)
+	}
+	return string(contents), nil
+}
+
+// splitKey splits the dot-delimited key into its segments, a `\.` being a literal dot within a segment
+// (e.g. `podAnnotations.example\.com/owner`).
+func splitKey(key string) []string {
+	var parts []string
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == '\\' && i+1 < len(key) && key[i+1] == '.':
+			b.WriteByte('.')
+			i++
+		case key[i] == '.':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(key[i])
+		}
+	}
+	return append(parts, b.String())
+}
@@ -0,0 +1,130 @@
+package helm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// writeSetFile writes the content to a file named name in dir, returning its path.
+func writeSetFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseSetFiles(t *testing.T) {
+	dir := t.TempDir()
+	cert := writeSetFile(t, dir, "cert.pem", "-----BEGIN CERTIFICATE-----\n")
+
+	got, err := ParseSetFiles([]string{"tls.crt=" + cert, `podAnnotations.example\.com/owner=` + cert})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	want := []SetFile{
+		{Key: "tls.crt", Path: cert},
+		{Key: `podAnnotations.example\.com/owner`, Path: cert},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("set files mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestParseSetFiles_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	cert := writeSetFile(t, dir, "cert.pem", "cert")
+
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{name: "no path", spec: "tls.crt"},
+		{name: "empty key", spec: "=" + cert},
+		{name: "empty path", spec: "tls.crt="},
+		{name: "empty key segment", spec: "tls..crt=" + cert},
+		{name: "missing file", spec: "tls.crt=" + filepath.Join(dir, "missing.pem")},
+		{name: "directory", spec: "tls.crt=" + dir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSetFiles([]string{tt.spec}); !errors.Is(err, errInvalidSetFile) {
+				t.Errorf("expected errInvalidSetFile but got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseSetFiles_TooLarge(t *testing.T) {
+	dir := t.TempDir()
+	limit := writeSetFile(t, dir, "limit.json", strings.Repeat("a", MaxSetFileSize))
+	large := writeSetFile(t, dir, "large.json", strings.Repeat("a", MaxSetFileSize+1))
+
+	if _, err := ParseSetFiles([]string{"config=" + limit}); err != nil {
+		t.Error("unexpected error for a file at the limit", err)
+	}
+	if _, err := ParseSetFiles([]string{"config=" + large}); !errors.Is(err, errSetFileTooLarge) {
+		t.Errorf("expected errSetFileTooLarge but got %v", err)
+	}
+
+	// a file which grew after it was parsed
+	files, err := ParseSetFiles([]string{"config=" + limit})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	writeSetFile(t, dir, "limit.json", strings.Repeat("a", MaxSetFileSize+1))
+	if _, err := setFileValues(files); !errors.Is(err, errSetFileTooLarge) {
+		t.Errorf("expected errSetFileTooLarge but got %v", err)
+	}
+}
+
+func TestSetFileValues(t *testing.T) {
+	dir := t.TempDir()
+	cert := writeSetFile(t, dir, "cert.pem", "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n")
+	config := writeSetFile(t, dir, "config.json", `{"enabled": true}`)
+
+	got, err := setFileValues([]SetFile{
+		{Key: "tls.crt", Path: cert},
+		{Key: "server.config", Path: cert},
+		// a later file overrides an earlier one with the same key
+		{Key: "server.config", Path: config},
+		{Key: `podAnnotations.example\.com/owner`, Path: config},
+	})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the contents are kept as a string, never parsed
+	want := map[string]any{
+		"tls":            map[string]any{"crt": "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"},
+		"server":         map[string]any{"config": `{"enabled": true}`},
+		"podAnnotations": map[string]any{"example.com/owner": `{"enabled": true}`},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestUserValues_SetFilePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	valuesFile := writeSetFile(t, dir, "values.yaml", "tls:\n  crt: from-values\n  key: from-values\n")
+	cert := writeSetFile(t, dir, "cert.pem", "from-file")
+
+	got, err := userValues(ValuesOpts{ValuesFile: valuesFile, SetFiles: []SetFile{{Key: "tls.crt", Path: cert}}})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the set file overrides the values file, without removing its other values
+	want := map[string]any{"tls": map[string]any{"crt": "from-file", "key": "from-values"}}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,70 @@
+package helm
+
+import corev1 "k8s.io/api/core/v1"
+
+// tolerationComponentsV1 are the values keys of the v1 chart components which are given the tolerations,
+// as well as the pod labels and annotations.
+// The global.jobs.kube key covers the connector pods launched by the workload-launcher.
+var tolerationComponentsV1 = []string{
+	jobsComponent,
+	"airbyte-bootloader",
+	"connector-builder-server",
+	"cron",
+	"minio",
+	"postgresql",
+	"server",
+	"temporal",
+	"webapp",
+	"worker",
+	"workload-api-server",
+	"workload-launcher",
+}
+
+// tolerationComponentsV2 are the values keys of the v2 chart components which are given the tolerations,
+// as well as the pod labels and annotations.
+var tolerationComponentsV2 = []string{
+	jobsComponent,
+	"airbyte-bootloader",
+	"connectorBuilderServer",
+	"cron",
+	"minio",
+	"postgresql",
+	"server",
+	"temporal",
+	"webapp",
+	"worker",
+	"workloadApiServer",
+	"workloadLauncher",
+}
+
+// tolerationValues returns the helm values which give each of the components the tolerations, nil if there are none.
+// The values are a map as the dot-delimited values don't support lists.
+func tolerationValues(components []string, tolerations []corev1.Toleration) map[string]any {
+	if len(tolerations) == 0 {
+		return nil
+	}
+
+	vals := map[string]any{}
+	for _, component := range components {
+		valuesAt(vals, component)["tolerations"] = tolerationList(tolerations)
+	}
+
+	return vals
+}
+
+// tolerationList returns the tolerations as a helm values list.
+func tolerationList(tolerations []corev1.Toleration) []any {
+	list := make([]any, len(tolerations))
+	for i, t := range tolerations {
+		m := map[string]any{
+			"key":      t.Key,
+			"operator": string(t.Operator),
+			"effect":   string(t.Effect),
+		}
+		if t.Value != "" {
+			m["value"] = t.Value
+		}
+		list[i] = m
+	}
+	return list
+}
@@ -0,0 +1,119 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var testTolerations = []corev1.Toleration{
+	{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "airbyte", Effect: corev1.TaintEffectNoSchedule},
+	{Key: "spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+}
+
+var testTolerationList = []any{
+	map[string]any{"key": "dedicated", "operator": "Equal", "value": "airbyte", "effect": "NoSchedule"},
+	map[string]any{"key": "spot", "operator": "Exists", "effect": "NoExecute"},
+}
+
+func TestTolerationValues(t *testing.T) {
+	if vals := tolerationValues(tolerationComponentsV1, nil); vals != nil {
+		t.Errorf("expected no values, got %v", vals)
+	}
+
+	want := map[string]any{
+		"global": map[string]any{"jobs": map[string]any{"kube": map[string]any{"tolerations": testTolerationList}}},
+		"server": map[string]any{"tolerations": testTolerationList},
+	}
+	if d := cmp.Diff(want, tolerationValues([]string{"global.jobs.kube", "server"}, testTolerations)); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestBuildAirbyteValues_Tolerations(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("worker:\n  tolerations: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		chartVersion string
+		components   []string
+	}{
+		{chartVersion: "1.9.9", components: tolerationComponentsV1},
+		{chartVersion: "2.0.0", components: tolerationComponentsV2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chartVersion, func(t *testing.T) {
+			got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+				TelemetryUser: "test-user",
+				Port:          8000,
+				Tolerations:   testTolerations,
+				ValuesFile:    valuesFile,
+			}, tt.chartVersion)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			var vals map[string]any
+			if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+				t.Fatal(err)
+			}
+
+			jobs := vals["global"].(map[string]any)["jobs"].(map[string]any)
+			if d := cmp.Diff(testTolerationList, jobs["kube"].(map[string]any)["tolerations"]); d != "" {
+				t.Errorf("jobs tolerations mismatch (-want +got):\n%s", d)
+			}
+
+			if d := cmp.Diff(testTolerationList, vals["server"].(map[string]any)["tolerations"]); d != "" {
+				t.Errorf("server tolerations mismatch (-want +got):\n%s", d)
+			}
+
+			// the values file overrides the tolerations
+			if d := cmp.Diff([]any{}, vals["worker"].(map[string]any)["tolerations"]); d != "" {
+				t.Errorf("worker tolerations mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestBuildNginxValues_Tolerations(t *testing.T) {
+	got, err := BuildNginxValues(8000, testTolerations)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var vals map[string]any
+	if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+		t.Fatal(err)
+	}
+
+	controller := vals["controller"].(map[string]any)
+	if d := cmp.Diff(testTolerationList, controller["tolerations"]); d != "" {
+		t.Errorf("controller tolerations mismatch (-want +got):\n%s", d)
+	}
+	patch := controller["admissionWebhooks"].(map[string]any)["patch"].(map[string]any)
+	if d := cmp.Diff(testTolerationList, patch["tolerations"]); d != "" {
+		t.Errorf("admission webhook tolerations mismatch (-want +got):\n%s", d)
+	}
+
+	// no tolerations, no tolerations values
+	got, err = BuildNginxValues(8000, nil)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	vals = nil
+	if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+		t.Fatal(err)
+	}
+	controller = vals["controller"].(map[string]any)
+	if _, ok := controller["tolerations"]; ok {
+		t.Error("expected no controller tolerations")
+	}
+}
@@ -0,0 +1,155 @@
+package helm
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// clusterValues are the dotted paths of the values which only apply to the cluster a release was installed to: the
+// id of the installation, the URL it is served at, the image pull secrets and storage class existing in that cluster.
+// abctl sets them again from the flags of a new installation, where they apply.
+var clusterValues = []string{
+	"global.airbyteUrl",
+	"global.env_vars.AIRBYTE_INSTALLATION_ID",
+	"global.imagePullSecrets",
+	"global.storageClass",
+}
+
+// nodeKeys are the keys which pin pods to the nodes of a cluster, omitted at any depth.
+var nodeKeys = []string{"nodeName", "nodeSelector"}
+
+// TransferableValues returns a copy of the values of a release, without the values which don't transfer to another
+// cluster: the clusterValues, the nodeKeys, and the hostPath volumes, along with their mounts, as the paths are those
+// of the nodes of the release's cluster. The sorted dotted paths of the omitted values are returned too.
+// The values aren't modified.
+func TransferableValues(values map[string]any) (map[string]any, []string) {
+	var omitted []string
+	out := transferableMap("", values, &omitted)
+	sort.Strings(omitted)
+	return out, omitted
+}
+
+// transferableMap returns a copy of the map at the path without the values which don't transfer, appending their
+// paths to the omitted.
+func transferableMap(path string, m map[string]any, omitted *[]string) map[string]any {
+	// the volumes and volume mounts of a component are siblings, e.g. extraVolumes and extraVolumeMounts
+	hostPaths := hostPathVolumes(m)
+
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		p := joinPath(path, k)
+		if slices.Contains(nodeKeys, k) || slices.Contains(clusterValues, p) {
+			*omitted = append(*omitted, p)
+			continue
+		}
+
+		list, ok := v.([]any)
+		if !ok {
+			out[k] = transferableValue(p, v, omitted)
+			continue
+		}
+		kept := make([]any, 0, len(list))
+		for i, item := range list {
+			itemPath := fmt.Sprintf("%s[%d]", p, i)
+			if isHostPathVolume(item) || isMountOf(item, hostPaths) {
+				*omitted = append(*omitted, itemPath)
+				continue
+			}
+			kept = append(kept, transferableValue(itemPath, item, omitted))
+		}
+		out[k] = kept
+	}
+	return out
+}
+
+// transferableValue returns a copy of the value at the path without the values which don't transfer.
+func transferableValue(path string, v any, omitted *[]string) any {
+	switch z := v.(type) {
+	case map[string]any:
+		return transferableMap(path, z, omitted)
+	case []any:
+		out := make([]any, len(z))
+		for i, item := range z {
+			out[i] = transferableValue(fmt.Sprintf("%s[%d]", path, i), item, omitted)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// copyValues returns a deep copy of the values, so they can be merged into without modifying them.
+// It never returns nil.
+func copyValues(values map[string]any) map[string]any {
+	out := make(map[string]any, len(values))
+	for k, v := range values {
+		out[k] = copyValue(v)
+	}
+	return out
+}
+
+func copyValue(v any) any {
+	switch z := v.(type) {
+	case map[string]any:
+		return copyValues(z)
+	case []any:
+		out := make([]any, len(z))
+		for i, item := range z {
+			out[i] = copyValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// hostPathVolumes returns the names of the hostPath volumes of the lists of the map.
+func hostPathVolumes(m map[string]any) map[string]bool {
+	names := map[string]bool{}
+	for _, v := range m {
+		list, ok := v.([]any)
+		if !ok {
+			continue
+		}
+		for _, item := range list {
+			if !isHostPathVolume(item) {
+				continue
+			}
+			if name, ok := item.(map[string]any)["name"].(string); ok {
+				names[name] = true
+			}
+		}
+	}
+	return names
+}
+
+// isHostPathVolume returns true if the list item is a volume whose source is a path of the node.
+func isHostPathVolume(item any) bool {
+	volume, ok := item.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = volume["hostPath"]
+	return ok
+}
+
+// isMountOf returns true if the list item is a volume mount of one of the volumes.
+func isMountOf(item any, volumes map[string]bool) bool {
+	mount, ok := item.(map[string]any)
+	if !ok {
+		return false
+	}
+	if _, ok := mount["mountPath"]; !ok {
+		return false
+	}
+	name, _ := mount["name"].(string)
+	return volumes[name]
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
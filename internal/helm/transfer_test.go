@@ -0,0 +1,137 @@
+package helm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTransferableValues(t *testing.T) {
+	release := map[string]any{
+		"global": map[string]any{
+			"airbyteUrl":       "http://airbyte.old-host:8000",
+			"edition":          "community",
+			"env_vars":         map[string]any{"AIRBYTE_INSTALLATION_ID": "old-user", "LOG_LEVEL": "DEBUG"},
+			"imagePullSecrets": []any{map[string]any{"name": "regcred"}},
+			"storageClass":     "gp3",
+		},
+		"server": map[string]any{
+			"nodeSelector": map[string]any{"kubernetes.io/hostname": "old-node"},
+			"extraVolumes": []any{
+				map[string]any{"name": "connectors", "hostPath": map[string]any{"path": "/connectors"}},
+				map[string]any{"name": "config", "configMap": map[string]any{"name": "airbyte-config"}},
+			},
+			"extraVolumeMounts": []any{
+				map[string]any{"name": "connectors", "mountPath": "/connectors"},
+				map[string]any{"name": "config", "mountPath": "/config"},
+			},
+		},
+		"worker": map[string]any{"replicaCount": 2},
+	}
+
+	got, omitted := TransferableValues(release)
+
+	want := map[string]any{
+		"global": map[string]any{
+			"edition":  "community",
+			"env_vars": map[string]any{"LOG_LEVEL": "DEBUG"},
+		},
+		"server": map[string]any{
+			"extraVolumes": []any{
+				map[string]any{"name": "config", "configMap": map[string]any{"name": "airbyte-config"}},
+			},
+			"extraVolumeMounts": []any{
+				map[string]any{"name": "config", "mountPath": "/config"},
+			},
+		},
+		"worker": map[string]any{"replicaCount": 2},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+
+	wantOmitted := []string{
+		"global.airbyteUrl",
+		"global.env_vars.AIRBYTE_INSTALLATION_ID",
+		"global.imagePullSecrets",
+		"global.storageClass",
+		"server.extraVolumeMounts[0]",
+		"server.extraVolumes[0]",
+		"server.nodeSelector",
+	}
+	if d := cmp.Diff(wantOmitted, omitted); d != "" {
+		t.Errorf("omitted mismatch (-want +got):\n%s", d)
+	}
+
+	// the values of the release are left as they are
+	if _, ok := release["global"].(map[string]any)["airbyteUrl"]; !ok {
+		t.Error("expected the release values to be unmodified")
+	}
+}
+
+func TestBuildAirbyteValues_BaseValues(t *testing.T) {
+	valuesFile := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("worker:\n  replicaCount: 3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	base := map[string]any{
+		"global": map[string]any{
+			"auth":     map[string]any{"enabled": false},
+			"env_vars": map[string]any{"LOG_LEVEL": "DEBUG"},
+		},
+		"worker": map[string]any{"replicaCount": 2, "debug": true},
+	}
+
+	got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+		TelemetryUser: "test-user",
+		Port:          8000,
+		ValuesFile:    valuesFile,
+		BaseValues:    base,
+	}, "2.0.0")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var vals map[string]any
+	if err := yaml.Unmarshal([]byte(got), &vals); err != nil {
+		t.Fatal(err)
+	}
+
+	global := vals["global"].(map[string]any)
+	// the values of abctl are merged over the base values, the values file over both
+	wantAuth := map[string]any{"enabled": true}
+	if d := cmp.Diff(wantAuth, global["auth"]); d != "" {
+		t.Errorf("auth mismatch (-want +got):\n%s", d)
+	}
+	wantEnvVars := map[string]any{"AIRBYTE_INSTALLATION_ID": "test-user", "LOG_LEVEL": "DEBUG"}
+	if d := cmp.Diff(wantEnvVars, global["env_vars"]); d != "" {
+		t.Errorf("env vars mismatch (-want +got):\n%s", d)
+	}
+	wantWorker := map[string]any{"replicaCount": 3, "debug": true}
+	if d := cmp.Diff(wantWorker, vals["worker"]); d != "" {
+		t.Errorf("worker mismatch (-want +got):\n%s", d)
+	}
+
+	// the base values are copied, not merged into
+	if d := cmp.Diff(map[string]any{"enabled": false}, base["global"].(map[string]any)["auth"]); d != "" {
+		t.Errorf("expected the base values to be unmodified (-want +got):\n%s", d)
+	}
+}
+
+func TestBuildAirbyteValues_BaseValuesNoDefaults(t *testing.T) {
+	got, err := BuildAirbyteValues(context.Background(), ValuesOpts{
+		NoDefaultValues: true,
+		BaseValues:      map[string]any{"global": map[string]any{"edition": "community"}},
+	}, "2.0.0")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("global:\n    edition: community\n", got); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,42 @@
+package helm
+
+import (
+	"regexp"
+	"sync"
+)
+
+// notReadyPattern matches the messages helm logs for a resource it is waiting on, e.g.
+// "Deployment is not ready: airbyte-abctl/airbyte-abctl-server. 0 out of 1 expected pods are ready".
+var notReadyPattern = regexp.MustCompile(`^\w+ (is not (ready|bound|completed)|does not have [\w ]+): \S`)
+
+// waitLog records the resource helm last reported as not ready while waiting on a release.
+var waitLog notReadyLog
+
+type notReadyLog struct {
+	mu   sync.Mutex
+	last string
+}
+
+func (l *notReadyLog) record(msg string) {
+	if !notReadyPattern.MatchString(msg) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.last = msg
+}
+
+// LastNotReady returns the message of the resource helm last reported as not ready while waiting on a release,
+// empty if there is none. As helm waits on one resource at a time, it is the resource a timed out wait was stuck on.
+func LastNotReady() string {
+	waitLog.mu.Lock()
+	defer waitLog.mu.Unlock()
+	return waitLog.last
+}
+
+// ResetNotReady forgets the resource helm last reported as not ready, it should be called before each release.
+func ResetNotReady() {
+	waitLog.mu.Lock()
+	defer waitLog.mu.Unlock()
+	waitLog.last = ""
+}
@@ -0,0 +1,28 @@
+package helm
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLastNotReady(t *testing.T) {
+	ResetNotReady()
+	t.Cleanup(ResetNotReady)
+
+	logger := helmLogger{}
+	logger.Debug("beginning wait for %d resources with timeout of %s", 12, "1h0m0s")
+	logger.Debug("Deployment is not ready: %s/%s. %d out of %d expected pods are ready", "airbyte-abctl", "airbyte-abctl-server", 0, 1)
+	logger.Debug("PersistentVolumeClaim is not bound: %s/%s", "airbyte-abctl", "airbyte-minio-pv-claim-airbyte-minio-0")
+	logger.Debug("creating 1 resource(s)")
+
+	want := "PersistentVolumeClaim is not bound: airbyte-abctl/airbyte-minio-pv-claim-airbyte-minio-0"
+	if d := cmp.Diff(want, LastNotReady()); d != "" {
+		t.Errorf("not ready mismatch (-want +got):\n%s", d)
+	}
+
+	ResetNotReady()
+	if got := LastNotReady(); got != "" {
+		t.Errorf("expected no resource but got %q", got)
+	}
+}
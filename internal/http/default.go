@@ -7,5 +7,6 @@ import (
 
 // DefaultClient is the default HTTP client with reasonable timeout
 var DefaultClient = &http.Client{
-	Timeout: 30 * time.Second,
-}
\ No newline at end of file
+	Timeout:   30 * time.Second,
+	Transport: &UserAgentTransport{Base: &RateLimitTransport{}},
+}
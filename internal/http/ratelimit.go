@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateUnits maps the supported (lowercase) rate units to their size in bytes.
+var rateUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+}
+
+// ParseRate converts a human-readable rate (e.g. "5MB/s", "500KiB/s", "1048576") into bytes per second.
+func ParseRate(s string) (int64, error) {
+	raw := strings.ToLower(strings.TrimSpace(s))
+	raw = strings.TrimSuffix(raw, "/s")
+
+	// split the numeric portion from the unit portion
+	idx := strings.IndexFunc(raw, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	num, unit := raw, ""
+	if idx >= 0 {
+		num, unit = raw[:idx], strings.TrimSpace(raw[idx:])
+	}
+
+	value, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: must be in the format <NUMBER><UNIT>/s (e.g. 5MB/s)", s)
+	}
+
+	multiplier, ok := rateUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid rate %q: unsupported unit %q", s, unit)
+	}
+
+	rate := int64(value * multiplier)
+	if rate <= 0 {
+		return 0, fmt.Errorf("invalid rate %q: must be greater than zero", s)
+	}
+
+	return rate, nil
+}
+
+// RateLimiter limits the aggregate throughput of all the readers created from it.
+// Each read reserves time on a shared schedule, so concurrent readers split the configured rate between them.
+type RateLimiter struct {
+	mu sync.Mutex
+	// bytesPerSec is the aggregate rate shared by all readers
+	bytesPerSec int64
+	// next is the earliest time the next read is allowed to proceed
+	next time.Time
+}
+
+// NewRateLimiter returns a RateLimiter which allows bytesPerSec across all of its readers.
+func NewRateLimiter(bytesPerSec int64) *RateLimiter {
+	return &RateLimiter{bytesPerSec: bytesPerSec}
+}
+
+// Reader wraps the r with this rate limiter.
+// Reads will block when the aggregate rate has been exceeded or return an error if the ctx is canceled.
+func (l *RateLimiter) Reader(ctx context.Context, r io.ReadCloser) io.ReadCloser {
+	return &rateLimitedReader{ctx: ctx, r: r, limiter: l}
+}
+
+// chunk returns the maximum number of bytes a single read may consume.
+// Keeping this to a fraction of the rate keeps the throughput smooth.
+func (l *RateLimiter) chunk() int {
+	c := l.bytesPerSec / 10
+	if c < 1 {
+		return 1
+	}
+	if c > 32*1024 {
+		return 32 * 1024
+	}
+	return int(c)
+}
+
+// wait blocks until the n bytes which have been read are allowed by the rate.
+func (l *RateLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(float64(n) / float64(l.bytesPerSec) * float64(time.Second)))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+var _ io.ReadCloser = (*rateLimitedReader)(nil)
+
+type rateLimitedReader struct {
+	ctx     context.Context
+	r       io.ReadCloser
+	limiter *RateLimiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if c := r.limiter.chunk(); len(p) > c {
+		p = p[:c]
+	}
+
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if errWait := r.limiter.wait(r.ctx, n); errWait != nil {
+			return n, errWait
+		}
+	}
+	return n, err
+}
+
+func (r *rateLimitedReader) Close() error {
+	return r.r.Close()
+}
+
+// downloadLimiter, when set, limits the aggregate throughput of the response bodies read through a RateLimitTransport.
+var downloadLimiter *RateLimiter
+
+// SetDownloadLimiter limits abctl's downloads, those of the DefaultClient, with the limiter. A nil limiter removes the
+// limit.
+func SetDownloadLimiter(limiter *RateLimiter) {
+	downloadLimiter = limiter
+}
+
+// RateLimitTransport limits the read of every response body with the limiter set by SetDownloadLimiter, after
+// sending the request with the Base transport. A nil Base uses http.DefaultTransport.
+type RateLimitTransport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || downloadLimiter == nil {
+		return resp, err
+	}
+	resp.Body = downloadLimiter.Reader(req.Context(), resp.Body)
+	return resp, nil
+}
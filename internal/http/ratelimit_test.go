@@ -0,0 +1,156 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "5MB/s", want: 5_000_000},
+		{input: "5mb/s", want: 5_000_000},
+		{input: "500KB/s", want: 500_000},
+		{input: "1.5MiB/s", want: 1_572_864},
+		{input: "1GB/s", want: 1_000_000_000},
+		{input: "2M", want: 2_000_000},
+		{input: "1024", want: 1024},
+		{input: " 10 KiB/s ", want: 10_240},
+		{input: "", wantErr: true},
+		{input: "fast", wantErr: true},
+		{input: "5XB/s", wantErr: true},
+		{input: "0MB/s", wantErr: true},
+		{input: "-1MB/s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRate(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("rate mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_Reader(t *testing.T) {
+	const rate = 100 * 1000
+	data := bytes.Repeat([]byte("a"), 30*1000)
+
+	limiter := NewRateLimiter(rate)
+	r := limiter.Reader(context.Background(), io.NopCloser(bytes.NewReader(data)))
+
+	start := time.Now()
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	elapsed := time.Since(start)
+
+	if d := cmp.Diff(len(data), len(got)); d != "" {
+		t.Errorf("read size mismatch (-want +got):\n%s", d)
+	}
+	// 30KB at 100KB/s should take ~300ms, allow some slack in either direction
+	if elapsed < 250*time.Millisecond {
+		t.Errorf("reader was not rate limited, took %s", elapsed)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("reader was limited too aggressively, took %s", elapsed)
+	}
+}
+
+func TestRateLimiter_Aggregate(t *testing.T) {
+	const rate = 100 * 1000
+	limiter := NewRateLimiter(rate)
+
+	// three concurrent readers of 10KB each should share the limit, taking ~300ms total
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := limiter.Reader(context.Background(), io.NopCloser(bytes.NewReader(bytes.Repeat([]byte("a"), 10*1000))))
+			if _, err := io.Copy(io.Discard, r); err != nil {
+				t.Error("unexpected error", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("readers were not limited in aggregate, took %s", elapsed)
+	}
+}
+
+func TestRateLimiter_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// a very low rate guarantees the reader would otherwise block
+	limiter := NewRateLimiter(1)
+	r := limiter.Reader(ctx, io.NopCloser(bytes.NewReader([]byte("abc"))))
+
+	_, err := r.Read(make([]byte, 1))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled but got %v", err)
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 30*1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	t.Cleanup(func() { SetDownloadLimiter(nil) })
+
+	get := func() time.Duration {
+		start := time.Now()
+		resp, err := DefaultClient.Get(srv.URL)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		defer resp.Body.Close()
+		got, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(len(data), len(got)); d != "" {
+			t.Errorf("read size mismatch (-want +got):\n%s", d)
+		}
+		return time.Since(start)
+	}
+
+	if elapsed := get(); elapsed > 250*time.Millisecond {
+		t.Errorf("download without a limiter was limited, took %s", elapsed)
+	}
+
+	// 30KB at 100KB/s should take ~300ms
+	SetDownloadLimiter(NewRateLimiter(100 * 1000))
+	if elapsed := get(); elapsed < 250*time.Millisecond {
+		t.Errorf("download was not rate limited, took %s", elapsed)
+	}
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/airbytehq/abctl/internal/build"
+)
+
+// userAgent, when set, overrides the default User-Agent of abctl's outbound requests.
+var userAgent string
+
+// SetUserAgent overrides the User-Agent of abctl's outbound requests. An empty userAgent restores the default.
+func SetUserAgent(ua string) {
+	userAgent = ua
+}
+
+// UserAgent returns the User-Agent of abctl's outbound requests, see build.UserAgent for the default.
+func UserAgent() string {
+	if userAgent != "" {
+		return userAgent
+	}
+	return build.UserAgent()
+}
+
+// UserAgentTransport sets the UserAgent on every request without a User-Agent, before sending it with the Base
+// transport. A nil Base uses http.DefaultTransport.
+type UserAgentTransport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *UserAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if req.Header.Get("User-Agent") == "" {
+		// a RoundTripper must not modify the request
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", UserAgent())
+	}
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/build"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAgentTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		override  string
+		requestUA string
+		expectUA  string
+	}{
+		{
+			name:     "default",
+			expectUA: build.UserAgent(),
+		},
+		{
+			name:     "override",
+			override: "acme-egress/1.0",
+			expectUA: "acme-egress/1.0",
+		},
+		{
+			name:      "request user agent is kept",
+			override:  "acme-egress/1.0",
+			requestUA: "custom/2.0",
+			expectUA:  "custom/2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { SetUserAgent("") })
+			SetUserAgent(tt.override)
+
+			var capturedUA string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				capturedUA = r.Header.Get("User-Agent")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+			if tt.requestUA != "" {
+				req.Header.Set("User-Agent", tt.requestUA)
+			}
+
+			resp, err := DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectUA, capturedUA)
+			// the request of the caller is left untouched
+			assert.Equal(t, tt.requestUA, req.Header.Get("User-Agent"))
+		})
+	}
+}
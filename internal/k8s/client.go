@@ -3,15 +3,21 @@ package k8s
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"path"
+	"slices"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,49 +25,83 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
 )
 
-// DefaultPersistentVolumeSize is the size of the disks created by the persistent-volumes and requested by
+// DefaultPersistentVolumeSize is the default size of the disks created by the persistent-volumes and requested by
 // the persistent-volume-claims.
 var DefaultPersistentVolumeSize = resource.MustParse("500Mi")
 
+// DefaultStorageClass is the storage class of the persistent-volumes and persistent-volume-claims, the built-in
+// storage class of kind.
+const DefaultStorageClass = "standard"
+
 // Client primarily for testing purposes
 type Client interface {
+	// DeploymentAddTolerations adds the tolerations to the pod template of the deployment name in the provided namespace,
+	// skipping any it already has.
+	DeploymentAddTolerations(ctx context.Context, namespace, name string, tolerations []corev1.Toleration) error
 	// DeploymentList returns a list of all the services within the namespace
 	DeploymentList(ctx context.Context, namespace string) (*appsv1.DeploymentList, error)
 	// DeploymentRestart will force a restart of the deployment name in the provided namespace.
 	// This is a blocking call, it should only return once the deployment has completed.
 	DeploymentRestart(ctx context.Context, namespace, name string) error
 
+	// EventsList returns a list of all the events within the namespace
+	EventsList(ctx context.Context, namespace string) (*eventsv1.EventList, error)
 	EventsWatch(ctx context.Context, namespace string) (watch.Interface, error)
 
 	IngressCreate(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error
 	IngressExists(ctx context.Context, namespace string, ingress string) bool
 	IngressUpdate(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error
 
+	// JobList returns a list of all the jobs within the namespace
+	JobList(ctx context.Context, namespace string) (*batchv1.JobList, error)
+
 	LogsGet(ctx context.Context, namespace string, name string) (string, error)
 
 	NamespaceCreate(ctx context.Context, namespace string) error
 	NamespaceExists(ctx context.Context, namespace string) bool
 	NamespaceDelete(ctx context.Context, namespace string) error
 
-	PersistentVolumeCreate(ctx context.Context, namespace, name string) error
+	// NodeList returns the nodes of the cluster.
+	NodeList(ctx context.Context) (*corev1.NodeList, error)
+
+	// PersistentVolumeCreate creates a persistent volume of the storageClass with the capacity of size.
+	PersistentVolumeCreate(ctx context.Context, namespace, name, storageClass string, size resource.Quantity) error
 	PersistentVolumeExists(ctx context.Context, namespace, name string) bool
 	PersistentVolumeDelete(ctx context.Context, namespace, name string) error
 
-	PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName string) error
+	// PersistentVolumeClaimCreate creates a persistent volume claim of the storageClass, bound to the volumeName, requesting size.
+	PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName, storageClass string, size resource.Quantity) error
 	PersistentVolumeClaimExists(ctx context.Context, namespace, name, volumeName string) bool
 	PersistentVolumeClaimDelete(ctx context.Context, namespace, name, volumeName string) error
+	PersistentVolumeClaimGet(ctx context.Context, namespace, name string) (*corev1.PersistentVolumeClaim, error)
 
+	// PodDelete deletes the pod, which its controller will recreate.
+	PodDelete(ctx context.Context, namespace, name string) error
 	PodList(ctx context.Context, namespace string) (*corev1.PodList, error)
+	// PodProxyGet performs an HTTP GET request against the port and path of the pod, proxied through the api-server.
+	PodProxyGet(ctx context.Context, namespace, name, port, path string) ([]byte, error)
+	// PodPortForward forwards the ports, in the format <LOCAL_PORT>:<POD_PORT>, from localhost to the pod.
+	// It blocks until the ctx is done or the connection to the pod is lost, closing ready once the ports are listening.
+	// See PortForward for a higher level helper.
+	PodPortForward(ctx context.Context, namespace, name string, ports []string, ready chan struct{}) error
 
 	SecretCreateOrUpdate(ctx context.Context, secret corev1.Secret) error
 	SecretPatch(ctx context.Context, namespace, name string, patchData []byte, patchType types.PatchType) error
 	// SecretDeleteCollection deletes multiple secrets.
 	// Note this takes a `type` and not a `name`.  All secrets matching this type will be removed.
 	SecretDeleteCollection(ctx context.Context, namespace, _type string) error
+	// SecretDeleteLabeled deletes the secrets matching the label selector, returning the names of those deleted.
+	SecretDeleteLabeled(ctx context.Context, namespace, selector string) ([]string, error)
 	SecretGet(ctx context.Context, namespace, name string) (*corev1.Secret, error)
 
+	// StorageClassList returns a list of all the storage classes of the cluster
+	StorageClassList(ctx context.Context) (*storagev1.StorageClassList, error)
+
 	// ConfigMapGet retrieves a ConfigMap by name
 	ConfigMapGet(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
 	// ConfigMapList lists ConfigMaps in a namespace
@@ -84,6 +124,29 @@ var _ Client = (*DefaultK8sClient)(nil)
 // DefaultK8sClient converts the official kubernetes client to our more manageable (and testable) interface
 type DefaultK8sClient struct {
 	ClientSet kubernetes.Interface
+	// RestConfig is required for port-forwarding, which isn't supported by the ClientSet.
+	RestConfig *rest.Config
+}
+
+func (d *DefaultK8sClient) DeploymentAddTolerations(ctx context.Context, namespace, name string, tolerations []corev1.Toleration) error {
+	deployment, err := d.ClientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get deployment %s: %w", name, err)
+	}
+
+	existing := deployment.Spec.Template.Spec.Tolerations
+	for _, t := range tolerations {
+		if !slices.Contains(existing, t) {
+			existing = append(existing, t)
+		}
+	}
+	deployment.Spec.Template.Spec.Tolerations = existing
+
+	if _, err := d.ClientSet.AppsV1().Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update deployment %s: %w", name, err)
+	}
+
+	return nil
 }
 
 func (d *DefaultK8sClient) DeploymentList(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
@@ -196,13 +259,13 @@ func (d *DefaultK8sClient) NamespaceDelete(ctx context.Context, namespace string
 	return d.ClientSet.CoreV1().Namespaces().Delete(ctx, namespace, metav1.DeleteOptions{})
 }
 
-func (d *DefaultK8sClient) PersistentVolumeCreate(ctx context.Context, namespace, name string) error {
+func (d *DefaultK8sClient) PersistentVolumeCreate(ctx context.Context, namespace, name, storageClass string, size resource.Quantity) error {
 	hostPathType := corev1.HostPathDirectoryOrCreate
 
 	pv := &corev1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
 		Spec: corev1.PersistentVolumeSpec{
-			Capacity: corev1.ResourceList{corev1.ResourceStorage: DefaultPersistentVolumeSize},
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: size},
 			PersistentVolumeSource: corev1.PersistentVolumeSource{
 				HostPath: &corev1.HostPathVolumeSource{
 					// TODO: is this a problem on windows?
@@ -214,7 +277,7 @@ func (d *DefaultK8sClient) PersistentVolumeCreate(ctx context.Context, namespace
 				corev1.ReadWriteOnce,
 			},
 			PersistentVolumeReclaimPolicy: "Retain",
-			StorageClassName:              "standard",
+			StorageClassName:              storageClass,
 		},
 	}
 
@@ -234,14 +297,12 @@ func (d *DefaultK8sClient) PersistentVolumeDelete(ctx context.Context, _, name s
 	return d.ClientSet.CoreV1().PersistentVolumes().Delete(ctx, name, metav1.DeleteOptions{})
 }
 
-func (d *DefaultK8sClient) PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName string) error {
-	storageClass := "standard"
-
+func (d *DefaultK8sClient) PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName, storageClass string, size resource.Quantity) error {
 	pvc := &corev1.PersistentVolumeClaim{
 		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: DefaultPersistentVolumeSize}},
+			Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: size}},
 			VolumeName:       volumeName,
 			StorageClassName: &storageClass,
 		},
@@ -265,6 +326,10 @@ func (d *DefaultK8sClient) PersistentVolumeClaimDelete(ctx context.Context, name
 	return d.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, name, metav1.DeleteOptions{})
 }
 
+func (d *DefaultK8sClient) PersistentVolumeClaimGet(ctx context.Context, namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+	return d.ClientSet.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
 func (d *DefaultK8sClient) SecretCreateOrUpdate(ctx context.Context, secret corev1.Secret) error {
 	namespace := secret.ObjectMeta.Namespace
 	name := secret.ObjectMeta.Name
@@ -301,6 +366,23 @@ func (d *DefaultK8sClient) SecretDeleteCollection(ctx context.Context, namespace
 	return d.ClientSet.CoreV1().Secrets(namespace).DeleteCollection(ctx, metav1.DeleteOptions{}, listOptions)
 }
 
+func (d *DefaultK8sClient) SecretDeleteLabeled(ctx context.Context, namespace, selector string) ([]string, error) {
+	secrets, err := d.ClientSet.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the secrets in namespace %s: %w", namespace, err)
+	}
+
+	var deleted []string
+	for _, secret := range secrets.Items {
+		if err := d.ClientSet.CoreV1().Secrets(namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			return deleted, fmt.Errorf("unable to delete the secret %s: %w", secret.Name, err)
+		}
+		deleted = append(deleted, secret.Name)
+	}
+
+	return deleted, nil
+}
+
 func (d *DefaultK8sClient) SecretGet(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
 	secret, err := d.ClientSet.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
@@ -322,6 +404,14 @@ func (d *DefaultK8sClient) ServiceGet(ctx context.Context, namespace string, nam
 	return d.ClientSet.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+func (d *DefaultK8sClient) StorageClassList(ctx context.Context) (*storagev1.StorageClassList, error) {
+	return d.ClientSet.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+}
+
+func (d *DefaultK8sClient) EventsList(ctx context.Context, namespace string) (*eventsv1.EventList, error) {
+	return d.ClientSet.EventsV1().Events(namespace).List(ctx, metav1.ListOptions{})
+}
+
 func (d *DefaultK8sClient) EventsWatch(ctx context.Context, namespace string) (watch.Interface, error) {
 	return d.ClientSet.EventsV1().Events(namespace).Watch(ctx, metav1.ListOptions{})
 }
@@ -348,10 +438,59 @@ func (d *DefaultK8sClient) StreamPodLogs(ctx context.Context, namespace string,
 	return req.Stream(ctx)
 }
 
+func (d *DefaultK8sClient) PodDelete(ctx context.Context, namespace, name string) error {
+	return d.ClientSet.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+func (d *DefaultK8sClient) JobList(ctx context.Context, namespace string) (*batchv1.JobList, error) {
+	return d.ClientSet.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+}
+
+func (d *DefaultK8sClient) NodeList(ctx context.Context) (*corev1.NodeList, error) {
+	return d.ClientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+}
+
 func (d *DefaultK8sClient) PodList(ctx context.Context, namespace string) (*corev1.PodList, error) {
 	return d.ClientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
 }
 
+func (d *DefaultK8sClient) PodProxyGet(ctx context.Context, namespace, name, port, path string) ([]byte, error) {
+	return d.ClientSet.CoreV1().Pods(namespace).ProxyGet("http", name, port, path, nil).DoRaw(ctx)
+}
+
+func (d *DefaultK8sClient) PodPortForward(ctx context.Context, namespace, name string, ports []string, ready chan struct{}) error {
+	if d.RestConfig == nil {
+		return errors.New("unable to port-forward: no rest config")
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(d.RestConfig)
+	if err != nil {
+		return fmt.Errorf("unable to create port-forward transport: %w", err)
+	}
+	url := d.ClientSet.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(namespace).Name(name).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	// the forwarder is stopped by closing the stop channel, tie it to the ctx
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			close(stop)
+		case <-done:
+		}
+	}()
+
+	fw, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, ports, stop, ready, io.Discard, io.Discard)
+	if err != nil {
+		return fmt.Errorf("unable to create port-forward to pod '%s': %w", name, err)
+	}
+
+	return fw.ForwardPorts()
+}
+
 // ConfigMapGet retrieves a ConfigMap by name
 func (d *DefaultK8sClient) ConfigMapGet(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
 	return d.ClientSet.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
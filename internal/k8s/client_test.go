@@ -15,6 +15,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	errorsk8s "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -546,6 +547,7 @@ func TestDefaultK8sClient_NamespaceDelete(t *testing.T) {
 
 func TestDefaultK8sClient_PersistentVolumeCreate(t *testing.T) {
 	testName := "pvc"
+	testSize := resource.MustParse("5Gi")
 
 	t.Run("happy path", func(t *testing.T) {
 		cs := fake.NewSimpleClientset()
@@ -565,7 +567,7 @@ func TestDefaultK8sClient_PersistentVolumeCreate(t *testing.T) {
 			if d := cmp.Diff(testName, incoming.ObjectMeta.Name); d != "" {
 				return true, nil, fmt.Errorf("unexpected create name: %s", d)
 			}
-			if d := cmp.Diff(DefaultPersistentVolumeSize, incoming.Spec.Capacity[corev1.ResourceStorage]); d != "" {
+			if d := cmp.Diff(testSize, incoming.Spec.Capacity[corev1.ResourceStorage]); d != "" {
 				return true, nil, fmt.Errorf("unexpected capcity: %s", d)
 			}
 			if d := cmp.Diff(path.Join("/var/local-path-provisioner", testName), incoming.Spec.PersistentVolumeSource.HostPath.Path); d != "" {
@@ -588,7 +590,7 @@ func TestDefaultK8sClient_PersistentVolumeCreate(t *testing.T) {
 		})
 
 		cli := &DefaultK8sClient{ClientSet: cs}
-		err := cli.PersistentVolumeCreate(context.Background(), testNamespace, testName)
+		err := cli.PersistentVolumeCreate(context.Background(), testNamespace, testName, DefaultStorageClass, testSize)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -601,7 +603,7 @@ func TestDefaultK8sClient_PersistentVolumeCreate(t *testing.T) {
 		})
 
 		cli := &DefaultK8sClient{ClientSet: cs}
-		err := cli.PersistentVolumeCreate(context.Background(), testNamespace, testName)
+		err := cli.PersistentVolumeCreate(context.Background(), testNamespace, testName, DefaultStorageClass, testSize)
 		if d := cmp.Diff(errTest, err, cmpopts.EquateErrors()); d != "" {
 			t.Errorf("unexpected error: %s", d)
 		}
@@ -705,6 +707,7 @@ func TestDefaultK8sClient_PersistentVolumeDelete(t *testing.T) {
 func TestDefaultK8sClient_PersistentVolumeClaimCreate(t *testing.T) {
 	testName := "pvc"
 	testVolume := "volume"
+	testSize := resource.MustParse("5Gi")
 
 	t.Run("happy path", func(t *testing.T) {
 		cs := fake.NewSimpleClientset()
@@ -727,7 +730,7 @@ func TestDefaultK8sClient_PersistentVolumeClaimCreate(t *testing.T) {
 			if d := cmp.Diff(corev1.ReadWriteOnce, incoming.Spec.AccessModes[0]); d != "" {
 				return true, nil, fmt.Errorf("unexpected access mode: %s", d)
 			}
-			if d := cmp.Diff(DefaultPersistentVolumeSize, incoming.Spec.Resources.Requests[corev1.ResourceStorage]); d != "" {
+			if d := cmp.Diff(testSize, incoming.Spec.Resources.Requests[corev1.ResourceStorage]); d != "" {
 				return true, nil, fmt.Errorf("unexpected resource storage: %s", d)
 			}
 			if d := cmp.Diff(testVolume, incoming.Spec.VolumeName); d != "" {
@@ -741,7 +744,7 @@ func TestDefaultK8sClient_PersistentVolumeClaimCreate(t *testing.T) {
 		})
 
 		cli := &DefaultK8sClient{ClientSet: cs}
-		err := cli.PersistentVolumeClaimCreate(context.Background(), testNamespace, testName, testVolume)
+		err := cli.PersistentVolumeClaimCreate(context.Background(), testNamespace, testName, testVolume, DefaultStorageClass, testSize)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -754,7 +757,7 @@ func TestDefaultK8sClient_PersistentVolumeClaimCreate(t *testing.T) {
 		})
 
 		cli := &DefaultK8sClient{ClientSet: cs}
-		err := cli.PersistentVolumeClaimCreate(context.Background(), testNamespace, testName, testVolume)
+		err := cli.PersistentVolumeClaimCreate(context.Background(), testNamespace, testName, testVolume, DefaultStorageClass, testSize)
 		if d := cmp.Diff(errTest, err, cmpopts.EquateErrors()); d != "" {
 			t.Errorf("unexpected error: %s", d)
 		}
@@ -1362,3 +1365,30 @@ func TestDefaultK8sClient_SecretPatch(t *testing.T) {
 		}
 	})
 }
+
+func TestDefaultK8sClient_SecretDeleteLabeled(t *testing.T) {
+	ctx := context.Background()
+	cs := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "labeled", Labels: map[string]string{"abc": "xyz"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "other", Labels: map[string]string{"abc": "other"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "other-namespace", Name: "labeled", Labels: map[string]string{"abc": "xyz"}}},
+	)
+	cli := &DefaultK8sClient{ClientSet: cs}
+
+	deleted, err := cli.SecretDeleteLabeled(ctx, testNamespace, "abc=xyz")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]string{"labeled"}, deleted); d != "" {
+		t.Errorf("deleted mismatch (-want +got):\n%s", d)
+	}
+
+	if _, err := cs.CoreV1().Secrets(testNamespace).Get(ctx, "labeled", metav1.GetOptions{}); !errorsk8s.IsNotFound(err) {
+		t.Errorf("expected the labeled secret to be deleted, got %v", err)
+	}
+	for _, s := range []struct{ namespace, name string }{{testNamespace, "other"}, {"other-namespace", "labeled"}} {
+		if _, err := cs.CoreV1().Secrets(s.namespace).Get(ctx, s.name, metav1.GetOptions{}); err != nil {
+			t.Errorf("expected the secret %s/%s to be kept: %v", s.namespace, s.name, err)
+		}
+	}
+}
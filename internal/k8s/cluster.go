@@ -7,6 +7,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/docker"
 	"github.com/airbytehq/abctl/internal/k8s/kind"
 	"github.com/airbytehq/abctl/internal/paths"
@@ -15,6 +16,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"gopkg.in/yaml.v3"
 	"sigs.k8s.io/kind/pkg/cluster"
+	nodeslib "sigs.k8s.io/kind/pkg/cluster/nodes"
 	kindExec "sigs.k8s.io/kind/pkg/exec"
 )
 
@@ -22,12 +24,14 @@ import (
 type ExtraVolumeMount struct {
 	HostPath      string
 	ContainerPath string
+	// ReadOnly mounts the host path read-only in the node
+	ReadOnly bool
 }
 
 // Cluster is an interface representing all the actions taken at the cluster level.
 type Cluster interface {
 	// Create a cluster with the provided name.
-	Create(ctx context.Context, portHTTP int, extraMounts []ExtraVolumeMount) error
+	Create(ctx context.Context, portHTTP int, extraMounts []ExtraVolumeMount, node NodeOpts) error
 	// Delete a cluster with the provided name.
 	Delete(ctx context.Context) error
 	// Exists returns true if the cluster exists, false otherwise.
@@ -38,10 +42,21 @@ type Cluster interface {
 // interface sanity check
 var _ Cluster = (*KindCluster)(nil)
 
+// kindProvider is the subset of the kind provider used by the KindCluster, primarily exists for testing.
+type kindProvider interface {
+	Create(name string, options ...cluster.CreateOption) error
+	Delete(name, explicitKubeconfigPath string) error
+	ExportKubeConfig(name, explicitPath string, internal bool) error
+	List() ([]string, error)
+	ListNodes(name string) ([]nodeslib.Node, error)
+}
+
+var _ kindProvider = (*cluster.Provider)(nil)
+
 // KindCluster is a Cluster implementation for kind (https://kind.sigs.k8s.io/).
 type KindCluster struct {
 	// p is the kind provider, not the abctl provider
-	p *cluster.Provider
+	p kindProvider
 	// kubeconfig is the full path to the kubeconfig file kind is using
 	kubeconfig  string
 	clusterName string
@@ -52,7 +67,13 @@ type KindCluster struct {
 // that we're currently using (e.g. https://github.com/kubernetes-sigs/kind/releases/tag/v0.25.0)
 const k8sVersion = "v1.32.2@sha256:f226345927d7e348497136874b6d207e0b32cc52154ad8323129352923a3142f"
 
-func (k *KindCluster) Create(ctx context.Context, port int, extraMounts []ExtraVolumeMount) error {
+// clusterCreateTimeout bounds how long creating the cluster can take.
+var clusterCreateTimeout = 10 * time.Minute
+
+// clusterAbortGrace is how long an aborted cluster creation is given to stop, before returning regardless.
+var clusterAbortGrace = 5 * time.Second
+
+func (k *KindCluster) Create(ctx context.Context, port int, extraMounts []ExtraVolumeMount, node NodeOpts) error {
 	ctx, span := trace.NewSpan(ctx, "KindCluster.Create")
 	defer span.End()
 	// Create the data directory before the cluster does to ensure that it's owned by the correct user.
@@ -64,29 +85,82 @@ func (k *KindCluster) Create(ctx context.Context, port int, extraMounts []ExtraV
 		return fmt.Errorf("unable to create directory '%s': %w", paths.Data, err)
 	}
 
-	// see https://kind.sigs.k8s.io/docs/user/ingress/#create-cluster
-	config := kind.DefaultConfig().WithHostPort(port)
-	for _, mount := range extraMounts {
-		config = config.WithVolumeMount(mount.HostPath, mount.ContainerPath)
-	}
-
-	rawCfg, err := yaml.Marshal(config)
+	rawCfg, err := yaml.Marshal(kindConfig(port, extraMounts, node))
 	if err != nil {
 		return fmt.Errorf("unable to marshal Kind cluster config: %w", err)
 	}
 
+	// the node isn't waited on to be Ready here, as kind only warns if it isn't, see WaitForNodesReady
 	opts := []cluster.CreateOption{
-		cluster.CreateWithWaitForReady(5 * time.Minute),
 		cluster.CreateWithKubeconfigPath(k.kubeconfig),
 		cluster.CreateWithNodeImage("kindest/node:" + k8sVersion),
 		cluster.CreateWithRawConfig(rawCfg),
 	}
 
-	if err := k.p.Create(k.clusterName, opts...); err != nil {
-		return fmt.Errorf("unable to create kind cluster: %w", formatKindErr(err))
+	ctx, cancel := context.WithTimeout(ctx, clusterCreateTimeout)
+	defer cancel()
+
+	if err := ctx.Err(); err != nil {
+		return clusterCreateAborted(err)
 	}
 
-	return nil
+	// kind doesn't support a context, run the create in the background so the ctx can abort it
+	created := make(chan error, 1)
+	go func() {
+		created <- k.p.Create(k.clusterName, opts...)
+	}()
+
+	select {
+	case err := <-created:
+		if err != nil {
+			return fmt.Errorf("unable to create kind cluster: %w", formatKindErr(err))
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	// Deleting the cluster removes any node containers created so far, which also fails the in-progress create.
+	// As the create may still add a node container after the first delete, delete again once it stops.
+	pterm.Debug.Printfln("Cluster creation aborted, removing the partially created cluster '%s'", k.clusterName)
+	k.deletePartial()
+	select {
+	case <-created:
+		k.deletePartial()
+	case <-time.After(clusterAbortGrace):
+		pterm.Debug.Printfln("Cluster creation did not stop within %s", clusterAbortGrace)
+	}
+
+	return clusterCreateAborted(ctx.Err())
+}
+
+// kindConfig returns the kind config of the cluster, exposing the ingress on the port.
+// see https://kind.sigs.k8s.io/docs/user/ingress/#create-cluster
+func kindConfig(port int, extraMounts []ExtraVolumeMount, node NodeOpts) *kind.Config {
+	config := kind.DefaultConfig().WithHostPort(port)
+	for _, mount := range extraMounts {
+		if mount.ReadOnly {
+			config = config.WithReadOnlyVolumeMount(mount.HostPath, mount.ContainerPath)
+		} else {
+			config = config.WithVolumeMount(mount.HostPath, mount.ContainerPath)
+		}
+	}
+	return config.WithNodeLabels(node.Labels).WithNodeTaints(kindTaints(node.Taints))
+}
+
+// deletePartial deletes a partially created cluster, errors are only logged as this is best effort.
+func (k *KindCluster) deletePartial() {
+	if err := k.p.Delete(k.clusterName, k.kubeconfig); err != nil {
+		pterm.Debug.Printfln("Unable to remove partially created cluster '%s': %s", k.clusterName, err)
+	}
+}
+
+// clusterCreateAborted returns the error of a cluster creation aborted by its context.
+// Exceeding the deadline returns an ErrClusterCreateTimeout error.
+func clusterCreateAborted(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", abctl.ErrClusterCreateTimeout, err)
+	}
+	return fmt.Errorf("cluster creation cancelled: %w", err)
 }
 
 func (k *KindCluster) Delete(ctx context.Context) error {
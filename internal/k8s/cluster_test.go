@@ -1,10 +1,20 @@
 package k8s
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/k8s/kind"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kind/pkg/cluster"
+	nodeslib "sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/exec"
 )
 
@@ -17,3 +27,133 @@ func TestFormatKindError(t *testing.T) {
 		t.Errorf("expected %q but got %q", expect, str)
 	}
 }
+
+// fakeKindProvider is a kind provider whose create adds a node container and then blocks until it is deleted,
+// simulating a create which is still in progress.
+type fakeKindProvider struct {
+	mu         sync.Mutex
+	containers map[string]bool
+	created    chan struct{}
+	deleted    chan struct{}
+	creates    int
+}
+
+func newFakeKindProvider() *fakeKindProvider {
+	return &fakeKindProvider{
+		containers: map[string]bool{},
+		created:    make(chan struct{}),
+		deleted:    make(chan struct{}),
+	}
+}
+
+func (f *fakeKindProvider) Create(name string, _ ...cluster.CreateOption) error {
+	f.mu.Lock()
+	f.creates++
+	f.containers[name+"-control-plane"] = true
+	f.mu.Unlock()
+	close(f.created)
+
+	// the create fails once its node container is removed
+	<-f.deleted
+	return errors.New("node container removed")
+}
+
+func (f *fakeKindProvider) Delete(name, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.containers[name+"-control-plane"] {
+		delete(f.containers, name+"-control-plane")
+		close(f.deleted)
+	}
+	return nil
+}
+
+func (f *fakeKindProvider) ExportKubeConfig(string, string, bool) error {
+	return nil
+}
+
+func (f *fakeKindProvider) List() ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeKindProvider) ListNodes(string) ([]nodeslib.Node, error) {
+	return nil, nil
+}
+
+// setTestDataDir points paths.Data at a temp directory for the duration of the test.
+func setTestDataDir(t *testing.T) {
+	orig := paths.Data
+	paths.Data = t.TempDir()
+	t.Cleanup(func() {
+		paths.Data = orig
+	})
+}
+
+func TestKindCluster_Create_Deadline(t *testing.T) {
+	setTestDataDir(t)
+
+	p := newFakeKindProvider()
+	c := &KindCluster{p: p, kubeconfig: filepath.Join(t.TempDir(), "kubeconfig"), clusterName: "test"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Create(ctx, 8000, nil, NodeOpts{})
+	if !errors.Is(err, abctl.ErrClusterCreateTimeout) {
+		t.Errorf("expected ErrClusterCreateTimeout but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected a prompt return but took %s", elapsed)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.creates != 1 {
+		t.Errorf("expected 1 create but got %d", p.creates)
+	}
+	if len(p.containers) != 0 {
+		t.Errorf("expected no lingering containers but got %v", p.containers)
+	}
+}
+
+func TestKindCluster_Create_AlreadyDone(t *testing.T) {
+	setTestDataDir(t)
+
+	p := newFakeKindProvider()
+	c := &KindCluster{p: p, kubeconfig: filepath.Join(t.TempDir(), "kubeconfig"), clusterName: "test"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	if err := c.Create(ctx, 8000, nil, NodeOpts{}); !errors.Is(err, abctl.ErrClusterCreateTimeout) {
+		t.Errorf("expected ErrClusterCreateTimeout but got %v", err)
+	}
+	if p.creates != 0 {
+		t.Errorf("expected no create but got %d", p.creates)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := c.Create(cancelled, 8000, nil, NodeOpts{})
+	if !errors.Is(err, context.Canceled) || errors.Is(err, abctl.ErrClusterCreateTimeout) {
+		t.Errorf("expected a cancellation error but got %v", err)
+	}
+}
+
+func TestKindConfig_ExtraMounts(t *testing.T) {
+	cfg := kindConfig(8000, []ExtraVolumeMount{
+		{HostPath: "/home/user/connectors", ContainerPath: "/connectors"},
+		{HostPath: "/home/user/data", ContainerPath: "/data", ReadOnly: true},
+	}, NodeOpts{})
+
+	// the data directory is always mounted first
+	want := []kind.Mount{
+		{HostPath: paths.Data, ContainerPath: "/var/local-path-provisioner"},
+		{HostPath: "/home/user/connectors", ContainerPath: "/connectors"},
+		{HostPath: "/home/user/data", ContainerPath: "/data", ReadOnly: true},
+	}
+	if d := cmp.Diff(want, cfg.Nodes[0].ExtraMounts); d != "" {
+		t.Errorf("mounts mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+
+	"github.com/airbytehq/abctl/internal/docker"
+)
+
+// interface sanity check
+var _ Cluster = (*FakeCluster)(nil)
+
+// FakeCluster is a Cluster held in memory, so that the install and uninstall flows can be exercised without docker
+// or kind, e.g. by the integration tests of an embedder. It is safe for concurrent use.
+type FakeCluster struct {
+	// CreateErr is returned by Create, which then leaves the cluster as is.
+	CreateErr error
+	// DeleteErr is returned by Delete, which then leaves the cluster as is.
+	DeleteErr error
+
+	mu       sync.Mutex
+	exists   bool
+	created  int
+	deleted  int
+	portHTTP int
+	images   []string
+}
+
+// NewFakeCluster returns a FakeCluster, which already exists if exists is true.
+func NewFakeCluster(exists bool) *FakeCluster {
+	return &FakeCluster{exists: exists}
+}
+
+func (c *FakeCluster) Create(_ context.Context, portHTTP int, _ []ExtraVolumeMount, _ NodeOpts) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.CreateErr != nil {
+		return c.CreateErr
+	}
+	c.exists = true
+	c.created++
+	c.portHTTP = portHTTP
+	return nil
+}
+
+func (c *FakeCluster) Delete(_ context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.DeleteErr != nil {
+		return c.DeleteErr
+	}
+	c.exists = false
+	c.deleted++
+	return nil
+}
+
+func (c *FakeCluster) Exists(_ context.Context) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.exists
+}
+
+// LoadImages records the images, nothing is loaded.
+func (c *FakeCluster) LoadImages(_ context.Context, _ docker.Client, images []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.images = append(c.images, images...)
+}
+
+// Created returns how many times the cluster was created.
+func (c *FakeCluster) Created() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.created
+}
+
+// Deleted returns how many times the cluster was deleted.
+func (c *FakeCluster) Deleted() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deleted
+}
+
+// PortHTTP returns the port the cluster was last created with.
+func (c *FakeCluster) PortHTTP() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.portHTTP
+}
+
+// Images returns the images loaded into the cluster, in the order they were loaded.
+func (c *FakeCluster) Images() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.images...)
+}
+
+// FakeProvider returns a provider whose Cluster is the cluster, rather than a kind cluster, and whose Kubeconfig is
+// the kubeconfig path. Nothing is written to the kubeconfig, and the provider doesn't require docker.
+// The cluster is usually a FakeCluster, though any Cluster can be injected.
+func FakeProvider(cluster Cluster, kubeconfig string) Provider {
+	return Provider{
+		Name:        Fake,
+		ClusterName: "fake-airbyte-abctl",
+		Context:     "fake-airbyte-abctl",
+		Kubeconfig:  kubeconfig,
+		cluster:     cluster,
+	}
+}
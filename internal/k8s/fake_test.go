@@ -0,0 +1,94 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFakeProvider(t *testing.T) {
+	ctx := context.Background()
+	kubeconfig := filepath.Join(t.TempDir(), "kube", "abctl.kubeconfig")
+	cluster := NewFakeCluster(false)
+	provider := FakeProvider(cluster, kubeconfig)
+
+	if provider.RequiresDocker() {
+		t.Error("expected the fake provider to not require docker")
+	}
+	if d := cmp.Diff(kubeconfig, provider.Kubeconfig); d != "" {
+		t.Errorf("kubeconfig mismatch (-want +got):\n%s", d)
+	}
+
+	if _, err := provider.GetCluster(ctx); !errors.Is(err, abctl.ErrClusterNotFound) {
+		t.Errorf("expected ErrClusterNotFound but got %v", err)
+	}
+
+	c, created, err := provider.EnsureCluster(ctx, 8000, nil, NodeOpts{})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if !created {
+		t.Error("expected the cluster to be created")
+	}
+	if c != Cluster(cluster) {
+		t.Error("expected the injected cluster")
+	}
+	if d := cmp.Diff(8000, cluster.PortHTTP()); d != "" {
+		t.Errorf("port mismatch (-want +got):\n%s", d)
+	}
+
+	// an existing cluster is reused
+	if _, created, err := provider.EnsureCluster(ctx, 9000, nil, NodeOpts{}); err != nil || created {
+		t.Errorf("expected the cluster to be reused, created=%t err=%v", created, err)
+	}
+	if d := cmp.Diff(1, cluster.Created()); d != "" {
+		t.Errorf("created mismatch (-want +got):\n%s", d)
+	}
+
+	c.LoadImages(ctx, nil, []string{"airbyte/server:1.0.0"})
+	if d := cmp.Diff([]string{"airbyte/server:1.0.0"}, cluster.Images()); d != "" {
+		t.Errorf("images mismatch (-want +got):\n%s", d)
+	}
+
+	if err := c.Delete(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if c.Exists(ctx) {
+		t.Error("expected the cluster to be deleted")
+	}
+	if d := cmp.Diff(1, cluster.Deleted()); d != "" {
+		t.Errorf("deleted mismatch (-want +got):\n%s", d)
+	}
+
+	// nothing is written for the fake cluster
+	if dirExists(filepath.Dir(kubeconfig)) {
+		t.Error("expected no kubeconfig directory")
+	}
+}
+
+func TestFakeCluster_Errors(t *testing.T) {
+	ctx := context.Background()
+	errTest := errors.New("test error")
+
+	cluster := NewFakeCluster(true)
+	cluster.DeleteErr = errTest
+	if err := cluster.Delete(ctx); !errors.Is(err, errTest) {
+		t.Errorf("expected the delete error but got %v", err)
+	}
+	if !cluster.Exists(ctx) {
+		t.Error("expected a failed delete to keep the cluster")
+	}
+
+	cluster = NewFakeCluster(false)
+	cluster.CreateErr = errTest
+	if _, _, err := FakeProvider(cluster, "").EnsureCluster(ctx, 8000, nil, NodeOpts{}); !errors.Is(err, errTest) {
+		t.Errorf("expected the create error but got %v", err)
+	}
+	if cluster.Exists(ctx) {
+		t.Error("expected a failed create to not create the cluster")
+	}
+}
@@ -10,9 +10,31 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// DefaultIngressClass is the ingress class of the nginx ingress controller installed by abctl.
+const DefaultIngressClass = "nginx"
+
+// IngressOpts configure the ingress of airbyte. The zero value is served by the nginx ingress controller installed
+// by abctl, over plain HTTP.
+type IngressOpts struct {
+	// Class is the ingress class of an existing ingress controller serving the ingress, DefaultIngressClass if empty.
+	Class string
+	// TLSSecret is the name of the secret, in the airbyte namespace, holding the TLS certificate of the hosts.
+	// TLS is not configured if empty.
+	TLSSecret string
+}
+
 // Ingress creates an ingress type for defining the webapp ingress rules.
-func Ingress(chartVersion string, hosts []string) *networkingv1.Ingress {
-	var ingressClassName = "nginx"
+func Ingress(chartVersion string, hosts []string, opts IngressOpts) *networkingv1.Ingress {
+	var ingressClassName = DefaultIngressClass
+	if opts.Class != "" {
+		ingressClassName = opts.Class
+	}
+
+	// only the provided hosts are covered by the certificate, not the localhost rules added below
+	var tls []networkingv1.IngressTLS
+	if opts.TLSSecret != "" {
+		tls = []networkingv1.IngressTLS{{Hosts: slices.Clone(hosts), SecretName: opts.TLSSecret}}
+	}
 
 	// if no host is defined, default to an empty host
 	if len(hosts) == 0 {
@@ -44,6 +66,7 @@ func Ingress(chartVersion string, hosts []string) *networkingv1.Ingress {
 		Spec: networkingv1.IngressSpec{
 			IngressClassName: &ingressClassName,
 			Rules:            rules,
+			TLS:              tls,
 		},
 	}
 }
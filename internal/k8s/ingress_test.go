@@ -91,7 +91,7 @@ func TestIngress(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			actHosts := extractHosts(Ingress(tt.chartVersion, tt.hosts))
+			actHosts := extractHosts(Ingress(tt.chartVersion, tt.hosts, IngressOpts{}))
 			sort.Strings(actHosts)
 			sort.Strings(tt.expHosts)
 			if d := cmp.Diff(tt.expHosts, actHosts); d != "" {
@@ -135,7 +135,7 @@ func TestIngressRouting(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ingress := Ingress(tt.chartVersion, []string{"localhost"})
+			ingress := Ingress(tt.chartVersion, []string{"localhost"}, IngressOpts{})
 
 			// Get the default route (path: "/")
 			var defaultRoute *networkingv1.HTTPIngressPath
@@ -162,3 +162,50 @@ func TestIngressRouting(t *testing.T) {
 		})
 	}
 }
+
+func TestIngressOpts(t *testing.T) {
+	tests := []struct {
+		name      string
+		hosts     []string
+		opts      IngressOpts
+		wantClass string
+		wantTLS   []networkingv1.IngressTLS
+	}{
+		{
+			name:      "defaults",
+			hosts:     []string{"airbyte.example.com"},
+			wantClass: "nginx",
+		},
+		{
+			name:      "ingress class",
+			hosts:     []string{"airbyte.example.com"},
+			opts:      IngressOpts{Class: "traefik"},
+			wantClass: "traefik",
+		},
+		{
+			name:      "tls secret",
+			hosts:     []string{"airbyte.example.com"},
+			opts:      IngressOpts{Class: "traefik", TLSSecret: "airbyte-tls"},
+			wantClass: "traefik",
+			wantTLS:   []networkingv1.IngressTLS{{Hosts: []string{"airbyte.example.com"}, SecretName: "airbyte-tls"}},
+		},
+		{
+			name:      "tls secret without hosts",
+			opts:      IngressOpts{Class: "traefik", TLSSecret: "airbyte-tls"},
+			wantClass: "traefik",
+			wantTLS:   []networkingv1.IngressTLS{{SecretName: "airbyte-tls"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingress := Ingress("2.0.0", tt.hosts, tt.opts)
+			if d := cmp.Diff(tt.wantClass, *ingress.Spec.IngressClassName); d != "" {
+				t.Errorf("ingress class mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantTLS, ingress.Spec.TLS); d != "" {
+				t.Errorf("tls mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
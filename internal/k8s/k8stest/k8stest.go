@@ -7,8 +7,13 @@ import (
 
 	"github.com/airbytehq/abctl/internal/k8s"
 	v1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 )
@@ -16,6 +21,7 @@ import (
 var _ k8s.Client = (*MockClient)(nil)
 
 type MockClient struct {
+	FnDeploymentAddTolerations    func(ctx context.Context, namespace, name string, tolerations []corev1.Toleration) error
 	FnDeploymentList              func(ctx context.Context, namespace string) (*v1.DeploymentList, error)
 	FnDeploymentRestart           func(ctx context.Context, namespace, name string) error
 	FnIngressCreate               func(ctx context.Context, namespace string, ingress *networkingv1.Ingress) error
@@ -24,34 +30,50 @@ type MockClient struct {
 	FnNamespaceCreate             func(ctx context.Context, namespace string) error
 	FnNamespaceExists             func(ctx context.Context, namespace string) bool
 	FnNamespaceDelete             func(ctx context.Context, namespace string) error
-	FnPersistentVolumeCreate      func(ctx context.Context, namespace, name string) error
+	FnNodeList                    func(ctx context.Context) (*corev1.NodeList, error)
+	FnPersistentVolumeCreate      func(ctx context.Context, namespace, name, storageClass string, size resource.Quantity) error
 	FnPersistentVolumeExists      func(ctx context.Context, namespace, name string) bool
 	FnPersistentVolumeDelete      func(ctx context.Context, namespace, name string) error
-	FnPersistentVolumeClaimCreate func(ctx context.Context, namespace, name, volumeName string) error
+	FnPersistentVolumeClaimCreate func(ctx context.Context, namespace, name, volumeName, storageClass string, size resource.Quantity) error
 	FnPersistentVolumeClaimExists func(ctx context.Context, namespace, name, volumeName string) bool
 	FnPersistentVolumeClaimDelete func(ctx context.Context, namespace, name, volumeName string) error
+	FnPersistentVolumeClaimGet    func(ctx context.Context, namespace, name string) (*corev1.PersistentVolumeClaim, error)
 	FnSecretCreateOrUpdate        func(ctx context.Context, secret corev1.Secret) error
 	FnSecretPatch                 func(ctx context.Context, namespace, name string, patchData []byte, patchType types.PatchType) error
 	FnSecretDeleteCollection      func(ctx context.Context, namespace, _type string) error
+	FnSecretDeleteLabeled         func(ctx context.Context, namespace, selector string) ([]string, error)
 	FnSecretGet                   func(ctx context.Context, namespace, name string) (*corev1.Secret, error)
 	FnServerVersionGet            func() (string, error)
 	FnServiceGet                  func(ctx context.Context, namespace, name string) (*corev1.Service, error)
+	FnStorageClassList            func(ctx context.Context) (*storagev1.StorageClassList, error)
+	FnEventsList                  func(ctx context.Context, namespace string) (*eventsv1.EventList, error)
 	FnEventsWatch                 func(ctx context.Context, namespace string) (watch.Interface, error)
+	FnJobList                     func(ctx context.Context, namespace string) (*batchv1.JobList, error)
 	FnLogsGet                     func(ctx context.Context, namespace string, name string) (string, error)
 	FnStreamPodLogs               func(ctx context.Context, namespace, podName string, since time.Time) (io.ReadCloser, error)
+	FnPodDelete                   func(ctx context.Context, namespace, name string) error
 	FnPodList                     func(ctx context.Context, namespace string) (*corev1.PodList, error)
+	FnPodProxyGet                 func(ctx context.Context, namespace, name, port, path string) ([]byte, error)
+	FnPodPortForward              func(ctx context.Context, namespace, name string, ports []string, ready chan struct{}) error
 	FnConfigMapGet                func(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error)
 	FnConfigMapList               func(ctx context.Context, namespace string) (*corev1.ConfigMapList, error)
 	FnConfigMapCreate             func(ctx context.Context, configMap *corev1.ConfigMap) error
 	FnConfigMapUpdate             func(ctx context.Context, configMap *corev1.ConfigMap) error
 }
 
+func (m *MockClient) DeploymentAddTolerations(ctx context.Context, namespace, name string, tolerations []corev1.Toleration) error {
+	if m.FnDeploymentAddTolerations != nil {
+		return m.FnDeploymentAddTolerations(ctx, namespace, name, tolerations)
+	}
+	return nil
+}
+
 func (m *MockClient) DeploymentList(ctx context.Context, namespace string) (*v1.DeploymentList, error) {
 	return m.FnDeploymentList(ctx, namespace)
 }
 
 func (m *MockClient) DeploymentRestart(ctx context.Context, namespace, name string) error {
-	if m.FnDeploymentRestart == nil {
+	if m.FnDeploymentRestart != nil {
 		return m.FnDeploymentRestart(ctx, namespace, name)
 	}
 	return nil
@@ -99,9 +121,9 @@ func (m *MockClient) NamespaceDelete(ctx context.Context, namespace string) erro
 	return nil
 }
 
-func (m *MockClient) PersistentVolumeCreate(ctx context.Context, namespace, name string) error {
+func (m *MockClient) PersistentVolumeCreate(ctx context.Context, namespace, name, storageClass string, size resource.Quantity) error {
 	if m.FnPersistentVolumeCreate != nil {
-		return m.FnPersistentVolumeCreate(ctx, namespace, name)
+		return m.FnPersistentVolumeCreate(ctx, namespace, name, storageClass, size)
 	}
 	return nil
 }
@@ -120,9 +142,9 @@ func (m *MockClient) PersistentVolumeDelete(ctx context.Context, namespace, name
 	return nil
 }
 
-func (m *MockClient) PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName string) error {
+func (m *MockClient) PersistentVolumeClaimCreate(ctx context.Context, namespace, name, volumeName, storageClass string, size resource.Quantity) error {
 	if m.FnPersistentVolumeClaimCreate != nil {
-		return m.FnPersistentVolumeClaimCreate(ctx, namespace, name, volumeName)
+		return m.FnPersistentVolumeClaimCreate(ctx, namespace, name, volumeName, storageClass, size)
 	}
 	return nil
 }
@@ -141,6 +163,13 @@ func (m *MockClient) PersistentVolumeClaimDelete(ctx context.Context, namespace,
 	return nil
 }
 
+func (m *MockClient) PersistentVolumeClaimGet(ctx context.Context, namespace, name string) (*corev1.PersistentVolumeClaim, error) {
+	if m.FnPersistentVolumeClaimGet != nil {
+		return m.FnPersistentVolumeClaimGet(ctx, namespace, name)
+	}
+	return nil, nil
+}
+
 func (m *MockClient) SecretCreateOrUpdate(ctx context.Context, secret corev1.Secret) error {
 	if m.FnSecretCreateOrUpdate != nil {
 		return m.FnSecretCreateOrUpdate(ctx, secret)
@@ -173,6 +202,14 @@ func (m *MockClient) SecretDeleteCollection(ctx context.Context, namespace, _typ
 	return nil
 }
 
+func (m *MockClient) SecretDeleteLabeled(ctx context.Context, namespace, selector string) ([]string, error) {
+	if m.FnSecretDeleteLabeled != nil {
+		return m.FnSecretDeleteLabeled(ctx, namespace, selector)
+	}
+
+	return nil, nil
+}
+
 func (m *MockClient) ServiceGet(ctx context.Context, namespace, name string) (*corev1.Service, error) {
 	return m.FnServiceGet(ctx, namespace, name)
 }
@@ -184,6 +221,20 @@ func (m *MockClient) ServerVersionGet() (string, error) {
 	return "test", nil
 }
 
+func (m *MockClient) StorageClassList(ctx context.Context) (*storagev1.StorageClassList, error) {
+	if m.FnStorageClassList == nil {
+		return &storagev1.StorageClassList{}, nil
+	}
+	return m.FnStorageClassList(ctx)
+}
+
+func (m *MockClient) EventsList(ctx context.Context, namespace string) (*eventsv1.EventList, error) {
+	if m.FnEventsList == nil {
+		return &eventsv1.EventList{}, nil
+	}
+	return m.FnEventsList(ctx, namespace)
+}
+
 func (m *MockClient) EventsWatch(ctx context.Context, namespace string) (watch.Interface, error) {
 	if m.FnEventsWatch == nil {
 		return watch.NewFake(), nil
@@ -191,6 +242,13 @@ func (m *MockClient) EventsWatch(ctx context.Context, namespace string) (watch.I
 	return m.FnEventsWatch(ctx, namespace)
 }
 
+func (m *MockClient) JobList(ctx context.Context, namespace string) (*batchv1.JobList, error) {
+	if m.FnJobList == nil {
+		return &batchv1.JobList{}, nil
+	}
+	return m.FnJobList(ctx, namespace)
+}
+
 func (m *MockClient) LogsGet(ctx context.Context, namespace string, name string) (string, error) {
 	if m.FnLogsGet == nil {
 		return "LogsGet called", nil
@@ -205,6 +263,27 @@ func (m *MockClient) StreamPodLogs(ctx context.Context, namespace string, podNam
 	return m.FnStreamPodLogs(ctx, namespace, podName, since)
 }
 
+func (m *MockClient) PodDelete(ctx context.Context, namespace, name string) error {
+	if m.FnPodDelete != nil {
+		return m.FnPodDelete(ctx, namespace, name)
+	}
+
+	return nil
+}
+
+// NodeList returns a single Ready node, unless FnNodeList is configured.
+func (m *MockClient) NodeList(ctx context.Context) (*corev1.NodeList, error) {
+	if m.FnNodeList == nil {
+		return &corev1.NodeList{Items: []corev1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			}},
+		}}}, nil
+	}
+	return m.FnNodeList(ctx)
+}
+
 func (m *MockClient) PodList(ctx context.Context, namespace string) (*corev1.PodList, error) {
 	if m.FnPodList == nil {
 		return &corev1.PodList{}, nil
@@ -212,6 +291,23 @@ func (m *MockClient) PodList(ctx context.Context, namespace string) (*corev1.Pod
 	return m.FnPodList(ctx, namespace)
 }
 
+func (m *MockClient) PodProxyGet(ctx context.Context, namespace, name, port, path string) ([]byte, error) {
+	if m.FnPodProxyGet == nil {
+		return nil, nil
+	}
+	return m.FnPodProxyGet(ctx, namespace, name, port, path)
+}
+
+func (m *MockClient) PodPortForward(ctx context.Context, namespace, name string, ports []string, ready chan struct{}) error {
+	if m.FnPodPortForward == nil {
+		// behave as an established forward
+		close(ready)
+		<-ctx.Done()
+		return nil
+	}
+	return m.FnPodPortForward(ctx, namespace, name, ports, ready)
+}
+
 func (m *MockClient) ConfigMapGet(ctx context.Context, namespace, name string) (*corev1.ConfigMap, error) {
 	if m.FnConfigMapGet == nil {
 		return &corev1.ConfigMap{}, nil
@@ -1,6 +1,9 @@
 package kind
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/airbytehq/abctl/internal/paths"
 )
 
@@ -43,12 +46,22 @@ type PortMapping struct {
 	Protocol      string `yaml:"protocol"`
 }
 
-func DefaultConfig() *Config {
-	kubeadmConfigPatch := `kind: InitConfiguration
+// Taint is a taint applied to the kind node when it registers.
+type Taint struct {
+	Key    string
+	Value  string
+	Effect string
+}
+
+// initConfigurationPatch is the kubeadm InitConfiguration patch of the control-plane node.
+const initConfigurationPatch = `kind: InitConfiguration
 nodeRegistration:
   kubeletExtraArgs:
     node-labels: "ingress-ready=true"`
 
+func DefaultConfig() *Config {
+	kubeadmConfigPatch := initConfigurationPatch
+
 	cfg := &Config{
 		Kind:       "Cluster",
 		ApiVersion: "kind.x-k8s.io/v1alpha4",
@@ -80,7 +93,45 @@ func (c *Config) WithVolumeMount(hostPath string, containerPath string) *Config
 	return c
 }
 
+// WithReadOnlyVolumeMount mounts the host path read-only in the node.
+func (c *Config) WithReadOnlyVolumeMount(hostPath string, containerPath string) *Config {
+	c.Nodes[0].ExtraMounts = append(c.Nodes[0].ExtraMounts, Mount{HostPath: hostPath, ContainerPath: containerPath, ReadOnly: true})
+	return c
+}
+
 func (c *Config) WithHostPort(port int) *Config {
 	c.Nodes[0].ExtraPortMappings[0].HostPort = int32(port)
 	return c
 }
+
+// WithNodeLabels adds the labels to the node, in addition to the ingress-ready label.
+func (c *Config) WithNodeLabels(labels map[string]string) *Config {
+	if len(labels) == 0 {
+		return c
+	}
+	if c.Nodes[0].Labels == nil {
+		c.Nodes[0].Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		c.Nodes[0].Labels[k] = v
+	}
+	return c
+}
+
+// WithNodeTaints registers the node with the taints.
+// Kind has no taints field, so they're set through the kubeadm InitConfiguration patch,
+// which also replaces the control-plane taint kind would otherwise remove.
+func (c *Config) WithNodeTaints(taints []Taint) *Config {
+	if len(taints) == 0 {
+		return c
+	}
+
+	var b strings.Builder
+	b.WriteString(initConfigurationPatch)
+	b.WriteString("\n  taints:")
+	for _, t := range taints {
+		b.WriteString(fmt.Sprintf("\n  - key: %q\n    value: %q\n    effect: %q", t.Key, t.Value, t.Effect))
+	}
+	c.Nodes[0].KubeadmConfigPatches[0] = b.String()
+	return c
+}
@@ -0,0 +1,71 @@
+package kind
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_WithNodeLabels(t *testing.T) {
+	cfg := DefaultConfig().WithNodeLabels(map[string]string{"team": "data", "env": "dev"})
+
+	if d := cmp.Diff(map[string]string{"team": "data", "env": "dev"}, cfg.Nodes[0].Labels); d != "" {
+		t.Errorf("labels mismatch (-want +got):\n%s", d)
+	}
+
+	// no labels leaves the node unchanged
+	if d := cmp.Diff(DefaultConfig(), DefaultConfig().WithNodeLabels(nil)); d != "" {
+		t.Errorf("config mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestConfig_WithNodeTaints(t *testing.T) {
+	cfg := DefaultConfig().WithNodeTaints([]Taint{
+		{Key: "dedicated", Value: "airbyte", Effect: "NoSchedule"},
+		{Key: "spot", Effect: "PreferNoSchedule"},
+	})
+
+	var patch map[string]any
+	if err := yaml.Unmarshal([]byte(cfg.Nodes[0].KubeadmConfigPatches[0]), &patch); err != nil {
+		t.Fatal("unable to unmarshal the kubeadm patch", err)
+	}
+
+	want := map[string]any{
+		"kind": "InitConfiguration",
+		"nodeRegistration": map[string]any{
+			"kubeletExtraArgs": map[string]any{"node-labels": "ingress-ready=true"},
+			"taints": []any{
+				map[string]any{"key": "dedicated", "value": "airbyte", "effect": "NoSchedule"},
+				map[string]any{"key": "spot", "value": "", "effect": "PreferNoSchedule"},
+			},
+		},
+	}
+	if d := cmp.Diff(want, patch); d != "" {
+		t.Errorf("patch mismatch (-want +got):\n%s", d)
+	}
+
+	// no taints leaves the patch unchanged
+	if d := cmp.Diff(DefaultConfig(), DefaultConfig().WithNodeTaints(nil)); d != "" {
+		t.Errorf("config mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestConfig_Marshal(t *testing.T) {
+	cfg := DefaultConfig().
+		WithNodeLabels(map[string]string{"team": "data"}).
+		WithNodeTaints([]Taint{{Key: "dedicated", Value: "airbyte", Effect: "NoExecute"}})
+
+	raw, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal("unable to marshal the config", err)
+	}
+
+	var got Config
+	if err := yaml.Unmarshal(raw, &got); err != nil {
+		t.Fatal("unable to unmarshal the config", err)
+	}
+	if d := cmp.Diff(*cfg, got); d != "" {
+		t.Errorf("config mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,189 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// KubeconfigPaths returns the kubeconfig files the user's configuration is loaded from, in order of precedence.
+// Matching kubectl, the KUBECONFIG environment variable may list multiple files separated by the OS specific
+// path list separator (':' on linux and macOS, ';' on windows). If KUBECONFIG is not set, ~/.kube/config is returned.
+func KubeconfigPaths() []string {
+	env := os.Getenv(clientcmd.RecommendedConfigPathEnvVar)
+	if env == "" {
+		return []string{clientcmd.RecommendedHomeFile}
+	}
+
+	var kubeconfigs []string
+	seen := map[string]struct{}{}
+	for _, p := range filepath.SplitList(env) {
+		if p == "" {
+			continue
+		}
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		kubeconfigs = append(kubeconfigs, p)
+	}
+
+	if len(kubeconfigs) == 0 {
+		return []string{clientcmd.RecommendedHomeFile}
+	}
+
+	return kubeconfigs
+}
+
+// KubeconfigWriteTarget returns the kubeconfig file any changes should be written to.
+// The first existing file which is writable is preferred. If none of the existing files are writable,
+// the first file which does not exist yet but can be created is returned.
+func KubeconfigWriteTarget(kubeconfigs []string) (string, error) {
+	for _, p := range kubeconfigs {
+		if _, err := os.Stat(p); err == nil && fileWritable(p) {
+			return p, nil
+		}
+	}
+
+	for _, p := range kubeconfigs {
+		if _, err := os.Stat(p); errors.Is(err, os.ErrNotExist) && fileCreatable(p) {
+			return p, nil
+		}
+	}
+
+	return "", fmt.Errorf("no writable kubeconfig file found in %v", kubeconfigs)
+}
+
+// LoadMergedKubeconfig loads the merged view of all the kubeconfigs, following the kubectl merge rules
+// where the first file to set a value wins. Files which do not exist are ignored.
+func LoadMergedKubeconfig(kubeconfigs []string) (*clientcmdapi.Config, error) {
+	rules := &clientcmd.ClientConfigLoadingRules{Precedence: kubeconfigs}
+	cfg, err := rules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kubeconfig from %v: %w", kubeconfigs, err)
+	}
+
+	return cfg, nil
+}
+
+// MergeKubeconfig merges the clusters, users and contexts from the src kubeconfig into the first writable
+// kubeconfig of the kubeconfigs, returning the path of the file that was written to.
+// Existing entries with the same name are replaced. If setCurrent is true, the current-context of the
+// written file is set to the current-context of the src.
+func MergeKubeconfig(src string, kubeconfigs []string, setCurrent bool) (string, error) {
+	srcCfg, err := clientcmd.LoadFromFile(src)
+	if err != nil {
+		return "", fmt.Errorf("unable to load kubeconfig %s: %w", src, err)
+	}
+
+	target, err := KubeconfigWriteTarget(kubeconfigs)
+	if err != nil {
+		return "", err
+	}
+
+	targetCfg := clientcmdapi.NewConfig()
+	if _, err := os.Stat(target); err == nil {
+		if targetCfg, err = clientcmd.LoadFromFile(target); err != nil {
+			return "", fmt.Errorf("unable to load kubeconfig %s: %w", target, err)
+		}
+	}
+
+	for name, c := range srcCfg.Clusters {
+		targetCfg.Clusters[name] = c
+	}
+	for name, a := range srcCfg.AuthInfos {
+		targetCfg.AuthInfos[name] = a
+	}
+	for name, c := range srcCfg.Contexts {
+		targetCfg.Contexts[name] = c
+	}
+	if setCurrent || targetCfg.CurrentContext == "" {
+		targetCfg.CurrentContext = srcCfg.CurrentContext
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("unable to create directory for kubeconfig %s: %w", target, err)
+	}
+	if err := clientcmd.WriteToFile(*targetCfg, target); err != nil {
+		return "", fmt.Errorf("unable to write kubeconfig %s: %w", target, err)
+	}
+
+	return target, nil
+}
+
+// UseContext sets the current-context to the context name, matching `kubectl config use-context`.
+// The current-context is written to the first writable kubeconfig of the kubeconfigs, returning the path of the
+// file that was written to. Returns an error if the merged view of the kubeconfigs has no context with the name.
+func UseContext(kubeconfigs []string, name string) (string, error) {
+	merged, err := LoadMergedKubeconfig(kubeconfigs)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := merged.Contexts[name]; !ok {
+		return "", fmt.Errorf("no context exists with the name %s", name)
+	}
+
+	target, err := KubeconfigWriteTarget(kubeconfigs)
+	if err != nil {
+		return "", err
+	}
+
+	targetCfg := clientcmdapi.NewConfig()
+	if _, err := os.Stat(target); err == nil {
+		if targetCfg, err = clientcmd.LoadFromFile(target); err != nil {
+			return "", fmt.Errorf("unable to load kubeconfig %s: %w", target, err)
+		}
+	}
+	targetCfg.CurrentContext = name
+
+	if err := clientcmd.WriteToFile(*targetCfg, target); err != nil {
+		return "", fmt.Errorf("unable to write kubeconfig %s: %w", target, err)
+	}
+
+	return target, nil
+}
+
+// fileWritable returns true if the existing file p can be opened for writing.
+func fileWritable(p string) bool {
+	f, err := os.OpenFile(p, os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	_ = f.Close()
+	return true
+}
+
+// fileCreatable returns true if the non-existent file p could be created, which requires that the closest
+// existing parent is a writable directory.
+func fileCreatable(p string) bool {
+	dir := filepath.Dir(p)
+	for {
+		info, err := os.Stat(dir)
+		if err == nil {
+			if !info.IsDir() {
+				return false
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+
+	f, err := os.CreateTemp(dir, ".abctl-kubeconfig-*")
+	if err != nil {
+		return false
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+	return true
+}
@@ -0,0 +1,256 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+current-context: %[1]s
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[1]s.example.com
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s
+users:
+- name: %[1]s
+  user:
+    token: %[1]s-token
+`
+
+func writeKubeconfig(t *testing.T, path, name string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(testKubeconfig, "%[1]s", name)), 0o600); err != nil {
+		t.Fatal("unable to write kubeconfig", err)
+	}
+}
+
+func TestKubeconfigPaths(t *testing.T) {
+	sep := string(os.PathListSeparator)
+
+	tests := []struct {
+		name string
+		env  string
+		want []string
+	}{
+		{
+			name: "unset",
+			want: []string{clientcmd.RecommendedHomeFile},
+		},
+		{
+			name: "single",
+			env:  "/a/config",
+			want: []string{"/a/config"},
+		},
+		{
+			name: "multiple",
+			env:  strings.Join([]string{"/a/config", "/b/config", "/c/config"}, sep),
+			want: []string{"/a/config", "/b/config", "/c/config"},
+		},
+		{
+			name: "empty entries and duplicates",
+			env:  strings.Join([]string{"", "/a/config", "", "/b/config", "/a/config"}, sep),
+			want: []string{"/a/config", "/b/config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(clientcmd.RecommendedConfigPathEnvVar, tt.env)
+			if d := cmp.Diff(tt.want, KubeconfigPaths()); d != "" {
+				t.Errorf("KubeconfigPaths mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestKubeconfigWriteTarget(t *testing.T) {
+	dir := t.TempDir()
+
+	// a regular file can never be a parent directory, making any path beneath it unwritable
+	notDir := filepath.Join(dir, "not-a-dir")
+	if err := os.WriteFile(notDir, []byte{}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	unwritable := filepath.Join(notDir, "config")
+
+	existingA := filepath.Join(dir, "a.config")
+	existingB := filepath.Join(dir, "b.config")
+	writeKubeconfig(t, existingA, "a")
+	writeKubeconfig(t, existingB, "b")
+	missing := filepath.Join(dir, "nested", "missing.config")
+
+	tests := []struct {
+		name        string
+		kubeconfigs []string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "first existing",
+			kubeconfigs: []string{existingA, existingB},
+			want:        existingA,
+		},
+		{
+			name:        "existing preferred over missing",
+			kubeconfigs: []string{missing, existingB},
+			want:        existingB,
+		},
+		{
+			name:        "unwritable skipped",
+			kubeconfigs: []string{unwritable, existingB},
+			want:        existingB,
+		},
+		{
+			name:        "missing created",
+			kubeconfigs: []string{unwritable, missing},
+			want:        missing,
+		},
+		{
+			name:        "nothing writable",
+			kubeconfigs: []string{unwritable},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := KubeconfigWriteTarget(tt.kubeconfigs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("KubeconfigWriteTarget mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestMergeKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+
+	src := filepath.Join(dir, "abctl.kubeconfig")
+	writeKubeconfig(t, src, "kind-airbyte-abctl")
+
+	notDir := filepath.Join(dir, "not-a-dir")
+	if err := os.WriteFile(notDir, []byte{}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	first := filepath.Join(notDir, "config")
+	second := filepath.Join(dir, "second.config")
+	third := filepath.Join(dir, "third.config")
+	writeKubeconfig(t, second, "second")
+	writeKubeconfig(t, third, "third")
+
+	kubeconfigs := []string{first, second, third}
+
+	target, err := MergeKubeconfig(src, kubeconfigs, false)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(second, target); d != "" {
+		t.Errorf("target mismatch (-want +got):\n%s", d)
+	}
+
+	written, err := clientcmd.LoadFromFile(second)
+	if err != nil {
+		t.Fatal("unable to load merged kubeconfig", err)
+	}
+	for _, name := range []string{"second", "kind-airbyte-abctl"} {
+		if _, ok := written.Contexts[name]; !ok {
+			t.Errorf("expected context %q in merged kubeconfig", name)
+		}
+	}
+	if d := cmp.Diff("second", written.CurrentContext); d != "" {
+		t.Errorf("current-context should not change (-want +got):\n%s", d)
+	}
+
+	// the third file must not be modified
+	untouched, err := clientcmd.LoadFromFile(third)
+	if err != nil {
+		t.Fatal("unable to load kubeconfig", err)
+	}
+	if _, ok := untouched.Contexts["kind-airbyte-abctl"]; ok {
+		t.Error("expected third kubeconfig to be unmodified")
+	}
+
+	// the merged view sees every context, with the first file's current-context winning
+	readable := []string{second, third}
+	merged, err := LoadMergedKubeconfig(readable)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	for _, name := range []string{"second", "third", "kind-airbyte-abctl"} {
+		if _, ok := merged.Contexts[name]; !ok {
+			t.Errorf("expected context %q in merged view", name)
+		}
+	}
+	if d := cmp.Diff("second", merged.CurrentContext); d != "" {
+		t.Errorf("merged current-context mismatch (-want +got):\n%s", d)
+	}
+
+	if _, err := MergeKubeconfig(src, kubeconfigs, true); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	merged, err = LoadMergedKubeconfig(readable)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("kind-airbyte-abctl", merged.CurrentContext); d != "" {
+		t.Errorf("merged current-context mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestUseContext(t *testing.T) {
+	dir := t.TempDir()
+
+	first := filepath.Join(dir, "first.config")
+	second := filepath.Join(dir, "second.config")
+	writeKubeconfig(t, first, "first")
+	writeKubeconfig(t, second, "second")
+	kubeconfigs := []string{first, second}
+
+	// a context defined only in the second file can still be made current
+	target, err := UseContext(kubeconfigs, "second")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(first, target); d != "" {
+		t.Errorf("target mismatch (-want +got):\n%s", d)
+	}
+
+	merged, err := LoadMergedKubeconfig(kubeconfigs)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("second", merged.CurrentContext); d != "" {
+		t.Errorf("current-context mismatch (-want +got):\n%s", d)
+	}
+	// only the current-context is changed
+	if _, ok := merged.Contexts["first"]; !ok {
+		t.Error("expected context \"first\" to be kept")
+	}
+
+	if _, err := UseContext(kubeconfigs, "missing"); err == nil {
+		t.Error("expected error for a missing context")
+	}
+	if merged, _ = LoadMergedKubeconfig(kubeconfigs); merged.CurrentContext != "second" {
+		t.Errorf("current-context should not change for a missing context, got %q", merged.CurrentContext)
+	}
+}
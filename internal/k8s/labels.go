@@ -0,0 +1,102 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ParseLabels parses a slice of label specs in the format <KEY>=<VALUE>.
+// Returns an error if any spec is invalid.
+func ParseLabels(specs []string) (map[string]string, error) {
+	return parseKeyValues("label", specs, validateLabel)
+}
+
+// ParseAnnotations parses a slice of annotation specs in the format <KEY>=<VALUE>.
+// Unlike a label, the value of an annotation is not restricted.
+// Returns an error if any spec is invalid.
+func ParseAnnotations(specs []string) (map[string]string, error) {
+	return parseKeyValues("annotation", specs, validateAnnotation)
+}
+
+// parseKeyValues parses a slice of specs in the format <KEY>=<VALUE>, validating each with validate.
+// The kind names the specs in any returned error.
+func parseKeyValues(kind string, specs []string, validate func(key, value string) error) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	kvs := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s %s is not a valid %s spec, must be <KEY>=<VALUE>", kind, spec, kind)
+		}
+		if err := validate(key, value); err != nil {
+			return nil, fmt.Errorf("%s %s is not valid: %w", kind, spec, err)
+		}
+		kvs[key] = value
+	}
+
+	return kvs, nil
+}
+
+// validateLabel validates the key and value have the syntax of a label, which taints share.
+func validateLabel(key, value string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("invalid key '%s': %s", key, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return fmt.Errorf("invalid value '%s': %s", value, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// validateAnnotation validates the key has the syntax of an annotation key, the value may be any string.
+func validateAnnotation(key, _ string) error {
+	if errs := validation.IsQualifiedName(strings.ToLower(key)); len(errs) > 0 {
+		return fmt.Errorf("invalid key '%s': %s", key, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// LabelSelector selects the objects labeled with the Key set to the Value.
+type LabelSelector struct {
+	Key   string
+	Value string
+}
+
+// String returns the selector in the <KEY>=<VALUE> format.
+func (s LabelSelector) String() string {
+	return s.Key + "=" + s.Value
+}
+
+// Matches returns true if the labels contain the selected label.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	v, ok := labels[s.Key]
+	return ok && v == s.Value
+}
+
+// ParseLabelSelectors parses a slice of label selector specs in the format <KEY>=<VALUE>, keeping their order.
+// Unlike ParseLabels, the same key may be selected with different values.
+// Returns an error if any spec is invalid.
+func ParseLabelSelectors(specs []string) ([]LabelSelector, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	selectors := make([]LabelSelector, len(specs))
+	for i, spec := range specs {
+		key, value, ok := strings.Cut(spec, "=")
+		if !ok {
+			return nil, fmt.Errorf("label selector %s is not a valid label selector spec, must be <KEY>=<VALUE>", spec)
+		}
+		if err := validateLabel(key, value); err != nil {
+			return nil, fmt.Errorf("label selector %s is not valid: %w", spec, err)
+		}
+		selectors[i] = LabelSelector{Key: key, Value: value}
+	}
+
+	return selectors, nil
+}
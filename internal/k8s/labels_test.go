@@ -0,0 +1,148 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "empty input",
+		},
+		{
+			name:  "valid labels",
+			input: []string{"team=data", "example.com/cost-center=1234"},
+			want:  map[string]string{"team": "data", "example.com/cost-center": "1234"},
+		},
+		{
+			name:    "missing value",
+			input:   []string{"team"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			input:   []string{"example.com/=data"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			input:   []string{"team=data team"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLabels(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("labels mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestParseAnnotations(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "empty input",
+		},
+		{
+			name:  "valid annotations",
+			input: []string{"example.com/owner=Data Platform <data@example.com>", "Policy=a=b", "empty="},
+			want:  map[string]string{"example.com/owner": "Data Platform <data@example.com>", "Policy": "a=b", "empty": ""},
+		},
+		{
+			name:    "missing value",
+			input:   []string{"example.com/owner"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			input:   []string{"bad key=value"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAnnotations(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("annotations mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestParseLabelSelectors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    []LabelSelector
+		wantErr bool
+	}{
+		{
+			name: "empty input",
+		},
+		{
+			name:  "same key with different values",
+			input: []string{"app=metrics", "app=sidecar-proxy", "example.com/optional=true"},
+			want: []LabelSelector{
+				{Key: "app", Value: "metrics"},
+				{Key: "app", Value: "sidecar-proxy"},
+				{Key: "example.com/optional", Value: "true"},
+			},
+		},
+		{
+			name:    "missing value",
+			input:   []string{"app"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			input:   []string{"app=metrics server"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLabelSelectors(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("selectors mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestLabelSelector_Matches(t *testing.T) {
+	s := LabelSelector{Key: "app", Value: "metrics"}
+	if !s.Matches(map[string]string{"app": "metrics", "tier": "optional"}) {
+		t.Error("expected the selector to match")
+	}
+	for _, labels := range []map[string]string{nil, {"app": "server"}, {"tier": "metrics"}} {
+		if s.Matches(labels) {
+			t.Errorf("expected the selector not to match %v", labels)
+		}
+	}
+}
@@ -2,12 +2,12 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/airbytehq/abctl/internal/common"
 	"github.com/airbytehq/abctl/internal/docker"
@@ -19,12 +19,30 @@ import (
 	nodeslib "sigs.k8s.io/kind/pkg/cluster/nodes"
 	"sigs.k8s.io/kind/pkg/cluster/nodeutils"
 	"sigs.k8s.io/kind/pkg/exec"
-	"sigs.k8s.io/kind/pkg/fs"
 )
 
+// DefaultImageLoadParallelism is the default number of images loaded into the cluster nodes concurrently.
+const DefaultImageLoadParallelism = 4
+
+// imageLoadParallelism is the number of images loaded into the cluster nodes concurrently.
+var imageLoadParallelism = DefaultImageLoadParallelism
+
+// SetImageLoadParallelism sets the number of images loaded into the cluster nodes concurrently by LoadImages.
+// Zero restores the DefaultImageLoadParallelism.
+func SetImageLoadParallelism(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid image load parallelism %d: must not be negative", n)
+	}
+	if n == 0 {
+		n = DefaultImageLoadParallelism
+	}
+	imageLoadParallelism = n
+	return nil
+}
+
 // loadImages pulls and loads images into the kind cluster.
 // It will pull all images in parallel, skip any images that already exist on the nodes,
-// save the rest to an image archive (tar file), and load archive onto the nodes.
+// and stream the rest into the nodes, imageLoadParallelism images at a time.
 func loadImages(ctx context.Context, dockerClient docker.Client, nodes []nodeslib.Node, images []string) error {
 	ctx, span := trace.NewSpan(ctx, "loadImages")
 	defer span.End()
@@ -32,10 +50,25 @@ func loadImages(ctx context.Context, dockerClient docker.Client, nodes []nodesli
 	span.SetAttributes(attribute.Int("total_nodes", len(nodes)))
 	span.SetAttributes(attribute.Int("total_images", len(images)))
 
+	// Only pull the images which aren't already present locally.
+	d := &docker.Docker{Client: dockerClient}
+	var pulls []string
+	for _, img := range images {
+		required, err := d.ImagePullRequired(ctx, img)
+		if err != nil {
+			pterm.Debug.Printfln("unable to determine if image %s is present: %s", img, err)
+		}
+		if !required {
+			pterm.Debug.Printfln("image %s is already present, skipping pull", img)
+			continue
+		}
+		pulls = append(pulls, img)
+	}
+
 	// Pull all the images via "docker pull", in parallel.
 	var wg sync.WaitGroup
-	wg.Add(len(images))
-	for _, img := range images {
+	wg.Add(len(pulls))
+	for _, img := range pulls {
 		pterm.Info.Printfln("Pulling image %s", img)
 
 		go func(ctx context.Context, img string) {
@@ -71,36 +104,89 @@ func loadImages(ctx context.Context, dockerClient docker.Client, nodes []nodesli
 		return nil
 	}
 
-	// Save all the images to an archive, images.tar
-	imagesTarPath, err := saveImageArchive(ctx, dockerClient, needed)
-	if err != nil {
-		return fmt.Errorf("failed to save image archive: %w", err)
+	importers := make([]imageImporter, len(nodes))
+	for i, n := range nodes {
+		importers[i] = nodeImporter(n)
 	}
-	defer os.RemoveAll(imagesTarPath)
+	return importImages(ctx, dockerClient, needed, imageLoadParallelism, importers)
+}
 
-	// Load the image archive into the Kind nodes.
-	f, err := os.Open(imagesTarPath)
-	if err != nil {
-		return fmt.Errorf("failed to open image archive: %w", err)
+// imageImporter imports the image archive read from r into a cluster node.
+type imageImporter func(ctx context.Context, r io.Reader) error
+
+// nodeImporter returns the imageImporter of the kind node.
+func nodeImporter(n nodeslib.Node) imageImporter {
+	return func(ctx context.Context, r io.Reader) error {
+		_, span := trace.NewSpan(ctx, "nodeutils.LoadImageArchive")
+		defer span.End()
+
+		span.SetAttributes(attribute.String("node", n.String()))
+		if err := nodeutils.LoadImageArchive(n, r); err != nil {
+			return fmt.Errorf("unable to load image archive into node %s: %w", n, err)
+		}
+		return nil
 	}
-	defer f.Close()
+}
 
-	for _, n := range nodes {
-		// TODO: Parallelize loading images onto nodes. Since the file stream can only be
-		// read once, we'll need to open a separate file handle for each node. Currently,
-		// this isn't critical as abctl typically provisions just a single node cluster.
-		func(f *os.File, n nodeslib.Node) {
-			_, span := trace.NewSpan(ctx, "nodeutils.LoadImageArchive")
-			defer span.End()
+// importImages saves each of the images with the docker client, streaming its archive into every importer,
+// so no archive is ever buffered in full. Up to parallelism images are imported concurrently.
+// The failures of every image are returned, each identifying the image which failed.
+func importImages(ctx context.Context, dockerClient docker.Client, images []string, parallelism int, importers []imageImporter) error {
+	ctx, span := trace.NewSpan(ctx, "importImages")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("parallelism", parallelism))
+
+	var (
+		wg     sync.WaitGroup
+		sem    = make(chan struct{}, max(parallelism, 1))
+		errs   = make([]error, len(images))
+		loaded atomic.Int32
+	)
+	for i, img := range images {
+		if err := ctx.Err(); err != nil {
+			errs[i] = fmt.Errorf("unable to load image %s: %w", img, err)
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = fmt.Errorf("unable to load image %s: %w", img, ctx.Err())
+			continue
+		}
 
-			span.SetAttributes(attribute.String("node", n.String()))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-			pterm.Debug.Printfln("loading image archive into kind node %s", n)
-			err := nodeutils.LoadImageArchive(n, f)
-			if err != nil {
-				pterm.Debug.Printfln("%s", err)
+			if err := importImage(ctx, dockerClient, img, importers); err != nil {
+				pterm.Warning.Printfln("Unable to load image %s into the cluster: %s", img, err)
+				errs[i] = fmt.Errorf("unable to load image %s: %w", img, err)
+				return
 			}
-		}(f, n)
+			n := int(loaded.Add(1))
+			pterm.Info.Printfln("Loaded image %s into the cluster (%d/%d, %d%%)", img, n, len(images), n*100/len(images))
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// importImage streams the archive of the image, saved with the docker client, into every importer.
+// The image is saved once per importer, as an archive stream can only be read once.
+func importImage(ctx context.Context, dockerClient docker.Client, img string, importers []imageImporter) error {
+	for _, importer := range importers {
+		r, err := dockerClient.ImageSave(ctx, []string{img})
+		if err != nil {
+			return fmt.Errorf("unable to save image: %w", err)
+		}
+		err = importer(ctx, r)
+		_ = r.Close()
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -191,34 +277,3 @@ func determineImagesForLoading(ctx context.Context, dockerClient docker.Client,
 
 	return needed
 }
-
-func saveImageArchive(ctx context.Context, dockerClient docker.Client, images []string) (string, error) {
-	ctx, span := trace.NewSpan(ctx, "saveImageArchive")
-	defer span.End()
-
-	// Setup the tar path where the images will be saved.
-	dir, err := fs.TempDir("", "images-tar-")
-	if err != nil {
-		return "", err
-	}
-
-	imagesTarPath := filepath.Join(dir, "images.tar")
-	pterm.Debug.Printfln("saving image archive to %s", imagesTarPath)
-
-	wf, err := os.Create(imagesTarPath)
-	if err != nil {
-		return "", err
-	}
-	defer wf.Close()
-
-	r, err := dockerClient.ImageSave(ctx, images)
-	if err != nil {
-		return "", err
-	}
-
-	if _, err := io.Copy(wf, r); err != nil {
-		return "", err
-	}
-
-	return imagesTarPath, nil
-}
@@ -0,0 +1,164 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/google/go-cmp/cmp"
+)
+
+// imageSaver returns a mock docker client whose saved image archives contain the name of the image.
+func imageSaver(fail map[string]error) dockertest.MockClient {
+	client := dockertest.NewMockClient()
+	client.FnImageSave = func(_ context.Context, imageIDs []string) (io.ReadCloser, error) {
+		if err := fail[imageIDs[0]]; err != nil {
+			return nil, err
+		}
+		return io.NopCloser(strings.NewReader(strings.Join(imageIDs, ","))), nil
+	}
+	return client
+}
+
+// recordingImporter is an imageImporter which records the archives it imports, and how many it imports concurrently.
+type recordingImporter struct {
+	mu       sync.Mutex
+	fail     map[string]error
+	imported []string
+	active   int
+	peak     int
+}
+
+func (r *recordingImporter) importer(_ context.Context, archive io.Reader) error {
+	data, err := io.ReadAll(archive)
+	if err != nil {
+		return err
+	}
+	img := string(data)
+
+	r.mu.Lock()
+	r.active++
+	r.peak = max(r.peak, r.active)
+	r.mu.Unlock()
+
+	// give the other imports the chance to run concurrently
+	time.Sleep(20 * time.Millisecond)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active--
+	if err := r.fail[img]; err != nil {
+		return err
+	}
+	r.imported = append(r.imported, img)
+	return nil
+}
+
+func TestImportImages_Parallelism(t *testing.T) {
+	images := []string{"airbyte/server:1.0.0", "airbyte/worker:1.0.0", "airbyte/webapp:1.0.0", "airbyte/cron:1.0.0", "airbyte/bootloader:1.0.0"}
+
+	tests := []struct {
+		name        string
+		parallelism int
+		wantPeak    int
+	}{
+		{name: "sequential", parallelism: 1, wantPeak: 1},
+		{name: "bounded", parallelism: 2, wantPeak: 2},
+		{name: "more than the images", parallelism: 10, wantPeak: len(images)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recordingImporter{}
+			if err := importImages(context.Background(), imageSaver(nil), images, tt.parallelism, []imageImporter{r.importer}); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if d := cmp.Diff(tt.wantPeak, r.peak); d != "" {
+				t.Errorf("peak concurrency mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(len(images), len(r.imported)); d != "" {
+				t.Errorf("imported images mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestImportImages_EveryNode(t *testing.T) {
+	node1, node2 := &recordingImporter{}, &recordingImporter{}
+	images := []string{"airbyte/server:1.0.0", "airbyte/worker:1.0.0"}
+
+	if err := importImages(context.Background(), imageSaver(nil), images, 2, []imageImporter{node1.importer, node2.importer}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// every node reads its own stream of the archive
+	for _, node := range []*recordingImporter{node1, node2} {
+		slices.Sort(node.imported)
+		if d := cmp.Diff(images, node.imported); d != "" {
+			t.Errorf("imported images mismatch (-want +got):\n%s", d)
+		}
+	}
+}
+
+func TestImportImages_Errors(t *testing.T) {
+	images := []string{"airbyte/server:1.0.0", "airbyte/worker:1.0.0", "airbyte/webapp:1.0.0"}
+	client := imageSaver(map[string]error{"airbyte/server:1.0.0": errors.New("no such image")})
+	r := &recordingImporter{fail: map[string]error{"airbyte/webapp:1.0.0": errors.New("ctr: content digest not found")}}
+
+	err := importImages(context.Background(), client, images, 2, []imageImporter{r.importer})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// each failure identifies its image, and doesn't prevent the other images from being loaded
+	for _, want := range []string{
+		"unable to load image airbyte/server:1.0.0: unable to save image: no such image",
+		"unable to load image airbyte/webapp:1.0.0: ctr: content digest not found",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to contain %q, got:\n%s", want, err)
+		}
+	}
+	if d := cmp.Diff([]string{"airbyte/worker:1.0.0"}, r.imported); d != "" {
+		t.Errorf("imported images mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestImportImages_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := &recordingImporter{}
+	err := importImages(ctx, imageSaver(nil), []string{"airbyte/server:1.0.0", "airbyte/worker:1.0.0"}, 1, []imageImporter{r.importer})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled but got %v", err)
+	}
+}
+
+func TestSetImageLoadParallelism(t *testing.T) {
+	t.Cleanup(func() { imageLoadParallelism = DefaultImageLoadParallelism })
+
+	if err := SetImageLoadParallelism(-1); err == nil {
+		t.Error("expected an error")
+	}
+	if err := SetImageLoadParallelism(8); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(8, imageLoadParallelism); d != "" {
+		t.Errorf("parallelism mismatch (-want +got):\n%s", d)
+	}
+
+	if err := SetImageLoadParallelism(0); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(DefaultImageLoadParallelism, imageLoadParallelism); d != "" {
+		t.Errorf("parallelism mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,52 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ClusterMarkerName is the name of the ConfigMap which marks a cluster as being managed by abctl.
+	ClusterMarkerName = "abctl-cluster"
+	// ClusterMarkerNamespace is the namespace the ClusterMarkerName ConfigMap is created in.
+	ClusterMarkerNamespace = "kube-system"
+)
+
+// ClusterMarked returns true if the cluster contains the abctl cluster marker.
+func ClusterMarked(ctx context.Context, client Client) (bool, error) {
+	if _, err := client.ConfigMapGet(ctx, ClusterMarkerNamespace, ClusterMarkerName); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unable to determine if the cluster is managed by abctl: %w", err)
+	}
+
+	return true, nil
+}
+
+// MarkCluster stamps the cluster with the abctl cluster marker, indicating the cluster is managed by abctl.
+func MarkCluster(ctx context.Context, client Client) error {
+	marker := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ClusterMarkerName,
+			Namespace: ClusterMarkerNamespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "abctl",
+			},
+		},
+		Data: map[string]string{
+			"markedAt": time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+
+	if err := client.ConfigMapCreate(ctx, marker); err != nil && !k8serrors.IsAlreadyExists(err) {
+		return fmt.Errorf("unable to mark the cluster as managed by abctl: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/k8s/kind"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeOpts are the labels and taints applied to the node of a created cluster.
+type NodeOpts struct {
+	Labels map[string]string
+	Taints []NodeTaint
+}
+
+// NodeTaint is a taint applied to the node of a created cluster.
+type NodeTaint struct {
+	Key    string
+	Value  string
+	Effect corev1.TaintEffect
+}
+
+// String returns the taint in the <KEY>=<VALUE>:<EFFECT> format.
+func (t NodeTaint) String() string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+}
+
+// Toleration returns the toleration which tolerates this taint.
+func (t NodeTaint) Toleration() corev1.Toleration {
+	if t.Value == "" {
+		return corev1.Toleration{Key: t.Key, Operator: corev1.TolerationOpExists, Effect: t.Effect}
+	}
+	return corev1.Toleration{Key: t.Key, Operator: corev1.TolerationOpEqual, Value: t.Value, Effect: t.Effect}
+}
+
+// taintEffects are the supported taint effects.
+var taintEffects = []corev1.TaintEffect{corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute}
+
+// ParseNodeLabels parses a slice of node label specs in the format <KEY>=<VALUE>.
+// Returns an error if any spec is invalid.
+func ParseNodeLabels(specs []string) (map[string]string, error) {
+	return parseKeyValues("node label", specs, validateLabel)
+}
+
+// ParseNodeTaints parses a slice of node taint specs in the format <KEY>[=<VALUE>]:<EFFECT>.
+// Returns an error if any spec is invalid.
+func ParseNodeTaints(specs []string) ([]NodeTaint, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	taints := make([]NodeTaint, len(specs))
+	for i, spec := range specs {
+		kv, effect, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("node taint %s is not a valid taint spec, must be <KEY>[=<VALUE>]:<EFFECT>", spec)
+		}
+		if !slices.Contains(taintEffects, corev1.TaintEffect(effect)) {
+			return nil, fmt.Errorf("node taint %s has an invalid effect '%s', must be one of %s", spec, effect, joinEffects())
+		}
+		key, value, _ := strings.Cut(kv, "=")
+		if err := validateLabel(key, value); err != nil {
+			return nil, fmt.Errorf("node taint %s is not valid: %w", spec, err)
+		}
+		taints[i] = NodeTaint{Key: key, Value: value, Effect: corev1.TaintEffect(effect)}
+	}
+
+	return taints, nil
+}
+
+func joinEffects() string {
+	effects := make([]string, len(taintEffects))
+	for i, e := range taintEffects {
+		effects[i] = string(e)
+	}
+	return strings.Join(effects, ", ")
+}
+
+// kindTaints converts the taints to their kind config representation.
+func kindTaints(taints []NodeTaint) []kind.Taint {
+	if len(taints) == 0 {
+		return nil
+	}
+	out := make([]kind.Taint, len(taints))
+	for i, t := range taints {
+		out[i] = kind.Taint{Key: t.Key, Value: t.Value, Effect: string(t.Effect)}
+	}
+	return out
+}
+
+// systemDeployments are the deployments kind runs on the node, which must tolerate any taints applied to it.
+var systemDeployments = []struct{ namespace, name string }{
+	{namespace: "kube-system", name: "coredns"},
+	{namespace: "local-path-storage", name: "local-path-provisioner"},
+}
+
+// TolerateSystemPods adds a toleration for each of the taints to the system deployments of the cluster,
+// otherwise the taints would prevent them from being scheduled.
+func TolerateSystemPods(ctx context.Context, client Client, taints []NodeTaint) error {
+	if len(taints) == 0 {
+		return nil
+	}
+
+	tolerations := make([]corev1.Toleration, len(taints))
+	for i, t := range taints {
+		tolerations[i] = t.Toleration()
+	}
+
+	for _, d := range systemDeployments {
+		if err := client.DeploymentAddTolerations(ctx, d.namespace, d.name, tolerations); err != nil {
+			return fmt.Errorf("unable to add the node taint tolerations to %s/%s: %w", d.namespace, d.name, err)
+		}
+	}
+
+	return nil
+}
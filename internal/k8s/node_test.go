@@ -0,0 +1,173 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/k8s/kind"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseNodeLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "empty input",
+		},
+		{
+			name:  "valid labels",
+			input: []string{"team=data", "example.com/env=dev", "empty="},
+			want:  map[string]string{"team": "data", "example.com/env": "dev", "empty": ""},
+		},
+		{
+			name:    "missing value",
+			input:   []string{"team"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			input:   []string{"bad key=data"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			input:   []string{"team=-data"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNodeLabels(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("labels mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestParseNodeTaints(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    []NodeTaint
+		wantErr bool
+	}{
+		{
+			name: "empty input",
+		},
+		{
+			name:  "valid taints",
+			input: []string{"dedicated=airbyte:NoSchedule", "spot:PreferNoSchedule", "example.com/gpu=true:NoExecute"},
+			want: []NodeTaint{
+				{Key: "dedicated", Value: "airbyte", Effect: corev1.TaintEffectNoSchedule},
+				{Key: "spot", Effect: corev1.TaintEffectPreferNoSchedule},
+				{Key: "example.com/gpu", Value: "true", Effect: corev1.TaintEffectNoExecute},
+			},
+		},
+		{
+			name:    "missing effect",
+			input:   []string{"dedicated=airbyte"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid effect",
+			input:   []string{"dedicated=airbyte:Never"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			input:   []string{"=airbyte:NoSchedule"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			input:   []string{"dedicated=air byte:NoSchedule"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNodeTaints(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("taints mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestNodeTaint_Toleration(t *testing.T) {
+	withValue := NodeTaint{Key: "dedicated", Value: "airbyte", Effect: corev1.TaintEffectNoSchedule}
+	if d := cmp.Diff(corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "airbyte", Effect: corev1.TaintEffectNoSchedule}, withValue.Toleration()); d != "" {
+		t.Errorf("toleration mismatch (-want +got):\n%s", d)
+	}
+
+	withoutValue := NodeTaint{Key: "spot", Effect: corev1.TaintEffectNoExecute}
+	if d := cmp.Diff(corev1.Toleration{Key: "spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute}, withoutValue.Toleration()); d != "" {
+		t.Errorf("toleration mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestKindTaints(t *testing.T) {
+	taints := []NodeTaint{{Key: "dedicated", Value: "airbyte", Effect: corev1.TaintEffectNoSchedule}}
+	if d := cmp.Diff([]kind.Taint{{Key: "dedicated", Value: "airbyte", Effect: "NoSchedule"}}, kindTaints(taints)); d != "" {
+		t.Errorf("taints mismatch (-want +got):\n%s", d)
+	}
+}
+
+type tolerationsClient struct {
+	Client
+	tolerated map[string][]corev1.Toleration
+	err       error
+}
+
+func (c *tolerationsClient) DeploymentAddTolerations(_ context.Context, namespace, name string, tolerations []corev1.Toleration) error {
+	c.tolerated[namespace+"/"+name] = tolerations
+	return c.err
+}
+
+func TestTolerateSystemPods(t *testing.T) {
+	client := &tolerationsClient{tolerated: map[string][]corev1.Toleration{}}
+	taints := []NodeTaint{{Key: "dedicated", Value: "airbyte", Effect: corev1.TaintEffectNoSchedule}}
+
+	if err := TolerateSystemPods(context.Background(), client, taints); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	tolerations := []corev1.Toleration{taints[0].Toleration()}
+	want := map[string][]corev1.Toleration{
+		"kube-system/coredns":                       tolerations,
+		"local-path-storage/local-path-provisioner": tolerations,
+	}
+	if d := cmp.Diff(want, client.tolerated); d != "" {
+		t.Errorf("tolerations mismatch (-want +got):\n%s", d)
+	}
+
+	// no taints, no tolerations
+	client = &tolerationsClient{tolerated: map[string][]corev1.Toleration{}}
+	if err := TolerateSystemPods(context.Background(), client, nil); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(client.tolerated) != 0 {
+		t.Errorf("expected no tolerations, got %v", client.tolerated)
+	}
+
+	errTest := errors.New("test error")
+	client = &tolerationsClient{tolerated: map[string][]corev1.Toleration{}, err: errTest}
+	if err := TolerateSystemPods(context.Background(), client, taints); !errors.Is(err, errTest) {
+		t.Errorf("expected error %v, got %v", errTest, err)
+	}
+}
@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultNodeReadyTimeout is how long to wait for the nodes to be Ready, if no timeout is provided.
+const DefaultNodeReadyTimeout = 5 * time.Minute
+
+// nodeReadyPollInterval is how often the nodes are checked while waiting for them to be Ready.
+// This variable should only be modified for testing purposes.
+var nodeReadyPollInterval = 2 * time.Second
+
+// WaitForNodesReady waits up to the timeout for every node of the cluster to register as Ready.
+// As the api-server of a new cluster may not be answering yet, failing to list the nodes is retried until the timeout.
+// An ErrNodeNotReady error describing the last conditions of the nodes is returned if they don't become Ready in time.
+// A timeout of 0 waits for the DefaultNodeReadyTimeout.
+func WaitForNodesReady(ctx context.Context, client Client, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = DefaultNodeReadyTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		nodes   []corev1.Node
+		lastErr error
+	)
+	for {
+		list, err := client.NodeList(ctx)
+		if err == nil {
+			nodes, lastErr = list.Items, nil
+			if len(nodes) > 0 && allNodesReady(nodes) {
+				return nil
+			}
+		} else {
+			lastErr = err
+			pterm.Debug.Printfln("Unable to list the nodes: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("waiting for the nodes to be ready cancelled: %w", ctx.Err())
+			}
+			if lastErr != nil {
+				return fmt.Errorf("%w within %s: unable to list the nodes: %w", abctl.ErrNodeNotReady, timeout, lastErr)
+			}
+			return fmt.Errorf("%w within %s: %s", abctl.ErrNodeNotReady, timeout, describeNodes(nodes))
+		case <-time.After(nodeReadyPollInterval):
+		}
+	}
+}
+
+// allNodesReady returns true if every node has a Ready condition which is true.
+func allNodesReady(nodes []corev1.Node) bool {
+	for _, node := range nodes {
+		if !nodeReady(node) {
+			return false
+		}
+	}
+	return true
+}
+
+func nodeReady(node corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// describeNodes returns the conditions of the nodes which aren't Ready,
+// e.g. "node airbyte-abctl-control-plane: Ready=False (KubeletNotReady: container runtime network not ready)".
+func describeNodes(nodes []corev1.Node) string {
+	if len(nodes) == 0 {
+		return "no node has registered"
+	}
+
+	var descs []string
+	for _, node := range nodes {
+		if nodeReady(node) {
+			continue
+		}
+		if len(node.Status.Conditions) == 0 {
+			descs = append(descs, fmt.Sprintf("node %s: no conditions reported", node.Name))
+			continue
+		}
+		conds := make([]string, len(node.Status.Conditions))
+		for i, c := range node.Status.Conditions {
+			conds[i] = fmt.Sprintf("%s=%s", c.Type, c.Status)
+			switch {
+			case c.Reason != "" && c.Message != "":
+				conds[i] += fmt.Sprintf(" (%s: %s)", c.Reason, c.Message)
+			case c.Reason != "":
+				conds[i] += fmt.Sprintf(" (%s)", c.Reason)
+			}
+		}
+		descs = append(descs, fmt.Sprintf("node %s: %s", node.Name, strings.Join(conds, ", ")))
+	}
+	return strings.Join(descs, "; ")
+}
@@ -0,0 +1,114 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	testingk8s "k8s.io/client-go/testing"
+)
+
+func setNodeReadyPollInterval(t *testing.T, d time.Duration) {
+	orig := nodeReadyPollInterval
+	nodeReadyPollInterval = d
+	t.Cleanup(func() { nodeReadyPollInterval = orig })
+}
+
+func testNode(ready corev1.ConditionStatus, reason, message string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-control-plane"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse, Reason: "KubeletHasSufficientMemory"},
+			{Type: corev1.NodeReady, Status: ready, Reason: reason, Message: message},
+		}},
+	}
+}
+
+func TestWaitForNodesReady(t *testing.T) {
+	setNodeReadyPollInterval(t, time.Millisecond)
+
+	// the node is reported NotReady, until the third list
+	cs := fake.NewSimpleClientset()
+	lists := 0
+	cs.PrependReactor("list", "nodes", func(testingk8s.Action) (bool, runtime.Object, error) {
+		lists++
+		node := testNode(corev1.ConditionFalse, "KubeletNotReady", "container runtime network not ready")
+		if lists >= 3 {
+			node = testNode(corev1.ConditionTrue, "KubeletReady", "kubelet is posting ready status")
+		}
+		return true, &corev1.NodeList{Items: []corev1.Node{*node}}, nil
+	})
+
+	if err := WaitForNodesReady(context.Background(), &DefaultK8sClient{ClientSet: cs}, time.Minute); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff(3, lists); d != "" {
+		t.Errorf("lists mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestWaitForNodesReady_Timeout(t *testing.T) {
+	setNodeReadyPollInterval(t, time.Millisecond)
+
+	tests := []struct {
+		name    string
+		nodes   []runtime.Object
+		listErr error
+		wantMsg string
+	}{
+		{
+			name:    "not ready",
+			nodes:   []runtime.Object{testNode(corev1.ConditionFalse, "KubeletNotReady", "container runtime network not ready")},
+			wantMsg: "kind node did not become Ready within 20ms: node airbyte-abctl-control-plane: MemoryPressure=False (KubeletHasSufficientMemory), Ready=False (KubeletNotReady: container runtime network not ready)",
+		},
+		{
+			name:    "not registered",
+			wantMsg: "kind node did not become Ready within 20ms: no node has registered",
+		},
+		{
+			name:    "list error",
+			listErr: errors.New("connection refused"),
+			wantMsg: "kind node did not become Ready within 20ms: unable to list the nodes: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset(tt.nodes...)
+			if tt.listErr != nil {
+				cs.PrependReactor("list", "nodes", func(testingk8s.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.listErr
+				})
+			}
+
+			err := WaitForNodesReady(context.Background(), &DefaultK8sClient{ClientSet: cs}, 20*time.Millisecond)
+			if !errors.Is(err, abctl.ErrNodeNotReady) {
+				t.Fatalf("expected error %v, got %v", abctl.ErrNodeNotReady, err)
+			}
+			if d := cmp.Diff(tt.wantMsg, err.Error()); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestWaitForNodesReady_Cancelled(t *testing.T) {
+	setNodeReadyPollInterval(t, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cs := fake.NewSimpleClientset(testNode(corev1.ConditionFalse, "KubeletNotReady", ""))
+	err := WaitForNodesReady(ctx, &DefaultK8sClient{ClientSet: cs}, time.Minute)
+	if err == nil || errors.Is(err, abctl.ErrNodeNotReady) || !strings.Contains(err.Error(), "cancelled") {
+		t.Errorf("expected the wait to be cancelled, got %v", err)
+	}
+}
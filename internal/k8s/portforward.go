@@ -0,0 +1,193 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ErrNoPod is returned when no running and ready pod matches a selection.
+var ErrNoPod = errors.New("no running and ready pod found")
+
+// SelectPod returns the name of a running and ready pod in the namespace which has all the labels.
+// If multiple pods match, the first by name is returned.
+func SelectPod(ctx context.Context, client Client, namespace string, labels map[string]string) (string, error) {
+	pods, err := client.PodList(ctx, namespace)
+	if err != nil {
+		return "", fmt.Errorf("unable to list pods in namespace '%s': %w", namespace, err)
+	}
+
+	var selected string
+	for _, pod := range pods.Items {
+		if !podMatches(pod, labels) || !podRunningAndReady(pod) {
+			continue
+		}
+		if selected == "" || pod.Name < selected {
+			selected = pod.Name
+		}
+	}
+	if selected == "" {
+		return "", fmt.Errorf("%w in namespace '%s' matching %v", ErrNoPod, namespace, labels)
+	}
+
+	return selected, nil
+}
+
+// ServicePod returns the name of a running and ready pod backing the service.
+func ServicePod(ctx context.Context, client Client, namespace, service string) (string, error) {
+	svc, err := client.ServiceGet(ctx, namespace, service)
+	if err != nil {
+		return "", fmt.Errorf("unable to get service '%s': %w", service, err)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", fmt.Errorf("service '%s' has no pod selector", service)
+	}
+
+	return SelectPod(ctx, client, namespace, svc.Spec.Selector)
+}
+
+func podMatches(pod corev1.Pod, labels map[string]string) bool {
+	for k, v := range labels {
+		if pod.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func podRunningAndReady(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Forward is an established port-forward to a pod, created by PortForward.
+type Forward struct {
+	addrs  map[string]string
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Addr returns the local address, e.g. 127.0.0.1:51234, forwarding to the pod port.
+// An empty string is returned if the pod port isn't forwarded.
+func (f *Forward) Addr(port string) string {
+	return f.addrs[port]
+}
+
+// Close stops the port-forward, waiting for it to shut down.
+func (f *Forward) Close() error {
+	f.once.Do(f.cancel)
+	<-f.done
+	return nil
+}
+
+// portForwardRetries is the number of consecutive attempts to re-establish a dropped port-forward.
+var portForwardRetries = 5
+
+// portForwardRetryDelay is the delay between the attempts to re-establish a dropped port-forward.
+var portForwardRetryDelay = time.Second
+
+// freePort returns an available local port. Exists as a variable for testing purposes.
+var freePort = func() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("unable to find an available port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// PortForward forwards an available local port to each of the ports of the pod, returning once the ports are
+// listening. The local ports remain the same if a dropped connection to the pod is re-established.
+// The port-forward is stopped when the ctx is done or the returned Forward is closed.
+func PortForward(ctx context.Context, client Client, namespace, pod string, ports []string) (*Forward, error) {
+	if len(ports) == 0 {
+		return nil, errors.New("no ports to forward")
+	}
+
+	addrs := map[string]string{}
+	specs := make([]string, 0, len(ports))
+	for _, port := range ports {
+		local, err := freePort()
+		if err != nil {
+			return nil, err
+		}
+		addrs[port] = net.JoinHostPort("127.0.0.1", strconv.Itoa(local))
+		specs = append(specs, fmt.Sprintf("%d:%s", local, port))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	f := &Forward{addrs: addrs, cancel: cancel, done: make(chan struct{})}
+
+	ready := make(chan struct{})
+	failed := make(chan error, 1)
+	go func() {
+		defer close(f.done)
+
+		err := client.PodPortForward(ctx, namespace, pod, specs, ready)
+		if !isClosed(ready) {
+			if err == nil {
+				err = errors.New("port-forward stopped before it was ready")
+			}
+			failed <- err
+			return
+		}
+
+		for attempt := 0; ctx.Err() == nil; {
+			if attempt == portForwardRetries {
+				pterm.Debug.Printfln("Unable to re-establish the port-forward to pod '%s': %s", pod, err)
+				return
+			}
+			pterm.Debug.Printfln("Port-forward to pod '%s' dropped, reconnecting: %v", pod, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(portForwardRetryDelay):
+			}
+
+			r := make(chan struct{})
+			err = client.PodPortForward(ctx, namespace, pod, specs, r)
+			if isClosed(r) {
+				attempt = 0
+			} else {
+				attempt++
+			}
+		}
+	}()
+
+	select {
+	case <-ready:
+		return f, nil
+	case err := <-failed:
+		cancel()
+		return nil, fmt.Errorf("unable to port-forward to pod '%s': %w", pod, err)
+	case <-ctx.Done():
+		_ = f.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// isClosed returns true if the channel is closed.
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
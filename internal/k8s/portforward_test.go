@@ -0,0 +1,257 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeForwardClient implements the Client methods used by the port-forward helpers.
+type fakeForwardClient struct {
+	Client
+	pods    []corev1.Pod
+	service *corev1.Service
+
+	mu       sync.Mutex
+	forwards [][]string
+	// forward is called for every PodPortForward call, attempt is 1-indexed
+	forward func(ctx context.Context, attempt int, ready chan struct{}) error
+}
+
+func (f *fakeForwardClient) PodList(_ context.Context, _ string) (*corev1.PodList, error) {
+	return &corev1.PodList{Items: f.pods}, nil
+}
+
+func (f *fakeForwardClient) ServiceGet(_ context.Context, _, _ string) (*corev1.Service, error) {
+	return f.service, nil
+}
+
+func (f *fakeForwardClient) PodPortForward(ctx context.Context, _, _ string, ports []string, ready chan struct{}) error {
+	f.mu.Lock()
+	f.forwards = append(f.forwards, ports)
+	attempt := len(f.forwards)
+	f.mu.Unlock()
+
+	return f.forward(ctx, attempt, ready)
+}
+
+func (f *fakeForwardClient) attempts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.forwards)
+}
+
+// established simulates a port-forward which is listening until the ctx is done.
+func established(ctx context.Context, ready chan struct{}) error {
+	close(ready)
+	<-ctx.Done()
+	return nil
+}
+
+// setFreePorts replaces freePort with one returning the ports in order.
+func setFreePorts(t *testing.T, ports ...int) {
+	orig := freePort
+	t.Cleanup(func() { freePort = orig })
+	freePort = func() (int, error) {
+		port := ports[0]
+		ports = ports[1:]
+		return port, nil
+	}
+}
+
+// waitClosed fails the test if the channel isn't closed within a second.
+func waitClosed(t *testing.T, ch chan struct{}) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the port-forward to stop")
+	}
+}
+
+func TestPortForward_PortSelection(t *testing.T) {
+	setFreePorts(t, 40001, 40002)
+	client := &fakeForwardClient{forward: func(ctx context.Context, _ int, ready chan struct{}) error {
+		return established(ctx, ready)
+	}}
+
+	f, err := PortForward(context.Background(), client, "airbyte-abctl", "airbyte-abctl-server-0", []string{"8001", "9000"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff([][]string{{"40001:8001", "40002:9000"}}, client.forwards); d != "" {
+		t.Errorf("ports mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff("127.0.0.1:40001", f.Addr("8001")); d != "" {
+		t.Errorf("addr mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff("127.0.0.1:40002", f.Addr("9000")); d != "" {
+		t.Errorf("addr mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff("", f.Addr("80")); d != "" {
+		t.Errorf("addr mismatch (-want +got):\n%s", d)
+	}
+
+	if err := f.Close(); err != nil {
+		t.Error("unexpected error", err)
+	}
+	waitClosed(t, f.done)
+	// closing more than once is safe
+	if err := f.Close(); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestPortForward_ContextCancel(t *testing.T) {
+	setFreePorts(t, 40001)
+	client := &fakeForwardClient{forward: func(ctx context.Context, _ int, ready chan struct{}) error {
+		return established(ctx, ready)
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f, err := PortForward(ctx, client, "airbyte-abctl", "airbyte-abctl-server-0", []string{"8001"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cancel()
+	waitClosed(t, f.done)
+	if d := cmp.Diff(1, client.attempts()); d != "" {
+		t.Errorf("attempts mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestPortForward_Error(t *testing.T) {
+	setFreePorts(t, 40001)
+	client := &fakeForwardClient{forward: func(_ context.Context, _ int, _ chan struct{}) error {
+		return errors.New("pod not found")
+	}}
+
+	if _, err := PortForward(context.Background(), client, "airbyte-abctl", "airbyte-abctl-server-0", []string{"8001"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestPortForward_Reconnect(t *testing.T) {
+	origDelay := portForwardRetryDelay
+	t.Cleanup(func() { portForwardRetryDelay = origDelay })
+	portForwardRetryDelay = time.Millisecond
+
+	setFreePorts(t, 40001)
+	reconnected := make(chan struct{})
+	client := &fakeForwardClient{forward: func(ctx context.Context, attempt int, ready chan struct{}) error {
+		switch attempt {
+		case 1:
+			// established and then dropped
+			close(ready)
+			return errors.New("lost connection to pod")
+		case 2:
+			// transient failure while reconnecting
+			return errors.New("connection refused")
+		default:
+			close(reconnected)
+			return established(ctx, ready)
+		}
+	}}
+
+	f, err := PortForward(context.Background(), client, "airbyte-abctl", "airbyte-abctl-server-0", []string{"8001"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+
+	waitClosed(t, reconnected)
+	// the same local port is reused for every attempt
+	want := [][]string{{"40001:8001"}, {"40001:8001"}, {"40001:8001"}}
+	client.mu.Lock()
+	if d := cmp.Diff(want, client.forwards); d != "" {
+		t.Errorf("ports mismatch (-want +got):\n%s", d)
+	}
+	client.mu.Unlock()
+}
+
+func TestPortForward_ReconnectExhausted(t *testing.T) {
+	origDelay, origRetries := portForwardRetryDelay, portForwardRetries
+	t.Cleanup(func() { portForwardRetryDelay, portForwardRetries = origDelay, origRetries })
+	portForwardRetryDelay = time.Millisecond
+	portForwardRetries = 2
+
+	setFreePorts(t, 40001)
+	client := &fakeForwardClient{forward: func(_ context.Context, attempt int, ready chan struct{}) error {
+		if attempt == 1 {
+			close(ready)
+		}
+		return errors.New("lost connection to pod")
+	}}
+
+	f, err := PortForward(context.Background(), client, "airbyte-abctl", "airbyte-abctl-server-0", []string{"8001"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the forward stops on its own once the retries are exhausted
+	waitClosed(t, f.done)
+	if d := cmp.Diff(3, client.attempts()); d != "" {
+		t.Errorf("attempts mismatch (-want +got):\n%s", d)
+	}
+}
+
+func testForwardPod(name string, labels map[string]string, ready bool) corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestSelectPod(t *testing.T) {
+	server := map[string]string{"app.kubernetes.io/name": "server"}
+	client := &fakeForwardClient{pods: []corev1.Pod{
+		testForwardPod("airbyte-abctl-worker-b", map[string]string{"app.kubernetes.io/name": "worker"}, true),
+		testForwardPod("airbyte-abctl-server-c", server, true),
+		testForwardPod("airbyte-abctl-server-a", server, false),
+		testForwardPod("airbyte-abctl-server-b", server, true),
+	}}
+
+	got, err := SelectPod(context.Background(), client, "airbyte-abctl", server)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("airbyte-abctl-server-b", got); d != "" {
+		t.Errorf("pod mismatch (-want +got):\n%s", d)
+	}
+
+	if _, err := SelectPod(context.Background(), client, "airbyte-abctl", map[string]string{"app.kubernetes.io/name": "db"}); !errors.Is(err, ErrNoPod) {
+		t.Errorf("expected ErrNoPod but got %v", err)
+	}
+}
+
+func TestServicePod(t *testing.T) {
+	client := &fakeForwardClient{
+		pods: []corev1.Pod{
+			testForwardPod("airbyte-abctl-server-a", map[string]string{"app.kubernetes.io/name": "server", "pod-template-hash": "abc"}, true),
+		},
+		service: &corev1.Service{Spec: corev1.ServiceSpec{Selector: map[string]string{"app.kubernetes.io/name": "server"}}},
+	}
+
+	got, err := ServicePod(context.Background(), client, "airbyte-abctl", "airbyte-abctl-airbyte-server-svc")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff("airbyte-abctl-server-a", got); d != "" {
+		t.Errorf("pod mismatch (-want +got):\n%s", d)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/common"
 	"github.com/airbytehq/abctl/internal/paths"
 	"github.com/airbytehq/abctl/internal/trace"
@@ -24,27 +25,75 @@ type Provider struct {
 	Context string
 	// Kubeconfig location
 	Kubeconfig string
+
+	// cluster is returned by Cluster instead of a kind cluster when set, see FakeProvider.
+	cluster Cluster
+}
+
+// newKindProvider returns the kind provider the clusters are managed with, exists for testing purposes.
+var newKindProvider = func() kindProvider {
+	return cluster.NewProvider(cluster.ProviderWithLogger(&kindLogger{pterm: pterm.Debug}))
 }
 
-// Cluster returns a kubernetes cluster for this provider.
+// Cluster returns a handle to the kubernetes cluster of this provider, whether it exists or not, without creating it.
+// The kubeconfig of an existing cluster is refreshed from kind, so that it can be reached with the Kubeconfig.
+// Prefer GetCluster or EnsureCluster, which make it explicit whether the cluster must already exist.
 func (p Provider) Cluster(ctx context.Context) (Cluster, error) {
-	_, span := trace.NewSpan(ctx, "Provider.Cluster")
+	ctx, span := trace.NewSpan(ctx, "Provider.Cluster")
 	defer span.End()
 
-	if err := os.MkdirAll(filepath.Dir(p.Kubeconfig), 0o766); err != nil {
-		return nil, fmt.Errorf("unable to create directory %s: %v", p.Kubeconfig, err)
+	if p.cluster != nil {
+		return p.cluster, nil
 	}
 
-	kindProvider := cluster.NewProvider(cluster.ProviderWithLogger(&kindLogger{pterm: pterm.Debug}))
-	if err := kindProvider.ExportKubeConfig(p.ClusterName, p.Kubeconfig, false); err != nil {
-		pterm.Debug.Printfln("failed to export kube config: %s", err)
+	if err := os.MkdirAll(filepath.Dir(p.Kubeconfig), 0o766); err != nil {
+		return nil, fmt.Errorf("unable to create directory %s: %v", p.Kubeconfig, err)
 	}
 
-	return &KindCluster{
+	kindProvider := newKindProvider()
+	c := &KindCluster{
 		p:           kindProvider,
 		kubeconfig:  p.Kubeconfig,
 		clusterName: p.ClusterName,
-	}, nil
+	}
+
+	// a cluster which doesn't exist has no kubeconfig to export
+	if c.Exists(ctx) {
+		if err := kindProvider.ExportKubeConfig(p.ClusterName, p.Kubeconfig, false); err != nil {
+			pterm.Debug.Printfln("failed to export kube config: %s", err)
+		}
+	}
+
+	return c, nil
+}
+
+// GetCluster returns the existing kubernetes cluster of this provider, for the commands which only operate on an
+// existing cluster, e.g. status. Returns an ErrClusterNotFound error if it doesn't exist.
+func (p Provider) GetCluster(ctx context.Context) (Cluster, error) {
+	c, err := p.Cluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Exists(ctx) {
+		return nil, fmt.Errorf("%w: cluster '%s'", abctl.ErrClusterNotFound, p.ClusterName)
+	}
+	return c, nil
+}
+
+// EnsureCluster returns the kubernetes cluster of this provider, creating it if it doesn't exist, and whether it
+// was created. An existing cluster is reused as is, the port, extraMounts and node are only applied to a new one.
+func (p Provider) EnsureCluster(ctx context.Context, port int, extraMounts []ExtraVolumeMount, node NodeOpts) (Cluster, bool, error) {
+	c, err := p.Cluster(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if c.Exists(ctx) {
+		return c, false, nil
+	}
+	if err := c.Create(ctx, port, extraMounts, node); err != nil {
+		return nil, false, err
+	}
+	return c, true, nil
 }
 
 var (
@@ -93,8 +142,17 @@ func (k *kindLogger) V(_ log.Level) log.InfoLogger {
 const (
 	Kind = "kind"
 	Test = "test"
+	// Fake is a provider for a cluster held in memory, see FakeProvider.
+	Fake = "fake"
+	// External is a provider for an existing cluster which is not managed by abctl.
+	External = "external"
 )
 
+// RequiresDocker returns true if the provider runs its cluster within docker.
+func (p Provider) RequiresDocker() bool {
+	return p.Name != External && p.Name != Fake
+}
+
 var (
 	// DefaultProvider represents the kind (https://kind.sigs.k8s.io/) provider.
 	DefaultProvider = Provider{
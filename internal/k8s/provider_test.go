@@ -7,8 +7,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/paths"
 	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/kind/pkg/cluster"
+	nodeslib "sigs.k8s.io/kind/pkg/cluster/nodes"
 )
 
 func TestProvider_Defaults(t *testing.T) {
@@ -49,6 +52,15 @@ func TestProvider_Defaults(t *testing.T) {
 	})
 }
 
+func TestProvider_RequiresDocker(t *testing.T) {
+	if !DefaultProvider.RequiresDocker() {
+		t.Error("expected the kind provider to require docker")
+	}
+	if (Provider{Name: External}).RequiresDocker() {
+		t.Error("expected the external provider to not require docker")
+	}
+}
+
 func TestProvider_Cluster(t *testing.T) {
 	// go will reuse TempDir directories between runs, ensure it is clean before running this test
 	if err := os.RemoveAll(filepath.Dir(TestProvider.Kubeconfig)); err != nil {
@@ -73,6 +85,132 @@ func TestProvider_Cluster(t *testing.T) {
 	}
 }
 
+// stubKindProvider is a kind provider which records the clusters it creates and the kubeconfigs it exports.
+type stubKindProvider struct {
+	clusters []string
+	creates  []string
+	exports  []string
+}
+
+func (s *stubKindProvider) Create(name string, _ ...cluster.CreateOption) error {
+	s.creates = append(s.creates, name)
+	s.clusters = append(s.clusters, name)
+	return nil
+}
+
+func (s *stubKindProvider) Delete(string, string) error {
+	return nil
+}
+
+func (s *stubKindProvider) ExportKubeConfig(name, _ string, _ bool) error {
+	s.exports = append(s.exports, name)
+	return nil
+}
+
+func (s *stubKindProvider) List() ([]string, error) {
+	return s.clusters, nil
+}
+
+func (s *stubKindProvider) ListNodes(string) ([]nodeslib.Node, error) {
+	return nil, nil
+}
+
+// setKindProvider makes the provider use the kind provider for the duration of the test.
+func setKindProvider(t *testing.T, kind kindProvider) {
+	orig := newKindProvider
+	newKindProvider = func() kindProvider { return kind }
+	t.Cleanup(func() { newKindProvider = orig })
+}
+
+func TestProvider_GetCluster(t *testing.T) {
+	setTestDataDir(t)
+	provider := Provider{Name: Test, ClusterName: "test", Kubeconfig: filepath.Join(t.TempDir(), "abctl.kubeconfig")}
+
+	t.Run("existing", func(t *testing.T) {
+		kind := &stubKindProvider{clusters: []string{"test"}}
+		setKindProvider(t, kind)
+
+		c, err := provider.GetCluster(context.Background())
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if !c.Exists(context.Background()) {
+			t.Error("expected the cluster to exist")
+		}
+		// the kubeconfig of the existing cluster is refreshed, it's never recreated
+		if d := cmp.Diff([]string{"test"}, kind.exports); d != "" {
+			t.Errorf("exports mismatch (-want +got):\n%s", d)
+		}
+		if len(kind.creates) != 0 {
+			t.Errorf("expected no create but got %v", kind.creates)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		kind := &stubKindProvider{clusters: []string{"other"}}
+		setKindProvider(t, kind)
+
+		_, err := provider.GetCluster(context.Background())
+		if !errors.Is(err, abctl.ErrClusterNotFound) {
+			t.Errorf("expected ErrClusterNotFound but got %v", err)
+		}
+		if len(kind.creates) != 0 {
+			t.Errorf("expected no create but got %v", kind.creates)
+		}
+		if len(kind.exports) != 0 {
+			t.Errorf("expected no export but got %v", kind.exports)
+		}
+	})
+}
+
+func TestProvider_EnsureCluster(t *testing.T) {
+	setTestDataDir(t)
+	provider := Provider{Name: Test, ClusterName: "test", Kubeconfig: filepath.Join(t.TempDir(), "abctl.kubeconfig")}
+
+	t.Run("existing", func(t *testing.T) {
+		kind := &stubKindProvider{clusters: []string{"test"}}
+		setKindProvider(t, kind)
+
+		_, created, err := provider.EnsureCluster(context.Background(), 8000, nil, NodeOpts{})
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if created {
+			t.Error("expected the existing cluster to be reused")
+		}
+		if len(kind.creates) != 0 {
+			t.Errorf("expected no create but got %v", kind.creates)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		kind := &stubKindProvider{}
+		setKindProvider(t, kind)
+
+		c, created, err := provider.EnsureCluster(context.Background(), 8000, nil, NodeOpts{})
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+		if !created {
+			t.Error("expected the cluster to be created")
+		}
+		if d := cmp.Diff([]string{"test"}, kind.creates); d != "" {
+			t.Errorf("creates mismatch (-want +got):\n%s", d)
+		}
+		if !c.Exists(context.Background()) {
+			t.Error("expected the cluster to exist")
+		}
+
+		// ensuring it again reuses the created cluster
+		if _, created, err := provider.EnsureCluster(context.Background(), 8000, nil, NodeOpts{}); err != nil || created {
+			t.Errorf("expected the cluster to be reused, got created %t, error %v", created, err)
+		}
+		if len(kind.creates) != 1 {
+			t.Errorf("expected 1 create but got %d", len(kind.creates))
+		}
+	})
+}
+
 func dirExists(dir string) bool {
 	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
 		return false
@@ -2,6 +2,9 @@ package k8s
 
 import (
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -32,3 +35,50 @@ func ParseVolumeMounts(specs []string) ([]ExtraVolumeMount, error) {
 
 	return mounts, nil
 }
+
+// errInvalidNodeExtraMountSpec returns an error for an invalid node extra mount spec.
+func errInvalidNodeExtraMountSpec(spec string) error {
+	return fmt.Errorf("node extra mount %s is not a valid mount spec, must be <HOST_PATH>=<NODE_PATH>[:ro]", spec)
+}
+
+// ParseNodeExtraMounts parses a slice of node extra mount specs in the format <HOST_PATH>=<NODE_PATH>[:ro]
+// and returns a slice of ExtraVolumeMount, which is read-only if the spec ends with :ro.
+// The host path must exist and be readable, it is made absolute as the cluster may not be created from the
+// current directory. The node path must be absolute. Returns an error if any spec is invalid.
+func ParseNodeExtraMounts(specs []string) ([]ExtraVolumeMount, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	mounts := make([]ExtraVolumeMount, len(specs))
+
+	for i, spec := range specs {
+		hostPath, nodePath, ok := strings.Cut(spec, "=")
+		if !ok || hostPath == "" || nodePath == "" {
+			return nil, errInvalidNodeExtraMountSpec(spec)
+		}
+		nodePath, readOnly := strings.CutSuffix(nodePath, ":ro")
+		// the node is always linux, regardless of the host
+		if strings.Contains(nodePath, ":") || !path.IsAbs(nodePath) {
+			return nil, errInvalidNodeExtraMountSpec(spec)
+		}
+
+		hostPath, err := filepath.Abs(hostPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to determine the absolute path of %s: %w", hostPath, err)
+		}
+		f, err := os.Open(hostPath)
+		if err != nil {
+			return nil, fmt.Errorf("host path %s of node extra mount %s must exist and be readable: %w", hostPath, spec, err)
+		}
+		_ = f.Close()
+
+		mounts[i] = ExtraVolumeMount{
+			HostPath:      hostPath,
+			ContainerPath: path.Clean(nodePath),
+			ReadOnly:      readOnly,
+		}
+	}
+
+	return mounts, nil
+}
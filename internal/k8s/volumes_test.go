@@ -1,6 +1,8 @@
 package k8s
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -53,3 +55,79 @@ func TestParseVolumeMounts(t *testing.T) {
 		})
 	}
 }
+
+func TestParseNodeExtraMounts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(file, []byte("id\n1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		name         string
+		input        []string
+		expectMounts []ExtraVolumeMount
+		expectErr    error
+	}{
+		{
+			name: "empty input",
+		},
+		{
+			name:         "directory",
+			input:        []string{dir + "=/connectors"},
+			expectMounts: []ExtraVolumeMount{{HostPath: dir, ContainerPath: "/connectors"}},
+		},
+		{
+			name:         "read-only file",
+			input:        []string{file + "=/data/data.csv:ro"},
+			expectMounts: []ExtraVolumeMount{{HostPath: file, ContainerPath: "/data/data.csv", ReadOnly: true}},
+		},
+		{
+			name:         "multiple mounts",
+			input:        []string{dir + "=/connectors/", file + "=/data.csv:ro"},
+			expectMounts: []ExtraVolumeMount{{HostPath: dir, ContainerPath: "/connectors"}, {HostPath: file, ContainerPath: "/data.csv", ReadOnly: true}},
+		},
+		{
+			name:      "invalid spec (missing equals)",
+			input:     []string{dir + ":/connectors"},
+			expectErr: errInvalidNodeExtraMountSpec(dir + ":/connectors"),
+		},
+		{
+			name:      "invalid spec (missing node path)",
+			input:     []string{dir + "="},
+			expectErr: errInvalidNodeExtraMountSpec(dir + "="),
+		},
+		{
+			name:      "invalid spec (relative node path)",
+			input:     []string{dir + "=connectors"},
+			expectErr: errInvalidNodeExtraMountSpec(dir + "=connectors"),
+		},
+		{
+			name:      "invalid spec (unknown option)",
+			input:     []string{dir + "=/connectors:rw"},
+			expectErr: errInvalidNodeExtraMountSpec(dir + "=/connectors:rw"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mounts, err := ParseNodeExtraMounts(tt.input)
+			assert.Equal(t, tt.expectMounts, mounts, "mounts should match")
+			if tt.expectErr != nil {
+				assert.EqualError(t, err, tt.expectErr.Error(), "errors should match")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("missing host path", func(t *testing.T) {
+		t.Parallel()
+		_, err := ParseNodeExtraMounts([]string{missing + "=/missing"})
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
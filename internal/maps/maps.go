@@ -3,6 +3,8 @@ package maps
 import (
 	"fmt"
 	"os"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -114,3 +116,78 @@ func Merge(base, override map[string]any) {
 		}
 	}
 }
+
+// ListKeys are the fields identifying an entry of a list merged by MergeDeep, in order of precedence.
+// E.g. the env vars, volumes and volume mounts of a container are identified by their name, tolerations by their key.
+var ListKeys = []string{"name", "key"}
+
+// MergeDeep merges the override map into the base map, modifying the base map in place.
+// Unlike Merge, which replaces a list of the base with the list of the override, the lists are merged too.
+// An entry of the override list which is a map identified by the same ListKeys field and value as an entry of the
+// base list is merged into that entry. Any other entry is appended, unless an equal entry already exists.
+func MergeDeep(base, override map[string]any) {
+	for k, overrideVal := range override {
+		baseVal, ok := base[k]
+		if !ok {
+			base[k] = overrideVal
+			continue
+		}
+
+		switch overrideChild := overrideVal.(type) {
+		case map[string]any:
+			if baseChild, ok := baseVal.(map[string]any); ok {
+				MergeDeep(baseChild, overrideChild)
+				continue
+			}
+		case []any:
+			if baseChild, ok := baseVal.([]any); ok {
+				base[k] = mergeLists(baseChild, overrideChild)
+				continue
+			}
+		}
+		base[k] = overrideVal
+	}
+}
+
+// mergeLists returns the entries of the override list merged into those of the base list, see MergeDeep.
+func mergeLists(base, override []any) []any {
+	merged := slices.Clone(base)
+	for _, entry := range override {
+		i := slices.IndexFunc(merged, func(e any) bool { return sameEntry(e, entry) })
+		if i < 0 {
+			merged = append(merged, entry)
+			continue
+		}
+
+		baseEntry, baseIsMap := merged[i].(map[string]any)
+		overrideEntry, overrideIsMap := entry.(map[string]any)
+		if baseIsMap && overrideIsMap {
+			MergeDeep(baseEntry, overrideEntry)
+		}
+	}
+	return merged
+}
+
+// sameEntry returns true if the list entries are maps identified by the same ListKeys field and value, or are equal.
+func sameEntry(a, b any) bool {
+	if field, value, ok := entryKey(b); ok {
+		if aField, aValue, ok := entryKey(a); ok {
+			return field == aField && reflect.DeepEqual(value, aValue)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// entryKey returns the first of the ListKeys fields of the list entry, and its value. False if the entry has none.
+func entryKey(entry any) (string, any, bool) {
+	m, ok := entry.(map[string]any)
+	if !ok {
+		return "", nil, false
+	}
+	for _, field := range ListKeys {
+		if v, ok := m[field]; ok {
+			return field, v, true
+		}
+	}
+	return "", nil, false
+}
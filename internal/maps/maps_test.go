@@ -319,3 +319,90 @@ func TestMerge(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeDeep(t *testing.T) {
+	tests := []struct {
+		name string
+		base map[string]any
+		over map[string]any
+		want map[string]any
+	}{
+		{
+			name: "maps merge as with Merge",
+			base: map[string]any{"a": "1", "b": map[string]any{"c": true}},
+			over: map[string]any{"b": map[string]any{"c": false, "d": "100"}},
+			want: map[string]any{"a": "1", "b": map[string]any{"c": false, "d": "100"}},
+		},
+		{
+			name: "entries with a new name are appended",
+			base: map[string]any{"env": []any{map[string]any{"name": "A", "value": "1"}}},
+			over: map[string]any{"env": []any{map[string]any{"name": "B", "value": "2"}}},
+			want: map[string]any{"env": []any{
+				map[string]any{"name": "A", "value": "1"},
+				map[string]any{"name": "B", "value": "2"},
+			}},
+		},
+		{
+			name: "entries with the same name are merged in place",
+			base: map[string]any{"volumes": []any{
+				map[string]any{"name": "data", "hostPath": map[string]any{"path": "/data", "type": "Directory"}},
+				map[string]any{"name": "tmp", "emptyDir": map[string]any{}},
+			}},
+			over: map[string]any{"volumes": []any{
+				map[string]any{"name": "data", "hostPath": map[string]any{"path": "/mnt/data"}},
+			}},
+			want: map[string]any{"volumes": []any{
+				map[string]any{"name": "data", "hostPath": map[string]any{"path": "/mnt/data", "type": "Directory"}},
+				map[string]any{"name": "tmp", "emptyDir": map[string]any{}},
+			}},
+		},
+		{
+			name: "entries are keyed by key without a name",
+			base: map[string]any{"tolerations": []any{map[string]any{"key": "spot", "effect": "NoSchedule"}}},
+			over: map[string]any{"tolerations": []any{
+				map[string]any{"key": "spot", "effect": "NoExecute"},
+				map[string]any{"key": "gpu", "operator": "Exists"},
+			}},
+			want: map[string]any{"tolerations": []any{
+				map[string]any{"key": "spot", "effect": "NoExecute"},
+				map[string]any{"key": "gpu", "operator": "Exists"},
+			}},
+		},
+		{
+			name: "equal entries are not duplicated",
+			base: map[string]any{"args": []any{"--verbose", map[string]any{"configMapRef": map[string]any{"name": "a"}}}},
+			over: map[string]any{"args": []any{"--verbose", "--debug", map[string]any{"configMapRef": map[string]any{"name": "a"}}}},
+			want: map[string]any{"args": []any{"--verbose", map[string]any{"configMapRef": map[string]any{"name": "a"}}, "--debug"}},
+		},
+		{
+			name: "nested lists are merged",
+			base: map[string]any{"server": map[string]any{"extraContainers": []any{
+				map[string]any{"name": "proxy", "env": []any{map[string]any{"name": "A", "value": "1"}}},
+			}}},
+			over: map[string]any{"server": map[string]any{"extraContainers": []any{
+				map[string]any{"name": "proxy", "env": []any{map[string]any{"name": "B", "value": "2"}}},
+			}}},
+			want: map[string]any{"server": map[string]any{"extraContainers": []any{
+				map[string]any{"name": "proxy", "env": []any{
+					map[string]any{"name": "A", "value": "1"},
+					map[string]any{"name": "B", "value": "2"},
+				}},
+			}}},
+		},
+		{
+			name: "a list replaces a value of another type",
+			base: map[string]any{"a": "1"},
+			over: map[string]any{"a": []any{"1"}},
+			want: map[string]any{"a": []any{"1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			MergeDeep(tt.base, tt.over)
+			if d := cmp.Diff(tt.want, tt.base); d != "" {
+				t.Error("mismatch (-want, +got) = ", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package output renders the results of the commands in the format selected with their --output-format flag.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/airbytehq/abctl/internal/helm"
+)
+
+// Format is the format a result is rendered in.
+type Format string
+
+const (
+	// Table is the human readable format, the default.
+	Table Format = "table"
+	YAML  Format = "yaml"
+	JSON  Format = "json"
+)
+
+// MachineReadable returns true if the format is meant for consumption by another program.
+func (f Format) MachineReadable() bool {
+	return f == YAML || f == JSON
+}
+
+// Tabler is implemented by the results which can be rendered in the Table format.
+type Tabler interface {
+	WriteTable(w io.Writer) error
+}
+
+// secretTag is the struct tag marking a field as secret, e.g. `output:"secret"`.
+const secretTag = "secret"
+
+// Render writes the result v to w in the format, with its secret fields redacted.
+// A result is only rendered as a Table if it implements Tabler. Its yaml keys are the same as its json keys.
+func Render(w io.Writer, format Format, v any) error {
+	v = Redact(v)
+
+	switch format {
+	case Table, "":
+		t, ok := v.(Tabler)
+		if !ok {
+			return fmt.Errorf("unable to render %T as a table", v)
+		}
+		return t.WriteTable(w)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("unable to render json: %w", err)
+		}
+		return nil
+	case YAML:
+		raw, err := toYAML(v)
+		if err != nil {
+			return fmt.Errorf("unable to render yaml: %w", err)
+		}
+		_, err = w.Write(raw)
+		return err
+	default:
+		return fmt.Errorf("invalid output format '%s': must be one of table, yaml or json", format)
+	}
+}
+
+// toYAML returns v as yaml, keeping the keys and their order of its json.
+func toYAML(v any) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// json is yaml, only its flow style is reset to the block style
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// Redact returns a copy of v with the value of every field tagged `output:"secret"` replaced by the
+// helm.RedactedValue, or by its zero value if it isn't a string. Empty secret fields are kept empty.
+// The secret fields of nested structs, pointers, slices and maps are redacted as well, v itself is never modified.
+func Redact(v any) any {
+	if v == nil {
+		return nil
+	}
+	return redact(reflect.ValueOf(v)).Interface()
+}
+
+func redact(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			switch {
+			case field.Tag.Get("output") != secretTag:
+				c.Field(i).Set(redact(v.Field(i)))
+			case v.Field(i).IsZero():
+			case field.Type.Kind() == reflect.String:
+				c.Field(i).SetString(helm.RedactedValue)
+			default:
+				c.Field(i).SetZero()
+			}
+		}
+		return c
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(redact(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			c.Index(i).Set(redact(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), redact(iter.Value()))
+		}
+		return c
+	default:
+		return v
+	}
+}
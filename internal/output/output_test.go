@@ -0,0 +1,155 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testCredential struct {
+	Name  string `json:"name"`
+	Token string `json:"token" output:"secret"`
+	Port  int    `json:"port,omitempty" output:"secret"`
+}
+
+type testResult struct {
+	URL         string           `json:"url"`
+	Ready       bool             `json:"ready"`
+	Password    string           `json:"password" output:"secret"`
+	Credentials []testCredential `json:"credentials"`
+}
+
+func (r testResult) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "URL:       %s\nReady:     %t\nPassword:  %s\n", r.URL, r.Ready, r.Password)
+	for _, c := range r.Credentials {
+		fmt.Fprintf(w, "  - %s=%s\n", c.Name, c.Token)
+	}
+	return nil
+}
+
+func TestRender(t *testing.T) {
+	result := testResult{
+		URL:      "http://localhost:8000",
+		Ready:    true,
+		Password: "hunter2",
+		Credentials: []testCredential{
+			{Name: "api", Token: "s3cr3t", Port: 443},
+			{Name: "empty"},
+		},
+	}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			format: Table,
+			want: `URL:       http://localhost:8000
+Ready:     true
+Password:  [REDACTED]
+  - api=[REDACTED]
+  - empty=
+`,
+		},
+		{
+			format: YAML,
+			want: `url: http://localhost:8000
+ready: true
+password: '[REDACTED]'
+credentials:
+  - name: api
+    token: '[REDACTED]'
+  - name: empty
+    token: ""
+`,
+		},
+		{
+			format: JSON,
+			want: `{
+  "url": "http://localhost:8000",
+  "ready": true,
+  "password": "[REDACTED]",
+  "credentials": [
+    {
+      "name": "api",
+      "token": "[REDACTED]"
+    },
+    {
+      "name": "empty",
+      "token": ""
+    }
+  ]
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var b strings.Builder
+			if err := Render(&b, tt.format, result); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if d := cmp.Diff(tt.want, b.String()); d != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+
+	// the result itself is never redacted
+	if d := cmp.Diff("hunter2", result.Password); d != "" {
+		t.Errorf("password mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff("s3cr3t", result.Credentials[0].Token); d != "" {
+		t.Errorf("token mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		v       any
+		wantErr string
+	}{
+		{
+			name:    "no table",
+			format:  Table,
+			v:       testCredential{Name: "api"},
+			wantErr: "unable to render output.testCredential as a table",
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			v:       testResult{},
+			wantErr: "invalid output format 'xml': must be one of table, yaml or json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Render(io.Discard, tt.format, tt.v)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if d := cmp.Diff(tt.wantErr, err.Error()); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestRedact_Pointers(t *testing.T) {
+	result := &testResult{Password: "hunter2"}
+	got := Redact(map[string]*testResult{"a": result}).(map[string]*testResult)
+
+	if d := cmp.Diff("[REDACTED]", got["a"].Password); d != "" {
+		t.Errorf("password mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff("hunter2", result.Password); d != "" {
+		t.Errorf("password mismatch (-want +got):\n%s", d)
+	}
+}
@@ -7,6 +7,12 @@ import (
 
 const (
 	FileKubeconfig = "abctl.kubeconfig"
+	// FileImageManifest is the manifest of the docker images pulled by abctl.
+	FileImageManifest = "images.json"
+	// FileImageCache caches the images resolved from the airbyte chart.
+	FileImageCache = "image-cache.json"
+	// FileJournal is the journal of the phases of the install and uninstall operations.
+	FileJournal = "journal.jsonl"
 
 	// PvMinio is the persistent volume directory for Minio storage.
 	PvMinio = "airbyte-minio-pv"
@@ -16,6 +22,17 @@ const (
 	PvPsql = "airbyte-volume-db"
 )
 
+const (
+	// EnvConfigDir relocates every file and directory of abctl, which otherwise default to the ~/.airbyte/abctl directory.
+	EnvConfigDir = "ABCTL_CONFIG_DIR"
+	// EnvXDGConfigHome, EnvXDGDataHome and EnvXDGCacheHome are the XDG base directories. If set, and the
+	// ~/.airbyte/abctl directory doesn't exist yet, the config, data and cache of abctl are stored in an abctl
+	// directory within them.
+	EnvXDGConfigHome = "XDG_CONFIG_HOME"
+	EnvXDGDataHome   = "XDG_DATA_HOME"
+	EnvXDGCacheHome  = "XDG_CACHE_HOME"
+)
+
 var (
 	// UserHome is the user's home directory
 	UserHome = func() string {
@@ -26,40 +43,105 @@ var (
 	// Airbyte is the full path to the ~/.airbyte directory
 	Airbyte = airbyte()
 
-	// AbCtl is the full path to the ~/.airbyte/abctl directory
-	AbCtl = abctl()
+	// dirs are the root directories every other path is derived from
+	dirs = resolveDirs(UserHome, os.Getenv, exists)
+
+	// AbCtl is the full path to the ~/.airbyte/abctl directory, or its relocated equivalent
+	AbCtl = dirs.Config
 
 	// Data is the full path to the ~/.airbyte/abctl/data directory
-	Data = data()
+	Data = data(dirs)
+
+	// Layers is the full path to the ~/.airbyte/abctl/layers directory
+	Layers = layers(dirs)
 
 	// Kubeconfig is the full path to the kubeconfig file
-	Kubeconfig = kubeconfig()
+	Kubeconfig = kubeconfig(dirs)
+
+	// ImageManifest is the full path to the image manifest file
+	ImageManifest = imageManifest(dirs)
+	// ImageCache is the full path to the chart image cache file
+	ImageCache = imageCache(dirs)
+	// Journal is the full path to the operations journal file
+	Journal = journal(dirs)
 
 	// HelmRepoConfig is the full path to where helm stores
 	// its repository configurations.
-	HelmRepoConfig = helmRepoConfig()
+	HelmRepoConfig = helmRepoConfig(dirs)
 
 	// HelmRepoCache is the full path to where helm stores
 	// its cached data.
-	HelmRepoCache = helmRepoCache()
+	HelmRepoCache = helmRepoCache(dirs)
 )
 
+// Dirs are the root directories of the config, data and cache of abctl.
+// By default, they are all the ~/.airbyte/abctl directory.
+type Dirs struct {
+	Config string
+	Data   string
+	Cache  string
+}
+
+// resolveDirs returns the root directories, relocated by the environment variables returned by getenv.
+// The EnvConfigDir takes precedence, relocating all of them to a single directory. Otherwise, the XDG base directories
+// are only honored if the default ~/.airbyte/abctl directory doesn't exist, so an existing installation isn't
+// split across, or orphaned by, a newly set XDG variable.
+func resolveDirs(home string, getenv func(string) string, exists func(string) bool) Dirs {
+	if dir := getenv(EnvConfigDir); dir != "" {
+		return Dirs{Config: dir, Data: dir, Cache: dir}
+	}
+
+	root := filepath.Join(home, ".airbyte", "abctl")
+	dirs := Dirs{Config: root, Data: root, Cache: root}
+	if exists(root) {
+		return dirs
+	}
+
+	if dir := getenv(EnvXDGConfigHome); dir != "" {
+		dirs.Config = filepath.Join(dir, "abctl")
+	}
+	if dir := getenv(EnvXDGDataHome); dir != "" {
+		dirs.Data = filepath.Join(dir, "abctl")
+	}
+	if dir := getenv(EnvXDGCacheHome); dir != "" {
+		dirs.Cache = filepath.Join(dir, "abctl")
+	}
+	return dirs
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 func airbyte() string {
 	return filepath.Join(UserHome, ".airbyte")
 }
 
-func abctl() string {
-	return filepath.Join(airbyte(), "abctl")
+func data(d Dirs) string {
+	return filepath.Join(d.Data, "data")
+}
+
+func layers(d Dirs) string {
+	return filepath.Join(d.Config, "layers")
+}
+
+func kubeconfig(d Dirs) string {
+	return filepath.Join(d.Config, FileKubeconfig)
+}
+
+func imageManifest(d Dirs) string {
+	return filepath.Join(d.Data, FileImageManifest)
 }
 
-func data() string {
-	return filepath.Join(abctl(), "data")
+func imageCache(d Dirs) string {
+	return filepath.Join(d.Cache, FileImageCache)
 }
 
-func kubeconfig() string {
-	return filepath.Join(abctl(), FileKubeconfig)
+func journal(d Dirs) string {
+	return filepath.Join(d.Data, FileJournal)
 }
 
-func helmRepoConfig() string { return filepath.Join(abctl(), ".helmrepo") }
+func helmRepoConfig(d Dirs) string { return filepath.Join(d.Config, ".helmrepo") }
 
-func helmRepoCache() string { return filepath.Join(abctl(), ".helmcache") }
+func helmRepoCache(d Dirs) string { return filepath.Join(d.Cache, ".helmcache") }
@@ -29,38 +29,160 @@ func Test_Paths(t *testing.T) {
 		}
 	})
 
+	// the remaining paths are derived from the default directories, their relocation is covered by TestResolveDirs
+	defaults := resolveDirs(UserHome, func(string) string { return "" }, func(string) bool { return false })
+
 	t.Run("AbCtl", func(t *testing.T) {
 		exp := filepath.Join(UserHome, ".airbyte", "abctl")
-		if d := cmp.Diff(exp, AbCtl); d != "" {
+		if d := cmp.Diff(exp, defaults.Config); d != "" {
 			t.Errorf("AbCtl mismatch (-want +got):\n%s", d)
 		}
 	})
 
 	t.Run("Data", func(t *testing.T) {
 		exp := filepath.Join(UserHome, ".airbyte", "abctl", "data")
-		if d := cmp.Diff(exp, Data); d != "" {
+		if d := cmp.Diff(exp, data(defaults)); d != "" {
 			t.Errorf("Data mismatch (-want +got):\n%s", d)
 		}
 	})
 
+	t.Run("ImageManifest", func(t *testing.T) {
+		exp := filepath.Join(UserHome, ".airbyte", "abctl", "images.json")
+		if d := cmp.Diff(exp, imageManifest(defaults)); d != "" {
+			t.Errorf("ImageManifest mismatch (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("ImageCache", func(t *testing.T) {
+		exp := filepath.Join(UserHome, ".airbyte", "abctl", "image-cache.json")
+		if d := cmp.Diff(exp, imageCache(defaults)); d != "" {
+			t.Errorf("ImageCache mismatch (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("Journal", func(t *testing.T) {
+		exp := filepath.Join(UserHome, ".airbyte", "abctl", "journal.jsonl")
+		if d := cmp.Diff(exp, journal(defaults)); d != "" {
+			t.Errorf("Journal mismatch (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("Layers", func(t *testing.T) {
+		exp := filepath.Join(UserHome, ".airbyte", "abctl", "layers")
+		if d := cmp.Diff(exp, layers(defaults)); d != "" {
+			t.Errorf("Layers mismatch (-want +got):\n%s", d)
+		}
+	})
+
 	t.Run("Kubeconfig", func(t *testing.T) {
 		exp := filepath.Join(UserHome, ".airbyte", "abctl", "abctl.kubeconfig")
-		if d := cmp.Diff(exp, Kubeconfig); d != "" {
+		if d := cmp.Diff(exp, kubeconfig(defaults)); d != "" {
 			t.Errorf("Kubeconfig mismatch (-want +got):\n%s", d)
 		}
 	})
 
 	t.Run("HelmRepoConfig", func(t *testing.T) {
 		exp := filepath.Join(UserHome, ".airbyte", "abctl", ".helmrepo")
-		if d := cmp.Diff(exp, HelmRepoConfig); d != "" {
+		if d := cmp.Diff(exp, helmRepoConfig(defaults)); d != "" {
 			t.Errorf("HelmRepoConfig mismatch (-want +got):\n%s", d)
 		}
 	})
 
 	t.Run("HelmRepoCache", func(t *testing.T) {
 		exp := filepath.Join(UserHome, ".airbyte", "abctl", ".helmcache")
-		if d := cmp.Diff(exp, HelmRepoCache); d != "" {
+		if d := cmp.Diff(exp, helmRepoCache(defaults)); d != "" {
 			t.Errorf("HelmRepoCache mismatch (-want +got):\n%s", d)
 		}
 	})
 }
+
+func TestResolveDirs(t *testing.T) {
+	home := filepath.Join("home", "user")
+	root := filepath.Join(home, ".airbyte", "abctl")
+
+	tests := []struct {
+		name   string
+		env    map[string]string
+		exists bool
+		want   Dirs
+	}{
+		{
+			name: "defaults",
+			want: Dirs{Config: root, Data: root, Cache: root},
+		},
+		{
+			name: "config dir",
+			env:  map[string]string{EnvConfigDir: "/opt/abctl", EnvXDGConfigHome: "/xdg/config"},
+			want: Dirs{Config: "/opt/abctl", Data: "/opt/abctl", Cache: "/opt/abctl"},
+		},
+		{
+			name:   "config dir with existing default",
+			env:    map[string]string{EnvConfigDir: "/opt/abctl"},
+			exists: true,
+			want:   Dirs{Config: "/opt/abctl", Data: "/opt/abctl", Cache: "/opt/abctl"},
+		},
+		{
+			name: "xdg",
+			env:  map[string]string{EnvXDGConfigHome: "/xdg/config", EnvXDGDataHome: "/xdg/data", EnvXDGCacheHome: "/xdg/cache"},
+			want: Dirs{
+				Config: filepath.Join("/xdg/config", "abctl"),
+				Data:   filepath.Join("/xdg/data", "abctl"),
+				Cache:  filepath.Join("/xdg/cache", "abctl"),
+			},
+		},
+		{
+			name: "xdg partial",
+			env:  map[string]string{EnvXDGDataHome: "/xdg/data"},
+			want: Dirs{Config: root, Data: filepath.Join("/xdg/data", "abctl"), Cache: root},
+		},
+		{
+			name:   "xdg with existing default",
+			env:    map[string]string{EnvXDGConfigHome: "/xdg/config", EnvXDGDataHome: "/xdg/data"},
+			exists: true,
+			want:   Dirs{Config: root, Data: root, Cache: root},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			exists := func(path string) bool {
+				if path != root {
+					t.Errorf("unexpected path checked %s", path)
+				}
+				return tt.exists
+			}
+
+			if d := cmp.Diff(tt.want, resolveDirs(home, getenv, exists)); d != "" {
+				t.Errorf("dirs mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDerivedPaths(t *testing.T) {
+	d := Dirs{Config: "/config", Data: "/data", Cache: "/cache"}
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "Data", got: data(d), want: filepath.Join("/data", "data")},
+		{name: "Layers", got: layers(d), want: filepath.Join("/config", "layers")},
+		{name: "Kubeconfig", got: kubeconfig(d), want: filepath.Join("/config", "abctl.kubeconfig")},
+		{name: "ImageManifest", got: imageManifest(d), want: filepath.Join("/data", "images.json")},
+		{name: "ImageCache", got: imageCache(d), want: filepath.Join("/cache", "image-cache.json")},
+		{name: "Journal", got: journal(d), want: filepath.Join("/data", "journal.jsonl")},
+		{name: "HelmRepoConfig", got: helmRepoConfig(d), want: filepath.Join("/config", ".helmrepo")},
+		{name: "HelmRepoCache", got: helmRepoCache(d), want: filepath.Join("/cache", ".helmcache")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, tt.got); d != "" {
+				t.Errorf("%s mismatch (-want +got):\n%s", tt.name, d)
+			}
+		})
+	}
+}
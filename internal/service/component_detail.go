@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/airbyte"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+)
+
+// maxComponentEvents limits how many of the most recent events of each pod are part of its detail.
+const maxComponentEvents = 10
+
+// ComponentDetail is the full detail of a single component, and each of its pods.
+type ComponentDetail struct {
+	Status ComponentStatus
+	// Pods are sorted by their name.
+	Pods []PodDetail
+}
+
+// PodDetail is the detail of a single pod of a component.
+type PodDetail struct {
+	Name  string
+	Phase corev1.PodPhase
+	Ready bool
+	// Containers are the init containers, followed by the containers, of the pod.
+	Containers []ContainerDetail
+	// Events are the most recent events of the pod, oldest first.
+	Events []string
+	// Logs are the most recent log lines of the pod, oldest first.
+	Logs []LogLine
+}
+
+// ContainerDetail is the state of a single container of a pod.
+type ContainerDetail struct {
+	Name     string
+	Init     bool
+	Ready    bool
+	Restarts int32
+	// State is the current state of the container, e.g. "running", "waiting: CrashLoopBackOff" or "terminated: Error (exit code 1)".
+	State string
+	// LastTermination is why the container last terminated, e.g. "OOMKilled (exit code 137)", empty if it never restarted.
+	LastTermination string
+}
+
+// LogLine is a single log line of a pod.
+type LogLine struct {
+	Time    time.Time
+	Level   string
+	Message string
+}
+
+// ComponentDetails returns the detail of the component, as named by ComponentStatuses (e.g. worker), including the
+// logLines most recent log lines of each of its pods. An ErrComponentNotFound error is returned if the component has
+// no pods. The events and logs are best effort, failures to retrieve them are only logged at debug.
+func ComponentDetails(ctx context.Context, client k8s.Client, namespace, component string, logLines int) (ComponentDetail, error) {
+	pods, err := client.PodList(ctx, namespace)
+	if err != nil {
+		return ComponentDetail{}, fmt.Errorf("unable to list pods in namespace '%s': %w", namespace, err)
+	}
+
+	var matched []corev1.Pod
+	var components []string
+	for _, status := range ComponentStatuses(pods.Items) {
+		components = append(components, status.Component)
+	}
+	for _, pod := range pods.Items {
+		if PodComponent(pod) == component {
+			matched = append(matched, pod)
+		}
+	}
+	if len(matched) == 0 {
+		return ComponentDetail{}, fmt.Errorf("%w: '%s', the components are: %s", abctl.ErrComponentNotFound, component, strings.Join(components, ", "))
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Name < matched[j].Name })
+
+	var events []eventsv1.Event
+	if list, err := client.EventsList(ctx, namespace); err != nil {
+		pterm.Debug.Printfln("Unable to list events in namespace '%s': %s", namespace, err)
+	} else {
+		events = list.Items
+	}
+
+	detail := ComponentDetail{Status: ComponentStatuses(matched)[0]}
+	for _, pod := range matched {
+		detail.Pods = append(detail.Pods, PodDetail{
+			Name:       pod.Name,
+			Phase:      pod.Status.Phase,
+			Ready:      podReady(pod),
+			Containers: containerDetails(pod),
+			Events:     podEvents(events, pod.Name),
+			Logs:       podLogs(ctx, client, namespace, pod.Name, logLines),
+		})
+	}
+	return detail, nil
+}
+
+// containerDetails returns the state of the init containers, followed by the containers, of the pod.
+func containerDetails(pod corev1.Pod) []ContainerDetail {
+	var details []ContainerDetail
+	for _, statuses := range []struct {
+		init     bool
+		statuses []corev1.ContainerStatus
+	}{{true, pod.Status.InitContainerStatuses}, {false, pod.Status.ContainerStatuses}} {
+		for _, c := range statuses.statuses {
+			d := ContainerDetail{
+				Name:     c.Name,
+				Init:     statuses.init,
+				Ready:    c.Ready,
+				Restarts: c.RestartCount,
+				State:    containerState(c.State),
+			}
+			if t := c.LastTerminationState.Terminated; t != nil {
+				d.LastTermination = terminationReason(t)
+			}
+			details = append(details, d)
+		}
+	}
+	return details
+}
+
+// containerState returns the state of the container, along with its reason if it isn't running.
+func containerState(state corev1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return "running"
+	case state.Waiting != nil:
+		return strings.TrimSuffix("waiting: "+state.Waiting.Reason, ": ")
+	case state.Terminated != nil:
+		return "terminated: " + terminationReason(state.Terminated)
+	default:
+		return "unknown"
+	}
+}
+
+// terminationReason returns why the container terminated, e.g. "OOMKilled (exit code 137)".
+func terminationReason(t *corev1.ContainerStateTerminated) string {
+	reason := t.Reason
+	if reason == "" {
+		reason = "Terminated"
+	}
+	reason = fmt.Sprintf("%s (exit code %d)", reason, t.ExitCode)
+	if t.Message != "" {
+		reason += ": " + strings.TrimSpace(t.Message)
+	}
+	return reason
+}
+
+// podEvents returns the maxComponentEvents most recent events regarding the pod, oldest first.
+func podEvents(events []eventsv1.Event, pod string) []string {
+	var regarding []eventsv1.Event
+	for _, e := range events {
+		if e.Regarding.Kind == "Pod" && e.Regarding.Name == pod {
+			regarding = append(regarding, e)
+		}
+	}
+	sort.SliceStable(regarding, func(i, j int) bool {
+		return eventTime(regarding[i]).Before(eventTime(regarding[j]))
+	})
+	if len(regarding) > maxComponentEvents {
+		regarding = regarding[len(regarding)-maxComponentEvents:]
+	}
+
+	lines := make([]string, 0, len(regarding))
+	for _, e := range regarding {
+		line := fmt.Sprintf("%s %s: %s", e.Type, e.Reason, e.Note)
+		if e.DeprecatedCount > 1 {
+			line += fmt.Sprintf(" (x%d)", e.DeprecatedCount)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// eventTime returns when the event last occurred.
+func eventTime(e eventsv1.Event) time.Time {
+	switch {
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return e.Series.LastObservedTime.Time
+	case !e.DeprecatedLastTimestamp.IsZero():
+		return e.DeprecatedLastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// podLogs returns the n most recent log lines of the pod, oldest first.
+func podLogs(ctx context.Context, client k8s.Client, namespace, pod string, n int) []LogLine {
+	if n <= 0 {
+		return nil
+	}
+
+	logs, err := client.LogsGet(ctx, namespace, pod)
+	if err != nil {
+		pterm.Debug.Printfln("Unable to get the logs of pod '%s': %s", pod, err)
+		return nil
+	}
+
+	var lines []LogLine
+	s := airbyte.NewLogScanner(strings.NewReader(logs))
+	for s.Scan() {
+		lines = append(lines, LogLine{Time: s.Line.Time, Level: s.Line.Level, Message: s.Line.Message})
+		if len(lines) > n {
+			lines = lines[1:]
+		}
+	}
+	if err := s.Err(); err != nil {
+		pterm.Debug.Printfln("Unable to read the logs of pod '%s': %s", pod, err)
+	}
+	return lines
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// podEvent returns an event regarding the pod, which last occurred at.
+func podEvent(name, pod, reason, note string, at time.Time) *eventsv1.Event {
+	return &eventsv1.Event{
+		ObjectMeta:              metav1.ObjectMeta{Name: name, Namespace: common.AirbyteNamespace},
+		Regarding:               corev1.ObjectReference{Kind: "Pod", Name: pod},
+		Type:                    corev1.EventTypeWarning,
+		Reason:                  reason,
+		Note:                    note,
+		DeprecatedLastTimestamp: metav1.NewTime(at),
+	}
+}
+
+func TestComponentDetails(t *testing.T) {
+	now := time.Now()
+
+	crashing := labeledPod("airbyte-abctl-worker-123-abc", "ReplicaSet", "airbyte-abctl-worker-123", nil, false)
+	crashing.Status.Phase = corev1.PodRunning
+	crashing.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "wait-for-db",
+		Ready: true,
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+	}}
+	crashing.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:                 "worker",
+		RestartCount:         3,
+		State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+	}}
+
+	running := labeledPod("airbyte-abctl-worker-123-def", "ReplicaSet", "airbyte-abctl-worker-123", nil, true)
+	running.Status.Phase = corev1.PodRunning
+	running.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "worker",
+		Ready: true,
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}}
+
+	cs := fake.NewSimpleClientset(
+		running,
+		crashing,
+		labeledPod("airbyte-abctl-server-456-abc", "ReplicaSet", "airbyte-abctl-server-456", nil, true),
+		podEvent("e2", crashing.Name, "BackOff", "Back-off restarting failed container", now),
+		podEvent("e1", crashing.Name, "Pulled", "Container image pulled", now.Add(-time.Minute)),
+		podEvent("e3", "airbyte-abctl-server-456-abc", "Unhealthy", "Readiness probe failed", now),
+	)
+
+	detail, err := ComponentDetails(context.Background(), &k8s.DefaultK8sClient{ClientSet: cs}, common.AirbyteNamespace, "worker", 5)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// the fake clientset returns "fake logs" as the logs of every pod
+	logs := []LogLine{{Message: "fake logs"}}
+	want := ComponentDetail{
+		Status: ComponentStatus{Component: "worker", Ready: 1, Pods: 2, Restarts: 3, LastState: "CrashLoopBackOff"},
+		Pods: []PodDetail{
+			{
+				Name:  "airbyte-abctl-worker-123-abc",
+				Phase: corev1.PodRunning,
+				Containers: []ContainerDetail{
+					{Name: "wait-for-db", Init: true, Ready: true, State: "terminated: Completed (exit code 0)"},
+					{Name: "worker", Restarts: 3, State: "waiting: CrashLoopBackOff", LastTermination: "OOMKilled (exit code 137)"},
+				},
+				Events: []string{
+					"Warning Pulled: Container image pulled",
+					"Warning BackOff: Back-off restarting failed container",
+				},
+				Logs: logs,
+			},
+			{
+				Name:       "airbyte-abctl-worker-123-def",
+				Phase:      corev1.PodRunning,
+				Ready:      true,
+				Containers: []ContainerDetail{{Name: "worker", Ready: true, State: "running"}},
+				Events:     []string{},
+				Logs:       logs,
+			},
+		},
+	}
+	if d := cmp.Diff(want, detail); d != "" {
+		t.Errorf("detail mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestComponentDetails_NotFound(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		labeledPod("airbyte-abctl-server-456-abc", "ReplicaSet", "airbyte-abctl-server-456", nil, true),
+		labeledPod("airbyte-db-0", "StatefulSet", "airbyte-db", nil, true),
+	)
+
+	_, err := ComponentDetails(context.Background(), &k8s.DefaultK8sClient{ClientSet: cs}, common.AirbyteNamespace, "wroker", 5)
+	if !errors.Is(err, abctl.ErrComponentNotFound) {
+		t.Fatalf("expected ErrComponentNotFound but got %v", err)
+	}
+	if d := cmp.Diff("component not found: 'wroker', the components are: db, server", err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestPodLogs(t *testing.T) {
+	logs := "first\n" +
+		`{"timestamp":1734723317023,"message":"Waiting for database","level":"WARN"}` + "\n" +
+		"third\n"
+
+	tests := []struct {
+		n    int
+		want []LogLine
+	}{
+		{n: 0},
+		{n: 1, want: []LogLine{{Message: "third"}}},
+		{
+			n: 2,
+			want: []LogLine{
+				{Time: time.UnixMilli(1734723317023), Level: "WARN", Message: "Waiting for database"},
+				{Message: "third"},
+			},
+		},
+		{
+			n: 10,
+			want: []LogLine{
+				{Message: "first"},
+				{Time: time.UnixMilli(1734723317023), Level: "WARN", Message: "Waiting for database"},
+				{Message: "third"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.n), func(t *testing.T) {
+			client := &k8stest.MockClient{
+				FnLogsGet: func(ctx context.Context, namespace string, name string) (string, error) {
+					return logs, nil
+				},
+			}
+			if d := cmp.Diff(tt.want, podLogs(context.Background(), client, common.AirbyteNamespace, "pod", tt.n)); d != "" {
+				t.Errorf("logs mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
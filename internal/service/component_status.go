@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ComponentStatus is the aggregated status of the pods of a single component.
+type ComponentStatus struct {
+	Component string
+	// Ready is the number of ready pods.
+	Ready int
+	// Pods is the total number of pods.
+	Pods int
+	// Restarts is the sum of the container restarts of every pod.
+	Restarts int32
+	// LastState is the reason a container of the component is waiting (e.g. CrashLoopBackOff), or otherwise the
+	// reason a container last terminated (e.g. OOMKilled). Empty if no container is waiting or has terminated.
+	LastState string
+}
+
+// Kind returns the kind of the component, which determines its default readiness timeout.
+func (c ComponentStatus) Kind() ComponentKind {
+	return componentKind(c.Component)
+}
+
+// IsReady returns true if the component has pods and all of them are ready.
+func (c ComponentStatus) IsReady() bool {
+	return c.Pods > 0 && c.Ready == c.Pods
+}
+
+// ComponentStatuses returns the status of every component of the pods, sorted by component name.
+// Pods which do not belong to a component (e.g. job or hook pods) are ignored.
+func ComponentStatuses(pods []corev1.Pod) []ComponentStatus {
+	byComponent := map[string]*ComponentStatus{}
+	waiting := map[string]bool{}
+	for _, pod := range pods {
+		component := PodComponent(pod)
+		if component == "" {
+			continue
+		}
+
+		status, ok := byComponent[component]
+		if !ok {
+			status = &ComponentStatus{Component: component}
+			byComponent[component] = status
+		}
+
+		status.Pods++
+		if podReady(pod) {
+			status.Ready++
+		}
+		for _, c := range pod.Status.ContainerStatuses {
+			status.Restarts += c.RestartCount
+		}
+		// a waiting container is the current state of the component, taking precedence over any past termination
+		if state, isWaiting := podLastState(pod); state != "" && !waiting[component] && (isWaiting || status.LastState == "") {
+			status.LastState = state
+			waiting[component] = isWaiting
+		}
+	}
+
+	statuses := make([]ComponentStatus, 0, len(byComponent))
+	for _, status := range byComponent {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Component < statuses[j].Component
+	})
+
+	return statuses
+}
+
+// podLastState returns the reason the first waiting container of the pod is waiting, true if one is. Otherwise
+// it returns the reason the first terminated container of the pod last terminated, false.
+func podLastState(pod corev1.Pod) (string, bool) {
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.State.Waiting != nil && c.State.Waiting.Reason != "" {
+			return c.State.Waiting.Reason, true
+		}
+	}
+	for _, c := range pod.Status.ContainerStatuses {
+		if c.LastTerminationState.Terminated != nil && c.LastTerminationState.Terminated.Reason != "" {
+			return c.LastTerminationState.Terminated.Reason, false
+		}
+	}
+	return "", false
+}
+
+// ComponentChangeKind is the kind of change of a component between two polls.
+type ComponentChangeKind string
+
+const (
+	ComponentAdded     ComponentChangeKind = "added"
+	ComponentRemoved   ComponentChangeKind = "removed"
+	ComponentReady     ComponentChangeKind = "ready"
+	ComponentNotReady  ComponentChangeKind = "not ready"
+	ComponentRestarted ComponentChangeKind = "restarted"
+)
+
+// ComponentChange is a change of a component's status between two polls.
+type ComponentChange struct {
+	Component string
+	Kind      ComponentChangeKind
+	// Restarts is the number of new restarts, only set for ComponentRestarted changes.
+	Restarts int32
+}
+
+func (c ComponentChange) String() string {
+	if c.Kind == ComponentRestarted {
+		return fmt.Sprintf("%s restarted (+%d)", c.Component, c.Restarts)
+	}
+	return fmt.Sprintf("%s %s", c.Component, c.Kind)
+}
+
+// DiffComponentStatuses returns the changes between the previous and current statuses, sorted by component name.
+// A component which became ready and restarted in the same poll reports both changes.
+func DiffComponentStatuses(prev, cur []ComponentStatus) []ComponentChange {
+	prevByComponent := map[string]ComponentStatus{}
+	for _, status := range prev {
+		prevByComponent[status.Component] = status
+	}
+
+	var changes []ComponentChange
+	for _, status := range cur {
+		before, ok := prevByComponent[status.Component]
+		delete(prevByComponent, status.Component)
+
+		if !ok {
+			changes = append(changes, ComponentChange{Component: status.Component, Kind: ComponentAdded})
+			continue
+		}
+
+		switch {
+		case status.IsReady() && !before.IsReady():
+			changes = append(changes, ComponentChange{Component: status.Component, Kind: ComponentReady})
+		case !status.IsReady() && before.IsReady():
+			changes = append(changes, ComponentChange{Component: status.Component, Kind: ComponentNotReady})
+		}
+		// restarts decrease when a restarted pod is replaced, which is not a restart
+		if status.Restarts > before.Restarts {
+			changes = append(changes, ComponentChange{
+				Component: status.Component,
+				Kind:      ComponentRestarted,
+				Restarts:  status.Restarts - before.Restarts,
+			})
+		}
+	}
+
+	for component := range prevByComponent {
+		changes = append(changes, ComponentChange{Component: component, Kind: ComponentRemoved})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Component < changes[j].Component
+	})
+
+	return changes
+}
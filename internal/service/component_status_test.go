@@ -0,0 +1,156 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestComponentStatuses(t *testing.T) {
+	restarted := testPod("airbyte-abctl-worker-7c9f8d6b5-b2c3d", "ReplicaSet", "airbyte-abctl-worker-7c9f8d6b5", false)
+	restarted.Status.ContainerStatuses = []corev1.ContainerStatus{{RestartCount: 2}, {RestartCount: 1}}
+
+	pods := []corev1.Pod{
+		testPod("airbyte-abctl-server-5d8f7b9c4-x2x7z", "ReplicaSet", "airbyte-abctl-server-5d8f7b9c4", true),
+		testPod("airbyte-abctl-worker-7c9f8d6b5-a1b2c", "ReplicaSet", "airbyte-abctl-worker-7c9f8d6b5", true),
+		restarted,
+		testPod("airbyte-db-0", "StatefulSet", "airbyte-db", true),
+		testPod("airbyte-abctl-airbyte-bootloader", "Job", "airbyte-abctl-airbyte-bootloader", false),
+	}
+
+	want := []ComponentStatus{
+		{Component: "db", Ready: 1, Pods: 1},
+		{Component: "server", Ready: 1, Pods: 1},
+		{Component: "worker", Ready: 1, Pods: 2, Restarts: 3},
+	}
+
+	if d := cmp.Diff(want, ComponentStatuses(pods)); d != "" {
+		t.Errorf("statuses mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestComponentStatuses_LastState(t *testing.T) {
+	crashing := testPod("airbyte-abctl-server-5d8f7b9c4-x2x7z", "ReplicaSet", "airbyte-abctl-server-5d8f7b9c4", false)
+	crashing.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error"}},
+		RestartCount:         4,
+	}}
+	oomKilled := testPod("airbyte-abctl-server-5d8f7b9c4-a1b2c", "ReplicaSet", "airbyte-abctl-server-5d8f7b9c4", true)
+	oomKilled.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}},
+		RestartCount:         1,
+	}}
+
+	tests := []struct {
+		name string
+		pods []corev1.Pod
+		want []ComponentStatus
+	}{
+		{
+			name: "terminated",
+			pods: []corev1.Pod{oomKilled},
+			want: []ComponentStatus{{Component: "server", Ready: 1, Pods: 1, Restarts: 1, LastState: "OOMKilled"}},
+		},
+		{
+			name: "waiting",
+			pods: []corev1.Pod{crashing},
+			want: []ComponentStatus{{Component: "server", Pods: 1, Restarts: 4, LastState: "CrashLoopBackOff"}},
+		},
+		{
+			name: "waiting takes precedence",
+			pods: []corev1.Pod{oomKilled, crashing},
+			want: []ComponentStatus{{Component: "server", Ready: 1, Pods: 2, Restarts: 5, LastState: "CrashLoopBackOff"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, ComponentStatuses(tt.pods)); d != "" {
+				t.Errorf("statuses mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestDiffComponentStatuses(t *testing.T) {
+	tests := []struct {
+		name string
+		prev []ComponentStatus
+		cur  []ComponentStatus
+		want []ComponentChange
+	}{
+		{
+			name: "no changes",
+			prev: []ComponentStatus{{Component: "server", Ready: 1, Pods: 1, Restarts: 1}},
+			cur:  []ComponentStatus{{Component: "server", Ready: 1, Pods: 1, Restarts: 1}},
+		},
+		{
+			name: "first poll",
+			cur:  []ComponentStatus{{Component: "db", Pods: 1}, {Component: "server", Pods: 1}},
+			want: []ComponentChange{
+				{Component: "db", Kind: ComponentAdded},
+				{Component: "server", Kind: ComponentAdded},
+			},
+		},
+		{
+			name: "became ready",
+			prev: []ComponentStatus{{Component: "server", Pods: 1}},
+			cur:  []ComponentStatus{{Component: "server", Ready: 1, Pods: 1}},
+			want: []ComponentChange{{Component: "server", Kind: ComponentReady}},
+		},
+		{
+			name: "partially ready",
+			prev: []ComponentStatus{{Component: "worker", Pods: 2}},
+			cur:  []ComponentStatus{{Component: "worker", Ready: 1, Pods: 2}},
+		},
+		{
+			name: "became not ready and restarted",
+			prev: []ComponentStatus{{Component: "worker", Ready: 1, Pods: 1, Restarts: 1}},
+			cur:  []ComponentStatus{{Component: "worker", Pods: 1, Restarts: 3}},
+			want: []ComponentChange{
+				{Component: "worker", Kind: ComponentNotReady},
+				{Component: "worker", Kind: ComponentRestarted, Restarts: 2},
+			},
+		},
+		{
+			name: "restarted pod replaced",
+			prev: []ComponentStatus{{Component: "worker", Ready: 1, Pods: 1, Restarts: 4}},
+			cur:  []ComponentStatus{{Component: "worker", Ready: 1, Pods: 1}},
+		},
+		{
+			name: "removed",
+			prev: []ComponentStatus{{Component: "db", Ready: 1, Pods: 1}, {Component: "server", Ready: 1, Pods: 1}},
+			cur:  []ComponentStatus{{Component: "server", Ready: 1, Pods: 1}},
+			want: []ComponentChange{{Component: "db", Kind: ComponentRemoved}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, DiffComponentStatuses(tt.prev, tt.cur)); d != "" {
+				t.Errorf("changes mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestComponentChange_String(t *testing.T) {
+	tests := []struct {
+		change ComponentChange
+		want   string
+	}{
+		{change: ComponentChange{Component: "server", Kind: ComponentReady}, want: "server ready"},
+		{change: ComponentChange{Component: "server", Kind: ComponentNotReady}, want: "server not ready"},
+		{change: ComponentChange{Component: "worker", Kind: ComponentRestarted, Restarts: 2}, want: "worker restarted (+2)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, tt.change.String()); d != "" {
+				t.Errorf("string mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	eventsv1 "k8s.io/api/events/v1"
+)
+
+// eventEmitter formats the kubernetes events of the airbyte release's objects for display.
+// Repeated events are collapsed, only being displayed again with their count once it doubles.
+type eventEmitter struct {
+	mu     sync.Mutex
+	counts map[eventKey]int
+}
+
+// eventKey identifies repeats of the same event.
+type eventKey struct {
+	kind, name, reason, note string
+}
+
+func newEventEmitter() *eventEmitter {
+	return &eventEmitter{counts: map[eventKey]int{}}
+}
+
+// releaseEvent returns true if the event regards an object of the airbyte release, e.g. the pod
+// airbyte-abctl-server-5d8f7b9c4-x2x7z or the stateful-set airbyte-db.
+func releaseEvent(e *eventsv1.Event) bool {
+	return strings.HasPrefix(e.Regarding.Name, "airbyte-")
+}
+
+// format returns the line to display for the event, or false if the event should not be displayed.
+func (em *eventEmitter) format(e *eventsv1.Event) (string, bool) {
+	if !releaseEvent(e) {
+		return "", false
+	}
+
+	em.mu.Lock()
+	key := eventKey{kind: e.Regarding.Kind, name: e.Regarding.Name, reason: e.Reason, note: e.Note}
+	em.counts[key]++
+	count := em.counts[key]
+	em.mu.Unlock()
+
+	// display the first event and then only when the count reaches a power of two
+	if count&(count-1) != 0 {
+		return "", false
+	}
+
+	line := fmt.Sprintf("%s %s/%s: %s", e.Reason, strings.ToLower(e.Regarding.Kind), e.Regarding.Name, e.Note)
+	if count > 1 {
+		line += fmt.Sprintf(" (x%d)", count)
+	}
+	return line, true
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+)
+
+func testEvent(kind, name, reason, note string) *eventsv1.Event {
+	return &eventsv1.Event{
+		Type:      "Warning",
+		Reason:    reason,
+		Note:      note,
+		Regarding: corev1.ObjectReference{Kind: kind, Name: name},
+	}
+}
+
+func TestEventEmitter(t *testing.T) {
+	backoff := testEvent("Pod", "airbyte-abctl-server-5d8f7b9c4-x2x7z", "BackOff", "Back-off restarting failed container")
+
+	// the fake event source, in the order the events are received
+	events := []*eventsv1.Event{
+		testEvent("Pod", "airbyte-db-0", "FailedScheduling", "0/1 nodes are available"),
+		backoff,
+		// not an object of the release
+		testEvent("Pod", "ingress-nginx-controller-7d9f8c6b5-q9w8e", "BackOff", "Back-off restarting failed container"),
+		backoff,
+		backoff,
+		// same reason, different note
+		testEvent("Pod", "airbyte-abctl-server-5d8f7b9c4-x2x7z", "BackOff", "Back-off pulling image"),
+		backoff,
+		backoff,
+		backoff,
+		backoff,
+		testEvent("Pod", "airbyte-db-0", "Scheduled", "Successfully assigned airbyte-abctl/airbyte-db-0"),
+	}
+
+	want := []string{
+		"FailedScheduling pod/airbyte-db-0: 0/1 nodes are available",
+		"BackOff pod/airbyte-abctl-server-5d8f7b9c4-x2x7z: Back-off restarting failed container",
+		"BackOff pod/airbyte-abctl-server-5d8f7b9c4-x2x7z: Back-off restarting failed container (x2)",
+		"BackOff pod/airbyte-abctl-server-5d8f7b9c4-x2x7z: Back-off pulling image",
+		"BackOff pod/airbyte-abctl-server-5d8f7b9c4-x2x7z: Back-off restarting failed container (x4)",
+		"Scheduled pod/airbyte-db-0: Successfully assigned airbyte-abctl/airbyte-db-0",
+	}
+
+	emitter := newEventEmitter()
+	var got []string
+	for _, e := range events {
+		if line, ok := emitter.format(e); ok {
+			got = append(got, line)
+		}
+	}
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("lines mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestReleaseEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "airbyte-abctl-server-5d8f7b9c4-x2x7z", want: true},
+		{name: "airbyte-db", want: true},
+		{name: "airbyte-minio-0", want: true},
+		{name: "ingress-nginx-controller-7d9f8c6b5-q9w8e", want: false},
+		{name: "coredns-7db6d8ff4d-abcde", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testEvent("Pod", tt.name, "Started", "Started container")
+			if d := cmp.Diff(tt.want, releaseEvent(e)); d != "" {
+				t.Errorf("release mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
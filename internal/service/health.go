@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// HealthProbe performs a deeper health check of a component's pod, beyond the pod reporting ready.
+// A nil error indicates the component is healthy.
+type HealthProbe func(ctx context.Context, client k8s.Client, namespace string, pod corev1.Pod) error
+
+// HealthProbes maps component names (e.g. "server", see PodComponent) to the probe verifying their health.
+type HealthProbes map[string]HealthProbe
+
+// Register adds the probe for the component, replacing any existing probe.
+func (h HealthProbes) Register(component string, probe HealthProbe) {
+	h[component] = probe
+}
+
+// DefaultHealthProbes are the health probes for the known airbyte components.
+//
+// The temporal component only exposes a gRPC endpoint and is not probed directly, instead its health is
+// verified through the worker which is unable to report healthy until it has connected to temporal.
+var DefaultHealthProbes = HealthProbes{
+	"server": HTTPHealthProbe("8001", "/api/v1/health"),
+	"worker": HTTPHealthProbe("9000", "/"),
+}
+
+// HTTPHealthProbe returns a HealthProbe which requires an HTTP GET against the port and path of the pod to succeed.
+func HTTPHealthProbe(port, path string) HealthProbe {
+	return func(ctx context.Context, client k8s.Client, namespace string, pod corev1.Pod) error {
+		if _, err := client.PodProxyGet(ctx, namespace, pod.Name, port, path); err != nil {
+			return fmt.Errorf("health check of pod '%s' at %s%s failed: %w", pod.Name, port, path, err)
+		}
+		return nil
+	}
+}
+
+// waitForComponentHealth runs the probes against a ready pod of every probed component in the namespace, retrying
+// until all of them succeed. A component without a ready pod is unhealthy, unless all of its pods are ignored by the
+// selectors, as ignored pods are never probed. If a component does not become healthy within its timeout, a
+// ComponentTimeoutError for that component is returned.
+func waitForComponentHealth(ctx context.Context, client k8s.Client, namespace string, probes HealthProbes, timeouts ComponentTimeouts, selectors ReadinessSelectors) error {
+	start := time.Now()
+	healthy := map[string]bool{}
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pods, err := client.PodList(ctx, namespace)
+		if err != nil {
+			pterm.Debug.Printfln("Unable to list pods in namespace '%s': %s", namespace, err)
+		} else {
+			// probe one ready pod per component, skipping the components whose every pod is ignored
+			targets := map[string]corev1.Pod{}
+			ignoredOnly := map[string]bool{}
+			for _, pod := range pods.Items {
+				component := PodComponent(pod)
+				if _, ok := probes[component]; !ok || healthy[component] {
+					continue
+				}
+				if selectors.ignored(pod) {
+					if _, ok := ignoredOnly[component]; !ok {
+						ignoredOnly[component] = true
+					}
+					continue
+				}
+				ignoredOnly[component] = false
+				if _, ok := targets[component]; !ok && podReady(pod) {
+					targets[component] = pod
+				}
+			}
+
+			var unhealthy []string
+			for component, probe := range probes {
+				if healthy[component] || ignoredOnly[component] {
+					continue
+				}
+				pod, ok := targets[component]
+				if !ok {
+					pterm.Debug.Printfln("Component '%s' has no ready pod yet", component)
+					unhealthy = append(unhealthy, component)
+					continue
+				}
+				if err := probe(ctx, client, namespace, pod); err != nil {
+					pterm.Debug.Printfln("Component '%s' is not healthy yet: %s", component, err)
+					unhealthy = append(unhealthy, component)
+					continue
+				}
+				pterm.Debug.Printfln("Component '%s' is healthy", component)
+				healthy[component] = true
+			}
+
+			if len(unhealthy) == 0 {
+				return nil
+			}
+
+			sort.Strings(unhealthy)
+			for _, component := range unhealthy {
+				timeout := timeouts.For(component)
+				if time.Since(start) > timeout {
+					pterm.Error.Printfln("Component '%s' was not healthy within %s", component, timeout)
+					return &ComponentTimeoutError{Component: component, Timeout: timeout}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
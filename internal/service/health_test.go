@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestHealthProbes_Register(t *testing.T) {
+	probes := HealthProbes{}
+	called := ""
+	probes.Register("temporal", func(ctx context.Context, client k8s.Client, namespace string, pod corev1.Pod) error {
+		called = pod.Name
+		return nil
+	})
+
+	probe, ok := probes["temporal"]
+	if !ok {
+		t.Fatal("expected probe to be registered")
+	}
+	pod := testPod("airbyte-temporal-123-abc", "ReplicaSet", "airbyte-temporal-123", true)
+	if err := probe(context.Background(), &k8stest.MockClient{}, common.AirbyteNamespace, pod); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if _, ok := probes["server"]; ok {
+		t.Error("unexpected probe registered")
+	}
+	if d := cmp.Diff(pod.Name, called); d != "" {
+		t.Error("unexpected pod", d)
+	}
+
+	// the default probes must cover the components with startup ordering dependencies
+	for _, component := range []string{"server", "worker"} {
+		if _, ok := DefaultHealthProbes[component]; !ok {
+			t.Errorf("expected a default probe for %s", component)
+		}
+	}
+}
+
+func TestHTTPHealthProbe(t *testing.T) {
+	pod := testPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", true)
+	healthy := false
+
+	// mock health endpoint, only serving the expected pod, port and path
+	k8sClient := &k8stest.MockClient{
+		FnPodProxyGet: func(ctx context.Context, namespace, name, port, path string) ([]byte, error) {
+			if namespace != common.AirbyteNamespace || name != pod.Name || port != "8001" || path != "/api/v1/health" {
+				t.Errorf("unexpected request %s/%s:%s%s", namespace, name, port, path)
+				return nil, errors.New("not found")
+			}
+			if !healthy {
+				return nil, errors.New("service unavailable")
+			}
+			return []byte(`{"available":true}`), nil
+		},
+	}
+
+	probe := HTTPHealthProbe("8001", "/api/v1/health")
+	if err := probe(context.Background(), k8sClient, common.AirbyteNamespace, pod); err == nil {
+		t.Error("expected error from unhealthy endpoint")
+	}
+
+	healthy = true
+	if err := probe(context.Background(), k8sClient, common.AirbyteNamespace, pod); err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestWaitForComponentHealth(t *testing.T) {
+	setReadinessPollInterval(t, 10*time.Millisecond)
+
+	pods := []corev1.Pod{
+		testPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", true),
+		testPod("airbyte-abctl-worker-456-def", "ReplicaSet", "airbyte-abctl-worker-456", true),
+		// not probed, must be ignored
+		testPod("airbyte-abctl-webapp-789-ghi", "ReplicaSet", "airbyte-abctl-webapp-789", true),
+	}
+	k8sClient := &k8stest.MockClient{
+		FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+			return &corev1.PodList{Items: pods}, nil
+		},
+	}
+
+	t.Run("healthy after retries", func(t *testing.T) {
+		workerCalls := 0
+		probes := HealthProbes{
+			"server": func(ctx context.Context, client k8s.Client, namespace string, pod corev1.Pod) error {
+				return nil
+			},
+			"worker": func(ctx context.Context, client k8s.Client, namespace string, pod corev1.Pod) error {
+				// the worker only becomes healthy once it has connected to temporal
+				workerCalls++
+				if workerCalls < 3 {
+					return errors.New("temporal unavailable")
+				}
+				return nil
+			},
+		}
+
+		err := waitForComponentHealth(context.Background(), k8sClient, common.AirbyteNamespace, probes, ComponentTimeouts{}, ReadinessSelectors{})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(3, workerCalls); d != "" {
+			t.Error("unexpected worker probe calls", d)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		probes := HealthProbes{
+			"worker": func(ctx context.Context, client k8s.Client, namespace string, pod corev1.Pod) error {
+				return errors.New("temporal unavailable")
+			},
+		}
+		timeouts := ComponentTimeouts{Overrides: map[string]time.Duration{"worker": 50 * time.Millisecond}}
+
+		err := waitForComponentHealth(context.Background(), k8sClient, common.AirbyteNamespace, probes, timeouts, ReadinessSelectors{})
+		var timeoutErr *ComponentTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected ComponentTimeoutError but got %v", err)
+		}
+		if d := cmp.Diff("worker", timeoutErr.Component); d != "" {
+			t.Error("unexpected component", d)
+		}
+	})
+
+	t.Run("no ready pod", func(t *testing.T) {
+		k8sClient := &k8stest.MockClient{
+			FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+				return &corev1.PodList{Items: []corev1.Pod{
+					// restarting, not ready
+					testPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", false),
+					testPod("airbyte-abctl-worker-456-def", "ReplicaSet", "airbyte-abctl-worker-456", true),
+				}}, nil
+			},
+		}
+		probes := HealthProbes{
+			"server": func(ctx context.Context, client k8s.Client, namespace string, pod corev1.Pod) error {
+				t.Error("unready pod must not be probed")
+				return nil
+			},
+			"worker": func(ctx context.Context, client k8s.Client, namespace string, pod corev1.Pod) error {
+				return nil
+			},
+		}
+		timeouts := ComponentTimeouts{Overrides: map[string]time.Duration{"server": 50 * time.Millisecond}}
+
+		err := waitForComponentHealth(context.Background(), k8sClient, common.AirbyteNamespace, probes, timeouts, ReadinessSelectors{})
+		var timeoutErr *ComponentTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected ComponentTimeoutError but got %v", err)
+		}
+		if d := cmp.Diff("server", timeoutErr.Component); d != "" {
+			t.Error("unexpected component", d)
+		}
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		worker := testPod("airbyte-abctl-worker-456-def", "ReplicaSet", "airbyte-abctl-worker-456", true)
+		worker.Labels = map[string]string{"app.kubernetes.io/name": "worker"}
+		k8sClient := &k8stest.MockClient{
+			FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+				return &corev1.PodList{Items: []corev1.Pod{worker}}, nil
+			},
+		}
+		probes := HealthProbes{
+			"worker": func(ctx context.Context, client k8s.Client, namespace string, pod corev1.Pod) error {
+				t.Error("ignored pod must not be probed")
+				return nil
+			},
+		}
+		selectors := ReadinessSelectors{Ignore: []k8s.LabelSelector{{Key: "app.kubernetes.io/name", Value: "worker"}}}
+
+		if err := waitForComponentHealth(context.Background(), k8sClient, common.AirbyteNamespace, probes, ComponentTimeouts{}, selectors); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	})
+}
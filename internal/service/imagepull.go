@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/docker/docker/api/types/image"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxImagePullRetries limits how many times an image the cluster node can't pull is loaded into the node from the host.
+var maxImagePullRetries = 2
+
+// imageLoader pulls the image on the host, if it isn't already present, and loads it into the cluster node.
+type imageLoader func(ctx context.Context, img string) error
+
+// imagePullRetrier remediates pods stuck in ImagePullBackOff during readiness. The host may be able to pull an image
+// the node can't, e.g. with the host's docker auth or once a transient registry error has passed, so the image is
+// loaded into the node from the host and the stuck pods are deleted to be recreated with the image present.
+type imagePullRetrier struct {
+	client k8s.Client
+	load   imageLoader
+
+	// attempts are the number of times each image has been loaded into the node
+	attempts map[string]int
+	// abandoned are the images which are no longer retried, and have been reported as such
+	abandoned map[string]bool
+}
+
+func newImagePullRetrier(client k8s.Client, load imageLoader) *imagePullRetrier {
+	return &imagePullRetrier{client: client, load: load, attempts: map[string]int{}, abandoned: map[string]bool{}}
+}
+
+// stuckPull is an image which a pod is unable to pull.
+type stuckPull struct {
+	pods []string
+	// message is the reason the node last gave for failing to pull the image
+	message string
+}
+
+// stuckPulls returns the images which the pods are in ImagePullBackOff for.
+func stuckPulls(pods []corev1.Pod) map[string]*stuckPull {
+	stuck := map[string]*stuckPull{}
+	for _, pod := range pods {
+		for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+			for _, status := range statuses {
+				if status.State.Waiting == nil || status.State.Waiting.Reason != "ImagePullBackOff" {
+					continue
+				}
+				s, ok := stuck[status.Image]
+				if !ok {
+					s = &stuckPull{}
+					stuck[status.Image] = s
+				}
+				if !slices.Contains(s.pods, pod.Name) {
+					s.pods = append(s.pods, pod.Name)
+				}
+				s.message = status.State.Waiting.Message
+			}
+		}
+	}
+	return stuck
+}
+
+// StuckImagePulls returns the images which the pods are in ImagePullBackOff for, along with the names of those pods.
+func StuckImagePulls(pods []corev1.Pod) map[string][]string {
+	stuck := map[string][]string{}
+	for img, s := range stuckPulls(pods) {
+		stuck[img] = s.pods
+	}
+	return stuck
+}
+
+// remediate loads every image the pods are stuck pulling into the node, and deletes the stuck pods so they're
+// recreated with the image present. Each image is only loaded up to maxImagePullRetries times, after which it's
+// reported and left for the readiness timeout to handle.
+func (r *imagePullRetrier) remediate(ctx context.Context, namespace string, pods []corev1.Pod) {
+	stuck := stuckPulls(pods)
+
+	images := make([]string, 0, len(stuck))
+	for img := range stuck {
+		images = append(images, img)
+	}
+	sort.Strings(images)
+
+	for _, img := range images {
+		s := stuck[img]
+		if r.abandoned[img] {
+			continue
+		}
+		if r.attempts[img] >= maxImagePullRetries {
+			r.abandoned[img] = true
+			pterm.Warning.Printfln("The cluster is still unable to pull image %s after loading it %d times: %s", img, r.attempts[img], s.message)
+			continue
+		}
+		r.attempts[img]++
+
+		pterm.Info.Printfln("The cluster is unable to pull image %s, loading it from the host (attempt %d of %d)", img, r.attempts[img], maxImagePullRetries)
+		pterm.Debug.Printfln("image %s pull failure: %s", img, s.message)
+		if err := r.load(ctx, img); err != nil {
+			if imagePullAuthError(err.Error()) || imagePullAuthError(s.message) {
+				// retrying won't help until the credentials are fixed
+				r.abandoned[img] = true
+				pterm.Error.Printfln("Access to image %s was denied by its registry: %s\n"+
+					"Provide credentials for the registry with --docker-username and --docker-password, or --pull-secret, and try again.", img, err)
+				continue
+			}
+			pterm.Warning.Printfln("Unable to load image %s from the host: %s", img, err)
+			continue
+		}
+
+		for _, pod := range s.pods {
+			if err := r.client.PodDelete(ctx, namespace, pod); err != nil {
+				pterm.Debug.Printfln("unable to delete pod %s: %s", pod, err)
+				continue
+			}
+			pterm.Debug.Printfln("deleted pod %s to retry pulling image %s", pod, img)
+		}
+	}
+}
+
+// imagePullAuthErrors are the fragments of image pull errors which indicate the registry denied access.
+var imagePullAuthErrors = []string{
+	"unauthorized",
+	"authentication required",
+	"access denied",
+	"denied:",
+	"forbidden",
+}
+
+// imagePullAuthError returns true if the image pull error indicates the registry denied access to the image.
+func imagePullAuthError(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, fragment := range imagePullAuthErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostImageLoader returns the imageLoader which pulls images with the docker client of the host.
+// The cluster is only looked up once an image needs to be loaded.
+func (m *Manager) hostImageLoader() imageLoader {
+	return func(ctx context.Context, img string) error {
+		missing := missingImages(ctx, m.docker, []string{img})
+		if len(missing) > 0 {
+			// pulled here, rather than by the cluster, so the reason for a failed pull can be reported
+			r, err := m.docker.Client.ImagePull(ctx, img, image.PullOptions{})
+			if err != nil {
+				return fmt.Errorf("unable to pull image %s: %w", img, err)
+			}
+			_, err = io.Copy(io.Discard, r)
+			r.Close()
+			if err != nil {
+				return fmt.Errorf("unable to pull image %s: %w", img, err)
+			}
+			if exists, _ := m.docker.ImageExists(ctx, img); !exists {
+				return fmt.Errorf("unable to pull image %s", img)
+			}
+		}
+
+		cluster, err := m.provider.Cluster(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to load image %s into the cluster: %w", img, err)
+		}
+		cluster.LoadImages(ctx, m.docker.Client, []string{img})
+		m.trackPulledImages(ctx, missing)
+		return nil
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	testingk8s "k8s.io/client-go/testing"
+)
+
+// backOffPod returns a pod of the server component whose container is in ImagePullBackOff for the image.
+func backOffPod(name, img string) *corev1.Pod {
+	pod := testPod(name, "ReplicaSet", "airbyte-abctl-server-123", false)
+	pod.Namespace = common.AirbyteNamespace
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "server",
+		Image: img,
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+			Reason:  "ImagePullBackOff",
+			Message: `Back-off pulling image "` + img + `"`,
+		}},
+	}}
+	return &pod
+}
+
+// recordingLoader is an imageLoader which records the images it loads, returning err.
+type recordingLoader struct {
+	err    error
+	loaded []string
+}
+
+func (l *recordingLoader) load(_ context.Context, img string) error {
+	l.loaded = append(l.loaded, img)
+	return l.err
+}
+
+func pollWithPullRetry(t *testing.T, cs *fake.Clientset, loader *recordingLoader) {
+	setReadinessPollInterval(t, 10*time.Millisecond)
+
+	client := &k8s.DefaultK8sClient{ClientSet: cs}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	timeouts := ComponentTimeouts{Defaults: map[ComponentKind]time.Duration{ComponentService: 5 * time.Second}}
+	if err := pollComponentReadiness(ctx, client, common.AirbyteNamespace, timeouts, ReadinessSelectors{}, newImagePullRetrier(client, loader.load)); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestPollComponentReadiness_ImagePullRetry(t *testing.T) {
+	cs := fake.NewSimpleClientset(
+		backOffPod("airbyte-abctl-server-123-abc", "airbyte/server:1.0.0"),
+		backOffPod("airbyte-abctl-server-123-def", "airbyte/server:1.0.0"),
+	)
+	loader := &recordingLoader{}
+
+	pollWithPullRetry(t, cs, loader)
+
+	// the image is loaded once, and both stuck pods are deleted to be recreated with it
+	if d := cmp.Diff([]string{"airbyte/server:1.0.0"}, loader.loaded); d != "" {
+		t.Errorf("loaded images mismatch (-want +got):\n%s", d)
+	}
+	pods, err := cs.CoreV1().Pods(common.AirbyteNamespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(0, len(pods.Items)); d != "" {
+		t.Errorf("expected the stuck pods to be deleted (-want +got):\n%s", d)
+	}
+}
+
+func TestPollComponentReadiness_ImagePullRetryBounded(t *testing.T) {
+	cs := fake.NewSimpleClientset(backOffPod("airbyte-abctl-server-123-abc", "airbyte/server:1.0.0"))
+	// the pod never recovers, as if it were recreated and stuck again every time
+	deletes := 0
+	cs.PrependReactor("delete", "pods", func(action testingk8s.Action) (bool, runtime.Object, error) {
+		deletes++
+		return true, nil, nil
+	})
+	loader := &recordingLoader{}
+
+	pollWithPullRetry(t, cs, loader)
+
+	want := make([]string, maxImagePullRetries)
+	for i := range want {
+		want[i] = "airbyte/server:1.0.0"
+	}
+	if d := cmp.Diff(want, loader.loaded); d != "" {
+		t.Errorf("loaded images mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(maxImagePullRetries, deletes); d != "" {
+		t.Errorf("deletes mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestPollComponentReadiness_ImagePullRetryLoadErr(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantLoads int
+	}{
+		{
+			name:      "auth error",
+			err:       errors.New("unable to pull image airbyte/server:1.0.0: unauthorized: authentication required"),
+			wantLoads: 1,
+		},
+		{
+			name:      "transient error",
+			err:       errors.New("unable to pull image airbyte/server:1.0.0: connection reset by peer"),
+			wantLoads: maxImagePullRetries,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := fake.NewSimpleClientset(backOffPod("airbyte-abctl-server-123-abc", "airbyte/server:1.0.0"))
+			loader := &recordingLoader{err: tt.err}
+
+			pollWithPullRetry(t, cs, loader)
+
+			if d := cmp.Diff(tt.wantLoads, len(loader.loaded)); d != "" {
+				t.Errorf("loads mismatch (-want +got):\n%s", d)
+			}
+			// the pod isn't deleted as the image couldn't be loaded
+			if _, err := cs.CoreV1().Pods(common.AirbyteNamespace).Get(context.Background(), "airbyte-abctl-server-123-abc", metav1.GetOptions{}); err != nil {
+				t.Error("expected the pod to be kept", err)
+			}
+		})
+	}
+}
+
+func TestStuckPulls(t *testing.T) {
+	initStuck := backOffPod("airbyte-abctl-server-123-abc", "airbyte/server:1.0.0")
+	initStuck.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+		Image: "busybox:1.36",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "not found"}},
+	}}
+	pulling := backOffPod("airbyte-abctl-worker-456-def", "airbyte/worker:1.0.0")
+	pulling.Status.ContainerStatuses[0].State.Waiting.Reason = "ContainerCreating"
+
+	got := stuckPulls([]corev1.Pod{*initStuck, *backOffPod("airbyte-abctl-server-123-ghi", "airbyte/server:1.0.0"), *pulling})
+
+	want := map[string]*stuckPull{
+		"airbyte/server:1.0.0": {
+			pods:    []string{"airbyte-abctl-server-123-abc", "airbyte-abctl-server-123-ghi"},
+			message: `Back-off pulling image "airbyte/server:1.0.0"`,
+		},
+		"busybox:1.36": {pods: []string{"airbyte-abctl-server-123-abc"}, message: "not found"},
+	}
+	if d := cmp.Diff(want, got, cmp.AllowUnexported(stuckPull{})); d != "" {
+		t.Errorf("stuck pulls mismatch (-want +got):\n%s", d)
+	}
+
+	wantPods := map[string][]string{
+		"airbyte/server:1.0.0": {"airbyte-abctl-server-123-abc", "airbyte-abctl-server-123-ghi"},
+		"busybox:1.36":         {"airbyte-abctl-server-123-abc"},
+	}
+	if d := cmp.Diff(wantPods, StuckImagePulls([]corev1.Pod{*initStuck, *backOffPod("airbyte-abctl-server-123-ghi", "airbyte/server:1.0.0"), *pulling})); d != "" {
+		t.Errorf("stuck image pulls mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestImagePullAuthError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{msg: `failed to authorize: failed to fetch anonymous token: unexpected status: 401 Unauthorized`, want: true},
+		{msg: `pull access denied for airbyte/private, repository does not exist or may require 'docker login'`, want: true},
+		{msg: `denied: requested access to the resource is denied`, want: true},
+		{msg: `unexpected status: 403 Forbidden`, want: true},
+		{msg: `dial tcp: lookup registry-1.docker.io: i/o timeout`},
+		{msg: `manifest for airbyte/server:sha256-4013abc not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, imagePullAuthError(tt.msg)); d != "" {
+				t.Errorf("auth error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/pterm/pterm"
+)
+
+// imageManifest is the manifest of the docker images pulled by abctl for the cluster.
+// Only images which weren't present before abctl pulled them are tracked, so that pruning them
+// never removes an image the user pulled themselves.
+type imageManifest struct {
+	Images []string `json:"images"`
+}
+
+// readImageManifest returns the images tracked in the manifest at path, none if the manifest doesn't exist.
+func readImageManifest(path string) ([]string, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read image manifest: %w", err)
+	}
+
+	var manifest imageManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse image manifest '%s': %w", path, err)
+	}
+	return manifest.Images, nil
+}
+
+// writeImageManifest writes the sorted images to the manifest at path, removing the manifest if there are none.
+func writeImageManifest(path string, images []string) error {
+	if len(images) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("unable to remove image manifest: %w", err)
+		}
+		return nil
+	}
+
+	images = slices.Clone(images)
+	slices.Sort(images)
+	raw, err := json.MarshalIndent(imageManifest{Images: slices.Compact(images)}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to marshal image manifest: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("unable to create image manifest directory: %w", err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		return fmt.Errorf("unable to write image manifest: %w", err)
+	}
+	return nil
+}
+
+// missingImages returns the images which are not present locally, none without a docker client.
+func missingImages(ctx context.Context, d *docker.Docker, images []string) []string {
+	if d == nil {
+		return nil
+	}
+
+	var missing []string
+	for _, img := range images {
+		exists, err := d.ImageExists(ctx, img)
+		if err != nil {
+			pterm.Debug.Printfln("unable to determine if image %s is present: %s", img, err)
+			continue
+		}
+		if !exists {
+			missing = append(missing, img)
+		}
+	}
+	return missing
+}
+
+// trackPulledImages adds the images, of those which were missing before they were loaded, which are now present
+// to the image manifest. This is best effort, so errors are only logged.
+func (m *Manager) trackPulledImages(ctx context.Context, missing []string) {
+	if m.docker == nil {
+		return
+	}
+
+	var pulled []string
+	for _, img := range missing {
+		if exists, _ := m.docker.ImageExists(ctx, img); exists {
+			pulled = append(pulled, img)
+		}
+	}
+	if len(pulled) == 0 {
+		return
+	}
+
+	tracked, err := readImageManifest(m.imageManifest)
+	if err != nil {
+		pterm.Debug.Printfln("unable to track pulled images: %s", err)
+		return
+	}
+	if err := writeImageManifest(m.imageManifest, append(tracked, pulled...)); err != nil {
+		pterm.Debug.Printfln("unable to track pulled images: %s", err)
+	}
+}
+
+// PrunableImages returns the images tracked in the image manifest at path, those UnusedImages selects from.
+func PrunableImages(path string) ([]string, error) {
+	return readImageManifest(path)
+}
+
+// PruneResult is the result of pruning the images pulled by abctl.
+type PruneResult struct {
+	// Removed are the unused images which were removed.
+	Removed []docker.UnusedImage
+	// Reclaimed is the size, in bytes, of the removed images.
+	Reclaimed int64
+}
+
+// UnusedImages returns the docker disk usage, along with the images pulled by abctl which no container uses,
+// and the dangling images left behind in their repositories. Any other image is never returned.
+func UnusedImages(ctx context.Context, d *docker.Docker, path string) (docker.DiskUsage, []docker.UnusedImage, error) {
+	tracked, err := readImageManifest(path)
+	if err != nil {
+		return docker.DiskUsage{}, nil, err
+	}
+
+	usage, err := d.DiskUsage(ctx)
+	if err != nil {
+		return docker.DiskUsage{}, nil, err
+	}
+	return usage, usage.UnusedImages(tracked), nil
+}
+
+// RemoveUnusedImages removes the unused images, as returned by UnusedImages, and stops tracking the removed
+// references in the image manifest at path. Images which couldn't be removed are skipped with a warning.
+func RemoveUnusedImages(ctx context.Context, d *docker.Docker, path string, images []docker.UnusedImage) (PruneResult, error) {
+	var res PruneResult
+
+	tracked, err := readImageManifest(path)
+	if err != nil {
+		return res, err
+	}
+
+	for _, img := range images {
+		if err := d.RemoveUnusedImage(ctx, img); err != nil {
+			pterm.Warning.Printfln("Unable to remove image %s: %s", img.ID, err)
+			continue
+		}
+		pterm.Debug.Printfln("Removed image %s", img.ID)
+		res.Removed = append(res.Removed, img)
+		res.Reclaimed += img.Size
+		tracked = slices.DeleteFunc(tracked, func(ref string) bool { return slices.Contains(img.Refs, ref) })
+	}
+
+	if err := writeImageManifest(path, tracked); err != nil {
+		return res, err
+	}
+	return res, nil
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
+	"github.com/airbytehq/abctl/internal/helm/mock"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/image"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUnusedImages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "images.json")
+	if err := writeImageManifest(path, []string{"airbyte/server:1.0.0", "airbyte/worker:1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &docker.Docker{Client: dockertest.MockClient{
+		FnDiskUsage: func(ctx context.Context, options types.DiskUsageOptions) (types.DiskUsage, error) {
+			return types.DiskUsage{
+				LayersSize: 700,
+				Images: []*image.Summary{
+					{ID: "sha256:1", RepoTags: []string{"airbyte/server:1.0.0"}, Size: 100},
+					{ID: "sha256:2", RepoTags: []string{"airbyte/worker:1.0.0"}, Size: 200, Containers: 1},
+					// pulled by the user, never selected even though it is unused
+					{ID: "sha256:3", RepoTags: []string{"postgres:13"}, Size: 400},
+				},
+			}, nil
+		},
+	}}
+
+	usage, images, err := UnusedImages(context.Background(), d, path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if d := cmp.Diff([]docker.UnusedImage{{ID: "sha256:1", Refs: []string{"airbyte/server:1.0.0"}, Size: 100}}, images); d != "" {
+		t.Errorf("unused images mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(int64(500), usage.Images.Reclaimable); d != "" {
+		t.Errorf("reclaimable mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestRemoveUnusedImages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "images.json")
+	if err := writeImageManifest(path, []string{"airbyte/server:1.0.0", "airbyte/worker:1.0.0", "airbyte/cron:1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var removed []string
+	d := &docker.Docker{Client: dockertest.MockClient{
+		FnImageRemove: func(ctx context.Context, imageID string, options image.RemoveOptions) ([]image.DeleteResponse, error) {
+			if imageID == "airbyte/worker:1.0.0" {
+				return nil, errors.New("image is being used by a container")
+			}
+			removed = append(removed, imageID)
+			return []image.DeleteResponse{{Deleted: imageID}}, nil
+		},
+	}}
+
+	images := []docker.UnusedImage{
+		{ID: "sha256:1", Refs: []string{"airbyte/server:1.0.0"}, Size: 100},
+		{ID: "sha256:2", Refs: []string{"airbyte/worker:1.0.0"}, Size: 200},
+		{ID: "sha256:3", Refs: []string{"sha256:3"}, Size: 50},
+	}
+	res, err := RemoveUnusedImages(context.Background(), d, path, images)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if d := cmp.Diff([]string{"airbyte/server:1.0.0", "sha256:3"}, removed); d != "" {
+		t.Errorf("removed images mismatch (-want +got):\n%s", d)
+	}
+	want := PruneResult{Removed: []docker.UnusedImage{images[0], images[2]}, Reclaimed: 150}
+	if d := cmp.Diff(want, res); d != "" {
+		t.Errorf("result mismatch (-want +got):\n%s", d)
+	}
+
+	// the image which couldn't be removed, and the one which wasn't selected, are still tracked
+	tracked, err := readImageManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff([]string{"airbyte/cron:1.0.0", "airbyte/worker:1.0.0"}, tracked); d != "" {
+		t.Errorf("tracked images mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestManager_TrackPulledImages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "images.json")
+	// tracked by a previous install
+	if err := writeImageManifest(path, []string{"airbyte/server:0.9.0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	present := []image.Summary{{RepoTags: []string{"postgres:13"}}}
+	cluster := &mockCluster{loadImages: func(ctx context.Context, dockerClient docker.Client, images []string) {
+		// postgres was already present, the server is pulled, the worker fails to pull
+		present = append(present, image.Summary{RepoTags: []string{"airbyte/server:1.0.0"}})
+	}}
+
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8stest.MockClient{}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithTelemetryClient(&telemetry.MockClient{}),
+		WithImageManifest(path),
+		WithDockerClient(&docker.Docker{Client: dockertest.MockClient{
+			FnImageList: func(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+				return present, nil
+			},
+		}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svcMgr.LoadConnectorImages(context.Background(), cluster, []string{"postgres:13", "airbyte/server:1.0.0", "airbyte/worker:1.0.0"})
+
+	tracked, err := readImageManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff([]string{"airbyte/server:0.9.0", "airbyte/server:1.0.0"}, tracked); d != "" {
+		t.Errorf("tracked images mismatch (-want +got):\n%s", d)
+	}
+}
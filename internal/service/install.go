@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +31,8 @@ import (
 	"helm.sh/helm/v3/pkg/repo"
 	corev1 "k8s.io/api/core/v1"
 	eventsv1 "k8s.io/api/events/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
 )
@@ -50,31 +53,133 @@ type InstallOpts struct {
 	LocalStorage     bool
 	EnablePsql17     bool
 
+	// Ingress configures the ingress of airbyte, by default served by the nginx ingress controller installed by abctl.
+	Ingress k8s.IngressOpts
+
 	DockerServer string
 	DockerUser   string
 	DockerPass   string
 	DockerEmail  string
 
 	NoBrowser bool
+	// ReadinessEndpoint is polled to declare airbyte reachable before launching the browser, the root url by default.
+	ReadinessEndpoint ReadinessEndpoint
+
+	// AdminPassword, if set, replaces the generated password of the airbyte admin login.
+	AdminPassword string
+
+	// LicenseKey, if set, is stored in the airbyte license secret read by the enterprise chart flavor.
+	LicenseKey string
+
+	// EmitEvents displays the kubernetes events of the airbyte release as they occur.
+	EmitEvents bool
+
+	// DataVolumeSize is the size of the database volume, defaults to k8s.DefaultPersistentVolumeSize if zero.
+	DataVolumeSize resource.Quantity
+	// AllowVolumeShrink allows a smaller DataVolumeSize than the size of the existing database volume claim.
+	AllowVolumeShrink bool
+	// StorageClass is the storage class of the persistent volumes and claims, defaults to k8s.DefaultStorageClass if
+	// empty. On a cluster not managed by abctl, it must exist.
+	StorageClass string
+	// RestoreVolumes, if set, restores the data of the persistent volumes (e.g. from a snapshot). It is called once the
+	// volumes are configured, before the airbyte chart is installed, so the database starts on the restored data.
+	RestoreVolumes func() error
+
+	// HelmTimeout is how long helm waits for the resources of a chart to be ready, defaults to DefaultHelmTimeout if zero.
+	HelmTimeout time.Duration
+	// HelmHistoryMax is how many revisions helm retains of each release, older ones are removed when it's upgraded.
+	// Zero retains every revision.
+	HelmHistoryMax int
+
+	// ComponentTimeouts are the readiness timeouts applied to the individual airbyte components
+	ComponentTimeouts ComponentTimeouts
+	// ReadinessSelectors include additional pods in, or exclude pods from, the readiness of the airbyte components
+	ReadinessSelectors ReadinessSelectors
+	// WaitJobs waits for the jobs created by the airbyte chart to complete successfully, not only for its pods to be ready.
+	// The logs of a failed job are printed.
+	WaitJobs bool
+	// VerifySync verifies, once installed, that the components a sync flows through accept work, see DefaultSyncChecks.
+	VerifySync bool
+
+	// PullSecrets are created in the airbyte namespace, the airbyte pods are given them through the HelmValuesYaml.
+	PullSecrets []PullSecret
+
+	// Tolerations are given to the nginx pods, for the taints applied to the cluster node.
+	// The airbyte pods are given them through the HelmValuesYaml.
+	Tolerations []corev1.Toleration
+
+	// EnvFromSecrets and EnvFromConfigMaps are the existing secrets and config maps given to the platform components
+	// through the HelmValuesYaml. On a cluster not managed by abctl, they must exist.
+	EnvFromSecrets    []string
+	EnvFromConfigMaps []string
+
+	// ImageCache, if set, caches the images resolved from the chart across installations.
+	ImageCache *helm.ImageCache
 }
 
 func (i *InstallOpts) DockerAuth() bool {
 	return i.DockerUser != "" && i.DockerPass != ""
 }
 
+// dataVolumeSize returns the size of the database volume.
+func (i *InstallOpts) dataVolumeSize() resource.Quantity {
+	if i.DataVolumeSize.IsZero() {
+		return k8s.DefaultPersistentVolumeSize
+	}
+	return i.DataVolumeSize
+}
+
+// storageClass returns the storage class of the persistent volumes and claims.
+func (i *InstallOpts) storageClass() string {
+	if i.StorageClass == "" {
+		return k8s.DefaultStorageClass
+	}
+	return i.StorageClass
+}
+
+// DefaultHelmTimeout is how long helm waits for the resources of a chart to be ready, by default.
+// It exceeds the readiness timeouts of the components, so a component which doesn't become ready is reported by
+// abctl, naming the component, before helm times out.
+const DefaultHelmTimeout = 60 * time.Minute
+
+// helmTimeout returns how long helm waits for the resources of a chart to be ready.
+func (i *InstallOpts) helmTimeout() time.Duration {
+	if i.HelmTimeout <= 0 {
+		return DefaultHelmTimeout
+	}
+	return i.HelmTimeout
+}
+
+// checkVolumeShrink returns an error if the requested size is smaller than the existing size, unless force is true.
+// Persistent volume claims cannot be shrunk, so the existing size is retained in either case.
+func checkVolumeShrink(name string, existing, requested resource.Quantity, force bool) error {
+	switch requested.Cmp(existing) {
+	case -1:
+		if !force {
+			return fmt.Errorf("%w: persistent volume claim '%s' is %s, requested %s", abctl.ErrVolumeShrink, name, existing.String(), requested.String())
+		}
+		pterm.Warning.Printfln("Persistent volume claim '%s' cannot be shrunk, keeping the existing size of %s", name, existing.String())
+	case 1:
+		pterm.Warning.Printfln("Persistent volume claim '%s' already exists, keeping the existing size of %s", name, existing.String())
+	}
+	return nil
+}
+
 // persistentVolume creates a persistent volume in the namespace with the name provided.
 // if uid (user id) and gid (group id) are non-zero, the persistent directory on the host machine that holds the
 // persistent volume will be changed to be owned by
-func (m *Manager) persistentVolume(ctx context.Context, namespace, name string) error {
+func (m *Manager) persistentVolume(ctx context.Context, namespace, name, storageClass string, size resource.Quantity) error {
 	ctx, span := trace.NewSpan(ctx, "command.persistentVolume")
 	span.SetAttributes(
 		attribute.String("namespace", namespace),
 		attribute.String("name", name),
+		attribute.String("storage_class", storageClass),
+		attribute.String("size", size.String()),
 	)
 	defer span.End()
 
 	if !m.k8s.PersistentVolumeExists(ctx, namespace, name) {
-		m.spinner.UpdateText(fmt.Sprintf("Creating persistent volume '%s'", name))
+		m.report(PhaseVolumes, fmt.Sprintf("Creating persistent volume '%s'", name))
 
 		// Pre-create the volume directory.
 		//
@@ -96,7 +201,7 @@ func (m *Manager) persistentVolume(ctx context.Context, namespace, name string)
 			return fmt.Errorf("unable to create persistent volume '%s': %w", name, err)
 		}
 
-		if err := m.k8s.PersistentVolumeCreate(ctx, namespace, name); err != nil {
+		if err := m.k8s.PersistentVolumeCreate(ctx, namespace, name, storageClass, size); err != nil {
 			pterm.Error.Println(fmt.Sprintf("Unable to create persistent volume '%s'", name))
 			return fmt.Errorf("unable to create persistent volume '%s': %w", name, err)
 		}
@@ -126,32 +231,47 @@ func (m *Manager) persistentVolume(ctx context.Context, namespace, name string)
 	return nil
 }
 
-func (m *Manager) persistentVolumeClaim(ctx context.Context, namespace, name, volumeName string) error {
+// persistentVolumeClaim creates a persistent volume claim of the given size and storage class, bound to the volume provided.
+// If the claim already exists, an error is returned if the requested size is smaller than the existing claim,
+// unless allowShrink is true.
+func (m *Manager) persistentVolumeClaim(ctx context.Context, namespace, name, volumeName, storageClass string, size resource.Quantity, allowShrink bool) error {
 	ctx, span := trace.NewSpan(ctx, "command.persistentVolumeClaim")
 	span.SetAttributes(
 		attribute.String("namespace", namespace),
 		attribute.String("name", name),
 		attribute.String("volume", volumeName),
+		attribute.String("storage_class", storageClass),
+		attribute.String("size", size.String()),
 	)
 	defer span.End()
 
 	if !m.k8s.PersistentVolumeClaimExists(ctx, namespace, name, volumeName) {
-		m.spinner.UpdateText(fmt.Sprintf("Creating persistent volume claim '%s'", name))
-		if err := m.k8s.PersistentVolumeClaimCreate(ctx, namespace, name, volumeName); err != nil {
+		m.report(PhaseVolumes, fmt.Sprintf("Creating persistent volume claim '%s'", name))
+		if err := m.k8s.PersistentVolumeClaimCreate(ctx, namespace, name, volumeName, storageClass, size); err != nil {
 			pterm.Error.Println(fmt.Sprintf("Unable to create persistent volume claim '%s'", name))
 			return fmt.Errorf("unable to create persistent volume claim '%s': %w", name, err)
 		}
 		pterm.Info.Println(fmt.Sprintf("Persistent volume claim '%s' created", name))
 	} else {
 		pterm.Info.Printfln("Persistent volume claim '%s' already exists", name)
+
+		pvc, err := m.k8s.PersistentVolumeClaimGet(ctx, namespace, name)
+		if err != nil || pvc == nil {
+			pterm.Debug.Printfln("Unable to determine the size of persistent volume claim '%s': %v", name, err)
+			return nil
+		}
+		if err := checkVolumeShrink(name, pvc.Spec.Resources.Requests[corev1.ResourceStorage], size, allowShrink); err != nil {
+			pterm.Error.Printfln("Persistent volume claim '%s' cannot be shrunk", name)
+			return err
+		}
 	}
 
 	return nil
 }
 
 // PrepImages determines the docker images needed by the chart, pulls them, and loads them into the cluster.
-// This is best effort, so errors are dropped here.
-func (m *Manager) PrepImages(ctx context.Context, cluster k8s.Cluster, opts *InstallOpts, withImages ...string) {
+// Returns the images, none if they couldn't be determined. This is best effort, so errors are dropped here.
+func (m *Manager) PrepImages(ctx context.Context, cluster k8s.Cluster, opts *InstallOpts, withImages ...string) []string {
 	ctx, span := trace.NewSpan(ctx, "command.PrepImages")
 	defer span.End()
 
@@ -159,30 +279,89 @@ func (m *Manager) PrepImages(ctx context.Context, cluster k8s.Cluster, opts *Ins
 		pterm.Info.Printfln("Patching image %s", image)
 	}
 
-	manifest, err := helm.FindImagesFromChart(m.helm, opts.HelmValuesYaml, opts.AirbyteChartLoc, opts.HelmChartVersion)
+	findImages := helm.FindImagesFromChart
+	if opts.ImageCache != nil {
+		findImages = opts.ImageCache.FindImages
+	}
+
+	manifest, err := findImages(m.helm, opts.HelmValuesYaml, opts.AirbyteChartLoc, opts.HelmChartVersion)
 	if err != nil {
 		pterm.Debug.Printfln("error building image manifest: %s", err)
-		return
+		return nil
 	}
 
 	// Merge images with the manifest.
 	manifest = merge.DockerImages(manifest, withImages)
 
+	if m.docker == nil {
+		pterm.Debug.Println("No Docker client, the images will be pulled by the cluster")
+		return manifest
+	}
+
+	missing := missingImages(ctx, m.docker, manifest)
 	cluster.LoadImages(ctx, m.docker.Client, manifest)
+	m.trackPulledImages(ctx, missing)
+	return manifest
 }
 
-// Install handles the installation of Airbyte
+// LoadConnectorImages pulls the connector images and loads them into the cluster, so connectors can run without
+// pulling their images at first use. Returns the images which were pulled and loaded, the rest are reported as missing.
+func (m *Manager) LoadConnectorImages(ctx context.Context, cluster k8s.Cluster, images []string) []string {
+	ctx, span := trace.NewSpan(ctx, "command.LoadConnectorImages")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("total_images", len(images)))
+
+	if m.docker == nil {
+		pterm.Warning.Printfln("Docker is unavailable, the %d connector images will be pulled at first use", len(images))
+		return nil
+	}
+
+	missing := missingImages(ctx, m.docker, images)
+	cluster.LoadImages(ctx, m.docker.Client, images)
+	m.trackPulledImages(ctx, missing)
+
+	var loaded []string
+	for _, img := range images {
+		exists, err := m.docker.ImageExists(ctx, img)
+		if err != nil {
+			pterm.Debug.Printfln("unable to determine if image %s is present: %s", img, err)
+		}
+		if !exists {
+			pterm.Warning.Printfln("Connector image %s could not be loaded, it will be pulled at first use", img)
+			continue
+		}
+		pterm.Info.Printfln("Connector image %s loaded", img)
+		loaded = append(loaded, img)
+	}
+
+	return loaded
+}
+
+// Install handles the installation of Airbyte, journaling its phases.
 func (m *Manager) Install(ctx context.Context, opts *InstallOpts) error {
+	m.journal.begin(OperationInstall)
+	err := m.install(ctx, opts)
+	m.journal.finish(err)
+	return err
+}
+
+func (m *Manager) install(ctx context.Context, opts *InstallOpts) error {
 	ctx, span := trace.NewSpan(ctx, "command.Install")
 	defer span.End()
 
 	// Provide a child context to the watcher so that it can shut it down early to ensure the watcher cleanly shutdown.
 	ctxWatch, watchStop := context.WithCancel(ctx)
 	defer watchStop()
-	go m.watchEvents(ctxWatch)
+	var emitter *eventEmitter
+	if opts.EmitEvents {
+		emitter = newEventEmitter()
+	}
+	go m.watchEvents(ctxWatch, emitter)
 
+	m.report(PhaseNamespace, fmt.Sprintf("Checking for namespace '%s'", common.AirbyteNamespace))
 	if !m.k8s.NamespaceExists(ctx, common.AirbyteNamespace) {
-		m.spinner.UpdateText(fmt.Sprintf("Creating namespace '%s'", common.AirbyteNamespace))
+		m.report(PhaseNamespace, fmt.Sprintf("Creating namespace '%s'", common.AirbyteNamespace))
 		if err := m.k8s.NamespaceCreate(ctx, common.AirbyteNamespace); err != nil {
 			pterm.Error.Println(fmt.Sprintf("Unable to create namespace '%s'", common.AirbyteNamespace))
 			return fmt.Errorf("unable to create airbyte namespace: %w", err)
@@ -192,34 +371,58 @@ func (m *Manager) Install(ctx context.Context, opts *InstallOpts) error {
 		pterm.Info.Printfln("Namespace '%s' already exists", common.AirbyteNamespace)
 	}
 
+	if !m.provider.RequiresDocker() {
+		if err := m.checkEnvFromSources(ctx, opts.EnvFromSecrets, opts.EnvFromConfigMaps); err != nil {
+			return err
+		}
+	}
+
 	// Storage volumes.
+	m.report(PhaseVolumes, "Configuring persistent volumes")
+	storageClass := opts.storageClass()
+	if opts.StorageClass != "" && !m.provider.RequiresDocker() {
+		if err := m.checkStorageClass(ctx, storageClass); err != nil {
+			return err
+		}
+	}
+
 	if opts.LocalStorage {
-		if err := m.persistentVolume(ctx, common.AirbyteNamespace, paths.PvLocal); err != nil {
+		if err := m.persistentVolume(ctx, common.AirbyteNamespace, paths.PvLocal, storageClass, k8s.DefaultPersistentVolumeSize); err != nil {
 			return err
 		}
 
-		if err := m.persistentVolumeClaim(ctx, common.AirbyteNamespace, pvcLocal, paths.PvLocal); err != nil {
+		if err := m.persistentVolumeClaim(ctx, common.AirbyteNamespace, pvcLocal, paths.PvLocal, storageClass, k8s.DefaultPersistentVolumeSize, false); err != nil {
 			return err
 		}
 	} else {
-		if err := m.persistentVolume(ctx, common.AirbyteNamespace, paths.PvMinio); err != nil {
+		if err := m.persistentVolume(ctx, common.AirbyteNamespace, paths.PvMinio, storageClass, k8s.DefaultPersistentVolumeSize); err != nil {
 			return err
 		}
 
-		if err := m.persistentVolumeClaim(ctx, common.AirbyteNamespace, pvcMinio, paths.PvMinio); err != nil {
+		if err := m.persistentVolumeClaim(ctx, common.AirbyteNamespace, pvcMinio, paths.PvMinio, storageClass, k8s.DefaultPersistentVolumeSize, false); err != nil {
 			return err
 		}
 	}
 
 	// PSQL volumes.
-	if err := m.persistentVolume(ctx, common.AirbyteNamespace, paths.PvPsql); err != nil {
+	if err := m.persistentVolume(ctx, common.AirbyteNamespace, paths.PvPsql, storageClass, opts.dataVolumeSize()); err != nil {
 		return err
 	}
 
-	if err := m.persistentVolumeClaim(ctx, common.AirbyteNamespace, pvcPsql, paths.PvPsql); err != nil {
+	if err := m.persistentVolumeClaim(ctx, common.AirbyteNamespace, pvcPsql, paths.PvPsql, storageClass, opts.dataVolumeSize(), opts.AllowVolumeShrink); err != nil {
 		return err
 	}
 
+	if opts.RestoreVolumes != nil {
+		m.report(PhaseVolumes, "Restoring the data of the persistent volumes")
+		if err := opts.RestoreVolumes(); err != nil {
+			pterm.Error.Println("Unable to restore the data of the persistent volumes")
+			return fmt.Errorf("unable to restore the persistent volumes: %w", err)
+		}
+		pterm.Success.Println("Restored the data of the persistent volumes")
+	}
+
+	m.report(PhaseSecrets, "Configuring secrets")
 	if opts.DockerAuth() {
 		pterm.Debug.Println(fmt.Sprintf("Creating '%s' secret", common.DockerAuthSecretName))
 		if err := m.handleDockerSecret(ctx, opts.DockerServer, opts.DockerUser, opts.DockerPass, opts.DockerEmail); err != nil {
@@ -229,8 +432,23 @@ func (m *Manager) Install(ctx context.Context, opts *InstallOpts) error {
 		pterm.Debug.Println(fmt.Sprintf("Created '%s' secret", common.DockerAuthSecretName))
 	}
 
+	for _, p := range opts.PullSecrets {
+		if err := m.handlePullSecret(ctx, p); err != nil {
+			return err
+		}
+	}
+	if len(opts.PullSecrets) > 0 {
+		pterm.Success.Printfln("Created %d image pull secrets", len(opts.PullSecrets))
+	}
+
+	if opts.LicenseKey != "" {
+		if err := m.handleLicenseSecret(ctx, opts.LicenseKey); err != nil {
+			return err
+		}
+	}
+
 	for _, secretFile := range opts.Secrets {
-		m.spinner.UpdateText(fmt.Sprintf("Creating secret from '%s'", secretFile))
+		m.report(PhaseSecrets, fmt.Sprintf("Creating secret from '%s'", secretFile))
 		raw, err := os.ReadFile(secretFile)
 		if err != nil {
 			pterm.Error.Println(fmt.Sprintf("Unable to read secret file '%s': %s", secretFile, err))
@@ -252,7 +470,31 @@ func (m *Manager) Install(ctx context.Context, opts *InstallOpts) error {
 		pterm.Success.Println(fmt.Sprintf("Secret from '%s' created or updated", secretFile))
 	}
 
-	if err := m.handleChart(ctx, chartRequest{
+	if opts.Ingress.TLSSecret != "" {
+		if err := m.checkTLSSecret(ctx, opts.Ingress.TLSSecret); err != nil {
+			return err
+		}
+	}
+
+	// Poll the readiness of the individual components while the chart is being installed,
+	// canceling the installation if any component exceeds its readiness timeout.
+	ctxChart, chartCancel := context.WithCancelCause(ctx)
+	defer chartCancel(nil)
+	// pods unable to pull their images are retried with the images loaded from the host
+	var pullRetry *imagePullRetrier
+	if m.docker != nil {
+		pullRetry = newImagePullRetrier(m.k8s, m.hostImageLoader())
+	}
+	// only the jobs created by this installation are waited on
+	jobsSince := time.Now()
+	go func() {
+		if err := pollComponentReadiness(ctxChart, m.k8s, common.AirbyteNamespace, opts.ComponentTimeouts, opts.ReadinessSelectors, pullRetry); err != nil {
+			chartCancel(err)
+		}
+	}()
+
+	if err := m.handleChart(ctxChart, chartRequest{
+		phase:        PhaseAirbyte,
 		name:         "airbyte",
 		repoName:     common.AirbyteRepoName,
 		repoURL:      common.AirbyteRepoURLv1,
@@ -262,21 +504,119 @@ func (m *Manager) Install(ctx context.Context, opts *InstallOpts) error {
 		chartLoc:     opts.AirbyteChartLoc,
 		namespace:    common.AirbyteNamespace,
 		valuesYAML:   opts.HelmValuesYaml,
+		timeout:      opts.helmTimeout(),
+		maxHistory:   opts.HelmHistoryMax,
 	}); err != nil {
+		var timeoutErr *ComponentTimeoutError
+		if errors.As(context.Cause(ctxChart), &timeoutErr) {
+			err = fmt.Errorf("%w: %w", timeoutErr, err)
+		}
+		// helm waits on the jobs of the hooks, which fail the installation if they fail
+		if opts.WaitJobs {
+			if jobErr := m.failedJob(ctx, common.AirbyteNamespace, jobsSince, opts.ReadinessSelectors); jobErr != nil {
+				err = fmt.Errorf("%w: %w", jobErr, err)
+			}
+		}
 		// if trace.SpanError isn't called here, the logs attached
 		// in the diagnoseAirbyteChartFailure method are lost
 		err = m.diagnoseAirbyteChartFailure(ctx, err)
 		err = fmt.Errorf("unable to install airbyte chart: %w", err)
 		return trace.SpanError(span, err)
 	}
+	chartCancel(nil)
+
+	if opts.AdminPassword != "" {
+		if err := m.seedAdminPassword(ctx, opts.AdminPassword); err != nil {
+			return err
+		}
+	}
+
+	// Pods added outside the airbyte chart aren't waited on by the chart installation.
+	if len(opts.ReadinessSelectors.WaitFor) > 0 {
+		m.report(PhaseHealth, "Waiting for the selected pods to be ready")
+		if err := waitForSelectedPods(ctx, m.k8s, common.AirbyteNamespace, opts.ReadinessSelectors, opts.ComponentTimeouts); err != nil {
+			return fmt.Errorf("unable to verify the readiness of the selected pods: %w", err)
+		}
+		pterm.Success.Println("Selected pods are ready")
+	}
+
+	// The remaining jobs of the chart aren't waited on by helm, their pods never report ready either.
+	if opts.WaitJobs {
+		m.report(PhaseHealth, "Waiting for the jobs to complete")
+		if err := waitForJobs(ctx, m.k8s, common.AirbyteNamespace, jobsSince, opts.ReadinessSelectors, opts.ComponentTimeouts); err != nil {
+			var jobErr *JobFailedError
+			if errors.As(err, &jobErr) {
+				m.printJobLogs(ctx, common.AirbyteNamespace, jobErr.Job)
+			}
+			return fmt.Errorf("unable to verify the completion of the jobs: %w", err)
+		}
+		pterm.Success.Println("Jobs completed")
+	}
+
+	// Pods reporting ready isn't enough for components with startup ordering dependencies, verify their health as well.
+	m.report(PhaseHealth, "Verifying the health of the airbyte components")
+	if err := waitForComponentHealth(ctx, m.k8s, common.AirbyteNamespace, DefaultHealthProbes, opts.ComponentTimeouts, opts.ReadinessSelectors); err != nil {
+		return fmt.Errorf("unable to verify the health of the airbyte components: %w", err)
+	}
+	pterm.Success.Println("Airbyte components are healthy")
+
+	// An existing ingress controller serves the ingress of an ingress class, abctl only installs its own for the default.
+	if opts.Ingress.Class == "" {
+		if err := m.installNginx(ctx, opts); err != nil {
+			return err
+		}
+	} else {
+		pterm.Info.Printfln("Using the existing '%s' ingress controller, the nginx ingress controller is not installed", opts.Ingress.Class)
+	}
+
+	if err := m.handleIngress(ctx, opts.HelmChartVersion, opts.Hosts, opts.Ingress); err != nil {
+		return err
+	}
+	watchStop()
+
+	url := AirbyteURL(opts.Hosts, m.portHTTP, opts.Ingress)
+	if opts.Ingress.Class == "" {
+		// verify ingress using localhost
+		if err := m.verifyIngress(ctx, url, opts.ReadinessEndpoint); err != nil {
+			return err
+		}
+	} else {
+		// the address of an existing ingress controller isn't known, nor necessarily reachable from this machine
+		pterm.Info.Printfln("Skipping the verification of the ingress served by the '%s' ingress controller", opts.Ingress.Class)
+	}
+
+	if opts.VerifySync {
+		m.report(PhaseHealth, "Verifying Airbyte is able to run a sync")
+		if err := verifySync(ctx, m.k8s, common.AirbyteNamespace, DefaultSyncChecks, syncVerifyTimeout); err != nil {
+			pterm.Error.Println("Airbyte is installed, but is not able to run a sync")
+			return fmt.Errorf("%w: %w", abctl.ErrSyncVerification, err)
+		}
+		pterm.Success.Println("Airbyte is able to run a sync")
+	}
+
+	if opts.NoBrowser {
+		pterm.Success.Println(fmt.Sprintf(
+			"Launching web-browser disabled. Airbyte should be accessible at\n  %s",
+			url,
+		))
+	} else {
+		m.launch(url)
+	}
 
-	nginxValues, err := helm.BuildNginxValues(m.portHTTP)
+	m.report(PhaseInstalled, "Airbyte installed")
+	return nil
+}
+
+// installNginx installs the nginx ingress controller, which serves the ingress of airbyte on the HTTP port.
+func (m *Manager) installNginx(ctx context.Context, opts *InstallOpts) error {
+	nginxValues, err := helm.BuildNginxValues(m.portHTTP, opts.Tolerations)
 	if err != nil {
 		return err
 	}
 	pterm.Debug.Printfln("nginx values:\n%s", nginxValues)
 
 	if err := m.handleChart(ctx, chartRequest{
+		phase:          PhaseIngress,
 		name:           "nginx",
 		uninstallFirst: true,
 		repoName:       common.NginxRepoName,
@@ -286,6 +626,8 @@ func (m *Manager) Install(ctx context.Context, opts *InstallOpts) error {
 		chartRelease:   common.NginxChartRelease,
 		namespace:      common.NginxNamespace,
 		valuesYAML:     nginxValues,
+		timeout:        opts.helmTimeout(),
+		maxHistory:     opts.HelmHistoryMax,
 	}); err != nil {
 		// If we timed out, there is a good chance it's due to an unavailable port, check if this is the case.
 		// As the kubernetes client doesn't return usable error types, have to check for a specific string value.
@@ -308,29 +650,89 @@ func (m *Manager) Install(ctx context.Context, opts *InstallOpts) error {
 		return fmt.Errorf("unable to install nginx chart: %w", err)
 	}
 
-	if err := m.handleIngress(ctx, opts.HelmChartVersion, opts.Hosts); err != nil {
-		return err
+	return nil
+}
+
+// AirbyteURL returns the URL airbyte is accessible at. By default, it is served on the HTTP port of localhost.
+// Served by an existing ingress controller, it is the first of the hosts, over https if a TLS secret is provided.
+func AirbyteURL(hosts []string, port int, ingress k8s.IngressOpts) string {
+	if ingress.Class == "" {
+		return fmt.Sprintf("http://localhost:%d", port)
 	}
-	watchStop()
 
-	// verify ingress using localhost
-	url := fmt.Sprintf("http://localhost:%d", m.portHTTP)
-	if err := m.verifyIngress(ctx, url); err != nil {
-		return err
+	host := "localhost"
+	if len(hosts) > 0 {
+		host = hosts[0]
+	}
+	if ingress.TLSSecret != "" {
+		return "https://" + host
 	}
+	return "http://" + host
+}
 
-	if opts.NoBrowser {
-		pterm.Success.Println(fmt.Sprintf(
-			"Launching web-browser disabled. Airbyte should be accessible at\n  %s",
-			url,
-		))
-	} else {
-		m.launch(url)
+// checkTLSSecret returns an ErrTLSSecret error if the TLS secret of the ingress doesn't exist in the airbyte namespace.
+func (m *Manager) checkTLSSecret(ctx context.Context, name string) error {
+	if _, err := m.k8s.SecretGet(ctx, common.AirbyteNamespace, name); err != nil {
+		if k8serrors.IsNotFound(err) {
+			pterm.Error.Printfln("TLS secret '%s' not found in namespace '%s'", name, common.AirbyteNamespace)
+			return fmt.Errorf("%w: secret '%s' in namespace '%s'", abctl.ErrTLSSecret, name, common.AirbyteNamespace)
+		}
+		return fmt.Errorf("unable to get tls secret '%s': %w", name, err)
+	}
+	pterm.Success.Printfln("Found TLS secret '%s'", name)
+	return nil
+}
+
+// checkEnvFromSources returns an ErrEnvFromSource error if any of the secrets or config maps given to the platform
+// components doesn't exist in the airbyte namespace.
+func (m *Manager) checkEnvFromSources(ctx context.Context, secrets, configMaps []string) error {
+	for _, name := range secrets {
+		if _, err := m.k8s.SecretGet(ctx, common.AirbyteNamespace, name); err != nil {
+			if k8serrors.IsNotFound(err) {
+				pterm.Error.Printfln("Secret '%s' not found in namespace '%s'", name, common.AirbyteNamespace)
+				return fmt.Errorf("%w: secret '%s' in namespace '%s'", abctl.ErrEnvFromSource, name, common.AirbyteNamespace)
+			}
+			return fmt.Errorf("unable to get secret '%s': %w", name, err)
+		}
+	}
+
+	for _, name := range configMaps {
+		if _, err := m.k8s.ConfigMapGet(ctx, common.AirbyteNamespace, name); err != nil {
+			if k8serrors.IsNotFound(err) {
+				pterm.Error.Printfln("Config map '%s' not found in namespace '%s'", name, common.AirbyteNamespace)
+				return fmt.Errorf("%w: config map '%s' in namespace '%s'", abctl.ErrEnvFromSource, name, common.AirbyteNamespace)
+			}
+			return fmt.Errorf("unable to get config map '%s': %w", name, err)
+		}
 	}
 
 	return nil
 }
 
+// checkStorageClass returns an ErrStorageClass error if the storage class doesn't exist in the cluster.
+func (m *Manager) checkStorageClass(ctx context.Context, name string) error {
+	classes, err := m.k8s.StorageClassList(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list storage classes: %w", err)
+	}
+
+	var names []string
+	for _, class := range classes.Items {
+		if class.Name == name {
+			pterm.Success.Printfln("Found storage class '%s'", name)
+			return nil
+		}
+		names = append(names, class.Name)
+	}
+
+	pterm.Error.Printfln("Storage class '%s' not found", name)
+	if len(names) == 0 {
+		return fmt.Errorf("%w: '%s', the cluster has no storage classes", abctl.ErrStorageClass, name)
+	}
+	slices.Sort(names)
+	return fmt.Errorf("%w: '%s', the storage classes are: %s", abctl.ErrStorageClass, name, strings.Join(names, ", "))
+}
+
 func (m *Manager) diagnoseAirbyteChartFailure(ctx context.Context, chartErr error) error {
 	if errors.Is(ctx.Err(), context.Canceled) {
 		return chartErr
@@ -384,14 +786,14 @@ func (m *Manager) diagnoseAirbyteChartFailure(ctx context.Context, chartErr erro
 	return chartErr
 }
 
-func (m *Manager) handleIngress(ctx context.Context, chartVersion string, hosts []string) error {
+func (m *Manager) handleIngress(ctx context.Context, chartVersion string, hosts []string, opts k8s.IngressOpts) error {
 	ctx, span := trace.NewSpan(ctx, "command.handleIngress")
 	defer span.End()
-	m.spinner.UpdateText("Checking for existing Ingress")
+	m.report(PhaseIngress, "Checking for existing Ingress")
 
 	if m.k8s.IngressExists(ctx, common.AirbyteNamespace, common.AirbyteIngress) {
 		pterm.Success.Println("Found existing Ingress")
-		if err := m.k8s.IngressUpdate(ctx, common.AirbyteNamespace, k8s.Ingress(chartVersion, hosts)); err != nil {
+		if err := m.k8s.IngressUpdate(ctx, common.AirbyteNamespace, k8s.Ingress(chartVersion, hosts, opts)); err != nil {
 			pterm.Error.Printfln("Unable to update existing Ingress")
 			return fmt.Errorf("unable to update existing ingress: %w", err)
 		}
@@ -400,7 +802,7 @@ func (m *Manager) handleIngress(ctx context.Context, chartVersion string, hosts
 	}
 
 	pterm.Info.Println("No existing Ingress found, creating one")
-	if err := m.k8s.IngressCreate(ctx, common.AirbyteNamespace, k8s.Ingress(chartVersion, hosts)); err != nil {
+	if err := m.k8s.IngressCreate(ctx, common.AirbyteNamespace, k8s.Ingress(chartVersion, hosts, opts)); err != nil {
 		pterm.Error.Println("Unable to create ingress")
 		return fmt.Errorf("unable to create ingress: %w", err)
 	}
@@ -408,7 +810,9 @@ func (m *Manager) handleIngress(ctx context.Context, chartVersion string, hosts
 	return nil
 }
 
-func (m *Manager) watchEvents(ctx context.Context) {
+// watchEvents handles the events of the airbyte namespace until the ctx is done.
+// If the emitter is non-nil, the events are also displayed as they are received.
+func (m *Manager) watchEvents(ctx context.Context, emitter *eventEmitter) {
 	ctx, span := trace.NewSpan(ctx, "command.watchEvents")
 	defer span.End()
 	pterm.Debug.Println("Event watcher started.")
@@ -436,7 +840,7 @@ func (m *Manager) watchEvents(ctx context.Context) {
 			}
 			numEvents++
 			if convertedEvent, ok := event.Object.(*eventsv1.Event); ok {
-				m.handleEvent(ctx, convertedEvent)
+				m.handleEvent(ctx, convertedEvent, emitter)
 			} else {
 				pterm.Debug.Printfln("Received unexpected event: %T", event.Object)
 			}
@@ -509,12 +913,22 @@ func captureAttributes(ctx context.Context, msg string) {
 }
 
 // handleEvent converts a kubernetes event into a console log message
-func (m *Manager) handleEvent(ctx context.Context, e *eventsv1.Event) {
+func (m *Manager) handleEvent(ctx context.Context, e *eventsv1.Event, emitter *eventEmitter) {
 	// This should be replaced with series.lastObservedTime, however that field is always nil...
 	if e.DeprecatedLastTimestamp.Before(now) {
 		return
 	}
 
+	if emitter != nil {
+		if line, ok := emitter.format(e); ok {
+			if strings.EqualFold(e.Type, "warning") {
+				pterm.Warning.Printfln("Event: %s", line)
+			} else {
+				pterm.Info.Printfln("Event: %s", line)
+			}
+		}
+	}
+
 	switch {
 	case strings.EqualFold(e.Type, "normal"):
 		captureAttributes(ctx, e.Note)
@@ -586,8 +1000,64 @@ func (m *Manager) handleDockerSecret(ctx context.Context, server, user, pass, em
 	return nil
 }
 
+// handleLicenseSecret stores the airbyte enterprise license key in the license secret.
+// The license key itself is never logged.
+func (m *Manager) handleLicenseSecret(ctx context.Context, licenseKey string) error {
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: common.AirbyteNamespace,
+			Name:      common.AirbyteLicenseSecretName,
+		},
+		Data: map[string][]byte{common.AirbyteLicenseSecretKey: []byte(licenseKey)},
+		Type: corev1.SecretTypeOpaque,
+	}
+
+	if err := m.k8s.SecretCreateOrUpdate(ctx, secret); err != nil {
+		pterm.Error.Println("Unable to create the license secret")
+		return fmt.Errorf("unable to create '%s' secret: %w", common.AirbyteLicenseSecretName, err)
+	}
+	pterm.Debug.Println(fmt.Sprintf("Created '%s' secret", common.AirbyteLicenseSecretName))
+	return nil
+}
+
+// seedAdminPassword sets the admin password in the airbyte auth secret, restarting the server if the password changed.
+// The password itself is never logged.
+func (m *Manager) seedAdminPassword(ctx context.Context, password string) error {
+	secret, err := m.k8s.SecretGet(ctx, common.AirbyteNamespace, common.AirbyteAuthSecretName)
+	if err != nil {
+		pterm.Error.Println("Unable to fetch the airbyte auth secret")
+		return fmt.Errorf("unable to fetch the airbyte auth secret: %w", err)
+	}
+
+	if string(secret.Data[common.AirbyteAuthSecretPassword]) == password {
+		pterm.Debug.Println("Admin password is unchanged")
+		return nil
+	}
+
+	m.report(PhaseAirbyte, "Setting the admin password")
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[common.AirbyteAuthSecretPassword] = []byte(password)
+	if err := m.k8s.SecretCreateOrUpdate(ctx, *secret); err != nil {
+		pterm.Error.Println("Unable to set the admin password")
+		return fmt.Errorf("unable to set the admin password: %w", err)
+	}
+
+	// the server only reads the password on startup
+	if err := m.k8s.DeploymentRestart(ctx, common.AirbyteNamespace, "airbyte-abctl-server"); err != nil {
+		pterm.Error.Println("Unable to restart airbyte-abctl-server")
+		return fmt.Errorf("unable to restart airbyte-abctl-server: %w", err)
+	}
+	pterm.Success.Println("Admin password set")
+
+	return nil
+}
+
 // chartRequest exists to make all the parameters to handleChart somewhat manageable
 type chartRequest struct {
+	// phase is the phase of the progress events reported while handling the chart
+	phase          Phase
 	name           string
 	repoName       string
 	repoURL        string
@@ -598,6 +1068,10 @@ type chartRequest struct {
 	namespace      string
 	valuesYAML     string
 	uninstallFirst bool
+	// timeout is how long helm waits for the resources of the chart to be ready
+	timeout time.Duration
+	// maxHistory is how many revisions of the release helm retains, zero retains every revision
+	maxHistory int
 }
 
 // errHelmStuck is the error returned (only from a msg perspective, not this actual error) from the underlying helm
@@ -620,7 +1094,7 @@ func (m *Manager) handleChart(
 		attribute.String("chartVersion", req.chartVersion),
 	)
 
-	m.spinner.UpdateText(fmt.Sprintf("Configuring %s Helm repository", req.name))
+	m.report(req.phase, fmt.Sprintf("Configuring %s Helm repository", req.name))
 
 	if err := m.helm.AddOrUpdateChartRepo(repo.Entry{
 		Name: req.repoName,
@@ -630,7 +1104,7 @@ func (m *Manager) handleChart(
 		return fmt.Errorf("unable to add %s chart repo: %w", req.name, err)
 	}
 
-	m.spinner.UpdateText(fmt.Sprintf("Fetching %s Helm Chart with version", req.chartName))
+	m.report(req.phase, fmt.Sprintf("Fetching %s Helm Chart with version", req.chartName))
 
 	// chartLoc := m.locateChart(req.chartName, req.chartVersion, req.chartFlag)
 
@@ -679,20 +1153,22 @@ func (m *Manager) handleChart(
 			"Starting Helm Chart installation of '%s' (version: %s)",
 			req.chartName, helmChart.Metadata.Version,
 		))
-		m.spinner.UpdateText(fmt.Sprintf(
+		m.report(req.phase, fmt.Sprintf(
 			"Installing '%s' (version: %s) Helm Chart (this may take several minutes)",
 			req.chartName, helmChart.Metadata.Version,
 		))
 
+		helm.ResetNotReady()
 		helmRelease, err = m.helm.InstallOrUpgradeChart(ctx, &goHelm.ChartSpec{
 			ReleaseName:     req.chartRelease,
 			ChartName:       req.chartLoc,
 			CreateNamespace: true,
 			Namespace:       req.namespace,
 			Wait:            true,
-			Timeout:         60 * time.Minute,
+			Timeout:         req.timeout,
 			ValuesYaml:      req.valuesYAML,
 			Version:         req.chartVersion,
+			MaxHistory:      req.maxHistory,
 		},
 			&goHelm.GenericHelmOptions{},
 		)
@@ -705,6 +1181,9 @@ func (m *Manager) handleChart(
 				}
 				continue
 			}
+			if helmTimedOut(ctx, err) {
+				return helmTimeoutError(req, err)
+			}
 			pterm.Error.Printfln("Failed to install %s Helm Chart", req.chartName)
 			return fmt.Errorf("unable to install helm: %w", err)
 		}
@@ -728,56 +1207,81 @@ func (m *Manager) handleChart(
 	return nil
 }
 
-// verifyIngress will open the url in the user's browser but only if the url returns a 200 response code first
-// TODO: clean up this method, make it testable
-func (m *Manager) verifyIngress(ctx context.Context, url string) error {
-	m.spinner.UpdateText("Verifying ingress")
+// helmTimedOut returns true if the err is helm's wait for the resources of a chart exceeding its own timeout,
+// rather than the ctx being canceled, e.g. by a component exceeding its readiness timeout.
+func helmTimedOut(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	// helm doesn't consistently wrap the error of its wait, have to check for the specific string values
+	return errors.Is(err, context.DeadlineExceeded) ||
+		strings.Contains(err.Error(), context.DeadlineExceeded.Error()) ||
+		strings.Contains(err.Error(), "timed out waiting for the condition")
+}
+
+// helmTimeoutError reports the resource helm was still waiting on when its wait for the chart timed out,
+// returning an ErrHelmTimeout error.
+func helmTimeoutError(req chartRequest, err error) error {
+	waiting := helm.LastNotReady()
+	if waiting == "" {
+		pterm.Error.Printfln("Helm timed out after %s waiting for the resources of the %s Helm Chart", req.timeout, req.chartName)
+		return fmt.Errorf("%w: %s chart after %s: %w", abctl.ErrHelmTimeout, req.chartName, req.timeout, err)
+	}
+
+	pterm.Error.Printfln("Helm timed out after %s waiting for the resources of the %s Helm Chart, still waiting on:\n  %s",
+		req.timeout, req.chartName, waiting)
+	return fmt.Errorf("%w: %s chart after %s, still waiting on %s: %w", abctl.ErrHelmTimeout, req.chartName, req.timeout, waiting, err)
+}
+
+// ingressTimeout is how long verifyIngress polls the readiness endpoint before failing.
+var ingressTimeout = 1 * time.Minute
+
+// ingressPollInterval is how often verifyIngress polls the readiness endpoint.
+var ingressPollInterval = 1 * time.Second
 
-	ingressCtx, cancel := context.WithTimeout(ctx, 1*time.Minute)
+// verifyIngress polls the readiness endpoint on the url until it returns an acceptable response, so the url is only
+// opened in the user's browser once airbyte is reachable.
+func (m *Manager) verifyIngress(ctx context.Context, url string, endpoint ReadinessEndpoint) error {
+	m.report(PhaseIngress, "Verifying ingress")
+
+	ingressCtx, cancel := context.WithTimeout(ctx, ingressTimeout)
 	defer cancel()
 
-	alive := make(chan error)
+	target := endpoint.url(url)
+	pterm.Debug.Printfln("Polling %s to verify the ingress", target)
 
-	go func() {
-		tick := time.Tick(1 * time.Second)
-		for {
-			select {
-			case <-ingressCtx.Done():
-				alive <- fmt.Errorf("liveness check failed: %w", ingressCtx.Err())
-			case <-tick:
-				req, err := http.NewRequestWithContext(ingressCtx, http.MethodGet, url, nil)
-				if err != nil {
-					alive <- fmt.Errorf("unable to create request: %w", err)
-				}
-				res, _ := m.http.Do(req)
-				// if no auth, we should get a 200
-				if res != nil && res.StatusCode == http.StatusOK {
-					alive <- nil
-				}
-				// if basic auth, we should get a 401 with a specific header that contains abctl
-				if res != nil && res.StatusCode == http.StatusUnauthorized && strings.Contains(res.Header.Get("WWW-Authenticate"), "abctl") {
-					alive <- nil
-				}
-			}
-		}
-	}()
+	ticker := time.NewTicker(ingressPollInterval)
+	defer ticker.Stop()
 
-	select {
-	case <-ingressCtx.Done():
-		pterm.Error.Println("Timed out waiting for ingress")
-		return fmt.Errorf("browser liveness check failed: %w", ingressCtx.Err())
-	case err := <-alive:
-		if err != nil {
-			pterm.Error.Println("Ingress verification failed")
-			return fmt.Errorf("browser failed liveness check: %w", err)
+	for {
+		select {
+		case <-ingressCtx.Done():
+			pterm.Error.Println("Timed out waiting for ingress")
+			return fmt.Errorf("browser liveness check failed: %w", ingressCtx.Err())
+		case <-ticker.C:
+			req, err := http.NewRequestWithContext(ingressCtx, http.MethodGet, target, nil)
+			if err != nil {
+				pterm.Error.Println("Ingress verification failed")
+				return fmt.Errorf("browser failed liveness check: unable to create request: %w", err)
+			}
+			res, err := m.http.Do(req)
+			if err != nil {
+				pterm.Debug.Printfln("Ingress not yet reachable: %s", err)
+				continue
+			}
+			if res.Body != nil {
+				res.Body.Close()
+			}
+			if endpoint.accepts(res) {
+				return nil
+			}
+			pterm.Debug.Printfln("Ingress returned status %d", res.StatusCode)
 		}
 	}
-	// if we're here, then no errors occurred
-	return nil
 }
 
 func (m *Manager) launch(url string) {
-	m.spinner.UpdateText(fmt.Sprintf("Attempting to launch web-browser for %s", url))
+	m.report(PhaseLaunch, fmt.Sprintf("Attempting to launch web-browser for %s", url))
 
 	if err := m.launcher(url); err != nil {
 		pterm.Warning.Println(fmt.Sprintf(
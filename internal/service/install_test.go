@@ -1,28 +1,47 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/docker/dockertest"
 	"github.com/airbytehq/abctl/internal/helm"
 	"github.com/airbytehq/abctl/internal/helm/mock"
 	"github.com/airbytehq/abctl/internal/k8s"
 	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/airbytehq/abctl/internal/paths"
 	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/docker/docker/api/types/image"
 	"github.com/google/go-cmp/cmp"
 	goHelm "github.com/mittwald/go-helm-client"
+	"github.com/pterm/pterm"
 	"go.uber.org/mock/gomock"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 const (
@@ -32,7 +51,7 @@ const (
 
 func TestCommand_Install_HappyPath(t *testing.T) {
 	valuesYaml := mustReadFile(t, "./testdata/test-edition.values.yaml")
-	expNginxValues, _ := helm.BuildNginxValues(portTest)
+	expNginxValues, _ := helm.BuildNginxValues(portTest, nil)
 
 	// This test covers the happy path for a successful Airbyte and Nginx install.
 	ctrl := gomock.NewController(t)
@@ -132,6 +151,7 @@ func TestCommand_Install_HappyPath(t *testing.T) {
 		FnIngressExists: func(ctx context.Context, namespace string, ingress string) bool {
 			return false
 		},
+		FnPodList: healthyPodList,
 	}
 	tel := telemetry.MockClient{}
 	httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
@@ -145,6 +165,7 @@ func TestCommand_Install_HappyPath(t *testing.T) {
 		WithTelemetryClient(&tel),
 		WithHTTPClient(&httpClient),
 		WithBrowserLauncher(func(url string) error { return nil }),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -158,6 +179,14 @@ func TestCommand_Install_HappyPath(t *testing.T) {
 	}
 }
 
+// healthyPodList lists a ready pod of every component probed by the DefaultHealthProbes.
+func healthyPodList(ctx context.Context, namespace string) (*corev1.PodList, error) {
+	return &corev1.PodList{Items: []corev1.Pod{
+		testPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", true),
+		testPod("airbyte-abctl-worker-456-def", "ReplicaSet", "airbyte-abctl-worker-456", true),
+	}}, nil
+}
+
 func TestCommand_Install_BadHelmState(t *testing.T) {
 	valuesYaml := mustReadFile(t, "testdata/test-edition.values.yaml")
 
@@ -211,6 +240,7 @@ func TestCommand_Install_BadHelmState(t *testing.T) {
 		FnIngressExists: func(ctx context.Context, namespace string, ingress string) bool {
 			return false
 		},
+		FnPodList: healthyPodList,
 	}
 	tel := telemetry.MockClient{}
 	httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
@@ -224,6 +254,7 @@ func TestCommand_Install_BadHelmState(t *testing.T) {
 		WithTelemetryClient(&tel),
 		WithHTTPClient(&httpClient),
 		WithBrowserLauncher(func(url string) error { return nil }),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -237,6 +268,80 @@ func TestCommand_Install_BadHelmState(t *testing.T) {
 	}
 }
 
+func TestCommand_Install_HelmHistoryMax(t *testing.T) {
+	valuesYaml := mustReadFile(t, "testdata/test-edition.values.yaml")
+
+	tests := []struct {
+		name       string
+		historyMax int
+	}{
+		{name: "bounded", historyMax: 5},
+		{name: "unbounded", historyMax: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			helm := mock.NewMockClient(ctrl)
+			helm.EXPECT().AddOrUpdateChartRepo(gomock.Any()).AnyTimes().Return(nil)
+			helm.EXPECT().GetChart(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(name string, _ *action.ChartPathOptions) (*chart.Chart, string, error) {
+				return &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}}, "", nil
+			})
+			helm.EXPECT().GetRelease(gomock.Any()).AnyTimes().Return(nil, errors.New("not found"))
+			helm.EXPECT().UninstallReleaseByName(gomock.Any()).AnyTimes().Return(nil)
+
+			// the max history of every release, by release name
+			maxHistory := map[string]int{}
+			helm.EXPECT().InstallOrUpgradeChart(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, spec *goHelm.ChartSpec, opts *goHelm.GenericHelmOptions) (*release.Release, error) {
+				maxHistory[spec.ReleaseName] = spec.MaxHistory
+				return &release.Release{
+					Chart:     &chart.Chart{Metadata: &chart.Metadata{Version: "test.version"}},
+					Name:      spec.ReleaseName,
+					Namespace: spec.Namespace,
+				}, nil
+			}).Times(2)
+
+			k8sClient := k8stest.MockClient{
+				FnIngressExists: func(ctx context.Context, namespace string, ingress string) bool {
+					return false
+				},
+				FnPodList: healthyPodList,
+			}
+			httpClient := mockHTTP{do: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: 200}, nil
+			}}
+			svcMgr, err := NewManager(
+				k8s.TestProvider,
+				WithPortHTTP(portTest),
+				WithHelmClient(helm),
+				WithK8sClient(&k8sClient),
+				WithTelemetryClient(&telemetry.MockClient{}),
+				WithHTTPClient(&httpClient),
+				WithBrowserLauncher(func(url string) error { return nil }),
+				WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			installOpts := &InstallOpts{
+				HelmValuesYaml:  valuesYaml,
+				AirbyteChartLoc: testAirbyteChartLoc,
+				HelmHistoryMax:  tt.historyMax,
+			}
+			if err := svcMgr.Install(context.Background(), installOpts); err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[string]int{common.AirbyteChartRelease: tt.historyMax, common.NginxChartRelease: tt.historyMax}
+			if d := cmp.Diff(want, maxHistory); d != "" {
+				t.Errorf("max history mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
 func TestCommand_Install_BadHelmStatePersists(t *testing.T) {
 	valuesYaml := mustReadFile(t, "testdata/test-edition.values.yaml")
 
@@ -281,6 +386,7 @@ func TestCommand_Install_BadHelmStatePersists(t *testing.T) {
 		WithTelemetryClient(&tel),
 		WithHTTPClient(&httpClient),
 		WithBrowserLauncher(func(url string) error { return nil }),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -340,6 +446,7 @@ func TestCommand_InstallError(t *testing.T) {
 		WithTelemetryClient(&tel),
 		WithHTTPClient(&httpClient),
 		WithBrowserLauncher(func(url string) error { return nil }),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -351,6 +458,358 @@ func TestCommand_InstallError(t *testing.T) {
 	}
 }
 
+func TestCommand_Install_HelmTimeout(t *testing.T) {
+	valuesYaml := mustReadFile(t, "testdata/test-edition.values.yaml")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helmClient := mock.NewMockClient(ctrl)
+	helmClient.EXPECT().AddOrUpdateChartRepo(gomock.Any()).AnyTimes().Return(nil)
+	helmClient.EXPECT().GetChart(gomock.Any(), gomock.Any()).AnyTimes().Return(&chart.Chart{Metadata: &chart.Metadata{Version: "test.airbyte.version"}}, "", nil)
+	helmClient.EXPECT().InstallOrUpgradeChart(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, spec *goHelm.ChartSpec, opts *goHelm.GenericHelmOptions) (*release.Release, error) {
+		if d := cmp.Diff(30*time.Minute, spec.Timeout); d != "" {
+			t.Errorf("timeout mismatch (-want +got):\n%s", d)
+		}
+		return nil, errors.New("context deadline exceeded")
+	})
+
+	k8sClient := k8stest.MockClient{
+		FnIngressExists: func(ctx context.Context, namespace string, ingress string) bool {
+			return false
+		},
+		FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+			return &corev1.PodList{}, nil
+		},
+	}
+	tel := telemetry.MockClient{}
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(helmClient),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&tel),
+		WithBrowserLauncher(func(url string) error { return nil }),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	installOpts := &InstallOpts{
+		HelmValuesYaml:  valuesYaml,
+		AirbyteChartLoc: testAirbyteChartLoc,
+		HelmTimeout:     30 * time.Minute,
+	}
+	if err := svcMgr.Install(context.Background(), installOpts); !errors.Is(err, abctl.ErrHelmTimeout) {
+		t.Errorf("expected ErrHelmTimeout but got %v", err)
+	}
+}
+
+func TestCommand_Install_RestoreVolumes(t *testing.T) {
+	origData := paths.Data
+	t.Cleanup(func() { paths.Data = origData })
+	paths.Data = filepath.Join(t.TempDir(), "data")
+
+	valuesYaml := mustReadFile(t, "testdata/test-edition.values.yaml")
+	testErr := errors.New("test error")
+
+	// the volumes must be restored once they are created, but before the database is started by the chart
+	var steps []string
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	helmClient := mock.NewMockClient(ctrl)
+	helmClient.EXPECT().AddOrUpdateChartRepo(gomock.Any()).AnyTimes().Return(nil)
+	helmClient.EXPECT().GetChart(gomock.Any(), gomock.Any()).AnyTimes().Return(&chart.Chart{Metadata: &chart.Metadata{Version: "test.airbyte.version"}}, "", nil)
+	helmClient.EXPECT().InstallOrUpgradeChart(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, spec *goHelm.ChartSpec, opts *goHelm.GenericHelmOptions) (*release.Release, error) {
+		steps = append(steps, "chart "+spec.ReleaseName)
+		return nil, testErr
+	})
+
+	k8sClient := k8stest.MockClient{
+		FnPersistentVolumeExists: func(ctx context.Context, namespace, name string) bool {
+			return false
+		},
+		FnPersistentVolumeCreate: func(ctx context.Context, namespace, name, storageClass string, size resource.Quantity) error {
+			steps = append(steps, "volume "+name)
+			return nil
+		},
+		FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+			return &corev1.PodList{}, nil
+		},
+	}
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithPortHTTP(portTest),
+		WithHelmClient(helmClient),
+		WithK8sClient(&k8sClient),
+		WithTelemetryClient(&telemetry.MockClient{}),
+		WithBrowserLauncher(func(url string) error { return nil }),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	installOpts := &InstallOpts{
+		HelmValuesYaml:  valuesYaml,
+		AirbyteChartLoc: testAirbyteChartLoc,
+		RestoreVolumes: func() error {
+			steps = append(steps, "restore")
+			return nil
+		},
+	}
+	if err := svcMgr.Install(context.Background(), installOpts); !errors.Is(err, testErr) {
+		t.Fatalf("expected the test error but got %v", err)
+	}
+
+	want := []string{"volume " + paths.PvMinio, "volume " + paths.PvPsql, "restore", "chart " + common.AirbyteChartRelease}
+	if d := cmp.Diff(want, steps); d != "" {
+		t.Errorf("steps mismatch (-want +got):\n%s", d)
+	}
+
+	t.Run("restore error", func(t *testing.T) {
+		installOpts.RestoreVolumes = func() error { return testErr }
+		steps = nil
+
+		if err := svcMgr.Install(context.Background(), installOpts); !errors.Is(err, testErr) {
+			t.Fatalf("expected the test error but got %v", err)
+		}
+		if slices.ContainsFunc(steps, func(s string) bool { return strings.HasPrefix(s, "chart") }) {
+			t.Errorf("expected the chart to not be installed, got steps %v", steps)
+		}
+	})
+}
+
+func TestHelmTimedOut(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{name: "deadline exceeded", ctx: context.Background(), err: context.DeadlineExceeded, want: true},
+		{name: "wrapped deadline message", ctx: context.Background(), err: errors.New("release airbyte-abctl failed: context deadline exceeded"), want: true},
+		{name: "condition timeout", ctx: context.Background(), err: errors.New("timed out waiting for the condition"), want: true},
+		{name: "canceled", ctx: canceled, err: errors.New("context canceled")},
+		{name: "canceled deadline", ctx: canceled, err: context.DeadlineExceeded},
+		{name: "other error", ctx: context.Background(), err: errors.New("test error")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := helmTimedOut(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("expected %t but got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestManager_LoadConnectorImages(t *testing.T) {
+	images := []string{"airbyte/source-postgres:3.6.0", "airbyte/source-faker:6.2.0"}
+
+	var requested []string
+	cluster := &mockCluster{loadImages: func(ctx context.Context, dockerClient docker.Client, images []string) {
+		requested = images
+	}}
+
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8stest.MockClient{}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithTelemetryClient(&telemetry.MockClient{}),
+		WithImageManifest(filepath.Join(t.TempDir(), "images.json")),
+		WithDockerClient(&docker.Docker{Client: dockertest.MockClient{
+			FnImageList: func(ctx context.Context, options image.ListOptions) ([]image.Summary, error) {
+				// only the postgres source could be pulled
+				return []image.Summary{{RepoTags: []string{"airbyte/source-postgres:3.6.0"}}}, nil
+			},
+		}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := svcMgr.LoadConnectorImages(context.Background(), cluster, images)
+	if d := cmp.Diff(images, requested); d != "" {
+		t.Errorf("requested images mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff([]string{"airbyte/source-postgres:3.6.0"}, loaded); d != "" {
+		t.Errorf("loaded images mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestManager_LoadConnectorImages_NoDocker(t *testing.T) {
+	cluster := &mockCluster{loadImages: func(ctx context.Context, dockerClient docker.Client, images []string) {
+		t.Error("unexpected images load without a docker client")
+	}}
+
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8stest.MockClient{}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithTelemetryClient(&telemetry.MockClient{}),
+		WithImageManifest(filepath.Join(t.TempDir(), "images.json")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded := svcMgr.LoadConnectorImages(context.Background(), cluster, []string{"airbyte/source-faker:6.2.0"}); len(loaded) != 0 {
+		t.Errorf("expected no loaded images, got %v", loaded)
+	}
+	if missing := missingImages(context.Background(), nil, []string{"airbyte/source-faker:6.2.0"}); len(missing) != 0 {
+		t.Errorf("expected no missing images, got %v", missing)
+	}
+	svcMgr.trackPulledImages(context.Background(), []string{"airbyte/source-faker:6.2.0"})
+}
+
+func TestManager_SeedAdminPassword(t *testing.T) {
+	const password = "correct-horse-battery-staple"
+
+	tests := []struct {
+		name        string
+		existing    string
+		wantUpdate  bool
+		wantRestart bool
+	}{
+		{name: "generated password", existing: "generated", wantUpdate: true, wantRestart: true},
+		{name: "same password", existing: password},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			pterm.SetDefaultOutput(&out)
+			pterm.EnableDebugMessages()
+			t.Cleanup(func() {
+				pterm.SetDefaultOutput(os.Stdout)
+				pterm.DisableDebugMessages()
+			})
+
+			var updated *corev1.Secret
+			var restarted string
+			k8sClient := &k8stest.MockClient{
+				FnSecretGet: func(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+					if namespace != common.AirbyteNamespace || name != common.AirbyteAuthSecretName {
+						t.Errorf("unexpected secret %s/%s", namespace, name)
+					}
+					return &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+						Data: map[string][]byte{
+							common.AirbyteAuthSecretPassword: []byte(tt.existing),
+							"instance-admin-client-id":       []byte("client-id"),
+						},
+					}, nil
+				},
+				FnSecretCreateOrUpdate: func(ctx context.Context, secret corev1.Secret) error {
+					updated = &secret
+					return nil
+				},
+				FnDeploymentRestart: func(ctx context.Context, namespace, name string) error {
+					restarted = name
+					return nil
+				},
+			}
+
+			svcMgr, err := NewManager(
+				k8s.TestProvider,
+				WithK8sClient(k8sClient),
+				WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+				WithTelemetryClient(&telemetry.MockClient{}),
+				WithSpinner(&pterm.SpinnerPrinter{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := svcMgr.seedAdminPassword(context.Background(), password); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if tt.wantUpdate {
+				if updated == nil {
+					t.Fatal("expected the secret to be updated")
+				}
+				if d := cmp.Diff(password, string(updated.Data[common.AirbyteAuthSecretPassword])); d != "" {
+					t.Errorf("password mismatch (-want +got):\n%s", d)
+				}
+				// the other credentials must be preserved
+				if d := cmp.Diff("client-id", string(updated.Data["instance-admin-client-id"])); d != "" {
+					t.Errorf("client-id mismatch (-want +got):\n%s", d)
+				}
+			} else if updated != nil {
+				t.Error("expected the secret to not be updated")
+			}
+
+			wantRestarted := ""
+			if tt.wantRestart {
+				wantRestarted = "airbyte-abctl-server"
+			}
+			if d := cmp.Diff(wantRestarted, restarted); d != "" {
+				t.Errorf("restart mismatch (-want +got):\n%s", d)
+			}
+
+			if bytes.Contains(out.Bytes(), []byte(password)) {
+				t.Errorf("password was logged:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestCheckVolumeShrink(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  string
+		requested string
+		force     bool
+		wantErr   error
+	}{
+		{name: "same size", existing: "500Mi", requested: "500Mi"},
+		{name: "same size, different units", existing: "1Gi", requested: "1024Mi"},
+		{name: "grow", existing: "500Mi", requested: "10Gi"},
+		{name: "shrink", existing: "10Gi", requested: "500Mi", wantErr: abctl.ErrVolumeShrink},
+		{name: "shrink forced", existing: "10Gi", requested: "500Mi", force: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkVolumeShrink("pvc", resource.MustParse(tt.existing), resource.MustParse(tt.requested), tt.force)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestInstallOpts_DataVolumeSize(t *testing.T) {
+	opts := InstallOpts{}
+	if d := cmp.Diff(k8s.DefaultPersistentVolumeSize, opts.dataVolumeSize()); d != "" {
+		t.Errorf("size mismatch (-want +got):\n%s", d)
+	}
+
+	opts.DataVolumeSize = resource.MustParse("20Gi")
+	if d := cmp.Diff(resource.MustParse("20Gi"), opts.dataVolumeSize()); d != "" {
+		t.Errorf("size mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestInstallOpts_HelmTimeout(t *testing.T) {
+	opts := InstallOpts{}
+	if d := cmp.Diff(DefaultHelmTimeout, opts.helmTimeout()); d != "" {
+		t.Errorf("timeout mismatch (-want +got):\n%s", d)
+	}
+
+	opts.HelmTimeout = 15 * time.Minute
+	if d := cmp.Diff(15*time.Minute, opts.helmTimeout()); d != "" {
+		t.Errorf("timeout mismatch (-want +got):\n%s", d)
+	}
+}
+
 func mustReadFile(t *testing.T, name string) string {
 	t.Helper()
 	b, err := os.ReadFile(name)
@@ -359,3 +818,281 @@ func mustReadFile(t *testing.T, name string) string {
 	}
 	return string(b)
 }
+
+func TestManager_HandleLicenseSecret(t *testing.T) {
+	var created *corev1.Secret
+	k8sClient := &k8stest.MockClient{
+		FnSecretCreateOrUpdate: func(ctx context.Context, secret corev1.Secret) error {
+			created = &secret
+			return nil
+		},
+	}
+
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(k8sClient),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithTelemetryClient(&telemetry.MockClient{}),
+		WithSpinner(&pterm.SpinnerPrinter{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svcMgr.handleLicenseSecret(context.Background(), "license"); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	want := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: common.AirbyteNamespace, Name: common.AirbyteLicenseSecretName},
+		Data:       map[string][]byte{common.AirbyteLicenseSecretKey: []byte("license")},
+		Type:       corev1.SecretTypeOpaque,
+	}
+	if d := cmp.Diff(want, created); d != "" {
+		t.Errorf("secret mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestAirbyteURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   []string
+		ingress k8s.IngressOpts
+		want    string
+	}{
+		{name: "defaults", want: "http://localhost:8000"},
+		{name: "defaults with hosts", hosts: []string{"airbyte.example.com"}, want: "http://localhost:8000"},
+		{name: "ingress class", hosts: []string{"airbyte.example.com"}, ingress: k8s.IngressOpts{Class: "traefik"}, want: "http://airbyte.example.com"},
+		{name: "ingress class without hosts", ingress: k8s.IngressOpts{Class: "traefik"}, want: "http://localhost"},
+		{
+			name:    "tls secret",
+			hosts:   []string{"airbyte.example.com", "airbyte.internal"},
+			ingress: k8s.IngressOpts{Class: "traefik", TLSSecret: "airbyte-tls"},
+			want:    "https://airbyte.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, AirbyteURL(tt.hosts, 8000, tt.ingress)); d != "" {
+				t.Errorf("url mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestManager_CheckTLSSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "exists"},
+		{
+			name:    "not found",
+			err:     k8serrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "airbyte-tls"),
+			wantErr: abctl.ErrTLSSecret,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k8sClient := &k8stest.MockClient{
+				FnSecretGet: func(ctx context.Context, namespace, name string) (*corev1.Secret, error) {
+					if d := cmp.Diff(common.AirbyteNamespace+"/airbyte-tls", namespace+"/"+name); d != "" {
+						t.Errorf("secret mismatch (-want +got):\n%s", d)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &corev1.Secret{}, nil
+				},
+			}
+
+			svcMgr, err := NewManager(
+				k8s.TestProvider,
+				WithK8sClient(k8sClient),
+				WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+				WithTelemetryClient(&telemetry.MockClient{}),
+				WithSpinner(&pterm.SpinnerPrinter{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = svcMgr.checkTLSSecret(context.Background(), "airbyte-tls")
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v but got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestManager_CheckStorageClass(t *testing.T) {
+	tests := []struct {
+		name    string
+		classes []runtime.Object
+		wantErr string
+	}{
+		{
+			name:    "exists",
+			classes: []runtime.Object{&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}}, &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gp3"}}},
+		},
+		{
+			name:    "not found",
+			classes: []runtime.Object{&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}}, &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gp2"}}},
+			wantErr: "storage class not found: 'gp3', the storage classes are: gp2, standard",
+		},
+		{
+			name:    "no storage classes",
+			wantErr: "storage class not found: 'gp3', the cluster has no storage classes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svcMgr, err := NewManager(
+				k8s.TestProvider,
+				WithK8sClient(&k8s.DefaultK8sClient{ClientSet: fake.NewSimpleClientset(tt.classes...)}),
+				WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+				WithTelemetryClient(&telemetry.MockClient{}),
+				WithSpinner(&pterm.SpinnerPrinter{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = svcMgr.checkStorageClass(context.Background(), "gp3")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, abctl.ErrStorageClass) {
+				t.Errorf("expected ErrStorageClass but got %v", err)
+			}
+			if d := cmp.Diff(tt.wantErr, fmt.Sprint(err)); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestManager_CheckEnvFromSources(t *testing.T) {
+	objects := []runtime.Object{
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-creds", Namespace: common.AirbyteNamespace}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-config", Namespace: common.AirbyteNamespace}},
+		// in another namespace, so not found
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other-creds", Namespace: "default"}},
+	}
+
+	tests := []struct {
+		name       string
+		secrets    []string
+		configMaps []string
+		wantErr    string
+	}{
+		{name: "none"},
+		{
+			name:       "exist",
+			secrets:    []string{"airbyte-creds"},
+			configMaps: []string{"airbyte-config"},
+		},
+		{
+			name:    "secret not found",
+			secrets: []string{"airbyte-creds", "other-creds"},
+			wantErr: "env source not found: secret 'other-creds' in namespace 'airbyte-abctl'",
+		},
+		{
+			name:       "config map not found",
+			configMaps: []string{"airbyte-creds"},
+			wantErr:    "env source not found: config map 'airbyte-creds' in namespace 'airbyte-abctl'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svcMgr, err := NewManager(
+				k8s.TestProvider,
+				WithK8sClient(&k8s.DefaultK8sClient{ClientSet: fake.NewSimpleClientset(objects...)}),
+				WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+				WithTelemetryClient(&telemetry.MockClient{}),
+				WithSpinner(&pterm.SpinnerPrinter{}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = svcMgr.checkEnvFromSources(context.Background(), tt.secrets, tt.configMaps)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, abctl.ErrEnvFromSource) {
+				t.Errorf("expected ErrEnvFromSource but got %v", err)
+			}
+			if d := cmp.Diff(tt.wantErr, fmt.Sprint(err)); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestManager_VerifyIngress(t *testing.T) {
+	origTimeout, origInterval := ingressTimeout, ingressPollInterval
+	t.Cleanup(func() {
+		ingressTimeout = origTimeout
+		ingressPollInterval = origInterval
+	})
+	ingressTimeout = 500 * time.Millisecond
+	ingressPollInterval = 10 * time.Millisecond
+
+	// the health path only becomes ready on the third request, the root path never does
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8stest.MockClient{}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithHTTPClient(srv.Client()),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("specified status", func(t *testing.T) {
+		endpoint := ReadinessEndpoint{Path: "/api/v1/health", Statuses: []int{http.StatusNoContent}}
+		if err := svcMgr.verifyIngress(context.Background(), srv.URL, endpoint); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+		if d := cmp.Diff(int32(3), requests.Load()); d != "" {
+			t.Errorf("requests mismatch (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		err := svcMgr.verifyIngress(context.Background(), srv.URL, ReadinessEndpoint{})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected a deadline exceeded error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/airbyte"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/trace"
+	"github.com/pterm/pterm"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// JobFailedError is returned when a job did not complete successfully.
+type JobFailedError struct {
+	Job    string
+	Reason string
+}
+
+func (e *JobFailedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("job '%s' failed", e.Job)
+	}
+	return fmt.Sprintf("job '%s' failed: %s", e.Job, e.Reason)
+}
+
+// jobCondition returns the condition of the job, false if the job doesn't report it as true.
+func jobCondition(job batchv1.Job, condType batchv1.JobConditionType) (batchv1.JobCondition, bool) {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == condType && cond.Status == corev1.ConditionTrue {
+			return cond, true
+		}
+	}
+	return batchv1.JobCondition{}, false
+}
+
+// jobComplete returns true if the job ran to completion.
+func jobComplete(job batchv1.Job) bool {
+	_, ok := jobCondition(job, batchv1.JobComplete)
+	return ok
+}
+
+// jobFailure returns the reason the job failed, false if it hasn't failed (yet).
+func jobFailure(job batchv1.Job) (string, bool) {
+	cond, ok := jobCondition(job, batchv1.JobFailed)
+	if !ok {
+		return "", false
+	}
+	if cond.Message != "" {
+		return fmt.Sprintf("%s: %s", cond.Reason, cond.Message), true
+	}
+	return cond.Reason, true
+}
+
+// jobComponent returns the name the timeout of the job is looked up by, the job name with the release prefix removed.
+func jobComponent(job string) string {
+	job = strings.TrimPrefix(job, "airbyte-abctl-")
+	return strings.TrimPrefix(job, "airbyte-")
+}
+
+// jobIgnored returns true if the job is selected by any of the Ignore selectors.
+func (s ReadinessSelectors) jobIgnored(job batchv1.Job) bool {
+	return slices.ContainsFunc(s.Ignore, func(sel k8s.LabelSelector) bool { return sel.Matches(job.Labels) })
+}
+
+// checkJobs returns the names of the jobs which are yet to complete, or a JobFailedError for the first failed job.
+// Jobs created before since (e.g. by a previous install) and the ignored jobs are skipped, as helm only recreates
+// the jobs which changed.
+func checkJobs(jobs []batchv1.Job, since time.Time, selectors ReadinessSelectors) ([]string, error) {
+	// creation timestamps only have a precision of seconds
+	since = since.Truncate(time.Second)
+
+	var pending []string
+	for _, job := range jobs {
+		if job.CreationTimestamp.Time.Before(since) || selectors.jobIgnored(job) {
+			continue
+		}
+		if reason, ok := jobFailure(job); ok {
+			return nil, &JobFailedError{Job: job.Name, Reason: reason}
+		}
+		if !jobComplete(job) {
+			pending = append(pending, job.Name)
+		}
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
+
+// waitForJobs waits until every job in the namespace created since the install started, and which isn't ignored,
+// has completed. If a job fails, a JobFailedError is returned. If a job doesn't complete within its timeout,
+// a ComponentTimeoutError for the job is returned.
+func waitForJobs(ctx context.Context, client k8s.Client, namespace string, since time.Time, selectors ReadinessSelectors, timeouts ComponentTimeouts) error {
+	firstSeen := map[string]time.Time{}
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	for {
+		jobs, err := client.JobList(ctx, namespace)
+		if err != nil {
+			pterm.Debug.Printfln("Unable to list jobs in namespace '%s': %s", namespace, err)
+		} else {
+			pending, err := checkJobs(jobs.Items, since, selectors)
+			if err != nil {
+				pterm.Error.Println(err.Error())
+				return err
+			}
+			if len(pending) == 0 {
+				return nil
+			}
+
+			now := time.Now()
+			for _, name := range pending {
+				if _, ok := firstSeen[name]; !ok {
+					firstSeen[name] = now
+				}
+				timeout := timeouts.For(jobComponent(name))
+				if now.Sub(firstSeen[name]) > timeout {
+					pterm.Error.Printfln("Job '%s' did not complete within %s", name, timeout)
+					return &ComponentTimeoutError{Component: name, Timeout: timeout}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// failedJob returns a JobFailedError if any job in the namespace created since the install started has failed,
+// e.g. a helm hook failing the chart installation, printing the logs of its pods.
+func (m *Manager) failedJob(ctx context.Context, namespace string, since time.Time, selectors ReadinessSelectors) error {
+	jobs, err := m.k8s.JobList(ctx, namespace)
+	if err != nil {
+		pterm.Debug.Printfln("Unable to list jobs in namespace '%s': %s", namespace, err)
+		return nil
+	}
+
+	_, err = checkJobs(jobs.Items, since, selectors)
+	var jobErr *JobFailedError
+	if !errors.As(err, &jobErr) {
+		return nil
+	}
+	pterm.Error.Println(jobErr.Error())
+	m.printJobLogs(ctx, namespace, jobErr.Job)
+	return jobErr
+}
+
+// jobPod returns true if the pod was created by the job.
+func jobPod(pod corev1.Pod, job string) bool {
+	return slices.ContainsFunc(pod.OwnerReferences, func(owner metav1.OwnerReference) bool {
+		return owner.Kind == "Job" && owner.Name == job
+	})
+}
+
+// printJobLogs prints the logs of the pods of the failed job, so the cause of the failure is visible without
+// having to retrieve them from the cluster. The logs are also attached to the trace.
+func (m *Manager) printJobLogs(ctx context.Context, namespace, job string) {
+	pods, err := m.k8s.PodList(ctx, namespace)
+	if err != nil {
+		pterm.Debug.Printfln("Unable to list the pods of job '%s': %s", job, err)
+		return
+	}
+
+	for _, pod := range pods.Items {
+		if !jobPod(pod, job) {
+			continue
+		}
+
+		logs, err := m.k8s.LogsGet(ctx, namespace, pod.Name)
+		if err != nil {
+			pterm.Debug.Printfln("Unable to get the logs of pod '%s': %s", pod.Name, err)
+			continue
+		}
+		trace.AttachLog(fmt.Sprintf("%s.log", pod.Name), logs)
+
+		pterm.Info.Printfln("Logs of pod '%s' of the failed job '%s':", pod.Name, job)
+		s := airbyte.NewLogScanner(strings.NewReader(logs))
+		for s.Scan() {
+			if s.Line.Level == "ERROR" {
+				pterm.Error.Printfln("%s: %s", pod.Name, s.Line.Message)
+			} else {
+				pterm.Info.Printfln("%s: %s", pod.Name, s.Line.Message)
+			}
+		}
+		if err := s.Err(); err != nil {
+			pterm.Debug.Printfln("Unable to read the logs of pod '%s': %s", pod.Name, err)
+		}
+	}
+}
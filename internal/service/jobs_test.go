@@ -0,0 +1,208 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/helm/mock"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	"go.uber.org/mock/gomock"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testJob(name string, created time.Time, labels map[string]string, conds ...batchv1.JobCondition) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         common.AirbyteNamespace,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Status: batchv1.JobStatus{Conditions: conds},
+	}
+}
+
+var (
+	jobCompleteCond = batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}
+	jobFailedCond   = batchv1.JobCondition{
+		Type:    batchv1.JobFailed,
+		Status:  corev1.ConditionTrue,
+		Reason:  "BackoffLimitExceeded",
+		Message: "Job has reached the specified backoff limit",
+	}
+)
+
+func TestCheckJobs(t *testing.T) {
+	since := time.Now()
+	selectors := ReadinessSelectors{Ignore: []k8s.LabelSelector{{Key: "app", Value: "optional"}}}
+
+	tests := []struct {
+		name        string
+		jobs        []*batchv1.Job
+		wantPending []string
+		wantErr     error
+	}{
+		{
+			name: "no jobs",
+		},
+		{
+			name: "completed",
+			jobs: []*batchv1.Job{testJob("airbyte-abctl-seed", since, nil, jobCompleteCond)},
+		},
+		{
+			name: "pending",
+			jobs: []*batchv1.Job{
+				testJob("airbyte-abctl-seed", since, nil, batchv1.JobCondition{Type: batchv1.JobComplete, Status: corev1.ConditionFalse}),
+				testJob("airbyte-abctl-migrate", since, nil),
+				testJob("airbyte-abctl-done", since, nil, jobCompleteCond),
+			},
+			wantPending: []string{"airbyte-abctl-migrate", "airbyte-abctl-seed"},
+		},
+		{
+			name:    "failed",
+			jobs:    []*batchv1.Job{testJob("airbyte-abctl-migrate", since, nil), testJob("airbyte-abctl-seed", since, nil, jobFailedCond)},
+			wantErr: &JobFailedError{Job: "airbyte-abctl-seed", Reason: "BackoffLimitExceeded: Job has reached the specified backoff limit"},
+		},
+		{
+			name: "created by a previous install",
+			jobs: []*batchv1.Job{testJob("airbyte-abctl-seed", since.Add(-time.Hour), nil, jobFailedCond)},
+		},
+		{
+			name: "ignored",
+			jobs: []*batchv1.Job{testJob("optional", since, map[string]string{"app": "optional"})},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var jobs []batchv1.Job
+			for _, job := range tt.jobs {
+				jobs = append(jobs, *job)
+			}
+
+			pending, err := checkJobs(jobs, since, selectors)
+			if d := cmp.Diff(fmt.Sprint(tt.wantErr), fmt.Sprint(err)); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff(tt.wantPending, pending); d != "" {
+				t.Errorf("pending mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestWaitForJobs(t *testing.T) {
+	setReadinessPollInterval(t, 10*time.Millisecond)
+
+	since := time.Now()
+	timeouts := ComponentTimeouts{Defaults: map[ComponentKind]time.Duration{ComponentService: 50 * time.Millisecond}}
+
+	tests := []struct {
+		name    string
+		job     *batchv1.Job
+		wantErr error
+	}{
+		{
+			name: "completed",
+			job:  testJob("airbyte-abctl-seed", since, nil, jobCompleteCond),
+		},
+		{
+			name:    "failed",
+			job:     testJob("airbyte-abctl-seed", since, nil, jobFailedCond),
+			wantErr: &JobFailedError{Job: "airbyte-abctl-seed", Reason: "BackoffLimitExceeded: Job has reached the specified backoff limit"},
+		},
+		{
+			name:    "not completed",
+			job:     testJob("airbyte-abctl-seed", since, nil),
+			wantErr: &ComponentTimeoutError{Component: "airbyte-abctl-seed", Timeout: 50 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &k8s.DefaultK8sClient{ClientSet: fake.NewSimpleClientset(tt.job)}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			err := waitForJobs(ctx, client, common.AirbyteNamespace, since, ReadinessSelectors{}, timeouts)
+			if d := cmp.Diff(fmt.Sprint(tt.wantErr), fmt.Sprint(err)); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestFailedJob_PrintsLogs(t *testing.T) {
+	var out bytes.Buffer
+	pterm.SetDefaultOutput(&out)
+	t.Cleanup(func() { pterm.SetDefaultOutput(os.Stdout) })
+
+	since := time.Now()
+	cs := fake.NewSimpleClientset(
+		testJob("airbyte-abctl-seed", since, nil, jobFailedCond),
+		labeledPod("airbyte-abctl-seed-abc", "Job", "airbyte-abctl-seed", nil, false),
+		labeledPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", nil, true),
+	)
+
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8s.DefaultK8sClient{ClientSet: cs}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithTelemetryClient(&telemetry.MockClient{}),
+		WithSpinner(&pterm.SpinnerPrinter{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = svcMgr.failedJob(context.Background(), common.AirbyteNamespace, since, ReadinessSelectors{})
+	var jobErr *JobFailedError
+	if !errors.As(err, &jobErr) {
+		t.Fatalf("expected JobFailedError but got %v", err)
+	}
+	if d := cmp.Diff("airbyte-abctl-seed", jobErr.Job); d != "" {
+		t.Errorf("job mismatch (-want +got):\n%s", d)
+	}
+
+	// the fake clientset returns "fake logs" as the logs of every pod
+	if !strings.Contains(out.String(), "airbyte-abctl-seed-abc: fake logs") {
+		t.Errorf("expected the logs of the job pod to be printed, got:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "airbyte-abctl-server-123-abc") {
+		t.Errorf("expected only the logs of the job pod to be printed, got:\n%s", out.String())
+	}
+}
+
+func TestFailedJob_NoneFailed(t *testing.T) {
+	since := time.Now()
+	cs := fake.NewSimpleClientset(testJob("airbyte-abctl-seed", since, nil, jobCompleteCond))
+
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8s.DefaultK8sClient{ClientSet: cs}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithTelemetryClient(&telemetry.MockClient{}),
+		WithSpinner(&pterm.SpinnerPrinter{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svcMgr.failedJob(context.Background(), common.AirbyteNamespace, since, ReadinessSelectors{}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pterm/pterm"
+)
+
+// maxJournalSize is the size, in bytes, the journal is capped at. Once exceeded, the oldest entries are trimmed.
+const maxJournalSize = 256 * 1024
+
+// The operations recorded in the journal.
+const (
+	OperationInstall   = "install"
+	OperationUninstall = "uninstall"
+)
+
+// The events of a journal entry.
+const (
+	JournalStart = "start"
+	JournalEnd   = "end"
+)
+
+// The outcomes of an ended phase or operation.
+const (
+	JournalSucceeded = "succeeded"
+	JournalFailed    = "failed"
+)
+
+// JournalEntry is a single line of the journal, recording the start or end of an operation or one of its phases.
+// Entries of the operation itself have no Phase.
+type JournalEntry struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Phase     Phase     `json:"phase,omitempty"`
+	Event     string    `json:"event"`
+	Outcome   string    `json:"outcome,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// journal is an append-only JSONL record of the phases of the Manager's operations, with their timestamps and
+// outcome, to diagnose where long operations spend their time without a trace collector.
+// Recording is best effort, failing to write the journal never fails the operation.
+type journal struct {
+	path    string
+	maxSize int64
+	now     func() time.Time
+
+	operation string
+	phase     Phase
+}
+
+func newJournal(path string) *journal {
+	return &journal{path: path, maxSize: maxJournalSize, now: time.Now}
+}
+
+// begin records the start of the operation.
+func (j *journal) begin(operation string) {
+	if j == nil {
+		return
+	}
+	j.operation, j.phase = operation, ""
+	j.record(JournalEntry{Operation: operation, Event: JournalStart})
+}
+
+// enter records the start of the phase, ending the current phase first. Entering the current phase again,
+// as happens when it reports progress more than once, records nothing.
+func (j *journal) enter(phase Phase) {
+	if j == nil || j.operation == "" || phase == j.phase {
+		return
+	}
+	if j.phase != "" {
+		j.record(JournalEntry{Operation: j.operation, Phase: j.phase, Event: JournalEnd, Outcome: JournalSucceeded})
+	}
+	j.phase = phase
+	j.record(JournalEntry{Operation: j.operation, Phase: phase, Event: JournalStart})
+}
+
+// finish records the end of the current phase and of the operation, which failed if err isn't nil.
+func (j *journal) finish(err error) {
+	if j == nil || j.operation == "" {
+		return
+	}
+	end := JournalEntry{Operation: j.operation, Event: JournalEnd, Outcome: JournalSucceeded}
+	if err != nil {
+		end.Outcome, end.Error = JournalFailed, err.Error()
+	}
+	if j.phase != "" {
+		phaseEnd := end
+		phaseEnd.Phase = j.phase
+		j.record(phaseEnd)
+	}
+	j.record(end)
+	j.operation, j.phase = "", ""
+}
+
+// record appends the entry, timestamped now, to the journal.
+func (j *journal) record(e JournalEntry) {
+	e.Time = j.now().UTC()
+	if err := j.append(e); err != nil {
+		pterm.Debug.Printfln("Unable to write the journal '%s': %s", j.path, err)
+	}
+}
+
+func (j *journal) append(e JournalEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("unable to marshal journal entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return fmt.Errorf("unable to create journal directory: %w", err)
+	}
+	if fi, err := os.Stat(j.path); err == nil && fi.Size()+int64(len(line)) > j.maxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open journal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("unable to append to journal: %w", err)
+	}
+	return nil
+}
+
+// rotate trims the oldest entries of the journal, keeping the newest entries which fit within half its maximum size,
+// so that it isn't rotated again on every append.
+func (j *journal) rotate() error {
+	raw, err := os.ReadFile(j.path)
+	if err != nil {
+		return fmt.Errorf("unable to read journal: %w", err)
+	}
+
+	lines := bytes.SplitAfter(raw, []byte("\n"))
+	keep, size := len(lines), int64(0)
+	for keep > 0 && size+int64(len(lines[keep-1])) <= j.maxSize/2 {
+		keep--
+		size += int64(len(lines[keep]))
+	}
+
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, bytes.Join(lines[keep:], nil), 0o644); err != nil {
+		return fmt.Errorf("unable to rotate journal: %w", err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("unable to rotate journal: %w", err)
+	}
+	return nil
+}
+
+// ReadJournal returns the entries of the journal at path, oldest first, none if the journal doesn't exist.
+// Lines which aren't valid entries, e.g. one partially written when abctl was killed, are skipped.
+func ReadJournal(path string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read journal: %w", err)
+	}
+	return entries, nil
+}
+
+// TimelineSpan is how long an operation, or one of its phases, ran.
+type TimelineSpan struct {
+	Operation string
+	// Phase is empty for the span of the operation itself.
+	Phase    Phase
+	Start    time.Time
+	Duration time.Duration
+	// Outcome is empty if the span never ended, e.g. the operation was interrupted.
+	Outcome string
+}
+
+// Timeline pairs the start and end entries of the journal into spans, in the order they started.
+func Timeline(entries []JournalEntry) []TimelineSpan {
+	type key struct {
+		operation string
+		phase     Phase
+	}
+
+	var spans []TimelineSpan
+	open := map[key]int{}
+	for _, e := range entries {
+		k := key{operation: e.Operation, phase: e.Phase}
+		switch e.Event {
+		case JournalStart:
+			// an operation starting again leaves the spans of the previous run unended
+			if e.Phase == "" {
+				for k := range open {
+					if k.operation == e.Operation {
+						delete(open, k)
+					}
+				}
+			}
+			open[k] = len(spans)
+			spans = append(spans, TimelineSpan{Operation: e.Operation, Phase: e.Phase, Start: e.Time})
+		case JournalEnd:
+			i, ok := open[k]
+			if !ok {
+				continue
+			}
+			delete(open, k)
+			spans[i].Duration = e.Time.Sub(spans[i].Start)
+			spans[i].Outcome = e.Outcome
+		}
+	}
+	return spans
+}
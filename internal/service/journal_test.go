@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/helm/mock"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+var journalStart = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// testJournal returns a journal in a temporary directory whose clock advances a second with every entry.
+func testJournal(t *testing.T) *journal {
+	j := newJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	now := journalStart
+	j.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return j
+}
+
+func at(seconds int) time.Time {
+	return journalStart.Add(time.Duration(seconds) * time.Second)
+}
+
+func TestJournal_Phases(t *testing.T) {
+	j := testJournal(t)
+
+	j.begin(OperationInstall)
+	j.enter(PhaseNamespace)
+	j.enter(PhaseSecrets)
+	// reporting the same phase again is not a new phase
+	j.enter(PhaseSecrets)
+	j.enter(PhaseAirbyte)
+	j.finish(errors.New("chart timed out"))
+
+	entries, err := ReadJournal(j.path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	want := []JournalEntry{
+		{Time: at(1), Operation: OperationInstall, Event: JournalStart},
+		{Time: at(2), Operation: OperationInstall, Phase: PhaseNamespace, Event: JournalStart},
+		{Time: at(3), Operation: OperationInstall, Phase: PhaseNamespace, Event: JournalEnd, Outcome: JournalSucceeded},
+		{Time: at(4), Operation: OperationInstall, Phase: PhaseSecrets, Event: JournalStart},
+		{Time: at(5), Operation: OperationInstall, Phase: PhaseSecrets, Event: JournalEnd, Outcome: JournalSucceeded},
+		{Time: at(6), Operation: OperationInstall, Phase: PhaseAirbyte, Event: JournalStart},
+		{Time: at(7), Operation: OperationInstall, Phase: PhaseAirbyte, Event: JournalEnd, Outcome: JournalFailed, Error: "chart timed out"},
+		{Time: at(8), Operation: OperationInstall, Event: JournalEnd, Outcome: JournalFailed, Error: "chart timed out"},
+	}
+	if d := cmp.Diff(want, entries); d != "" {
+		t.Errorf("entries mismatch (-want +got):\n%s", d)
+	}
+
+	// phases reported outside of an operation aren't journaled
+	j.enter(PhaseStatus)
+	if entries, _ := ReadJournal(j.path); len(entries) != len(want) {
+		t.Errorf("expected %d entries, got %d", len(want), len(entries))
+	}
+}
+
+func TestJournal_Rotate(t *testing.T) {
+	j := testJournal(t)
+	j.maxSize = 1024
+
+	for i := 0; i < 50; i++ {
+		j.begin(OperationUninstall)
+		j.finish(nil)
+	}
+
+	fi, err := os.Stat(j.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() > j.maxSize {
+		t.Errorf("expected the journal to be at most %d bytes, got %d", j.maxSize, fi.Size())
+	}
+
+	entries, err := ReadJournal(j.path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(entries) == 0 || len(entries) >= 100 {
+		t.Fatalf("expected the oldest entries to be trimmed, got %d entries", len(entries))
+	}
+	// only the oldest entries are trimmed, the newest is kept
+	if d := cmp.Diff(JournalEntry{Time: at(100), Operation: OperationUninstall, Event: JournalEnd, Outcome: JournalSucceeded}, entries[len(entries)-1]); d != "" {
+		t.Errorf("newest entry mismatch (-want +got):\n%s", d)
+	}
+	for i := 1; i < len(entries); i++ {
+		if !entries[i].Time.After(entries[i-1].Time) {
+			t.Errorf("expected the entries in order, entry %d is not after entry %d", i, i-1)
+		}
+	}
+}
+
+func TestReadJournal_NotExist(t *testing.T) {
+	entries, err := ReadJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries, got %v", entries)
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	entries := []JournalEntry{
+		// an interrupted install, which never ended
+		{Time: at(0), Operation: OperationInstall, Event: JournalStart},
+		{Time: at(1), Operation: OperationInstall, Phase: PhaseAirbyte, Event: JournalStart},
+		// the next install
+		{Time: at(10), Operation: OperationInstall, Event: JournalStart},
+		{Time: at(11), Operation: OperationInstall, Phase: PhaseAirbyte, Event: JournalStart},
+		{Time: at(191), Operation: OperationInstall, Phase: PhaseAirbyte, Event: JournalEnd, Outcome: JournalSucceeded},
+		{Time: at(192), Operation: OperationInstall, Event: JournalEnd, Outcome: JournalSucceeded},
+	}
+
+	want := []TimelineSpan{
+		{Operation: OperationInstall, Start: at(0)},
+		{Operation: OperationInstall, Phase: PhaseAirbyte, Start: at(1)},
+		{Operation: OperationInstall, Start: at(10), Duration: 182 * time.Second, Outcome: JournalSucceeded},
+		{Operation: OperationInstall, Phase: PhaseAirbyte, Start: at(11), Duration: 3 * time.Minute, Outcome: JournalSucceeded},
+	}
+	if d := cmp.Diff(want, Timeline(entries)); d != "" {
+		t.Errorf("timeline mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestManager_Uninstall_Journal(t *testing.T) {
+	origData := paths.Data
+	t.Cleanup(func() { paths.Data = origData })
+	paths.Data = filepath.Join(t.TempDir(), "data")
+
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8stest.MockClient{}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithTelemetryClient(&telemetry.MockClient{}),
+		WithProgress(func(Event) {}),
+		WithJournal(path),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svcMgr.Uninstall(context.Background(), UninstallOpts{Persisted: true}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	entries, err := ReadJournal(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	type event struct {
+		Phase   Phase
+		Event   string
+		Outcome string
+	}
+	var got []event
+	for _, e := range entries {
+		if e.Operation != OperationUninstall {
+			t.Errorf("unexpected operation %s", e.Operation)
+		}
+		got = append(got, event{Phase: e.Phase, Event: e.Event, Outcome: e.Outcome})
+	}
+
+	want := []event{
+		{Event: JournalStart},
+		{Phase: PhasePersistedData, Event: JournalStart},
+		{Phase: PhasePersistedData, Event: JournalEnd, Outcome: JournalSucceeded},
+		{Phase: PhaseUninstalled, Event: JournalStart},
+		{Phase: PhaseUninstalled, Event: JournalEnd, Outcome: JournalSucceeded},
+		{Event: JournalEnd, Outcome: JournalSucceeded},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("journal mismatch (-want +got):\n%s", d)
+	}
+}
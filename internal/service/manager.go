@@ -48,10 +48,15 @@ type Manager struct {
 	helm     goHelm.Client
 	k8s      k8s.Client
 	portHTTP int
-	spinner  *pterm.SpinnerPrinter
+	progress func(Event)
 	tel      telemetry.Client
 	launcher BrowserLauncher
 	userHome string
+
+	// imageManifest is the path of the manifest tracking the images pulled by abctl
+	imageManifest string
+	// journal records the phases of the install and uninstall operations
+	journal *journal
 }
 
 // Option for configuring the Manager, primarily exists for testing
@@ -105,12 +110,25 @@ func WithUserHome(home string) Option {
 	}
 }
 
-func WithSpinner(spinner *pterm.SpinnerPrinter) Option {
+// WithImageManifest define the path of the manifest tracking the images pulled by abctl.
+func WithImageManifest(path string) Option {
 	return func(m *Manager) {
-		m.spinner = spinner
+		m.imageManifest = path
 	}
 }
 
+// WithJournal define the path of the journal recording the phases of the install and uninstall operations.
+func WithJournal(path string) Option {
+	return func(m *Manager) {
+		m.journal = newJournal(path)
+	}
+}
+
+// WithSpinner displays the progress of the Manager's operations on the spinner.
+func WithSpinner(spinner *pterm.SpinnerPrinter) Option {
+	return WithProgress(SpinnerProgress(spinner))
+}
+
 func WithPortHTTP(port int) Option {
 	return func(m *Manager) {
 		m.portHTTP = port
@@ -129,6 +147,14 @@ func NewManager(provider k8s.Provider, opts ...Option) (*Manager, error) {
 		m.userHome = paths.UserHome
 	}
 
+	if m.imageManifest == "" {
+		m.imageManifest = paths.ImageManifest
+	}
+
+	if m.journal == nil {
+		m.journal = newJournal(paths.Journal)
+	}
+
 	// set http client, if not defined
 	if m.http == nil {
 		m.http = &http.Client{Timeout: 10 * time.Second}
@@ -159,9 +185,10 @@ func NewManager(provider k8s.Provider, opts ...Option) (*Manager, error) {
 		m.tel = telemetry.NoopClient{}
 	}
 
-	// set spinner, if not defined
-	if m.spinner == nil {
-		m.spinner, _ = pterm.DefaultSpinner.Start()
+	// set progress, if not defined
+	if m.progress == nil {
+		spinner, _ := pterm.DefaultSpinner.Start()
+		m.progress = SpinnerProgress(spinner)
 	}
 
 	// set the browser launcher, if not defined
@@ -211,7 +238,7 @@ func DefaultK8s(kubecfg, kubectx string) (k8s.Client, error) {
 		return nil, fmt.Errorf("%w: could not create clientset: %w", abctl.ErrKubernetes, err)
 	}
 
-	return &k8s.DefaultK8sClient{ClientSet: k8sClient}, nil
+	return &k8s.DefaultK8sClient{ClientSet: k8sClient, RestConfig: restCfg}, nil
 }
 
 // SupportMinio checks if a MinIO persistent volume directory exists on the
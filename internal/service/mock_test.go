@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
 	"net/http"
+
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/k8s"
 )
 
 var _ HTTPClient = (*mockHTTP)(nil)
@@ -13,3 +17,25 @@ type mockHTTP struct {
 func (m *mockHTTP) Do(req *http.Request) (*http.Response, error) {
 	return m.do(req)
 }
+
+var _ k8s.Cluster = (*mockCluster)(nil)
+
+type mockCluster struct {
+	loadImages func(ctx context.Context, dockerClient docker.Client, images []string)
+}
+
+func (m *mockCluster) Create(context.Context, int, []k8s.ExtraVolumeMount, k8s.NodeOpts) error {
+	return nil
+}
+
+func (m *mockCluster) Delete(context.Context) error {
+	return nil
+}
+
+func (m *mockCluster) Exists(context.Context) bool {
+	return true
+}
+
+func (m *mockCluster) LoadImages(ctx context.Context, dockerClient docker.Client, images []string) {
+	m.loadImages(ctx, dockerClient, images)
+}
@@ -0,0 +1,71 @@
+package service
+
+import "github.com/pterm/pterm"
+
+// Phase is a stage of an operation of the Manager.
+type Phase string
+
+// The phases of an Install, in the order they occur.
+const (
+	PhaseNamespace Phase = "namespace"
+	PhaseVolumes   Phase = "volumes"
+	PhaseSecrets   Phase = "secrets"
+	PhaseAirbyte   Phase = "airbyte"
+	PhaseHealth    Phase = "health"
+	PhaseIngress   Phase = "ingress"
+	PhaseLaunch    Phase = "launch"
+	PhaseInstalled Phase = "installed"
+)
+
+// The phases of an Uninstall, in the order they occur.
+const (
+	PhasePersistedData Phase = "persisted-data"
+	PhaseUninstalled   Phase = "uninstalled"
+)
+
+// PhaseStatus is the single phase of a Status.
+const PhaseStatus Phase = "status"
+
+// phasePercent is the approximate percentage of the operation completed when its phase begins.
+var phasePercent = map[Phase]int{
+	PhaseNamespace:     0,
+	PhaseVolumes:       5,
+	PhaseSecrets:       10,
+	PhaseAirbyte:       15,
+	PhaseHealth:        60,
+	PhaseIngress:       75,
+	PhaseLaunch:        95,
+	PhaseInstalled:     100,
+	PhasePersistedData: 0,
+	PhaseUninstalled:   100,
+	PhaseStatus:        0,
+}
+
+// Event describes the progress of an operation of the Manager.
+type Event struct {
+	Phase   Phase
+	Message string
+	// Percent is the approximate percentage, between 0 and 100, of the operation completed.
+	Percent int
+}
+
+// WithProgress defines the callback which receives the progress events of the Manager's operations.
+// The callback is called synchronously and should return quickly.
+func WithProgress(fn func(Event)) Option {
+	return func(m *Manager) {
+		m.progress = fn
+	}
+}
+
+// SpinnerProgress returns a progress callback which displays the message of every event on the spinner.
+func SpinnerProgress(spinner *pterm.SpinnerPrinter) func(Event) {
+	return func(e Event) {
+		spinner.UpdateText(e.Message)
+	}
+}
+
+// report sends a progress event for the phase to the progress callback, journaling the phase if it changed.
+func (m *Manager) report(phase Phase, msg string) {
+	m.journal.enter(phase)
+	m.progress(Event{Phase: phase, Message: msg, Percent: phasePercent[phase]})
+}
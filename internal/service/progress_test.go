@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/helm/mock"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/airbytehq/abctl/internal/paths"
+	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestManager_Uninstall_Progress(t *testing.T) {
+	tests := []struct {
+		name      string
+		persisted bool
+		want      []Event
+	}{
+		{
+			name: "retain persisted data",
+			want: []Event{
+				{Phase: PhaseUninstalled, Message: "Airbyte uninstalled", Percent: 100},
+			},
+		},
+		{
+			name:      "remove persisted data",
+			persisted: true,
+			want: []Event{
+				{Phase: PhasePersistedData, Message: "Removing persisted data", Percent: 0},
+				{Phase: PhaseUninstalled, Message: "Airbyte uninstalled", Percent: 100},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origData := paths.Data
+			t.Cleanup(func() { paths.Data = origData })
+			paths.Data = filepath.Join(t.TempDir(), "data")
+			if err := os.MkdirAll(paths.Data, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []Event
+			svcMgr, err := NewManager(
+				k8s.TestProvider,
+				WithK8sClient(&k8stest.MockClient{}),
+				WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+				WithTelemetryClient(&telemetry.MockClient{}),
+				WithProgress(func(e Event) { got = append(got, e) }),
+				WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := svcMgr.Uninstall(context.Background(), UninstallOpts{Persisted: tt.persisted}); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", d)
+			}
+
+			_, err = os.Stat(paths.Data)
+			if removed := errors.Is(err, fs.ErrNotExist); removed != tt.persisted {
+				t.Errorf("expected persisted data removed to be %t", tt.persisted)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/docker"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// PullSecretLabel labels the image pull secrets created by abctl, so they can be found and removed on uninstall.
+const PullSecretLabel = "abctl.airbyte.io/pull-secret"
+
+// PullSecret is an image pull secret created in the airbyte namespace, which is given to every airbyte pod.
+type PullSecret struct {
+	Name string
+
+	// Server, User, Password and Email are the registry credentials, unless a ConfigFile is provided.
+	Server   string
+	User     string
+	Password string
+	Email    string
+
+	// ConfigFile is the path of an existing docker config file (e.g. ~/.docker/config.json), which is used as is.
+	ConfigFile string
+}
+
+// String returns the pull secret in its spec format, with the password redacted.
+func (p PullSecret) String() string {
+	if p.ConfigFile != "" {
+		return fmt.Sprintf("name=%s,config=%s", p.Name, p.ConfigFile)
+	}
+	s := fmt.Sprintf("name=%s,registry=%s,user=%s,password=%s", p.Name, p.Server, p.User, helm.RedactedValue)
+	if p.Email != "" {
+		s += ",email=" + p.Email
+	}
+	return s
+}
+
+// ParsePullSecrets parses a slice of pull secret specs, in either the format
+// name=<NAME>,registry=<SERVER>,user=<USER>,password=<PASSWORD>[,email=<EMAIL>] or name=<NAME>,config=<PATH>.
+// Returns an error if any spec is invalid, the error never contains the password.
+func ParsePullSecrets(specs []string) ([]PullSecret, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	secrets := make([]PullSecret, len(specs))
+	names := map[string]bool{}
+	for i, spec := range specs {
+		p, err := parsePullSecret(spec)
+		if err != nil {
+			return nil, err
+		}
+		if names[p.Name] {
+			return nil, fmt.Errorf("pull secret %s is specified more than once", p.Name)
+		}
+		names[p.Name] = true
+		secrets[i] = p
+	}
+
+	return secrets, nil
+}
+
+func parsePullSecret(spec string) (PullSecret, error) {
+	var p PullSecret
+	for i, field := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			// the field may be part of a password, so only its position is reported
+			return PullSecret{}, fmt.Errorf("pull secret field %d is not in the format <KEY>=<VALUE>, passwords containing a comma must be provided with config=<PATH>", i+1)
+		}
+		switch strings.TrimSpace(key) {
+		case "name":
+			p.Name = value
+		case "registry", "server":
+			p.Server = value
+		case "user", "username":
+			p.User = value
+		case "password":
+			p.Password = value
+		case "email":
+			p.Email = value
+		case "config":
+			p.ConfigFile = value
+		default:
+			return PullSecret{}, fmt.Errorf("pull secret field '%s' is unknown, must be one of name, registry, user, password, email or config", key)
+		}
+	}
+
+	if p.Name == "" {
+		return PullSecret{}, fmt.Errorf("pull secret %s is missing a name", p)
+	}
+	if errs := validation.IsDNS1123Subdomain(p.Name); len(errs) > 0 {
+		return PullSecret{}, fmt.Errorf("pull secret name '%s' is invalid: %s", p.Name, strings.Join(errs, "; "))
+	}
+	if p.Name == common.DockerAuthSecretName {
+		return PullSecret{}, fmt.Errorf("pull secret name '%s' is reserved for the --docker-* flags", p.Name)
+	}
+
+	hasCreds := p.Server != "" || p.User != "" || p.Password != "" || p.Email != ""
+	switch {
+	case p.ConfigFile != "" && hasCreds:
+		return PullSecret{}, fmt.Errorf("pull secret %s must provide either config or the registry credentials, not both", p.Name)
+	case p.ConfigFile == "" && (p.Server == "" || p.User == "" || p.Password == ""):
+		return PullSecret{}, fmt.Errorf("pull secret %s must provide config, or registry, user and password", p.Name)
+	}
+
+	return p, nil
+}
+
+// dockerConfigJSON returns the docker config json stored in the pull secret.
+func (p PullSecret) dockerConfigJSON() ([]byte, error) {
+	if p.ConfigFile == "" {
+		return docker.Secret(p.Server, p.User, p.Password, p.Email)
+	}
+
+	raw, err := os.ReadFile(p.ConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the docker config file: %w", err)
+	}
+	var config struct {
+		Auths map[string]any `json:"auths"`
+	}
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("unable to parse the docker config file %s: %w", p.ConfigFile, err)
+	}
+	if len(config.Auths) == 0 {
+		return nil, fmt.Errorf("the docker config file %s has no auths, credential helpers are not supported", p.ConfigFile)
+	}
+	return raw, nil
+}
+
+// handlePullSecret creates, or updates, the image pull secret in the airbyte namespace.
+// The credentials are never logged.
+func (m *Manager) handlePullSecret(ctx context.Context, p PullSecret) error {
+	data, err := p.dockerConfigJSON()
+	if err != nil {
+		return fmt.Errorf("unable to create the '%s' pull secret: %w", p.Name, err)
+	}
+
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: common.AirbyteNamespace,
+			Name:      p.Name,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "abctl",
+				PullSecretLabel:                "true",
+			},
+		},
+		Data: map[string][]byte{corev1.DockerConfigJsonKey: data},
+		Type: corev1.SecretTypeDockerConfigJson,
+	}
+
+	if err := m.k8s.SecretCreateOrUpdate(ctx, secret); err != nil {
+		pterm.Error.Printfln("Unable to create the '%s' pull secret", p.Name)
+		return fmt.Errorf("unable to create the '%s' pull secret: %w", p.Name, err)
+	}
+	pterm.Debug.Printfln("Created the pull secret %s", p)
+	return nil
+}
+
+// deletePullSecrets deletes the image pull secrets created by abctl.
+func (m *Manager) deletePullSecrets(ctx context.Context) error {
+	deleted, err := m.k8s.SecretDeleteLabeled(ctx, common.AirbyteNamespace, PullSecretLabel+"=true")
+	for _, name := range deleted {
+		pterm.Debug.Printfln("Deleted the '%s' pull secret", name)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to delete the pull secrets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/helm/mock"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/telemetry"
+	"github.com/google/go-cmp/cmp"
+	"github.com/pterm/pterm"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParsePullSecrets(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []string
+		want    []PullSecret
+		wantErr bool
+	}{
+		{
+			name: "empty input",
+		},
+		{
+			name: "credentials and config",
+			input: []string{
+				"name=ghcr,registry=ghcr.io,user=octocat,password=s3cret",
+				"name=quay,server=quay.io,username=robot,password=pa=ss,email=robot@example.com",
+				"name=local,config=/home/user/.docker/config.json",
+			},
+			want: []PullSecret{
+				{Name: "ghcr", Server: "ghcr.io", User: "octocat", Password: "s3cret"},
+				{Name: "quay", Server: "quay.io", User: "robot", Password: "pa=ss", Email: "robot@example.com"},
+				{Name: "local", ConfigFile: "/home/user/.docker/config.json"},
+			},
+		},
+		{
+			name:    "missing name",
+			input:   []string{"registry=ghcr.io,user=octocat,password=s3cret"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			input:   []string{"name=Not_Valid,registry=ghcr.io,user=octocat,password=s3cret"},
+			wantErr: true,
+		},
+		{
+			name:    "reserved name",
+			input:   []string{"name=docker-auth,registry=ghcr.io,user=octocat,password=s3cret"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name",
+			input:   []string{"name=ghcr,config=a.json", "name=ghcr,config=b.json"},
+			wantErr: true,
+		},
+		{
+			name:    "missing password",
+			input:   []string{"name=ghcr,registry=ghcr.io,user=octocat"},
+			wantErr: true,
+		},
+		{
+			name:    "config and credentials",
+			input:   []string{"name=ghcr,config=a.json,user=octocat"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field",
+			input:   []string{"name=ghcr,config=a.json,token=abc"},
+			wantErr: true,
+		},
+		{
+			name:    "password containing a comma",
+			input:   []string{"name=ghcr,registry=ghcr.io,user=octocat,password=s3c,ret"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePullSecrets(tt.input)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil {
+				for _, password := range []string{"s3cret", "s3c", "ret"} {
+					if strings.Contains(err.Error(), password) {
+						t.Errorf("error contains the password: %s", err)
+					}
+				}
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("pull secrets mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestPullSecret_String(t *testing.T) {
+	p := PullSecret{Name: "ghcr", Server: "ghcr.io", User: "octocat", Password: "s3cret"}
+	if d := cmp.Diff("name=ghcr,registry=ghcr.io,user=octocat,password=[REDACTED]", p.String()); d != "" {
+		t.Errorf("string mismatch (-want +got):\n%s", d)
+	}
+}
+
+func newPullSecretManager(t *testing.T, objects ...*corev1.Secret) (*Manager, *fake.Clientset) {
+	cs := fake.NewSimpleClientset()
+	for _, o := range objects {
+		if _, err := cs.CoreV1().Secrets(o.Namespace).Create(context.Background(), o, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8s.DefaultK8sClient{ClientSet: cs}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithTelemetryClient(&telemetry.MockClient{}),
+		WithSpinner(&pterm.SpinnerPrinter{}),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svcMgr, cs
+}
+
+func TestManager_HandlePullSecret(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	config := `{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNz"}}}`
+	if err := os.WriteFile(configFile, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		pullSecret PullSecret
+		wantAuths  map[string]any
+	}{
+		{
+			name:       "credentials",
+			pullSecret: PullSecret{Name: "ghcr", Server: "ghcr.io", User: "octocat", Password: "s3cret"},
+			wantAuths: map[string]any{"ghcr.io": map[string]any{
+				"username": "octocat",
+				"password": "s3cret",
+				"email":    "",
+				"auth":     "b2N0b2NhdDpzM2NyZXQ=",
+			}},
+		},
+		{
+			name:       "config file",
+			pullSecret: PullSecret{Name: "local", ConfigFile: configFile},
+			wantAuths:  map[string]any{"registry.example.com": map[string]any{"auth": "dXNlcjpwYXNz"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svcMgr, cs := newPullSecretManager(t)
+			if err := svcMgr.handlePullSecret(context.Background(), tt.pullSecret); err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			secret, err := cs.CoreV1().Secrets(common.AirbyteNamespace).Get(context.Background(), tt.pullSecret.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal("expected the secret to be created", err)
+			}
+			if d := cmp.Diff(corev1.SecretTypeDockerConfigJson, secret.Type); d != "" {
+				t.Errorf("secret type mismatch (-want +got):\n%s", d)
+			}
+			if d := cmp.Diff("true", secret.Labels[PullSecretLabel]); d != "" {
+				t.Errorf("secret label mismatch (-want +got):\n%s", d)
+			}
+
+			var got struct {
+				Auths map[string]any `json:"auths"`
+			}
+			if err := json.Unmarshal(secret.Data[corev1.DockerConfigJsonKey], &got); err != nil {
+				t.Fatal(err)
+			}
+			if d := cmp.Diff(tt.wantAuths, got.Auths); d != "" {
+				t.Errorf("auths mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestManager_HandlePullSecret_InvalidConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFile, []byte(`{"credsStore":"desktop"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	svcMgr, cs := newPullSecretManager(t)
+	if err := svcMgr.handlePullSecret(context.Background(), PullSecret{Name: "local", ConfigFile: configFile}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	secrets, err := cs.CoreV1().Secrets(common.AirbyteNamespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(0, len(secrets.Items)); d != "" {
+		t.Errorf("unexpected secrets (-want +got):\n%s", d)
+	}
+}
+
+func TestManager_Uninstall_PullSecrets(t *testing.T) {
+	svcMgr, cs := newPullSecretManager(t,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: common.AirbyteNamespace, Name: "ghcr", Labels: map[string]string{PullSecretLabel: "true"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: common.AirbyteNamespace, Name: "quay", Labels: map[string]string{PullSecretLabel: "true"}}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: common.AirbyteNamespace, Name: common.AirbyteAuthSecretName}},
+	)
+
+	if err := svcMgr.Uninstall(context.Background(), UninstallOpts{}); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	secrets, err := cs.CoreV1().Secrets(common.AirbyteNamespace).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range secrets.Items {
+		names = append(names, s.Name)
+	}
+	// only the pull secrets created by abctl are removed
+	if d := cmp.Diff([]string{common.AirbyteAuthSecretName}, names); d != "" {
+		t.Errorf("secrets mismatch (-want +got):\n%s", d)
+	}
+}
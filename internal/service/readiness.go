@@ -0,0 +1,436 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ComponentKind groups components which share a default readiness timeout.
+type ComponentKind string
+
+const (
+	// ComponentDatabase is a stateful database component (e.g. the airbyte-db)
+	ComponentDatabase ComponentKind = "database"
+	// ComponentStorage is a stateful storage component (e.g. minio)
+	ComponentStorage ComponentKind = "storage"
+	// ComponentService is any stateless component (e.g. server, webapp, worker)
+	ComponentService ComponentKind = "service"
+)
+
+// defaultComponentTimeouts are the readiness timeouts applied to each component kind.
+// Stateful components are given longer, as they may need to initialize or migrate their data on startup.
+var defaultComponentTimeouts = map[ComponentKind]time.Duration{
+	ComponentDatabase: 20 * time.Minute,
+	ComponentStorage:  15 * time.Minute,
+	ComponentService:  10 * time.Minute,
+}
+
+// readinessPollInterval is how often the component readiness is checked.
+var readinessPollInterval = 5 * time.Second
+
+// ComponentTimeouts contains the readiness timeouts for the airbyte components.
+type ComponentTimeouts struct {
+	// Defaults are the timeouts per component kind, if nil the defaultComponentTimeouts are used
+	Defaults map[ComponentKind]time.Duration
+	// Overrides are the timeouts per component name (e.g. "db" or "server"), taking precedence over the Defaults
+	Overrides map[string]time.Duration
+	// Stall, if non-zero, replaces the Defaults with a timeout which restarts whenever a component makes progress,
+	// only expiring once the component has made no progress for the Stall duration. Overrides remain fixed timeouts.
+	Stall time.Duration
+}
+
+// For returns the readiness timeout of the component.
+func (c ComponentTimeouts) For(component string) time.Duration {
+	if d, ok := c.Overrides[component]; ok {
+		return d
+	}
+	defaults := c.Defaults
+	if defaults == nil {
+		defaults = defaultComponentTimeouts
+	}
+	if d, ok := defaults[componentKind(component)]; ok {
+		return d
+	}
+	return defaultComponentTimeouts[ComponentService]
+}
+
+// Longest returns the longest fixed readiness timeout of any component. A Stall timeout is not included,
+// as it restarts whenever a component makes progress.
+func (c ComponentTimeouts) Longest() time.Duration {
+	defaults := c.Defaults
+	if defaults == nil {
+		defaults = defaultComponentTimeouts
+	}
+
+	var longest time.Duration
+	for _, d := range defaults {
+		longest = max(longest, d)
+	}
+	for _, d := range c.Overrides {
+		longest = max(longest, d)
+	}
+	return longest
+}
+
+// stallFor returns the stall timeout of the component, false if the component has a fixed timeout.
+func (c ComponentTimeouts) stallFor(component string) (time.Duration, bool) {
+	if c.Stall <= 0 {
+		return 0, false
+	}
+	if _, ok := c.Overrides[component]; ok {
+		return 0, false
+	}
+	return c.Stall, true
+}
+
+// ComponentTimeoutError is returned when a component did not become ready within its timeout.
+type ComponentTimeoutError struct {
+	Component string
+	Timeout   time.Duration
+	// Stalled is true if the Timeout is a stall timeout, i.e. the component made no progress for the Timeout.
+	Stalled bool
+}
+
+func (e *ComponentTimeoutError) Error() string {
+	if e.Stalled {
+		return fmt.Sprintf("component '%s' made no progress towards ready for %s", e.Component, e.Timeout)
+	}
+	return fmt.Sprintf("component '%s' was not ready within %s", e.Component, e.Timeout)
+}
+
+// componentKind returns the kind of the component, based on its name.
+func componentKind(component string) ComponentKind {
+	switch {
+	case component == "db" || strings.Contains(component, "postgres"):
+		return ComponentDatabase
+	case strings.Contains(component, "minio"):
+		return ComponentStorage
+	default:
+		return ComponentService
+	}
+}
+
+// PodComponent returns the component name of the pod, which is derived from the controller owning the pod
+// with the release prefix removed, e.g. "airbyte-abctl-server-5d8f7b9c4-x2x7z" is the "server" component.
+// Pods which are not owned by a long-running controller (e.g. job or hook pods) return an empty string.
+func PodComponent(pod corev1.Pod) string {
+	var name string
+	for _, owner := range pod.OwnerReferences {
+		switch owner.Kind {
+		case "ReplicaSet":
+			// replica-sets are named <deployment>-<pod-template-hash>
+			name = owner.Name
+			if idx := strings.LastIndex(name, "-"); idx > 0 {
+				name = name[:idx]
+			}
+		case "StatefulSet", "DaemonSet":
+			name = owner.Name
+		}
+	}
+
+	name = strings.TrimPrefix(name, "airbyte-abctl-")
+	name = strings.TrimPrefix(name, "airbyte-")
+	return name
+}
+
+// ReadinessSelectors adjust which pods gate the readiness of the airbyte components. Without any, only the pods
+// belonging to a component (see PodComponent) are waited on.
+type ReadinessSelectors struct {
+	// WaitFor selects additional pods to wait on, e.g. those of a deployment or job added through the helm values.
+	WaitFor []k8s.LabelSelector
+	// Ignore selects pods which are never waited on, e.g. optional components which are known to be slow.
+	Ignore []k8s.LabelSelector
+}
+
+// component returns the name the readiness of the pod is tracked by, empty if the pod isn't waited on.
+// Selected pods which don't belong to a component are tracked by the first selector selecting them.
+func (s ReadinessSelectors) component(pod corev1.Pod) string {
+	if s.ignored(pod) {
+		return ""
+	}
+	if component := PodComponent(pod); component != "" {
+		return component
+	}
+	for _, sel := range s.WaitFor {
+		if sel.Matches(pod.Labels) {
+			return sel.String()
+		}
+	}
+	return ""
+}
+
+// ignored returns true if the pod is selected by any of the Ignore selectors.
+func (s ReadinessSelectors) ignored(pod corev1.Pod) bool {
+	return slices.ContainsFunc(s.Ignore, func(sel k8s.LabelSelector) bool { return sel.Matches(pod.Labels) })
+}
+
+// podReady returns true if the pod reports the Ready condition.
+func podReady(pod corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podSucceeded returns true if every container of the pod ran to completion, e.g. the pod of a job.
+// Such a pod never reports ready, but no longer holds up readiness either.
+func podSucceeded(pod corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded
+}
+
+// podProgress returns a measure of how far the pod has progressed towards ready. It increases as the pod is
+// scheduled and initialized, the images of its containers are pulled, and its containers start and become ready.
+func podProgress(pod corev1.Pod) int {
+	var progress int
+	for _, cond := range pod.Status.Conditions {
+		if cond.Status == corev1.ConditionTrue {
+			progress++
+		}
+	}
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.ImageID != "" {
+				progress++
+			}
+			if status.State.Running != nil || (status.State.Terminated != nil && status.State.Terminated.ExitCode == 0) {
+				progress++
+			}
+			if status.Ready {
+				progress++
+			}
+		}
+	}
+	return progress
+}
+
+// stallTracker tracks the progress of the components, detecting those which stopped making progress.
+// Only progress beyond the most progress seen counts, so a crash-looping component which repeatedly
+// starts and fails is not mistaken for one making progress.
+type stallTracker struct {
+	most         map[string]int
+	lastProgress map[string]time.Time
+}
+
+func newStallTracker() *stallTracker {
+	return &stallTracker{most: map[string]int{}, lastProgress: map[string]time.Time{}}
+}
+
+// observe records the progress of the component at now, returning how long it has been since the component
+// last made progress. The first observation of a component counts as progress.
+func (s *stallTracker) observe(component string, progress int, now time.Time) time.Duration {
+	if most, ok := s.most[component]; !ok || progress > most {
+		s.most[component] = progress
+		s.lastProgress[component] = now
+	}
+	return now.Sub(s.lastProgress[component])
+}
+
+// pollComponentReadiness polls the pods in the namespace until the ctx is done, tracking the readiness of every
+// component. Each component's timeout starts when its first pod is seen, or with a stall timeout, whenever the
+// component last made progress. If any component is not ready before its timeout expires, a ComponentTimeoutError
+// for that component is returned.
+// Which pods are tracked is adjusted by the selectors.
+// If pullRetry is provided, the pods stuck pulling their images are remediated by it.
+func pollComponentReadiness(ctx context.Context, client k8s.Client, namespace string, timeouts ComponentTimeouts, selectors ReadinessSelectors, pullRetry *imagePullRetrier) error {
+	firstSeen := map[string]time.Time{}
+	ready := map[string]bool{}
+	stalls := newStallTracker()
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pods, err := client.PodList(ctx, namespace)
+		if err != nil {
+			pterm.Debug.Printfln("Unable to list pods in namespace '%s': %s", namespace, err)
+		} else {
+			if pullRetry != nil {
+				pullRetry.remediate(ctx, namespace, pods.Items)
+			}
+
+			now := time.Now()
+			// a component is only ready once all of its pods are
+			componentReady := map[string]bool{}
+			progress := map[string]int{}
+			for _, pod := range pods.Items {
+				component := selectors.component(pod)
+				if component == "" || ready[component] {
+					continue
+				}
+				if _, ok := firstSeen[component]; !ok {
+					firstSeen[component] = now
+				}
+				if r, ok := componentReady[component]; !ok || r {
+					componentReady[component] = podReady(pod) || podSucceeded(pod)
+				}
+				progress[component] += podProgress(pod)
+			}
+
+			var pending []string
+			for component, r := range componentReady {
+				if r {
+					ready[component] = true
+					pterm.Debug.Printfln("Component '%s' is ready", component)
+					continue
+				}
+				pending = append(pending, component)
+			}
+			sort.Strings(pending)
+
+			for _, component := range pending {
+				if stall, ok := timeouts.stallFor(component); ok {
+					if stalls.observe(component, progress[component], now) > stall {
+						pterm.Error.Printfln("Component '%s' made no progress towards ready for %s", component, stall)
+						return &ComponentTimeoutError{Component: component, Timeout: stall, Stalled: true}
+					}
+					continue
+				}
+
+				timeout := timeouts.For(component)
+				if now.Sub(firstSeen[component]) > timeout {
+					pterm.Error.Printfln("Component '%s' was not ready within %s", component, timeout)
+					return &ComponentTimeoutError{Component: component, Timeout: timeout}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitForSelectedPods waits until each of the WaitFor selectors selects at least one pod, and every selected pod
+// which isn't ignored is ready or has succeeded. If the pods of a selector are not ready within its timeout,
+// a ComponentTimeoutError for the selector is returned.
+func waitForSelectedPods(ctx context.Context, client k8s.Client, namespace string, selectors ReadinessSelectors, timeouts ComponentTimeouts) error {
+	if len(selectors.WaitFor) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	for {
+		pods, err := client.PodList(ctx, namespace)
+		if err != nil {
+			pterm.Debug.Printfln("Unable to list pods in namespace '%s': %s", namespace, err)
+		} else {
+			var pending []string
+			for _, sel := range selectors.WaitFor {
+				found, ready := false, true
+				for _, pod := range pods.Items {
+					if !sel.Matches(pod.Labels) || selectors.ignored(pod) {
+						continue
+					}
+					found = true
+					ready = ready && (podReady(pod) || podSucceeded(pod))
+				}
+				if !found || !ready {
+					pending = append(pending, sel.String())
+				}
+			}
+
+			if len(pending) == 0 {
+				return nil
+			}
+
+			for _, name := range pending {
+				timeout := timeouts.For(name)
+				if time.Since(start) > timeout {
+					pterm.Error.Printfln("Pods selected by '%s' were not ready within %s", name, timeout)
+					return &ComponentTimeoutError{Component: name, Timeout: timeout}
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReadinessEndpoint is the path, and the acceptable status codes of it, polled to declare airbyte reachable once
+// installed. The zero value polls the root path, accepting a 200, or the 401 of the basic auth added by abctl.
+type ReadinessEndpoint struct {
+	Path     string
+	Statuses []int
+}
+
+// String returns the endpoint in its spec format.
+func (r ReadinessEndpoint) String() string {
+	if len(r.Statuses) == 0 {
+		return r.Path
+	}
+	statuses := make([]string, len(r.Statuses))
+	for i, s := range r.Statuses {
+		statuses[i] = strconv.Itoa(s)
+	}
+	return r.Path + ":" + strings.Join(statuses, ",")
+}
+
+// ParseReadinessEndpoint parses a readiness endpoint spec, in the format <PATH>[:<STATUS>[,<STATUS>...]]
+// (e.g. /api/v1/health:200,204). An empty spec returns the zero ReadinessEndpoint.
+func ParseReadinessEndpoint(spec string) (ReadinessEndpoint, error) {
+	if spec == "" {
+		return ReadinessEndpoint{}, nil
+	}
+
+	path, statuses, hasStatuses := strings.Cut(spec, ":")
+	if !strings.HasPrefix(path, "/") {
+		return ReadinessEndpoint{}, fmt.Errorf("readiness endpoint '%s' must start with a '/'", spec)
+	}
+
+	r := ReadinessEndpoint{Path: path}
+	if !hasStatuses {
+		return r, nil
+	}
+	for _, s := range strings.Split(statuses, ",") {
+		status, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || status < 100 || status > 599 {
+			return ReadinessEndpoint{}, fmt.Errorf("readiness endpoint '%s' has an invalid status code '%s'", spec, s)
+		}
+		r.Statuses = append(r.Statuses, status)
+	}
+	return r, nil
+}
+
+// url returns the url of the endpoint on the airbyte base url.
+func (r ReadinessEndpoint) url(base string) string {
+	if r.Path == "" {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + r.Path
+}
+
+// accepts returns true if the response declares airbyte reachable.
+func (r ReadinessEndpoint) accepts(res *http.Response) bool {
+	if len(r.Statuses) > 0 {
+		return slices.Contains(r.Statuses, res.StatusCode)
+	}
+
+	// if no auth, we should get a 200
+	if res.StatusCode == http.StatusOK {
+		return true
+	}
+	// if basic auth, we should get a 401 with a specific header that contains abctl
+	return res.StatusCode == http.StatusUnauthorized && strings.Contains(res.Header.Get("WWW-Authenticate"), "abctl")
+}
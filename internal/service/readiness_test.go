@@ -0,0 +1,572 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testPod(name, ownerKind, ownerName string, ready bool) corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			OwnerReferences: []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName}},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestPodComponent(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  corev1.Pod
+		want string
+	}{
+		{
+			name: "deployment",
+			pod:  testPod("airbyte-abctl-server-5d8f7b9c4-x2x7z", "ReplicaSet", "airbyte-abctl-server-5d8f7b9c4", true),
+			want: "server",
+		},
+		{
+			name: "statefulset",
+			pod:  testPod("airbyte-db-0", "StatefulSet", "airbyte-db", true),
+			want: "db",
+		},
+		{
+			name: "job",
+			pod:  testPod("airbyte-abctl-sync-abc", "Job", "airbyte-abctl-sync", true),
+		},
+		{
+			name: "bare pod",
+			pod:  corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "airbyte-abctl-airbyte-bootloader"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, PodComponent(tt.pod)); d != "" {
+				t.Errorf("PodComponent mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestComponentTimeouts_For(t *testing.T) {
+	timeouts := ComponentTimeouts{Overrides: map[string]time.Duration{"server": time.Minute}}
+
+	if d := cmp.Diff(time.Minute, timeouts.For("server")); d != "" {
+		t.Errorf("override mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(defaultComponentTimeouts[ComponentDatabase], timeouts.For("db")); d != "" {
+		t.Errorf("database default mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(defaultComponentTimeouts[ComponentStorage], timeouts.For("minio")); d != "" {
+		t.Errorf("storage default mismatch (-want +got):\n%s", d)
+	}
+	if d := cmp.Diff(defaultComponentTimeouts[ComponentService], timeouts.For("worker")); d != "" {
+		t.Errorf("service default mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestComponentTimeouts_Longest(t *testing.T) {
+	if d := cmp.Diff(defaultComponentTimeouts[ComponentDatabase], ComponentTimeouts{}.Longest()); d != "" {
+		t.Errorf("longest mismatch (-want +got):\n%s", d)
+	}
+
+	timeouts := ComponentTimeouts{Overrides: map[string]time.Duration{"server": 45 * time.Minute}, Stall: time.Hour}
+	if d := cmp.Diff(45*time.Minute, timeouts.Longest()); d != "" {
+		t.Errorf("longest mismatch (-want +got):\n%s", d)
+	}
+}
+
+func setReadinessPollInterval(t *testing.T, d time.Duration) {
+	orig := readinessPollInterval
+	readinessPollInterval = d
+	t.Cleanup(func() { readinessPollInterval = orig })
+}
+
+func TestPollComponentReadiness_Override(t *testing.T) {
+	setReadinessPollInterval(t, 10*time.Millisecond)
+
+	start := time.Now()
+	k8sClient := &k8stest.MockClient{
+		FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+			if d := cmp.Diff(common.AirbyteNamespace, namespace); d != "" {
+				t.Error("namespace mismatch", d)
+			}
+			// the db takes longer than the service default to become ready
+			dbReady := time.Since(start) > 200*time.Millisecond
+			return &corev1.PodList{Items: []corev1.Pod{
+				testPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", true),
+				testPod("airbyte-db-0", "StatefulSet", "airbyte-db", dbReady),
+			}}, nil
+		},
+	}
+
+	timeouts := ComponentTimeouts{
+		Defaults: map[ComponentKind]time.Duration{
+			ComponentDatabase: 50 * time.Millisecond,
+			ComponentService:  50 * time.Millisecond,
+		},
+		Overrides: map[string]time.Duration{"db": 5 * time.Second},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	if err := pollComponentReadiness(ctx, k8sClient, common.AirbyteNamespace, timeouts, ReadinessSelectors{}, nil); err != nil {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestPollComponentReadiness_Timeout(t *testing.T) {
+	setReadinessPollInterval(t, 10*time.Millisecond)
+
+	k8sClient := &k8stest.MockClient{
+		FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+			return &corev1.PodList{Items: []corev1.Pod{
+				testPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", true),
+				testPod("airbyte-abctl-worker-456-def", "ReplicaSet", "airbyte-abctl-worker-456", false),
+				testPod("airbyte-db-0", "StatefulSet", "airbyte-db", false),
+			}}, nil
+		},
+	}
+
+	// the db has a generous override, the worker must still enforce its default
+	timeouts := ComponentTimeouts{
+		Defaults: map[ComponentKind]time.Duration{
+			ComponentDatabase: 50 * time.Millisecond,
+			ComponentService:  50 * time.Millisecond,
+		},
+		Overrides: map[string]time.Duration{"db": 5 * time.Second},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	err := pollComponentReadiness(ctx, k8sClient, common.AirbyteNamespace, timeouts, ReadinessSelectors{}, nil)
+
+	var timeoutErr *ComponentTimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expected ComponentTimeoutError but got %v", err)
+	}
+	expected := &ComponentTimeoutError{Component: "worker", Timeout: 50 * time.Millisecond}
+	if d := cmp.Diff(expected, timeoutErr); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestStallTracker(t *testing.T) {
+	start := time.Now()
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	tests := []struct {
+		name     string
+		progress []int
+		want     time.Duration
+	}{
+		{name: "first observation", progress: []int{0}, want: 0},
+		{name: "steady progress", progress: []int{0, 1, 2, 3, 4}, want: 0},
+		{name: "no progress", progress: []int{2, 2, 2, 2, 2}, want: 4 * time.Second},
+		{name: "progress then stall", progress: []int{0, 1, 2, 2, 2}, want: 2 * time.Second},
+		{name: "regression is not progress", progress: []int{3, 1, 2, 3, 3}, want: 4 * time.Second},
+		{name: "beyond the most progress", progress: []int{3, 1, 2, 3, 4}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newStallTracker()
+			var got time.Duration
+			// one observation per second
+			for i, p := range tt.progress {
+				got = tracker.observe("server", p, at(i))
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("stalled duration mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestPodProgress(t *testing.T) {
+	pending := corev1.Pod{}
+	scheduled := corev1.Pod{Status: corev1.PodStatus{
+		Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}},
+		ContainerStatuses: []corev1.ContainerStatus{
+			{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+		},
+	}}
+	pulled := corev1.Pod{Status: corev1.PodStatus{
+		Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}},
+		ContainerStatuses: []corev1.ContainerStatus{
+			{ImageID: "sha256:1", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+		},
+	}}
+	running := corev1.Pod{Status: corev1.PodStatus{
+		Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionTrue}},
+		ContainerStatuses: []corev1.ContainerStatus{
+			{ImageID: "sha256:1", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		},
+	}}
+	ready := corev1.Pod{Status: corev1.PodStatus{
+		Conditions: []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+			{Type: corev1.PodReady, Status: corev1.ConditionTrue},
+		},
+		ContainerStatuses: []corev1.ContainerStatus{
+			{ImageID: "sha256:1", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		},
+	}}
+
+	// every step towards ready increases the progress
+	prev := -1
+	for i, pod := range []corev1.Pod{pending, scheduled, pulled, running, ready} {
+		p := podProgress(pod)
+		if p <= prev {
+			t.Errorf("step %d: progress %d did not increase from %d", i, p, prev)
+		}
+		prev = p
+	}
+}
+
+func TestPollComponentReadiness_Stall(t *testing.T) {
+	setReadinessPollInterval(t, 10*time.Millisecond)
+
+	progressingPod := func(progress int) corev1.Pod {
+		pod := testPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", false)
+		for i := 0; i < progress; i++ {
+			pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{ImageID: "sha256:1"})
+		}
+		return pod
+	}
+
+	t.Run("progressing", func(t *testing.T) {
+		// the server is never ready, but makes progress on every poll
+		polls := 0
+		k8sClient := &k8stest.MockClient{
+			FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+				polls++
+				return &corev1.PodList{Items: []corev1.Pod{progressingPod(polls)}}, nil
+			},
+		}
+
+		// well past both the default timeout and the stall timeout
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+
+		timeouts := ComponentTimeouts{
+			Defaults: map[ComponentKind]time.Duration{ComponentService: 50 * time.Millisecond},
+			Stall:    100 * time.Millisecond,
+		}
+		if err := pollComponentReadiness(ctx, k8sClient, common.AirbyteNamespace, timeouts, ReadinessSelectors{}, nil); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	})
+
+	t.Run("stalled", func(t *testing.T) {
+		k8sClient := &k8stest.MockClient{
+			FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+				return &corev1.PodList{Items: []corev1.Pod{progressingPod(2)}}, nil
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		timeouts := ComponentTimeouts{
+			Defaults: map[ComponentKind]time.Duration{ComponentService: 5 * time.Second},
+			Stall:    50 * time.Millisecond,
+		}
+		err := pollComponentReadiness(ctx, k8sClient, common.AirbyteNamespace, timeouts, ReadinessSelectors{}, nil)
+
+		var timeoutErr *ComponentTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected ComponentTimeoutError but got %v", err)
+		}
+		expected := &ComponentTimeoutError{Component: "server", Timeout: 50 * time.Millisecond, Stalled: true}
+		if d := cmp.Diff(expected, timeoutErr); d != "" {
+			t.Errorf("error mismatch (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("override keeps a fixed timeout", func(t *testing.T) {
+		polls := 0
+		k8sClient := &k8stest.MockClient{
+			FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+				polls++
+				return &corev1.PodList{Items: []corev1.Pod{progressingPod(polls)}}, nil
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		timeouts := ComponentTimeouts{
+			Overrides: map[string]time.Duration{"server": 50 * time.Millisecond},
+			Stall:     5 * time.Second,
+		}
+		err := pollComponentReadiness(ctx, k8sClient, common.AirbyteNamespace, timeouts, ReadinessSelectors{}, nil)
+
+		expected := &ComponentTimeoutError{Component: "server", Timeout: 50 * time.Millisecond}
+		var timeoutErr *ComponentTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected ComponentTimeoutError but got %v", err)
+		}
+		if d := cmp.Diff(expected, timeoutErr); d != "" {
+			t.Errorf("error mismatch (-want +got):\n%s", d)
+		}
+	})
+}
+
+// labeledPod returns a pod in the airbyte namespace with the labels, owned by the owner kind and name if provided.
+func labeledPod(name, ownerKind, ownerName string, labels map[string]string, ready bool) *corev1.Pod {
+	pod := testPod(name, ownerKind, ownerName, ready)
+	if ownerKind == "" {
+		pod.OwnerReferences = nil
+	}
+	pod.Namespace = common.AirbyteNamespace
+	pod.Labels = labels
+	return &pod
+}
+
+func TestReadinessSelectors_Component(t *testing.T) {
+	selectors := ReadinessSelectors{
+		WaitFor: []k8s.LabelSelector{{Key: "app", Value: "seed"}},
+		Ignore:  []k8s.LabelSelector{{Key: "app", Value: "metrics"}},
+	}
+
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want string
+	}{
+		{
+			name: "default component",
+			pod:  labeledPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", nil, true),
+			want: "server",
+		},
+		{
+			name: "ignored component",
+			pod:  labeledPod("airbyte-abctl-metrics-123-abc", "ReplicaSet", "airbyte-abctl-metrics-123", map[string]string{"app": "metrics"}, true),
+		},
+		{
+			name: "selected job",
+			pod:  labeledPod("seed-abc", "Job", "seed", map[string]string{"app": "seed"}, false),
+			want: "app=seed",
+		},
+		{
+			name: "unselected job",
+			pod:  labeledPod("sync-abc", "Job", "sync", map[string]string{"app": "sync"}, false),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, selectors.component(*tt.pod)); d != "" {
+				t.Errorf("component mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestPollComponentReadiness_Selectors(t *testing.T) {
+	setReadinessPollInterval(t, 10*time.Millisecond)
+
+	client := &k8s.DefaultK8sClient{ClientSet: fake.NewSimpleClientset(
+		labeledPod("airbyte-abctl-server-123-abc", "ReplicaSet", "airbyte-abctl-server-123", nil, true),
+		// an optional component added through the values, which never becomes ready
+		labeledPod("airbyte-abctl-metrics-123-abc", "ReplicaSet", "airbyte-abctl-metrics-123", map[string]string{"app": "metrics"}, false),
+		// a bare pod added through the values, which never becomes ready
+		labeledPod("seed", "", "", map[string]string{"app": "seed"}, false),
+	)}
+	timeouts := ComponentTimeouts{Defaults: map[ComponentKind]time.Duration{ComponentService: 50 * time.Millisecond}}
+
+	poll := func(selectors ReadinessSelectors) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		return pollComponentReadiness(ctx, client, common.AirbyteNamespace, timeouts, selectors, nil)
+	}
+
+	t.Run("default components", func(t *testing.T) {
+		var timeoutErr *ComponentTimeoutError
+		if err := poll(ReadinessSelectors{}); !errors.As(err, &timeoutErr) || timeoutErr.Component != "metrics" {
+			t.Fatalf("expected the metrics component to time out, got %v", err)
+		}
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		if err := poll(ReadinessSelectors{Ignore: []k8s.LabelSelector{{Key: "app", Value: "metrics"}}}); err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	})
+
+	t.Run("waited for", func(t *testing.T) {
+		err := poll(ReadinessSelectors{
+			WaitFor: []k8s.LabelSelector{{Key: "app", Value: "seed"}},
+			Ignore:  []k8s.LabelSelector{{Key: "app", Value: "metrics"}},
+		})
+		var timeoutErr *ComponentTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected ComponentTimeoutError but got %v", err)
+		}
+		if d := cmp.Diff(&ComponentTimeoutError{Component: "app=seed", Timeout: 50 * time.Millisecond}, timeoutErr); d != "" {
+			t.Errorf("error mismatch (-want +got):\n%s", d)
+		}
+	})
+}
+
+func TestWaitForSelectedPods(t *testing.T) {
+	setReadinessPollInterval(t, 10*time.Millisecond)
+
+	succeeded := labeledPod("seed-abc", "Job", "seed", map[string]string{"app": "seed"}, false)
+	succeeded.Status.Phase = corev1.PodSucceeded
+	client := &k8s.DefaultK8sClient{ClientSet: fake.NewSimpleClientset(
+		labeledPod("proxy-123-abc", "ReplicaSet", "proxy-123", map[string]string{"app": "proxy"}, true),
+		succeeded,
+		labeledPod("metrics-123-abc", "ReplicaSet", "metrics-123", map[string]string{"app": "metrics", "tier": "optional"}, false),
+	)}
+	timeouts := ComponentTimeouts{Defaults: map[ComponentKind]time.Duration{ComponentService: 50 * time.Millisecond}}
+
+	tests := []struct {
+		name      string
+		selectors ReadinessSelectors
+		wantErr   *ComponentTimeoutError
+	}{
+		{
+			name: "no selectors",
+		},
+		{
+			name:      "ready and succeeded",
+			selectors: ReadinessSelectors{WaitFor: []k8s.LabelSelector{{Key: "app", Value: "proxy"}, {Key: "app", Value: "seed"}}},
+		},
+		{
+			name:      "not ready",
+			selectors: ReadinessSelectors{WaitFor: []k8s.LabelSelector{{Key: "app", Value: "proxy"}, {Key: "app", Value: "metrics"}}},
+			wantErr:   &ComponentTimeoutError{Component: "app=metrics", Timeout: 50 * time.Millisecond},
+		},
+		{
+			name:      "no pods selected",
+			selectors: ReadinessSelectors{WaitFor: []k8s.LabelSelector{{Key: "app", Value: "missing"}}},
+			wantErr:   &ComponentTimeoutError{Component: "app=missing", Timeout: 50 * time.Millisecond},
+		},
+		{
+			name: "ignored pods are excluded",
+			selectors: ReadinessSelectors{
+				WaitFor: []k8s.LabelSelector{{Key: "app", Value: "proxy"}, {Key: "app", Value: "metrics"}},
+				Ignore:  []k8s.LabelSelector{{Key: "tier", Value: "optional"}},
+			},
+			wantErr: &ComponentTimeoutError{Component: "app=metrics", Timeout: 50 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			err := waitForSelectedPods(ctx, client, common.AirbyteNamespace, tt.selectors, timeouts)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatal("unexpected error", err)
+				}
+				return
+			}
+			var timeoutErr *ComponentTimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("expected ComponentTimeoutError but got %v", err)
+			}
+			if d := cmp.Diff(tt.wantErr, timeoutErr); d != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestParseReadinessEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ReadinessEndpoint
+		wantErr bool
+	}{
+		{name: "empty", input: ""},
+		{name: "path", input: "/api/v1/health", want: ReadinessEndpoint{Path: "/api/v1/health"}},
+		{name: "status", input: "/api/v1/health:204", want: ReadinessEndpoint{Path: "/api/v1/health", Statuses: []int{204}}},
+		{name: "statuses", input: "/login:200, 302", want: ReadinessEndpoint{Path: "/login", Statuses: []int{200, 302}}},
+		{name: "no leading slash", input: "api/v1/health", wantErr: true},
+		{name: "invalid status", input: "/api/v1/health:ok", wantErr: true},
+		{name: "out of range status", input: "/api/v1/health:600", wantErr: true},
+		{name: "empty status", input: "/api/v1/health:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReadinessEndpoint(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("endpoint mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestReadinessEndpoint_Accepts(t *testing.T) {
+	basicAuth := http.Header{"Www-Authenticate": []string{`Basic realm="abctl"`}}
+
+	tests := []struct {
+		name     string
+		endpoint ReadinessEndpoint
+		res      *http.Response
+		want     bool
+	}{
+		{name: "default ok", res: &http.Response{StatusCode: http.StatusOK}, want: true},
+		{name: "default abctl auth", res: &http.Response{StatusCode: http.StatusUnauthorized, Header: basicAuth}, want: true},
+		{name: "default other auth", res: &http.Response{StatusCode: http.StatusUnauthorized}},
+		{name: "default redirect", res: &http.Response{StatusCode: http.StatusFound}},
+		{
+			name:     "statuses",
+			endpoint: ReadinessEndpoint{Path: "/", Statuses: []int{http.StatusFound, http.StatusNoContent}},
+			res:      &http.Response{StatusCode: http.StatusNoContent},
+			want:     true,
+		},
+		{
+			name:     "statuses exclude ok",
+			endpoint: ReadinessEndpoint{Path: "/", Statuses: []int{http.StatusFound}},
+			res:      &http.Response{StatusCode: http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := cmp.Diff(tt.want, tt.endpoint.accepts(tt.res)); d != "" {
+				t.Errorf("accepts mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Package servicetest provides fakes of the clients of the service.Manager, so that the install and uninstall flows
+// can be exercised without docker, kind or a kubernetes cluster. Combined with a k8s.FakeProvider:
+//
+//	cluster := k8s.NewFakeCluster(false)
+//	provider := k8s.FakeProvider(cluster, kubeconfig)
+//	helmClient := servicetest.NewFakeHelm()
+//	mgr, err := service.NewManager(provider,
+//		service.WithK8sClient(&k8stest.MockClient{FnPodList: servicetest.HealthyPodList}),
+//		service.WithHelmClient(helmClient),
+//		service.WithHTTPClient(servicetest.HealthyHTTPClient{}),
+//		service.WithBrowserLauncher(servicetest.NoopBrowser),
+//	)
+package servicetest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/service"
+	goHelm "github.com/mittwald/go-helm-client"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FakeChartVersion is the version of the charts of the FakeHelm, unless a version is requested.
+const FakeChartVersion = "0.0.0-fake"
+
+var _ goHelm.Client = (*FakeHelm)(nil)
+
+// FakeHelm is a helm client holding its repositories and releases in memory. Installing a chart deploys its release
+// immediately. It is safe for concurrent use.
+// Only the methods used by the service.Manager are implemented, calling any other panics.
+type FakeHelm struct {
+	goHelm.Client
+
+	// InstallErr is returned by InstallOrUpgradeChart, which then leaves the releases as is.
+	InstallErr error
+	// UninstallErr is returned by UninstallReleaseByName, which then leaves the releases as is.
+	UninstallErr error
+
+	mu       sync.Mutex
+	repos    map[string]string
+	releases map[string]*release.Release
+}
+
+// NewFakeHelm returns a FakeHelm without any repositories or releases.
+func NewFakeHelm() *FakeHelm {
+	return &FakeHelm{
+		repos:    map[string]string{},
+		releases: map[string]*release.Release{},
+	}
+}
+
+func (f *FakeHelm) AddOrUpdateChartRepo(entry repo.Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.repos[entry.Name] = entry.URL
+	return nil
+}
+
+func (f *FakeHelm) GetChart(chartName string, opts *action.ChartPathOptions) (*chart.Chart, string, error) {
+	return fakeChart(chartName, opts.Version), chartName, nil
+}
+
+func (f *FakeHelm) GetRelease(name string) (*release.Release, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rel, ok := f.releases[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", driver.ErrReleaseNotFound, name)
+	}
+	return rel, nil
+}
+
+func (f *FakeHelm) GetReleaseValues(name string, _ bool) (map[string]any, error) {
+	rel, err := f.GetRelease(name)
+	if err != nil {
+		return nil, err
+	}
+	return rel.Config, nil
+}
+
+func (f *FakeHelm) InstallOrUpgradeChart(_ context.Context, spec *goHelm.ChartSpec, _ *goHelm.GenericHelmOptions) (*release.Release, error) {
+	if f.InstallErr != nil {
+		return nil, f.InstallErr
+	}
+
+	var vals map[string]any
+	if err := yaml.Unmarshal([]byte(spec.ValuesYaml), &vals); err != nil {
+		return nil, fmt.Errorf("unable to parse the values of release %s: %w", spec.ReleaseName, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	version := 1
+	if prev, ok := f.releases[spec.ReleaseName]; ok {
+		version = prev.Version + 1
+	}
+	rel := &release.Release{
+		Name:      spec.ReleaseName,
+		Namespace: spec.Namespace,
+		Version:   version,
+		Chart:     fakeChart(spec.ChartName, spec.Version),
+		Config:    vals,
+		Info:      &release.Info{Status: release.StatusDeployed},
+	}
+	f.releases[spec.ReleaseName] = rel
+	return rel, nil
+}
+
+func (f *FakeHelm) ListDeployedReleases() ([]*release.Release, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	releases := make([]*release.Release, 0, len(f.releases))
+	for _, name := range f.releaseNames() {
+		releases = append(releases, f.releases[name])
+	}
+	return releases, nil
+}
+
+func (f *FakeHelm) UninstallReleaseByName(name string) error {
+	if f.UninstallErr != nil {
+		return f.UninstallErr
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.releases, name)
+	return nil
+}
+
+// Repos returns the URL of each of the added repositories, keyed by name.
+func (f *FakeHelm) Repos() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	repos := make(map[string]string, len(f.repos))
+	for name, url := range f.repos {
+		repos[name] = url
+	}
+	return repos
+}
+
+// Releases returns the sorted names of the deployed releases.
+func (f *FakeHelm) Releases() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.releaseNames()
+}
+
+func (f *FakeHelm) releaseNames() []string {
+	names := make([]string, 0, len(f.releases))
+	for name := range f.releases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fakeChart returns the chart of the name, with the FakeChartVersion unless a version is given.
+func fakeChart(name, version string) *chart.Chart {
+	if version == "" {
+		version = FakeChartVersion
+	}
+	return &chart.Chart{Metadata: &chart.Metadata{Name: name, Version: version, AppVersion: version}}
+}
+
+// ClientFactory returns a service.ManagerClientFactory which returns the clients regardless of the kubeconfig, e.g.
+// for the commands which create their own service.Manager.
+func ClientFactory(k8sClient k8s.Client, helmClient goHelm.Client) service.ManagerClientFactory {
+	return func(_, _ string) (k8s.Client, goHelm.Client, error) {
+		return k8sClient, helmClient, nil
+	}
+}
+
+// HealthyHTTPClient is a service.HTTPClient to which every request succeeds, e.g. the check that airbyte is reachable.
+type HealthyHTTPClient struct{}
+
+func (HealthyHTTPClient) Do(_ *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+// NoopBrowser is a service.BrowserLauncher which doesn't launch a browser.
+func NoopBrowser(_ string) error {
+	return nil
+}
+
+// HealthyPodList lists a ready pod of every component probed by the service.DefaultHealthProbes, for the FnPodList
+// of a k8stest.MockClient. As the probes of the MockClient succeed, the install finds airbyte healthy.
+func HealthyPodList(_ context.Context, _ string) (*corev1.PodList, error) {
+	var pods []corev1.Pod
+	for _, component := range []string{"server", "worker"} {
+		owner := fmt.Sprintf("airbyte-abctl-%s-fake", component)
+		pods = append(pods, corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            owner + "-0",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: owner}},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		})
+	}
+	return &corev1.PodList{Items: pods}, nil
+}
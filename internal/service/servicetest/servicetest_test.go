@@ -0,0 +1,157 @@
+package servicetest
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/abctl"
+	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/airbytehq/abctl/internal/service"
+	"github.com/google/go-cmp/cmp"
+)
+
+// newTestManager returns a manager of the provider whose clients are created by the factory, as the commands do.
+func newTestManager(t *testing.T, provider k8s.Provider, factory service.ManagerClientFactory, journal string) *service.Manager {
+	k8sClient, helmClient, err := factory(provider.Kubeconfig, provider.Context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr, err := service.NewManager(
+		provider,
+		service.WithK8sClient(k8sClient),
+		service.WithHelmClient(helmClient),
+		service.WithHTTPClient(HealthyHTTPClient{}),
+		service.WithBrowserLauncher(NoopBrowser),
+		service.WithProgress(func(service.Event) {}),
+		service.WithJournal(journal),
+		service.WithImageManifest(filepath.Join(t.TempDir(), "images.json")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mgr
+}
+
+// operationOutcomes returns the outcome of each of the operations of the journal, oldest first.
+func operationOutcomes(t *testing.T, journal string) []string {
+	entries, err := service.ReadJournal(journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outcomes []string
+	for _, e := range entries {
+		if e.Phase == "" && e.Event == service.JournalEnd {
+			outcomes = append(outcomes, e.Operation+" "+e.Outcome)
+		}
+	}
+	return outcomes
+}
+
+func TestInstallUninstall(t *testing.T) {
+	ctx := context.Background()
+	journal := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	cluster := k8s.NewFakeCluster(false)
+	provider := k8s.FakeProvider(cluster, filepath.Join(t.TempDir(), "abctl.kubeconfig"))
+	helmClient := NewFakeHelm()
+	factory := ClientFactory(&k8stest.MockClient{FnPodList: HealthyPodList}, helmClient)
+
+	// install
+	c, created, err := provider.EnsureCluster(ctx, 8000, nil, k8s.NodeOpts{})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if !created {
+		t.Error("expected the cluster to be created")
+	}
+
+	mgr := newTestManager(t, provider, factory, journal)
+	if err := mgr.Install(ctx, &service.InstallOpts{
+		HelmValuesYaml:  "global:\n  edition: test\n",
+		AirbyteChartLoc: "airbyte/airbyte",
+	}); err != nil {
+		t.Fatal("unexpected install error:", err)
+	}
+
+	if d := cmp.Diff([]string{common.AirbyteChartRelease, common.NginxChartRelease}, helmClient.Releases()); d != "" {
+		t.Errorf("releases mismatch (-want +got):\n%s", d)
+	}
+	rel, err := helmClient.GetRelease(common.AirbyteChartRelease)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if d := cmp.Diff(map[string]any{"edition": "test"}, rel.Config["global"]); d != "" {
+		t.Errorf("values mismatch (-want +got):\n%s", d)
+	}
+
+	// installing again reuses the cluster and upgrades the releases
+	if _, created, err := provider.EnsureCluster(ctx, 8000, nil, k8s.NodeOpts{}); err != nil || created {
+		t.Errorf("expected the cluster to be reused, created=%t err=%v", created, err)
+	}
+	if err := newTestManager(t, provider, factory, journal).Install(ctx, &service.InstallOpts{
+		HelmValuesYaml:  "global:\n  edition: test\n",
+		AirbyteChartLoc: "airbyte/airbyte",
+	}); err != nil {
+		t.Fatal("unexpected install error:", err)
+	}
+	if rel, _ := helmClient.GetRelease(common.AirbyteChartRelease); rel.Version != 2 {
+		t.Errorf("expected the release to be upgraded to version 2, got %d", rel.Version)
+	}
+
+	// uninstall, which removes the releases along with the cluster
+	if err := mgr.Uninstall(ctx, service.UninstallOpts{}); err != nil {
+		t.Fatal("unexpected uninstall error:", err)
+	}
+	if err := c.Delete(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, err := provider.GetCluster(ctx); !errors.Is(err, abctl.ErrClusterNotFound) {
+		t.Errorf("expected ErrClusterNotFound but got %v", err)
+	}
+	if d := cmp.Diff(1, cluster.Deleted()); d != "" {
+		t.Errorf("deleted mismatch (-want +got):\n%s", d)
+	}
+
+	want := []string{"install succeeded", "install succeeded", "uninstall succeeded"}
+	if d := cmp.Diff(want, operationOutcomes(t, journal)); d != "" {
+		t.Errorf("journal mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestInstall_HelmError(t *testing.T) {
+	ctx := context.Background()
+	journal := filepath.Join(t.TempDir(), "journal.jsonl")
+	errTest := errors.New("test error")
+
+	provider := k8s.FakeProvider(k8s.NewFakeCluster(true), filepath.Join(t.TempDir(), "abctl.kubeconfig"))
+	helmClient := NewFakeHelm()
+	helmClient.InstallErr = errTest
+
+	mgr := newTestManager(t, provider, ClientFactory(&k8stest.MockClient{FnPodList: HealthyPodList}, helmClient), journal)
+	err := mgr.Install(ctx, &service.InstallOpts{AirbyteChartLoc: "airbyte/airbyte"})
+	if !errors.Is(err, errTest) {
+		t.Errorf("expected the install error but got %v", err)
+	}
+	if len(helmClient.Releases()) != 0 {
+		t.Errorf("expected no releases, got %v", helmClient.Releases())
+	}
+	if d := cmp.Diff([]string{"install failed"}, operationOutcomes(t, journal)); d != "" {
+		t.Errorf("journal mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestFakeHelm_Uninstall(t *testing.T) {
+	helmClient := NewFakeHelm()
+	if _, err := helmClient.GetRelease(common.AirbyteChartRelease); err == nil {
+		t.Error("expected a not found error")
+	}
+
+	helmClient.UninstallErr = errors.New("test error")
+	if err := helmClient.UninstallReleaseByName(common.AirbyteChartRelease); err == nil {
+		t.Error("expected the uninstall error")
+	}
+}
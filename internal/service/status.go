@@ -3,20 +3,38 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/airbytehq/abctl/internal/common"
+	"github.com/airbytehq/abctl/internal/helm"
+	"github.com/airbytehq/abctl/internal/k8s"
 	"github.com/pterm/pterm"
 	"go.opencensus.io/trace"
 )
 
-// Status handles the status of local Airbyte.
-func (m *Manager) Status(ctx context.Context) error {
+// Status returns the status of every component of local Airbyte, sorted by component name.
+// Unlike PrintStatus nothing is printed, so it's suitable for embedders as well as the status command.
+func (m *Manager) Status(ctx context.Context) ([]ComponentStatus, error) {
+	return Statuses(ctx, m.k8s, common.AirbyteNamespace)
+}
+
+// Statuses returns the status of every component of the pods in the namespace, sorted by component name.
+func Statuses(ctx context.Context, client k8s.Client, namespace string) ([]ComponentStatus, error) {
+	pods, err := client.PodList(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pods in namespace '%s': %w", namespace, err)
+	}
+	return ComponentStatuses(pods.Items), nil
+}
+
+// PrintStatus prints the status of the helm releases of local Airbyte.
+func (m *Manager) PrintStatus(ctx context.Context) error {
 	_, span := trace.StartSpan(ctx, "command.Status")
 	defer span.End()
 
 	charts := []string{common.AirbyteChartRelease, common.NginxChartRelease}
 	for _, name := range charts {
-		m.spinner.UpdateText(fmt.Sprintf("Verifying %s Helm Chart installation status", name))
+		m.report(PhaseStatus, fmt.Sprintf("Verifying %s Helm Chart installation status", name))
 
 		rel, err := m.helm.GetRelease(name)
 		if err != nil {
@@ -25,10 +43,19 @@ func (m *Manager) Status(ctx context.Context) error {
 			continue
 		}
 
-		pterm.Info.Println(fmt.Sprintf(
+		msg := fmt.Sprintf(
 			"Found helm chart '%s'\n  Status: %s\n  Chart Version: %s\n  App Version: %s",
 			name, rel.Info.Status.String(), rel.Chart.Metadata.Version, rel.Chart.Metadata.AppVersion,
-		))
+		)
+		if name == common.AirbyteChartRelease {
+			msg += fmt.Sprintf("\n  Flavor: %s", helm.FlavorFromValues(rel.Config))
+			components := "none"
+			if enabled := helm.EnabledComponents(rel.Config); len(enabled) > 0 {
+				components = strings.Join(enabled, ", ")
+			}
+			msg += fmt.Sprintf("\n  Optional Components: %s", components)
+		}
+		pterm.Info.Println(msg)
 	}
 
 	pterm.Info.Println(fmt.Sprintf("Airbyte should be accessible via http://localhost:%d", m.portHTTP))
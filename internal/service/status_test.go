@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/airbytehq/abctl/internal/helm/mock"
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestManager_Status(t *testing.T) {
+	restarted := labeledPod("airbyte-abctl-worker-7c9f8d6b5-b2c3d", "ReplicaSet", "airbyte-abctl-worker-7c9f8d6b5", nil, false)
+	restarted.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		RestartCount:         2,
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}},
+	}}
+
+	cs := fake.NewSimpleClientset(
+		labeledPod("airbyte-abctl-server-5d8f7b9c4-x2x7z", "ReplicaSet", "airbyte-abctl-server-5d8f7b9c4", nil, true),
+		labeledPod("airbyte-abctl-worker-7c9f8d6b5-a1b2c", "ReplicaSet", "airbyte-abctl-worker-7c9f8d6b5", nil, true),
+		restarted,
+		labeledPod("airbyte-db-0", "StatefulSet", "airbyte-db", nil, true),
+		// job pods aren't components
+		labeledPod("airbyte-abctl-airbyte-bootloader", "Job", "airbyte-abctl-airbyte-bootloader", nil, false),
+	)
+
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8s.DefaultK8sClient{ClientSet: cs}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := svcMgr.Status(context.Background())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	want := []ComponentStatus{
+		{Component: "db", Ready: 1, Pods: 1},
+		{Component: "server", Ready: 1, Pods: 1},
+		{Component: "worker", Ready: 1, Pods: 2, Restarts: 2, LastState: "OOMKilled"},
+	}
+	if d := cmp.Diff(want, statuses); d != "" {
+		t.Errorf("statuses mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestManager_Status_Err(t *testing.T) {
+	svcMgr, err := NewManager(
+		k8s.TestProvider,
+		WithK8sClient(&k8stest.MockClient{
+			FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+				return nil, errors.New("connection refused")
+			},
+		}),
+		WithHelmClient(mock.NewMockClient(gomock.NewController(t))),
+		WithJournal(filepath.Join(t.TempDir(), "journal.jsonl")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = svcMgr.Status(context.Background())
+	if d := cmp.Diff("unable to list pods in namespace 'airbyte-abctl': connection refused", err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/k8s"
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SyncCheck verifies that a component of the scheduling plane accepts work, through an HTTP GET against the Port and
+// Path of a ready pod of the Component. A check without a Path only requires a ready pod.
+type SyncCheck struct {
+	Component string
+	Port      string
+	Path      string
+}
+
+// DefaultSyncChecks are the checks of the components a sync flows through, in that order: the server accepts the
+// request, the workload api queues it, temporal schedules it, the worker picks it up and the workload launcher starts
+// its pods. Temporal only exposes a gRPC endpoint, it is verified through the worker which depends on it.
+var DefaultSyncChecks = []SyncCheck{
+	{Component: "server", Port: "8001", Path: "/api/v1/health"},
+	{Component: "workload-api-server", Port: "8007", Path: "/health"},
+	{Component: "temporal"},
+	{Component: "worker", Port: "9000", Path: "/"},
+	{Component: "workload-launcher", Port: "8016", Path: "/health"},
+}
+
+// syncVerifyTimeout is how long the components are given to accept work, once installed and healthy.
+var syncVerifyTimeout = 5 * time.Minute
+
+// SyncVerificationError is returned when a component of the scheduling plane doesn't accept work, so syncs can't run.
+type SyncVerificationError struct {
+	Component string
+	Err       error
+}
+
+func (e *SyncVerificationError) Error() string {
+	return fmt.Sprintf("component '%s' is not accepting work: %s", e.Component, e.Err)
+}
+
+func (e *SyncVerificationError) Unwrap() error {
+	return e.Err
+}
+
+// verifySync runs the checks against the pods of the namespace, retrying until all of them succeed. If they don't
+// within the timeout, the error of the last attempt is returned, a SyncVerificationError naming the first component,
+// in the order of the checks, which didn't accept work.
+func verifySync(ctx context.Context, client k8s.Client, namespace string, checks []SyncCheck, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := checkSync(ctx, client, namespace, checks)
+		if err == nil {
+			return nil
+		}
+		pterm.Debug.Printfln("Airbyte is not able to run a sync yet: %s", err)
+		if time.Now().After(deadline) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkSync runs each of the checks in order, against a ready pod of its component, returning a
+// SyncVerificationError for the first which fails.
+func checkSync(ctx context.Context, client k8s.Client, namespace string, checks []SyncCheck) error {
+	pods, err := client.PodList(ctx, namespace)
+	if err != nil {
+		return fmt.Errorf("unable to list pods in namespace '%s': %w", namespace, err)
+	}
+
+	ready := map[string]corev1.Pod{}
+	for _, pod := range pods.Items {
+		component := PodComponent(pod)
+		if _, ok := ready[component]; !ok && podReady(pod) {
+			ready[component] = pod
+		}
+	}
+
+	for _, check := range checks {
+		pod, ok := ready[check.Component]
+		if !ok {
+			return &SyncVerificationError{Component: check.Component, Err: errors.New("no ready pod")}
+		}
+		if check.Path == "" {
+			continue
+		}
+		if _, err := client.PodProxyGet(ctx, namespace, pod.Name, check.Port, check.Path); err != nil {
+			return &SyncVerificationError{
+				Component: check.Component,
+				Err:       fmt.Errorf("check of pod '%s' at %s%s failed: %w", pod.Name, check.Port, check.Path, err),
+			}
+		}
+		pterm.Debug.Printfln("Component '%s' accepts work", check.Component)
+	}
+	return nil
+}
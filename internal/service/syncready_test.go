@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/airbytehq/abctl/internal/k8s/k8stest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// syncPods lists a pod of every component of the DefaultSyncChecks, ready unless it is one of the unready.
+func syncPods(unready ...string) []corev1.Pod {
+	var pods []corev1.Pod
+	for _, check := range DefaultSyncChecks {
+		ready := true
+		for _, component := range unready {
+			ready = ready && component != check.Component
+		}
+		if check.Component == "temporal" {
+			pods = append(pods, testPod("airbyte-temporal-123-abc", "ReplicaSet", "airbyte-temporal-123", ready))
+			continue
+		}
+		owner := "airbyte-abctl-" + check.Component + "-123"
+		pods = append(pods, testPod(owner+"-abc", "ReplicaSet", owner, ready))
+	}
+	return pods
+}
+
+func TestVerifySync(t *testing.T) {
+	setReadinessPollInterval(t, time.Millisecond)
+
+	errProxy := errors.New("503 service unavailable")
+
+	tests := []struct {
+		name    string
+		pods    []corev1.Pod
+		failing map[string]bool
+		// wantComponent is the component the failure is attributed to, none if the verification succeeds
+		wantComponent string
+	}{
+		{
+			name: "accepting work",
+			pods: syncPods(),
+		},
+		{
+			name:          "missing component",
+			pods:          syncPods()[1:],
+			wantComponent: "server",
+		},
+		{
+			name:          "unready temporal",
+			pods:          syncPods("temporal"),
+			wantComponent: "temporal",
+		},
+		{
+			name:          "failing worker",
+			pods:          syncPods(),
+			failing:       map[string]bool{"airbyte-abctl-worker-123-abc": true},
+			wantComponent: "worker",
+		},
+		{
+			name: "first failing in order",
+			pods: syncPods("workload-launcher"),
+			failing: map[string]bool{
+				"airbyte-abctl-worker-123-abc":              true,
+				"airbyte-abctl-workload-api-server-123-abc": true,
+			},
+			wantComponent: "workload-api-server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var probed []string
+			k8sClient := &k8stest.MockClient{
+				FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+					return &corev1.PodList{Items: tt.pods}, nil
+				},
+				FnPodProxyGet: func(ctx context.Context, namespace, name, port, path string) ([]byte, error) {
+					probed = append(probed, port+path)
+					if tt.failing[name] {
+						return nil, errProxy
+					}
+					return nil, nil
+				},
+			}
+
+			err := verifySync(context.Background(), k8sClient, "test", DefaultSyncChecks, 10*time.Millisecond)
+			if tt.wantComponent == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				want := []string{"8001/api/v1/health", "8007/health", "9000/", "8016/health"}
+				if d := cmp.Diff(want, probed); d != "" {
+					t.Errorf("probes mismatch (-want +got):\n%s", d)
+				}
+				return
+			}
+
+			var syncErr *SyncVerificationError
+			if !errors.As(err, &syncErr) {
+				t.Fatalf("expected a SyncVerificationError but got %v", err)
+			}
+			if d := cmp.Diff(tt.wantComponent, syncErr.Component); d != "" {
+				t.Errorf("component mismatch (-want +got):\n%s", d)
+			}
+		})
+	}
+}
+
+func TestVerifySync_Retries(t *testing.T) {
+	setReadinessPollInterval(t, time.Millisecond)
+
+	// the worker only accepts work once temporal is reachable
+	var attempts atomic.Int32
+	k8sClient := &k8stest.MockClient{
+		FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+			return &corev1.PodList{Items: syncPods()}, nil
+		},
+		FnPodProxyGet: func(ctx context.Context, namespace, name, port, path string) ([]byte, error) {
+			if port == "9000" && attempts.Add(1) < 3 {
+				return nil, errors.New("temporal unreachable")
+			}
+			return nil, nil
+		},
+	}
+
+	if err := verifySync(context.Background(), k8sClient, "test", DefaultSyncChecks, time.Minute); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if d := cmp.Diff(int32(3), attempts.Load()); d != "" {
+		t.Errorf("attempts mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestVerifySync_PodListErr(t *testing.T) {
+	setReadinessPollInterval(t, time.Millisecond)
+
+	errList := errors.New("connection refused")
+	k8sClient := &k8stest.MockClient{
+		FnPodList: func(ctx context.Context, namespace string) (*corev1.PodList, error) {
+			return nil, errList
+		},
+	}
+
+	err := verifySync(context.Background(), k8sClient, "test", DefaultSyncChecks, 5*time.Millisecond)
+	if !errors.Is(err, errList) {
+		t.Errorf("expected the pod list error but got %v", err)
+	}
+	// the failure can't be attributed to a component
+	var syncErr *SyncVerificationError
+	if errors.As(err, &syncErr) {
+		t.Errorf("unexpected SyncVerificationError for '%s'", syncErr.Component)
+	}
+}
+
+func TestSyncVerificationError(t *testing.T) {
+	errProxy := errors.New("503 service unavailable")
+	err := &SyncVerificationError{Component: "worker", Err: errProxy}
+
+	if d := cmp.Diff("component 'worker' is not accepting work: 503 service unavailable", err.Error()); d != "" {
+		t.Errorf("error mismatch (-want +got):\n%s", d)
+	}
+	if !errors.Is(err, errProxy) {
+		t.Error("expected the error to wrap the check error")
+	}
+}
@@ -13,11 +13,23 @@ type UninstallOpts struct {
 	Persisted bool
 }
 
-// Uninstall handles the uninstallation of Airbyte.
-func (m *Manager) Uninstall(_ context.Context, opts UninstallOpts) error {
+// Uninstall handles the uninstallation of Airbyte, journaling its phases.
+func (m *Manager) Uninstall(ctx context.Context, opts UninstallOpts) error {
+	m.journal.begin(OperationUninstall)
+	err := m.uninstall(ctx, opts)
+	m.journal.finish(err)
+	return err
+}
+
+func (m *Manager) uninstall(ctx context.Context, opts UninstallOpts) error {
+	// the pull secrets hold registry credentials, so are removed even if the cluster removal fails
+	if err := m.deletePullSecrets(ctx); err != nil {
+		pterm.Warning.Printfln("Unable to remove the image pull secrets: %s", err)
+	}
+
 	// check if persisted data should be removed, if not this is a noop
 	if opts.Persisted {
-		m.spinner.UpdateText("Removing persisted data")
+		m.report(PhasePersistedData, "Removing persisted data")
 		if err := os.RemoveAll(paths.Data); err != nil {
 			pterm.Error.Println(fmt.Sprintf("Unable to remove persisted data '%s'", paths.Data))
 			return fmt.Errorf("unable to remove persisted data '%s': %w", paths.Data, err)
@@ -25,5 +37,6 @@ func (m *Manager) Uninstall(_ context.Context, opts UninstallOpts) error {
 		pterm.Success.Println("Removed persisted data")
 	}
 
+	m.report(PhaseUninstalled, "Airbyte uninstalled")
 	return nil
 }
@@ -15,9 +15,10 @@ import (
 type EventState string
 
 const (
-	Start   EventState = "started"
-	Failed             = "failed"
-	Success            = "succeeded"
+	Start           EventState = "started"
+	Failed                     = "failed"
+	Success                    = "succeeded"
+	PreflightFailed            = "preflight_failed"
 )
 
 type EventType string
@@ -26,11 +27,23 @@ const (
 	Credentials EventType = "credentials"
 	Deployments           = "deployments"
 	Install               = "install"
+	Logs                  = "logs"
 	Migrate               = "migrate"
 	Status                = "status"
 	Uninstall             = "uninstall"
 )
 
+// PreflightReason is an anonymized code describing which pre-flight check blocked an activity.
+// A reason must never include any user specific information (paths, hostnames, ports, etc.).
+type PreflightReason string
+
+const (
+	ReasonDockerDaemonDown PreflightReason = "docker_daemon_down"
+	ReasonPortUnavailable  PreflightReason = "port_unavailable"
+	ReasonClusterNotOwned  PreflightReason = "cluster_not_owned"
+	ReasonStrictWarning    PreflightReason = "strict_warning"
+)
+
 // Client interface for telemetry data.
 type Client interface {
 	// Start should be called as quickly as possible.
@@ -39,6 +52,8 @@ type Client interface {
 	Success(context.Context, EventType) error
 	// Failure should be called only if the activity failed.
 	Failure(context.Context, EventType, error) error
+	// Preflight should be called only if a pre-flight check blocked the activity.
+	Preflight(context.Context, EventType, PreflightReason) error
 	// Attr should be called to add additional attributes to this activity.
 	Attr(key, val string)
 	// User returns the user identifier being used by this client
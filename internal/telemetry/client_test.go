@@ -1,6 +1,7 @@
 package telemetry
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -101,6 +102,11 @@ func TestGet_Dnt(t *testing.T) {
 		t.Error(fmt.Sprintf("expected NoopClient; received: %T", cli))
 	}
 
+	// pre-flight failures are not sent either
+	if err := cli.Preflight(context.Background(), Install, ReasonDockerDaemonDown); err != nil {
+		t.Error("unexpected error", err)
+	}
+
 	// no configuration file was created
 	_, err := os.ReadFile(filepath.Join(home, ConfigFile))
 	if !errors.Is(err, os.ErrNotExist) {
@@ -5,11 +5,12 @@ import "context"
 var _ Client = (*MockClient)(nil)
 
 type MockClient struct {
-	attrs   map[string]string
-	start   func(context.Context, EventType) error
-	success func(context.Context, EventType) error
-	failure func(context.Context, EventType, error) error
-	wrap    func(context.Context, EventType, func() error) error
+	attrs      map[string]string
+	preflights []PreflightReason
+	start      func(context.Context, EventType) error
+	success    func(context.Context, EventType) error
+	failure    func(context.Context, EventType, error) error
+	wrap       func(context.Context, EventType, func() error) error
 }
 
 func (m *MockClient) Start(ctx context.Context, eventType EventType) error {
@@ -24,6 +25,16 @@ func (m *MockClient) Failure(ctx context.Context, eventType EventType, err error
 	return m.failure(ctx, eventType, err)
 }
 
+func (m *MockClient) Preflight(_ context.Context, _ EventType, reason PreflightReason) error {
+	m.preflights = append(m.preflights, reason)
+	return nil
+}
+
+// Preflights returns the reasons of every Preflight call, in the order they were made.
+func (m *MockClient) Preflights() []PreflightReason {
+	return m.preflights
+}
+
 func (m *MockClient) Attr(key, val string) {
 	if m.attrs == nil {
 		m.attrs = map[string]string{}
@@ -22,6 +22,10 @@ func (n NoopClient) Failure(context.Context, EventType, error) error {
 	return nil
 }
 
+func (n NoopClient) Preflight(context.Context, EventType, PreflightReason) error {
+	return nil
+}
+
 func (n NoopClient) Attr(_, _ string) {}
 
 func (n NoopClient) User() string {
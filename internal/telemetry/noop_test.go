@@ -21,6 +21,9 @@ func TestNoopClient(t *testing.T) {
 	if err := cli.Failure(ctx, Install, errors.New("")); err != nil {
 		t.Error(err)
 	}
+	if err := cli.Preflight(ctx, Install, ReasonPortUnavailable); err != nil {
+		t.Error(err)
+	}
 
 	cli.Attr("k", "v'")
 
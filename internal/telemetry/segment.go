@@ -67,15 +67,20 @@ func NewSegmentClient(cfg Config, opts ...Option) *SegmentClient {
 }
 
 func (s *SegmentClient) Start(ctx context.Context, et EventType) error {
-	return s.send(ctx, Start, et, nil)
+	return s.send(ctx, Start, et, nil, nil)
 }
 
 func (s *SegmentClient) Success(ctx context.Context, et EventType) error {
-	return s.send(ctx, Success, et, nil)
+	return s.send(ctx, Success, et, nil, nil)
 }
 
 func (s *SegmentClient) Failure(ctx context.Context, et EventType, err error) error {
-	return s.send(ctx, Failed, et, err)
+	return s.send(ctx, Failed, et, err, nil)
+}
+
+// Preflight sends only the reason code, never the underlying error, as it may contain user specific information.
+func (s *SegmentClient) Preflight(ctx context.Context, et EventType, reason PreflightReason) error {
+	return s.send(ctx, PreflightFailed, et, nil, map[string]string{"preflight_reason": string(reason)})
 }
 
 func (s *SegmentClient) Attr(key, val string) {
@@ -131,7 +136,7 @@ const (
 	url         = "https://api.segment.io/v1/track"
 )
 
-func (s *SegmentClient) send(ctx context.Context, es EventState, et EventType, ee error) error {
+func (s *SegmentClient) send(ctx context.Context, es EventState, et EventType, ee error, props map[string]string) error {
 	properties := map[string]string{
 		"deployment_method": "abctl",
 		"session_id":        s.sessionID.String(),
@@ -145,6 +150,7 @@ func (s *SegmentClient) send(ctx context.Context, es EventState, et EventType, e
 	}
 	// add all the attributes to the properties map before sending it
 	maps.Copy(properties, s.attrs)
+	maps.Copy(properties, props)
 
 	if ee != nil {
 		properties["error"] = ee.Error()
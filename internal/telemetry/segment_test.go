@@ -751,6 +751,50 @@ func TestSegmentClient_FailureErr(t *testing.T) {
 	}
 }
 
+func TestSegmentClient_Preflight(t *testing.T) {
+	var req *http.Request
+	mDoer := &mockDoer{
+		do: func(r *http.Request) (*http.Response, error) {
+			req = r
+			return &http.Response{Body: io.NopCloser(&strings.Reader{})}, nil
+		},
+	}
+
+	opts := []Option{
+		WithSessionID(sessionID),
+		WithHTTPClient(mDoer),
+	}
+
+	cli := NewSegmentClient(Config{AnalyticsID: UUID(userID)}, opts...)
+
+	if err := cli.Preflight(context.Background(), Install, ReasonDockerDaemonDown); err != nil {
+		t.Error("preflight call failed", err)
+	}
+
+	reqBodyRaw, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Error("unable to read request body", err)
+	}
+	var reqBody body
+	if err := json.Unmarshal(reqBodyRaw, &reqBody); err != nil {
+		t.Error("unable to unmarshal request body", err)
+	}
+
+	if d := cmp.Diff(string(Install), reqBody.Event); d != "" {
+		t.Error("request event mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(string(PreflightFailed), reqBody.Properties["state"]); d != "" {
+		t.Error("request state mismatch (-want +got):", d)
+	}
+	if d := cmp.Diff(string(ReasonDockerDaemonDown), reqBody.Properties["preflight_reason"]); d != "" {
+		t.Error("request preflight_reason mismatch (-want +got):", d)
+	}
+	// the error is never sent, only the reason
+	if _, ok := reqBody.Properties["error"]; ok {
+		t.Error("request error should not be set")
+	}
+}
+
 func TestSegmentClient_Wrap(t *testing.T) {
 	var eventType *string
 	var eventStates []string
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/airbytehq/abctl/internal/build"
+	abctlhttp "github.com/airbytehq/abctl/internal/http"
 	"golang.org/x/mod/semver"
 )
 
@@ -25,7 +26,7 @@ type doer interface {
 func Check(ctx context.Context) (string, error) {
 	ctx, updateCancel := context.WithTimeout(ctx, 2*time.Second)
 	defer updateCancel()
-	return check(ctx, http.DefaultClient, build.Version)
+	return check(ctx, abctlhttp.DefaultClient, build.Version)
 }
 
 func check(ctx context.Context, doer doer, version string) (string, error) {
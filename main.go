@@ -11,6 +11,7 @@ import (
 	"github.com/airbytehq/abctl/internal/abctl"
 	"github.com/airbytehq/abctl/internal/build"
 	"github.com/airbytehq/abctl/internal/cmd"
+	"github.com/airbytehq/abctl/internal/cmd/completion"
 	"github.com/airbytehq/abctl/internal/telemetry"
 	"github.com/airbytehq/abctl/internal/trace"
 	"github.com/airbytehq/abctl/internal/update"
@@ -28,9 +29,13 @@ func run() int {
 	// ensure the pterm info width matches the other printers
 	pterm.Info.Prefix.Text = " INFO  "
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	ctx, stop := notifyContext(context.Background())
 	defer stop()
-	printUpdateMsg := checkForNewerAbctlVersion(ctx)
+	// the output of the completion commands is consumed by the shell, which must not wait on the update check
+	printUpdateMsg := func() {}
+	if !completion.Requested(os.Args[1:]) {
+		printUpdateMsg = checkForNewerAbctlVersion(ctx)
+	}
 
 	telClient := telemetry.Get()
 
@@ -94,6 +99,11 @@ func handleErr(ctx context.Context, err error) int {
 		pterm.Info.Println(e.Help())
 	}
 
+	var exitErr *abctl.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
 	return 1
 }
 
@@ -119,6 +129,33 @@ func checkForNewerAbctlVersion(ctx context.Context) func() {
 	}
 }
 
+// notifyContext returns a context which is cancelled on the first interrupt or termination signal,
+// allowing the running command to stop and clean up after itself. A second signal exits immediately.
+func notifyContext(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-sigs:
+		case <-ctx.Done():
+			return
+		}
+		pterm.Warning.Println("Stopping, send the signal again to exit immediately")
+		cancel()
+
+		<-sigs
+		os.Exit(130)
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigs)
+		cancel()
+	}
+}
+
 // bindCtx exists to allow kong to correctly inject a context.Context into the Run methods on the commands.
 func bindCtx(ctx context.Context) func() (context.Context, error) {
 	return func() (context.Context, error) {